@@ -42,6 +42,8 @@ func NewRouter(app core.App) (*router.Router[*core.RequestEvent], error) {
 	bindLogsApi(app, apiGroup)
 	bindBackupApi(app, apiGroup)
 	bindCronApi(app, apiGroup)
+	bindWebhooksApi(app, apiGroup)
+	bindJobsApi(app, apiGroup)
 	bindFileApi(app, apiGroup)
 	bindBatchApi(app, apiGroup)
 	bindRealtimeApi(app, apiGroup)
@@ -1,14 +1,20 @@
 package apis
 
 import (
+	"database/sql"
+	"errors"
 	"net/http"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/router"
 	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 // bindCronApi registers the crons api endpoint.
@@ -16,6 +22,23 @@ func bindCronApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup := rg.Group("/crons").Bind(RequireSuperuserAuth())
 	subGroup.GET("", cronsList)
 	subGroup.POST("/{id}", cronRun)
+	subGroup.GET("/{id}/runs", cronRunsList)
+}
+
+// The supported cron job statuses.
+const (
+	cronStatusIdle    = "idle"
+	cronStatusRunning = "running"
+)
+
+// cronJobInfo defines the public cron job list item data.
+type cronJobInfo struct {
+	Id         string         `json:"id"`
+	Expression string         `json:"expression"`
+	Timezone   string         `json:"timezone"`
+	Status     string         `json:"status"`
+	NextRun    types.DateTime `json:"nextRun"`
+	LastRun    *core.CronRun  `json:"lastRun"`
 }
 
 func cronsList(e *core.RequestEvent) error {
@@ -31,7 +54,35 @@ func cronsList(e *core.RequestEvent) error {
 		return strings.Compare(a.Id(), b.Id())
 	})
 
-	return e.JSON(http.StatusOK, jobs)
+	now := time.Now()
+
+	result := make([]*cronJobInfo, len(jobs))
+	for i, j := range jobs {
+		info := &cronJobInfo{
+			Id:         j.Id(),
+			Expression: j.Expression(),
+			Timezone:   j.Location().String(),
+			Status:     cronStatusIdle,
+		}
+
+		if j.IsRunning() {
+			info.Status = cronStatusRunning
+		}
+
+		if next := j.NextRun(now); !next.IsZero() {
+			info.NextRun, _ = types.ParseDateTime(next)
+		}
+
+		lastRun, err := e.App.FindLastCronRun(j.Id())
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return e.InternalServerError("Failed to load the cron job last run.", err)
+		}
+		info.LastRun = lastRun
+
+		result[i] = info
+	}
+
+	return e.JSON(http.StatusOK, result)
 }
 
 func cronRun(e *core.RequestEvent) error {
@@ -57,3 +108,21 @@ func cronRun(e *core.RequestEvent) error {
 
 	return e.NoContent(http.StatusNoContent)
 }
+
+var cronRunFilterFields = []string{
+	"id", "jobId", "error", "started", "duration", "manual", "panicked", "created",
+}
+
+func cronRunsList(e *core.RequestEvent) error {
+	fieldResolver := search.NewSimpleFieldResolver(cronRunFilterFields...)
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(e.App.CronRunQuery().AndWhere(dbx.HashExp{"jobId": e.Request.PathValue("id")})).
+		ParseAndExec(e.Request.URL.Query().Encode(), &[]*core.CronRun{})
+
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
@@ -64,6 +64,11 @@ func (api *fileApi) fileToken(e *core.RequestEvent) error {
 		return e.UnauthorizedError("Missing auth context.", nil)
 	}
 
+	// the file token is not restricted to specific collections
+	if hasScopedAPIKey(e) {
+		return e.ForbiddenError("The request cannot be performed with a scoped API key.", nil)
+	}
+
 	token, err := e.Auth.NewFileToken()
 	if err != nil {
 		return e.InternalServerError("Failed to generate file token", err)
@@ -0,0 +1,80 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// bindJobsApi registers the background jobs api endpoints.
+func bindJobsApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/jobs").Bind(RequireSuperuserAuth())
+	sub.GET("", jobsList)
+	sub.GET("/{id}", jobsView)
+	sub.POST("/{id}/retry", jobsRetry)
+	sub.POST("/{id}/cancel", jobsCancel)
+}
+
+var jobFilterFields = []string{
+	"id", "name", "queue", "status", "attempts", "maxAttempts",
+	"uniqueKey", "lastError", "runAt", "created", "updated", "payload",
+	`^payload\.[\w\.\:]*\w+$`,
+}
+
+func jobsList(e *core.RequestEvent) error {
+	fieldResolver := search.NewSimpleFieldResolver(jobFilterFields...)
+
+	result, err := search.NewProvider(fieldResolver).
+		Query(e.App.JobQuery()).
+		ParseAndExec(e.Request.URL.Query().Encode(), &[]*core.Job{})
+
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func jobsView(e *core.RequestEvent) error {
+	id := e.Request.PathValue("id")
+	if id == "" {
+		return e.NotFoundError("", nil)
+	}
+
+	job, err := e.App.FindJobById(id)
+	if err != nil || job == nil {
+		return e.NotFoundError("", err)
+	}
+
+	return e.JSON(http.StatusOK, job)
+}
+
+func jobsRetry(e *core.RequestEvent) error {
+	job, err := e.App.FindJobById(e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	job, err = e.App.Jobs().Retry(job.Id)
+	if err != nil {
+		return e.BadRequestError("Failed to retry the job.", err)
+	}
+
+	return e.JSON(http.StatusOK, job)
+}
+
+func jobsCancel(e *core.RequestEvent) error {
+	job, err := e.App.FindJobById(e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	job, err = e.App.Jobs().Cancel(job.Id)
+	if err != nil {
+		return e.BadRequestError("Failed to cancel the job.", err)
+	}
+
+	return e.JSON(http.StatusOK, job)
+}
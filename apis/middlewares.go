@@ -102,10 +102,18 @@ func requireAuth(optCollectionNames ...string) func(*core.RequestEvent) error {
 
 // RequireSuperuserAuth middleware requires a request to have
 // a valid superuser Authorization header.
+//
+// Requests authenticated with a scoped superuser API key are rejected.
 func RequireSuperuserAuth() *hook.Handler[*core.RequestEvent] {
 	return &hook.Handler[*core.RequestEvent]{
-		Id:   DefaultRequireSuperuserAuthMiddlewareId,
-		Func: requireAuth(core.CollectionNameSuperusers),
+		Id: DefaultRequireSuperuserAuthMiddlewareId,
+		Func: func(e *core.RequestEvent) error {
+			if hasScopedAPIKey(e) {
+				return e.ForbiddenError("The request cannot be performed with a scoped API key.", nil)
+			}
+
+			return requireAuth(core.CollectionNameSuperusers)(e)
+		},
 	}
 }
 
@@ -124,6 +132,10 @@ func RequireSuperuserOrOwnerAuth(ownerIdPathParam string) *hook.Handler[*core.Re
 			}
 
 			if e.Auth.IsSuperuser() {
+				if hasScopedAPIKey(e) {
+					return e.ForbiddenError("The request cannot be performed with a scoped API key.", nil)
+				}
+
 				return e.Next()
 			}
 
@@ -146,21 +158,15 @@ func RequireSuperuserOrOwnerAuth(ownerIdPathParam string) *hook.Handler[*core.Re
 // RequireSameCollectionContextAuth middleware requires a request to have
 // a valid record Authorization header and the auth record's collection to
 // match the one from the route path parameter (default to "collection" if collectionParam is empty).
+//
+// Requests authenticated with a scoped API key are rejected because the
+// scoped keys are restricted only to their allowed collections records actions.
 func RequireSameCollectionContextAuth(collectionPathParam string) *hook.Handler[*core.RequestEvent] {
 	return &hook.Handler[*core.RequestEvent]{
 		Id: DefaultRequireSameCollectionContextAuthMiddlewareId,
 		Func: func(e *core.RequestEvent) error {
-			if e.Auth == nil {
-				return e.UnauthorizedError("The request requires valid record authorization token.", nil)
-			}
-
-			if collectionPathParam == "" {
-				collectionPathParam = "collection"
-			}
-
-			collection, _ := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue(collectionPathParam))
-			if collection == nil || e.Auth.Collection().Id != collection.Id {
-				return e.ForbiddenError(fmt.Sprintf("The request requires auth record from %s collection.", e.Auth.Collection().Name), nil)
+			if err := checkSameCollectionContextAuth(e, collectionPathParam); err != nil {
+				return err
 			}
 
 			return e.Next()
@@ -168,6 +174,37 @@ func RequireSameCollectionContextAuth(collectionPathParam string) *hook.Handler[
 	}
 }
 
+// checkSameCollectionContextAuth returns an error if the request
+// doesn't have a valid auth record from the path param collection.
+func checkSameCollectionContextAuth(e *core.RequestEvent, collectionPathParam string) error {
+	if e.Auth == nil {
+		return e.UnauthorizedError("The request requires valid record authorization token.", nil)
+	}
+
+	if collectionPathParam == "" {
+		collectionPathParam = "collection"
+	}
+
+	collection, _ := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue(collectionPathParam))
+	if collection == nil || e.Auth.Collection().Id != collection.Id {
+		return e.ForbiddenError(fmt.Sprintf("The request requires auth record from %s collection.", e.Auth.Collection().Name), nil)
+	}
+
+	if hasScopedAPIKey(e) {
+		return e.ForbiddenError("The request cannot be performed with a scoped API key.", nil)
+	}
+
+	return nil
+}
+
+// hasScopedAPIKey reports whether the request is authenticated with a scoped API key
+// (aka. restricted only to its allowed collections records actions).
+func hasScopedAPIKey(e *core.RequestEvent) bool {
+	apiKey := e.APIKey()
+
+	return apiKey != nil && len(apiKey.Scopes()) > 0
+}
+
 // loadAuthToken attempts to load the auth context based on the "Authorization: TOKEN" header value.
 //
 // This middleware does nothing in case of:
@@ -193,6 +230,19 @@ func loadAuthToken() *hook.Handler[*core.RequestEvent] {
 				return e.Next()
 			}
 
+			// long-lived API key
+			if strings.HasPrefix(token, core.APIKeyPrefix) {
+				record, apiKey, err := e.App.FindAuthRecordByAPIKey(token)
+				if err != nil {
+					e.App.Logger().Debug("loadAuthToken API key failure", "error", err)
+				} else if record != nil {
+					e.Auth = record
+					e.Set(core.RequestEventKeyAPIKey, apiKey)
+				}
+
+				return e.Next()
+			}
+
 			record, err := e.App.FindAuthRecordByToken(token, core.TokenTypeAuth)
 			if err != nil {
 				e.App.Logger().Debug("loadAuthToken failure", "error", err)
@@ -132,6 +132,13 @@ func isClientRateLimited(e *core.RequestEvent, rtId string) bool {
 
 // @todo consider exporting as helper?
 func checkRateLimit(e *core.RequestEvent, rtId string, rule core.RateLimitRule) error {
+	return checkRateLimitByKey(e, rtId, e.RealIP(), rule)
+}
+
+// checkRateLimitByKey is similar to checkRateLimit but tracks the
+// requests of the specified client key instead of the request IP
+// (e.g. to apply the limit per auth record regardless of the client IP).
+func checkRateLimitByKey(e *core.RequestEvent, rtId string, key string, rule core.RateLimitRule) error {
 	switch rule.Audience {
 	case core.RateLimitRuleAudienceAll:
 		// valid for both guest and regular users
@@ -161,7 +168,6 @@ func checkRateLimit(e *core.RequestEvent, rtId string, rule core.RateLimitRule)
 		return nil
 	}
 
-	key := e.RealIP()
 	if key == "" {
 		e.App.Logger().Warn("Empty rate limit client key")
 		return nil
@@ -1,13 +1,17 @@
 package apis
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -28,11 +32,21 @@ const clientsChunkSize = 150
 // RealtimeClientAuthKey is the name of the realtime client store key that holds its auth state.
 const RealtimeClientAuthKey = "auth"
 
+// RealtimeClientAPIKeyKey is the name of the realtime client store key that holds
+// the API key used for the client authentication (if any).
+const RealtimeClientAPIKeyKey = "apiKey"
+
+// RealtimeClientLastEventIdKey is the name of the realtime client store key that holds
+// the Last-Event-ID of a resumed connection until its missed messages are replayed.
+const RealtimeClientLastEventIdKey = "lastEventId"
+
 // bindRealtimeApi registers the realtime api endpoints.
 func bindRealtimeApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	sub := rg.Group("/realtime")
 	sub.GET("", realtimeConnect).Bind(SkipSuccessActivityLog())
 	sub.POST("", realtimeSetSubscriptions)
+	sub.GET("/ws", realtimeWebSocketConnect).Bind(SkipSuccessActivityLog())
+	sub.POST("/publish", realtimePublish)
 
 	bindRealtimeEvents(app)
 }
@@ -66,11 +80,22 @@ func realtimeConnect(e *core.RequestEvent) error {
 	connectEvent.Client = subscriptions.NewDefaultClient()
 	connectEvent.IdleTimeout = 5 * time.Minute
 
+	// the id of the last delivered message used as SSE event id
+	// (on reconnect the browser sends it back with the Last-Event-ID header)
+	historyEnabled := e.App.SubscriptionsBroker().HistorySize() > 0
+	lastEventId, isResumed := realtimeResumeEventId(e.App, e.Request.Header.Get("Last-Event-ID"))
+
 	return e.App.OnRealtimeConnectRequest().Trigger(connectEvent, func(ce *core.RealtimeConnectRequestEvent) error {
+		// store the resume point so that the missed messages can be
+		// replayed once the client submits its subscriptions
+		if isResumed {
+			ce.Client.Set(RealtimeClientLastEventIdKey, lastEventId)
+		}
+
 		// register new subscription client
 		ce.App.SubscriptionsBroker().Register(ce.Client)
 		defer func() {
-			e.App.SubscriptionsBroker().Unregister(ce.Client.Id())
+			realtimeUnregisterClient(e.App, ce.Client)
 		}()
 
 		ce.App.Logger().Debug("Realtime connection established.", slog.String("clientId", ce.Client.Id()))
@@ -84,10 +109,21 @@ func realtimeConnect(e *core.RequestEvent) error {
 			Data: []byte(`{"clientId":"` + ce.Client.Id() + `"}`),
 		}
 		connectMsgErr := ce.App.OnRealtimeMessageSend().Trigger(connectMsgEvent, func(me *core.RealtimeMessageEvent) error {
+			// note: the PB_CONNECT event id is the client id for backward compatibility with the SDKs
 			err := me.Message.WriteSSE(me.Response, me.Client.Id())
 			if err != nil {
 				return err
 			}
+
+			// write an "id only" SSE block to set the initial Last-Event-ID
+			// without dispatching an actual event to the client
+			if historyEnabled {
+				_, err = me.Response.Write([]byte("id:" + strconv.FormatUint(lastEventId, 10) + "\n\n"))
+				if err != nil {
+					return err
+				}
+			}
+
 			return me.Flush()
 		})
 		if connectMsgErr != nil {
@@ -122,7 +158,15 @@ func realtimeConnect(e *core.RequestEvent) error {
 				msgEvent.Client = ce.Client
 				msgEvent.Message = &msg
 				msgErr := ce.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
-					err := me.Message.WriteSSE(me.Response, me.Client.Id())
+					eventId := me.Client.Id()
+					if historyEnabled {
+						if me.Message.Id > 0 {
+							lastEventId = me.Message.Id
+						}
+						eventId = strconv.FormatUint(lastEventId, 10)
+					}
+
+					err := me.Message.WriteSSE(me.Response, eventId)
 					if err != nil {
 						return err
 					}
@@ -186,6 +230,22 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 		return e.NotFoundError("Missing or invalid client id.", err)
 	}
 
+	return realtimeSubscribe(e, client, form.Subscriptions, func(e *core.RealtimeSubscribeRequestEvent) error {
+		return execAfterSuccessTx(true, e.App, func() error {
+			return e.NoContent(http.StatusNoContent)
+		})
+	})
+}
+
+// realtimeSubscribe replaces the client subscriptions with the provided ones
+// (triggering the OnRealtimeSubscribeRequest hook) and calls onSuccess
+// at the end of the default hook handler to write the response.
+func realtimeSubscribe(
+	e *core.RequestEvent,
+	client subscriptions.Client,
+	subs []string,
+	onSuccess func(e *core.RealtimeSubscribeRequestEvent) error,
+) error {
 	// for now allow only guest->auth upgrades and any other auth change is forbidden
 	clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
 	if clientAuth != nil && !isSameAuth(clientAuth, e.Auth) {
@@ -195,11 +255,31 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 	event := new(core.RealtimeSubscribeRequestEvent)
 	event.RequestEvent = e
 	event.Client = client
-	event.Subscriptions = form.Subscriptions
+	event.Subscriptions = subs
 
 	return e.App.OnRealtimeSubscribeRequest().Trigger(event, func(e *core.RealtimeSubscribeRequestEvent) error {
+		// buffer the live record messages of a resumed connection until
+		// its missed messages are replayed so that the client Last-Event-ID
+		// can't move past a message that is not replayed yet
+		lastEventId, isResumed := e.Client.Get(RealtimeClientLastEventIdKey).(uint64)
+		var replayBuffer *realtimeReplayBuffer
+		if isResumed {
+			e.Client.Unset(RealtimeClientLastEventIdKey)
+
+			replayBuffer = &realtimeReplayBuffer{}
+			e.Client.Set(realtimeClientReplayBufferKey, replayBuffer)
+		}
+
 		// update auth state
 		e.Client.Set(RealtimeClientAuthKey, e.Auth)
+		if apiKey := e.APIKey(); apiKey != nil {
+			e.Client.Set(RealtimeClientAPIKeyKey, apiKey)
+		} else {
+			e.Client.Unset(RealtimeClientAPIKeyKey)
+		}
+
+		oldTopics := realtimePresenceTopics(e.Client)
+		oldSubs := e.Client.Subscriptions()
 
 		// unsubscribe from any previous existing subscriptions
 		e.Client.Unsubscribe()
@@ -207,18 +287,78 @@ func realtimeSetSubscriptions(e *core.RequestEvent) error {
 		// subscribe to the new subscriptions
 		e.Client.Subscribe(e.Subscriptions...)
 
+		// the messages published after this point are delivered with the regular broadcast
+		replayUpToId := e.App.SubscriptionsBroker().LastMessageId()
+
+		realtimePresenceWatchersIndex(e.App).sync(e.Client)
+
+		// notify the presence subscribers for the joined and left topics
+		realtimeNotifyPresenceChanges(e.App, e.Client, oldTopics, realtimePresenceTopics(e.Client))
+
+		// send the current presence state for the newly added presence subscriptions
+		synced := []string{}
+		for sub := range e.Client.Subscriptions() {
+			if _, ok := oldSubs[sub]; ok {
+				continue
+			}
+
+			topic := strings.SplitN(sub, "?", 2)[0]
+			if !strings.HasSuffix(topic, realtimePresenceSuffix) || slices.Contains(synced, topic) {
+				continue
+			}
+			synced = append(synced, topic)
+
+			realtimeSyncPresence(e.App, e.Client, strings.TrimSuffix(topic, realtimePresenceSuffix))
+		}
+
+		// replay the missed messages of a resumed connection (if any)
+		// and then send the live messages received in the meantime
+		if replayBuffer != nil {
+			app := e.App
+			client := e.Client
+			routine.FireAndForget(func() {
+				realtimeReplayMessages(app, client, lastEventId, replayUpToId)
+
+				client.Unset(realtimeClientReplayBufferKey)
+
+				for _, msg := range replayBuffer.flush() {
+					// the older ones were already replayed
+					if msg.Id == 0 || msg.Id > replayUpToId {
+						client.Send(msg)
+					}
+				}
+			})
+		}
+
 		e.App.Logger().Debug(
 			"Realtime subscriptions updated.",
 			slog.String("clientId", e.Client.Id()),
 			slog.Any("subscriptions", e.Subscriptions),
 		)
 
-		return execAfterSuccessTx(true, e.App, func() error {
-			return e.NoContent(http.StatusNoContent)
-		})
+		return onSuccess(e)
 	})
 }
 
+// realtimeResumeEventId returns the id of the last broker message that is considered
+// delivered for a new realtime connection and whether the connection is resumed
+// from the provided rawLastEventId (usually the Last-Event-ID header value).
+//
+// The connection is resumed only if the broker history is enabled and
+// rawLastEventId is a valid id that is not greater than the last published one.
+func realtimeResumeEventId(app core.App, rawLastEventId string) (uint64, bool) {
+	broker := app.SubscriptionsBroker()
+
+	lastEventId := broker.LastMessageId()
+
+	resumeEventId, err := strconv.ParseUint(rawLastEventId, 10, 64)
+	if err != nil || broker.HistorySize() <= 0 || resumeEventId > lastEventId {
+		return lastEventId, false
+	}
+
+	return resumeEventId, true
+}
+
 // updateClientsAuth updates the existing clients auth record with the new one (matched by ID).
 func realtimeUpdateClientsAuth(app core.App, newAuthRecord *core.Record) error {
 	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
@@ -257,6 +397,7 @@ func realtimeUnsetClientsAuthState(app core.App, authModel core.Model) error {
 					clientAuth.Id == authModel.PK() &&
 					clientAuth.Collection().Name == authModel.TableName() {
 					client.Unset(RealtimeClientAuthKey)
+					client.Unset(RealtimeClientAPIKeyKey)
 				}
 			}
 
@@ -268,6 +409,11 @@ func realtimeUnsetClientsAuthState(app core.App, authModel core.Model) error {
 }
 
 func bindRealtimeEvents(app core.App) {
+	// broadcast the events published by the other app instances (if any)
+	app.SubscriptionsBroker().OnFanout(func(event subscriptions.Event) {
+		realtimeHandleFanoutEvent(app, event)
+	})
+
 	// update the clients that has auth record association
 	app.OnModelAfterUpdateSuccess().Bind(&hook.Handler[*core.ModelEvent]{
 		Func: func(e *core.ModelEvent) error {
@@ -322,6 +468,8 @@ func bindRealtimeEvents(app core.App) {
 						slog.String("error", err.Error()),
 					)
 				}
+
+				realtimeFanoutRecord(e.App, "create", record)
 			}
 
 			return e.Next()
@@ -342,6 +490,8 @@ func bindRealtimeEvents(app core.App) {
 						slog.String("error", err.Error()),
 					)
 				}
+
+				realtimeFanoutRecord(e.App, "update", record)
 			}
 
 			return e.Next()
@@ -381,7 +531,21 @@ func bindRealtimeEvents(app core.App) {
 			// custom model it'll fail to resolve since the record is already deleted
 			collection := realtimeResolveRecordCollection(e.App, e.Model)
 			if collection != nil {
-				err := realtimeBroadcastDryCacheKey(e.App, getDryCacheKey("delete", e.Model))
+				var record *core.Record
+				switch m := e.Model.(type) {
+				case *core.Record:
+					record = m
+				case core.RecordProxy:
+					record = m.ProxyRecord()
+				}
+
+				var eventId uint64
+				if record != nil {
+					eventId = realtimePublishRecordEvent(e.App, "delete", record)
+					realtimeFanoutRecord(e.App, "delete", record)
+				}
+
+				err := realtimeBroadcastDryCacheKey(e.App, getDryCacheKey("delete", e.Model), eventId)
 				if err != nil {
 					app.Logger().Debug(
 						"Failed to broadcast record delete",
@@ -490,21 +654,19 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 		return errors.New("[broadcastRecord] Record collection not set")
 	}
 
+	// store the event in the broker history so that it could be replayed on reconnect
+	// (the dry cached messages are published later once the record action is completed)
+	var eventId uint64
+	if !dryCache {
+		eventId = realtimePublishRecordEvent(app, action, record)
+	}
+
 	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
 	if len(chunks) == 0 {
 		return nil // no subscribers
 	}
 
-	subscriptionRuleMap := map[string]*string{
-		(collection.Name + "/" + record.Id + "?"): collection.ViewRule,
-		(collection.Id + "/" + record.Id + "?"):   collection.ViewRule,
-		(collection.Name + "/*?"):                 collection.ListRule,
-		(collection.Id + "/*?"):                   collection.ListRule,
-
-		// @deprecated: the same as the wildcard topic but kept for backward compatibility
-		(collection.Name + "?"): collection.ListRule,
-		(collection.Id + "?"):   collection.ListRule,
-	}
+	subscriptionRuleMap := realtimeSubscriptionRuleMap(collection, record.Id)
 
 	dryCacheKey := getDryCacheKey(action, record)
 
@@ -517,142 +679,327 @@ func realtimeBroadcastRecord(app core.App, action string, record *core.Record, d
 
 	for _, chunk := range chunks {
 		group.Go(func() error {
-			var clientAuth *core.Record
-
 			for _, client := range chunk {
 				// note: not executed concurrently to avoid races and to ensure
 				// that the access checks are applied for the current record db state
-				for prefix, rule := range subscriptionRuleMap {
-					subs := client.Subscriptions(prefix)
-					if len(subs) == 0 {
-						continue
-					}
+				messages := realtimeRecordMessages(app, accessCheckApp, client, subscriptionRuleMap, action, record)
 
-					clientAuth, _ = client.Get(RealtimeClientAuthKey).(*core.Record)
+				for _, msg := range messages {
+					msg.Id = eventId
 
-					for sub, options := range subs {
-						// mock request data
-						requestInfo := &core.RequestInfo{
-							Context: core.RequestInfoContextRealtime,
-							Method:  "GET",
-							Query:   options.Query,
-							Headers: options.Headers,
-							Auth:    clientAuth,
+					if dryCache {
+						cached, ok := client.Get(dryCacheKey).([]subscriptions.Message)
+						if !ok {
+							cached = []subscriptions.Message{msg}
+						} else {
+							cached = append(cached, msg)
 						}
+						client.Set(dryCacheKey, cached)
+					} else {
+						routine.FireAndForget(func() {
+							realtimeSendRecordMessage(client, msg)
+						})
+					}
+				}
+			}
 
-						if !realtimeCanAccessRecord(accessCheckApp, record, requestInfo, rule) {
-							continue
-						}
+			return nil
+		})
+	}
 
-						// create a clean record copy without expand and unknown fields because we don't know yet
-						// which exact fields the client subscription requested or has permissions to access
-						cleanRecord := record.Fresh()
-
-						// trigger the enrich hooks
-						enrichErr := triggerRecordEnrichHooks(app, requestInfo, []*core.Record{cleanRecord}, func() error {
-							// apply expand
-							rawExpand := options.Query[expandQueryParam]
-							if rawExpand != "" {
-								expandErrs := app.ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(app, requestInfo))
-								if len(expandErrs) > 0 {
-									app.Logger().Debug(
-										"[broadcastRecord] expand errors",
-										slog.String("id", cleanRecord.Id),
-										slog.String("collectionName", cleanRecord.Collection().Name),
-										slog.String("sub", sub),
-										slog.String("expand", rawExpand),
-										slog.Any("errors", expandErrs),
-									)
-								}
-							}
-
-							// ignore the auth record email visibility checks
-							// for auth owner, superuser or manager
-							if collection.IsAuth() {
-								if isSameAuth(clientAuth, cleanRecord) ||
-									realtimeCanAccessRecord(accessCheckApp, cleanRecord, requestInfo, collection.ManageRule) {
-									cleanRecord.IgnoreEmailVisibility(true)
-								}
-							}
-
-							return nil
-						})
-						if enrichErr != nil {
-							app.Logger().Debug(
-								"[broadcastRecord] record enrich error",
-								slog.String("id", cleanRecord.Id),
-								slog.String("collectionName", cleanRecord.Collection().Name),
-								slog.String("sub", sub),
-								slog.Any("error", enrichErr),
-							)
-							continue
-						}
+	return group.Wait()
+}
 
-						data := &recordData{
-							Action: action,
-							Record: cleanRecord,
-						}
+// realtimeSubscriptionRuleMap returns the record subscription topic prefixes
+// mapped to their related collection access rule.
+func realtimeSubscriptionRuleMap(collection *core.Collection, recordId string) map[string]*string {
+	return map[string]*string{
+		(collection.Name + "/" + recordId + "?"): collection.ViewRule,
+		(collection.Id + "/" + recordId + "?"):   collection.ViewRule,
+		(collection.Name + "/*?"):                collection.ListRule,
+		(collection.Id + "/*?"):                  collection.ListRule,
 
-						// check fields
-						rawFields := options.Query[fieldsQueryParam]
-						if rawFields != "" {
-							decoded, err := picker.Pick(cleanRecord, rawFields)
-							if err == nil {
-								data.Record = decoded
-							} else {
-								app.Logger().Debug(
-									"[broadcastRecord] pick fields error",
-									slog.String("id", cleanRecord.Id),
-									slog.String("collectionName", cleanRecord.Collection().Name),
-									slog.String("sub", sub),
-									slog.String("fields", rawFields),
-									slog.String("error", err.Error()),
-								)
-							}
-						}
+		// @deprecated: the same as the wildcard topic but kept for backward compatibility
+		(collection.Name + "?"): collection.ListRule,
+		(collection.Id + "?"):   collection.ListRule,
+	}
+}
 
-						dataBytes, err := json.Marshal(data)
-						if err != nil {
-							app.Logger().Debug(
-								"[broadcastRecord] data marshal error",
-								slog.String("id", cleanRecord.Id),
-								slog.String("collectionName", cleanRecord.Collection().Name),
-								slog.String("error", err.Error()),
-							)
-							continue
-						}
+// realtimeRecordMessages returns the record action messages for all client
+// subscriptions matching the subscriptionRuleMap and satisfying their access rule.
+func realtimeRecordMessages(
+	app core.App,
+	accessCheckApp core.App,
+	client subscriptions.Client,
+	subscriptionRuleMap map[string]*string,
+	action string,
+	record *core.Record,
+) []subscriptions.Message {
+	collection := record.Collection()
 
-						msg := subscriptions.Message{
-							Name: sub,
-							Data: dataBytes,
-						}
+	var messages []subscriptions.Message
 
-						if dryCache {
-							messages, ok := client.Get(dryCacheKey).([]subscriptions.Message)
-							if !ok {
-								messages = []subscriptions.Message{msg}
-							} else {
-								messages = append(messages, msg)
-							}
-							client.Set(dryCacheKey, messages)
-						} else {
-							routine.FireAndForget(func() {
-								client.Send(msg)
-							})
-						}
+	for prefix, rule := range subscriptionRuleMap {
+		subs := client.Subscriptions(prefix)
+		if len(subs) == 0 {
+			continue
+		}
+
+		clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+
+		clientAPIKey, _ := client.Get(RealtimeClientAPIKeyKey).(*core.APIKey)
+		if clientAPIKey != nil && !clientAPIKey.Allows(collection, core.APIKeyActionView) {
+			continue
+		}
+
+		for sub, options := range subs {
+			// mock request data
+			requestInfo := &core.RequestInfo{
+				Context: core.RequestInfoContextRealtime,
+				Method:  "GET",
+				Query:   options.Query,
+				Headers: options.Headers,
+				Auth:    clientAuth,
+				APIKey:  clientAPIKey,
+			}
+
+			if !realtimeCanAccessRecord(accessCheckApp, record, requestInfo, rule) {
+				continue
+			}
+
+			// create a clean record copy without expand and unknown fields because we don't know yet
+			// which exact fields the client subscription requested or has permissions to access
+			cleanRecord := record.Fresh()
+
+			// trigger the enrich hooks
+			enrichErr := triggerRecordEnrichHooks(app, requestInfo, []*core.Record{cleanRecord}, func() error {
+				// apply expand
+				rawExpand := options.Query[expandQueryParam]
+				if rawExpand != "" {
+					expandErrs := app.ExpandRecord(cleanRecord, strings.Split(rawExpand, ","), expandFetch(app, requestInfo))
+					if len(expandErrs) > 0 {
+						app.Logger().Debug(
+							"[broadcastRecord] expand errors",
+							slog.String("id", cleanRecord.Id),
+							slog.String("collectionName", cleanRecord.Collection().Name),
+							slog.String("sub", sub),
+							slog.String("expand", rawExpand),
+							slog.Any("errors", expandErrs),
+						)
 					}
 				}
+
+				// ignore the auth record email visibility checks
+				// for auth owner, superuser or manager
+				if collection.IsAuth() {
+					if isSameAuth(clientAuth, cleanRecord) ||
+						realtimeCanAccessRecord(accessCheckApp, cleanRecord, requestInfo, collection.ManageRule) {
+						cleanRecord.IgnoreEmailVisibility(true)
+					}
+				}
+
+				return nil
+			})
+			if enrichErr != nil {
+				app.Logger().Debug(
+					"[broadcastRecord] record enrich error",
+					slog.String("id", cleanRecord.Id),
+					slog.String("collectionName", cleanRecord.Collection().Name),
+					slog.String("sub", sub),
+					slog.Any("error", enrichErr),
+				)
+				continue
 			}
 
-			return nil
-		})
+			data := &recordData{
+				Action: action,
+				Record: cleanRecord,
+			}
+
+			// check fields
+			rawFields := options.Query[fieldsQueryParam]
+			if rawFields != "" {
+				decoded, err := picker.Pick(cleanRecord, rawFields)
+				if err == nil {
+					data.Record = decoded
+				} else {
+					app.Logger().Debug(
+						"[broadcastRecord] pick fields error",
+						slog.String("id", cleanRecord.Id),
+						slog.String("collectionName", cleanRecord.Collection().Name),
+						slog.String("sub", sub),
+						slog.String("fields", rawFields),
+						slog.String("error", err.Error()),
+					)
+				}
+			}
+
+			dataBytes, err := json.Marshal(data)
+			if err != nil {
+				app.Logger().Debug(
+					"[broadcastRecord] data marshal error",
+					slog.String("id", cleanRecord.Id),
+					slog.String("collectionName", cleanRecord.Collection().Name),
+					slog.String("error", err.Error()),
+				)
+				continue
+			}
+
+			messages = append(messages, subscriptions.Message{
+				Name: sub,
+				Data: dataBytes,
+			})
+		}
 	}
 
-	return group.Wait()
+	return messages
+}
+
+// realtimePublishRecordEvent stores the record action event in the
+// subscriptions broker history and returns its assigned event id.
+//
+// The history is opt-in (see [subscriptions.Broker.SetHistorySize]) because the
+// history message data contains the raw record fields data (including the hidden ones).
+// It is never sent directly to the clients (see realtimeReplayMessages).
+func realtimePublishRecordEvent(app core.App, action string, record *core.Record) uint64 {
+	broker := app.SubscriptionsBroker()
+	if broker.HistorySize() <= 0 {
+		return 0
+	}
+
+	raw, err := json.Marshal(record.FieldsData())
+	if err != nil {
+		app.Logger().Debug(
+			"[publishRecordEvent] data marshal error",
+			slog.String("id", record.Id),
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("error", err.Error()),
+		)
+		return 0
+	}
+
+	return broker.Publish(record.Collection().Id, subscriptions.Message{
+		Name: action,
+		Data: raw,
+	})
+}
+
+// realtimeClientReplayBufferKey is the name of the realtime client store key that holds
+// the live record messages received while the missed messages are being replayed.
+const realtimeClientReplayBufferKey = "replayBuffer"
+
+// realtimeReplayBuffer holds the live record messages of a single client
+// until the replay of its missed messages completes.
+type realtimeReplayBuffer struct {
+	messages []subscriptions.Message
+	mu       sync.Mutex
+	flushed  bool
+}
+
+// add appends msg to the buffer.
+//
+// Returns false if the buffer was already flushed.
+func (b *realtimeReplayBuffer) add(msg subscriptions.Message) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.flushed {
+		return false
+	}
+
+	b.messages = append(b.messages, msg)
+
+	return true
+}
+
+// flush marks the buffer as flushed and returns its messages.
+func (b *realtimeReplayBuffer) flush() []subscriptions.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.flushed = true
+
+	return b.messages
 }
 
-// realtimeBroadcastDryCacheKey broadcasts the dry cached key related messages.
-func realtimeBroadcastDryCacheKey(app core.App, key string) error {
+// realtimeSendRecordMessage sends a live record message to the client
+// or buffers it if the client missed messages are still being replayed.
+func realtimeSendRecordMessage(client subscriptions.Client, msg subscriptions.Message) {
+	if buffer, ok := client.Get(realtimeClientReplayBufferKey).(*realtimeReplayBuffer); ok && buffer.add(msg) {
+		return
+	}
+
+	client.Send(msg)
+}
+
+// realtimeReplayMessages sends to the client the record messages
+// from the broker history with id in the range (afterId, upToId]
+// after re-running the subscriptions access checks.
+//
+// Note that the access checks are performed against the current db state,
+// meaning that the delete events are replayed only if the subscription
+// rule doesn't require the deleted record to exist (ex. public or superuser only).
+func realtimeReplayMessages(app core.App, client subscriptions.Client, afterId uint64, upToId uint64) {
+	// resolve the collections of the client subscriptions
+	// (ex. "demo/*", "demo/abc?options=...", "demo")
+	collections := map[string]*core.Collection{}
+	for sub := range client.Subscriptions() {
+		identifier, _, _ := strings.Cut(strings.SplitN(sub, "?", 2)[0], "/")
+
+		collection, err := app.FindCachedCollectionByNameOrId(identifier)
+		if err != nil || collection.IsView() {
+			continue
+		}
+
+		collections[collection.Id] = collection
+	}
+
+	type historyEntry struct {
+		collection *core.Collection
+		message    subscriptions.Message
+	}
+
+	entries := []historyEntry{}
+	for _, collection := range collections {
+		for _, m := range app.SubscriptionsBroker().History(collection.Id, afterId) {
+			if m.Id <= upToId {
+				entries = append(entries, historyEntry{collection, m})
+			}
+		}
+	}
+
+	slices.SortFunc(entries, func(a, b historyEntry) int {
+		return cmp.Compare(a.message.Id, b.message.Id)
+	})
+
+	for _, entry := range entries {
+		data := map[string]any{}
+		if err := json.Unmarshal(entry.message.Data, &data); err != nil {
+			continue
+		}
+
+		record := core.NewRecord(entry.collection)
+		record.Load(data)
+
+		messages := realtimeRecordMessages(
+			app,
+			app,
+			client,
+			realtimeSubscriptionRuleMap(entry.collection, record.Id),
+			entry.message.Name,
+			record,
+		)
+
+		for _, msg := range messages {
+			msg.Id = entry.message.Id
+			client.Send(msg)
+		}
+	}
+}
+
+// realtimeBroadcastDryCacheKey broadcasts the dry cached key related messages
+// (the messages id is set to the provided eventId).
+func realtimeBroadcastDryCacheKey(app core.App, key string, eventId uint64) error {
 	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
 	if len(chunks) == 0 {
 		return nil // no subscribers
@@ -674,7 +1021,8 @@ func realtimeBroadcastDryCacheKey(app core.App, key string) error {
 
 				routine.FireAndForget(func() {
 					for _, msg := range messages {
-						client.Send(msg)
+						msg.Id = eventId
+						realtimeSendRecordMessage(client, msg)
 					}
 				})
 			}
@@ -0,0 +1,214 @@
+package apis
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"golang.org/x/sync/errgroup"
+)
+
+type realtimePublishForm struct {
+	Channel string          `form:"channel" json:"channel"`
+	Data    json.RawMessage `form:"data" json:"data"`
+}
+
+func (form *realtimePublishForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(
+			&form.Channel,
+			validation.Required,
+			validation.Length(1, 255),
+			validation.Match(core.RealtimeChannelNameRegex),
+			validation.By(checkNotPresenceTopic),
+		),
+	)
+}
+
+// checkNotPresenceTopic prevents publishing fake presence messages.
+func checkNotPresenceTopic(value any) error {
+	v, _ := value.(string)
+
+	if strings.HasSuffix(v, realtimePresenceSuffix) {
+		return validation.NewError("validation_presence_channel", "The presence channels are reserved.")
+	}
+
+	return nil
+}
+
+// channelData represents the broadcasted custom channel message data.
+type channelData struct {
+	Channel string `json:"channel"`
+	Data    any    `json:"data"`
+}
+
+func realtimePublish(e *core.RequestEvent) error {
+	form := new(realtimePublishForm)
+
+	err := e.BindBody(form)
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	err = form.validate()
+	if err != nil {
+		return e.BadRequestError("", err)
+	}
+
+	return realtimePublishToChannel(e, nil, form, func(e *core.RealtimePublishRequestEvent) error {
+		return e.NoContent(http.StatusNoContent)
+	})
+}
+
+// realtimePublishToChannel checks the channel publish rule, triggers the
+// OnRealtimePublishRequest hook and broadcasts the form data to the channel subscribers.
+//
+// optClient is the publisher realtime client and it is nil when publishing with the HTTP API.
+func realtimePublishToChannel(
+	e *core.RequestEvent,
+	optClient subscriptions.Client,
+	form *realtimePublishForm,
+	onSuccess func(e *core.RealtimePublishRequestEvent) error,
+) error {
+	channel, ok := e.App.Settings().Realtime.FindChannel(form.Channel)
+	if !ok {
+		return e.NotFoundError("Missing or invalid realtime channel.", nil)
+	}
+
+	// the scoped API keys are restricted only to their allowed collections records actions
+	if hasScopedAPIKey(e) {
+		return e.ForbiddenError("The request cannot be performed with a scoped API key.", nil)
+	}
+
+	// for consistency with the subscriptions only the client auth could be used
+	if optClient != nil {
+		clientAuth, _ := optClient.Get(RealtimeClientAuthKey).(*core.Record)
+		if clientAuth != nil && !isSameAuth(clientAuth, e.Auth) {
+			return e.ForbiddenError("The current and the previous request authorization don't match.", nil)
+		}
+	}
+
+	var data any
+	if len(form.Data) > 0 {
+		if err := json.Unmarshal(form.Data, &data); err != nil {
+			return e.BadRequestError("Invalid channel message data.", err)
+		}
+	}
+
+	event := new(core.RealtimePublishRequestEvent)
+	event.RequestEvent = e
+	event.Client = optClient
+	event.Channel = form.Channel
+	event.Data = data
+
+	return e.App.OnRealtimePublishRequest().Trigger(event, func(e *core.RealtimePublishRequestEvent) error {
+		info, err := e.RequestInfo()
+		if err != nil {
+			return e.BadRequestError("", err)
+		}
+
+		body := map[string]any{
+			"channel": e.Channel,
+			"data":    e.Data,
+		}
+
+		requestInfo := &core.RequestInfo{
+			Context: core.RequestInfoContextRealtime,
+			Method:  http.MethodPost,
+			Query:   info.Query,
+			Headers: info.Headers,
+			Body:    body,
+			Auth:    e.Auth,
+		}
+
+		canPublish, _ := e.App.CanAccessRealtimeChannel(requestInfo, channel.PublishRule)
+		if !canPublish {
+			return e.ForbiddenError("You are not allowed to publish to this channel.", nil)
+		}
+
+		err = realtimeBroadcastChannelMessage(e.App, channel, e.Channel, e.Data)
+		if err != nil {
+			return e.InternalServerError("Failed to broadcast the channel message.", err)
+		}
+
+		realtimeFanoutChannelMessage(e.App, e.Channel, e.Data)
+
+		e.App.Logger().Debug(
+			"Realtime channel message published.",
+			slog.String("channel", e.Channel),
+		)
+
+		return onSuccess(e)
+	})
+}
+
+// realtimeBroadcastChannelMessage sends the channel message to all
+// clients subscribed to channelName that satisfy the channel subscribe rule.
+func realtimeBroadcastChannelMessage(app core.App, channel core.RealtimeChannel, channelName string, data any) error {
+	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
+	if len(chunks) == 0 {
+		return nil // no subscribers
+	}
+
+	dataBytes, err := json.Marshal(&channelData{
+		Channel: channelName,
+		Data:    data,
+	})
+	if err != nil {
+		return err
+	}
+
+	body := map[string]any{
+		"channel": channelName,
+		"data":    data,
+	}
+
+	group := new(errgroup.Group)
+
+	for _, chunk := range chunks {
+		group.Go(func() error {
+			for _, client := range chunk {
+				subs := client.Subscriptions(channelName + "?")
+				if len(subs) == 0 {
+					continue
+				}
+
+				clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+
+				for sub, options := range subs {
+					// mock request data
+					requestInfo := &core.RequestInfo{
+						Context: core.RequestInfoContextRealtime,
+						Method:  http.MethodGet,
+						Query:   options.Query,
+						Headers: options.Headers,
+						Body:    body,
+						Auth:    clientAuth,
+					}
+
+					if ok, _ := app.CanAccessRealtimeChannel(requestInfo, channel.SubscribeRule); !ok {
+						continue
+					}
+
+					msg := subscriptions.Message{
+						Name: sub,
+						Data: dataBytes,
+					}
+
+					routine.FireAndForget(func() {
+						client.Send(msg)
+					})
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
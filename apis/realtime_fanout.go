@@ -0,0 +1,266 @@
+package apis
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"golang.org/x/sync/errgroup"
+)
+
+// realtime fanout event types
+const (
+	realtimeFanoutTypeRecord  = "record"
+	realtimeFanoutTypeChannel = "channel"
+)
+
+// realtimeFanoutRecordData represents the fanout record event data.
+//
+// Note that similar to the local realtime messages the record data
+// doesn't contain the hidden fields (password, tokenKey, etc.).
+type realtimeFanoutRecordData struct {
+	Action       string          `json:"action"`
+	CollectionId string          `json:"collectionId"`
+	Record       json.RawMessage `json:"record"`
+}
+
+// realtimeFanoutRecord sends the record action event to the other
+// app instances (if the subscriptions broker backend supports it).
+func realtimeFanoutRecord(app core.App, action string, record *core.Record) {
+	if _, ok := app.SubscriptionsBroker().Backend().(*subscriptions.MemoryBackend); ok {
+		return // single instance
+	}
+
+	// serialize the record the same way as the local realtime messages
+	// (the email is always exported because its visibility is resolved
+	// per client on the receiving instance)
+	raw, err := json.Marshal(record.Fresh().IgnoreEmailVisibility(true))
+	if err != nil {
+		app.Logger().Debug(
+			"[fanoutRecord] record marshal error",
+			slog.String("id", record.Id),
+			slog.String("collectionName", record.Collection().Name),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	realtimeFanout(app, realtimeFanoutTypeRecord, &realtimeFanoutRecordData{
+		Action:       action,
+		CollectionId: record.Collection().Id,
+		Record:       raw,
+	})
+}
+
+// realtimeFanoutChannelMessage sends the custom channel message to the other
+// app instances (if the subscriptions broker backend supports it).
+func realtimeFanoutChannelMessage(app core.App, channelName string, data any) {
+	if _, ok := app.SubscriptionsBroker().Backend().(*subscriptions.MemoryBackend); ok {
+		return // single instance
+	}
+
+	realtimeFanout(app, realtimeFanoutTypeChannel, &channelData{
+		Channel: channelName,
+		Data:    data,
+	})
+}
+
+func realtimeFanout(app core.App, eventType string, data any) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		app.Logger().Debug(
+			"Failed to marshal the realtime fanout event data",
+			slog.String("type", eventType),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	event := subscriptions.Event{
+		Type: eventType,
+		Data: raw,
+	}
+
+	// the transport could be slow so don't block the caller
+	routine.FireAndForget(func() {
+		err := app.SubscriptionsBroker().Fanout(event)
+		if err != nil {
+			app.Logger().Warn(
+				"Failed to fanout realtime event",
+				slog.String("type", eventType),
+				slog.String("error", err.Error()),
+			)
+		}
+	})
+}
+
+// realtimeHandleFanoutEvent broadcasts the event received from
+// another app instance to the current instance clients.
+func realtimeHandleFanoutEvent(app core.App, event subscriptions.Event) {
+	var err error
+
+	switch event.Type {
+	case realtimeFanoutTypeRecord:
+		err = realtimeHandleFanoutRecord(app, event.Data)
+	case realtimeFanoutTypeChannel:
+		err = realtimeHandleFanoutChannelMessage(app, event.Data)
+	default:
+		return // unknown event
+	}
+
+	if err != nil {
+		app.Logger().Debug(
+			"Failed to handle realtime fanout event",
+			slog.String("type", event.Type),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func realtimeHandleFanoutRecord(app core.App, rawData []byte) error {
+	data := &realtimeFanoutRecordData{}
+	if err := json.Unmarshal(rawData, data); err != nil {
+		return err
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(data.Record, &fields); err != nil {
+		return err
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(data.CollectionId)
+	if err != nil {
+		return err
+	}
+
+	// note: load the raw field values because record.Set ignores
+	// the values of some of the fields (e.g. autodate)
+	record := core.NewRecord(collection)
+	for _, field := range collection.Fields {
+		v, ok := fields[field.GetName()]
+		if !ok {
+			continue
+		}
+
+		prepared, err := field.PrepareValue(record, v)
+		if err != nil {
+			return err
+		}
+		record.SetRaw(field.GetName(), prepared)
+	}
+
+	// sync the auth state of the current instance clients
+	if collection.IsAuth() {
+		switch data.Action {
+		case "update":
+			err = realtimeMergeClientsAuth(app, record)
+		case "delete":
+			err = realtimeUnsetClientsAuthState(app, record)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if data.Action == "delete" {
+		return realtimeBroadcastFanoutDelete(app, record)
+	}
+
+	return realtimeBroadcastRecord(app, data.Action, record, false)
+}
+
+// errRealtimeFanoutRollback is used to rollback the fanout delete access checks transaction.
+var errRealtimeFanoutRollback = errors.New("realtime fanout rollback")
+
+// realtimeBroadcastFanoutDelete broadcasts the delete event of a record
+// deleted by another app instance.
+//
+// Because the access checks are performed against the db state and the
+// record is already deleted, they are executed in a transaction that
+// temporary restores the record row and it is always rolled back.
+//
+// Note that the hidden fields of the restored row are not available
+// and are set to their zero values.
+func realtimeBroadcastFanoutDelete(app core.App, record *core.Record) error {
+	err := app.RunInTransaction(func(txApp core.App) error {
+		_, findErr := txApp.FindRecordById(record.Collection(), record.Id)
+		if errors.Is(findErr, sql.ErrNoRows) {
+			row, err := record.DBExport(txApp)
+			if err != nil {
+				return err
+			}
+
+			_, err = txApp.DB().Insert(record.Collection().Name, row).Execute()
+			if err != nil {
+				return err
+			}
+		} else if findErr != nil {
+			return findErr
+		}
+
+		err := realtimeBroadcastRecord(app, "delete", record, false, txApp)
+		if err != nil {
+			return err
+		}
+
+		return errRealtimeFanoutRollback
+	})
+	if errors.Is(err, errRealtimeFanoutRollback) {
+		return nil
+	}
+
+	return err
+}
+
+// realtimeMergeClientsAuth updates the auth state of all clients that
+// have the provided auth record with the fanout record data.
+//
+// The hidden fields of the current client auth record are preserved
+// because they are not part of the fanout record data.
+func realtimeMergeClientsAuth(app core.App, newAuthRecord *core.Record) error {
+	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
+
+	group := new(errgroup.Group)
+
+	for _, chunk := range chunks {
+		group.Go(func() error {
+			for _, client := range chunk {
+				clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+				if clientAuth != nil &&
+					clientAuth.Id == newAuthRecord.Id &&
+					clientAuth.Collection().Name == newAuthRecord.Collection().Name {
+					merged := clientAuth.Fresh()
+					for _, field := range merged.Collection().Fields {
+						if field.GetHidden() {
+							continue
+						}
+						merged.SetRaw(field.GetName(), newAuthRecord.GetRaw(field.GetName()))
+					}
+					client.Set(RealtimeClientAuthKey, merged)
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+func realtimeHandleFanoutChannelMessage(app core.App, rawData []byte) error {
+	data := &channelData{}
+	if err := json.Unmarshal(rawData, data); err != nil {
+		return err
+	}
+
+	channel, ok := app.Settings().Realtime.FindChannel(data.Channel)
+	if !ok {
+		return nil // not configured for the current instance
+	}
+
+	return realtimeBroadcastChannelMessage(app, channel, data.Channel, data.Data)
+}
@@ -0,0 +1,268 @@
+package apis_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// fanoutTestTransport is a simple in-memory transport for connecting multiple broker backends.
+type fanoutTestTransport struct {
+	mux      *sync.Mutex
+	handlers *[]func(payload []byte)
+}
+
+func newFanoutTestTransports(total int) []subscriptions.Transport {
+	mux := &sync.Mutex{}
+	handlers := &[]func(payload []byte){}
+
+	result := make([]subscriptions.Transport, total)
+	for i := range result {
+		result[i] = &fanoutTestTransport{mux: mux, handlers: handlers}
+	}
+
+	return result
+}
+
+func (t *fanoutTestTransport) Publish(payload []byte) error {
+	t.mux.Lock()
+	handlers := *t.handlers
+	t.mux.Unlock()
+
+	for _, h := range handlers {
+		h(payload)
+	}
+
+	return nil
+}
+
+func (t *fanoutTestTransport) Subscribe(handler func(payload []byte)) error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	*t.handlers = append(*t.handlers, handler)
+
+	return nil
+}
+
+func (t *fanoutTestTransport) Close() error {
+	return nil
+}
+
+func TestRealtimeFanout(t *testing.T) {
+	// mock 2 app instances sharing the same transport
+	app1, _ := tests.NewTestApp()
+	defer app1.Cleanup()
+
+	app2, _ := tests.NewTestApp()
+	defer app2.Cleanup()
+
+	var app1Router http.Handler
+
+	transports := newFanoutTestTransports(2)
+
+	var payloadsMu sync.Mutex
+	var payloads []string
+	transports[0].Subscribe(func(payload []byte) {
+		payloadsMu.Lock()
+		payloads = append(payloads, string(payload))
+		payloadsMu.Unlock()
+	})
+	for i, app := range []*tests.TestApp{app1, app2} {
+		backend, err := subscriptions.NewTransportBackend(transports[i], "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		app.SubscriptionsBroker().SetBackend(backend)
+
+		app.Settings().Realtime.Channels = []core.RealtimeChannel{
+			{Name: "public", SubscribeRule: types.Pointer(""), PublishRule: types.Pointer("")},
+		}
+		if err := app.Save(app.Settings()); err != nil {
+			t.Fatal(err)
+		}
+
+		// require the record to exist in order to check that the deleted records are restored for the access checks
+		demo2, err := app.FindCollectionByNameOrId("demo2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		demo2.ListRule = types.Pointer(`title != ""`)
+		if err := app.Save(demo2); err != nil {
+			t.Fatal(err)
+		}
+
+		// bind the realtime record events
+		pbRouter, err := apis.NewRouter(app)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i == 0 {
+			app1Router, err = pbRouter.BuildMux()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	user, err := app2.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	received := map[string][]subscriptions.Message{}
+
+	registerClient := func(app *tests.TestApp, auth *core.Record, subs ...string) *subscriptions.DefaultClient {
+		client := subscriptions.NewDefaultClient()
+		client.Subscribe(subs...)
+		if auth != nil {
+			client.Set(apis.RealtimeClientAuthKey, auth)
+		}
+		app.SubscriptionsBroker().Register(client)
+
+		go func() {
+			for m := range client.Channel() {
+				mu.Lock()
+				received[client.Id()] = append(received[client.Id()], m)
+				mu.Unlock()
+			}
+		}()
+
+		return client
+	}
+
+	client1 := registerClient(app1, nil, "demo2/*")
+	defer client1.Discard()
+
+	// connected to the second instance
+	client2 := registerClient(app2, nil, "demo2/*", "demo1/*", "public")
+	defer client2.Discard()
+	client3 := registerClient(app2, user, "users/"+user.Id)
+	defer client3.Discard()
+
+	// record event
+	demo2Record, err := app1.FindRecordById("demo2", "llvuca81nly1qls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	demo2Record.Set("title", "fanout_test")
+	if err := app1.Save(demo2Record); err != nil {
+		t.Fatal(err)
+	}
+
+	// superusers only (the remote instance still performs the access checks)
+	demo1Record, err := app1.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app1.Save(demo1Record); err != nil {
+		t.Fatal(err)
+	}
+
+	// auth record update event
+	userCopy, err := app1.FindRecordById("users", user.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userCopy.Set("name", "fanout_test_name")
+	if err := app1.Save(userCopy); err != nil {
+		t.Fatal(err)
+	}
+
+	// delete event of a record that no longer exists in the db of the remote instance
+	demo2Deleted, err := app1.FindRecordById("demo2", "0yxhwia2amd8gec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = app2.DB().Delete("demo2", dbx.HashExp{"id": demo2Deleted.Id}).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := app1.Delete(demo2Deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// channel message
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/realtime/publish", strings.NewReader(`{"channel":"public","data":{"a":1}}`))
+	req.Header.Set("Content-Type", "application/json")
+	app1Router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected publish status 204, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	scenarios := []struct {
+		name     string
+		client   *subscriptions.DefaultClient
+		expected []string
+	}{
+		{"local client", client1, []string{`"action":"update"`, `"title":"fanout_test"`}},
+		{"remote guest client dates", client2, []string{`"created":"2022-10-12 11:42:51.509Z"`}},
+		{"remote guest client", client2, []string{`"title":"fanout_test"`, `"action":"delete"`, `"id":"0yxhwia2amd8gec"`, `"channel":"public","data":{"a":1}`}},
+		{"remote auth client", client3, []string{`"name":"fanout_test_name"`}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			var data []string
+			for _, m := range received[s.client.Id()] {
+				data = append(data, string(m.Data))
+			}
+			all := strings.Join(data, "\n")
+
+			for _, str := range s.expected {
+				if !strings.Contains(all, str) {
+					t.Fatalf("Missing %q in the received messages:\n%s", str, all)
+				}
+			}
+
+			if strings.Contains(all, `"collectionName":"demo1"`) {
+				t.Fatalf("Unexpected demo1 message in the received messages:\n%s", all)
+			}
+		})
+	}
+
+	// the auth state of the remote instance client should be updated
+	// (while preserving its hidden fields)
+	clientAuth, _ := client3.Get(apis.RealtimeClientAuthKey).(*core.Record)
+	if clientAuth == nil || clientAuth.GetString("name") != "fanout_test_name" {
+		t.Fatalf("Expected the client auth state to be updated, got %v", clientAuth)
+	}
+	if clientAuth.TokenKey() == "" || clientAuth.TokenKey() != user.TokenKey() {
+		t.Fatalf("Expected the client auth tokenKey to be preserved, got %q", clientAuth.TokenKey())
+	}
+
+	// the local client shouldn't receive duplicated messages
+	if total := len(received[client1.Id()]); total != 2 {
+		t.Fatalf("Expected 2 messages for the local client, got %d", total)
+	}
+
+	// the fanout payloads shouldn't contain the record hidden fields
+	payloadsMu.Lock()
+	defer payloadsMu.Unlock()
+	if len(payloads) == 0 {
+		t.Fatal("Expected at least 1 fanout payload")
+	}
+	for _, p := range payloads {
+		if strings.Contains(p, "tokenKey") || strings.Contains(p, "password") {
+			t.Fatalf("Unexpected hidden field in the fanout payload:\n%s", p)
+		}
+	}
+}
@@ -0,0 +1,389 @@
+package apis
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"golang.org/x/sync/errgroup"
+)
+
+// realtimePresenceSuffix is the topic suffix of the presence subscriptions
+// (eg. "demo/RECORD_ID/presence", "chat/room1/presence").
+const realtimePresenceSuffix = "/presence"
+
+// realtime presence message actions
+const (
+	realtimePresenceActionJoin  = "join"
+	realtimePresenceActionLeave = "leave"
+	realtimePresenceActionSync  = "sync"
+)
+
+// presenceData represents the broadcasted presence message data.
+type presenceData struct {
+	Action string `json:"action"`
+	Topic  string `json:"topic"`
+
+	// Auth is the joined/left client auth state (nil for guests and "sync" messages).
+	Auth *presenceAuth `json:"auth"`
+
+	// Total is the number of all connected clients subscribed to the topic (including guests).
+	Total int `json:"total"`
+
+	// Presence is the unique list of the topic auth subscribers.
+	Presence []*presenceAuth `json:"presence"`
+}
+
+// presenceAuth is the public presence representation of a client auth record.
+//
+// Note: the client ids are never exposed because they could be used
+// to hijack the subscriptions of another client.
+type presenceAuth struct {
+	Id             string `json:"id"`
+	CollectionId   string `json:"collectionId"`
+	CollectionName string `json:"collectionName"`
+}
+
+func newPresenceAuth(client subscriptions.Client) *presenceAuth {
+	auth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+	if auth == nil {
+		return nil
+	}
+
+	return &presenceAuth{
+		Id:             auth.Id,
+		CollectionId:   auth.Collection().Id,
+		CollectionName: auth.Collection().Name,
+	}
+}
+
+// realtimePresenceTopics returns the sorted unique presence tracked topics
+// of the client subscriptions (without the subscription options).
+func realtimePresenceTopics(client subscriptions.Client) []string {
+	topics := []string{}
+
+	for sub := range client.Subscriptions() {
+		topic := strings.SplitN(sub, "?", 2)[0]
+
+		if strings.HasSuffix(topic, realtimePresenceSuffix) || slices.Contains(topics, topic) {
+			continue
+		}
+
+		topics = append(topics, topic)
+	}
+
+	slices.Sort(topics)
+
+	return topics
+}
+
+// realtimeUnregisterClient unregisters the client from the app
+// subscriptions broker and notifies for the left topics (if any).
+func realtimeUnregisterClient(app core.App, client subscriptions.Client) {
+	topics := realtimePresenceTopics(client)
+
+	app.SubscriptionsBroker().Unregister(client.Id())
+
+	realtimePresenceWatchersIndex(app).remove(client.Id())
+
+	realtimeNotifyPresenceChanges(app, client, topics, nil)
+}
+
+// realtimeNotifyPresenceChanges triggers the OnRealtimePresenceChange hook
+// for each joined (in newTopics but not in oldTopics) and
+// left (in oldTopics but not in newTopics) client topic.
+func realtimeNotifyPresenceChanges(app core.App, client subscriptions.Client, oldTopics []string, newTopics []string) {
+	watchers := realtimePresenceWatchersIndex(app)
+
+	for _, topic := range oldTopics {
+		if !slices.Contains(newTopics, topic) {
+			realtimeTriggerPresenceChange(app, client, topic, realtimePresenceActionLeave, watchers)
+		}
+	}
+
+	for _, topic := range newTopics {
+		if !slices.Contains(oldTopics, topic) {
+			realtimeTriggerPresenceChange(app, client, topic, realtimePresenceActionJoin, watchers)
+		}
+	}
+}
+
+const realtimePresenceWatchersStoreKey = "__pbRealtimePresenceWatchers__"
+
+// realtimePresenceWatchersIndex returns the app presence watchers index.
+//
+// The index is initialized from the currently registered clients on first use
+// and then it is kept in sync by the realtime subscribe and disconnect handlers.
+func realtimePresenceWatchersIndex(app core.App) *presenceWatchers {
+	return app.Store().GetOrSet(realtimePresenceWatchersStoreKey, func() any {
+		watchers := newPresenceWatchers()
+
+		for _, client := range app.SubscriptionsBroker().Clients() {
+			watchers.sync(client)
+		}
+
+		return watchers
+	}).(*presenceWatchers)
+}
+
+// presenceWatchers is a refcounted index of the topics that
+// have at least one "TOPIC/presence" subscriber.
+//
+// It is used to avoid the unnecessary broadcast of the topics changes
+// that nobody is interested in.
+type presenceWatchers struct {
+	topics  map[string]int      // topic -> number of watching clients
+	clients map[string][]string // client id -> watched topics
+	mu      sync.RWMutex
+}
+
+func newPresenceWatchers() *presenceWatchers {
+	return &presenceWatchers{
+		topics:  map[string]int{},
+		clients: map[string][]string{},
+	}
+}
+
+// sync updates the index with the current client "TOPIC/presence" subscriptions.
+func (w *presenceWatchers) sync(client subscriptions.Client) {
+	watched := []string{}
+	for sub := range client.Subscriptions() {
+		topic := strings.SplitN(sub, "?", 2)[0]
+		if !strings.HasSuffix(topic, realtimePresenceSuffix) {
+			continue
+		}
+
+		topic = strings.TrimSuffix(topic, realtimePresenceSuffix)
+		if !slices.Contains(watched, topic) {
+			watched = append(watched, topic)
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.set(client.Id(), watched)
+}
+
+// remove removes the client from the index.
+func (w *presenceWatchers) remove(clientId string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.set(clientId, nil)
+}
+
+// isWatched reports whether the topic has at least one presence subscriber.
+func (w *presenceWatchers) isWatched(topic string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.topics[topic] > 0
+}
+
+// set replaces the watched topics of a single client.
+//
+// Note: must be called with an acquired write lock.
+func (w *presenceWatchers) set(clientId string, topics []string) {
+	for _, topic := range w.clients[clientId] {
+		w.topics[topic]--
+		if w.topics[topic] <= 0 {
+			delete(w.topics, topic)
+		}
+	}
+
+	if len(topics) == 0 {
+		delete(w.clients, clientId)
+		return
+	}
+
+	w.clients[clientId] = topics
+
+	for _, topic := range topics {
+		w.topics[topic]++
+	}
+}
+
+func realtimeTriggerPresenceChange(app core.App, client subscriptions.Client, topic string, action string, watchers *presenceWatchers) {
+	event := new(core.RealtimePresenceEvent)
+	event.App = app
+	event.Client = client
+	event.Topic = topic
+	event.Action = action
+
+	err := app.OnRealtimePresenceChange().Trigger(event, func(e *core.RealtimePresenceEvent) error {
+		if !watchers.isWatched(e.Topic) {
+			return nil // no presence subscribers
+		}
+
+		return realtimeBroadcastPresence(e.App, e.Topic, e.Action, e.Client)
+	})
+	if err != nil {
+		app.Logger().Debug(
+			"Realtime presence change failed.",
+			slog.String("clientId", client.Id()),
+			slog.String("topic", topic),
+			slog.String("action", action),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// realtimeBroadcastPresence sends a presence message to all clients
+// subscribed to the "TOPIC/presence" topic that are allowed to access it.
+func realtimeBroadcastPresence(app core.App, topic string, action string, changedClient subscriptions.Client) error {
+	chunks := app.SubscriptionsBroker().ChunkedClients(clientsChunkSize)
+	if len(chunks) == 0 {
+		return nil // no subscribers
+	}
+
+	dataBytes, err := realtimePresenceData(app, topic, action, changedClient)
+	if err != nil {
+		return err
+	}
+
+	group := new(errgroup.Group)
+
+	for _, chunk := range chunks {
+		group.Go(func() error {
+			for _, client := range chunk {
+				realtimeSendPresence(app, client, topic, dataBytes)
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// realtimeSyncPresence sends a "sync" presence message with the current topic
+// presence state to the client (usually after a new presence subscription).
+func realtimeSyncPresence(app core.App, client subscriptions.Client, topic string) error {
+	dataBytes, err := realtimePresenceData(app, topic, realtimePresenceActionSync, nil)
+	if err != nil {
+		return err
+	}
+
+	realtimeSendPresence(app, client, topic, dataBytes)
+
+	return nil
+}
+
+func realtimePresenceData(app core.App, topic string, action string, optChangedClient subscriptions.Client) ([]byte, error) {
+	clients := app.SubscriptionsBroker().Presence(topic)
+
+	data := &presenceData{
+		Action:   action,
+		Topic:    topic,
+		Total:    len(clients),
+		Presence: []*presenceAuth{},
+	}
+
+	if optChangedClient != nil {
+		data.Auth = newPresenceAuth(optChangedClient)
+	}
+
+	for _, client := range clients {
+		auth := newPresenceAuth(client)
+		if auth == nil {
+			continue
+		}
+
+		exists := slices.ContainsFunc(data.Presence, func(p *presenceAuth) bool {
+			return p.Id == auth.Id && p.CollectionId == auth.CollectionId
+		})
+		if !exists {
+			data.Presence = append(data.Presence, auth)
+		}
+	}
+
+	return json.Marshal(data)
+}
+
+func realtimeSendPresence(app core.App, client subscriptions.Client, topic string, dataBytes []byte) {
+	subs := client.Subscriptions(topic + realtimePresenceSuffix + "?")
+	if len(subs) == 0 {
+		return
+	}
+
+	clientAuth, _ := client.Get(RealtimeClientAuthKey).(*core.Record)
+
+	for sub, options := range subs {
+		// mock request data
+		requestInfo := &core.RequestInfo{
+			Context: core.RequestInfoContextRealtime,
+			Method:  http.MethodGet,
+			Query:   options.Query,
+			Headers: options.Headers,
+			Auth:    clientAuth,
+		}
+
+		if !realtimeCanAccessPresence(app, requestInfo, topic) {
+			continue
+		}
+
+		msg := subscriptions.Message{
+			Name: sub,
+			Data: dataBytes,
+		}
+
+		routine.FireAndForget(func() {
+			client.Send(msg)
+		})
+	}
+}
+
+// realtimeCanAccessPresence checks whether the presence of the specified topic
+// could be accessed with the provided request info.
+//
+// The presence of:
+//   - a custom channel requires the channel subscribe rule
+//   - a single record ("COLLECTION/RECORD_ID") requires the collection view rule
+//   - a collection ("COLLECTION/*") requires a public collection list rule
+//
+// All other topics are accessible only by superusers.
+func realtimeCanAccessPresence(app core.App, requestInfo *core.RequestInfo, topic string) bool {
+	if requestInfo.HasSuperuserAuth() {
+		return true
+	}
+
+	if channel, ok := app.Settings().Realtime.FindChannel(topic); ok {
+		requestInfo.Body = map[string]any{"channel": topic}
+
+		canAccess, _ := app.CanAccessRealtimeChannel(requestInfo, channel.SubscribeRule)
+
+		return canAccess
+	}
+
+	collectionNameOrId, recordId, ok := strings.Cut(topic, "/")
+	if !ok || recordId == "" {
+		return false
+	}
+
+	collection, err := app.FindCachedCollectionByNameOrId(collectionNameOrId)
+	if err != nil {
+		return false
+	}
+
+	// the list rule could be checked only for the public collections
+	// because the collection subscribers could be filtered individually
+	if recordId == "*" {
+		return collection.ListRule != nil && *collection.ListRule == ""
+	}
+
+	record, err := app.FindRecordById(collection, recordId)
+	if err != nil {
+		return false
+	}
+
+	canAccess, _ := app.CanAccessRecord(record, requestInfo, collection.ViewRule)
+
+	return canAccess
+}
@@ -43,6 +43,87 @@ func TestRealtimeConnect(t *testing.T) {
 				}
 			},
 		},
+		{
+			Name:           "resumed connection with valid Last-Event-ID",
+			Method:         http.MethodGet,
+			URL:            "/api/realtime",
+			Timeout:        100 * time.Millisecond,
+			Headers:        map[string]string{"Last-Event-ID": "1"},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`event:PB_CONNECT`,
+				"\nid:1\n\n",
+			},
+			ExpectedEvents: map[string]int{
+				"*":                        0,
+				"OnRealtimeConnectRequest": 1,
+				"OnRealtimeMessageSend":    1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.SubscriptionsBroker().SetHistorySize(100)
+				app.SubscriptionsBroker().Publish("test", subscriptions.Message{Name: "a"})
+				app.SubscriptionsBroker().Publish("test", subscriptions.Message{Name: "b"})
+
+				app.OnRealtimeConnectRequest().BindFunc(func(e *core.RealtimeConnectRequestEvent) error {
+					err := e.Next()
+
+					if v, _ := e.Client.Get(apis.RealtimeClientLastEventIdKey).(uint64); v != 1 {
+						t.Errorf("Expected the client last event id to be 1, got %v", v)
+					}
+					return err
+				})
+			},
+		},
+		{
+			Name:           "resumed connection with invalid Last-Event-ID",
+			Method:         http.MethodGet,
+			URL:            "/api/realtime",
+			Timeout:        100 * time.Millisecond,
+			Headers:        map[string]string{"Last-Event-ID": "100"},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`event:PB_CONNECT`,
+				"\nid:2\n\n",
+			},
+			ExpectedEvents: map[string]int{
+				"*":                        0,
+				"OnRealtimeConnectRequest": 1,
+				"OnRealtimeMessageSend":    1,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.SubscriptionsBroker().SetHistorySize(100)
+				app.SubscriptionsBroker().Publish("test", subscriptions.Message{Name: "a"})
+				app.SubscriptionsBroker().Publish("test", subscriptions.Message{Name: "b"})
+
+				app.OnRealtimeConnectRequest().BindFunc(func(e *core.RealtimeConnectRequestEvent) error {
+					err := e.Next()
+
+					if v := e.Client.Get(apis.RealtimeClientLastEventIdKey); v != nil {
+						t.Errorf("Expected the client last event id to be nil, got %v", v)
+					}
+					return err
+				})
+			},
+		},
+		{
+			Name:           "disabled broker history (default)",
+			Method:         http.MethodGet,
+			URL:            "/api/realtime",
+			Timeout:        100 * time.Millisecond,
+			Headers:        map[string]string{"Last-Event-ID": "0"},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`event:PB_CONNECT`,
+			},
+			NotExpectedContent: []string{
+				"\nid:0\n\n",
+			},
+			ExpectedEvents: map[string]int{
+				"*":                        0,
+				"OnRealtimeConnectRequest": 1,
+				"OnRealtimeMessageSend":    1,
+			},
+		},
 		{
 			Name:           "PB_CONNECT interrupt",
 			Method:         http.MethodGet,
@@ -172,6 +253,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   1001,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				client.Subscribe("test0") // should be replaced
@@ -220,6 +302,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   3,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				client.Subscribe("test0")
@@ -244,6 +327,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   1,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				client.Subscribe("test0")
@@ -265,6 +349,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   3,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				client.Subscribe("test0")
@@ -296,6 +381,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   2,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				app.SubscriptionsBroker().Register(client)
@@ -320,6 +406,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   2,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				app.SubscriptionsBroker().Register(client)
@@ -344,6 +431,7 @@ func TestRealtimeSubscribe(t *testing.T) {
 			ExpectedEvents: map[string]int{
 				"*":                          0,
 				"OnRealtimeSubscribeRequest": 1,
+				"OnRealtimePresenceChange":   2,
 			},
 			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
 				// the same user as the auth token
@@ -424,6 +512,190 @@ func TestRealtimeSubscribe(t *testing.T) {
 	}
 }
 
+func TestRealtimeSubscribeReplay(t *testing.T) {
+	var mu sync.Mutex
+	var messages []subscriptions.Message
+
+	client := subscriptions.NewDefaultClient()
+
+	scenario := tests.ApiScenario{
+		Method:         http.MethodPost,
+		URL:            "/api/realtime",
+		Body:           strings.NewReader(`{"clientId":"` + client.Id() + `","subscriptions":["demo1/*","demo2/*","demo2/llvuca81nly1qls"]}`),
+		ExpectedStatus: 204,
+		ExpectedEvents: map[string]int{
+			"*":                          0,
+			"OnRealtimeSubscribeRequest": 1,
+			"OnRealtimePresenceChange":   3,
+		},
+		BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			app.SubscriptionsBroker().SetHistorySize(100)
+
+			// mock events fired while the client was disconnected
+			for _, ids := range [][2]string{
+				{"demo2", "llvuca81nly1qls"},
+				{"demo1", "84nmscqy84lsi1t"}, // superusers only
+				{"demo2", "achvryl401bhse3"},
+			} {
+				record, err := app.FindRecordById(ids[0], ids[1])
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := app.Save(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// events that the client has already received
+			client.Set(apis.RealtimeClientLastEventIdKey, uint64(1))
+			app.SubscriptionsBroker().Register(client)
+
+			go func() {
+				for m := range client.Channel() {
+					mu.Lock()
+					messages = append(messages, m)
+					mu.Unlock()
+				}
+			}()
+		},
+		AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+			defer client.Discard()
+
+			if v := client.Get(apis.RealtimeClientLastEventIdKey); v != nil {
+				t.Fatalf("Expected the last event id key to be removed, got %v", v)
+			}
+
+			// wait for the replay
+			var total int
+			for i := 0; i < 50; i++ {
+				mu.Lock()
+				total = len(messages)
+				mu.Unlock()
+				if total >= 1 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if len(messages) != 1 {
+				t.Fatalf("Expected 1 replayed message, got %d: %v", len(messages), messages)
+			}
+
+			if messages[0].Name != "demo2/*" || messages[0].Id != 3 {
+				t.Fatalf("Expected demo2/* message with id 3, got %q with id %d", messages[0].Name, messages[0].Id)
+			}
+
+			if !strings.Contains(string(messages[0].Data), `"action":"update"`) ||
+				!strings.Contains(string(messages[0].Data), `"id":"achvryl401bhse3"`) {
+				t.Fatalf("Unexpected message data %s", messages[0].Data)
+			}
+		},
+	}
+
+	scenario.Test(t)
+}
+
+func TestRealtimeSubscribeReplayOrder(t *testing.T) {
+	var mu sync.Mutex
+	var messages []subscriptions.Message
+
+	client := subscriptions.NewDefaultClient()
+
+	startReading := make(chan struct{})
+
+	scenario := tests.ApiScenario{
+		Method:         http.MethodPost,
+		URL:            "/api/realtime",
+		Body:           strings.NewReader(`{"clientId":"` + client.Id() + `","subscriptions":["demo2/*"]}`),
+		ExpectedStatus: 204,
+		ExpectedEvents: map[string]int{
+			"OnRealtimeSubscribeRequest": 1,
+		},
+		BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			app.SubscriptionsBroker().SetHistorySize(100)
+
+			// mock events fired while the client was disconnected
+			for _, id := range []string{"llvuca81nly1qls", "achvryl401bhse3"} {
+				record, err := app.FindRecordById("demo2", id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := app.Save(record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// fire a live event while the replay is still in progress
+			app.OnRealtimeSubscribeRequest().BindFunc(func(e *core.RealtimeSubscribeRequestEvent) error {
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				record, err := e.App.FindRecordById("demo2", "llvuca81nly1qls")
+				if err != nil {
+					return err
+				}
+				if err := e.App.Save(record); err != nil {
+					return err
+				}
+
+				// give some time to the live message to be sent before the client starts reading
+				time.AfterFunc(50*time.Millisecond, func() {
+					close(startReading)
+				})
+
+				return nil
+			})
+
+			client.Set(apis.RealtimeClientLastEventIdKey, uint64(0))
+			app.SubscriptionsBroker().Register(client)
+
+			go func() {
+				<-startReading
+
+				for m := range client.Channel() {
+					mu.Lock()
+					messages = append(messages, m)
+					mu.Unlock()
+				}
+			}()
+		},
+		AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+			defer client.Discard()
+
+			// wait for the replayed and the live messages
+			for i := 0; i < 50; i++ {
+				mu.Lock()
+				total := len(messages)
+				mu.Unlock()
+				if total >= 3 {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			ids := make([]uint64, len(messages))
+			for i, m := range messages {
+				ids[i] = m.Id
+			}
+
+			if !slices.Equal(ids, []uint64{1, 2, 3}) {
+				t.Fatalf("Expected the live message to be sent after the replayed ones, got message ids %v", ids)
+			}
+		},
+	}
+
+	scenario.Test(t)
+}
+
 func TestRealtimeAuthRecordDeleteEvent(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -0,0 +1,362 @@
+package apis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+	"golang.org/x/net/websocket"
+)
+
+// note: large enough to fit the max allowed subscriptions form
+const realtimeWebSocketMaxPayloadBytes = 8 << 20
+
+// realtime WebSocket frame types
+const (
+	realtimeWebSocketTypeSubscribe   = "subscribe"
+	realtimeWebSocketTypeUnsubscribe = "unsubscribe"
+	realtimeWebSocketTypePublish     = "publish"
+	realtimeWebSocketTypeMessage     = "message"
+	realtimeWebSocketTypeAck         = "ack"
+	realtimeWebSocketTypeError       = "error"
+)
+
+// realtimeWebSocketRequest defines a single client->server realtime WebSocket frame.
+//
+// Example:
+//
+//	{"type":"subscribe", "ref":"1", "authorization":"TOKEN", "subscriptions":["demo/*"]}
+//	{"type":"unsubscribe", "ref":"2", "subscriptions":["demo/*"]}
+//	{"type":"publish", "ref":"3", "channel":"chat/room1", "data":{"text":"hello"}}
+type realtimeWebSocketRequest struct {
+	Type          string          `json:"type"`
+	Ref           string          `json:"ref"`
+	Authorization string          `json:"authorization"`
+	Subscriptions []string        `json:"subscriptions"`
+	Channel       string          `json:"channel"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// realtimeWebSocketResponse defines a single server->client realtime WebSocket frame.
+//
+// Example:
+//
+//	{"type":"message", "id":1, "name":"demo/*", "data":{"action":"create","record":{...}}}
+//	{"type":"ack", "ref":"1"}
+//	{"type":"error", "ref":"2", "error":{"status":403,"message":"...","data":{}}}
+type realtimeWebSocketResponse struct {
+	Type  string           `json:"type"`
+	Ref   string           `json:"ref,omitempty"`
+	Id    uint64           `json:"id,omitempty"`
+	Name  string           `json:"name,omitempty"`
+	Data  json.RawMessage  `json:"data,omitempty"`
+	Error *router.ApiError `json:"error,omitempty"`
+}
+
+// realtimeWebSocketConnect handles the WebSocket variant of the realtime connection.
+//
+// It works similarly to the SSE realtimeConnect but instead of a separate
+// POST request, the client subscriptions are submitted over the socket itself
+// (see realtimeWebSocketRequest).
+//
+// Because browsers can't send custom headers with the WebSocket handshake,
+// the subscribe frames could also contain an "authorization" token and
+// the resume point could be specified with the "lastEventId" query parameter.
+func realtimeWebSocketConnect(e *core.RequestEvent) error {
+	if !strings.EqualFold(e.Request.Header.Get("Upgrade"), "websocket") {
+		return e.BadRequestError("Missing or invalid WebSocket upgrade request.", nil)
+	}
+
+	// disable the global read and write deadlines for the WebSocket connection
+	rc := http.NewResponseController(e.Response)
+	for _, setDeadline := range []func(time.Time) error{rc.SetReadDeadline, rc.SetWriteDeadline} {
+		deadlineErr := setDeadline(time.Time{})
+		if deadlineErr != nil {
+			if !errors.Is(deadlineErr, http.ErrNotSupported) {
+				return e.InternalServerError("Failed to initialize WebSocket connection.", deadlineErr)
+			}
+
+			e.App.Logger().Warn("SetReadDeadline/SetWriteDeadline is not supported, fallback to the default server timeouts")
+		}
+	}
+
+	connectEvent := new(core.RealtimeConnectRequestEvent)
+	connectEvent.RequestEvent = e
+	connectEvent.Client = subscriptions.NewDefaultClient()
+	connectEvent.IdleTimeout = 5 * time.Minute
+
+	rawLastEventId := e.Request.Header.Get("Last-Event-ID")
+	if rawLastEventId == "" {
+		rawLastEventId = e.Request.URL.Query().Get("lastEventId")
+	}
+	lastEventId, isResumed := realtimeResumeEventId(e.App, rawLastEventId)
+
+	return e.App.OnRealtimeConnectRequest().Trigger(connectEvent, func(ce *core.RealtimeConnectRequestEvent) error {
+		// store the resume point so that the missed messages can be
+		// replayed once the client submits its subscriptions
+		if isResumed {
+			ce.Client.Set(RealtimeClientLastEventIdKey, lastEventId)
+		}
+
+		server := websocket.Server{
+			// note: the Origin header is not checked because the realtime
+			// auth state is token based and doesn't rely on cookies
+			Handshake: func(config *websocket.Config, r *http.Request) error {
+				return nil
+			},
+			Handler: func(ws *websocket.Conn) {
+				realtimeServeWebSocket(ce, ws, lastEventId)
+			},
+		}
+
+		server.ServeHTTP(ce.Response, ce.Request)
+
+		return nil
+	})
+}
+
+// realtimeServeWebSocket runs the realtime WebSocket connection read and write loops.
+func realtimeServeWebSocket(ce *core.RealtimeConnectRequestEvent, ws *websocket.Conn, lastEventId uint64) {
+	defer ws.Close()
+
+	ws.MaxPayloadBytes = realtimeWebSocketMaxPayloadBytes
+
+	historyEnabled := ce.App.SubscriptionsBroker().HistorySize() > 0
+
+	// register new subscription client
+	ce.App.SubscriptionsBroker().Register(ce.Client)
+	defer func() {
+		realtimeUnregisterClient(ce.App, ce.Client)
+	}()
+
+	ce.App.Logger().Debug("Realtime WebSocket connection established.", slog.String("clientId", ce.Client.Id()))
+
+	// note: the hijacked connection is not tracked by the server and
+	// the context is cancelled on read error (aka. closed connection)
+	ctx, cancel := context.WithCancel(ce.Request.Context())
+	defer cancel()
+
+	// note: websocket.JSON.Send is safe for concurrent use
+	sendMessage := func(msg *subscriptions.Message) error {
+		msgEvent := new(core.RealtimeMessageEvent)
+		msgEvent.RequestEvent = ce.RequestEvent
+		msgEvent.Client = ce.Client
+		msgEvent.Message = msg
+
+		return ce.App.OnRealtimeMessageSend().Trigger(msgEvent, func(me *core.RealtimeMessageEvent) error {
+			frame := &realtimeWebSocketResponse{
+				Type: realtimeWebSocketTypeMessage,
+				Name: me.Message.Name,
+				Data: me.Message.Data,
+			}
+
+			if historyEnabled {
+				if me.Message.Id > 0 {
+					lastEventId = me.Message.Id
+				}
+				frame.Id = lastEventId
+			}
+
+			// wrap non-json data (if any) as json string
+			if !json.Valid(frame.Data) {
+				frame.Data, _ = json.Marshal(string(me.Message.Data))
+			}
+
+			return websocket.JSON.Send(ws, frame)
+		})
+	}
+
+	// signalize established connection (aka. fire "connect" message)
+	connectMsgErr := sendMessage(&subscriptions.Message{
+		Name: "PB_CONNECT",
+		Data: []byte(`{"clientId":"` + ce.Client.Id() + `"}`),
+	})
+	if connectMsgErr != nil {
+		ce.App.Logger().Debug(
+			"Realtime WebSocket connection closed (failed to deliver PB_CONNECT)",
+			slog.String("clientId", ce.Client.Id()),
+			slog.String("error", connectMsgErr.Error()),
+		)
+		return
+	}
+
+	activity := make(chan struct{}, 1)
+
+	go func() {
+		defer cancel()
+
+		for {
+			req := new(realtimeWebSocketRequest)
+
+			if err := websocket.JSON.Receive(ws, req); err != nil {
+				var syntaxErr *json.SyntaxError
+				var typeErr *json.UnmarshalTypeError
+				if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+					return // closed or broken connection
+				}
+
+				websocket.JSON.Send(ws, &realtimeWebSocketResponse{
+					Type:  realtimeWebSocketTypeError,
+					Error: NewBadRequestError("Failed to parse the WebSocket message.", err),
+				})
+				continue
+			}
+
+			select {
+			case activity <- struct{}{}:
+			default:
+			}
+
+			err := realtimeWebSocketHandleRequest(ce, ws, req)
+			if err != nil {
+				websocket.JSON.Send(ws, &realtimeWebSocketResponse{
+					Type:  realtimeWebSocketTypeError,
+					Ref:   req.Ref,
+					Error: ToApiError(err),
+				})
+			}
+		}
+	}()
+
+	// start an idle timer to keep track of inactive/forgotten connections
+	idleTimer := time.NewTimer(ce.IdleTimeout)
+	defer idleTimer.Stop()
+
+	for {
+		select {
+		case <-idleTimer.C:
+			cancel()
+		case <-activity:
+			idleTimer.Stop()
+			idleTimer.Reset(ce.IdleTimeout)
+		case msg, ok := <-ce.Client.Channel():
+			if !ok {
+				// channel is closed
+				ce.App.Logger().Debug(
+					"Realtime WebSocket connection closed (closed channel)",
+					slog.String("clientId", ce.Client.Id()),
+				)
+				return
+			}
+
+			msgErr := sendMessage(&msg)
+			if msgErr != nil {
+				ce.App.Logger().Debug(
+					"Realtime WebSocket connection closed (failed to deliver message)",
+					slog.String("clientId", ce.Client.Id()),
+					slog.String("error", msgErr.Error()),
+				)
+				return
+			}
+
+			idleTimer.Stop()
+			idleTimer.Reset(ce.IdleTimeout)
+		case <-ctx.Done():
+			// connection is closed
+			ce.App.Logger().Debug(
+				"Realtime WebSocket connection closed (cancelled request)",
+				slog.String("clientId", ce.Client.Id()),
+			)
+			return
+		}
+	}
+}
+
+// realtimeWebSocketHandleRequest processes a single client subscribe/unsubscribe/publish frame
+// with the same semantic as the realtimeSetSubscriptions and realtimePublish POST requests.
+//
+// The "unsubscribe" frame removes only the listed subscriptions
+// (or all of them if none are listed).
+//
+// The frame "authorization" token is optional and when not set the
+// previously submitted auth state (or the handshake one) is used.
+func realtimeWebSocketHandleRequest(ce *core.RealtimeConnectRequestEvent, ws *websocket.Conn, req *realtimeWebSocketRequest) error {
+	// create a new request event for each frame to avoid
+	// concurrent modifications of the connection request auth state
+	e := new(core.RequestEvent)
+	e.App = ce.App
+	e.Request = ce.Request
+	e.Response = ce.Response
+	e.Auth = ce.Auth
+	if apiKey := ce.APIKey(); apiKey != nil {
+		e.Set(core.RequestEventKeyAPIKey, apiKey)
+	}
+
+	// the auth state is preserved for the lifetime of the connection
+	// so it is not required to be resubmitted with every frame
+	if clientAuth, _ := ce.Client.Get(RealtimeClientAuthKey).(*core.Record); clientAuth != nil {
+		e.Auth = clientAuth
+
+		if apiKey, _ := ce.Client.Get(RealtimeClientAPIKeyKey).(*core.APIKey); apiKey != nil {
+			e.Set(core.RequestEventKeyAPIKey, apiKey)
+		}
+	}
+
+	if req.Authorization != "" {
+		token := strings.TrimPrefix(req.Authorization, "Bearer ")
+
+		record, err := e.App.FindAuthRecordByToken(token, core.TokenTypeAuth)
+		if err != nil {
+			return NewUnauthorizedError("Missing or invalid auth token.", err)
+		}
+
+		e.Auth = record
+		e.Set(core.RequestEventKeyAPIKey, nil)
+	}
+
+	sendAck := func() error {
+		return websocket.JSON.Send(ws, &realtimeWebSocketResponse{
+			Type: realtimeWebSocketTypeAck,
+			Ref:  req.Ref,
+		})
+	}
+
+	var subs []string
+
+	switch req.Type {
+	case realtimeWebSocketTypeSubscribe:
+		subs = req.Subscriptions
+	case realtimeWebSocketTypeUnsubscribe:
+		if len(req.Subscriptions) > 0 {
+			for sub := range ce.Client.Subscriptions() {
+				if !slices.Contains(req.Subscriptions, sub) {
+					subs = append(subs, sub)
+				}
+			}
+		}
+	case realtimeWebSocketTypePublish:
+		form := &realtimePublishForm{
+			Channel: req.Channel,
+			Data:    req.Data,
+		}
+		if err := form.validate(); err != nil {
+			return NewBadRequestError("", err)
+		}
+
+		return realtimePublishToChannel(e, ce.Client, form, func(e *core.RealtimePublishRequestEvent) error {
+			return sendAck()
+		})
+	default:
+		return NewBadRequestError("Unsupported WebSocket message type.", nil)
+	}
+
+	form := &realtimeSubscribeForm{
+		ClientId:      ce.Client.Id(),
+		Subscriptions: subs,
+	}
+	if err := form.validate(); err != nil {
+		return NewBadRequestError("", err)
+	}
+
+	return realtimeSubscribe(e, ce.Client, form.Subscriptions, func(e *core.RealtimeSubscribeRequestEvent) error {
+		return sendAck()
+	})
+}
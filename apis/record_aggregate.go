@@ -0,0 +1,104 @@
+package apis
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// records aggregate query params
+const (
+	aggregateGroupByQueryParam   string = "groupBy"
+	aggregateAggregateQueryParam string = "aggregate"
+)
+
+// recordsAggregateResponse defines the records aggregate endpoint response.
+type recordsAggregateResponse struct {
+	Items []map[string]any `json:"items"`
+
+	// Truncated indicates whether there are more than [search.MaxPerPage]
+	// groups and the returned items were limited to the first ones.
+	Truncated bool `json:"truncated"`
+}
+
+func recordsAggregate(e *core.RequestEvent) error {
+	collection, err := e.App.FindCachedCollectionByNameOrId(e.Request.PathValue("collection"))
+	if err != nil || collection == nil {
+		return e.NotFoundError("Missing collection context.", err)
+	}
+
+	err = checkCollectionRateLimit(e, collection, "list")
+	if err != nil {
+		return err
+	}
+
+	err = checkAPIKeyScope(e, collection, core.APIKeyActionList)
+	if err != nil {
+		return err
+	}
+
+	requestInfo, err := e.RequestInfo()
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("", err))
+	}
+
+	if collection.ListRule == nil && !requestInfo.HasSuperuserAuth() {
+		return e.ForbiddenError("Only superusers can perform this action.", nil)
+	}
+
+	// forbid users and guests to query special filter fields
+	err = checkForSuperuserOnlyRuleFields(requestInfo)
+	if err != nil {
+		return err
+	}
+
+	query := e.Request.URL.Query()
+
+	options := core.RecordsAggregateOptions{
+		RequestInfo: requestInfo,
+		Filter:      query.Get(search.FilterQueryParam),
+		GroupBy:     splitAggregateQueryParam(query.Get(aggregateGroupByQueryParam)),
+		Aggregates:  splitAggregateQueryParam(query.Get(aggregateAggregateQueryParam)),
+		Limit:       search.MaxPerPage + 1, // +1 to check whether the result was truncated
+	}
+
+	if !requestInfo.HasSuperuserAuth() && collection.ListRule != nil {
+		options.Rule = *collection.ListRule
+	}
+
+	if len(options.Filter) > search.MaxFilterLength {
+		return e.BadRequestError("", search.ErrFilterLengthLimit)
+	}
+
+	items, err := e.App.AggregateRecords(collection, options)
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to aggregate the collection records.", err))
+	}
+
+	response := &recordsAggregateResponse{Items: items}
+	if len(items) > search.MaxPerPage {
+		response.Items = items[:search.MaxPerPage]
+		response.Truncated = true
+	}
+
+	return e.JSON(http.StatusOK, response)
+}
+
+func splitAggregateQueryParam(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	result := []string{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			result = append(result, part)
+		}
+	}
+
+	return result
+}
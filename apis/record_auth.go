@@ -23,9 +23,10 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 		collectionPathRateLimit("", "listAuthMethods"),
 	)
 
+	// note: the collection context auth is checked in the handler
+	// because the refresh token exchange doesn't require an auth token
 	sub.POST("/auth-refresh", recordAuthRefresh).Bind(
 		collectionPathRateLimit("", "authRefresh"),
-		RequireSameCollectionContextAuth(""),
 	)
 
 	sub.POST("/auth-with-password", recordAuthWithPassword).Bind(
@@ -43,6 +44,72 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 		collectionPathRateLimit("", "authWithOTP", "auth"),
 	)
 
+	sub.POST("/enroll-totp", recordEnrollTOTP).Bind(
+		collectionPathRateLimit("", "enrollTOTP"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/confirm-totp", recordConfirmTOTP).Bind(
+		collectionPathRateLimit("", "confirmTOTP"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/disable-totp", recordDisableTOTP).Bind(
+		collectionPathRateLimit("", "disableTOTP"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/auth-with-totp", recordAuthWithTOTP).Bind(
+		collectionPathRateLimit("", "authWithTOTP", "auth"),
+	)
+
+	sub.POST("/request-webauthn", recordRequestWebAuthn).Bind(
+		collectionPathRateLimit("", "requestWebAuthn"),
+	)
+	sub.POST("/register-webauthn", recordRegisterWebAuthn).Bind(
+		collectionPathRateLimit("", "registerWebAuthn"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/auth-with-webauthn", recordAuthWithWebAuthn).Bind(
+		collectionPathRateLimit("", "authWithWebAuthn", "auth"),
+	)
+
+	sub.GET("/saml/metadata", recordSAMLMetadata).Bind(
+		collectionPathRateLimit("", "samlMetadata"),
+	)
+	sub.GET("/saml/login", recordSAMLLogin).Bind(
+		collectionPathRateLimit("", "samlLogin"),
+	)
+	sub.POST("/saml/acs", recordSAMLACS).Bind(
+		collectionPathRateLimit("", "samlACS"),
+	)
+	sub.POST("/auth-with-saml", recordAuthWithSAML).Bind(
+		collectionPathRateLimit("", "authWithSAML", "auth"),
+	)
+
+	sub.GET("/api-keys", recordListAPIKeys).Bind(
+		collectionPathRateLimit("", "listAPIKeys"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.POST("/api-keys", recordCreateAPIKey).Bind(
+		collectionPathRateLimit("", "createAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.DELETE("/api-keys/{id}", recordDeleteAPIKey).Bind(
+		collectionPathRateLimit("", "deleteAPIKey"),
+		RequireSameCollectionContextAuth(""),
+	)
+
+	sub.GET("/auth-sessions", recordListAuthSessions).Bind(
+		collectionPathRateLimit("", "listAuthSessions"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.DELETE("/auth-sessions", recordDeleteOtherAuthSessions).Bind(
+		collectionPathRateLimit("", "deleteAuthSessions"),
+		RequireSameCollectionContextAuth(""),
+	)
+	sub.DELETE("/auth-sessions/{id}", recordDeleteAuthSession).Bind(
+		collectionPathRateLimit("", "deleteAuthSession"),
+		RequireSameCollectionContextAuth(""),
+	)
+
 	sub.POST("/request-password-reset", recordRequestPasswordReset).Bind(
 		collectionPathRateLimit("", "requestPasswordReset"),
 	)
@@ -66,6 +133,7 @@ func bindRecordAuthApi(app core.App, rg *router.RouterGroup[*core.RequestEvent])
 	)
 
 	sub.POST("/impersonate/{id}", recordAuthImpersonate).Bind(RequireSuperuserAuth())
+	sub.POST("/unlock/{id}", recordAuthUnlock).Bind(RequireSuperuserAuth())
 }
 
 func findAuthCollection(e *core.RequestEvent) (*core.Collection, error) {
@@ -0,0 +1,175 @@
+package apis
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func recordListAPIKeys(e *core.RequestEvent) error {
+	apiKeys, err := e.App.FindAllAPIKeysByRecord(e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the auth record API keys.", err)
+	}
+
+	return e.JSON(http.StatusOK, apiKeys)
+}
+
+func recordCreateAPIKey(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.APIKeys.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow API keys.", nil)
+	}
+
+	// prevent minting new keys with a leaked one
+	if e.APIKey() != nil {
+		return e.ForbiddenError("New API keys cannot be created with an API key authorization.", nil)
+	}
+
+	form := &createAPIKeyForm{app: e.App, collection: collection}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	apiKey := core.NewAPIKey(e.App)
+	apiKey.SetCollectionRef(collection.Id)
+	apiKey.SetRecordRef(e.Auth.Id)
+	apiKey.SetName(form.Name)
+	apiKey.SetScopes(form.Scopes)
+	apiKey.SetExpires(form.Expires)
+	key := apiKey.GenerateKey()
+
+	if err = e.App.Save(apiKey); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create the API key.", err))
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"key":    key, // the plain key is returned only once
+		"apiKey": apiKey,
+	})
+}
+
+func recordDeleteAPIKey(e *core.RequestEvent) error {
+	apiKey := &core.APIKey{}
+
+	err := e.App.RecordQuery(core.CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{
+			"id":            e.Request.PathValue("id"),
+			"collectionRef": e.Auth.Collection().Id,
+			"recordRef":     e.Auth.Id,
+		}).
+		Limit(1).
+		One(apiKey)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if err = e.App.Delete(apiKey); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to delete the API key.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// checkAPIKeyScope returns a forbidden error if the current request is authenticated
+// with an API key whose scopes doesn't allow the specified collection records action.
+func checkAPIKeyScope(e *core.RequestEvent, collection *core.Collection, action string) error {
+	apiKey := e.APIKey()
+	if apiKey == nil || apiKey.Allows(collection, action) {
+		return nil
+	}
+
+	return e.ForbiddenError("The API key is not allowed to perform this action.", nil)
+}
+
+// -------------------------------------------------------------------
+
+type createAPIKeyForm struct {
+	app        core.App
+	collection *core.Collection
+
+	Name    string             `form:"name" json:"name"`
+	Scopes  []core.APIKeyScope `form:"scopes" json:"scopes"`
+	Expires types.DateTime     `form:"expires" json:"expires"`
+}
+
+func (form *createAPIKeyForm) validate() error {
+	maxDuration := form.collection.APIKeys.MaxDurationTime()
+
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Required, validation.Length(1, 100)),
+		validation.Field(&form.Scopes, validation.By(form.checkScopes)),
+		validation.Field(
+			&form.Expires,
+			validation.When(maxDuration > 0, validation.Required),
+			validation.By(form.checkExpires(maxDuration)),
+		),
+	)
+}
+
+func (form *createAPIKeyForm) checkScopes(value any) error {
+	scopes, _ := value.([]core.APIKeyScope)
+
+	errs := validation.Errors{}
+
+	for i, scope := range scopes {
+		err := validation.ValidateStruct(&scope,
+			validation.Field(&scope.Collection, validation.Required, validation.By(form.checkScopeCollection)),
+			validation.Field(&scope.Actions, validation.Required, validation.Each(validation.In(list.ToInterfaceSlice(core.APIKeyActions)...))),
+		)
+		if err != nil {
+			errs[strconv.Itoa(i)] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (form *createAPIKeyForm) checkScopeCollection(value any) error {
+	v, _ := value.(string)
+	if v == "" || v == "*" {
+		return nil
+	}
+
+	if _, err := form.app.FindCachedCollectionByNameOrId(v); err != nil {
+		return validation.NewError("validation_missing_collection", "Missing or invalid collection.")
+	}
+
+	return nil
+}
+
+func (form *createAPIKeyForm) checkExpires(maxDuration time.Duration) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(types.DateTime)
+		if v.IsZero() {
+			return nil // nothing to check
+		}
+
+		if !v.Time().After(time.Now()) {
+			return validation.NewError("validation_past_date", "The expiration date must be in the future.")
+		}
+
+		if maxDuration > 0 && v.Time().After(time.Now().Add(maxDuration)) {
+			return validation.NewError("validation_max_duration", "The expiration date exceeds the max allowed API key lifetime.")
+		}
+
+		return nil
+	}
+}
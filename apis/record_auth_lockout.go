@@ -0,0 +1,141 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// recordAuthUnlock removes all failed attempts lockouts of the specified auth record.
+func recordAuthUnlock(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	record, err := e.App.FindRecordById(collection, e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if err = e.App.DeleteAllAuthLockoutsByRecord(record); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to unlock the auth record.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// isAuthLocked reports whether the auth record is temporary locked
+// for the specified auth method.
+//
+// Note that the callers are expected to respond with the same generic error
+// as for invalid credentials to prevent accounts enumeration.
+func isAuthLocked(e *core.RequestEvent, authRecord *core.Record, method string, config core.AuthLockoutConfig) bool {
+	if !config.Enabled || authRecord == nil {
+		return false
+	}
+
+	lockout, _ := e.App.FindAuthLockout(authRecord, method)
+
+	return lockout != nil && lockout.IsLocked()
+}
+
+// registerAuthFailure increments the consecutive failed attempts counter
+// of the auth record for the specified auth method and temporary locks
+// the record if the configured max attempts are reached.
+func registerAuthFailure(e *core.RequestEvent, authRecord *core.Record, method string, config core.AuthLockoutConfig) error {
+	if !config.Enabled || authRecord == nil {
+		return nil
+	}
+
+	originalApp := e.App
+	defer func() { e.App = originalApp }()
+
+	return e.App.RunInTransaction(func(txApp core.App) error {
+		e.App = txApp
+
+		lockout, err := incrementAuthFailures(txApp, authRecord, method, config)
+		if err != nil {
+			return err
+		}
+
+		lockDuration := config.LockDuration(lockout.Failures())
+		if lockDuration <= 0 {
+			if lockout.IsNew() {
+				return txApp.Save(lockout)
+			}
+			return nil
+		}
+
+		lockout.SetLockedUntil(types.NowDateTime().Add(lockDuration))
+
+		event := new(core.RecordAuthLockoutEvent)
+		event.RequestEvent = e
+		event.Collection = authRecord.Collection()
+		event.Record = authRecord
+		event.Lockout = lockout
+		event.AuthMethod = method
+
+		return e.App.OnRecordAuthLockout().Trigger(event, func(e *core.RecordAuthLockoutEvent) error {
+			return e.App.Save(e.Lockout)
+		})
+	})
+}
+
+// incrementAuthFailures atomically increments the failed attempts counter
+// of the existing auth record lockout (resetting the stale one) and returns
+// the updated lockout model.
+//
+// If there is no existing lockout, it returns a new unsaved one with 1 failure.
+func incrementAuthFailures(txApp core.App, authRecord *core.Record, method string, config core.AuthLockoutConfig) (*core.AuthLockout, error) {
+	now := types.NowDateTime()
+
+	result, err := txApp.DB().NewQuery(
+		"UPDATE {{" + core.CollectionNameAuthLockouts + "}} SET " +
+			"[[failures]] = (CASE WHEN [[updated]] < {:staleDate} THEN 1 ELSE [[failures]] + 1 END), " +
+			"[[updated]] = {:now} " +
+			"WHERE [[collectionRef]] = {:collectionRef} AND [[recordRef]] = {:recordRef} AND [[method]] = {:method}",
+	).Bind(dbx.Params{
+		"staleDate":     now.Add(-1 * config.MaxDurationTime()).String(),
+		"now":           now.String(),
+		"collectionRef": authRecord.Collection().Id,
+		"recordRef":     authRecord.Id,
+		"method":        method,
+	}).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected == 0 {
+		lockout := core.NewAuthLockout(txApp)
+		lockout.SetCollectionRef(authRecord.Collection().Id)
+		lockout.SetRecordRef(authRecord.Id)
+		lockout.SetMethod(method)
+		lockout.SetFailures(1)
+		return lockout, nil
+	}
+
+	return txApp.FindAuthLockout(authRecord, method)
+}
+
+// resetAuthFailures resets the consecutive failed attempts counter
+// of the auth record for the specified auth method.
+func resetAuthFailures(e *core.RequestEvent, authRecord *core.Record, method string, config core.AuthLockoutConfig) error {
+	if !config.Enabled || authRecord == nil {
+		return nil
+	}
+
+	lockout, _ := e.App.FindAuthLockout(authRecord, method)
+	if lockout == nil {
+		return nil // nothing to reset
+	}
+
+	return e.App.Delete(lockout)
+}
@@ -16,6 +16,18 @@ type otpResponse struct {
 	Duration int64 `json:"duration"` // in seconds
 }
 
+type totpResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type webauthnResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type samlResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
 type mfaResponse struct {
 	Enabled  bool  `json:"enabled"`
 	Duration int64 `json:"duration"` // in seconds
@@ -54,6 +66,9 @@ type authMethodsResponse struct {
 	OAuth2   oauth2Response   `json:"oauth2"`
 	MFA      mfaResponse      `json:"mfa"`
 	OTP      otpResponse      `json:"otp"`
+	TOTP     totpResponse     `json:"totp"`
+	WebAuthn webauthnResponse `json:"webauthn"`
+	SAML     samlResponse     `json:"saml"`
 
 	// legacy fields
 	// @todo remove after dropping v0.22 support
@@ -88,6 +103,15 @@ func recordAuthMethods(e *core.RequestEvent) error {
 		OTP: otpResponse{
 			Enabled: collection.OTP.Enabled,
 		},
+		TOTP: totpResponse{
+			Enabled: collection.TOTP.Enabled,
+		},
+		WebAuthn: webauthnResponse{
+			Enabled: collection.WebAuthn.Enabled,
+		},
+		SAML: samlResponse{
+			Enabled: collection.SAML.Enabled,
+		},
 		MFA: mfaResponse{
 			Enabled: collection.MFA.Enabled,
 		},
@@ -125,6 +149,16 @@ func recordAuthMethods(e *core.RequestEvent) error {
 			continue // skip provider
 		}
 
+		// don't block the listing on the OIDC issuer discovery
+		// (the discovery document is loaded in the background)
+		if oidc, ok := provider.(*auth.OIDC); ok && !oidc.DiscoverCached() && oidc.AuthURL() == "" {
+			e.App.Logger().Debug(
+				"The OAuth2 provider discovery document is not loaded yet",
+				slog.String("name", config.Name),
+			)
+			continue // skip provider
+		}
+
 		info := providerInfo{
 			Name:        config.Name,
 			DisplayName: provider.DisplayName(),
@@ -152,6 +186,11 @@ func recordAuthMethods(e *core.RequestEvent) error {
 				oauth2.SetAuthURLParam("code_challenge", info.CodeChallenge),
 				oauth2.SetAuthURLParam("code_challenge_method", info.CodeChallengeMethod),
 			)
+
+			// bind the OIDC id_token to the current auth request
+			if oidc, ok := provider.(*auth.OIDC); ok && oidc.Issuer() != "" {
+				urlOpts = append(urlOpts, oauth2.SetAuthURLParam("nonce", auth.OIDCNonce(info.CodeVerifier)))
+			}
 		}
 
 		info.AuthURL = provider.BuildAuthURL(
@@ -2,6 +2,7 @@ package apis_test
 
 import (
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/core"
@@ -38,6 +39,9 @@ func TestRecordAuthMethodsList(t *testing.T) {
 				`"oauth2":{"providers":[],"enabled":false}`,
 				`"mfa":{"enabled":false,"duration":0}`,
 				`"otp":{"enabled":false,"duration":0}`,
+				`"totp":{"enabled":false}`,
+				`"webauthn":{"enabled":false}`,
+				`"saml":{"enabled":false}`,
 			},
 			ExpectedEvents: map[string]int{"*": 0},
 		},
@@ -64,6 +68,60 @@ func TestRecordAuthMethodsList(t *testing.T) {
 			},
 			ExpectedEvents: map[string]int{"*": 0},
 		},
+		{
+			Name:   "auth collection with OIDC provider with not loaded discovery document",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/auth-methods",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				// the discovery request is blocked until the end of the test
+				done := make(chan struct{})
+				issuer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					<-done
+					w.WriteHeader(http.StatusInternalServerError)
+				}))
+				app.Store().Set("testIssuerClose", func() {
+					close(done)
+					issuer.Close()
+				})
+
+				users, err := app.FindCollectionByNameOrId("users")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				users.OAuth2.Providers = append(users.OAuth2.Providers,
+					core.OAuth2ProviderConfig{
+						Name:         "oidc",
+						ClientId:     "test",
+						ClientSecret: "test",
+						Extra:        map[string]any{"issuer": issuer.URL},
+					},
+					core.OAuth2ProviderConfig{
+						Name:         "oidc2",
+						ClientId:     "test",
+						ClientSecret: "test",
+						AuthURL:      "https://example.com/authorize",
+						Extra:        map[string]any{"issuer": issuer.URL},
+					},
+				)
+				if err = app.Save(users); err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				app.Store().Get("testIssuerClose").(func())()
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"name":"google"`,
+				`"name":"oidc2"`,
+				`"authURL":"https://example.com/authorize?`,
+			},
+			NotExpectedContent: []string{
+				`"name":"oidc"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
 
 		// rate limit checks
 		// -----------------------------------------------------------
@@ -1,17 +1,29 @@
 package apis
 
 import (
+	"errors"
+
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/spf13/cast"
 )
 
 func recordAuthRefresh(e *core.RequestEvent) error {
-	record := e.Auth
-	if record == nil {
-		return e.NotFoundError("Missing auth record context.", nil)
+	form := &authRefreshForm{}
+	if err := e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	if form.RefreshToken != "" {
+		return recordAuthRefreshWithToken(e, form.RefreshToken)
+	}
+
+	if err := checkSameCollectionContextAuth(e, ""); err != nil {
+		return err
 	}
 
+	record := e.Auth
+
 	event := new(core.RecordAuthRefreshRequestEvent)
 	event.RequestEvent = e
 	event.Collection = record.Collection()
@@ -24,12 +36,81 @@ func recordAuthRefresh(e *core.RequestEvent) error {
 		claims, _ := security.ParseUnverifiedJWT(token) //
 		if v, ok := claims[core.TokenClaimRefreshable]; ok && cast.ToBool(v) {
 			var tokenErr error
-			token, tokenErr = e.Record.NewAuthToken()
+
+			// preserve the auth session link (if any)
+			if sessionId := cast.ToString(claims[core.TokenClaimSessionId]); sessionId != "" {
+				token, tokenErr = e.Record.NewSessionAuthToken(sessionId)
+			} else {
+				token, tokenErr = e.Record.NewAuthToken()
+			}
+
 			if tokenErr != nil {
 				return e.InternalServerError("Failed to refresh auth token.", tokenErr)
 			}
 		}
 
-		return recordAuthResponse(e.RequestEvent, e.Record, token, "", nil)
+		return recordAuthResponse(e.RequestEvent, e.Record, token, "", "", nil)
+	})
+}
+
+// recordAuthRefreshWithToken exchanges the provided refresh token
+// with a new auth token and a new (rotated) refresh token.
+func recordAuthRefreshWithToken(e *core.RequestEvent, refreshToken string) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.RefreshToken.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow refresh tokens.", nil)
+	}
+
+	session, err := e.App.FindAuthSessionByRefreshToken(refreshToken)
+	if err != nil || session.CollectionRef() != collection.Id {
+		return e.UnauthorizedError("Invalid or expired refresh token.", err)
+	}
+
+	// an already exchanged refresh token was most likely leaked
+	// so revoke the entire session to force a new sign-in
+	if session.IsReusedRefreshToken(refreshToken) || session.HasExpired() {
+		if err = e.App.Delete(session); err != nil {
+			e.App.Logger().Warn("Failed to delete auth session", "error", err, "sessionId", session.Id)
+		}
+
+		return e.UnauthorizedError("Invalid or expired refresh token.", nil)
+	}
+
+	record, err := e.App.FindRecordById(collection, session.RecordRef())
+	if err != nil {
+		return e.UnauthorizedError("Invalid or expired refresh token.", err)
+	}
+
+	event := new(core.RecordAuthRefreshRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+	event.Record = record
+
+	return e.App.OnRecordAuthRefreshRequest().Trigger(event, func(e *core.RecordAuthRefreshRequestEvent) error {
+		newRefreshToken, err := e.App.RotateAuthSessionRefreshToken(session, refreshToken, e.Collection.RefreshToken.DurationTime())
+		if err != nil {
+			if errors.Is(err, core.ErrAuthSessionTokenMismatch) {
+				return e.UnauthorizedError("Invalid or expired refresh token.", err)
+			}
+
+			return firstApiError(err, e.BadRequestError("Failed to refresh the auth session.", err))
+		}
+
+		token, err := e.Record.NewSessionAuthToken(session.Id)
+		if err != nil {
+			return e.InternalServerError("Failed to refresh auth token.", err)
+		}
+
+		return recordAuthResponse(e.RequestEvent, e.Record, token, newRefreshToken, "", nil)
 	})
 }
+
+// -------------------------------------------------------------------
+
+type authRefreshForm struct {
+	RefreshToken string `form:"refreshToken" json:"refreshToken"`
+}
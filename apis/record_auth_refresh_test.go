@@ -1,11 +1,16 @@
 package apis_test
 
 import (
+	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestRecordAuthRefresh(t *testing.T) {
@@ -214,3 +219,278 @@ func TestRecordAuthRefresh(t *testing.T) {
 		scenario.Test(t)
 	}
 }
+
+func TestRecordAuthRefreshWithRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	sessionToken := newTestSessionAuthToken(t)
+
+	sessionDeleteEvents := map[string]int{
+		"OnModelDelete":              1,
+		"OnModelDeleteExecute":       1,
+		"OnModelAfterDeleteSuccess":  1,
+		"OnRecordDelete":             1,
+		"OnRecordDeleteExecute":      1,
+		"OnRecordAfterDeleteSuccess": 1,
+	}
+
+	withEvents := func(base map[string]int, extra map[string]int) map[string]int {
+		result := map[string]int{"*": 0}
+		for k, v := range base {
+			result[k] = v
+		}
+		for k, v := range extra {
+			result[k] = v
+		}
+		return result
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "disabled refresh tokens",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"` + testRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+
+				users, err := app.FindCollectionByNameOrId("users")
+				if err != nil {
+					t.Fatal(err)
+				}
+				users.RefreshToken.Enabled = false
+				if err = app.Save(users); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "non-auth collection",
+			Method: http.MethodPost,
+			URL:    "/api/collections/demo1/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"` + testRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+			},
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "refresh token from different auth collection",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"` + testRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+
+				clients, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+				clients.RefreshToken.Enabled = true
+				clients.RefreshToken.Duration = 3600
+				if err = app.Save(clients); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid refresh token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"invalid"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "expired session refresh token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"` + testRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(-1*time.Minute))
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if _, err := app.FindAuthSessionById(testAuthSessionId); err == nil {
+					t.Fatal("Expected the expired session to be deleted")
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  withEvents(sessionDeleteEvents, nil),
+		},
+		{
+			Name:   "reused (already exchanged) refresh token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"` + testPreviousRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if _, err := app.FindAuthSessionById(testAuthSessionId); err == nil {
+					t.Fatal("Expected the compromised session to be deleted")
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  withEvents(sessionDeleteEvents, nil),
+		},
+		{
+			Name:   "concurrently exchanged refresh token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Body:   strings.NewReader(`{"refreshToken":"` + testRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+
+				// simulate another request exchanging the same token in the meantime
+				app.OnRecordAuthRefreshRequest().BindFunc(func(e *core.RecordAuthRefreshRequestEvent) error {
+					session, err := e.App.FindAuthSessionById(testAuthSessionId)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if _, err := e.App.RotateAuthSessionRefreshToken(session, testRefreshToken, 1*time.Hour); err != nil {
+						t.Fatal(err)
+					}
+
+					return e.Next()
+				})
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if _, err := app.FindAuthSessionById(testAuthSessionId); err != nil {
+					t.Fatalf("Expected the session to remain, got %v", err)
+				}
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*":                          0,
+				"OnRecordAuthRefreshRequest": 1,
+			},
+		},
+		{
+			Name:   "valid refresh token",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh?expand=rel",
+			Body:   strings.NewReader(`{"refreshToken":"` + testRefreshToken + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Minute))
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				body := struct {
+					Token        string `json:"token"`
+					RefreshToken string `json:"refreshToken"`
+				}{}
+				if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+
+				session, err := app.FindAuthSessionById(testAuthSessionId)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !session.ValidateRefreshToken(body.RefreshToken) {
+					t.Fatal("Expected the new refresh token to be valid")
+				}
+
+				if !session.IsReusedRefreshToken(testRefreshToken) {
+					t.Fatal("Expected the old refresh token to be marked as exchanged")
+				}
+
+				if d := time.Until(session.Expires().Time()); d < 59*time.Minute {
+					t.Fatalf("Expected the session expiration to be extended, got %v", d)
+				}
+
+				record, err := app.FindAuthRecordByToken(body.Token, core.TokenTypeAuth)
+				if err != nil || record.Id != "4q1xlclmfloku33" {
+					t.Fatalf("Expected valid session bound auth token, got %v (%v)", record, err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":`,
+				`"refreshToken":`,
+				`"id":"4q1xlclmfloku33"`,
+				`"email":"test@example.com"`,
+				`"expand":`,
+				`"id":"llvuca81nly1qls"`,
+			},
+			NotExpectedContent: []string{
+				`"refreshToken":"` + testRefreshToken + `"`,
+			},
+			// the session is rotated with a direct conditional db update
+			ExpectedEvents: withEvents(nil, map[string]int{
+				"OnRecordAuthRefreshRequest": 1,
+				"OnRecordAuthRequest":        1,
+				"OnRecordEnrich":             2,
+			}),
+		},
+		{
+			Name:   "session bound auth token refresh",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Headers: map[string]string{
+				"Authorization": sessionToken,
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTestAuthSession(t, app, types.NowDateTime().Add(1*time.Hour))
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				body := struct {
+					Token string `json:"token"`
+				}{}
+				if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+
+				claims, _ := security.ParseUnverifiedJWT(body.Token)
+				if v := claims[core.TokenClaimSessionId]; v != testAuthSessionId {
+					t.Fatalf("Expected the session link to be preserved, got %#v", v)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":`,
+				`"id":"4q1xlclmfloku33"`,
+			},
+			NotExpectedContent: []string{
+				`"refreshToken":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                          0,
+				"OnRecordAuthRefreshRequest": 1,
+				"OnRecordAuthRequest":        1,
+				"OnRecordEnrich":             1,
+			},
+		},
+		{
+			Name:   "revoked session bound auth token refresh",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-refresh",
+			Headers: map[string]string{
+				"Authorization": sessionToken,
+			},
+			ExpectedStatus:  401,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
@@ -0,0 +1,292 @@
+package apis
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/saml"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
+)
+
+const (
+	// samlRequestsStoreKey is the app store key with the pending
+	// (aka. not yet answered) AuthnRequest ids.
+	samlRequestsStoreKey = "@samlRequests"
+
+	// samlCodesStoreKey is the app store key with the verified SAML
+	// users waiting to be exchanged with the auth-with-saml endpoint.
+	samlCodesStoreKey = "@samlCodes"
+
+	// samlMaxPendingItems limits the number of the stored pending
+	// requests and codes to prevent unbounded memory growth.
+	samlMaxPendingItems = 10000
+
+	// samlMaxStateLength is the max allowed length of the client login state.
+	samlMaxStateLength = 255
+)
+
+type samlPendingItem struct {
+	collectionId string
+	state        string
+	authUser     *auth.AuthUser
+	expiresAt    int64
+}
+
+// recordSAMLMetadata returns the collection service provider metadata XML.
+//
+// Note that the metadata is available even if the SAML auth is not
+// enabled yet to simplify the initial identity provider configuration.
+func recordSAMLMetadata(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	sp := &saml.ServiceProvider{
+		EntityId: collection.SAML.EntityId,
+		ACSURL:   samlEndpointURL(e.App, collection, "acs"),
+	}
+	if sp.EntityId == "" {
+		sp.EntityId = samlEndpointURL(e.App, collection, "metadata")
+	}
+
+	return e.Blob(http.StatusOK, "application/samlmetadata+xml", sp.Metadata())
+}
+
+// recordSAMLLogin redirects the user to the identity provider
+// single sign-on url with a new AuthnRequest.
+//
+// The client must provide a random "state" query parameter that is bound
+// to the login and it is required later on the samlCode exchange
+// (similar to the OAuth2 state it protects against login CSRF).
+func recordSAMLLogin(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	state := e.Request.URL.Query().Get("state")
+	if state == "" || len(state) > samlMaxStateLength {
+		return e.BadRequestError("Missing or invalid state query parameter.", nil)
+	}
+
+	sp, err := samlServiceProvider(e.App, collection)
+	if err != nil {
+		return e.InternalServerError("Failed to init the SAML service provider.", err)
+	}
+
+	// the request id is also used as RelayState to locate the pending request
+	// (it is short enough to fit in the recommended 80 bytes limit)
+	requestId := saml.NewRequestId()
+
+	redirectURL, err := sp.AuthnRequestURL(requestId, requestId)
+	if err != nil {
+		return e.InternalServerError("Failed to build the SAML request.", err)
+	}
+
+	stored := samlStoreSet(e.App, samlRequestsStoreKey, requestId, samlPendingItem{
+		collectionId: collection.Id,
+		state:        state,
+		expiresAt:    time.Now().Add(collection.SAML.DurationTime()).Unix(),
+	})
+	if !stored {
+		return e.TooManyRequestsError("Too many pending SAML requests. Please try again later.", nil)
+	}
+
+	return e.Redirect(http.StatusFound, redirectURL)
+}
+
+// recordSAMLACS handles the identity provider SAMLResponse (HTTP-POST binding)
+// and redirects to the collection SAML.RedirectURL with either a single use
+// "samlCode" or an "error" query parameter (+ the login "state").
+//
+// Unsolicited (aka. IdP-initiated) responses are not supported.
+func recordSAMLACS(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	redirectURL, err := url.Parse(collection.SAML.RedirectURL)
+	if err != nil {
+		return e.InternalServerError("Invalid SAML redirect url.", err)
+	}
+
+	var state string
+
+	redirect := func(param string, value string) error {
+		query := redirectURL.Query()
+		query.Set(param, value)
+		if state != "" {
+			query.Set("state", state)
+		}
+		redirectURL.RawQuery = query.Encode()
+
+		return e.Redirect(http.StatusSeeOther, redirectURL.String())
+	}
+
+	requestId := e.Request.PostFormValue("RelayState")
+
+	request, ok := samlStorePop(e.App, samlRequestsStoreKey, requestId, collection.Id)
+	if !ok {
+		e.App.Logger().Debug("Missing or expired SAML request", "requestId", requestId)
+		return redirect("error", "Missing or expired SAML request.")
+	}
+	state = request.state
+
+	sp, err := samlServiceProvider(e.App, collection)
+	if err != nil {
+		return e.InternalServerError("Failed to init the SAML service provider.", err)
+	}
+
+	assertion, err := sp.ParseResponse(e.Request.PostFormValue("SAMLResponse"), requestId)
+	if err != nil {
+		e.App.Logger().Debug("Failed to verify SAML response", "error", err, "requestId", requestId)
+		return redirect("error", "Failed to verify the SAML response.")
+	}
+
+	authUser := collection.SAML.AuthUser(assertion)
+	if authUser.Id == "" {
+		e.App.Logger().Debug("Missing SAML user identifier", "requestId", requestId)
+		return redirect("error", "Missing SAML user identifier.")
+	}
+
+	code := security.RandomString(40)
+
+	stored := samlStoreSet(e.App, samlCodesStoreKey, code, samlPendingItem{
+		collectionId: collection.Id,
+		state:        state,
+		authUser:     authUser,
+		expiresAt:    time.Now().Add(collection.SAML.DurationTime()).Unix(),
+	})
+	if !stored {
+		return redirect("error", "Too many pending SAML logins. Please try again later.")
+	}
+
+	return redirect("samlCode", code)
+}
+
+func recordAuthWithSAML(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.SAML.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow SAML authentication.", nil)
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextSAML)
+
+	form := new(recordSAMLLoginForm)
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+
+	// note: the code is consumed even on state mismatch to prevent guessing
+	item, ok := samlStorePop(e.App, samlCodesStoreKey, form.Code, collection.Id)
+	if !ok || !security.Equal(item.state, form.State) {
+		return e.BadRequestError("Invalid or expired SAML code.", nil)
+	}
+
+	return authWithExternalUser(e, collection, core.SAMLProviderName, nil, item.authUser, form.CreateData, core.MFAMethodSAML)
+}
+
+// -------------------------------------------------------------------
+
+type recordSAMLLoginForm struct {
+	// Additional data that will be used for creating a new auth record
+	// if an existing SAML linked account doesn't exist.
+	CreateData map[string]any `form:"createData" json:"createData"`
+
+	// The single use code returned from the ACS redirect.
+	Code string `form:"code" json:"code"`
+
+	// The client state used to initiate the SAML login.
+	State string `form:"state" json:"state"`
+}
+
+func (form *recordSAMLLoginForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.Length(0, 100)),
+		validation.Field(&form.State, validation.Required, validation.Length(0, samlMaxStateLength)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+// samlEndpointURL returns the absolute url of the specified collection SAML endpoint.
+//
+// The collection id is used instead of its name because the url is
+// registered in the identity provider and shouldn't change on rename.
+func samlEndpointURL(app core.App, collection *core.Collection, endpoint string) string {
+	return strings.TrimRight(app.Settings().Meta.AppURL, "/") +
+		"/api/collections/" + url.PathEscape(collection.Id) + "/saml/" + endpoint
+}
+
+func samlServiceProvider(app core.App, collection *core.Collection) (*saml.ServiceProvider, error) {
+	return collection.SAML.InitServiceProvider(
+		samlEndpointURL(app, collection, "metadata"),
+		samlEndpointURL(app, collection, "acs"),
+	)
+}
+
+func samlStore(app core.App, storeKey string) *store.Store[string, samlPendingItem] {
+	items := app.Store().GetOrSet(storeKey, func() any {
+		return store.New[string, samlPendingItem](nil)
+	}).(*store.Store[string, samlPendingItem])
+
+	// cleanup the expired items
+	now := time.Now().Unix()
+	for key, item := range items.GetAll() {
+		if item.expiresAt < now {
+			items.Remove(key)
+		}
+	}
+
+	return items
+}
+
+// samlStoreSet stores the specified item and reports whether the max
+// allowed pending items limit wasn't reached.
+func samlStoreSet(app core.App, storeKey string, key string, item samlPendingItem) bool {
+	return samlStore(app, storeKey).SetIfLessThanLimit(key, item, samlMaxPendingItems)
+}
+
+// samlStorePop removes and returns the specified non-expired collection item.
+func samlStorePop(app core.App, storeKey string, key string, collectionId string) (samlPendingItem, bool) {
+	if key == "" {
+		return samlPendingItem{}, false
+	}
+
+	items := samlStore(app, storeKey)
+
+	var item samlPendingItem
+
+	// atomically "consume" the item to ensure that it could be used only once
+	items.SetFunc(key, func(old samlPendingItem) samlPendingItem {
+		item = old
+		return samlPendingItem{}
+	})
+	items.Remove(key)
+
+	return item, item.collectionId == collectionId && item.expiresAt >= time.Now().Unix()
+}
@@ -0,0 +1,530 @@
+package apis_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/saml"
+)
+
+const (
+	testSAMLIdPEntityId = "https://idp.example.com"
+	testSAMLIdPSSOURL   = "https://idp.example.com/sso"
+	testSAMLRedirectURL = "https://app.example.com/saml-callback"
+)
+
+func TestRecordSAMLMetadata(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "missing collection",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/missing/saml/metadata",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "non-auth collection",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/demo1/saml/metadata",
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:           "auth collection (with disabled SAML)",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/users/saml/metadata",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`<md:EntityDescriptor`,
+				`entityID="http://localhost:8090/api/collections/_pb_users_auth_/saml/metadata"`,
+				`Location="http://localhost:8090/api/collections/_pb_users_auth_/saml/acs"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "auth collection with custom entity id",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/saml/metadata",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				users, err := app.FindCollectionByNameOrId("users")
+				if err != nil {
+					t.Fatal(err)
+				}
+				users.SAML.EntityId = "urn:test:sp"
+				if err := app.Save(users); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`entityID="urn:test:sp"`,
+				`Location="http://localhost:8090/api/collections/_pb_users_auth_/saml/acs"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordSAMLLogin(t *testing.T) {
+	t.Parallel()
+
+	_, certPEM := newTestSAMLCertificate(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled SAML",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/users/saml/login",
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing state",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/saml/login",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTestSAML(t, app, certPEM)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "enabled SAML",
+			Method: http.MethodGet,
+			URL:    "/api/collections/users/saml/login?state=test_state",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTestSAML(t, app, certPEM)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				loc, err := url.Parse(res.Header.Get("Location"))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !strings.HasPrefix(loc.String(), testSAMLIdPSSOURL+"?") {
+					t.Fatalf("Expected redirect to the IdP, got %q", loc.String())
+				}
+
+				if loc.Query().Get("SAMLRequest") == "" {
+					t.Fatal("Expected non-empty SAMLRequest")
+				}
+
+				if !strings.HasPrefix(loc.Query().Get("RelayState"), "_") {
+					t.Fatalf("Expected the request id as RelayState, got %q", loc.Query().Get("RelayState"))
+				}
+			},
+			ExpectedStatus: 302,
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordSAMLACS(t *testing.T) {
+	t.Parallel()
+
+	_, certPEM := newTestSAMLCertificate(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:   "disabled SAML",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/saml/acs",
+			Body:   strings.NewReader("SAMLResponse=test&RelayState=test"),
+			Headers: map[string]string{
+				"Content-Type": "application/x-www-form-urlencoded",
+			},
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "unknown RelayState",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/saml/acs",
+			Body:   strings.NewReader("SAMLResponse=test&RelayState=_missing"),
+			Headers: map[string]string{
+				"Content-Type": "application/x-www-form-urlencoded",
+			},
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTestSAML(t, app, certPEM)
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				loc := res.Header.Get("Location")
+				if !strings.HasPrefix(loc, testSAMLRedirectURL+"?error=") {
+					t.Fatalf("Expected error redirect, got %q", loc)
+				}
+			},
+			ExpectedStatus: 303,
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithSAML(t *testing.T) {
+	t.Parallel()
+
+	_, certPEM := newTestSAMLCertificate(t)
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "disabled SAML",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-saml",
+			Body:            strings.NewReader(`{"code":"test"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-saml",
+			Body:   strings.NewReader(`{}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTestSAML(t, app, certPEM)
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"code":{"code":"validation_required"`,
+				`"state":{"code":"validation_required"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-saml",
+			Body:   strings.NewReader(`{"code":"test","state":"test"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableTestSAML(t, app, certPEM)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordSAMLFlow(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	key, certPEM := newTestSAMLCertificate(t)
+
+	users := enableTestSAML(t, app, certPEM)
+
+	pbRouter, err := apis.NewRouter(app)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mux, err := pbRouter.BuildMux()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	send := func(method string, path string, contentType string, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// AuthnRequest redirect
+	// ---
+	const state = "test_state_1234567890"
+
+	loginRec := send(http.MethodGet, "/api/collections/users/saml/login?state="+state, "", "")
+	if loginRec.Code != http.StatusFound {
+		t.Fatalf("Expected login status 302, got %d", loginRec.Code)
+	}
+
+	loginLoc, err := url.Parse(loginRec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestId := loginLoc.Query().Get("RelayState")
+
+	// ACS
+	// ---
+	acsURL := app.Settings().Meta.AppURL + "/api/collections/" + users.Id + "/saml/acs"
+	audience := app.Settings().Meta.AppURL + "/api/collections/" + users.Id + "/saml/metadata"
+
+	samlResponse := newTestSAMLResponse(t, key, requestId, acsURL, audience, "saml_user@example.com")
+
+	acsBody := url.Values{
+		"SAMLResponse": {samlResponse},
+		"RelayState":   {requestId},
+	}.Encode()
+
+	acsRec := send(http.MethodPost, "/api/collections/users/saml/acs", "application/x-www-form-urlencoded", acsBody)
+	if acsRec.Code != http.StatusSeeOther {
+		t.Fatalf("Expected ACS status 303, got %d", acsRec.Code)
+	}
+
+	acsLoc, err := url.Parse(acsRec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := acsLoc.Query().Get("samlCode")
+	if code == "" {
+		t.Fatalf("Expected samlCode redirect, got %q", acsLoc.String())
+	}
+	if v := acsLoc.Query().Get("state"); v != state {
+		t.Fatalf("Expected the login state %q in the redirect, got %q", state, v)
+	}
+
+	// the same request must not be accepted twice
+	replayRec := send(http.MethodPost, "/api/collections/users/saml/acs", "application/x-www-form-urlencoded", acsBody)
+	if loc := replayRec.Header().Get("Location"); !strings.Contains(loc, "error=") {
+		t.Fatalf("Expected replayed ACS error redirect, got %q", loc)
+	}
+
+	// code exchange
+	// ---
+	authRec := send(http.MethodPost, "/api/collections/users/auth-with-saml", "application/json", `{"code":"`+code+`","state":"`+state+`"}`)
+	if authRec.Code != http.StatusOK {
+		t.Fatalf("Expected auth status 200, got %d:\n%s", authRec.Code, authRec.Body.String())
+	}
+
+	var authResponse struct {
+		Token  string         `json:"token"`
+		Record map[string]any `json:"record"`
+		Meta   map[string]any `json:"meta"`
+	}
+	if err := json.Unmarshal(authRec.Body.Bytes(), &authResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	if authResponse.Token == "" {
+		t.Fatal("Expected non-empty auth token")
+	}
+
+	if v := authResponse.Record["email"]; v != "saml_user@example.com" {
+		t.Fatalf("Expected the SAML user email, got %v", v)
+	}
+
+	if v := authResponse.Record["name"]; v != "SAML User" {
+		t.Fatalf("Expected the mapped SAML user name, got %v", v)
+	}
+
+	if v := authResponse.Record["verified"]; v != true {
+		t.Fatalf("Expected verified record, got %v", v)
+	}
+
+	if v := authResponse.Meta["isNew"]; v != true {
+		t.Fatalf("Expected isNew meta, got %v", v)
+	}
+
+	externalAuth, err := app.FindFirstExternalAuthByExpr(dbx.HashExp{
+		"collectionRef": users.Id,
+		"provider":      core.SAMLProviderName,
+		"providerId":    "saml_user@example.com",
+	})
+	if err != nil {
+		t.Fatalf("Expected SAML external auth: %v", err)
+	}
+
+	if externalAuth.RecordRef() != authResponse.Record["id"] {
+		t.Fatalf("Expected external auth for record %v, got %q", authResponse.Record["id"], externalAuth.RecordRef())
+	}
+
+	// the code must not be accepted twice
+	reusedRec := send(http.MethodPost, "/api/collections/users/auth-with-saml", "application/json", `{"code":"`+code+`","state":"`+state+`"}`)
+	if reusedRec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected reused code status 400, got %d", reusedRec.Code)
+	}
+
+	// code exchange with a different client state (login CSRF)
+	// ---
+	loginRec = send(http.MethodGet, "/api/collections/users/saml/login?state="+state, "", "")
+	loginLoc, err = url.Parse(loginRec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestId = loginLoc.Query().Get("RelayState")
+
+	acsBody = url.Values{
+		"SAMLResponse": {newTestSAMLResponse(t, key, requestId, acsURL, audience, "saml_user@example.com")},
+		"RelayState":   {requestId},
+	}.Encode()
+
+	acsRec = send(http.MethodPost, "/api/collections/users/saml/acs", "application/x-www-form-urlencoded", acsBody)
+	acsLoc, err = url.Parse(acsRec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code = acsLoc.Query().Get("samlCode")
+	if code == "" {
+		t.Fatalf("Expected samlCode redirect, got %q", acsLoc.String())
+	}
+
+	mismatchRec := send(http.MethodPost, "/api/collections/users/auth-with-saml", "application/json", `{"code":"`+code+`","state":"other_state"}`)
+	if mismatchRec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected state mismatch status 400, got %d", mismatchRec.Code)
+	}
+
+	// the code is consumed on mismatch
+	mismatchReusedRec := send(http.MethodPost, "/api/collections/users/auth-with-saml", "application/json", `{"code":"`+code+`","state":"`+state+`"}`)
+	if mismatchReusedRec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected consumed code status 400, got %d", mismatchReusedRec.Code)
+	}
+}
+
+// -------------------------------------------------------------------
+
+func newTestSAMLCertificate(t testing.TB) (*rsa.PrivateKey, string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func enableTestSAML(t testing.TB, app core.App, certPEM string) *core.Collection {
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users.MFA.Enabled = false
+	users.SAML.Enabled = true
+	users.SAML.IdPEntityId = testSAMLIdPEntityId
+	users.SAML.IdPSSOURL = testSAMLIdPSSOURL
+	users.SAML.IdPCertificate = certPEM
+	users.SAML.RedirectURL = testSAMLRedirectURL
+	users.SAML.Duration = 300
+	users.SAML.MappedAttributes.Name = "displayName"
+	users.SAML.MappedFields.Name = "name"
+
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	return users
+}
+
+// newTestSAMLResponse returns a base64 encoded SAMLResponse with a signed assertion.
+//
+// The assertion and the SignedInfo are written directly in their exclusive
+// canonical form so that the raw strings could be digested and signed.
+func newTestSAMLResponse(t testing.TB, key *rsa.PrivateKey, requestId, acsURL, audience, nameId string) string {
+	now := time.Now().UTC().Format(time.RFC3339)
+	notOnOrAfter := time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+
+	assertion := func(signature string) string {
+		return `<saml:Assertion xmlns:saml="` + saml.NSAssertion + `" ID="_assertion1" IssueInstant="` + now + `" Version="2.0">` +
+			`<saml:Issuer>` + testSAMLIdPEntityId + `</saml:Issuer>` +
+			signature +
+			`<saml:Subject>` +
+			`<saml:NameID Format="` + saml.NameIdFormatEmail + `">` + nameId + `</saml:NameID>` +
+			`<saml:SubjectConfirmation Method="` + saml.SubjectConfirmationBearer + `">` +
+			`<saml:SubjectConfirmationData InResponseTo="` + requestId + `" NotOnOrAfter="` + notOnOrAfter + `" Recipient="` + acsURL + `"></saml:SubjectConfirmationData>` +
+			`</saml:SubjectConfirmation>` +
+			`</saml:Subject>` +
+			`<saml:Conditions NotBefore="` + now + `" NotOnOrAfter="` + notOnOrAfter + `">` +
+			`<saml:AudienceRestriction><saml:Audience>` + audience + `</saml:Audience></saml:AudienceRestriction>` +
+			`</saml:Conditions>` +
+			`<saml:AuthnStatement AuthnInstant="` + now + `" SessionIndex="_session1"></saml:AuthnStatement>` +
+			`<saml:AttributeStatement>` +
+			`<saml:Attribute Name="displayName"><saml:AttributeValue>SAML User</saml:AttributeValue></saml:Attribute>` +
+			`</saml:AttributeStatement>` +
+			`</saml:Assertion>`
+	}
+
+	digest := sha256.Sum256([]byte(assertion("")))
+
+	signedInfo := `<ds:SignedInfo xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		`<ds:CanonicalizationMethod Algorithm="` + saml.AlgExcC14N + `"></ds:CanonicalizationMethod>` +
+		`<ds:SignatureMethod Algorithm="` + saml.AlgRSASHA256 + `"></ds:SignatureMethod>` +
+		`<ds:Reference URI="#_assertion1">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="` + saml.AlgEnvelopedSignature + `"></ds:Transform>` +
+		`<ds:Transform Algorithm="` + saml.AlgExcC14N + `"></ds:Transform>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="` + saml.AlgSHA256 + `"></ds:DigestMethod>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+
+	signedInfoHash := sha256.Sum256([]byte(signedInfo))
+
+	signatureValue, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, signedInfoHash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature := `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#">` +
+		signedInfo +
+		`<ds:SignatureValue>` + base64.StdEncoding.EncodeToString(signatureValue) + `</ds:SignatureValue>` +
+		`</ds:Signature>`
+
+	var doc bytes.Buffer
+	doc.WriteString(`<samlp:Response xmlns:samlp="` + saml.NSProtocol + `" xmlns:saml="` + saml.NSAssertion + `"`)
+	doc.WriteString(` Destination="` + acsURL + `" ID="_response1" InResponseTo="` + requestId + `" IssueInstant="` + now + `" Version="2.0">`)
+	doc.WriteString(`<saml:Issuer>` + testSAMLIdPEntityId + `</saml:Issuer>`)
+	doc.WriteString(`<samlp:Status><samlp:StatusCode Value="` + saml.StatusSuccess + `"/></samlp:Status>`)
+	doc.WriteString(assertion(signature))
+	doc.WriteString(`</samlp:Response>`)
+
+	return base64.StdEncoding.EncodeToString(doc.Bytes())
+}
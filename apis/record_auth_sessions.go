@@ -0,0 +1,94 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+func recordListAuthSessions(e *core.RequestEvent) error {
+	sessions, err := e.App.FindAllAuthSessionsByRecord(e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the auth record sessions.", err)
+	}
+
+	currentSessionId := currentAuthSessionId(e)
+
+	result := make([]*core.AuthSession, 0, len(sessions))
+	for _, session := range sessions {
+		if session.HasExpired() {
+			continue
+		}
+
+		session.WithCustomData(true)
+		session.Set("current", session.Id == currentSessionId)
+
+		result = append(result, session)
+	}
+
+	return e.JSON(http.StatusOK, result)
+}
+
+func recordDeleteAuthSession(e *core.RequestEvent) error {
+	session := &core.AuthSession{}
+
+	err := e.App.RecordQuery(core.CollectionNameAuthSessions).
+		AndWhere(dbx.HashExp{
+			"id":            e.Request.PathValue("id"),
+			"collectionRef": e.Auth.Collection().Id,
+			"recordRef":     e.Auth.Id,
+		}).
+		Limit(1).
+		One(session)
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	if err = e.App.Delete(session); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to delete the auth session.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// recordDeleteOtherAuthSessions deletes all auth record sessions
+// except the one linked to the current request auth token (aka. "log out other devices").
+func recordDeleteOtherAuthSessions(e *core.RequestEvent) error {
+	sessions, err := e.App.FindAllAuthSessionsByRecord(e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the auth record sessions.", err)
+	}
+
+	currentSessionId := currentAuthSessionId(e)
+
+	err = e.App.RunInTransaction(func(txApp core.App) error {
+		for _, session := range sessions {
+			if session.Id == currentSessionId {
+				continue
+			}
+
+			if err := txApp.Delete(session); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to delete the auth sessions.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// currentAuthSessionId returns the auth session id linked to the
+// current request auth token (if any).
+func currentAuthSessionId(e *core.RequestEvent) string {
+	// note: the token is expected to be already verified by the loadAuthToken middleware
+	claims, _ := security.ParseUnverifiedJWT(getAuthTokenFromRequest(e))
+
+	return cast.ToString(claims[core.TokenClaimSessionId])
+}
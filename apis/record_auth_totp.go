@@ -0,0 +1,180 @@
+package apis
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// totpRecoveryCodesTotal is the number of the generated recovery codes on TOTP enrollment confirmation.
+const totpRecoveryCodesTotal = 10
+
+// totpRateLimitRule is an extra rate limit rule applied to all TOTP code checks
+// of a single auth record (regardless of the client IP) as a basic brute-force protection.
+var totpRateLimitRule = core.RateLimitRule{MaxRequests: 5, Duration: 180}
+
+func recordEnrollTOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.TOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow TOTP authentication.", nil)
+	}
+
+	record := e.Auth
+
+	totp, err := e.App.FindTOTPByRecord(record)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return e.InternalServerError("", err)
+	}
+
+	if totp != nil && totp.Verified() {
+		return e.BadRequestError("TOTP is already enabled for the current auth record.", nil)
+	}
+
+	// create new or replace the pending enrollment secret
+	if totp == nil {
+		totp = core.NewTOTP(e.App)
+		totp.SetCollectionRef(collection.Id)
+		totp.SetRecordRef(record.Id)
+	}
+
+	secret := security.NewTOTPSecret()
+
+	if err = totp.SetSecret(secret, core.TOTPEncryptionKey(e.App, collection)); err != nil {
+		return e.InternalServerError("Failed to encrypt the TOTP secret.", err)
+	}
+	totp.SetLastStep(0)
+
+	if err = e.App.Save(totp); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to save the TOTP enrollment.", err))
+	}
+
+	issuer := collection.TOTP.Issuer
+	if issuer == "" {
+		issuer = e.App.Settings().Meta.AppName
+	}
+
+	account := record.Email()
+	if account == "" {
+		account = record.Id
+	}
+
+	return e.JSON(http.StatusOK, map[string]string{
+		"secret": secret,
+		"url":    security.TOTPURL(secret, issuer, account),
+	})
+}
+
+func recordConfirmTOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.TOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow TOTP authentication.", nil)
+	}
+
+	record := e.Auth
+
+	form := &totpCodeForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	totp, err := e.App.FindTOTPByRecord(record)
+	if err != nil {
+		return e.BadRequestError("Missing TOTP enrollment.", err)
+	}
+
+	if totp.Verified() {
+		return e.BadRequestError("TOTP is already enabled for the current auth record.", nil)
+	}
+
+	err = checkRateLimitByKey(e, "@pb_totp", record.Id, totpRateLimitRule)
+	if err != nil {
+		return e.TooManyRequestsError("Too many attempts, please try again later.", nil)
+	}
+
+	_, err = e.App.ConsumeTOTPCode(totp, form.Code, core.TOTPEncryptionKey(e.App, collection), false)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidTOTPCode) {
+			return e.BadRequestError("Invalid TOTP code.", nil)
+		}
+		return firstApiError(err, e.InternalServerError("Failed to confirm the TOTP enrollment.", err))
+	}
+
+	totp.SetVerified(true)
+	recoveryCodes := totp.GenerateRecoveryCodes(totpRecoveryCodesTotal)
+
+	if err = e.App.Save(totp); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to confirm the TOTP enrollment.", err))
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+func recordDisableTOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	record := e.Auth
+
+	form := &totpCodeForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	totp, err := e.App.FindTOTPByRecord(record)
+	if err != nil || !totp.Verified() {
+		return e.BadRequestError("TOTP is not enabled for the current auth record.", err)
+	}
+
+	err = checkRateLimitByKey(e, "@pb_totp", record.Id, totpRateLimitRule)
+	if err != nil {
+		return e.TooManyRequestsError("Too many attempts, please try again later.", nil)
+	}
+
+	_, err = e.App.ConsumeTOTPCode(totp, form.Code, core.TOTPEncryptionKey(e.App, collection), true)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidTOTPCode) {
+			return e.BadRequestError("Invalid TOTP or recovery code.", nil)
+		}
+		return firstApiError(err, e.InternalServerError("Failed to disable TOTP.", err))
+	}
+
+	if err = e.App.Delete(totp); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to disable TOTP.", err))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// -------------------------------------------------------------------
+
+type totpCodeForm struct {
+	Code string `form:"code" json:"code"`
+}
+
+func (form *totpCodeForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+}
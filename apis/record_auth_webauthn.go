@@ -0,0 +1,344 @@
+package apis
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/store"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+// webauthnUsedChallengesStoreKey is the app store key with the already
+// used authentication challenges (used as a replay protection).
+const webauthnUsedChallengesStoreKey = "@webauthnUsedChallenges"
+
+// recordRequestWebAuthn returns the WebAuthn ceremony options
+// for the navigator.credentials.create() or navigator.credentials.get() calls.
+//
+// If the request is authenticated with a record from the same collection,
+// the registration options are returned, otherwise - the authentication
+// (discoverable credentials) options.
+func recordRequestWebAuthn(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	rpId := webauthnRPId(e.App, collection)
+	if rpId == "" {
+		return e.BadRequestError("Missing or invalid WebAuthn relying party id.", nil)
+	}
+
+	userVerification := "preferred"
+	if collection.WebAuthn.RequireUserVerification {
+		userVerification = "required"
+	}
+
+	timeout := collection.WebAuthn.DurationTime().Milliseconds()
+
+	// authentication options
+	// ---
+	if e.Auth == nil || e.Auth.Collection().Id != collection.Id {
+		challenge, err := core.NewWebAuthnChallenge(collection, "")
+		if err != nil {
+			return e.InternalServerError("Failed to generate WebAuthn challenge.", err)
+		}
+
+		return e.JSON(http.StatusOK, map[string]any{
+			"publicKey": webauthnRequestOptions{
+				Challenge:        challenge,
+				RPId:             rpId,
+				Timeout:          timeout,
+				UserVerification: userVerification,
+				AllowCredentials: []webauthnCredentialDescriptor{},
+			},
+		})
+	}
+
+	// registration options
+	// ---
+	challenge, err := core.NewWebAuthnChallenge(collection, e.Auth.Id)
+	if err != nil {
+		return e.InternalServerError("Failed to generate WebAuthn challenge.", err)
+	}
+
+	credentials, err := e.App.FindAllWebAuthnCredentialsByRecord(e.Auth)
+	if err != nil {
+		return e.InternalServerError("Failed to fetch the existing WebAuthn credentials.", err)
+	}
+
+	exclude := make([]webauthnCredentialDescriptor, len(credentials))
+	for i, c := range credentials {
+		exclude[i] = webauthnCredentialDescriptor{Type: "public-key", Id: c.CredentialId()}
+	}
+
+	params := make([]webauthnCredentialParam, len(webauthn.SupportedAlgorithms))
+	for i, alg := range webauthn.SupportedAlgorithms {
+		params[i] = webauthnCredentialParam{Type: "public-key", Alg: alg}
+	}
+
+	rpName := collection.WebAuthn.RPName
+	if rpName == "" {
+		rpName = e.App.Settings().Meta.AppName
+	}
+
+	userName := e.Auth.Email()
+	if userName == "" {
+		userName = e.Auth.Id
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"publicKey": webauthnCreationOptions{
+			Challenge: challenge,
+			RP: webauthnEntity{
+				Id:   rpId,
+				Name: rpName,
+			},
+			User: webauthnEntity{
+				Id:          webauthn.EncodeBase64([]byte(e.Auth.Id)),
+				Name:        userName,
+				DisplayName: userName,
+			},
+			PubKeyCredParams:   params,
+			Timeout:            timeout,
+			ExcludeCredentials: exclude,
+			AuthenticatorSelection: webauthnAuthenticatorSelection{
+				ResidentKey:      "required",
+				UserVerification: userVerification,
+			},
+			Attestation: "none",
+		},
+	})
+}
+
+// recordRegisterWebAuthn verifies the navigator.credentials.create()
+// response and stores the new WebAuthn credential of the current auth record.
+func recordRegisterWebAuthn(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	form := &registerWebAuthnForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	clientDataJSON, clientData, challenge, err := parseWebAuthnClientData(collection, form.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired WebAuthn challenge.", err)
+	}
+
+	if challenge.RecordId == "" || challenge.RecordId != e.Auth.Id {
+		return e.BadRequestError("Invalid or expired WebAuthn challenge.", errors.New("the challenge is not for the current auth record"))
+	}
+
+	attestationObject, err := webauthn.DecodeBase64(form.Credential.Response.AttestationObject)
+	if err != nil {
+		return e.BadRequestError("Invalid WebAuthn attestation object.", err)
+	}
+
+	authData, err := webauthn.VerifyRegistration(clientDataJSON, attestationObject, webauthn.Expectations{
+		RPId:                    webauthnRPId(e.App, collection),
+		Challenge:               clientData.Challenge,
+		RequireUserVerification: collection.WebAuthn.RequireUserVerification,
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to verify the WebAuthn credential.", err)
+	}
+
+	credentialId := webauthn.EncodeBase64(authData.CredentialId)
+
+	if _, err := e.App.FindWebAuthnCredentialByCredentialId(collection, credentialId); err == nil {
+		return e.BadRequestError("The WebAuthn credential is already registered.", nil)
+	}
+
+	credential := core.NewWebAuthnCredential(e.App)
+	credential.SetCollectionRef(collection.Id)
+	credential.SetRecordRef(e.Auth.Id)
+	credential.SetCredentialId(credentialId)
+	credential.SetPublicKey(authData.PublicKey)
+	credential.SetSignCount(authData.SignCount)
+	credential.SetName(form.Name)
+
+	if err = e.App.Save(credential); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to save the WebAuthn credential.", err))
+	}
+
+	return e.JSON(http.StatusOK, credential)
+}
+
+// -------------------------------------------------------------------
+
+// webauthnRPId returns the WebAuthn relying party id of the collection
+// (fallbacks to the application URL host).
+func webauthnRPId(app core.App, collection *core.Collection) string {
+	if collection.WebAuthn.RPId != "" {
+		return collection.WebAuthn.RPId
+	}
+
+	u, err := url.Parse(app.Settings().Meta.AppURL)
+	if err != nil {
+		return ""
+	}
+
+	return u.Hostname()
+}
+
+// parseWebAuthnClientData decodes the base64url clientDataJSON and
+// verifies its challenge.
+func parseWebAuthnClientData(collection *core.Collection, encoded string) ([]byte, *webauthn.ClientData, *core.WebAuthnChallenge, error) {
+	raw, err := webauthn.DecodeBase64(encoded)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	clientData, err := webauthn.ParseClientData(raw)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	challenge, err := core.ParseWebAuthnChallenge(collection, clientData.Challenge)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return raw, clientData, challenge, nil
+}
+
+// useWebAuthnChallenge marks the challenge as used and reports
+// whether it wasn't already used.
+func useWebAuthnChallenge(app core.App, challenge *core.WebAuthnChallenge) bool {
+	used := app.Store().GetOrSet(webauthnUsedChallengesStoreKey, func() any {
+		return store.New[string, int64](nil)
+	}).(*store.Store[string, int64])
+
+	// cleanup the expired challenges
+	now := time.Now().Unix()
+	for nonce, exp := range used.GetAll() {
+		if exp < now {
+			used.Remove(nonce)
+		}
+	}
+
+	var alreadyUsed bool
+	used.SetFunc(challenge.Nonce, func(old int64) int64 {
+		alreadyUsed = old > 0
+		return challenge.ExpiresAt
+	})
+
+	return !alreadyUsed
+}
+
+// -------------------------------------------------------------------
+
+type webauthnEntity struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+type webauthnCredentialParam struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type webauthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	Id   string `json:"id"`
+}
+
+type webauthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+type webauthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RP                     webauthnEntity                 `json:"rp"`
+	User                   webauthnEntity                 `json:"user"`
+	PubKeyCredParams       []webauthnCredentialParam      `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	ExcludeCredentials     []webauthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection webauthnAuthenticatorSelection `json:"authenticatorSelection"`
+	Attestation            string                         `json:"attestation"`
+}
+
+type webauthnRequestOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RPId             string                         `json:"rpId"`
+	Timeout          int64                          `json:"timeout"`
+	UserVerification string                         `json:"userVerification"`
+	AllowCredentials []webauthnCredentialDescriptor `json:"allowCredentials"`
+}
+
+// webauthnCredentialForm is the JSON serialized PublicKeyCredential
+// (ex. the result of credential.toJSON()).
+type webauthnCredentialForm struct {
+	Id       string                         `form:"id" json:"id"`
+	RawId    string                         `form:"rawId" json:"rawId"`
+	Type     string                         `form:"type" json:"type"`
+	Response webauthnCredentialResponseForm `form:"response" json:"response"`
+}
+
+type webauthnCredentialResponseForm struct {
+	ClientDataJSON    string `form:"clientDataJSON" json:"clientDataJSON"`
+	AttestationObject string `form:"attestationObject" json:"attestationObject"`
+	AuthenticatorData string `form:"authenticatorData" json:"authenticatorData"`
+	Signature         string `form:"signature" json:"signature"`
+	UserHandle        string `form:"userHandle" json:"userHandle"`
+}
+
+// credentialId returns the normalized base64url encoded credential id.
+func (form *webauthnCredentialForm) credentialId() (string, error) {
+	id := form.RawId
+	if id == "" {
+		id = form.Id
+	}
+
+	raw, err := webauthn.DecodeBase64(id)
+	if err != nil {
+		return "", err
+	}
+
+	return webauthn.EncodeBase64(raw), nil
+}
+
+type registerWebAuthnForm struct {
+	Name       string                 `form:"name" json:"name"`
+	Credential webauthnCredentialForm `form:"credential" json:"credential"`
+}
+
+func (form *registerWebAuthnForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Name, validation.Length(0, 100)),
+		validation.Field(&form.Credential, validation.By(func(_ any) error {
+			c := &form.Credential
+			return validation.ValidateStruct(c,
+				validation.Field(&c.Type, validation.Required, validation.In("public-key")),
+				validation.Field(&c.Response, validation.By(func(_ any) error {
+					r := &c.Response
+					return validation.ValidateStruct(r,
+						validation.Field(&r.ClientDataJSON, validation.Required),
+						validation.Field(&r.AttestationObject, validation.Required),
+					)
+				})),
+			)
+		})),
+	)
+}
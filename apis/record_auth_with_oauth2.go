@@ -15,6 +15,7 @@ import (
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/dbx"
 	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"golang.org/x/oauth2"
@@ -30,11 +31,6 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 		return e.ForbiddenError("The collection is not configured to allow OAuth2 authentication.", nil)
 	}
 
-	var fallbackAuthRecord *core.Record
-	if e.Auth != nil && e.Auth.Collection().Id == collection.Id {
-		fallbackAuthRecord = e.Auth
-	}
-
 	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextOAuth2)
 
 	form := new(recordOAuth2LoginForm)
@@ -72,10 +68,21 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 	provider.SetContext(ctx)
 	provider.SetRedirectURL(form.RedirectURL)
 
+	// load the missing urls from the OIDC issuer discovery document (if configured)
+	if oidc, ok := provider.(*auth.OIDC); ok {
+		if err := oidc.Discover(); err != nil {
+			return firstApiError(err, e.InternalServerError("Failed to init provider "+form.Provider, err))
+		}
+	}
+
 	var opts []oauth2.AuthCodeOption
 
 	if provider.PKCE() {
 		opts = append(opts, oauth2.SetAuthURLParam("code_verifier", form.CodeVerifier))
+
+		if oidc, ok := provider.(*auth.OIDC); ok && oidc.Issuer() != "" {
+			oidc.SetNonce(auth.OIDCNonce(form.CodeVerifier))
+		}
 	}
 
 	// fetch token
@@ -90,12 +97,34 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 		return firstApiError(err, e.BadRequestError("Failed to fetch OAuth2 user.", err))
 	}
 
+	return authWithExternalUser(e, collection, form.Provider, provider, authUser, form.CreateData, core.MFAMethodOAuth2)
+}
+
+// authWithExternalUser authenticates (and links if missing) the auth record
+// associated with the provided verified external user.
+//
+// It is shared between the OAuth2 and the SAML auth flows and both
+// trigger the OnRecordAuthWithOAuth2Request hook.
+func authWithExternalUser(
+	e *core.RequestEvent,
+	collection *core.Collection,
+	providerName string,
+	providerClient auth.Provider,
+	authUser *auth.AuthUser,
+	createData map[string]any,
+	mfaMethod string,
+) error {
+	var fallbackAuthRecord *core.Record
+	if e.Auth != nil && e.Auth.Collection().Id == collection.Id {
+		fallbackAuthRecord = e.Auth
+	}
+
 	var authRecord *core.Record
 
 	// check for existing relation with the auth collection
 	externalAuthRel, err := e.App.FindFirstExternalAuthByExpr(dbx.HashExp{
-		"collectionRef": form.collection.Id,
-		"provider":      form.Provider,
+		"collectionRef": collection.Id,
+		"provider":      providerName,
 		"providerId":    authUser.Id,
 	})
 	if err != nil && !errors.Is(err, sql.ErrNoRows) {
@@ -104,16 +133,16 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 
 	switch {
 	case err == nil && externalAuthRel != nil:
-		authRecord, err = e.App.FindRecordById(form.collection, externalAuthRel.RecordRef())
+		authRecord, err = e.App.FindRecordById(collection, externalAuthRel.RecordRef())
 		if err != nil {
 			return err
 		}
-	case fallbackAuthRecord != nil && fallbackAuthRecord.Collection().Id == form.collection.Id:
+	case fallbackAuthRecord != nil:
 		// fallback to the logged auth record (if any)
 		authRecord = fallbackAuthRecord
 	case authUser.Email != "":
 		// look for an existing auth record by the external auth record's email
-		authRecord, err = e.App.FindAuthRecordByEmail(form.collection.Id, authUser.Email)
+		authRecord, err = e.App.FindAuthRecordByEmail(collection.Id, authUser.Email)
 		if err != nil && !errors.Is(err, sql.ErrNoRows) {
 			return e.InternalServerError("Failed OAuth2 auth record check.", err)
 		}
@@ -124,10 +153,10 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 	event := new(core.RecordAuthWithOAuth2RequestEvent)
 	event.RequestEvent = e
 	event.Collection = collection
-	event.ProviderName = form.Provider
-	event.ProviderClient = provider
+	event.ProviderName = providerName
+	event.ProviderClient = providerClient
 	event.OAuth2User = authUser
-	event.CreateData = form.CreateData
+	event.CreateData = createData
 	event.Record = authRecord
 	event.IsNewRecord = authRecord == nil
 
@@ -148,7 +177,7 @@ func recordAuthWithOAuth2(e *core.RequestEvent) error {
 		}
 		meta["isNew"] = e.IsNewRecord
 
-		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodOAuth2, meta)
+		return RecordAuthResponse(e.RequestEvent, e.Record, mfaMethod, meta)
 	})
 }
 
@@ -199,9 +228,9 @@ func (form *recordOAuth2LoginForm) checkProviderName(value any) error {
 	return nil
 }
 
-func oldCanAssignUsername(txApp core.App, collection *core.Collection, username string) bool {
+func oldCanAssignUsername(txApp core.App, collection *core.Collection, fieldName string, username string) bool {
 	// ensure that username is unique
-	index, hasUniqueue := dbutils.FindSingleColumnUniqueIndex(collection.Indexes, collection.OAuth2.MappedFields.Username)
+	index, hasUniqueue := dbutils.FindSingleColumnUniqueIndex(collection.Indexes, fieldName)
 	if hasUniqueue {
 		var expr dbx.Expression
 		if strings.EqualFold(index.Columns[0].Collate, "nocase") {
@@ -219,12 +248,23 @@ func oldCanAssignUsername(txApp core.App, collection *core.Collection, username
 	}
 
 	// ensure that the value matches the pattern of the username field (if text)
-	txtField, _ := collection.Fields.GetByName(collection.OAuth2.MappedFields.Username).(*core.TextField)
+	txtField, _ := collection.Fields.GetByName(fieldName).(*core.TextField)
 
 	return txtField != nil && txtField.ValidatePlainValue(username) == nil
 }
 
+// externalAuthMappedFields returns the collection fields mapping of the event external provider.
+func externalAuthMappedFields(e *core.RecordAuthWithOAuth2RequestEvent) core.OAuth2KnownFields {
+	if e.ProviderName == core.SAMLProviderName {
+		return e.Collection.SAML.MappedFields
+	}
+
+	return e.Collection.OAuth2.MappedFields
+}
+
 func oauth2Submit(e *core.RecordAuthWithOAuth2RequestEvent, optExternalAuth *core.ExternalAuth) error {
+	mappedFields := externalAuthMappedFields(e)
+
 	return e.App.RunInTransaction(func(txApp core.App) error {
 		if e.Record == nil {
 			// extra check to prevent creating a superuser record via
@@ -245,25 +285,25 @@ func oauth2Submit(e *core.RecordAuthWithOAuth2RequestEvent, optExternalAuth *cor
 			}
 
 			// map known fields (unless the field was explicitly submitted as part of CreateData)
-			if _, ok := payload[e.Collection.OAuth2.MappedFields.Id]; !ok && e.Collection.OAuth2.MappedFields.Id != "" {
-				payload[e.Collection.OAuth2.MappedFields.Id] = e.OAuth2User.Id
+			if _, ok := payload[mappedFields.Id]; !ok && mappedFields.Id != "" {
+				payload[mappedFields.Id] = e.OAuth2User.Id
 			}
-			if _, ok := payload[e.Collection.OAuth2.MappedFields.Name]; !ok && e.Collection.OAuth2.MappedFields.Name != "" {
-				payload[e.Collection.OAuth2.MappedFields.Name] = e.OAuth2User.Name
+			if _, ok := payload[mappedFields.Name]; !ok && mappedFields.Name != "" {
+				payload[mappedFields.Name] = e.OAuth2User.Name
 			}
-			if _, ok := payload[e.Collection.OAuth2.MappedFields.Username]; !ok &&
+			if _, ok := payload[mappedFields.Username]; !ok &&
 				// no explicit username payload value and existing OAuth2 mapping
-				e.Collection.OAuth2.MappedFields.Username != "" &&
+				mappedFields.Username != "" &&
 				// extra checks for backward compatibility with earlier versions
-				oldCanAssignUsername(txApp, e.Collection, e.OAuth2User.Username) {
-				payload[e.Collection.OAuth2.MappedFields.Username] = e.OAuth2User.Username
+				oldCanAssignUsername(txApp, e.Collection, mappedFields.Username, e.OAuth2User.Username) {
+				payload[mappedFields.Username] = e.OAuth2User.Username
 			}
-			if _, ok := payload[e.Collection.OAuth2.MappedFields.AvatarURL]; !ok &&
+			if _, ok := payload[mappedFields.AvatarURL]; !ok &&
 				// no explicit avatar payload value and existing OAuth2 mapping
-				e.Collection.OAuth2.MappedFields.AvatarURL != "" &&
+				mappedFields.AvatarURL != "" &&
 				// non-empty OAuth2 avatar url
 				e.OAuth2User.AvatarURL != "" {
-				mappedField := e.Collection.Fields.GetByName(e.Collection.OAuth2.MappedFields.AvatarURL)
+				mappedField := e.Collection.Fields.GetByName(mappedFields.AvatarURL)
 				if mappedField != nil && mappedField.Type() == core.FieldTypeFile {
 					// download the avatar if the mapped field is a file
 					avatarFile, err := func() (*filesystem.File, error) {
@@ -274,11 +314,11 @@ func oauth2Submit(e *core.RecordAuthWithOAuth2RequestEvent, optExternalAuth *cor
 					if err != nil {
 						txApp.Logger().Warn("Failed to retrieve OAuth2 avatar", slog.String("error", err.Error()))
 					} else {
-						payload[e.Collection.OAuth2.MappedFields.AvatarURL] = avatarFile
+						payload[mappedFields.AvatarURL] = avatarFile
 					}
 				} else {
 					// otherwise - assign the url string
-					payload[e.Collection.OAuth2.MappedFields.AvatarURL] = e.OAuth2User.AvatarURL
+					payload[mappedFields.AvatarURL] = e.OAuth2User.AvatarURL
 				}
 			}
 
@@ -356,8 +396,13 @@ func sendOAuth2RecordCreateRequest(txApp core.App, e *core.RecordAuthWithOAuth2R
 		Body:   payload,
 	}
 
+	requestContext := core.RequestInfoContextOAuth2
+	if e.ProviderName == core.SAMLProviderName {
+		requestContext = core.RequestInfoContextSAML
+	}
+
 	var createdRecord *core.Record
-	response, err := processInternalRequest(txApp, e.RequestEvent, ir, core.RequestInfoContextOAuth2, func(data any) error {
+	response, err := processInternalRequest(txApp, e.RequestEvent, ir, requestContext, func(data any) error {
 		createdRecord, _ = data.(*core.Record)
 
 		return nil
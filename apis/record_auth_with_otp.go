@@ -59,7 +59,16 @@ func recordAuthWithOTP(e *core.RequestEvent) error {
 		return e.TooManyRequestsError("Too many attempts, please try again later with a new OTP.", nil)
 	}
 
+	if isAuthLocked(e, event.Record, core.MFAMethodOTP, collection.OTP.Lockout) {
+		return e.BadRequestError("Invalid or expired OTP", errors.New("the auth record is temporary locked"))
+	}
+
 	if !event.OTP.ValidatePassword(form.Password) {
+		err = registerAuthFailure(e, event.Record, core.MFAMethodOTP, collection.OTP.Lockout)
+		if err != nil {
+			return firstApiError(err, e.InternalServerError("Failed to register the failed auth attempt.", err))
+		}
+
 		return e.BadRequestError("Invalid or expired OTP", errors.New("incorrect password"))
 	}
 	// ---
@@ -87,6 +96,11 @@ func recordAuthWithOTP(e *core.RequestEvent) error {
 			e.App.Logger().Error("Failed to delete used OTP", "error", err, "otpId", e.OTP.Id)
 		}
 
+		err = resetAuthFailures(e.RequestEvent, e.Record, core.MFAMethodOTP, e.Collection.OTP.Lockout)
+		if err != nil {
+			e.App.Logger().Warn("Failed to reset the failed OTP auth attempts", "error", err, "recordId", e.Record.Id)
+		}
+
 		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodOTP, nil)
 	})
 }
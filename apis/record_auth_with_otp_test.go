@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
@@ -160,6 +161,113 @@ func TestRecordAuthWithOTP(t *testing.T) {
 			ExpectedContent: []string{`"data":{}`},
 			ExpectedEvents:  map[string]int{"*": 0},
 		},
+		{
+			Name:   "otp with wrong password and enabled lockout",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-otp",
+			Body: strings.NewReader(`{
+				"otpId":"` + strings.Repeat("a", 15) + `",
+				"password":"123456"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				user.Collection().OTP.Lockout.Enabled = true
+				user.Collection().OTP.Lockout.MaxAttempts = 1
+				user.Collection().OTP.Lockout.Duration = 60
+				user.Collection().OTP.Lockout.MaxDuration = 3600
+				if err = app.Save(user.Collection()); err != nil {
+					t.Fatal(err)
+				}
+
+				otp := core.NewOTP(app)
+				otp.Id = strings.Repeat("a", 15)
+				otp.SetCollectionRef(user.Collection().Id)
+				otp.SetRecordRef(user.Id)
+				otp.SetPassword("1234567890")
+				if err := app.Save(otp); err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				lockout, err := app.FindAuthLockout(user, core.MFAMethodOTP)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !lockout.IsLocked() {
+					t.Fatal("Expected the user to be locked")
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents: map[string]int{
+				"*":                   0,
+				"OnRecordAuthLockout": 1,
+				// lockout create
+				"OnModelCreate":              1,
+				"OnModelCreateExecute":       1,
+				"OnModelAfterCreateSuccess":  1,
+				"OnModelValidate":            1,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordValidate":           1,
+			},
+		},
+		{
+			Name:   "valid otp with valid password and locked record",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-otp",
+			Body: strings.NewReader(`{
+				"otpId":"` + strings.Repeat("a", 15) + `",
+				"password":"123456"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				user.Collection().OTP.Lockout.Enabled = true
+				user.Collection().OTP.Lockout.MaxAttempts = 1
+				user.Collection().OTP.Lockout.Duration = 60
+				user.Collection().OTP.Lockout.MaxDuration = 3600
+				if err = app.Save(user.Collection()); err != nil {
+					t.Fatal(err)
+				}
+
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				lockout.SetRecordRef(user.Id)
+				lockout.SetMethod(core.MFAMethodOTP)
+				lockout.SetFailures(1)
+				lockout.SetLockedUntil(types.NowDateTime().Add(1 * time.Hour))
+				if err = app.Save(lockout); err != nil {
+					t.Fatal(err)
+				}
+
+				otp := core.NewOTP(app)
+				otp.Id = strings.Repeat("a", 15)
+				otp.SetCollectionRef(user.Collection().Id)
+				otp.SetRecordRef(user.Id)
+				otp.SetPassword("123456")
+				if err := app.Save(otp); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
 		{
 			Name:   "expired otp with valid password",
 			Method: http.MethodPost,
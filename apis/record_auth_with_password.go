@@ -66,6 +66,11 @@ func recordAuthWithPassword(e *core.RequestEvent) error {
 		return e.InternalServerError("", foundErr)
 	}
 
+	// note: returns the same generic error as for invalid credentials to prevent accounts enumeration
+	if isAuthLocked(e, foundRecord, core.MFAMethodPassword, collection.PasswordAuth.Lockout) {
+		return e.BadRequestError("Failed to authenticate.", errors.New("the auth record is temporary locked"))
+	}
+
 	event := new(core.RecordAuthWithPasswordRequestEvent)
 	event.RequestEvent = e
 	event.Collection = collection
@@ -76,9 +81,19 @@ func recordAuthWithPassword(e *core.RequestEvent) error {
 
 	return e.App.OnRecordAuthWithPasswordRequest().Trigger(event, func(e *core.RecordAuthWithPasswordRequestEvent) error {
 		if e.Record == nil || !e.Record.ValidatePassword(e.Password) {
+			err := registerAuthFailure(e.RequestEvent, e.Record, core.MFAMethodPassword, e.Collection.PasswordAuth.Lockout)
+			if err != nil {
+				return firstApiError(err, e.InternalServerError("Failed to register the failed auth attempt.", err))
+			}
+
 			return e.BadRequestError("Failed to authenticate.", errors.New("invalid login credentials"))
 		}
 
+		err := resetAuthFailures(e.RequestEvent, e.Record, core.MFAMethodPassword, e.Collection.PasswordAuth.Lockout)
+		if err != nil {
+			e.App.Logger().Warn("Failed to reset the failed password auth attempts", "error", err, "recordId", e.Record.Id)
+		}
+
 		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodPassword, nil)
 	})
 }
@@ -1,13 +1,17 @@
 package apis_test
 
 import (
+	"encoding/json"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestRecordAuthWithPassword(t *testing.T) {
@@ -39,6 +43,42 @@ func TestRecordAuthWithPassword(t *testing.T) {
 		}
 	}
 
+	// enableLockout enables the clients password auth lockout and
+	// stubs the specified failed attempts (optionally locked) for test@example.com.
+	enableLockout := func(failures int, locked bool) func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+		return func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+			client, err := app.FindAuthRecordByEmail("clients", "test@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clients := client.Collection()
+			clients.PasswordAuth.Lockout.Enabled = true
+			clients.PasswordAuth.Lockout.MaxAttempts = 2
+			clients.PasswordAuth.Lockout.Duration = 60
+			clients.PasswordAuth.Lockout.MaxDuration = 3600
+			if err = app.Save(clients); err != nil {
+				t.Fatal(err)
+			}
+
+			if failures == 0 {
+				return
+			}
+
+			lockout := core.NewAuthLockout(app)
+			lockout.SetCollectionRef(clients.Id)
+			lockout.SetRecordRef(client.Id)
+			lockout.SetMethod(core.MFAMethodPassword)
+			lockout.SetFailures(failures)
+			if locked {
+				lockout.SetLockedUntil(types.NowDateTime().Add(1 * time.Hour))
+			}
+			if err = app.Save(lockout); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
 	scenarios := []tests.ApiScenario{
 		{
 			Name:            "disabled password auth",
@@ -156,6 +196,7 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				// hidden fields
 				`"tokenKey"`,
 				`"password"`,
+				`"passwordExpired"`,
 			},
 			ExpectedEvents: map[string]int{
 				"*":                               0,
@@ -212,6 +253,365 @@ func TestRecordAuthWithPassword(t *testing.T) {
 				"OnMailerRecordAuthAlertSend": 1,
 			},
 		},
+		{
+			Name:   "valid identity field and valid password with enabled refresh tokens",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				clients, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				clients.RefreshToken.Enabled = true
+				clients.RefreshToken.Duration = 3600
+				if err = app.Save(clients); err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				body := struct {
+					Token        string `json:"token"`
+					RefreshToken string `json:"refreshToken"`
+				}{}
+				if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+
+				session, err := app.FindAuthSessionByRefreshToken(body.RefreshToken)
+				if err != nil {
+					t.Fatalf("Expected the refresh token session to be created, got %v", err)
+				}
+
+				claims, _ := security.ParseUnverifiedJWT(body.Token)
+				if v := claims[core.TokenClaimSessionId]; v != session.Id {
+					t.Fatalf("Expected the token to be bound to session %q, got %#v", session.Id, v)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"email":"test@example.com"`,
+				`"token":`,
+				`"refreshToken":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// authSession and authOrigin create
+				"OnModelCreate":               2,
+				"OnModelCreateExecute":        2,
+				"OnModelAfterCreateSuccess":   2,
+				"OnModelValidate":             2,
+				"OnRecordCreate":              2,
+				"OnRecordCreateExecute":       2,
+				"OnRecordAfterCreateSuccess":  2,
+				"OnRecordValidate":            2,
+				"OnMailerSend":                1,
+				"OnMailerRecordAuthAlertSend": 1,
+			},
+		},
+		{
+			Name:   "repeated sign-in from the same device with enabled refresh tokens",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				clients, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				clients.RefreshToken.Enabled = true
+				clients.RefreshToken.Duration = 3600
+				if err = app.Save(clients); err != nil {
+					t.Fatal(err)
+				}
+
+				client, err := app.FindAuthRecordByEmail(clients, "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// existing session with the same device fingerprint
+				session := core.NewAuthSession(app)
+				session.SetCollectionRef(clients.Id)
+				session.SetRecordRef(client.Id)
+				session.SetFingerprint("d0f88d6c87767262ba8e93d6acccd784")
+				session.Set("id", "existingsession")
+				app.Store().Set("testExistingRefreshToken", session.RotateRefreshToken(1*time.Hour))
+				if err = app.Save(session); err != nil {
+					t.Fatal(err)
+				}
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				body := struct {
+					RefreshToken string `json:"refreshToken"`
+				}{}
+				if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+					t.Fatal(err)
+				}
+
+				session, err := app.FindAuthSessionByRefreshToken(body.RefreshToken)
+				if err != nil {
+					t.Fatalf("Expected the refresh token session to be created, got %v", err)
+				}
+				if session.Id == "existingsession" {
+					t.Fatal("Expected a new session to be created")
+				}
+
+				existing, err := app.FindAuthSessionById("existingsession")
+				if err != nil {
+					t.Fatalf("Expected the existing session to remain, got %v", err)
+				}
+				existingToken, _ := app.Store().Get("testExistingRefreshToken").(string)
+				if !existing.ValidateRefreshToken(existingToken) {
+					t.Fatal("Expected the existing session refresh token to remain valid")
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"email":"test@example.com"`,
+				`"token":`,
+				`"refreshToken":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// authSession and authOrigin create
+				"OnModelCreate":               2,
+				"OnModelCreateExecute":        2,
+				"OnModelAfterCreateSuccess":   2,
+				"OnModelValidate":             2,
+				"OnRecordCreate":              2,
+				"OnRecordCreateExecute":       2,
+				"OnRecordAfterCreateSuccess":  2,
+				"OnRecordValidate":            2,
+				"OnMailerSend":                1,
+				"OnMailerRecordAuthAlertSend": 1,
+			},
+		},
+		{
+			Name:   "valid identity field and invalid password with enabled lockout (max attempts reached)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"invalid"
+			}`),
+			BeforeTestFunc: enableLockout(1, false),
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				client, err := app.FindAuthRecordByEmail("clients", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				lockout, err := app.FindAuthLockout(client, core.MFAMethodPassword)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if lockout.Failures() != 2 || !lockout.IsLocked() {
+					t.Fatalf("Expected locked lockout with 2 failures, got %d failures (locked: %v)", lockout.Failures(), lockout.IsLocked())
+				}
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{}`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthLockout":             1,
+				// lockout update
+				"OnModelUpdate":              1,
+				"OnModelUpdateExecute":       1,
+				"OnModelAfterUpdateSuccess":  1,
+				"OnModelValidate":            1,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+				"OnRecordValidate":           1,
+			},
+		},
+		{
+			Name:   "valid identity field and valid password with locked record",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc:  enableLockout(1, true),
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid identity field and valid password with previous failed attempts",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: enableLockout(1, false),
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				client, err := app.FindAuthRecordByEmail("clients", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := app.FindAuthLockout(client, core.MFAMethodPassword); err == nil {
+					t.Fatal("Expected the failed attempts to be reset")
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"email":"test@example.com"`,
+				`"token":`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// lockout delete
+				"OnModelDelete":              1,
+				"OnModelDeleteExecute":       1,
+				"OnModelAfterDeleteSuccess":  1,
+				"OnRecordDelete":             1,
+				"OnRecordDeleteExecute":      1,
+				"OnRecordAfterDeleteSuccess": 1,
+				// authOrigin track
+				"OnModelCreate":               1,
+				"OnModelCreateExecute":        1,
+				"OnModelAfterCreateSuccess":   1,
+				"OnModelValidate":             1,
+				"OnRecordCreate":              1,
+				"OnRecordCreateExecute":       1,
+				"OnRecordAfterCreateSuccess":  1,
+				"OnRecordValidate":            1,
+				"OnMailerSend":                1,
+				"OnMailerRecordAuthAlertSend": 1,
+			},
+		},
+		{
+			Name:   "valid identity field and valid password with expired password max age",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				clients, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// the current password is tracked on max age enable
+				clients.Fields.GetByName(core.FieldNamePassword).(*core.PasswordField).MaxAge = 3600
+				if err = app.Save(clients); err != nil {
+					t.Fatal(err)
+				}
+
+				client, err := app.FindAuthRecordByEmail(clients, "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				changes, err := app.FindRecentPasswordChanges(client, 1)
+				if err != nil || len(changes) != 1 {
+					t.Fatalf("Expected the current password to be tracked, got %v (%v)", changes, err)
+				}
+
+				// make the change older than the max age
+				changes[0].SetRaw("created", types.NowDateTime().Add(-2*time.Hour))
+				if err = app.SaveNoValidate(changes[0]); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"email":"test@example.com"`,
+				`"token":`,
+				`"passwordExpired":true`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// authOrigin track
+				"OnModelCreate":               1,
+				"OnModelCreateExecute":        1,
+				"OnModelAfterCreateSuccess":   1,
+				"OnModelValidate":             1,
+				"OnRecordCreate":              1,
+				"OnRecordCreateExecute":       1,
+				"OnRecordAfterCreateSuccess":  1,
+				"OnRecordValidate":            1,
+				"OnMailerSend":                1,
+				"OnMailerRecordAuthAlertSend": 1,
+			},
+		},
+		{
+			Name:   "valid identity field and valid password with just enabled password max age",
+			Method: http.MethodPost,
+			URL:    "/api/collections/clients/auth-with-password",
+			Body: strings.NewReader(`{
+				"identity":"test@example.com",
+				"password":"1234567890"
+			}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				clients, err := app.FindCollectionByNameOrId("clients")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// the record is older than the max age but its password age starts from now
+				clients.Fields.GetByName(core.FieldNamePassword).(*core.PasswordField).MaxAge = 3600
+				if err = app.Save(clients); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"email":"test@example.com"`,
+				`"token":`,
+			},
+			NotExpectedContent: []string{
+				`"passwordExpired":true`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithPasswordRequest": 1,
+				"OnRecordAuthRequest":             1,
+				"OnRecordEnrich":                  1,
+				// authOrigin track
+				"OnModelCreate":               1,
+				"OnModelCreateExecute":        1,
+				"OnModelAfterCreateSuccess":   1,
+				"OnModelValidate":             1,
+				"OnRecordCreate":              1,
+				"OnRecordCreateExecute":       1,
+				"OnRecordAfterCreateSuccess":  1,
+				"OnRecordValidate":            1,
+				"OnMailerSend":                1,
+				"OnMailerRecordAuthAlertSend": 1,
+			},
+		},
 		{
 			Name:   "unknown explicit identityField",
 			Method: http.MethodPost,
@@ -0,0 +1,94 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+func recordAuthWithTOTP(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.TOTP.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow TOTP authentication.", nil)
+	}
+
+	form := &authWithTOTPForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextTOTP)
+
+	event := new(core.RecordAuthWithTOTPRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+
+	// extra validations
+	// (note: TOTP is allowed only as additional MFA method because the
+	// auth record is resolved from the MFA session of the first auth method)
+	// ---
+	mfa, err := e.App.FindMFAById(form.MFAId)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired MFA session.", err)
+	}
+
+	if mfa.CollectionRef() != collection.Id {
+		return e.BadRequestError("Invalid or expired MFA session.", errors.New("the MFA is for a different collection"))
+	}
+
+	if mfa.HasExpired(collection.MFA.DurationTime()) {
+		return e.BadRequestError("Invalid or expired MFA session.", errors.New("the MFA is expired"))
+	}
+
+	event.Record, err = e.App.FindRecordById(collection, mfa.RecordRef())
+	if err != nil {
+		return e.BadRequestError("Invalid or expired MFA session.", fmt.Errorf("missing auth record: %w", err))
+	}
+
+	err = checkRateLimitByKey(e, "@pb_totp", event.Record.Id, totpRateLimitRule)
+	if err != nil {
+		return e.TooManyRequestsError("Too many attempts, please try again later.", nil)
+	}
+
+	event.TOTP, err = e.App.FindTOTPByRecord(event.Record)
+	if err != nil || !event.TOTP.Verified() {
+		return e.BadRequestError("Invalid TOTP code.", errors.New("TOTP is not enabled for the auth record"))
+	}
+
+	// mark the code as used to prevent reuse
+	event.UsedRecoveryCode, err = e.App.ConsumeTOTPCode(event.TOTP, form.Code, core.TOTPEncryptionKey(e.App, collection), true)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidTOTPCode) {
+			return e.BadRequestError("Invalid TOTP code.", nil)
+		}
+		return firstApiError(err, e.InternalServerError("Failed to update the TOTP state.", err))
+	}
+	// ---
+
+	return e.App.OnRecordAuthWithTOTPRequest().Trigger(event, func(e *core.RecordAuthWithTOTPRequestEvent) error {
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodTOTP, nil)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type authWithTOTPForm struct {
+	MFAId string `form:"mfaId" json:"mfaId"`
+	Code  string `form:"code" json:"code"`
+}
+
+func (form *authWithTOTPForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.MFAId, validation.Required, validation.Length(1, 255)),
+		validation.Field(&form.Code, validation.Required, validation.Length(1, 100)),
+	)
+}
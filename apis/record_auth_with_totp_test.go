@@ -0,0 +1,381 @@
+package apis_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const testTOTPSecret = "JBSWY3DPEHPK3PXP"
+
+// stubTOTPEnrollment enables TOTP and MFA for the users collection and
+// creates a TOTP enrollment and an MFA session for the test@example.com user.
+func stubTOTPEnrollment(t testing.TB, app *tests.TestApp, verified bool) {
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user.Collection().TOTP.Enabled = true
+	user.Collection().MFA.Enabled = true
+	if err = app.Save(user.Collection()); err != nil {
+		t.Fatal(err)
+	}
+
+	mfa := core.NewMFA(app)
+	mfa.Id = strings.Repeat("m", 15)
+	mfa.SetCollectionRef(user.Collection().Id)
+	mfa.SetRecordRef(user.Id)
+	mfa.SetMethod(core.MFAMethodPassword)
+	if err = app.Save(mfa); err != nil {
+		t.Fatal(err)
+	}
+
+	totp := core.NewTOTP(app)
+	totp.SetCollectionRef(user.Collection().Id)
+	totp.SetRecordRef(user.Id)
+	totp.SetVerified(verified)
+	if err = totp.SetSecret(testTOTPSecret, core.TOTPEncryptionKey(app, user.Collection())); err != nil {
+		t.Fatal(err)
+	}
+	totp.Set("recoveryCodes", []string{security.SHA256("aaaaa-11111"), security.SHA256("bbbbb-22222")})
+	if err = app.Save(totp); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordAuthWithTOTP(t *testing.T) {
+	t.Parallel()
+
+	// (the code remains valid for the test duration because of the allowed clock skew)
+	code, err := security.TOTPCode(testTOTPSecret, security.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	successEvents := map[string]int{
+		"*":                           0,
+		"OnRecordAuthWithTOTPRequest": 1,
+		"OnRecordAuthRequest":         1,
+		"OnRecordEnrich":              1,
+		// ---
+		// (note: the totp code is marked as used with a direct db update)
+		"OnModelValidate":           1,
+		"OnModelCreate":             1, // authOrigin
+		"OnModelCreateExecute":      1,
+		"OnModelAfterCreateSuccess": 1,
+		"OnModelDelete":             1, // mfa delete
+		"OnModelDeleteExecute":      1,
+		"OnModelAfterDeleteSuccess": 1,
+		// ---
+		"OnRecordValidate":           1,
+		"OnRecordCreate":             1,
+		"OnRecordCreateExecute":      1,
+		"OnRecordAfterCreateSuccess": 1,
+		"OnRecordDelete":             1,
+		"OnRecordDeleteExecute":      1,
+		"OnRecordAfterDeleteSuccess": 1,
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/auth-with-totp",
+			Body:            strings.NewReader(`{"mfaId":"test","code":"123456"}`),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "auth collection with disabled totp",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-totp",
+			Body:            strings.NewReader(`{"mfaId":"test","code":"123456"}`),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(``),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, true)
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"data":{`,
+				`"mfaId":{"code":"validation_required"`,
+				`"code":{"code":"validation_required"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "missing mfa",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"missing","code":"` + code + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, true)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "unverified totp enrollment",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"` + strings.Repeat("m", 15) + `","code":"` + code + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, false)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"` + strings.Repeat("m", 15) + `","code":"invalid"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, true)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid totp code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"` + strings.Repeat("m", 15) + `","code":"` + code + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, true)
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			NotExpectedContent: []string{
+				`"mfaId"`,
+				`"tokenKey"`,
+				`"password"`,
+			},
+			ExpectedEvents: successEvents,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				totp, err := app.FindTOTPByRecord(user)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if totp.LastStep() == 0 {
+					t.Fatal("Expected the TOTP last step to be persisted")
+				}
+
+				if _, err := app.FindMFAById(strings.Repeat("m", 15)); err == nil {
+					t.Fatal("Expected the MFA session to be deleted")
+				}
+			},
+		},
+		{
+			Name:   "valid recovery code",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"` + strings.Repeat("m", 15) + `","code":"bbbbb-22222"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, true)
+				app.OnRecordAuthWithTOTPRequest().BindFunc(func(e *core.RecordAuthWithTOTPRequestEvent) error {
+					if !e.UsedRecoveryCode {
+						t.Fatal("Expected UsedRecoveryCode to be true")
+					}
+					return e.Next()
+				})
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"email":"test@example.com"`,
+			},
+			ExpectedEvents: successEvents,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				totp, err := app.FindTOTPByRecord(user)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if total := totp.TotalRecoveryCodes(); total != 1 {
+					t.Fatalf("Expected 1 remaining recovery code, got %d", total)
+				}
+			},
+		},
+
+		{
+			Name:   "too many invalid codes for the same auth record from different IPs",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			Body:   strings.NewReader(`{"mfaId":"` + strings.Repeat("m", 15) + `","code":"` + code + `"}`),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				stubTOTPEnrollment(t, app, true)
+
+				mux, err := e.Router.BuildMux()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				for i := 0; i < 5; i++ {
+					rec := httptest.NewRecorder()
+					req := httptest.NewRequest(
+						http.MethodPost,
+						"/api/collections/users/auth-with-totp",
+						strings.NewReader(`{"mfaId":"`+strings.Repeat("m", 15)+`","code":"invalid"}`),
+					)
+					req.Header.Set("Content-Type", "application/json")
+					req.RemoteAddr = fmt.Sprintf("10.0.0.%d:1234", i+1)
+					mux.ServeHTTP(rec, req)
+
+					if rec.Code != http.StatusBadRequest {
+						t.Fatalf("[%d] Expected status 400, got %d", i, rec.Code)
+					}
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:authWithTOTP",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "users:auth"},
+					{MaxRequests: 0, Label: "users:authWithTOTP"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "RateLimit rule - *:auth",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-totp",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 0, Label: "*:auth"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+func TestRecordAuthWithTOTPConcurrentCode(t *testing.T) {
+	t.Parallel()
+
+	code, err := security.TOTPCode(testTOTPSecret, security.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []string{code, "aaaaa-11111"} {
+		t.Run(c, func(t *testing.T) {
+			app, err := tests.NewTestApp()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer app.Cleanup()
+
+			stubTOTPEnrollment(t, app, true)
+
+			pbRouter, err := apis.NewRouter(app)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			mux, err := pbRouter.BuildMux()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// (below the TOTP rate limit of 5 attempts per auth record)
+			const total = 5
+
+			var mu sync.Mutex
+			var succeeded int
+
+			var wg sync.WaitGroup
+			wg.Add(total)
+
+			for range total {
+				go func() {
+					defer wg.Done()
+
+					req := httptest.NewRequest(
+						http.MethodPost,
+						"/api/collections/users/auth-with-totp",
+						strings.NewReader(`{"mfaId":"`+strings.Repeat("m", 15)+`","code":"`+c+`"}`),
+					)
+					req.Header.Set("Content-Type", "application/json")
+
+					rec := httptest.NewRecorder()
+					mux.ServeHTTP(rec, req)
+
+					switch rec.Code {
+					case http.StatusOK:
+						mu.Lock()
+						succeeded++
+						mu.Unlock()
+					case http.StatusBadRequest:
+					default:
+						t.Errorf("Unexpected status %d: %s", rec.Code, rec.Body.String())
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if succeeded != 1 {
+				t.Fatalf("Expected exactly 1 successful auth, got %d", succeeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package apis
+
+import (
+	"errors"
+	"fmt"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+func recordAuthWithWebAuthn(e *core.RequestEvent) error {
+	collection, err := findAuthCollection(e)
+	if err != nil {
+		return err
+	}
+
+	if !collection.WebAuthn.Enabled {
+		return e.ForbiddenError("The collection is not configured to allow WebAuthn authentication.", nil)
+	}
+
+	form := &authWithWebAuthnForm{}
+	if err = e.BindBody(form); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while loading the submitted data.", err))
+	}
+	if err = form.validate(); err != nil {
+		return firstApiError(err, e.BadRequestError("An error occurred while validating the submitted data.", err))
+	}
+
+	e.Set(core.RequestEventKeyInfoContext, core.RequestInfoContextWebAuthn)
+
+	event := new(core.RecordAuthWithWebAuthnRequestEvent)
+	event.RequestEvent = e
+	event.Collection = collection
+
+	// extra validations
+	// ---
+	clientDataJSON, clientData, challenge, err := parseWebAuthnClientData(collection, form.Credential.Response.ClientDataJSON)
+	if err != nil {
+		return e.BadRequestError("Invalid or expired WebAuthn challenge.", err)
+	}
+
+	if challenge.RecordId != "" {
+		return e.BadRequestError("Invalid or expired WebAuthn challenge.", errors.New("registration challenges cannot be used for authentication"))
+	}
+
+	credentialId, err := form.Credential.credentialId()
+	if err != nil {
+		return e.BadRequestError("Invalid or unknown WebAuthn credential.", err)
+	}
+
+	event.Credential, err = e.App.FindWebAuthnCredentialByCredentialId(collection, credentialId)
+	if err != nil {
+		return e.BadRequestError("Invalid or unknown WebAuthn credential.", err)
+	}
+
+	rawAuthData, err := webauthn.DecodeBase64(form.Credential.Response.AuthenticatorData)
+	if err != nil {
+		return e.BadRequestError("Invalid WebAuthn authenticator data.", err)
+	}
+
+	signature, err := webauthn.DecodeBase64(form.Credential.Response.Signature)
+	if err != nil {
+		return e.BadRequestError("Invalid WebAuthn signature.", err)
+	}
+
+	authData, err := webauthn.VerifyAssertion(clientDataJSON, rawAuthData, signature, event.Credential.PublicKey(), webauthn.Expectations{
+		RPId:                    webauthnRPId(e.App, collection),
+		Challenge:               clientData.Challenge,
+		RequireUserVerification: collection.WebAuthn.RequireUserVerification,
+	})
+	if err != nil {
+		return e.BadRequestError("Failed to verify the WebAuthn assertion.", err)
+	}
+
+	if form.Credential.Response.UserHandle != "" {
+		userHandle, err := webauthn.DecodeBase64(form.Credential.Response.UserHandle)
+		if err != nil || string(userHandle) != event.Credential.RecordRef() {
+			return e.BadRequestError("Failed to verify the WebAuthn assertion.", errors.New("user handle mismatch"))
+		}
+	}
+
+	// authenticators that don't support counters always return 0
+	if (authData.SignCount != 0 || event.Credential.SignCount() != 0) && authData.SignCount <= event.Credential.SignCount() {
+		return e.BadRequestError("Failed to verify the WebAuthn assertion.", errors.New("invalid signature counter - the authenticator may have been cloned"))
+	}
+
+	if !useWebAuthnChallenge(e.App, challenge) {
+		return e.BadRequestError("Invalid or expired WebAuthn challenge.", errors.New("the challenge was already used"))
+	}
+
+	event.Record, err = e.App.FindRecordById(collection, event.Credential.RecordRef())
+	if err != nil {
+		return e.BadRequestError("Invalid or unknown WebAuthn credential.", fmt.Errorf("missing auth record: %w", err))
+	}
+
+	event.Credential.SetSignCount(authData.SignCount)
+	if err = e.App.Save(event.Credential); err != nil {
+		return firstApiError(err, e.InternalServerError("Failed to update the WebAuthn credential.", err))
+	}
+	// ---
+
+	return e.App.OnRecordAuthWithWebAuthnRequest().Trigger(event, func(e *core.RecordAuthWithWebAuthnRequestEvent) error {
+		return RecordAuthResponse(e.RequestEvent, e.Record, core.MFAMethodWebAuthn, nil)
+	})
+}
+
+// -------------------------------------------------------------------
+
+type authWithWebAuthnForm struct {
+	Credential webauthnCredentialForm `form:"credential" json:"credential"`
+}
+
+func (form *authWithWebAuthnForm) validate() error {
+	return validation.ValidateStruct(form,
+		validation.Field(&form.Credential, validation.By(func(_ any) error {
+			c := &form.Credential
+			return validation.ValidateStruct(c,
+				validation.Field(&c.Id, validation.When(c.RawId == "", validation.Required)),
+				validation.Field(&c.Type, validation.Required, validation.In("public-key")),
+				validation.Field(&c.Response, validation.By(func(_ any) error {
+					r := &c.Response
+					return validation.ValidateStruct(r,
+						validation.Field(&r.ClientDataJSON, validation.Required),
+						validation.Field(&r.AuthenticatorData, validation.Required),
+						validation.Field(&r.Signature, validation.Required),
+					)
+				})),
+			)
+		})),
+	)
+}
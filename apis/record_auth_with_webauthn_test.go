@@ -0,0 +1,358 @@
+package apis_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+func TestRecordAuthWithWebAuthn(t *testing.T) {
+	t.Parallel()
+
+	key := newTestWebAuthnKey(t)
+	otherKey := newTestWebAuthnKey(t)
+
+	credentialId := []byte("test_credential")
+
+	authChallenge := newTestWebAuthnChallenge(t, "")
+	registrationChallenge := newTestWebAuthnChallenge(t, "4q1xlclmfloku33")
+
+	assertionBody := func(challenge string, signCount uint32, signKey any, userHandle string, extra string) string {
+		clientData := testWebAuthnClientData(webauthn.TypeGet, challenge, "http://localhost:8090")
+		authData := testWebAuthnAuthData("localhost", webauthn.FlagUserPresent, signCount)
+
+		var signature []byte
+		switch k := signKey.(type) {
+		case []byte:
+			signature = k
+		default:
+			signature = testWebAuthnSign(t, key, authData, clientData)
+			if signKey != nil {
+				signature = testWebAuthnSign(t, otherKey, authData, clientData)
+			}
+		}
+
+		return `{
+			` + extra + `
+			"credential":{
+				"id":"` + webauthn.EncodeBase64(credentialId) + `",
+				"rawId":"` + webauthn.EncodeBase64(credentialId) + `",
+				"type":"public-key",
+				"response":{
+					"clientDataJSON":"` + clientData + `",
+					"authenticatorData":"` + webauthn.EncodeBase64(authData) + `",
+					"signature":"` + webauthn.EncodeBase64(signature) + `",
+					"userHandle":"` + userHandle + `"
+				}
+			}
+		}`
+	}
+
+	successEvents := map[string]int{
+		"*":                               0,
+		"OnRecordAuthWithWebAuthnRequest": 1,
+		"OnRecordAuthRequest":             1,
+		"OnRecordEnrich":                  1,
+		// ---
+		"OnModelValidate":           2,
+		"OnModelCreate":             1, // authOrigin
+		"OnModelCreateExecute":      1,
+		"OnModelAfterCreateSuccess": 1,
+		"OnModelUpdate":             1, // credential signCount update
+		"OnModelUpdateExecute":      1,
+		"OnModelAfterUpdateSuccess": 1,
+		// ---
+		"OnRecordValidate":           2,
+		"OnRecordCreate":             1,
+		"OnRecordCreateExecute":      1,
+		"OnRecordAfterCreateSuccess": 1,
+		"OnRecordUpdate":             1,
+		"OnRecordUpdateExecute":      1,
+		"OnRecordAfterUpdateSuccess": 1,
+	}
+
+	scenarios := []tests.ApiScenario{
+		{
+			Name:            "not an auth collection",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/demo1/auth-with-webauthn",
+			Body:            strings.NewReader(assertionBody(authChallenge, 1, nil, "", "")),
+			ExpectedStatus:  404,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:            "disabled webauthn",
+			Method:          http.MethodPost,
+			URL:             "/api/collections/users/auth-with-webauthn",
+			Body:            strings.NewReader(assertionBody(authChallenge, 1, nil, "", "")),
+			ExpectedStatus:  403,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "empty body",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users")
+			},
+			ExpectedStatus: 400,
+			ExpectedContent: []string{
+				`"credential":{`,
+				`"id":{"code":"validation_required"`,
+				`"type":{"code":"validation_required"`,
+				`"clientDataJSON":{"code":"validation_required"`,
+				`"authenticatorData":{"code":"validation_required"`,
+				`"signature":{"code":"validation_required"`,
+			},
+			ExpectedEvents: map[string]int{"*": 0},
+		},
+		{
+			Name:   "registration challenge",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(registrationChallenge, 1, nil, "", "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users")
+				stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "unknown credential",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 1, nil, "", "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users")
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "invalid signature",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 1, otherKey, "", "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users")
+				stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "user handle mismatch",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 1, nil, webauthn.EncodeBase64([]byte("oap640cot4yru2s")), "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users")
+				stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "sign count not increased",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 5, nil, "", "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				enableWebAuthn(t, app, "users")
+				credential := stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+				credential.SetSignCount(5)
+				if err := app.Save(credential); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "valid assertion",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 6, nil, webauthn.EncodeBase64([]byte("4q1xlclmfloku33")), "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				collection := enableWebAuthn(t, app, "users")
+				collection.MFA.Enabled = false
+				if err := app.Save(collection); err != nil {
+					t.Fatal(err)
+				}
+
+				credential := stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+				credential.SetSignCount(5)
+				if err := app.Save(credential); err != nil {
+					t.Fatal(err)
+				}
+
+				app.OnRecordAuthRequest().BindFunc(func(e *core.RecordAuthRequestEvent) error {
+					info, err := e.RequestInfo()
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if info.Context != core.RequestInfoContextWebAuthn {
+						t.Fatalf("Expected request context %q, got %q", core.RequestInfoContextWebAuthn, info.Context)
+					}
+
+					return e.Next()
+				})
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"record":{`,
+				`"email":"test@example.com"`,
+			},
+			NotExpectedContent: []string{
+				`"meta":`,
+				`"tokenKey"`,
+				`"password"`,
+			},
+			ExpectedEvents: successEvents,
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				credential, err := app.FindWebAuthnCredentialByCredentialId("users", webauthn.EncodeBase64(credentialId))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if credential.SignCount() != 6 {
+					t.Fatalf("Expected the sign count to be updated to 6, got %d", credential.SignCount())
+				}
+			},
+		},
+		{
+			Name:   "valid assertion with MFA (first factor)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 0, nil, "", "")),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				collection := enableWebAuthn(t, app, "users")
+				collection.MFA.Enabled = true
+				if err := app.Save(collection); err != nil {
+					t.Fatal(err)
+				}
+
+				stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+			},
+			ExpectedStatus: 401,
+			ExpectedContent: []string{
+				`"mfaId":"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                               0,
+				"OnRecordAuthWithWebAuthnRequest": 1,
+				"OnRecordAuthRequest":             1,
+				// ---
+				"OnModelValidate":           2,
+				"OnModelCreate":             1, // mfa
+				"OnModelCreateExecute":      1,
+				"OnModelAfterCreateSuccess": 1,
+				"OnModelUpdate":             1, // credential
+				"OnModelUpdateExecute":      1,
+				"OnModelAfterUpdateSuccess": 1,
+				// ---
+				"OnRecordValidate":           2,
+				"OnRecordCreate":             1,
+				"OnRecordCreateExecute":      1,
+				"OnRecordAfterCreateSuccess": 1,
+				"OnRecordUpdate":             1,
+				"OnRecordUpdateExecute":      1,
+				"OnRecordAfterUpdateSuccess": 1,
+			},
+		},
+		{
+			Name:   "valid assertion with MFA (second factor)",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			Body:   strings.NewReader(assertionBody(authChallenge, 0, nil, "", `"mfaId":"`+strings.Repeat("m", 15)+`",`)),
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				collection := enableWebAuthn(t, app, "users")
+				collection.MFA.Enabled = true
+				if err := app.Save(collection); err != nil {
+					t.Fatal(err)
+				}
+
+				user := stubTestWebAuthnCredential(t, app, key, "test@example.com", credentialId)
+
+				mfa := core.NewMFA(app)
+				mfa.Id = strings.Repeat("m", 15)
+				mfa.SetCollectionRef(collection.Id)
+				mfa.SetRecordRef(user.RecordRef())
+				mfa.SetMethod(core.MFAMethodPassword)
+				if err := app.Save(mfa); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"token":"`,
+				`"email":"test@example.com"`,
+			},
+			ExpectedEvents: func() map[string]int {
+				events := make(map[string]int, len(successEvents))
+				for k, v := range successEvents {
+					events[k] = v
+				}
+				events["OnModelDelete"] = 1 // mfa delete
+				events["OnModelDeleteExecute"] = 1
+				events["OnModelAfterDeleteSuccess"] = 1
+				events["OnRecordDelete"] = 1
+				events["OnRecordDeleteExecute"] = 1
+				events["OnRecordAfterDeleteSuccess"] = 1
+				return events
+			}(),
+		},
+
+		// rate limit checks
+		// -----------------------------------------------------------
+		{
+			Name:   "RateLimit rule - users:authWithWebAuthn",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 100, Label: "users:auth"},
+					{MaxRequests: 0, Label: "users:authWithWebAuthn"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "RateLimit rule - *:auth",
+			Method: http.MethodPost,
+			URL:    "/api/collections/users/auth-with-webauthn",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				app.Settings().RateLimits.Enabled = true
+				app.Settings().RateLimits.Rules = []core.RateLimitRule{
+					{MaxRequests: 100, Label: "abc"},
+					{MaxRequests: 0, Label: "*:auth"},
+				}
+			},
+			ExpectedStatus:  429,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
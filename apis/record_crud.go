@@ -27,6 +27,7 @@ import (
 func bindRecordCrudApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
 	subGroup := rg.Group("/collections/{collection}/records").Unbind(DefaultRateLimitMiddlewareId)
 	subGroup.GET("", recordsList)
+	subGroup.GET("/aggregate", recordsAggregate)
 	subGroup.GET("/{id}", recordView)
 	subGroup.POST("", recordCreate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
 	subGroup.PATCH("/{id}", recordUpdate(true, nil)).Bind(dynamicCollectionBodyLimit(""))
@@ -44,6 +45,11 @@ func recordsList(e *core.RequestEvent) error {
 		return err
 	}
 
+	err = checkAPIKeyScope(e, collection, core.APIKeyActionList)
+	if err != nil {
+		return err
+	}
+
 	requestInfo, err := e.RequestInfo()
 	if err != nil {
 		return firstApiError(err, e.BadRequestError("", err))
@@ -152,6 +158,11 @@ func recordView(e *core.RequestEvent) error {
 		return err
 	}
 
+	err = checkAPIKeyScope(e, collection, core.APIKeyActionView)
+	if err != nil {
+		return err
+	}
+
 	recordId := e.Request.PathValue("id")
 	if recordId == "" {
 		return e.NotFoundError("", nil)
@@ -216,6 +227,11 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return err
 		}
 
+		err = checkAPIKeyScope(e, collection, core.APIKeyActionCreate)
+		if err != nil {
+			return err
+		}
+
 		requestInfo, err := e.RequestInfo()
 		if err != nil {
 			return firstApiError(err, e.BadRequestError("", err))
@@ -233,9 +249,9 @@ func recordCreate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return firstApiError(err, e.BadRequestError("Failed to read the submitted data.", err))
 		}
 
-		// set a random password for the OAuth2 ignoring its plain password validators
+		// set a random password for the OAuth2 and SAML sign-ups ignoring its plain password validators
 		var skipPlainPasswordRecordValidators bool
-		if requestInfo.Context == core.RequestInfoContextOAuth2 {
+		if requestInfo.Context == core.RequestInfoContextOAuth2 || requestInfo.Context == core.RequestInfoContextSAML {
 			if _, ok := data[core.FieldNamePassword]; !ok {
 				data[core.FieldNamePassword] = security.RandomString(30)
 				data[core.FieldNamePassword+"Confirm"] = data[core.FieldNamePassword]
@@ -396,6 +412,11 @@ func recordUpdate(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return err
 		}
 
+		err = checkAPIKeyScope(e, collection, core.APIKeyActionUpdate)
+		if err != nil {
+			return err
+		}
+
 		recordId := e.Request.PathValue("id")
 		if recordId == "" {
 			return e.NotFoundError("", nil)
@@ -529,6 +550,11 @@ func recordDelete(responseWriteAfterTx bool, optFinalizer func(data any) error)
 			return err
 		}
 
+		err = checkAPIKeyScope(e, collection, core.APIKeyActionDelete)
+		if err != nil {
+			return err
+		}
+
 		recordId := e.Request.PathValue("id")
 		if recordId == "" {
 			return e.NotFoundError("", nil)
@@ -94,6 +94,99 @@ func TestRecordCrudList(t *testing.T) {
 				"OnRecordEnrich":       3,
 			},
 		},
+		{
+			Name:            "public collection with invalid cursor",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/demo2/records?cursor=invalid",
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:           "public collection with empty cursor (first cursor page)",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/demo2/records?cursor=&perPage=2&sort=title",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"perPage":2`,
+				`"totalPages":2`,
+				`"totalItems":3`,
+				`"items":[{`,
+				`"id":"llvuca81nly1qls"`,
+				`"id":"achvryl401bhse3"`,
+				`"nextCursor":"WyJ0ZXN0MiIsImFjaHZyeWw0MDFiaHNlMyJd"`,
+			},
+			NotExpectedContent: []string{
+				`"id":"0yxhwia2amd8gec"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       2,
+			},
+		},
+		{
+			Name:           "public collection with cursor (last cursor page)",
+			Method:         http.MethodGet,
+			URL:            "/api/collections/demo2/records?cursor=WyJ0ZXN0MiIsImFjaHZyeWw0MDFiaHNlMyJd&perPage=2&sort=title&skipTotal=1",
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"perPage":2`,
+				`"totalItems":-1`,
+				`"items":[{`,
+				`"id":"0yxhwia2amd8gec"`,
+			},
+			NotExpectedContent: []string{
+				`"id":"llvuca81nly1qls"`,
+				`"id":"achvryl401bhse3"`,
+				`"nextCursor"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       1,
+			},
+		},
+		{
+			Name:            "public collection with @rank sort but without match() filter",
+			Method:          http.MethodGet,
+			URL:             "/api/collections/demo2/records?sort=@rank",
+			ExpectedStatus:  400,
+			ExpectedContent: []string{`"data":{}`},
+			ExpectedEvents:  map[string]int{"*": 0},
+		},
+		{
+			Name:   "public collection with full-text match() filter and @rank sort",
+			Method: http.MethodGet,
+			URL:    "/api/collections/demo2/records?filter=match(title,'test2%20OR%20test3')=true&sort=@rank,title",
+			BeforeTestFunc: func(t testing.TB, app *tests.TestApp, e *core.ServeEvent) {
+				col, err := app.FindCollectionByNameOrId("demo2")
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				col.Fields.GetByName("title").(*core.TextField).FullText = true
+
+				if err = app.Save(col); err != nil {
+					t.Fatal(err)
+				}
+			},
+			ExpectedStatus: 200,
+			ExpectedContent: []string{
+				`"totalItems":2`,
+				`"items":[{`,
+				`"id":"achvryl401bhse3"`,
+				`"id":"0yxhwia2amd8gec"`,
+			},
+			NotExpectedContent: []string{
+				`"id":"llvuca81nly1qls"`,
+			},
+			ExpectedEvents: map[string]int{
+				"*":                    0,
+				"OnRecordsListRequest": 1,
+				"OnRecordEnrich":       2,
+			},
+		},
 		{
 			Name:           "public collection (using the collection id)",
 			Method:         http.MethodGet,
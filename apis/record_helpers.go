@@ -38,10 +38,15 @@ func RecordAuthResponse(e *core.RequestEvent, authRecord *core.Record, authMetho
 		return e.InternalServerError("Failed to create auth token.", tokenErr)
 	}
 
-	return recordAuthResponse(e, authRecord, token, authMethod, meta)
+	return recordAuthResponse(e, authRecord, token, "", authMethod, meta)
 }
 
-func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token string, authMethod string, meta any) error {
+// recordAuthResponse writes the auth record response.
+//
+// If refreshToken is not set and authMethod is not empty (aka. new sign-in),
+// a new refresh token auth session is created for the current device
+// (if enabled for the collection).
+func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token string, refreshToken string, authMethod string, meta any) error {
 	originalRequestInfo, err := e.RequestInfo()
 	if err != nil {
 		return err
@@ -57,6 +62,7 @@ func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token str
 	event.Collection = authRecord.Collection()
 	event.Record = authRecord
 	event.Token = token
+	event.RefreshToken = refreshToken
 	event.Meta = meta
 	event.AuthMethod = authMethod
 
@@ -83,6 +89,16 @@ func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token str
 		}
 		// ---
 
+		// refresh token auth session
+		// ---
+		if e.RefreshToken == "" && e.AuthMethod != "" && e.Record.Collection().RefreshToken.Enabled {
+			e.Token, e.RefreshToken, err = createAuthSession(e.RequestEvent, e.Record)
+			if err != nil {
+				return e.InternalServerError("Failed to create auth session.", err)
+			}
+		}
+		// ---
+
 		// create a shallow copy of the cached request data and adjust it to the current auth record
 		requestInfo := *originalRequestInfo
 		requestInfo.Auth = e.Record
@@ -117,12 +133,20 @@ func recordAuthResponse(e *core.RequestEvent, authRecord *core.Record, token str
 		}
 
 		result := struct {
-			Meta   any          `json:"meta,omitempty"`
-			Record *core.Record `json:"record"`
-			Token  string       `json:"token"`
+			Meta            any          `json:"meta,omitempty"`
+			Record          *core.Record `json:"record"`
+			Token           string       `json:"token"`
+			RefreshToken    string       `json:"refreshToken,omitempty"`
+			PasswordExpired bool         `json:"passwordExpired,omitempty"`
 		}{
-			Token:  e.Token,
-			Record: e.Record,
+			Token:        e.Token,
+			RefreshToken: e.RefreshToken,
+			Record:       e.Record,
+		}
+
+		// flag the response so that the client could force a password change
+		if field, ok := e.Record.Collection().Fields.GetByName(core.FieldNamePassword).(*core.PasswordField); ok {
+			result.PasswordExpired = field.HasExpired(e.App, e.Record)
 		}
 
 		if e.Meta != nil {
@@ -369,6 +393,10 @@ func expandFetch(app core.App, originalRequestInfo *core.RequestInfo) core.Expan
 
 	return func(relCollection *core.Collection, relIds []string) ([]*core.Record, error) {
 		records, findErr := app.FindRecordsByIds(relCollection.Id, relIds, func(q *dbx.SelectQuery) error {
+			if requestInfoPtr.APIKey != nil && !requestInfoPtr.APIKey.Allows(relCollection, core.APIKeyActionView) {
+				return fmt.Errorf("the API key is not allowed to view collection %q records", relCollection.Name)
+			}
+
 			if requestInfoPtr.Auth != nil && requestInfoPtr.Auth.IsSuperuser() {
 				return nil // superusers can access everything
 			}
@@ -562,15 +590,43 @@ func execAfterSuccessTx(checkTx bool, app core.App, fn func() error) error {
 
 const maxAuthOrigins = 5
 
-func authAlert(e *core.RequestEvent, authRecord *core.Record) error {
-	// generating fingerprint
-	// ---
+// authFingerprint generates the current request client device fingerprint.
+func authFingerprint(e *core.RequestEvent) string {
 	userAgent := e.Request.UserAgent()
 	if len(userAgent) > 300 {
 		userAgent = userAgent[:300]
 	}
-	fingerprint := security.MD5(e.RealIP() + userAgent)
-	// ---
+
+	return security.MD5(e.RealIP() + userAgent)
+}
+
+// createAuthSession creates a new auth session for the current sign-in
+// and returns the session bound auth token and its refresh token.
+//
+// Note that a new session is always created (even for the same client device)
+// because the device fingerprint is not unique (e.g. for clients behind NAT).
+func createAuthSession(e *core.RequestEvent, authRecord *core.Record) (string, string, error) {
+	session := core.NewAuthSession(e.App)
+	session.SetCollectionRef(authRecord.Collection().Id)
+	session.SetRecordRef(authRecord.Id)
+	session.SetFingerprint(authFingerprint(e))
+
+	refreshToken := session.RotateRefreshToken(authRecord.Collection().RefreshToken.DurationTime())
+
+	if err := e.App.Save(session); err != nil {
+		return "", "", err
+	}
+
+	token, err := authRecord.NewSessionAuthToken(session.Id)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+func authAlert(e *core.RequestEvent, authRecord *core.Record) error {
+	fingerprint := authFingerprint(e)
 
 	origins, err := e.App.FindAllAuthOriginsByRecord(authRecord)
 	if err != nil {
@@ -0,0 +1,47 @@
+package apis
+
+import (
+	"net/http"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// bindWebhooksApi registers the webhooks api endpoints.
+//
+// The webhooks and their deliveries are regular system collections and
+// are managed with the records api (the attempts are also logged with
+// "type=webhook" and are browsable with the logs api).
+func bindWebhooksApi(app core.App, rg *router.RouterGroup[*core.RequestEvent]) {
+	sub := rg.Group("/webhooks").Bind(RequireSuperuserAuth())
+	sub.POST("/deliveries/{id}/redeliver", webhookRedeliver)
+}
+
+// webhookRedeliver creates and immediately sends a new copy
+// of an existing webhook delivery (usually a failed one).
+func webhookRedeliver(e *core.RequestEvent) error {
+	original, err := e.App.FindWebhookDeliveryById(e.Request.PathValue("id"))
+	if err != nil {
+		return e.NotFoundError("", err)
+	}
+
+	delivery := core.NewWebhookDelivery(e.App)
+	delivery.SetWebhook(original.Webhook())
+	delivery.SetEvent(original.Event())
+	delivery.SetCollectionRef(original.CollectionRef())
+	delivery.SetRecordRef(original.RecordRef())
+	delivery.SetPayload(original.Payload())
+	delivery.SetStatus(core.WebhookDeliveryStatusPending)
+	delivery.SetNextAttemptAt(types.NowDateTime())
+
+	if err = e.App.Save(delivery); err != nil {
+		return firstApiError(err, e.BadRequestError("Failed to create the webhook delivery.", err))
+	}
+
+	if err = e.App.SendWebhookDelivery(delivery); err != nil {
+		return e.InternalServerError("Failed to send the webhook delivery.", err)
+	}
+
+	return e.JSON(http.StatusOK, delivery)
+}
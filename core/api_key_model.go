@@ -0,0 +1,233 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAPIKeys = "_apiKeys"
+
+// APIKeyPrefix is the common prefix of all generated plain API keys
+// (it is used to distinguish them from the regular auth tokens).
+const APIKeyPrefix = "pbk_"
+
+// Supported API key scope actions.
+const (
+	APIKeyActionList   = "list"
+	APIKeyActionView   = "view"
+	APIKeyActionCreate = "create"
+	APIKeyActionUpdate = "update"
+	APIKeyActionDelete = "delete"
+)
+
+// APIKeyActions is the list of all supported API key scope actions.
+var APIKeyActions = []string{
+	APIKeyActionList,
+	APIKeyActionView,
+	APIKeyActionCreate,
+	APIKeyActionUpdate,
+	APIKeyActionDelete,
+}
+
+var (
+	_ Model        = (*APIKey)(nil)
+	_ PreValidator = (*APIKey)(nil)
+	_ RecordProxy  = (*APIKey)(nil)
+)
+
+// APIKeyScope defines a single API key collection access scope.
+type APIKeyScope struct {
+	// Collection is the name or id of the allowed collection ("*" for any).
+	Collection string `json:"collection"`
+
+	// Actions is the list of the allowed collection records actions
+	// (list, view, create, update, delete).
+	Actions []string `json:"actions"`
+}
+
+// APIKey defines a Record proxy for working with the apiKeys collection.
+type APIKey struct {
+	*Record
+}
+
+// NewAPIKey instantiates and returns a new blank *APIKey model.
+//
+// Example usage:
+//
+//	apiKey := core.NewAPIKey(app)
+//	apiKey.SetRecordRef(user.Id)
+//	apiKey.SetCollectionRef(user.Collection().Id)
+//	apiKey.SetName("my integration")
+//	plainKey := apiKey.GenerateKey()
+//	app.Save(apiKey)
+func NewAPIKey(app App) *APIKey {
+	m := &APIKey{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAPIKeys)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on APIKey.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *APIKey) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAPIKeys {
+		return errors.New("missing or invalid APIKey ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *APIKey) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *APIKey) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *APIKey) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *APIKey) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *APIKey) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *APIKey) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Name returns the "name" record field value (aka. the API key label).
+func (m *APIKey) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *APIKey) SetName(name string) {
+	m.Set("name", name)
+}
+
+// KeyHint returns the "keyHint" record field value
+// (aka. the last few characters of the plain API key).
+func (m *APIKey) KeyHint() string {
+	return m.GetString("keyHint")
+}
+
+// GenerateKey generates a new random plain API key, stores its hash
+// in the "keyHash" record field and returns the plain key.
+//
+// Note that only the key hash is persisted, so the returned plain key
+// must be shown to the user right away as it cannot be recovered later.
+func (m *APIKey) GenerateKey() string {
+	key := APIKeyPrefix + security.RandomString(40)
+
+	m.Set("keyHash", hashAPIKey(key))
+	m.Set("keyHint", key[len(key)-4:])
+
+	return key
+}
+
+// ValidateKey reports whether the provided plain API key matches with the stored hash.
+func (m *APIKey) ValidateKey(key string) bool {
+	hash := m.GetString("keyHash")
+
+	return hash != "" && security.Equal(hash, hashAPIKey(key))
+}
+
+// Scopes returns the "scopes" record field value.
+//
+// An API key without scopes is not restricted to specific collections and actions.
+func (m *APIKey) Scopes() []APIKeyScope {
+	scopes := []APIKeyScope{}
+	_ = m.UnmarshalJSONField("scopes", &scopes)
+	return scopes
+}
+
+// SetScopes updates the "scopes" record field value.
+func (m *APIKey) SetScopes(scopes []APIKeyScope) {
+	m.Set("scopes", scopes)
+}
+
+// Allows reports whether the API key scopes allow the specified action
+// for the records of the provided collection.
+func (m *APIKey) Allows(collection *Collection, action string) bool {
+	scopes := m.Scopes()
+	if len(scopes) == 0 {
+		return true // unrestricted
+	}
+
+	for _, scope := range scopes {
+		if scope.Collection != "*" &&
+			scope.Collection != collection.Id &&
+			!strings.EqualFold(scope.Collection, collection.Name) {
+			continue
+		}
+
+		if slices.Contains(scope.Actions, action) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Expires returns the "expires" record field value.
+//
+// A zero value means that the API key doesn't expire.
+func (m *APIKey) Expires() types.DateTime {
+	return m.GetDateTime("expires")
+}
+
+// SetExpires updates the "expires" record field value.
+func (m *APIKey) SetExpires(date types.DateTime) {
+	m.Set("expires", date)
+}
+
+// HasExpired checks whether the API key has an expiration date that has already passed.
+func (m *APIKey) HasExpired() bool {
+	expires := m.Expires()
+
+	return !expires.IsZero() && !expires.Time().After(time.Now())
+}
+
+// Created returns the "created" record field value.
+func (m *APIKey) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *APIKey) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func hashAPIKey(key string) string {
+	return security.SHA256(key)
+}
+
+func (app *BaseApp) registerAPIKeyHooks() {
+	recordRefHooks[*APIKey](app, CollectionNameAPIKeys, CollectionTypeAuth)
+}
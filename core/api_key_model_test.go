@@ -0,0 +1,356 @@
+package core_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAPIKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	if apiKey.Collection().Name != core.CollectionNameAPIKeys {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAPIKeys, apiKey.Collection().Name)
+	}
+}
+
+func TestAPIKeyProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	apiKey := core.APIKey{}
+	apiKey.SetProxyRecord(record)
+
+	if apiKey.ProxyRecord() == nil || apiKey.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, apiKey.ProxyRecord())
+	}
+}
+
+func TestAPIKeyStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", apiKey.SetCollectionRef, apiKey.CollectionRef},
+		{"recordRef", apiKey.SetRecordRef, apiKey.RecordRef},
+		{"name", apiKey.SetName, apiKey.Name},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := apiKey.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAPIKeyGenerateAndValidateKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKey := core.NewAPIKey(app)
+
+	if apiKey.ValidateKey("") {
+		t.Fatal("Expected empty key to be invalid for a blank API key model")
+	}
+
+	key := apiKey.GenerateKey()
+
+	if !strings.HasPrefix(key, core.APIKeyPrefix) {
+		t.Fatalf("Expected key with %q prefix, got %q", core.APIKeyPrefix, key)
+	}
+
+	if hash := apiKey.GetString("keyHash"); hash == "" || strings.Contains(hash, key) {
+		t.Fatalf("Expected the key hash to be stored, got %q", hash)
+	}
+
+	if hint := apiKey.KeyHint(); hint != key[len(key)-4:] {
+		t.Fatalf("Expected key hint %q, got %q", key[len(key)-4:], hint)
+	}
+
+	if !apiKey.ValidateKey(key) {
+		t.Fatal("Expected the generated key to be valid")
+	}
+
+	if apiKey.ValidateKey(key + "a") {
+		t.Fatal("Expected a modified key to be invalid")
+	}
+
+	if key2 := apiKey.GenerateKey(); key2 == key || apiKey.ValidateKey(key) {
+		t.Fatal("Expected the old key to be invalidated after regeneration")
+	}
+}
+
+func TestAPIKeyScopes(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name       string
+		scopes     []core.APIKeyScope
+		collection *core.Collection
+		action     string
+		expected   bool
+	}{
+		{"no scopes", nil, demo1, core.APIKeyActionDelete, true},
+		{
+			"matching collection name and action",
+			[]core.APIKeyScope{{Collection: "demo1", Actions: []string{core.APIKeyActionList, core.APIKeyActionView}}},
+			demo1,
+			core.APIKeyActionView,
+			true,
+		},
+		{
+			"matching collection id",
+			[]core.APIKeyScope{{Collection: demo1.Id, Actions: []string{core.APIKeyActionList}}},
+			demo1,
+			core.APIKeyActionList,
+			true,
+		},
+		{
+			"matching collection but not action",
+			[]core.APIKeyScope{{Collection: "demo1", Actions: []string{core.APIKeyActionList}}},
+			demo1,
+			core.APIKeyActionCreate,
+			false,
+		},
+		{
+			"different collection",
+			[]core.APIKeyScope{{Collection: "demo1", Actions: []string{core.APIKeyActionList}}},
+			demo2,
+			core.APIKeyActionList,
+			false,
+		},
+		{
+			"wildcard collection",
+			[]core.APIKeyScope{{Collection: "*", Actions: []string{core.APIKeyActionList}}},
+			demo2,
+			core.APIKeyActionList,
+			true,
+		},
+		{
+			"multiple scopes",
+			[]core.APIKeyScope{
+				{Collection: "demo1", Actions: []string{core.APIKeyActionList}},
+				{Collection: "demo2", Actions: []string{core.APIKeyActionUpdate}},
+			},
+			demo2,
+			core.APIKeyActionUpdate,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			apiKey := core.NewAPIKey(app)
+			apiKey.SetScopes(s.scopes)
+
+			if total := len(apiKey.Scopes()); total != len(s.scopes) {
+				t.Fatalf("Expected %d scopes, got %d", len(s.scopes), total)
+			}
+
+			if v := apiKey.Allows(s.collection, s.action); v != s.expected {
+				t.Fatalf("Expected Allows %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyHasExpired(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		expires  types.DateTime
+		expected bool
+	}{
+		{types.DateTime{}, false},
+		{types.NowDateTime().Add(1 * time.Hour), false},
+		{types.NowDateTime().Add(-1 * time.Minute), true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.expires.String()), func(t *testing.T) {
+			apiKey := core.NewAPIKey(app)
+			apiKey.SetExpires(s.expires)
+
+			if !apiKey.Expires().Time().Equal(s.expires.Time()) {
+				t.Fatalf("Expected expires %v, got %v", s.expires, apiKey.Expires())
+			}
+
+			if v := apiKey.HasExpired(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAPIKeyPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	apiKeysCol, err := app.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+
+		if err := app.Validate(apiKey); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-APIKey collection", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+		apiKey.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		apiKey.SetRecordRef(user.Id)
+		apiKey.SetCollectionRef(user.Collection().Id)
+		apiKey.SetName("test")
+		apiKey.GenerateKey()
+
+		if err := app.Validate(apiKey); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("APIKey collection", func(t *testing.T) {
+		apiKey := &core.APIKey{}
+		apiKey.SetProxyRecord(core.NewRecord(apiKeysCol))
+		apiKey.SetRecordRef(user.Id)
+		apiKey.SetCollectionRef(user.Collection().Id)
+		apiKey.SetName("test")
+		apiKey.GenerateKey()
+
+		if err := app.Validate(apiKey); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAPIKeyValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		apiKey       func() *core.APIKey
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.APIKey {
+				return core.NewAPIKey(app)
+			},
+			[]string{"collectionRef", "recordRef", "name", "keyHash"},
+		},
+		{
+			"non-auth collection",
+			func() *core.APIKey {
+				apiKey := core.NewAPIKey(app)
+				apiKey.SetCollectionRef(demo1.Collection().Id)
+				apiKey.SetRecordRef(demo1.Id)
+				apiKey.SetName("test")
+				apiKey.GenerateKey()
+				return apiKey
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.APIKey {
+				apiKey := core.NewAPIKey(app)
+				apiKey.SetCollectionRef(user.Collection().Id)
+				apiKey.SetRecordRef("missing")
+				apiKey.SetName("test")
+				apiKey.GenerateKey()
+				return apiKey
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.APIKey {
+				apiKey := core.NewAPIKey(app)
+				apiKey.SetCollectionRef(user.Collection().Id)
+				apiKey.SetRecordRef(user.Id)
+				apiKey.SetName("test")
+				apiKey.GenerateKey()
+				return apiKey
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.apiKey())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+)
+
+// FindAllAPIKeysByRecord returns all APIKey models linked to the provided auth record (in DESC order).
+func (app *BaseApp) FindAllAPIKeysByRecord(authRecord *Record) ([]*APIKey, error) {
+	result := []*APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAPIKeyByKey returns a single APIKey model by its plain key value.
+func (app *BaseApp) FindAPIKeyByKey(key string) (*APIKey, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, errors.New("invalid API key format")
+	}
+
+	result := &APIKey{}
+
+	err := app.RecordQuery(CollectionNameAPIKeys).
+		AndWhere(dbx.HashExp{"keyHash": hashAPIKey(key)}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthRecordByAPIKey finds the auth record associated with the provided plain API key.
+//
+// Returns an error if the API key is invalid, expired, the API keys of the
+// record collection are disabled or the key is not associated to an auth collection record.
+func (app *BaseApp) FindAuthRecordByAPIKey(key string) (*Record, *APIKey, error) {
+	if key == "" {
+		return nil, nil, errors.New("missing API key")
+	}
+
+	apiKey, err := app.FindAPIKeyByKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if apiKey.HasExpired() {
+		return nil, nil, errors.New("the API key has expired")
+	}
+
+	record, err := app.FindRecordById(apiKey.CollectionRef(), apiKey.RecordRef())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	collection := record.Collection()
+	if !collection.IsAuth() {
+		return nil, nil, errors.New("the API key is not associated to an auth collection record")
+	}
+
+	if !collection.APIKeys.Enabled {
+		return nil, nil, errors.New("the API keys of the auth collection are disabled")
+	}
+
+	return record, apiKey, nil
+}
@@ -0,0 +1,199 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// stubAPIKeys creates 2 API keys for test@example.com (the second one expired)
+// and 1 for test2@example.com and returns their plain keys.
+func stubAPIKeys(t *testing.T, app core.App) []string {
+	users := []string{"test@example.com", "test@example.com", "test2@example.com"}
+
+	keys := make([]string, len(users))
+
+	for i, email := range users {
+		user, err := app.FindAuthRecordByEmail("users", email)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		apiKey := core.NewAPIKey(app)
+		apiKey.SetCollectionRef(user.Collection().Id)
+		apiKey.SetRecordRef(user.Id)
+		apiKey.SetName("key" + string(rune('0'+i)))
+		if i == 1 {
+			apiKey.SetExpires(types.NowDateTime().Add(-1 * time.Hour))
+		}
+		keys[i] = apiKey.GenerateKey()
+		if err := app.Save(apiKey); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return keys
+}
+
+func TestFindAllAPIKeysByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAPIKeys(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user3, err := app.FindAuthRecordByEmail("users", "test3@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected []string
+	}{
+		{user1, []string{"key0", "key1"}},
+		{user2, []string{"key2"}},
+		{user3, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Email(), func(t *testing.T) {
+			result, err := app.FindAllAPIKeysByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected %d API keys, got %d", len(s.expected), len(result))
+			}
+
+			for _, name := range s.expected {
+				var exists bool
+				for _, apiKey := range result {
+					if apiKey.Name() == name {
+						exists = true
+						break
+					}
+				}
+				if !exists {
+					t.Fatalf("Missing API key %q", name)
+				}
+			}
+		})
+	}
+}
+
+func TestFindAPIKeyByKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	keys := stubAPIKeys(t, app)
+
+	scenarios := []struct {
+		name     string
+		key      string
+		expected string
+	}{
+		{"empty", "", ""},
+		{"missing prefix", keys[0][len(core.APIKeyPrefix):], ""},
+		{"nonexisting", core.APIKeyPrefix + "missing", ""},
+		{"existing", keys[0], "key0"},
+		{"existing (expired)", keys[1], "key1"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := app.FindAPIKeyByKey(s.key)
+
+			hasErr := err != nil
+			expectErr := s.expected == ""
+			if hasErr != expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", expectErr, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Name() != s.expected {
+				t.Fatalf("Expected API key %q, got %q", s.expected, result.Name())
+			}
+		})
+	}
+}
+
+func TestFindAuthRecordByAPIKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	keys := stubAPIKeys(t, app)
+
+	t.Run("disabled collection API keys", func(t *testing.T) {
+		if _, _, err := app.FindAuthRecordByAPIKey(keys[0]); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users.APIKeys.Enabled = true
+	if err := app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name          string
+		key           string
+		expectedEmail string
+	}{
+		{"empty", "", ""},
+		{"invalid", core.APIKeyPrefix + "invalid", ""},
+		{"expired", keys[1], ""},
+		{"valid (user1)", keys[0], "test@example.com"},
+		{"valid (user2)", keys[2], "test2@example.com"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			record, apiKey, err := app.FindAuthRecordByAPIKey(s.key)
+
+			hasErr := err != nil
+			expectErr := s.expectedEmail == ""
+			if hasErr != expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", expectErr, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if record.Email() != s.expectedEmail {
+				t.Fatalf("Expected record %q, got %q", s.expectedEmail, record.Email())
+			}
+
+			if apiKey == nil || apiKey.RecordRef() != record.Id {
+				t.Fatalf("Expected API key for record %q, got %v", record.Id, apiKey)
+			}
+		})
+	}
+}
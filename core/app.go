@@ -85,6 +85,9 @@ type App interface {
 	// SubscriptionsBroker returns the app realtime subscriptions broker instance.
 	SubscriptionsBroker() *subscriptions.Broker
 
+	// Jobs returns the app persistent background jobs queue.
+	Jobs() *Jobs
+
 	// NewMailClient creates and returns a new SMTP or Sendmail client
 	// based on the current app settings.
 	NewMailClient() mailer.Mailer
@@ -371,6 +374,36 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// JobQuery returns a new Job select query.
+	JobQuery() *dbx.SelectQuery
+
+	// FindJobById finds a single Job entry by its id.
+	FindJobById(id string) (*Job, error)
+
+	// DeleteOldJobs deletes all finished (completed, failed or canceled)
+	// jobs that were last updated before updatedBefore.
+	DeleteOldJobs(updatedBefore time.Time) error
+
+	// ---------------------------------------------------------------
+
+	// CronRunQuery returns a new CronRun select query.
+	CronRunQuery() *dbx.SelectQuery
+
+	// FindCronRunById finds a single CronRun entry by its id.
+	FindCronRunById(id string) (*CronRun, error)
+
+	// FindLastCronRun finds the most recently started persisted run of the specified cron job.
+	FindLastCronRun(jobId string) (*CronRun, error)
+
+	// DeleteOldCronRuns deletes all cron job runs that are created before createdBefore.
+	DeleteOldCronRuns(createdBefore time.Time) error
+
+	// DeleteExcessCronRuns deletes the oldest runs of each cron job
+	// so that at most maxPerJob runs per job are kept.
+	DeleteExcessCronRuns(maxPerJob int) error
+
+	// ---------------------------------------------------------------
+
 	// CollectionQuery returns a new Collection select query.
 	CollectionQuery() *dbx.SelectQuery
 
@@ -530,6 +563,168 @@ type App interface {
 
 	// ---------------------------------------------------------------
 
+	// FindTOTPByRecord returns the TOTP model linked to the provided auth record.
+	//
+	// Returns [sql.ErrNoRows] if the auth record doesn't have a TOTP enrollment.
+	FindTOTPByRecord(authRecord *Record) (*TOTP, error)
+
+	// ConsumeTOTPCode verifies the provided TOTP code (or one of the remaining
+	// recovery codes if allowRecoveryCode is set) and marks it as used.
+	//
+	// The code is marked as used with a single conditional db update so
+	// that when the same code is submitted concurrently only one of the calls succeeds.
+	// The others fail with [ErrInvalidTOTPCode].
+	//
+	// Returns whether a recovery code was used.
+	//
+	// Note that no model hooks are triggered for the totp update.
+	ConsumeTOTPCode(totp *TOTP, code string, encryptionKey string, allowRecoveryCode bool) (bool, error)
+
+	// ---------------------------------------------------------------
+
+	// FindAllWebAuthnCredentialsByRecord returns all WebAuthnCredential models
+	// linked to the provided auth record (in DESC order).
+	FindAllWebAuthnCredentialsByRecord(authRecord *Record) ([]*WebAuthnCredential, error)
+
+	// FindWebAuthnCredentialByCredentialId returns a single WebAuthnCredential model
+	// by its base64url encoded authenticator credential id.
+	//
+	// collectionModelOrIdentifier could be the auth collection model, id or name.
+	FindWebAuthnCredentialByCredentialId(collectionModelOrIdentifier any, credentialId string) (*WebAuthnCredential, error)
+
+	// ---------------------------------------------------------------
+
+	// FindAllAPIKeysByRecord returns all APIKey models linked to the provided auth record (in DESC order).
+	FindAllAPIKeysByRecord(authRecord *Record) ([]*APIKey, error)
+
+	// FindAPIKeyByKey returns a single APIKey model by its plain key value.
+	FindAPIKeyByKey(key string) (*APIKey, error)
+
+	// FindAuthRecordByAPIKey finds the auth record associated with the provided plain API key.
+	//
+	// Returns an error if the API key is invalid, expired, the API keys of the
+	// record collection are disabled or the key is not associated to an auth collection record.
+	FindAuthRecordByAPIKey(key string) (*Record, *APIKey, error)
+
+	// ---------------------------------------------------------------
+
+	// FindAllAuthSessionsByRecord returns all AuthSession models linked to the provided auth record (in DESC order).
+	FindAllAuthSessionsByRecord(authRecord *Record) ([]*AuthSession, error)
+
+	// FindAuthSessionById returns a single AuthSession model by its id.
+	FindAuthSessionById(id string) (*AuthSession, error)
+
+	// FindAuthSessionByRecordAndFingerprint returns a single AuthSession model
+	// by its authRecord relation and device fingerprint.
+	FindAuthSessionByRecordAndFingerprint(authRecord *Record, fingerprint string) (*AuthSession, error)
+
+	// FindAuthSessionByRefreshToken returns a single AuthSession model
+	// by its current or previous plain refresh token.
+	//
+	// Use [AuthSession.IsReusedRefreshToken] to check whether the found
+	// session was matched by an already exchanged refresh token.
+	FindAuthSessionByRefreshToken(token string) (*AuthSession, error)
+
+	// RotateAuthSessionRefreshToken exchanges the current plain refreshToken
+	// of the provided session with a new one and returns the new plain token.
+	//
+	// The rotation is performed with a single conditional db update
+	// (aka. compare-and-swap of the stored token hash) so that only one of
+	// multiple concurrent exchanges of the same refresh token could succeed.
+	// The others fail with [ErrAuthSessionTokenMismatch].
+	//
+	// Note that no model hooks are triggered for the session update.
+	RotateAuthSessionRefreshToken(session *AuthSession, refreshToken string, duration time.Duration) (string, error)
+
+	// DeleteAllAuthSessionsByRecord deletes all AuthSession models associated with the provided record.
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllAuthSessionsByRecord(authRecord *Record) error
+
+	// DeleteExpiredAuthSessions deletes the expired AuthSession models for all auth collections.
+	DeleteExpiredAuthSessions() error
+
+	// ---------------------------------------------------------------
+
+	// FindAllAuthLockoutsByRecord returns all AuthLockout models linked to the provided auth record.
+	FindAllAuthLockoutsByRecord(authRecord *Record) ([]*AuthLockout, error)
+
+	// FindAuthLockout returns the AuthLockout model of the provided auth record and auth method.
+	FindAuthLockout(authRecord *Record, method string) (*AuthLockout, error)
+
+	// DeleteAllAuthLockoutsByRecord deletes all AuthLockout models associated
+	// with the provided record (aka. unlocks the auth record).
+	//
+	// Returns a combined error with the failed deletes.
+	DeleteAllAuthLockoutsByRecord(authRecord *Record) error
+
+	// DeleteExpiredAuthLockouts deletes the AuthLockout models that are
+	// no longer locked and whose failed attempts counter window has passed.
+	DeleteExpiredAuthLockouts() error
+
+	// ---------------------------------------------------------------
+
+	// FindRecentPasswordChanges returns the latest limit PasswordChange models
+	// of the provided auth record ordered by their created date DESC.
+	//
+	// If limit is <= 0, all PasswordChange models of the auth record are returned.
+	FindRecentPasswordChanges(authRecord *Record, limit int) ([]*PasswordChange, error)
+
+	// DeleteAllPasswordChangesByRecord deletes all PasswordChange models associated with the provided record.
+	DeleteAllPasswordChangesByRecord(authRecord *Record) error
+
+	// ---------------------------------------------------------------
+
+	// FindPermissionByName returns a single Permission model by its name.
+	FindPermissionByName(name string) (*Permission, error)
+
+	// FindRoleByName returns a single Role model by its name.
+	FindRoleByName(name string) (*Role, error)
+
+	// FindAllRoleAssignmentsByRecord returns all RoleAssignment models
+	// linked to the provided auth record (in DESC order).
+	FindAllRoleAssignmentsByRecord(authRecord *Record) ([]*RoleAssignment, error)
+
+	// HasPermission checks whether the provided auth record has been
+	// assigned a role with the specified permission name.
+	//
+	// If scope is empty, only the global role assignments are checked,
+	// otherwise - both the global and the scope specific ones.
+	//
+	// This is the Go equivalent of the `@request.auth.can(permission, scope)` rule function.
+	HasPermission(authRecord *Record, permission string, scope string) (bool, error)
+
+	// ---------------------------------------------------------------
+
+	// FindWebhookDeliveryById returns a single WebhookDelivery model by its id.
+	FindWebhookDeliveryById(id string) (*WebhookDelivery, error)
+
+	// FindAllDueWebhookDeliveries returns up to limit pending WebhookDelivery
+	// models which next attempt date is in the past (oldest first).
+	FindAllDueWebhookDeliveries(limit int) ([]*WebhookDelivery, error)
+
+	// DeleteOldWebhookDeliveries deletes all completed (successful or failed)
+	// webhook deliveries that were last updated before the specified date.
+	DeleteOldWebhookDeliveries(updatedBefore time.Time) error
+
+	// DispatchWebhookDeliveries sends all currently due pending webhook deliveries.
+	//
+	// It is usually called automatically in the background after a record
+	// change and on every minute to retry the previously failed deliveries.
+	DispatchWebhookDeliveries() error
+
+	// SendWebhookDelivery sends a single pending webhook delivery
+	// and persists the attempt result in the delivery model.
+	//
+	// On failure the delivery is scheduled to be retried with exponential
+	// backoff until the max allowed attempts are reached.
+	//
+	// The returned error is only for the delivery model persistence.
+	// The request failure is available with [WebhookDelivery.LastError].
+	SendWebhookDelivery(delivery *WebhookDelivery) error
+
+	// ---------------------------------------------------------------
+
 	// FindAllAuthOriginsByRecord returns all AuthOrigin models linked to the provided auth record (in DESC order).
 	FindAllAuthOriginsByRecord(authRecord *Record) ([]*AuthOrigin, error)
 
@@ -639,12 +834,29 @@ type App interface {
 	// CountRecords returns the total number of records in a collection.
 	CountRecords(collectionModelOrIdentifier any, exprs ...dbx.Expression) (int64, error)
 
+	// AggregateRecords returns the aggregated values (count, sum, avg, min, max)
+	// of the records from the specified collection, grouped by the
+	// options.GroupBy field expressions.
+	//
+	// NB! Use the options.Params to bind untrusted user variables!
+	//
+	// Example:
+	//
+	//	app.AggregateRecords("orders", core.RecordsAggregateOptions{
+	//		GroupBy:    []string{"status"},
+	//		Aggregates: []string{"count()", "sum(total)"},
+	//		Filter:     "created >= {:date}",
+	//		Params:     dbx.Params{"date": "2025-01-01 00:00:00.000Z"},
+	//	})
+	AggregateRecords(collectionModelOrIdentifier any, options RecordsAggregateOptions) ([]map[string]any, error)
+
 	// FindAuthRecordByToken finds the auth record associated with the provided JWT
 	// (auth, file, verifyEmail, changeEmail, passwordReset types).
 	//
 	// Optionally specify a list of validTypes to check tokens only from those types.
 	//
-	// Returns an error if the JWT is invalid, expired or not associated to an auth collection record.
+	// Returns an error if the JWT is invalid, expired, linked to a revoked auth session
+	// or not associated to an auth collection record.
 	FindAuthRecordByToken(token string, validTypes ...string) (*Record, error)
 
 	// FindAuthRecordByEmail finds the auth record associated with the provided email.
@@ -672,6 +884,18 @@ type App interface {
 	//	if ok, _ := app.CanAccessRecord(record, requestInfo, rule); ok { ... }
 	CanAccessRecord(record *Record, requestInfo *RequestInfo, accessRule *string) (bool, error)
 
+	// CanAccessRealtimeChannel checks if the specified requestInfo
+	// satisfies a realtime channel subscribe or publish accessRule.
+	//
+	// Similar to CanAccessRecord, the rule checks are ignored in case
+	// requestInfo.Auth is a superuser, nil rule means "superusers only"
+	// and empty string rule means "everyone".
+	//
+	// Only the @request.* and @collection.* fields are allowed in the rule.
+	//
+	// The method always return false on invalid rule or db query error.
+	CanAccessRealtimeChannel(requestInfo *RequestInfo, accessRule *string) (bool, error)
+
 	// ExpandRecord expands the relations of a single Record model.
 	//
 	// If optFetchFunc is not set, then a default function will be used
@@ -1262,6 +1486,18 @@ type App interface {
 	// modify the submitted change.
 	OnRealtimeSubscribeRequest() *hook.Hook[*RealtimeSubscribeRequestEvent]
 
+	// OnRealtimePublishRequest hook is triggered when a client publishes
+	// a message to a custom realtime channel, allowing you to further
+	// validate and modify the published message data.
+	OnRealtimePublishRequest() *hook.Hook[*RealtimePublishRequestEvent]
+
+	// OnRealtimePresenceChange hook is triggered when a realtime client
+	// joins (subscribes) or leaves (unsubscribes or disconnects) a topic.
+	//
+	// The join/leave message is sent to the "TOPIC/presence" subscribers
+	// only if the hook handlers chain completes (aka. e.Next() is called).
+	OnRealtimePresenceChange() *hook.Hook[*RealtimePresenceEvent]
+
 	// ---------------------------------------------------------------
 	// Settings API event hooks
 	// ---------------------------------------------------------------
@@ -1329,6 +1565,9 @@ type App interface {
 	// OnRecordAuthWithOAuth2Request hook is triggered on each Record
 	// OAuth2 sign-in/sign-up API request (after token exchange and before external provider linking).
 	//
+	// It is also triggered for the SAML sign-in/sign-up API requests
+	// with [RecordAuthWithOAuth2RequestEvent.ProviderName] set to [SAMLProviderName].
+	//
 	// If [RecordAuthWithOAuth2RequestEvent.Record] is not set, then the OAuth2
 	// request will try to create a new auth Record.
 	//
@@ -1436,6 +1675,35 @@ type App interface {
 	// triggered and called only if their event data origin matches the tags.
 	OnRecordAuthWithOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithOTPRequestEvent]
 
+	// OnRecordAuthWithTOTPRequest hook is triggered on each Record
+	// auth with TOTP (authenticator app code) API request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithTOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithTOTPRequestEvent]
+
+	// OnRecordAuthWithWebAuthnRequest hook is triggered on each Record
+	// auth with WebAuthn (passkey) API request.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthWithWebAuthnRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithWebAuthnRequestEvent]
+
+	// OnRecordAuthLockout hook is triggered every time when an auth record
+	// gets temporary locked because of too many consecutive failed
+	// password or OTP authentication attempts.
+	//
+	// Could be used for example to notify the record owner or the superusers.
+	//
+	// Return an error from the hook to prevent the lockout from being applied.
+	//
+	// If the optional "tags" list (Collection ids or names) is specified,
+	// then all event handlers registered via the created hook will be
+	// triggered and called only if their event data origin matches the tags.
+	OnRecordAuthLockout(tags ...string) *hook.TaggedHook[*RecordAuthLockoutEvent]
+
 	// ---------------------------------------------------------------
 	// Record CRUD API event hooks
 	// ---------------------------------------------------------------
@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAuthLockouts = "_authLockouts"
+
+var (
+	_ Model        = (*AuthLockout)(nil)
+	_ PreValidator = (*AuthLockout)(nil)
+	_ RecordProxy  = (*AuthLockout)(nil)
+)
+
+// AuthLockout defines a Record proxy for working with the authLockouts collection.
+//
+// Each AuthLockout model tracks the consecutive failed attempts
+// of a single auth record for a specific auth method (password, otp).
+type AuthLockout struct {
+	*Record
+}
+
+// NewAuthLockout instantiates and returns a new blank *AuthLockout model.
+//
+// Example usage:
+//
+//	lockout := core.NewAuthLockout(app)
+//	lockout.SetRecordRef(user.Id)
+//	lockout.SetCollectionRef(user.Collection().Id)
+//	lockout.SetMethod(core.MFAMethodPassword)
+//	lockout.SetFailures(1)
+//	app.Save(lockout)
+func NewAuthLockout(app App) *AuthLockout {
+	m := &AuthLockout{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAuthLockouts)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on AuthLockout.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *AuthLockout) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAuthLockouts {
+		return errors.New("missing or invalid AuthLockout ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *AuthLockout) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *AuthLockout) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *AuthLockout) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *AuthLockout) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *AuthLockout) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *AuthLockout) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Method returns the "method" record field value (password, otp).
+func (m *AuthLockout) Method() string {
+	return m.GetString("method")
+}
+
+// SetMethod updates the "method" record field value.
+func (m *AuthLockout) SetMethod(method string) {
+	m.Set("method", method)
+}
+
+// Failures returns the "failures" record field value
+// (aka. the number of consecutive failed attempts).
+func (m *AuthLockout) Failures() int {
+	return m.GetInt("failures")
+}
+
+// SetFailures updates the "failures" record field value.
+func (m *AuthLockout) SetFailures(failures int) {
+	m.Set("failures", failures)
+}
+
+// LockedUntil returns the "lockedUntil" record field value.
+func (m *AuthLockout) LockedUntil() types.DateTime {
+	return m.GetDateTime("lockedUntil")
+}
+
+// SetLockedUntil updates the "lockedUntil" record field value.
+func (m *AuthLockout) SetLockedUntil(date types.DateTime) {
+	m.Set("lockedUntil", date)
+}
+
+// IsLocked checks whether the lockout "lockedUntil" date is still in the future.
+func (m *AuthLockout) IsLocked() bool {
+	return m.LockedUntil().Time().After(time.Now())
+}
+
+// Created returns the "created" record field value.
+func (m *AuthLockout) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value
+// (aka. the date of the last failed attempt).
+func (m *AuthLockout) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerAuthLockoutHooks() {
+	recordRefHooks[*AuthLockout](app, CollectionNameAuthLockouts, CollectionTypeAuth)
+
+	// unlock the auth record on password change
+	app.OnRecordUpdate().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			err := e.Next()
+			if err != nil || !e.Record.Collection().IsAuth() {
+				return err
+			}
+
+			old := e.Record.Original().GetString(FieldNamePassword + ":hash")
+			new := e.Record.GetString(FieldNamePassword + ":hash")
+			if old != new {
+				err = e.App.DeleteAllAuthLockoutsByRecord(e.Record)
+				if err != nil {
+					e.App.Logger().Warn(
+						"Failed to delete the auth record lockouts",
+						"error", err,
+						"recordId", e.Record.Id,
+						"collectionId", e.Record.Collection().Id,
+					)
+				}
+			}
+
+			return nil
+		},
+		Priority: 99,
+	})
+
+	// run on every hour to cleanup the stale lockouts
+	app.Cron().Add("__pbAuthLockoutsCleanup__", "0 * * * *", func() {
+		if err := app.DeleteExpiredAuthLockouts(); err != nil {
+			app.Logger().Warn("Failed to delete expired auth lockouts", "error", err)
+		}
+	})
+}
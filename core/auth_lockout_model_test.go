@@ -0,0 +1,287 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAuthLockout(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	if lockout.Collection().Name != core.CollectionNameAuthLockouts {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAuthLockouts, lockout.Collection().Name)
+	}
+}
+
+func TestAuthLockoutProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	lockout := core.AuthLockout{}
+	lockout.SetProxyRecord(record)
+
+	if lockout.ProxyRecord() == nil || lockout.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, lockout.ProxyRecord())
+	}
+}
+
+func TestAuthLockoutStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", lockout.SetCollectionRef, lockout.CollectionRef},
+		{"recordRef", lockout.SetRecordRef, lockout.RecordRef},
+		{"method", lockout.SetMethod, lockout.Method},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := lockout.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthLockoutFailures(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockout := core.NewAuthLockout(app)
+
+	for _, testValue := range []int{0, 1, 100} {
+		t.Run(fmt.Sprint(testValue), func(t *testing.T) {
+			lockout.SetFailures(testValue)
+
+			if v := lockout.Failures(); v != testValue {
+				t.Fatalf("Expected getter %d, got %d", testValue, v)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutIsLocked(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	now := types.NowDateTime()
+
+	scenarios := []struct {
+		lockedUntil types.DateTime
+		expected    bool
+	}{
+		{types.DateTime{}, false},
+		{now.Add(-1 * time.Minute), false},
+		{now.Add(1 * time.Minute), true},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.lockedUntil.String()), func(t *testing.T) {
+			lockout := core.NewAuthLockout(app)
+			lockout.SetLockedUntil(s.lockedUntil)
+
+			if v := lockout.LockedUntil().String(); v != s.lockedUntil.String() {
+				t.Fatalf("Expected lockedUntil %q, got %q", s.lockedUntil.String(), v)
+			}
+
+			if v := lockout.IsLocked(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	lockoutsCol, err := app.FindCollectionByNameOrId(core.CollectionNameAuthLockouts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		lockout := &core.AuthLockout{}
+
+		if err := app.Validate(lockout); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-AuthLockout collection", func(t *testing.T) {
+		lockout := &core.AuthLockout{}
+		lockout.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		lockout.SetRecordRef(user.Id)
+		lockout.SetCollectionRef(user.Collection().Id)
+		lockout.SetMethod(core.MFAMethodPassword)
+
+		if err := app.Validate(lockout); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("AuthLockout collection", func(t *testing.T) {
+		lockout := &core.AuthLockout{}
+		lockout.SetProxyRecord(core.NewRecord(lockoutsCol))
+		lockout.SetRecordRef(user.Id)
+		lockout.SetCollectionRef(user.Collection().Id)
+		lockout.SetMethod(core.MFAMethodPassword)
+
+		if err := app.Validate(lockout); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAuthLockoutValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		lockout      func() *core.AuthLockout
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.AuthLockout {
+				return core.NewAuthLockout(app)
+			},
+			[]string{"collectionRef", "recordRef", "method"},
+		},
+		{
+			"non-auth collection",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(demo1.Collection().Id)
+				lockout.SetRecordRef(demo1.Id)
+				lockout.SetMethod(core.MFAMethodPassword)
+				return lockout
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				lockout.SetRecordRef("missing")
+				lockout.SetMethod(core.MFAMethodPassword)
+				return lockout
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.AuthLockout {
+				lockout := core.NewAuthLockout(app)
+				lockout.SetCollectionRef(user.Collection().Id)
+				lockout.SetRecordRef(user.Id)
+				lockout.SetMethod(core.MFAMethodPassword)
+				return lockout
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.lockout())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestAuthLockoutPasswordChangeHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// update without password change
+	user.Set("name", "new_name")
+	if err = app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	lockouts, err := app.FindAllAuthLockoutsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lockouts) != 2 {
+		t.Fatalf("Expected the lockouts to remain, got %d", len(lockouts))
+	}
+
+	// update with password change
+	user.SetPassword("1234567890_new")
+	if err = app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	lockouts, err = app.FindAllAuthLockoutsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lockouts) != 0 {
+		t.Fatalf("Expected the lockouts to be deleted, got %d", len(lockouts))
+	}
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindAllAuthLockoutsByRecord returns all AuthLockout models linked to the provided auth record.
+func (app *BaseApp) FindAllAuthLockoutsByRecord(authRecord *Record) ([]*AuthLockout, error) {
+	result := []*AuthLockout{}
+
+	err := app.RecordQuery(CollectionNameAuthLockouts).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthLockout returns the AuthLockout model of the provided auth record and auth method.
+func (app *BaseApp) FindAuthLockout(authRecord *Record, method string) (*AuthLockout, error) {
+	result := &AuthLockout{}
+
+	err := app.RecordQuery(CollectionNameAuthLockouts).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+			"method":        method,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAllAuthLockoutsByRecord deletes all AuthLockout models associated
+// with the provided record (aka. unlocks the auth record).
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllAuthLockoutsByRecord(authRecord *Record) error {
+	models, err := app.FindAllAuthLockoutsByRecord(authRecord)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// DeleteExpiredAuthLockouts deletes the AuthLockout models that are
+// no longer locked and whose failed attempts counter window has passed.
+func (app *BaseApp) DeleteExpiredAuthLockouts() error {
+	authCollections, err := app.FindAllCollections(CollectionTypeAuth)
+	if err != nil {
+		return err
+	}
+
+	now := types.NowDateTime()
+
+	// note: perform even if the lockout is disabled to ensure that there are no dangling old records
+	for _, collection := range authCollections {
+		windows := map[string]time.Duration{
+			MFAMethodPassword: collection.PasswordAuth.Lockout.MaxDurationTime(),
+			MFAMethodOTP:      collection.OTP.Lockout.MaxDurationTime(),
+		}
+
+		for method, window := range windows {
+			items := []*Record{}
+
+			err = app.RecordQuery(CollectionNameAuthLockouts).
+				AndWhere(dbx.HashExp{"collectionRef": collection.Id, "method": method}).
+				AndWhere(dbx.NewExp("[[updated]] < {:date}", dbx.Params{"date": now.Add(-1 * window)})).
+				AndWhere(dbx.NewExp("[[lockedUntil]] < {:now}", dbx.Params{"now": now})).
+				All(&items)
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				err = app.Delete(item)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
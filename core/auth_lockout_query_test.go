@@ -0,0 +1,244 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// stubAuthLockouts creates a password (locked) and otp (unlocked) lockout
+// for test@example.com and a password (unlocked) lockout for test2@example.com.
+func stubAuthLockouts(t *testing.T, app core.App) {
+	items := []struct {
+		email  string
+		method string
+		locked bool
+	}{
+		{"test@example.com", core.MFAMethodPassword, true},
+		{"test@example.com", core.MFAMethodOTP, false},
+		{"test2@example.com", core.MFAMethodPassword, false},
+	}
+
+	for _, item := range items {
+		user, err := app.FindAuthRecordByEmail("users", item.email)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lockout := core.NewAuthLockout(app)
+		lockout.SetCollectionRef(user.Collection().Id)
+		lockout.SetRecordRef(user.Id)
+		lockout.SetMethod(item.method)
+		lockout.SetFailures(3)
+		if item.locked {
+			lockout.SetLockedUntil(types.NowDateTime().Add(1 * time.Hour))
+		}
+		if err := app.Save(lockout); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindAllAuthLockoutsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	scenarios := []struct {
+		email    string
+		expected int
+	}{
+		{"test@example.com", 2},
+		{"test2@example.com", 1},
+		{"test3@example.com", 0},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.email, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.FindAllAuthLockoutsByRecord(user)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != s.expected {
+				t.Fatalf("Expected %d lockouts, got %d", s.expected, len(result))
+			}
+
+			for _, lockout := range result {
+				if lockout.RecordRef() != user.Id {
+					t.Fatalf("Expected lockout for record %q, got %q", user.Id, lockout.RecordRef())
+				}
+			}
+		})
+	}
+}
+
+func TestFindAuthLockout(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	scenarios := []struct {
+		email          string
+		method         string
+		expectError    bool
+		expectedLocked bool
+	}{
+		{"test@example.com", "", true, false},
+		{"test@example.com", "missing", true, false},
+		{"test@example.com", core.MFAMethodPassword, false, true},
+		{"test@example.com", core.MFAMethodOTP, false, false},
+		{"test2@example.com", core.MFAMethodOTP, true, false},
+		{"test2@example.com", core.MFAMethodPassword, false, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.email+"_"+s.method, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.FindAuthLockout(user, s.method)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Method() != s.method || result.RecordRef() != user.Id {
+				t.Fatalf("Expected %s lockout for record %q, got %s for %q", s.method, user.Id, result.Method(), result.RecordRef())
+			}
+
+			if result.IsLocked() != s.expectedLocked {
+				t.Fatalf("Expected locked %v, got %v", s.expectedLocked, result.IsLocked())
+			}
+		})
+	}
+}
+
+func TestDeleteAllAuthLockoutsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.DeleteAllAuthLockoutsByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected int
+	}{
+		{user1, 0},
+		{user2, 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Email(), func(t *testing.T) {
+			result, err := app.FindAllAuthLockoutsByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != s.expected {
+				t.Fatalf("Expected %d lockouts, got %d", s.expected, len(result))
+			}
+		})
+	}
+}
+
+func TestDeleteExpiredAuthLockouts(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthLockouts(t, app)
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users.PasswordAuth.Lockout.MaxDuration = 60
+	users.OTP.Lockout.MaxDuration = 60
+	if err = app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	// make all lockouts stale
+	_, err = app.DB().NewQuery("UPDATE {{" + core.CollectionNameAuthLockouts + "}} SET [[updated]] = {:date}").
+		Bind(dbx.Params{"date": types.NowDateTime().Add(-2 * time.Minute)}).
+		Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.DeleteExpiredAuthLockouts(); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		email    string
+		expected []string
+	}{
+		{"test@example.com", []string{core.MFAMethodPassword}}, // still locked
+		{"test2@example.com", nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.email, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.FindAllAuthLockoutsByRecord(user)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected %d lockouts, got %d", len(s.expected), len(result))
+			}
+
+			for i, lockout := range result {
+				if lockout.Method() != s.expected[i] {
+					t.Fatalf("Expected lockout %d method %q, got %q", i, s.expected[i], lockout.Method())
+				}
+			}
+		})
+	}
+}
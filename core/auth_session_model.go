@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameAuthSessions = "_authSessions"
+
+var (
+	_ Model        = (*AuthSession)(nil)
+	_ PreValidator = (*AuthSession)(nil)
+	_ RecordProxy  = (*AuthSession)(nil)
+)
+
+// AuthSession defines a Record proxy for working with the authSessions collection.
+//
+// Each auth session represents a single signed in device and holds
+// the hash of its current (rotating) refresh token.
+type AuthSession struct {
+	*Record
+}
+
+// NewAuthSession instantiates and returns a new blank *AuthSession model.
+//
+// Example usage:
+//
+//	session := core.NewAuthSession(app)
+//	session.SetRecordRef(user.Id)
+//	session.SetCollectionRef(user.Collection().Id)
+//	session.SetFingerprint("...")
+//	refreshToken := session.RotateRefreshToken(user.Collection().RefreshToken.DurationTime())
+//	app.Save(session)
+func NewAuthSession(app App) *AuthSession {
+	m := &AuthSession{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameAuthSessions)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on AuthSession.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *AuthSession) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameAuthSessions {
+		return errors.New("missing or invalid AuthSession ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *AuthSession) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *AuthSession) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *AuthSession) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *AuthSession) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *AuthSession) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *AuthSession) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Fingerprint returns the "fingerprint" record field value
+// (it matches with the related AuthOrigin fingerprint).
+func (m *AuthSession) Fingerprint() string {
+	return m.GetString("fingerprint")
+}
+
+// SetFingerprint updates the "fingerprint" record field value.
+func (m *AuthSession) SetFingerprint(fingerprint string) {
+	m.Set("fingerprint", fingerprint)
+}
+
+// RotateRefreshToken generates a new random refresh token, stores its
+// hash in the "tokenHash" record field (moving the old one to "previousTokenHash"),
+// extends the session expiration with the specified duration and returns the plain token.
+//
+// Note that only the token hash is persisted, so the returned plain token
+// must be sent to the client right away as it cannot be recovered later.
+func (m *AuthSession) RotateRefreshToken(duration time.Duration) string {
+	token := security.RandomString(50)
+
+	m.Set("previousTokenHash", m.GetString("tokenHash"))
+	m.Set("tokenHash", hashRefreshToken(token))
+	m.SetExpires(types.NowDateTime().Add(duration))
+
+	return token
+}
+
+// ValidateRefreshToken reports whether the provided plain refresh token
+// matches with the current session token hash.
+func (m *AuthSession) ValidateRefreshToken(token string) bool {
+	hash := m.GetString("tokenHash")
+
+	return hash != "" && security.Equal(hash, hashRefreshToken(token))
+}
+
+// IsReusedRefreshToken reports whether the provided plain refresh token
+// matches with the previous (already exchanged) session token hash.
+//
+// A reused refresh token usually means that the token was leaked.
+func (m *AuthSession) IsReusedRefreshToken(token string) bool {
+	hash := m.GetString("previousTokenHash")
+
+	return hash != "" && security.Equal(hash, hashRefreshToken(token))
+}
+
+// Expires returns the "expires" record field value.
+func (m *AuthSession) Expires() types.DateTime {
+	return m.GetDateTime("expires")
+}
+
+// SetExpires updates the "expires" record field value.
+func (m *AuthSession) SetExpires(date types.DateTime) {
+	m.Set("expires", date)
+}
+
+// HasExpired checks whether the session expiration date has already passed.
+func (m *AuthSession) HasExpired() bool {
+	return !m.Expires().Time().After(time.Now())
+}
+
+// Created returns the "created" record field value.
+func (m *AuthSession) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *AuthSession) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func hashRefreshToken(token string) string {
+	return security.SHA256(token)
+}
+
+func (app *BaseApp) registerAuthSessionHooks() {
+	recordRefHooks[*AuthSession](app, CollectionNameAuthSessions, CollectionTypeAuth)
+
+	// revoke all existing sessions on tokenKey change
+	// (e.g. on password change or explicit tokenKey refresh)
+	app.OnRecordUpdate().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			err := e.Next()
+			if err != nil || !e.Record.Collection().IsAuth() {
+				return err
+			}
+
+			if e.Record.Original().TokenKey() != e.Record.TokenKey() {
+				err = e.App.DeleteAllAuthSessionsByRecord(e.Record)
+				if err != nil {
+					e.App.Logger().Warn(
+						"Failed to delete all previous auth sessions",
+						"error", err,
+						"recordId", e.Record.Id,
+						"collectionId", e.Record.Collection().Id,
+					)
+				}
+			}
+
+			return nil
+		},
+		Priority: 99,
+	})
+
+	// run on every hour to cleanup expired auth sessions
+	app.Cron().Add("__pbAuthSessionsCleanup__", "0 * * * *", func() {
+		if err := app.DeleteExpiredAuthSessions(); err != nil {
+			app.Logger().Warn("Failed to delete expired auth sessions", "error", err)
+		}
+	})
+}
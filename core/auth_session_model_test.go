@@ -0,0 +1,342 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewAuthSession(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	session := core.NewAuthSession(app)
+
+	if session.Collection().Name != core.CollectionNameAuthSessions {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameAuthSessions, session.Collection().Name)
+	}
+}
+
+func TestAuthSessionProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	session := core.AuthSession{}
+	session.SetProxyRecord(record)
+
+	if session.ProxyRecord() == nil || session.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, session.ProxyRecord())
+	}
+}
+
+func TestAuthSessionStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	session := core.NewAuthSession(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", session.SetCollectionRef, session.CollectionRef},
+		{"recordRef", session.SetRecordRef, session.RecordRef},
+		{"fingerprint", session.SetFingerprint, session.Fingerprint},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := session.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestAuthSessionRotateRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	session := core.NewAuthSession(app)
+
+	if session.ValidateRefreshToken("") {
+		t.Fatal("Expected empty token to be invalid")
+	}
+
+	token1 := session.RotateRefreshToken(1 * time.Hour)
+	if token1 == "" {
+		t.Fatal("Expected non-empty refresh token")
+	}
+
+	if session.GetString("tokenHash") == token1 {
+		t.Fatal("Expected the plain token to not be stored")
+	}
+
+	expires := session.Expires().Time()
+	if d := time.Until(expires); d < 59*time.Minute || d > 1*time.Hour {
+		t.Fatalf("Expected expires to be ~1h from now, got %v", expires)
+	}
+
+	if !session.ValidateRefreshToken(token1) {
+		t.Fatal("Expected token1 to be valid")
+	}
+
+	if session.IsReusedRefreshToken(token1) {
+		t.Fatal("Expected token1 to not be marked as reused")
+	}
+
+	token2 := session.RotateRefreshToken(2 * time.Hour)
+	if token2 == token1 {
+		t.Fatal("Expected a new refresh token to be generated")
+	}
+
+	if session.ValidateRefreshToken(token1) {
+		t.Fatal("Expected token1 to be no longer valid")
+	}
+
+	if !session.IsReusedRefreshToken(token1) {
+		t.Fatal("Expected token1 to be marked as reused")
+	}
+
+	if !session.ValidateRefreshToken(token2) {
+		t.Fatal("Expected token2 to be valid")
+	}
+
+	if session.IsReusedRefreshToken(token2) {
+		t.Fatal("Expected token2 to not be marked as reused")
+	}
+
+	if d := time.Until(session.Expires().Time()); d < 119*time.Minute {
+		t.Fatalf("Expected expires to be extended, got %v", d)
+	}
+}
+
+func TestAuthSessionHasExpired(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	now := types.NowDateTime()
+
+	scenarios := []struct {
+		expires  types.DateTime
+		expected bool
+	}{
+		{types.DateTime{}, true},
+		{now.Add(-1 * time.Minute), true},
+		{now.Add(1 * time.Minute), false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s", i, s.expires.String()), func(t *testing.T) {
+			session := core.NewAuthSession(app)
+			session.SetExpires(s.expires)
+
+			if v := session.Expires().String(); v != s.expires.String() {
+				t.Fatalf("Expected expires %q, got %q", s.expires.String(), v)
+			}
+
+			if v := session.HasExpired(); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestAuthSessionPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	sessionsCol, err := app.FindCollectionByNameOrId(core.CollectionNameAuthSessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		session := &core.AuthSession{}
+
+		if err := app.Validate(session); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-AuthSession collection", func(t *testing.T) {
+		session := &core.AuthSession{}
+		session.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		session.SetRecordRef(user.Id)
+		session.SetCollectionRef(user.Collection().Id)
+		session.RotateRefreshToken(1 * time.Hour)
+
+		if err := app.Validate(session); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("AuthSession collection", func(t *testing.T) {
+		session := &core.AuthSession{}
+		session.SetProxyRecord(core.NewRecord(sessionsCol))
+		session.SetRecordRef(user.Id)
+		session.SetCollectionRef(user.Collection().Id)
+		session.RotateRefreshToken(1 * time.Hour)
+
+		if err := app.Validate(session); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestAuthSessionValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		session      func() *core.AuthSession
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.AuthSession {
+				return core.NewAuthSession(app)
+			},
+			[]string{"collectionRef", "recordRef", "tokenHash", "expires"},
+		},
+		{
+			"non-auth collection",
+			func() *core.AuthSession {
+				session := core.NewAuthSession(app)
+				session.SetCollectionRef(demo1.Collection().Id)
+				session.SetRecordRef(demo1.Id)
+				session.RotateRefreshToken(1 * time.Hour)
+				return session
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.AuthSession {
+				session := core.NewAuthSession(app)
+				session.SetCollectionRef(user.Collection().Id)
+				session.SetRecordRef("missing")
+				session.RotateRefreshToken(1 * time.Hour)
+				return session
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.AuthSession {
+				session := core.NewAuthSession(app)
+				session.SetCollectionRef(user.Collection().Id)
+				session.SetRecordRef(user.Id)
+				session.RotateRefreshToken(1 * time.Hour)
+				return session
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.session())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestAuthSessionTokenKeyChangeHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthSessions(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// update without tokenKey change
+	user.Set("name", "new_name")
+	if err = app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := app.FindAllAuthSessionsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("Expected the sessions to remain, got %d", len(sessions))
+	}
+
+	// update with tokenKey change
+	user.RefreshTokenKey()
+	if err = app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err = app.FindAllAuthSessionsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("Expected the sessions to be deleted, got %d", len(sessions))
+	}
+
+	// the other users sessions should remain
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err = app.FindAllAuthSessionsByRecord(user2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected the other user sessions to remain, got %d", len(sessions))
+	}
+}
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindAllAuthSessionsByRecord returns all AuthSession models linked to the provided auth record (in DESC order).
+func (app *BaseApp) FindAllAuthSessionsByRecord(authRecord *Record) ([]*AuthSession, error) {
+	result := []*AuthSession{}
+
+	err := app.RecordQuery(CollectionNameAuthSessions).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("updated DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthSessionById returns a single AuthSession model by its id.
+func (app *BaseApp) FindAuthSessionById(id string) (*AuthSession, error) {
+	result := &AuthSession{}
+
+	q := app.RecordQuery(CollectionNameAuthSessions).
+		AndWhere(dbx.HashExp{"id": id})
+
+	err := q.Limit(1).One(result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthSessionByRecordAndFingerprint returns a single AuthSession model
+// by its authRecord relation and device fingerprint.
+func (app *BaseApp) FindAuthSessionByRecordAndFingerprint(authRecord *Record, fingerprint string) (*AuthSession, error) {
+	result := &AuthSession{}
+
+	err := app.RecordQuery(CollectionNameAuthSessions).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+			"fingerprint":   fingerprint,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAuthSessionByRefreshToken returns a single AuthSession model
+// by its current or previous plain refresh token.
+//
+// Use [AuthSession.IsReusedRefreshToken] to check whether the found
+// session was matched by an already exchanged refresh token.
+func (app *BaseApp) FindAuthSessionByRefreshToken(token string) (*AuthSession, error) {
+	if token == "" {
+		return nil, errors.New("missing refresh token")
+	}
+
+	result := &AuthSession{}
+
+	hash := hashRefreshToken(token)
+
+	err := app.RecordQuery(CollectionNameAuthSessions).
+		AndWhere(dbx.Or(
+			dbx.HashExp{"tokenHash": hash},
+			dbx.HashExp{"previousTokenHash": hash},
+		)).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ErrAuthSessionTokenMismatch is returned when the auth session
+// refresh token was already rotated (e.g. by a concurrent request).
+var ErrAuthSessionTokenMismatch = errors.New("the auth session refresh token was already rotated")
+
+// RotateAuthSessionRefreshToken exchanges the current plain refreshToken
+// of the provided session with a new one and returns the new plain token.
+//
+// The rotation is performed with a single conditional db update
+// (aka. compare-and-swap of the stored token hash) so that only one of
+// multiple concurrent exchanges of the same refresh token could succeed.
+// The others fail with [ErrAuthSessionTokenMismatch].
+//
+// Note that no model hooks are triggered for the session update.
+func (app *BaseApp) RotateAuthSessionRefreshToken(session *AuthSession, refreshToken string, duration time.Duration) (string, error) {
+	oldHash := hashRefreshToken(refreshToken)
+
+	if !session.ValidateRefreshToken(refreshToken) {
+		return "", ErrAuthSessionTokenMismatch
+	}
+
+	// rotate a copy to leave the session unchanged in case of a failure
+	rotated := &AuthSession{Record: session.Clone()}
+	newToken := rotated.RotateRefreshToken(duration)
+	rotated.SetRaw("updated", types.NowDateTime())
+
+	result, err := app.DB().Update(
+		CollectionNameAuthSessions,
+		dbx.Params{
+			"tokenHash":         rotated.GetString("tokenHash"),
+			"previousTokenHash": rotated.GetString("previousTokenHash"),
+			"expires":           rotated.Expires().String(),
+			"updated":           rotated.Updated().String(),
+		},
+		dbx.HashExp{"id": session.Id, "tokenHash": oldHash},
+	).Execute()
+	if err != nil {
+		return "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if affected == 0 {
+		return "", ErrAuthSessionTokenMismatch
+	}
+
+	session.SetProxyRecord(rotated.Record)
+
+	return newToken, nil
+}
+
+// DeleteAllAuthSessionsByRecord deletes all AuthSession models associated with the provided record.
+//
+// Returns a combined error with the failed deletes.
+func (app *BaseApp) DeleteAllAuthSessionsByRecord(authRecord *Record) error {
+	models, err := app.FindAllAuthSessionsByRecord(authRecord)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, m := range models {
+		if err := app.Delete(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	return nil
+}
+
+// DeleteExpiredAuthSessions deletes the expired AuthSession models for all auth collections.
+func (app *BaseApp) DeleteExpiredAuthSessions() error {
+	items := []*Record{}
+
+	err := app.RecordQuery(CollectionNameAuthSessions).
+		AndWhere(dbx.NewExp("[[expires]] < {:date}", dbx.Params{"date": types.NowDateTime()})).
+		All(&items)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		err = app.Delete(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
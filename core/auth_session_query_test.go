@@ -0,0 +1,360 @@
+package core_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// stubAuthSessions creates 2 auth sessions for test@example.com (the second one expired)
+// and 1 for test2@example.com and returns their plain refresh tokens.
+func stubAuthSessions(t *testing.T, app core.App) []string {
+	users := []string{"test@example.com", "test@example.com", "test2@example.com"}
+
+	tokens := make([]string, len(users))
+
+	for i, email := range users {
+		user, err := app.FindAuthRecordByEmail("users", email)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		session := core.NewAuthSession(app)
+		session.SetCollectionRef(user.Collection().Id)
+		session.SetRecordRef(user.Id)
+		session.SetFingerprint("fingerprint" + string(rune('0'+i)))
+		tokens[i] = session.RotateRefreshToken(1 * time.Hour)
+		if i == 1 {
+			session.SetExpires(types.NowDateTime().Add(-1 * time.Hour))
+		}
+		if err := app.Save(session); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return tokens
+}
+
+func TestFindAllAuthSessionsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthSessions(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user3, err := app.FindAuthRecordByEmail("users", "test3@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected []string
+	}{
+		{user1, []string{"fingerprint0", "fingerprint1"}},
+		{user2, []string{"fingerprint2"}},
+		{user3, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Email(), func(t *testing.T) {
+			result, err := app.FindAllAuthSessionsByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected total sessions %d, got %d", len(s.expected), len(result))
+			}
+
+			for _, session := range result {
+				if !slices.Contains(s.expected, session.Fingerprint()) {
+					t.Fatalf("Unexpected session %q", session.Fingerprint())
+				}
+			}
+		})
+	}
+}
+
+func TestFindAuthSessionById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthSessions(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := app.FindAllAuthSessionsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{sessions[0].Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindAuthSessionById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result.Id != s.id {
+				t.Fatalf("Expected session %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindAuthSessionByRecordAndFingerprint(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthSessions(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		record      *core.Record
+		fingerprint string
+		expectError bool
+	}{
+		{"empty fingerprint", user1, "", true},
+		{"missing fingerprint", user1, "missing", true},
+		{"fingerprint from different record", user2, "fingerprint0", true},
+		{"existing fingerprint", user1, "fingerprint0", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := app.FindAuthSessionByRecordAndFingerprint(s.record, s.fingerprint)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result.Fingerprint() != s.fingerprint {
+				t.Fatalf("Expected session with fingerprint %q, got %q", s.fingerprint, result.Fingerprint())
+			}
+		})
+	}
+}
+
+func TestFindAuthSessionByRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tokens := stubAuthSessions(t, app)
+
+	// rotate the last session token to check the previous token lookup
+	rotated, err := app.FindAuthSessionByRefreshToken(tokens[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	newToken := rotated.RotateRefreshToken(1 * time.Hour)
+	if err = app.Save(rotated); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name                string
+		token               string
+		expectError         bool
+		expectedFingerprint string
+		expectedReused      bool
+	}{
+		{"empty token", "", true, "", false},
+		{"missing token", "missing", true, "", false},
+		{"current token", tokens[0], false, "fingerprint0", false},
+		{"expired session token", tokens[1], false, "fingerprint1", false},
+		{"previous token", tokens[2], false, "fingerprint2", true},
+		{"rotated token", newToken, false, "fingerprint2", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := app.FindAuthSessionByRefreshToken(s.token)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Fingerprint() != s.expectedFingerprint {
+				t.Fatalf("Expected session with fingerprint %q, got %q", s.expectedFingerprint, result.Fingerprint())
+			}
+
+			if v := result.IsReusedRefreshToken(s.token); v != s.expectedReused {
+				t.Fatalf("Expected reused %v, got %v", s.expectedReused, v)
+			}
+		})
+	}
+}
+
+func TestDeleteAllAuthSessionsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubAuthSessions(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.DeleteAllAuthSessionsByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected int
+	}{
+		{user1, 0},
+		{user2, 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Email(), func(t *testing.T) {
+			result, err := app.FindAllAuthSessionsByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != s.expected {
+				t.Fatalf("Expected %d sessions, got %d", s.expected, len(result))
+			}
+		})
+	}
+}
+
+func TestDeleteExpiredAuthSessions(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tokens := stubAuthSessions(t, app)
+
+	if err := app.DeleteExpiredAuthSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, token := range tokens {
+		_, err := app.FindAuthSessionByRefreshToken(token)
+
+		expectDeleted := i == 1
+
+		if deleted := err != nil; deleted != expectDeleted {
+			t.Fatalf("[%d] Expected deleted %v, got %v (%v)", i, expectDeleted, deleted, err)
+		}
+	}
+}
+
+func TestRotateAuthSessionRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tokens := stubAuthSessions(t, app)
+
+	session, err := app.FindAuthSessionByRefreshToken(tokens[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// stale copy of the same session (e.g. loaded by a concurrent request)
+	staleSession, err := app.FindAuthSessionByRefreshToken(tokens[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = app.RotateAuthSessionRefreshToken(session, "invalid", 1*time.Hour); !errors.Is(err, core.ErrAuthSessionTokenMismatch) {
+		t.Fatalf("Expected ErrAuthSessionTokenMismatch for invalid token, got %v", err)
+	}
+
+	newToken, err := app.RotateAuthSessionRefreshToken(session, tokens[0], 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = app.RotateAuthSessionRefreshToken(staleSession, tokens[0], 2*time.Hour); !errors.Is(err, core.ErrAuthSessionTokenMismatch) {
+		t.Fatalf("Expected ErrAuthSessionTokenMismatch for the already rotated token, got %v", err)
+	}
+
+	if !session.ValidateRefreshToken(newToken) {
+		t.Fatal("Expected the session model to be updated with the new token")
+	}
+
+	stored, err := app.FindAuthSessionById(session.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !stored.ValidateRefreshToken(newToken) {
+		t.Fatal("Expected the new refresh token to be valid")
+	}
+
+	if !stored.IsReusedRefreshToken(tokens[0]) {
+		t.Fatal("Expected the old refresh token to be marked as exchanged")
+	}
+
+	if d := time.Until(stored.Expires().Time()); d < 119*time.Minute {
+		t.Fatalf("Expected the session expiration to be extended, got %v", d)
+	}
+}
@@ -76,6 +76,7 @@ type BaseApp struct {
 	cron                *cron.Cron
 	settings            *Settings
 	subscriptionsBroker *subscriptions.Broker
+	jobs                *Jobs
 	logger              *slog.Logger
 	concurrentDB        dbx.Builder
 	nonconcurrentDB     dbx.Builder
@@ -147,6 +148,8 @@ type BaseApp struct {
 	onRealtimeConnectRequest   *hook.Hook[*RealtimeConnectRequestEvent]
 	onRealtimeMessageSend      *hook.Hook[*RealtimeMessageEvent]
 	onRealtimeSubscribeRequest *hook.Hook[*RealtimeSubscribeRequestEvent]
+	onRealtimePublishRequest   *hook.Hook[*RealtimePublishRequestEvent]
+	onRealtimePresenceChange   *hook.Hook[*RealtimePresenceEvent]
 
 	// settings event hooks
 	onSettingsListRequest   *hook.Hook[*SettingsListRequestEvent]
@@ -170,6 +173,9 @@ type BaseApp struct {
 	onRecordConfirmEmailChangeRequest   *hook.Hook[*RecordConfirmEmailChangeRequestEvent]
 	onRecordRequestOTPRequest           *hook.Hook[*RecordCreateOTPRequestEvent]
 	onRecordAuthWithOTPRequest          *hook.Hook[*RecordAuthWithOTPRequestEvent]
+	onRecordAuthWithTOTPRequest         *hook.Hook[*RecordAuthWithTOTPRequestEvent]
+	onRecordAuthWithWebAuthnRequest     *hook.Hook[*RecordAuthWithWebAuthnRequestEvent]
+	onRecordAuthLockout                 *hook.Hook[*RecordAuthLockoutEvent]
 
 	// record crud API event hooks
 	onRecordsListRequest  *hook.Hook[*RecordsListRequestEvent]
@@ -201,6 +207,7 @@ func NewBaseApp(config BaseAppConfig) *BaseApp {
 		subscriptionsBroker: subscriptions.NewBroker(),
 		config:              &config,
 	}
+	app.jobs = newJobs(app)
 
 	// apply config defaults
 	if app.config.DBConnect == nil {
@@ -295,6 +302,8 @@ func (app *BaseApp) initHooks() {
 	app.onRealtimeConnectRequest = &hook.Hook[*RealtimeConnectRequestEvent]{}
 	app.onRealtimeMessageSend = &hook.Hook[*RealtimeMessageEvent]{}
 	app.onRealtimeSubscribeRequest = &hook.Hook[*RealtimeSubscribeRequestEvent]{}
+	app.onRealtimePublishRequest = &hook.Hook[*RealtimePublishRequestEvent]{}
+	app.onRealtimePresenceChange = &hook.Hook[*RealtimePresenceEvent]{}
 
 	// settings event hooks
 	app.onSettingsListRequest = &hook.Hook[*SettingsListRequestEvent]{}
@@ -318,6 +327,9 @@ func (app *BaseApp) initHooks() {
 	app.onRecordConfirmEmailChangeRequest = &hook.Hook[*RecordConfirmEmailChangeRequestEvent]{}
 	app.onRecordRequestOTPRequest = &hook.Hook[*RecordCreateOTPRequestEvent]{}
 	app.onRecordAuthWithOTPRequest = &hook.Hook[*RecordAuthWithOTPRequestEvent]{}
+	app.onRecordAuthWithTOTPRequest = &hook.Hook[*RecordAuthWithTOTPRequestEvent]{}
+	app.onRecordAuthWithWebAuthnRequest = &hook.Hook[*RecordAuthWithWebAuthnRequestEvent]{}
+	app.onRecordAuthLockout = &hook.Hook[*RecordAuthLockoutEvent]{}
 
 	// record crud API event hooks
 	app.onRecordsListRequest = &hook.Hook[*RecordsListRequestEvent]{}
@@ -447,6 +459,7 @@ type closer interface {
 // (closing db connections, stopping cron ticker, etc.).
 func (app *BaseApp) ResetBootstrapState() error {
 	app.Cron().Stop()
+	app.Jobs().Stop()
 
 	var errs []error
 
@@ -612,6 +625,11 @@ func (app *BaseApp) SubscriptionsBroker() *subscriptions.Broker {
 	return app.subscriptionsBroker
 }
 
+// Jobs returns the app persistent background jobs queue.
+func (app *BaseApp) Jobs() *Jobs {
+	return app.jobs
+}
+
 // NewMailClient creates and returns a new SMTP or Sendmail client
 // based on the current app settings.
 func (app *BaseApp) NewMailClient() mailer.Mailer {
@@ -1029,6 +1047,14 @@ func (app *BaseApp) OnRealtimeSubscribeRequest() *hook.Hook[*RealtimeSubscribeRe
 	return app.onRealtimeSubscribeRequest
 }
 
+func (app *BaseApp) OnRealtimePublishRequest() *hook.Hook[*RealtimePublishRequestEvent] {
+	return app.onRealtimePublishRequest
+}
+
+func (app *BaseApp) OnRealtimePresenceChange() *hook.Hook[*RealtimePresenceEvent] {
+	return app.onRealtimePresenceChange
+}
+
 // -------------------------------------------------------------------
 // Settings API event hooks
 // -------------------------------------------------------------------
@@ -1109,6 +1135,18 @@ func (app *BaseApp) OnRecordAuthWithOTPRequest(tags ...string) *hook.TaggedHook[
 	return hook.NewTaggedHook(app.onRecordAuthWithOTPRequest, tags...)
 }
 
+func (app *BaseApp) OnRecordAuthWithTOTPRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithTOTPRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithTOTPRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthWithWebAuthnRequest(tags ...string) *hook.TaggedHook[*RecordAuthWithWebAuthnRequestEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthWithWebAuthnRequest, tags...)
+}
+
+func (app *BaseApp) OnRecordAuthLockout(tags ...string) *hook.TaggedHook[*RecordAuthLockoutEvent] {
+	return hook.NewTaggedHook(app.onRecordAuthLockout, tags...)
+}
+
 // -------------------------------------------------------------------
 // Record CRUD API event hooks
 // -------------------------------------------------------------------
@@ -1375,10 +1413,21 @@ func (app *BaseApp) registerBaseHooks() {
 	app.registerAutobackupHooks()
 	app.registerCollectionHooks()
 	app.registerRecordHooks()
+	app.registerRollupHooks()
 	app.registerSuperuserHooks()
 	app.registerExternalAuthHooks()
 	app.registerMFAHooks()
 	app.registerOTPHooks()
+	app.registerTOTPHooks()
+	app.registerWebAuthnCredentialHooks()
+	app.registerAPIKeyHooks()
+	app.registerAuthSessionHooks()
+	app.registerRoleAssignmentHooks()
+	app.registerWebhookHooks()
+	app.registerJobsHooks()
+	app.registerCronRunsHooks()
+	app.registerAuthLockoutHooks()
+	app.registerPasswordChangeHooks()
 	app.registerAuthOriginHooks()
 }
 
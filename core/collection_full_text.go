@@ -0,0 +1,226 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// FullTextTableName returns the name of the FTS5 shadow table that
+// holds the full-text search index of the specified collection.
+//
+// The table name is based on the collection id so that it remains
+// the same when the collection is renamed.
+func FullTextTableName(collection *Collection) string {
+	return "_fts_" + collection.Id
+}
+
+// fullTextFields returns the collection fields that are configured
+// to be part of the collection full-text search index.
+func fullTextFields(collection *Collection) []Field {
+	if collection == nil || collection.IsView() {
+		return nil
+	}
+
+	var result []Field
+
+	for _, f := range collection.Fields {
+		if ft, ok := f.(FullTextIndexer); ok && ft.IsFullText() {
+			result = append(result, f)
+		}
+	}
+
+	return result
+}
+
+// fullTextFieldIds returns the ids of the collection full-text indexed fields.
+//
+// Note that the FTS5 table columns are named after the field ids
+// so that renaming a field doesn't require rebuilding the index.
+func fullTextFieldIds(collection *Collection) []string {
+	fields := fullTextFields(collection)
+
+	ids := make([]string, len(fields))
+	for i, f := range fields {
+		ids[i] = f.GetId()
+	}
+
+	return ids
+}
+
+// syncFullTextIndex creates, rebuilds or deletes the FTS5 shadow
+// table of newCollection based on its full-text indexed fields
+// and (re)creates the related record table triggers.
+//
+// oldCollection could be nil in case of a newly created collection.
+//
+// NB! The old record table triggers are expected to be already
+// dropped (see dropFullTextTriggers) because otherwise they could
+// prevent some of the record table columns changes.
+func syncFullTextIndex(app App, newCollection *Collection, oldCollection *Collection) error {
+	oldIds := fullTextFieldIds(oldCollection)
+	newIds := fullTextFieldIds(newCollection)
+
+	if !slices.Equal(oldIds, newIds) {
+		if err := app.DeleteTable(FullTextTableName(newCollection)); err != nil {
+			return err
+		}
+
+		if len(newIds) > 0 {
+			cols := make([]string, len(newIds))
+			for i, id := range newIds {
+				cols[i] = "[[" + id + "]]"
+			}
+
+			// note: use a contentless table since the indexed values are already stored in the records table
+			_, err := app.DB().NewQuery(fmt.Sprintf(
+				"CREATE VIRTUAL TABLE {{%s}} USING fts5(%s, content='', contentless_delete=1)",
+				FullTextTableName(newCollection),
+				strings.Join(cols, ", "),
+			)).Execute()
+			if err != nil {
+				return fmt.Errorf("failed to create the full-text index table: %w", err)
+			}
+
+			if err := populateFullTextIndex(app, newCollection); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(newIds) == 0 {
+		return nil
+	}
+
+	return createFullTextTriggers(app, newCollection)
+}
+
+// populateFullTextIndex inserts all existing collection records into its full-text index.
+func populateFullTextIndex(app App, collection *Collection) error {
+	fields := fullTextFields(collection)
+
+	cols := make([]string, len(fields))
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = "[[" + f.GetId() + "]]"
+		values[i] = "[[" + f.GetName() + "]]"
+	}
+
+	_, err := app.DB().NewQuery(fmt.Sprintf(
+		"INSERT INTO {{%s}} ([[rowid]], %s) SELECT [[_rowid_]], %s FROM {{%s}}",
+		FullTextTableName(collection),
+		strings.Join(cols, ", "),
+		strings.Join(values, ", "),
+		collection.Name,
+	)).Execute()
+	if err != nil {
+		return fmt.Errorf("failed to populate the full-text index: %w", err)
+	}
+
+	return nil
+}
+
+// rebuildFullTextIndex clears and repopulates the collection full-text index.
+func rebuildFullTextIndex(app App, collection *Collection) error {
+	if len(fullTextFields(collection)) == 0 {
+		return nil
+	}
+
+	return app.RunInTransaction(func(txApp App) error {
+		tableName := FullTextTableName(collection)
+
+		_, err := txApp.DB().NewQuery(fmt.Sprintf(
+			"INSERT INTO {{%s}} ([[%s]]) VALUES ('delete-all')",
+			tableName,
+			tableName,
+		)).Execute()
+		if err != nil {
+			return err
+		}
+
+		return populateFullTextIndex(txApp, collection)
+	})
+}
+
+// createFullTextTriggers creates the record table triggers that
+// keep the collection full-text index in sync with the records.
+//
+// The records are mapped to the index entries by their table rowid.
+func createFullTextTriggers(app App, collection *Collection) error {
+	fields := fullTextFields(collection)
+
+	tableName := FullTextTableName(collection)
+
+	cols := make([]string, len(fields))
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = "[[" + f.GetId() + "]]"
+		values[i] = "[[new." + f.GetName() + "]]"
+	}
+
+	insertStmt := fmt.Sprintf(
+		"INSERT INTO {{%s}} ([[rowid]], %s) VALUES ([[new._rowid_]], %s);",
+		tableName,
+		strings.Join(cols, ", "),
+		strings.Join(values, ", "),
+	)
+
+	deleteStmt := fmt.Sprintf(
+		"DELETE FROM {{%s}} WHERE [[rowid]] = [[old._rowid_]];",
+		tableName,
+	)
+
+	triggers := []string{
+		fmt.Sprintf(
+			"CREATE TRIGGER {{%s_ai}} AFTER INSERT ON {{%s}} BEGIN %s END",
+			tableName, collection.Name, insertStmt,
+		),
+		fmt.Sprintf(
+			"CREATE TRIGGER {{%s_au}} AFTER UPDATE ON {{%s}} BEGIN %s %s END",
+			tableName, collection.Name, deleteStmt, insertStmt,
+		),
+		fmt.Sprintf(
+			"CREATE TRIGGER {{%s_ad}} AFTER DELETE ON {{%s}} BEGIN %s END",
+			tableName, collection.Name, deleteStmt,
+		),
+	}
+
+	for _, trigger := range triggers {
+		if _, err := app.DB().NewQuery(trigger).Execute(); err != nil {
+			return fmt.Errorf("failed to create the full-text index trigger: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// dropFullTextTriggers drops the collection full-text index triggers (if any).
+func dropFullTextTriggers(app App, collection *Collection) error {
+	tableName := FullTextTableName(collection)
+
+	for _, suffix := range []string{"_ai", "_au", "_ad"} {
+		_, err := app.DB().NewQuery("DROP TRIGGER IF EXISTS {{" + tableName + suffix + "}}").Execute()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rebuildAllFullTextIndexes rebuilds the full-text index of all
+// collections with at least one full-text indexed field.
+func rebuildAllFullTextIndexes(app App) error {
+	collections, err := app.FindAllCollections(CollectionTypeBase, CollectionTypeAuth)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range collections {
+		if err := rebuildFullTextIndex(app, c); err != nil {
+			return fmt.Errorf("[%s] failed to rebuild the full-text index: %w", c.Name, err)
+		}
+	}
+
+	return nil
+}
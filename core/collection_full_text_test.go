@@ -0,0 +1,366 @@
+package core_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+func TestFullTextTableName(t *testing.T) {
+	t.Parallel()
+
+	collection := core.NewBaseCollection("test")
+	collection.Id = "abc"
+
+	if name := core.FullTextTableName(collection); name != "_fts_abc" {
+		t.Fatalf("Expected _fts_abc, got %q", name)
+	}
+}
+
+func TestFullTextIndexSync(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("fts_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "title", FullText: true},
+		&core.EditorField{Name: "content", FullText: true},
+		&core.TextField{Name: "note"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	ftsTable := core.FullTextTableName(collection)
+
+	if !app.HasTable(ftsTable) {
+		t.Fatalf("Expected table %q to be created", ftsTable)
+	}
+
+	createRecord := func(title, content, note string) *core.Record {
+		record := core.NewRecord(collection)
+		record.Set("title", title)
+		record.Set("content", content)
+		record.Set("note", note)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		return record
+	}
+
+	r1 := createRecord("hello world", "lorem ipsum", "abc")
+	r2 := createRecord("test", "hello again", "hello")
+	createRecord("other", "dolor sit", "hello")
+
+	assertMatch := func(t *testing.T, collectionName string, filter string, expectedIds ...string) {
+		records, err := app.FindRecordsByFilter(collectionName, filter, "", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids := make([]string, len(records))
+		for i, r := range records {
+			ids[i] = r.Id
+		}
+
+		slices.Sort(ids)
+		slices.Sort(expectedIds)
+
+		if !slices.Equal(ids, expectedIds) {
+			t.Fatalf("Expected ids %v, got %v", expectedIds, ids)
+		}
+	}
+
+	t.Run("initial records", func(t *testing.T) {
+		assertMatch(t, collection.Name, "match('hello') = true", r1.Id, r2.Id)
+		assertMatch(t, collection.Name, "match(title, 'hello') = true", r1.Id)
+		assertMatch(t, collection.Name, "match('hel*') = true && note = 'hello'", r2.Id)
+		assertMatch(t, collection.Name, "match('missing') = true")
+	})
+
+	t.Run("record update", func(t *testing.T) {
+		r1.Set("title", "updated")
+		if err := app.Save(r1); err != nil {
+			t.Fatal(err)
+		}
+
+		assertMatch(t, collection.Name, "match('hello') = true", r2.Id)
+		assertMatch(t, collection.Name, "match('updated') = true", r1.Id)
+	})
+
+	t.Run("record delete", func(t *testing.T) {
+		if err := app.Delete(r2); err != nil {
+			t.Fatal(err)
+		}
+
+		assertMatch(t, collection.Name, "match('hello') = true")
+	})
+
+	t.Run("collection and field rename", func(t *testing.T) {
+		collection.Name = "fts_test_renamed"
+		collection.Fields.GetByName("title").SetName("title_renamed")
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		record := core.NewRecord(collection)
+		record.Set("title_renamed", "new hello")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		assertMatch(t, collection.Name, "match(title_renamed, 'updated') = true", r1.Id)
+		assertMatch(t, collection.Name, "match('hello') = true", record.Id)
+	})
+
+	t.Run("new indexed field", func(t *testing.T) {
+		collection.Fields.Add(&core.TextField{Name: "note", FullText: true})
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		// existing records should be reindexed
+		records, err := app.FindRecordsByFilter(collection, "match(note, 'abc') = true", "", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Id != r1.Id {
+			t.Fatalf("Expected only %q to match, got %v", r1.Id, records)
+		}
+	})
+
+	t.Run("vacuum", func(t *testing.T) {
+		if err := app.Vacuum(); err != nil {
+			t.Fatal(err)
+		}
+
+		assertMatch(t, collection.Name, "match('updated') = true", r1.Id)
+	})
+
+	t.Run("disabled full-text", func(t *testing.T) {
+		for _, f := range collection.Fields {
+			switch v := f.(type) {
+			case *core.TextField:
+				v.FullText = false
+			case *core.EditorField:
+				v.FullText = false
+			}
+		}
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		if app.HasTable(ftsTable) {
+			t.Fatalf("Expected table %q to be deleted", ftsTable)
+		}
+
+		_, err := app.FindRecordsByFilter(collection, "match('hello') = true", "", 0, 0)
+		if err == nil {
+			t.Fatal("Expected match error for collection without indexed fields")
+		}
+
+		// ensure that the records table is still writable (aka. no leftover triggers)
+		createRecord("another", "", "")
+	})
+
+	t.Run("collection delete", func(t *testing.T) {
+		collection.Fields.GetByName("title_renamed").(*core.TextField).FullText = true
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		if !app.HasTable(ftsTable) {
+			t.Fatalf("Expected table %q to be recreated", ftsTable)
+		}
+
+		if err := app.Delete(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		if app.HasTable(ftsTable) {
+			t.Fatalf("Expected table %q to be deleted", ftsTable)
+		}
+	})
+}
+
+func TestFullTextMatchAndRank(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("fts_test")
+	collection.Fields.Add(
+		&core.TextField{Name: "title", FullText: true},
+		&core.TextField{Name: "secret", FullText: true, Hidden: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, data := range [][2]string{
+		{"a", "apple apple apple"},
+		{"apple", "b"},
+		{"apple banana", "apple"},
+	} {
+		record := core.NewRecord(collection)
+		record.Set("title", data[0])
+		record.Set("secret", data[1])
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scenarios := []struct {
+		name          string
+		allowHidden   bool
+		filter        string
+		sort          string
+		expectError   bool
+		expectedTitle []string
+	}{
+		{
+			"rank without match",
+			true,
+			"",
+			"@rank",
+			true,
+			nil,
+		},
+		{
+			"match all fields with hidden fields access",
+			true,
+			"match('apple') = true",
+			"@rank",
+			false,
+			[]string{"a", "apple banana", "apple"},
+		},
+		{
+			"match all fields without hidden fields access",
+			false,
+			"match('apple') = true",
+			"@rank",
+			false,
+			[]string{"apple", "apple banana"},
+		},
+		{
+			"column filter escape attempt without hidden fields access",
+			false,
+			"match('x) OR apple OR (y') = true",
+			"",
+			true,
+			nil,
+		},
+		{
+			"hidden column filter without hidden fields access",
+			false,
+			"match('x OR " + collection.Fields.GetByName("secret").GetId() + ":apple') = true",
+			"",
+			false,
+			nil,
+		},
+		{
+			"match hidden field without hidden fields access",
+			false,
+			"match(secret, 'apple') = true",
+			"",
+			true,
+			nil,
+		},
+		{
+			"match hidden field with hidden fields access",
+			true,
+			"match(secret, 'apple') = true",
+			"-@rank",
+			false,
+			[]string{"apple banana", "a"},
+		},
+		{
+			"non-indexed field",
+			true,
+			"match(id, 'apple') = true",
+			"",
+			true,
+			nil,
+		},
+		{
+			"null query",
+			true,
+			"match(@request.query.q) = true",
+			"",
+			false,
+			nil,
+		},
+		{
+			"empty query",
+			true,
+			"match('') = true",
+			"",
+			false,
+			nil,
+		},
+		{
+			"negated match",
+			true,
+			"match(title, 'apple') != true",
+			"",
+			false,
+			[]string{"a"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			resolver := core.NewRecordFieldResolver(app, collection, nil, s.allowHidden)
+
+			query := app.RecordQuery(collection)
+
+			var titles []string
+			err := func() error {
+				if s.filter != "" {
+					expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+					if err != nil {
+						return err
+					}
+					query.AndWhere(expr)
+				}
+
+				for _, sortField := range search.ParseSortFromString(s.sort) {
+					if sortField.Name == "" {
+						continue
+					}
+					expr, err := sortField.BuildExpr(resolver)
+					if err != nil {
+						return err
+					}
+					query.AndOrderBy(expr)
+				}
+
+				if err := resolver.UpdateQuery(query); err != nil {
+					return err
+				}
+
+				return query.Select("{{fts_test}}.[[title]]").Column(&titles)
+			}()
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if strings.Join(titles, ",") != strings.Join(s.expectedTitle, ",") {
+				t.Fatalf("Expected titles %v, got %v", s.expectedTitle, titles)
+			}
+		})
+	}
+}
@@ -702,6 +702,19 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 			}
 			return fmt.Errorf("[%s] failed to delete due to existing relation references: %s", e.Collection.Name, strings.Join(names, ", "))
 		}
+
+		// ensure that the collection is not used as rollup back-relation
+		rollupReferences, err := findRollupReferences(e.App, e.Collection, e.Collection.Id)
+		if err != nil {
+			return fmt.Errorf("[%s] failed to check collection rollup references: %w", e.Collection.Name, err)
+		}
+		if total := len(rollupReferences); total > 0 {
+			names := make([]string, 0, len(rollupReferences))
+			for ref := range rollupReferences {
+				names = append(names, ref.Name)
+			}
+			return fmt.Errorf("[%s] failed to delete due to existing rollup references: %s", e.Collection.Name, strings.Join(names, ", "))
+		}
 	}
 
 	originalApp := e.App
@@ -718,6 +731,11 @@ func onCollectionDeleteExecute(e *CollectionEvent) error {
 			if err := txApp.DeleteTable(e.Collection.Name); err != nil {
 				return err
 			}
+
+			// delete the related full-text index table (if any)
+			if err := txApp.DeleteTable(FullTextTableName(e.Collection)); err != nil {
+				return err
+			}
 		}
 
 		if !e.Collection.disableIntegrityChecks {
@@ -9,6 +9,7 @@ import (
 	"github.com/go-ozzo/ozzo-validation/v4/is"
 	"github.com/pocketbase/pocketbase/tools/auth"
 	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/saml"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
@@ -19,27 +20,32 @@ func (m *Collection) unsetMissingOAuth2MappedFields() {
 		return
 	}
 
-	if m.OAuth2.MappedFields.Id != "" {
-		if m.Fields.GetByName(m.OAuth2.MappedFields.Id) == nil {
-			m.OAuth2.MappedFields.Id = ""
+	m.unsetMissingKnownFields(&m.OAuth2.MappedFields)
+	m.unsetMissingKnownFields(&m.SAML.MappedFields)
+}
+
+func (m *Collection) unsetMissingKnownFields(knownFields *OAuth2KnownFields) {
+	if knownFields.Id != "" {
+		if m.Fields.GetByName(knownFields.Id) == nil {
+			knownFields.Id = ""
 		}
 	}
 
-	if m.OAuth2.MappedFields.Name != "" {
-		if m.Fields.GetByName(m.OAuth2.MappedFields.Name) == nil {
-			m.OAuth2.MappedFields.Name = ""
+	if knownFields.Name != "" {
+		if m.Fields.GetByName(knownFields.Name) == nil {
+			knownFields.Name = ""
 		}
 	}
 
-	if m.OAuth2.MappedFields.Username != "" {
-		if m.Fields.GetByName(m.OAuth2.MappedFields.Username) == nil {
-			m.OAuth2.MappedFields.Username = ""
+	if knownFields.Username != "" {
+		if m.Fields.GetByName(knownFields.Username) == nil {
+			knownFields.Username = ""
 		}
 	}
 
-	if m.OAuth2.MappedFields.AvatarURL != "" {
-		if m.Fields.GetByName(m.OAuth2.MappedFields.AvatarURL) == nil {
-			m.OAuth2.MappedFields.AvatarURL = ""
+	if knownFields.AvatarURL != "" {
+		if m.Fields.GetByName(knownFields.AvatarURL) == nil {
+			knownFields.AvatarURL = ""
 		}
 	}
 }
@@ -54,9 +60,14 @@ func (m *Collection) setDefaultAuthOptions() {
 			Enabled:       true,
 			EmailTemplate: defaultAuthAlertTemplate,
 		},
+		SAML: SAMLConfig{
+			Enabled:  false,
+			Duration: 300, // 5min
+		},
 		PasswordAuth: PasswordAuthConfig{
 			Enabled:        true,
 			IdentityFields: []string{FieldNameEmail},
+			Lockout:        defaultAuthLockout,
 		},
 		MFA: MFAConfig{
 			Enabled:  false,
@@ -67,6 +78,21 @@ func (m *Collection) setDefaultAuthOptions() {
 			Duration:      180, // 3min
 			Length:        8,
 			EmailTemplate: defaultOTPTemplate,
+			Lockout:       defaultAuthLockout,
+		},
+		TOTP: TOTPConfig{
+			Enabled: false,
+		},
+		WebAuthn: WebAuthnConfig{
+			Enabled:  false,
+			Duration: 180, // 3min
+		},
+		APIKeys: APIKeysConfig{
+			Enabled: false,
+		},
+		RefreshToken: RefreshTokenConfig{
+			Enabled:  false,
+			Duration: 2592000, // 30 days
 		},
 		AuthToken: TokenConfig{
 			Secret:   security.RandomString(50),
@@ -122,6 +148,10 @@ type collectionAuthOptions struct {
 	// and which OAuth2 providers are allowed.
 	OAuth2 OAuth2Config `form:"oauth2" json:"oauth2"`
 
+	// SAML defines options related to the SAML 2.0 single sign-on
+	// (the collection acts as a service provider for a single IdP).
+	SAML SAMLConfig `form:"saml" json:"saml"`
+
 	// PasswordAuth defines options related to the collection password authentication.
 	PasswordAuth PasswordAuthConfig `form:"passwordAuth" json:"passwordAuth"`
 
@@ -131,6 +161,19 @@ type collectionAuthOptions struct {
 	// OTP defines options related to the One-time password authentication (OTP).
 	OTP OTPConfig `form:"otp" json:"otp"`
 
+	// TOTP defines options related to the authenticator app (TOTP) MFA method.
+	TOTP TOTPConfig `form:"totp" json:"totp"`
+
+	// WebAuthn defines options related to the passkeys (WebAuthn) authentication.
+	WebAuthn WebAuthnConfig `form:"webauthn" json:"webauthn"`
+
+	// APIKeys defines options related to the long-lived auth record API keys.
+	APIKeys APIKeysConfig `form:"apiKeys" json:"apiKeys"`
+
+	// RefreshToken defines options related to the server-side auth
+	// sessions with rotating refresh tokens.
+	RefreshToken RefreshTokenConfig `form:"refreshToken" json:"refreshToken"`
+
 	// Various token configurations
 	// ---
 	AuthToken          TokenConfig `form:"authToken" json:"authToken"`
@@ -162,7 +205,12 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		validation.Field(&o.AuthAlert),
 		validation.Field(&o.PasswordAuth),
 		validation.Field(&o.OAuth2),
+		validation.Field(&o.SAML),
 		validation.Field(&o.OTP),
+		validation.Field(&o.TOTP),
+		validation.Field(&o.WebAuthn),
+		validation.Field(&o.APIKeys),
+		validation.Field(&o.RefreshToken),
 		validation.Field(&o.MFA),
 		validation.Field(&o.AuthToken),
 		validation.Field(&o.PasswordResetToken),
@@ -189,9 +237,18 @@ func (o *collectionAuthOptions) validate(cv *collectionValidator) error {
 		if o.OAuth2.Enabled {
 			authsEnabled++
 		}
+		if o.SAML.Enabled {
+			authsEnabled++
+		}
 		if o.OTP.Enabled {
 			authsEnabled++
 		}
+		if o.TOTP.Enabled {
+			authsEnabled++
+		}
+		if o.WebAuthn.Enabled {
+			authsEnabled++
+		}
 		if authsEnabled < 2 {
 			return validation.Errors{
 				"mfa": validation.Errors{
@@ -322,6 +379,9 @@ type OTPConfig struct {
 	// In addition to the system placeholders you can also make use of
 	// [core.EmailPlaceholderOTPId] and [core.EmailPlaceholderOTP].
 	EmailTemplate EmailTemplate `form:"emailTemplate" json:"emailTemplate"`
+
+	// Lockout specifies the failed OTP attempts lockout options.
+	Lockout AuthLockoutConfig `form:"lockout" json:"lockout"`
 }
 
 // Validate makes OTPConfig validatable by implementing [validation.Validatable] interface.
@@ -332,6 +392,7 @@ func (c OTPConfig) Validate() error {
 		// note: for now always run the email template validations even
 		// if not enabled since it could be used separately
 		validation.Field(&c.EmailTemplate),
+		validation.Field(&c.Lockout),
 	)
 }
 
@@ -342,6 +403,172 @@ func (c OTPConfig) DurationTime() time.Duration {
 
 // -------------------------------------------------------------------
 
+var defaultAuthLockout = AuthLockoutConfig{
+	Enabled:     false,
+	MaxAttempts: 5,
+	Duration:    60,   // 1min
+	MaxDuration: 3600, // 1h
+}
+
+// AuthLockoutConfig defines the per auth record failed attempts lockout options.
+type AuthLockoutConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxAttempts specifies the allowed consecutive failed attempts
+	// before temporary locking the auth record.
+	MaxAttempts int `form:"maxAttempts" json:"maxAttempts"`
+
+	// Duration specifies the initial lockout duration (in seconds).
+	//
+	// Each additional failed attempt after the lockout doubles
+	// the previous duration (up to MaxDuration).
+	Duration int64 `form:"duration" json:"duration"`
+
+	// MaxDuration specifies the max lockout duration (in seconds).
+	//
+	// It is also used as window after which the failed attempts counter is reset.
+	MaxDuration int64 `form:"maxDuration" json:"maxDuration"`
+}
+
+// Validate makes AuthLockoutConfig validatable by implementing [validation.Validatable] interface.
+func (c AuthLockoutConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxAttempts, validation.When(c.Enabled, validation.Required, validation.Min(1), validation.Max(1000))),
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required, validation.Min(1), validation.Max(86400))),
+		validation.Field(&c.MaxDuration, validation.When(c.Enabled, validation.Required, validation.Min(1), validation.Min(c.Duration), validation.Max(31536000))),
+	)
+}
+
+// MaxDurationTime returns the current MaxDuration as [time.Duration].
+func (c AuthLockoutConfig) MaxDurationTime() time.Duration {
+	return time.Duration(c.MaxDuration) * time.Second
+}
+
+// LockDuration returns the lockout duration for the specified number
+// of consecutive failed attempts (0 if no lockout should be applied).
+func (c AuthLockoutConfig) LockDuration(failures int) time.Duration {
+	if !c.Enabled || c.MaxAttempts <= 0 || failures < c.MaxAttempts {
+		return 0
+	}
+
+	d := c.Duration
+	for i := c.MaxAttempts; i < failures && d < c.MaxDuration; i++ {
+		d *= 2
+	}
+
+	if d > c.MaxDuration {
+		d = c.MaxDuration
+	}
+
+	return time.Duration(d) * time.Second
+}
+
+// -------------------------------------------------------------------
+
+type TOTPConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Issuer is the optional issuer name shown in the authenticator apps.
+	//
+	// If empty, fallbacks to the application name.
+	Issuer string `form:"issuer" json:"issuer"`
+}
+
+// Validate makes TOTPConfig validatable by implementing [validation.Validatable] interface.
+func (c TOTPConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Issuer, validation.Length(0, 100)),
+	)
+}
+
+// -------------------------------------------------------------------
+
+type WebAuthnConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Duration specifies how long the issued registration and
+	// authentication challenges to be valid (in seconds).
+	Duration int64 `form:"duration" json:"duration"`
+
+	// RPId is the optional relying party identifier (aka. the domain
+	// to which the passkeys are scoped, ex. "example.com").
+	//
+	// If empty, fallbacks to the host of the application URL.
+	RPId string `form:"rpId" json:"rpId"`
+
+	// RPName is the optional relying party name shown by the authenticators.
+	//
+	// If empty, fallbacks to the application name.
+	RPName string `form:"rpName" json:"rpName"`
+
+	// RequireUserVerification requires the authenticators to verify the
+	// user identity (ex. with PIN or biometrics) and not only their presence.
+	RequireUserVerification bool `form:"requireUserVerification" json:"requireUserVerification"`
+}
+
+// Validate makes WebAuthnConfig validatable by implementing [validation.Validatable] interface.
+func (c WebAuthnConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required, validation.Min(10), validation.Max(86400))),
+		validation.Field(&c.RPId, validation.Length(0, 255), is.Host),
+		validation.Field(&c.RPName, validation.Length(0, 100)),
+	)
+}
+
+// DurationTime returns the current Duration as [time.Duration].
+func (c WebAuthnConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// -------------------------------------------------------------------
+
+type APIKeysConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// MaxDuration specifies the max allowed lifetime of a new API key (in seconds).
+	//
+	// Set it to 0 to allow creating API keys without expiration.
+	MaxDuration int64 `form:"maxDuration" json:"maxDuration"`
+}
+
+// Validate makes APIKeysConfig validatable by implementing [validation.Validatable] interface.
+func (c APIKeysConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.MaxDuration, validation.Min(0), validation.Max(315360000)), // 10 years
+	)
+}
+
+// MaxDurationTime returns the current MaxDuration as [time.Duration].
+func (c APIKeysConfig) MaxDurationTime() time.Duration {
+	return time.Duration(c.MaxDuration) * time.Second
+}
+
+// -------------------------------------------------------------------
+
+type RefreshTokenConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// Duration specifies how long an auth session could remain unused
+	// before its refresh token expires (in seconds).
+	//
+	// Each refresh token exchange extends the session with the same duration.
+	Duration int64 `form:"duration" json:"duration"`
+}
+
+// Validate makes RefreshTokenConfig validatable by implementing [validation.Validatable] interface.
+func (c RefreshTokenConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Duration, validation.When(c.Enabled, validation.Required, validation.Min(60), validation.Max(315360000))),
+	)
+}
+
+// DurationTime returns the current Duration as [time.Duration].
+func (c RefreshTokenConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// -------------------------------------------------------------------
+
 type MFAConfig struct {
 	Enabled bool `form:"enabled" json:"enabled"`
 
@@ -376,6 +603,9 @@ type PasswordAuthConfig struct {
 	//
 	// Usually only fields that has single column UNIQUE index are accepted as values.
 	IdentityFields []string `form:"identityFields" json:"identityFields"`
+
+	// Lockout specifies the failed password attempts lockout options.
+	Lockout AuthLockoutConfig `form:"lockout" json:"lockout"`
 }
 
 // Validate makes PasswordAuthConfig validatable by implementing [validation.Validatable] interface.
@@ -389,6 +619,7 @@ func (c PasswordAuthConfig) Validate() error {
 
 	return validation.ValidateStruct(&c,
 		validation.Field(&c.IdentityFields, validation.Required),
+		validation.Field(&c.Lockout),
 	)
 }
 
@@ -501,6 +732,9 @@ func checkProviderName(value any) error {
 }
 
 // InitProvider returns a new auth.Provider instance loaded with the current OAuth2ProviderConfig options.
+//
+// Note that the OIDC issuer discovery (if configured) is not performed here
+// because it may require a network request (see [auth.OIDC.Discover]).
 func (c OAuth2ProviderConfig) InitProvider() (auth.Provider, error) {
 	provider, err := auth.NewProviderByName(c.Name)
 	if err != nil {
@@ -541,3 +775,159 @@ func (c OAuth2ProviderConfig) InitProvider() (auth.Provider, error) {
 
 	return provider, nil
 }
+
+// -------------------------------------------------------------------
+
+// SAMLProviderName is the [ExternalAuth] provider name of the SAML linked accounts.
+const SAMLProviderName = "saml"
+
+// SAMLKnownAttributes defines the names of the SAML assertion
+// attributes that hold the common external user data.
+type SAMLKnownAttributes struct {
+	// Id is the attribute with the unique IdP user identifier.
+	//
+	// If empty, fallbacks to the assertion subject NameID.
+	Id string `form:"id" json:"id"`
+
+	// Email is the attribute with the user email address.
+	//
+	// If empty, fallbacks to the assertion subject NameID but only
+	// if it is in the emailAddress format.
+	Email string `form:"email" json:"email"`
+
+	Name      string `form:"name" json:"name"`
+	Username  string `form:"username" json:"username"`
+	AvatarURL string `form:"avatarURL" json:"avatarURL"`
+}
+
+type SAMLConfig struct {
+	Enabled bool `form:"enabled" json:"enabled"`
+
+	// EntityId is the optional service provider entity identifier.
+	//
+	// If empty, fallbacks to the collection SAML metadata url.
+	EntityId string `form:"entityId" json:"entityId"`
+
+	// IdPEntityId is the identity provider entity identifier
+	// (aka. the expected assertions issuer).
+	IdPEntityId string `form:"idpEntityId" json:"idpEntityId"`
+
+	// IdPSSOURL is the identity provider single sign-on service url (HTTP-Redirect binding).
+	IdPSSOURL string `form:"idpSSOURL" json:"idpSSOURL"`
+
+	// IdPCertificate is the PEM or base64 DER encoded identity provider signing certificate.
+	IdPCertificate string `form:"idpCertificate" json:"idpCertificate"`
+
+	// RedirectURL is the frontend url where the user will be redirected
+	// after the identity provider response is processed.
+	//
+	// On success a "samlCode" query parameter is appended to the url
+	// (to be exchanged with the auth-with-saml endpoint), otherwise - an "error" one.
+	RedirectURL string `form:"redirectURL" json:"redirectURL"`
+
+	// Duration specifies how long the issued SAML requests and
+	// the SP login codes to be valid (in seconds).
+	Duration int64 `form:"duration" json:"duration"`
+
+	// MappedAttributes specifies the SAML assertion attributes
+	// that will be used to populate the external user data.
+	MappedAttributes SAMLKnownAttributes `form:"mappedAttributes" json:"mappedAttributes"`
+
+	// MappedFields specifies the collection fields that will be
+	// populated with the external user data on sign-up.
+	MappedFields OAuth2KnownFields `form:"mappedFields" json:"mappedFields"`
+}
+
+// Validate makes SAMLConfig validatable by implementing [validation.Validatable] interface.
+func (c SAMLConfig) Validate() error {
+	if !c.Enabled {
+		return nil // no need to validate
+	}
+
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.EntityId, validation.Length(0, 1024)),
+		validation.Field(&c.IdPEntityId, validation.Required, validation.Length(0, 1024)),
+		validation.Field(&c.IdPSSOURL, validation.Required, is.URL),
+		validation.Field(&c.IdPCertificate, validation.Required, validation.By(checkSAMLCertificate)),
+		validation.Field(&c.RedirectURL, validation.Required, is.URL),
+		validation.Field(&c.Duration, validation.Required, validation.Min(10), validation.Max(86400)),
+	)
+}
+
+func checkSAMLCertificate(value any) error {
+	v, _ := value.(string)
+	if v == "" {
+		return nil // nothing to check
+	}
+
+	if _, err := saml.ParseCertificate(v); err != nil {
+		return validation.NewError("validation_invalid_certificate", "Invalid or unsupported X.509 certificate.")
+	}
+
+	return nil
+}
+
+// DurationTime returns the current Duration as [time.Duration].
+func (c SAMLConfig) DurationTime() time.Duration {
+	return time.Duration(c.Duration) * time.Second
+}
+
+// InitServiceProvider returns a new saml.ServiceProvider instance loaded with the current SAMLConfig options.
+//
+// defaultEntityId is used only if c.EntityId is not set.
+func (c SAMLConfig) InitServiceProvider(defaultEntityId string, acsURL string) (*saml.ServiceProvider, error) {
+	cert, err := saml.ParseCertificate(c.IdPCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	entityId := c.EntityId
+	if entityId == "" {
+		entityId = defaultEntityId
+	}
+
+	return &saml.ServiceProvider{
+		EntityId:       entityId,
+		ACSURL:         acsURL,
+		IdPEntityId:    c.IdPEntityId,
+		IdPSSOURL:      c.IdPSSOURL,
+		IdPCertificate: cert,
+	}, nil
+}
+
+// AuthUser maps the provided verified SAML assertion to an [auth.AuthUser]
+// based on the configured MappedAttributes.
+func (c SAMLConfig) AuthUser(assertion *saml.Assertion) *auth.AuthUser {
+	user := &auth.AuthUser{
+		Id:        assertion.NameId,
+		Name:      assertion.Attribute(c.MappedAttributes.Name),
+		Username:  assertion.Attribute(c.MappedAttributes.Username),
+		AvatarURL: assertion.Attribute(c.MappedAttributes.AvatarURL),
+		RawUser:   make(map[string]any, len(assertion.Attributes)+1),
+	}
+
+	if c.MappedAttributes.Id != "" {
+		user.Id = assertion.Attribute(c.MappedAttributes.Id)
+	}
+
+	if c.MappedAttributes.Email != "" {
+		user.Email = assertion.Attribute(c.MappedAttributes.Email)
+	} else if assertion.NameIdFormat == saml.NameIdFormatEmail {
+		user.Email = assertion.NameId
+	}
+
+	for name, values := range assertion.Attributes {
+		if len(values) == 1 {
+			user.RawUser[name] = values[0]
+		} else {
+			user.RawUser[name] = values
+		}
+	}
+	user.RawUser["nameId"] = assertion.NameId
+
+	if !assertion.ExpiresAt.IsZero() {
+		user.Expiry, _ = types.ParseDateTime(assertion.ExpiresAt)
+	}
+
+	return user
+}
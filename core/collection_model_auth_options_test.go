@@ -2,8 +2,14 @@ package core_test
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
@@ -11,6 +17,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/auth"
+	"github.com/pocketbase/pocketbase/tools/saml"
 	"github.com/pocketbase/pocketbase/tools/types"
 )
 
@@ -675,6 +682,229 @@ func TestMFAConfigDurationTime(t *testing.T) {
 	}
 }
 
+func TestAPIKeysConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.APIKeysConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.APIKeysConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.APIKeysConfig{Enabled: true},
+			[]string{},
+		},
+		{
+			"invalid max duration (< 0)",
+			core.APIKeysConfig{Enabled: true, MaxDuration: -1},
+			[]string{"maxDuration"},
+		},
+		{
+			"invalid max duration (> 10 years)",
+			core.APIKeysConfig{Enabled: true, MaxDuration: 315360001},
+			[]string{"maxDuration"},
+		},
+		{
+			"valid data",
+			core.APIKeysConfig{Enabled: true, MaxDuration: 315360000},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestAPIKeysConfigMaxDurationTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.APIKeysConfig
+		expected time.Duration
+	}{
+		{core.APIKeysConfig{}, 0 * time.Second},
+		{core.APIKeysConfig{MaxDuration: 1234}, 1234 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.config.MaxDuration), func(t *testing.T) {
+			result := s.config.MaxDurationTime()
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %d, got %d", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestRefreshTokenConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.RefreshTokenConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.RefreshTokenConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.RefreshTokenConfig{Enabled: true},
+			[]string{"duration"},
+		},
+		{
+			"invalid duration (< 60)",
+			core.RefreshTokenConfig{Enabled: true, Duration: 59},
+			[]string{"duration"},
+		},
+		{
+			"invalid duration (> 10 years)",
+			core.RefreshTokenConfig{Enabled: true, Duration: 315360001},
+			[]string{"duration"},
+		},
+		{
+			"valid data",
+			core.RefreshTokenConfig{Enabled: true, Duration: 60},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestRefreshTokenConfigDurationTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.RefreshTokenConfig
+		expected time.Duration
+	}{
+		{core.RefreshTokenConfig{}, 0 * time.Second},
+		{core.RefreshTokenConfig{Duration: 1234}, 1234 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.config.Duration), func(t *testing.T) {
+			result := s.config.DurationTime()
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %d, got %d", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.AuthLockoutConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			core.AuthLockoutConfig{},
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			core.AuthLockoutConfig{Enabled: true},
+			[]string{"maxAttempts", "duration", "maxDuration"},
+		},
+		{
+			"invalid data (lower bounds)",
+			core.AuthLockoutConfig{Enabled: true, MaxAttempts: -1, Duration: -1, MaxDuration: -1},
+			[]string{"maxAttempts", "duration", "maxDuration"},
+		},
+		{
+			"invalid data (upper bounds)",
+			core.AuthLockoutConfig{Enabled: true, MaxAttempts: 1001, Duration: 86401, MaxDuration: 31536001},
+			[]string{"maxAttempts", "duration", "maxDuration"},
+		},
+		{
+			"maxDuration < duration",
+			core.AuthLockoutConfig{Enabled: true, MaxAttempts: 5, Duration: 60, MaxDuration: 59},
+			[]string{"maxDuration"},
+		},
+		{
+			"valid data",
+			core.AuthLockoutConfig{Enabled: true, MaxAttempts: 5, Duration: 60, MaxDuration: 60},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestAuthLockoutConfigMaxDurationTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.AuthLockoutConfig
+		expected time.Duration
+	}{
+		{core.AuthLockoutConfig{}, 0 * time.Second},
+		{core.AuthLockoutConfig{MaxDuration: 1234}, 1234 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.config.MaxDuration), func(t *testing.T) {
+			result := s.config.MaxDurationTime()
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %d, got %d", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestAuthLockoutConfigLockDuration(t *testing.T) {
+	enabled := core.AuthLockoutConfig{Enabled: true, MaxAttempts: 3, Duration: 60, MaxDuration: 300}
+
+	disabled := enabled
+	disabled.Enabled = false
+
+	scenarios := []struct {
+		config   core.AuthLockoutConfig
+		failures int
+		expected time.Duration
+	}{
+		{disabled, 10, 0},
+		{enabled, 0, 0},
+		{enabled, 2, 0},
+		{enabled, 3, 60 * time.Second},
+		{enabled, 4, 120 * time.Second},
+		{enabled, 5, 240 * time.Second},
+		{enabled, 6, 300 * time.Second},
+		{enabled, 1000, 300 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%v_%d", i, s.config.Enabled, s.failures), func(t *testing.T) {
+			result := s.config.LockDuration(s.failures)
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
 func TestPasswordAuthConfigValidate(t *testing.T) {
 	scenarios := []struct {
 		name           string
@@ -1024,3 +1254,217 @@ func TestOAuth2ProviderConfigInitProvider(t *testing.T) {
 		})
 	}
 }
+
+func TestSAMLConfigValidate(t *testing.T) {
+	certPEM := newTestSAMLCertificatePEM(t)
+
+	validConfig := func() core.SAMLConfig {
+		return core.SAMLConfig{
+			Enabled:        true,
+			IdPEntityId:    "https://idp.example.com",
+			IdPSSOURL:      "https://idp.example.com/sso",
+			IdPCertificate: certPEM,
+			RedirectURL:    "https://example.com/saml",
+			Duration:       300,
+		}
+	}
+
+	scenarios := []struct {
+		name           string
+		config         func() core.SAMLConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value (disabled)",
+			func() core.SAMLConfig { return core.SAMLConfig{} },
+			[]string{},
+		},
+		{
+			"zero value (enabled)",
+			func() core.SAMLConfig { return core.SAMLConfig{Enabled: true} },
+			[]string{"idpEntityId", "idpSSOURL", "idpCertificate", "redirectURL", "duration"},
+		},
+		{
+			"invalid urls",
+			func() core.SAMLConfig {
+				c := validConfig()
+				c.IdPSSOURL = "invalid"
+				c.RedirectURL = "invalid"
+				return c
+			},
+			[]string{"idpSSOURL", "redirectURL"},
+		},
+		{
+			"invalid certificate",
+			func() core.SAMLConfig {
+				c := validConfig()
+				c.IdPCertificate = "invalid"
+				return c
+			},
+			[]string{"idpCertificate"},
+		},
+		{
+			"invalid duration (< 10)",
+			func() core.SAMLConfig {
+				c := validConfig()
+				c.Duration = 9
+				return c
+			},
+			[]string{"duration"},
+		},
+		{
+			"invalid duration (> 86400)",
+			func() core.SAMLConfig {
+				c := validConfig()
+				c.Duration = 86401
+				return c
+			},
+			[]string{"duration"},
+		},
+		{
+			"valid data",
+			validConfig,
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config().Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestSAMLConfigDurationTime(t *testing.T) {
+	scenarios := []struct {
+		config   core.SAMLConfig
+		expected time.Duration
+	}{
+		{core.SAMLConfig{}, 0 * time.Second},
+		{core.SAMLConfig{Duration: 1234}, 1234 * time.Second},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%d", i, s.config.Duration), func(t *testing.T) {
+			result := s.config.DurationTime()
+
+			if result != s.expected {
+				t.Fatalf("Expected duration %d, got %d", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestSAMLConfigInitServiceProvider(t *testing.T) {
+	config := core.SAMLConfig{
+		IdPEntityId:    "https://idp.example.com",
+		IdPSSOURL:      "https://idp.example.com/sso",
+		IdPCertificate: newTestSAMLCertificatePEM(t),
+	}
+
+	sp, err := config.InitServiceProvider("default_entity", "acs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sp.EntityId != "default_entity" || sp.ACSURL != "acs" || sp.IdPEntityId != config.IdPEntityId || sp.IdPSSOURL != config.IdPSSOURL || sp.IdPCertificate == nil {
+		t.Fatalf("Unexpected service provider %#v", sp)
+	}
+
+	config.EntityId = "custom_entity"
+	sp, err = config.InitServiceProvider("default_entity", "acs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sp.EntityId != "custom_entity" {
+		t.Fatalf("Expected the custom entity id, got %q", sp.EntityId)
+	}
+
+	config.IdPCertificate = "invalid"
+	if _, err := config.InitServiceProvider("default_entity", "acs"); err == nil {
+		t.Fatal("Expected invalid certificate error")
+	}
+}
+
+func TestSAMLConfigAuthUser(t *testing.T) {
+	assertion := &saml.Assertion{
+		NameId:       "test@example.com",
+		NameIdFormat: saml.NameIdFormatEmail,
+		Attributes: map[string][]string{
+			"uid":    {"test_uid"},
+			"mail":   {"mail@example.com"},
+			"cn":     {"Test Name"},
+			"groups": {"a", "b"},
+		},
+	}
+
+	scenarios := []struct {
+		name       string
+		attributes core.SAMLKnownAttributes
+		expected   []string
+	}{
+		{
+			"without mapped attributes",
+			core.SAMLKnownAttributes{},
+			[]string{
+				`"id":"test@example.com"`,
+				`"email":"test@example.com"`,
+				`"name":""`,
+				`"groups":["a","b"]`,
+				`"nameId":"test@example.com"`,
+			},
+		},
+		{
+			"with mapped attributes",
+			core.SAMLKnownAttributes{Id: "uid", Email: "mail", Name: "cn", Username: "missing"},
+			[]string{
+				`"id":"test_uid"`,
+				`"email":"mail@example.com"`,
+				`"name":"Test Name"`,
+				`"username":""`,
+				`"uid":"test_uid"`,
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			config := core.SAMLConfig{MappedAttributes: s.attributes}
+
+			raw, err := json.Marshal(config.AuthUser(assertion))
+			if err != nil {
+				t.Fatal(err)
+			}
+			rawStr := string(raw)
+
+			for _, part := range s.expected {
+				if !strings.Contains(rawStr, part) {
+					t.Fatalf("Missing part %q in\n%v", part, rawStr)
+				}
+			}
+		})
+	}
+}
+
+func newTestSAMLCertificatePEM(t testing.TB) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -43,7 +43,19 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 				return err
 			}
 
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
+
+			if err := syncRollupFields(txApp, newCollection, nil); err != nil {
+				return err
+			}
+
+			if err := syncFormulaFields(txApp, newCollection, nil); err != nil {
+				return err
+			}
+
+			return syncFullTextIndex(txApp, newCollection, nil)
 		}
 
 		// update
@@ -62,6 +74,14 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 			needIndexesUpdate = true
 		}
 
+		// drop the old full-text index triggers (if any) since they
+		// reference the record table columns and could prevent their changes
+		if len(fullTextFields(oldCollection)) > 0 {
+			if err := dropFullTextTriggers(txApp, oldCollection); err != nil {
+				return err
+			}
+		}
+
 		if needIndexesUpdate {
 			// drop old indexes (if any)
 			if err := dropCollectionIndexes(txApp, oldCollection); err != nil {
@@ -133,10 +153,22 @@ func (app *BaseApp) SyncRecordTableSchema(newCollection *Collection, oldCollecti
 		}
 
 		if needIndexesUpdate {
-			return createCollectionIndexes(txApp, newCollection)
+			if err := createCollectionIndexes(txApp, newCollection); err != nil {
+				return err
+			}
 		}
 
-		return nil
+		// note: recomputed before the full-text index sync so
+		// that the new rollup and formula values are also indexed
+		if err := syncRollupFields(txApp, newCollection, oldCollection); err != nil {
+			return err
+		}
+
+		if err := syncFormulaFields(txApp, newCollection, oldCollection); err != nil {
+			return err
+		}
+
+		return syncFullTextIndex(txApp, newCollection, oldCollection)
 	})
 	if txErr != nil {
 		return txErr
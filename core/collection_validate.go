@@ -113,6 +113,7 @@ func (validator *collectionValidator) run() error {
 			validation.By(checkForVia),
 			validation.Match(collectionNameRegex),
 			validation.By(validator.ensureNoSystemNameChange),
+			validation.By(validator.ensureNoRollupRelationNameChange),
 			validation.By(validator.checkUniqueName),
 		),
 		validation.Field(
@@ -123,6 +124,7 @@ func (validator *collectionValidator) run() error {
 				!validator.new.IsView(),
 				validation.By(validator.ensureNoSystemFieldsChange),
 				validation.By(validator.ensureNoFieldsTypeChange),
+				validation.By(validator.ensureNoRollupFieldsChange),
 			),
 			validation.When(validator.new.IsAuth(), validation.By(validator.checkReservedAuthKeys)),
 			validation.By(validator.checkFieldValidators),
@@ -242,6 +244,92 @@ func (validator *collectionValidator) ensureNoFieldsTypeChange(value any) error
 	return nil
 }
 
+// ensureNoRollupRelationNameChange checks whether the renamed collection
+// is used as back-relation in a rollup field.
+func (validator *collectionValidator) ensureNoRollupRelationNameChange(value any) error {
+	v, _ := value.(string)
+
+	if validator.original.IsNew() || strings.EqualFold(v, validator.original.Name) {
+		return nil // no change
+	}
+
+	references, err := findRollupReferences(validator.app, validator.original, validator.original.Id)
+	if err != nil {
+		return err
+	}
+
+	// the current collection rollup fields could be also changed with the rename
+	for _, field := range validator.new.Fields {
+		rollup, ok := field.(*RollupField)
+		if !ok {
+			continue
+		}
+
+		parts := viaRegex.FindStringSubmatch(rollup.Relation)
+		if len(parts) == 3 && strings.EqualFold(parts[1], validator.original.Name) {
+			references[validator.new] = append(references[validator.new], rollup)
+		}
+	}
+
+	for refCollection, rollups := range references {
+		return validation.NewError(
+			"validation_collection_rollup_reference",
+			fmt.Sprintf("The collection is used as back-relation in the rollup field %q of collection %q.", rollups[0].Name, refCollection.Name),
+		)
+	}
+
+	return nil
+}
+
+// ensureNoRollupFieldsChange checks whether the removed or renamed fields
+// are used as back-relation or aggregated field in a rollup field
+// of another collection.
+//
+// The rollup fields of the current collection are validated separately
+// with their ValidateSettings.
+func (validator *collectionValidator) ensureNoRollupFieldsChange(value any) error {
+	v, ok := value.(FieldsList)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	if validator.original.IsNew() {
+		return nil // no rollup references yet
+	}
+
+	references, err := findRollupReferences(validator.app, validator.original, validator.original.Id)
+	if err != nil {
+		return err
+	}
+
+	for refCollection, rollups := range references {
+		for _, rollup := range rollups {
+			parts := viaRegex.FindStringSubmatch(rollup.Relation)
+
+			relField, _ := v.GetByName(parts[2]).(*RelationField)
+			if relField == nil || relField.CollectionId != refCollection.Id {
+				return validation.NewError(
+					"validation_field_rollup_reference",
+					fmt.Sprintf("Field %q is used as back-relation in the rollup field %q of collection %q.", parts[2], rollup.Name, refCollection.Name),
+				)
+			}
+
+			if rollup.Field == "" {
+				continue
+			}
+
+			if _, ok := v.GetByName(rollup.Field).(*NumberField); !ok {
+				return validation.NewError(
+					"validation_field_rollup_reference",
+					fmt.Sprintf("Field %q is aggregated in the rollup field %q of collection %q.", rollup.Field, rollup.Name, refCollection.Name),
+				)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (validator *collectionValidator) checkFieldDuplicates(value any) error {
 	fields, ok := value.(FieldsList)
 	if !ok {
@@ -0,0 +1,26 @@
+package core
+
+import "github.com/pocketbase/pocketbase/tools/types"
+
+var (
+	_ Model = (*CronRun)(nil)
+)
+
+const CronRunsTableName = "_cronRuns"
+
+// CronRun defines a single persisted cron job run stored in the auxiliary database.
+type CronRun struct {
+	BaseModel
+
+	JobId    string         `db:"jobId" json:"jobId"`
+	Error    string         `db:"error" json:"error"`
+	Started  types.DateTime `db:"started" json:"started"`
+	Created  types.DateTime `db:"created" json:"created"`
+	Duration float64        `db:"duration" json:"duration"` // in ms
+	Manual   bool           `db:"manual" json:"manual"`
+	Panicked bool           `db:"panicked" json:"panicked"`
+}
+
+func (m *CronRun) TableName() string {
+	return CronRunsTableName
+}
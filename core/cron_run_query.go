@@ -0,0 +1,75 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// CronRunQuery returns a new CronRun select query.
+func (app *BaseApp) CronRunQuery() *dbx.SelectQuery {
+	return app.AuxModelQuery(&CronRun{})
+}
+
+// FindCronRunById finds a single CronRun entry by its id.
+func (app *BaseApp) FindCronRunById(id string) (*CronRun, error) {
+	model := &CronRun{}
+
+	err := app.CronRunQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// FindLastCronRun finds the most recently started persisted run of the specified cron job.
+func (app *BaseApp) FindLastCronRun(jobId string) (*CronRun, error) {
+	model := &CronRun{}
+
+	err := app.CronRunQuery().
+		AndWhere(dbx.HashExp{"jobId": jobId}).
+		OrderBy("started DESC", "rowid DESC").
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// DeleteOldCronRuns deletes all cron job runs that are created before createdBefore.
+//
+// For better performance the cron runs delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteOldCronRuns(createdBefore time.Time) error {
+	formattedDate := createdBefore.UTC().Format(types.DefaultDateLayout)
+	expr := dbx.NewExp("[[created]] <= {:date}", dbx.Params{"date": formattedDate})
+
+	_, err := app.auxNonconcurrentDB.Delete(CronRunsTableName, expr).Execute()
+
+	return err
+}
+
+// DeleteExcessCronRuns deletes the oldest runs of each cron job
+// so that at most maxPerJob runs per job are kept.
+//
+// For better performance the cron runs delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteExcessCronRuns(maxPerJob int) error {
+	expr := dbx.NewExp(
+		"[[id]] IN (SELECT [[id]] FROM (SELECT [[id]], ROW_NUMBER() OVER (PARTITION BY [[jobId]] ORDER BY [[started]] DESC, [[rowid]] DESC) AS [[rowNum]] FROM {{"+CronRunsTableName+"}}) WHERE [[rowNum]] > {:max})",
+		dbx.Params{"max": max(maxPerJob, 0)},
+	)
+
+	_, err := app.auxNonconcurrentDB.Delete(CronRunsTableName, expr).Execute()
+
+	return err
+}
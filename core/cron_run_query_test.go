@@ -0,0 +1,212 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func stubCronRun(t testing.TB, app core.App, jobId string, started string) *core.CronRun {
+	run := &core.CronRun{JobId: jobId}
+	run.Id = core.GenerateDefaultRandomId()
+	run.Started, _ = types.ParseDateTime(started)
+	run.Created = types.NowDateTime()
+
+	if err := app.AuxSaveNoValidate(run); err != nil {
+		t.Fatal(err)
+	}
+
+	return run
+}
+
+func TestFindCronRunById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	run := stubCronRun(t, app, "test", "2024-01-01 00:00:00.000Z")
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{run.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindCronRunById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result.Id != s.id {
+				t.Fatalf("Expected result with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindLastCronRun(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubCronRun(t, app, "a", "2024-01-01 00:00:00.000Z")
+	last := stubCronRun(t, app, "a", "2024-01-03 00:00:00.000Z")
+	stubCronRun(t, app, "a", "2024-01-02 00:00:00.000Z")
+	stubCronRun(t, app, "b", "2024-01-04 00:00:00.000Z")
+
+	scenarios := []struct {
+		jobId      string
+		expectedId string
+	}{
+		{"missing", ""},
+		{"a", last.Id},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.jobId, func(t *testing.T) {
+			result, err := app.FindLastCronRun(s.jobId)
+
+			if s.expectedId == "" {
+				if err == nil {
+					t.Fatalf("Expected error, got %v", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result.Id != s.expectedId {
+				t.Fatalf("Expected run %q, got %q", s.expectedId, result.Id)
+			}
+		})
+	}
+}
+
+func TestDeleteOldCronRuns(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	old := stubCronRun(t, app, "test", "2024-01-01 00:00:00.000Z")
+	recent := stubCronRun(t, app, "test", "2024-01-02 00:00:00.000Z")
+
+	_, err := app.AuxNonconcurrentDB().Update(
+		core.CronRunsTableName,
+		dbx.Params{"created": types.NowDateTime().Add(-2 * time.Hour).String()},
+		dbx.HashExp{"id": old.Id},
+	).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DeleteOldCronRuns(time.Now().Add(-1 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindCronRunById(old.Id); err == nil {
+		t.Fatalf("Expected run %q to be deleted", old.Id)
+	}
+
+	if _, err := app.FindCronRunById(recent.Id); err != nil {
+		t.Fatalf("Expected run %q to exist, got %v", recent.Id, err)
+	}
+}
+
+func TestDeleteExcessCronRuns(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	test1 := stubCronRun(t, app, "test1", "2024-01-01 00:00:00.000Z")
+	test2 := stubCronRun(t, app, "test1", "2024-01-02 00:00:00.000Z")
+	test3 := stubCronRun(t, app, "test1", "2024-01-03 00:00:00.000Z")
+	other := stubCronRun(t, app, "test2", "2024-01-01 00:00:00.000Z")
+
+	if err := app.DeleteExcessCronRuns(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindCronRunById(test1.Id); err == nil {
+		t.Fatalf("Expected run %q to be deleted", test1.Id)
+	}
+
+	for _, run := range []*core.CronRun{test2, test3, other} {
+		if _, err := app.FindCronRunById(run.Id); err != nil {
+			t.Fatalf("Expected run %q to exist, got %v", run.Id, err)
+		}
+	}
+}
+
+func TestCronRunsPersistence(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Cron().MustAdd("test", "* * * * *", func() {
+		panic("test_panic")
+	})
+
+	for _, j := range app.Cron().Jobs() {
+		if j.Id() == "test" {
+			j.Run()
+		}
+	}
+
+	run, err := app.FindLastCronRun("test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !run.Manual {
+		t.Fatal("Expected manual run")
+	}
+
+	if !run.Panicked {
+		t.Fatal("Expected panicked run")
+	}
+
+	if run.Error != "panic: test_panic" {
+		t.Fatalf("Expected run error %q, got %q", "panic: test_panic", run.Error)
+	}
+
+	if run.Started.IsZero() {
+		t.Fatal("Expected non-zero run started date")
+	}
+}
+
+func TestCronRunsPersistenceSkipSystemJobs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Cron().MustAdd("__pbTest__", "* * * * *", func() {})
+
+	for _, j := range app.Cron().Jobs() {
+		if j.Id() == "__pbTest__" {
+			j.Run()
+		}
+	}
+
+	if _, err := app.FindLastCronRun("__pbTest__"); err == nil {
+		t.Fatal("Expected the system cron job run to be not persisted")
+	}
+}
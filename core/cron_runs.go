@@ -0,0 +1,71 @@
+package core
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// cronRunsMaxAge is the max age of the persisted cron job runs.
+	cronRunsMaxAge = 7 * 24 * time.Hour
+
+	// cronRunsMaxPerJob is the max number of persisted runs per cron job
+	// (ex. to limit the history of jobs with seconds schedule).
+	cronRunsMaxPerJob = 1000
+
+	// systemCronJobPrefix is the id prefix of the internal cron jobs.
+	systemCronJobPrefix = "__pb"
+)
+
+// registerCronRunsHooks persists the finished cron job runs
+// in the auxiliary database and registers their periodic cleanup.
+//
+// The runs of the internal system cron jobs are not persisted.
+func (app *BaseApp) registerCronRunsHooks() {
+	app.Cron().SetRunListener(func(run *cron.Run) {
+		if strings.HasPrefix(run.JobId, systemCronJobPrefix) {
+			return
+		}
+
+		if err := app.saveCronRun(run); err != nil {
+			app.Logger().Warn("Failed to save cron job run", "jobId", run.JobId, "error", err)
+		}
+	})
+
+	// run every hour to cleanup the old and excess cron job runs
+	app.Cron().Add("__pbCronRunsCleanup__", "0 * * * *", func() {
+		if err := app.DeleteOldCronRuns(time.Now().Add(-cronRunsMaxAge)); err != nil {
+			app.Logger().Warn("Failed to delete old cron job runs", "error", err)
+		}
+
+		if err := app.DeleteExcessCronRuns(cronRunsMaxPerJob); err != nil {
+			app.Logger().Warn("Failed to delete excess cron job runs", "error", err)
+		}
+	})
+}
+
+func (app *BaseApp) saveCronRun(run *cron.Run) error {
+	model := &CronRun{
+		JobId:    run.JobId,
+		Duration: float64(run.Duration) / float64(time.Millisecond),
+		Manual:   run.Manual,
+		Panicked: run.Panicked,
+	}
+
+	started, err := types.ParseDateTime(run.Started)
+	if err != nil {
+		return err
+	}
+	model.Started = started
+	model.Created = types.NowDateTime()
+	model.Id = GenerateDefaultRandomId()
+
+	if run.Error != nil {
+		model.Error = run.Error.Error()
+	}
+
+	return app.AuxSaveNoValidate(model)
+}
@@ -121,8 +121,15 @@ func (app *BaseApp) hasTable(db dbx.Builder, tableName string) bool {
 }
 
 // Vacuum executes VACUUM on the data.db in order to reclaim unused data db disk space.
+//
+// Because VACUUM may change the records table rowids, the collections
+// full-text indexes (if any) are also rebuilt after that.
 func (app *BaseApp) Vacuum() error {
-	return app.vacuum(app.NonconcurrentDB())
+	if err := app.vacuum(app.NonconcurrentDB()); err != nil {
+		return err
+	}
+
+	return rebuildAllFullTextIndexes(app)
 }
 
 // AuxVacuum executes VACUUM on the auxiliary.db in order to reclaim unused auxiliary db disk space.
@@ -13,6 +13,7 @@ import (
 // Common request store keys used by the middlewares and api handlers.
 const (
 	RequestEventKeyInfoContext = "infoContext"
+	RequestEventKeyAPIKey      = "apiKey"
 )
 
 // RequestEvent defines the PocketBase router handler event.
@@ -89,6 +90,7 @@ func (e *RequestEvent) RequestInfo() (*RequestInfo, error) {
 
 	if e.cachedRequestInfo != nil {
 		e.cachedRequestInfo.Auth = e.Auth
+		e.cachedRequestInfo.APIKey = e.APIKey()
 
 		infoCtx, _ := e.Get(RequestEventKeyInfoContext).(string)
 		if infoCtx != "" {
@@ -141,12 +143,30 @@ func (e *RequestEvent) initRequestInfo() error {
 	}
 
 	info.Auth = e.Auth
+	info.APIKey = e.APIKey()
 
 	e.cachedRequestInfo = info
 
 	return nil
 }
 
+// APIKey returns the API key used to authenticate the current request
+// (returns nil if the request auth state wasn't loaded with an API key).
+func (e *RequestEvent) APIKey() *APIKey {
+	apiKey, _ := e.Get(RequestEventKeyAPIKey).(*APIKey)
+
+	// ensure that the API key is still for the current auth record
+	// (e.g. in case e.Auth was manually changed)
+	if apiKey == nil ||
+		e.Auth == nil ||
+		apiKey.RecordRef() != e.Auth.Id ||
+		apiKey.CollectionRef() != e.Auth.Collection().Id {
+		return nil
+	}
+
+	return apiKey
+}
+
 // -------------------------------------------------------------------
 
 const (
@@ -156,7 +176,10 @@ const (
 	RequestInfoContextProtectedFile = "protectedFile"
 	RequestInfoContextBatch         = "batch"
 	RequestInfoContextOAuth2        = "oauth2"
+	RequestInfoContextSAML          = "saml"
 	RequestInfoContextOTP           = "otp"
+	RequestInfoContextTOTP          = "totp"
+	RequestInfoContextWebAuthn      = "webauthn"
 	RequestInfoContextPasswordAuth  = "password"
 )
 
@@ -169,6 +192,7 @@ type RequestInfo struct {
 	Headers map[string]string `json:"headers"`
 	Body    map[string]any    `json:"body"`
 	Auth    *Record           `json:"auth"`
+	APIKey  *APIKey           `json:"apiKey,omitempty"`
 	Method  string            `json:"method"`
 	Context string            `json:"context"`
 }
@@ -193,5 +217,9 @@ func (info *RequestInfo) Clone() *RequestInfo {
 		clone.Auth = info.Auth.Fresh()
 	}
 
+	if info.APIKey != nil {
+		clone.APIKey = &APIKey{Record: info.APIKey.Fresh()}
+	}
+
 	return clone
 }
@@ -442,6 +442,32 @@ type RealtimeSubscribeRequestEvent struct {
 	Subscriptions []string
 }
 
+type RealtimePresenceEvent struct {
+	hook.Event
+	App App
+
+	Client subscriptions.Client
+
+	// Topic is the subscription topic (without the options)
+	// that the client has joined or left.
+	Topic string
+
+	// Action is the presence change action ("join" or "leave").
+	Action string
+}
+
+type RealtimePublishRequestEvent struct {
+	hook.Event
+	*RequestEvent
+
+	// Client is the publisher realtime client
+	// (it is nil when the message is published with the HTTP API).
+	Client subscriptions.Client
+
+	Channel string
+	Data    any
+}
+
 // -------------------------------------------------------------------
 // Record CRUD API events data
 // -------------------------------------------------------------------
@@ -494,6 +520,39 @@ type RecordAuthWithOTPRequestEvent struct {
 	OTP    *OTP
 }
 
+type RecordAuthWithTOTPRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record *Record
+	TOTP   *TOTP
+
+	// UsedRecoveryCode indicates whether the submitted code was a recovery code.
+	UsedRecoveryCode bool
+}
+
+type RecordAuthWithWebAuthnRequestEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record     *Record
+	Credential *WebAuthnCredential
+}
+
+type RecordAuthLockoutEvent struct {
+	hook.Event
+	*RequestEvent
+	baseCollectionEventData
+
+	Record  *Record
+	Lockout *AuthLockout
+
+	// AuthMethod is the auth method of the failed attempts (password, otp).
+	AuthMethod string
+}
+
 type RecordAuthRequestEvent struct {
 	hook.Event
 	*RequestEvent
@@ -503,6 +562,11 @@ type RecordAuthRequestEvent struct {
 	Token      string
 	Meta       any
 	AuthMethod string
+
+	// RefreshToken is the plain refresh token of the auth session
+	// linked to Token (populated only when the collection has the
+	// refresh tokens enabled and after the MFA check).
+	RefreshToken string
 }
 
 type RecordAuthWithPasswordRequestEvent struct {
@@ -521,7 +585,10 @@ type RecordAuthWithOAuth2RequestEvent struct {
 	*RequestEvent
 	baseCollectionEventData
 
-	ProviderName   string
+	ProviderName string
+
+	// ProviderClient is the OAuth2 provider client instance
+	// (it is nil for the SAML auth requests, aka. when ProviderName is [SAMLProviderName]).
 	ProviderClient auth.Provider
 	Record         *Record
 	OAuth2User     *auth.AuthUser
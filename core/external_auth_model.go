@@ -123,10 +123,11 @@ func (app *BaseApp) registerExternalAuthHooks() {
 
 	app.OnRecordValidate(CollectionNameExternalAuths).Bind(&hook.Handler[*RecordEvent]{
 		Func: func(e *RecordEvent) error {
-			providerNames := make([]any, 0, len(auth.Providers))
+			providerNames := make([]any, 0, len(auth.Providers)+1)
 			for name := range auth.Providers {
 				providerNames = append(providerNames, name)
 			}
+			providerNames = append(providerNames, SAMLProviderName)
 
 			provider := e.Record.GetString("provider")
 			if err := validation.Validate(provider, validation.Required, validation.In(providerNames...)); err != nil {
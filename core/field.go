@@ -166,6 +166,13 @@ type MultiValuer interface {
 	IsMultiple() bool
 }
 
+// FullTextIndexer defines a field interface for fields that could be
+// included in the collection full-text search index.
+type FullTextIndexer interface {
+	// IsFullText checks whether the field is configured to be full-text indexed.
+	IsFullText() bool
+}
+
 // RecordInterceptor defines a field interface for reacting to various
 // Record related operations (create, delete, validate, etc.).
 type RecordInterceptor interface {
@@ -21,6 +21,7 @@ const DefaultEditorFieldMaxSize int64 = 5 << 20
 var (
 	_ Field                 = (*EditorField)(nil)
 	_ MaxBodySizeCalculator = (*EditorField)(nil)
+	_ FullTextIndexer       = (*EditorField)(nil)
 )
 
 // EditorField defines "editor" type field to store HTML formatted text.
@@ -61,6 +62,10 @@ type EditorField struct {
 
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
+
+	// FullText includes the field in the collection full-text search index
+	// allowing it to be queried with the match() filter function.
+	FullText bool `form:"fullText" json:"fullText"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -108,6 +113,11 @@ func (f *EditorField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// IsFullText implements [FullTextIndexer] interface method.
+func (f *EditorField) IsFullText() bool {
+	return f.FullText
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *EditorField) ColumnType(app App) string {
 	return "TEXT DEFAULT '' NOT NULL"
@@ -0,0 +1,419 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeFormula] = func() Field {
+		return &FormulaField{}
+	}
+}
+
+const FieldTypeFormula = "formula"
+
+// Supported formula field result types.
+const (
+	FormulaResultText   = "text"
+	FormulaResultNumber = "number"
+	FormulaResultBool   = "bool"
+)
+
+// formulaForbiddenRegex matches the expression constructs that are not allowed
+// (statements separator, comments and subqueries).
+//
+// Note that the quoted literals must be removed before the check (see formulaQuotedRegex).
+var formulaForbiddenRegex = regexp.MustCompile(`(?i)(;|--|/\*|\bselect\b)`)
+
+// formulaQuotedRegex matches the terminated SQLite string literals and
+// quoted identifiers (including the escaped quotes inside them).
+var formulaQuotedRegex = regexp.MustCompile("'(?:[^']|'')*'|\"(?:[^\"]|\"\")*\"|`(?:[^`]|``)*`|\\[[^\\]]*\\]")
+
+var (
+	_ Field             = (*FormulaField)(nil)
+	_ SetterFinder      = (*FormulaField)(nil)
+	_ RecordInterceptor = (*FormulaField)(nil)
+)
+
+// FormulaField defines "formula" type field, aka. a read-only field
+// which value is computed from the other record fields on record save.
+//
+// The Expression is a single SQLite scalar expression where the other record
+// fields could be referenced by their names, for example:
+//
+//	qty * price
+//	first || ' ' || last
+//	round(total / 100.0, 2)
+//	status = 'active' AND verified
+//
+// The computed value is stored in a regular column so it could be
+// filtered and sorted like any other field.
+//
+// The existing records are recomputed every time the Expression or the ResultType changes
+// (together with the formula fields after it because they could reference its value).
+//
+// The respective zero record field value depends on the ResultType
+// (empty string for "text", 0 for "number" and false for "bool").
+type FormulaField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Expression (required) is the SQLite expression used to compute the field value.
+	//
+	// It could reference only the non-formula fields and the formula
+	// fields defined before the current one in the collection fields list.
+	Expression string `form:"expression" json:"expression"`
+
+	// ResultType specifies the type of the computed value
+	// ("text", "number" or "bool").
+	//
+	// If not set, fallbacks to "text".
+	ResultType string `form:"resultType" json:"resultType"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *FormulaField) Type() string {
+	return FieldTypeFormula
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *FormulaField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *FormulaField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *FormulaField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *FormulaField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *FormulaField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *FormulaField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *FormulaField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *FormulaField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *FormulaField) ColumnType(app App) string {
+	switch f.resultType() {
+	case FormulaResultNumber:
+		return "NUMERIC DEFAULT 0 NOT NULL"
+	case FormulaResultBool:
+		return "BOOLEAN DEFAULT FALSE NOT NULL"
+	default:
+		return "TEXT DEFAULT '' NOT NULL"
+	}
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *FormulaField) PrepareValue(record *Record, raw any) (any, error) {
+	switch f.resultType() {
+	case FormulaResultNumber:
+		return cast.ToFloat64(raw), nil
+	case FormulaResultBool:
+		return cast.ToBool(raw), nil
+	default:
+		return cast.ToString(raw), nil
+	}
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *FormulaField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *FormulaField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(
+			&f.ResultType,
+			validation.In(FormulaResultText, FormulaResultNumber, FormulaResultBool),
+		),
+		validation.Field(
+			&f.Expression,
+			validation.Required,
+			validation.Length(1, 1000),
+			validation.By(f.checkExpression(app, collection)),
+		),
+	)
+}
+
+func (f *FormulaField) checkExpression(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		// (the quoted literals are replaced to allow for example ' -- ' as text separator)
+		if formulaForbiddenRegex.MatchString(formulaQuotedRegex.ReplaceAllLiteralString(v, "''")) {
+			return validation.NewError("validation_invalid_formula", "Subqueries, comments and multiple statements are not allowed.")
+		}
+
+		// dry-run the expression with NULL values to check for syntax errors and unknown fields
+		names := f.allowedFieldNames(collection)
+		params := make(dbx.Params, len(names))
+		for i := range names {
+			params[formulaParamName(i)] = nil
+		}
+
+		var result sql.NullString
+		err := app.DB().NewQuery(f.evalQuery(names)).Bind(params).Row(&result)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return validation.NewError("validation_invalid_formula", "Invalid formula expression - "+err.Error())
+		}
+
+		return nil
+	}
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *FormulaField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		// return noopSetter to disallow updating the value with record.Set()
+		return noopSetter
+	default:
+		return nil
+	}
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *FormulaField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	switch actionName {
+	case InterceptorActionCreateExecute, InterceptorActionUpdateExecute:
+		value, err := f.Eval(app, record)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate formula field %q: %w", f.Name, err)
+		}
+
+		record.SetRaw(f.Name, value)
+
+		return actionFunc()
+	default:
+		return actionFunc()
+	}
+}
+
+// Eval evaluates the field expression against the current record fields data
+// and returns the prepared result (without updating the record).
+func (f *FormulaField) Eval(app App, record *Record) (any, error) {
+	exported, err := record.dbExport()
+	if err != nil {
+		return nil, err
+	}
+
+	names := f.allowedFieldNames(record.Collection())
+	params := make(dbx.Params, len(names))
+	for i, name := range names {
+		value := exported[name]
+
+		// normalize the whole floats to be consistent with the
+		// NUMERIC column affinity conversion (ex. 30.0 -> 30)
+		if v, ok := value.(float64); ok && v == math.Trunc(v) && math.Abs(v) <= float64(maxSafeJSONInt) {
+			value = int64(v)
+		}
+
+		params[formulaParamName(i)] = value
+	}
+
+	var result sql.NullString
+	err = app.DB().NewQuery(f.evalQuery(names)).Bind(params).Row(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !result.Valid {
+		return f.PrepareValue(record, nil)
+	}
+
+	return f.PrepareValue(record, result.String)
+}
+
+// evalQuery returns the query for evaluating the field expression
+// against the named params of the specified field names.
+func (f *FormulaField) evalQuery(names []string) string {
+	// note: the extra NULL column is to ensure that the subquery
+	// is valid even if there are no other collection fields
+	columns := "SELECT NULL"
+	for i, name := range names {
+		columns += ", {:" + formulaParamName(i) + "} AS [[" + name + "]]"
+	}
+
+	return "SELECT (" + f.Expression + ") FROM (" + columns + ")"
+}
+
+// formulaParamName returns the evaluation query param name of the i-th field
+// (field names are not used directly to avoid collisions with the dbx reserved param names).
+func formulaParamName(i int) string {
+	return "formula" + strconv.Itoa(i)
+}
+
+// allowedFieldNames returns the names of the collection fields that
+// could be referenced in the expression (aka. all non-formula fields
+// and the formula fields defined before the current one).
+func (f *FormulaField) allowedFieldNames(collection *Collection) []string {
+	names := make([]string, 0, len(collection.Fields))
+
+	var found bool
+	for _, field := range collection.Fields {
+		if field.GetId() == f.Id && field.GetName() == f.Name {
+			found = true
+			continue
+		}
+
+		if _, ok := field.(*FormulaField); ok && found {
+			continue
+		}
+
+		names = append(names, field.GetName())
+	}
+
+	return names
+}
+
+func (f *FormulaField) resultType() string {
+	if f.ResultType == "" {
+		return FormulaResultText
+	}
+
+	return f.ResultType
+}
+
+// defaultValueSQL returns the SQL literal of the field zero value.
+func (f *FormulaField) defaultValueSQL() string {
+	switch f.resultType() {
+	case FormulaResultNumber:
+		return "0"
+	case FormulaResultBool:
+		return "FALSE"
+	default:
+		return "''"
+	}
+}
+
+// syncFormulaFields recomputes the values of the existing newCollection records
+// for the first new formula field (or the one with changed expression or result type)
+// and all formula fields after it because they could reference its value.
+//
+// oldCollection could be nil in case of a newly created collection.
+func syncFormulaFields(app App, newCollection *Collection, oldCollection *Collection) error {
+	var changed bool
+
+	for _, field := range newCollection.Fields {
+		f, ok := field.(*FormulaField)
+		if !ok {
+			continue
+		}
+
+		if !changed && oldCollection != nil {
+			old, _ := oldCollection.Fields.GetById(f.Id).(*FormulaField)
+			if old != nil && old.Expression == f.Expression && old.resultType() == f.resultType() {
+				continue // no change
+			}
+		}
+
+		changed = true
+
+		_, err := app.DB().Update(
+			newCollection.Name,
+			dbx.Params{f.Name: dbx.NewExp(f.updateExpr())},
+			nil,
+		).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to compute formula field %q - %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// recomputeFormulaFields recomputes all formula fields of the collection
+// records matching the where expression (e.g. after a direct db update
+// of the record columns like the rollup fields sync).
+//
+// The fields are recomputed one after another in their collection order
+// so that each formula could use the already updated values of the previous ones.
+func recomputeFormulaFields(app App, collection *Collection, where dbx.Expression) error {
+	for _, field := range collection.Fields {
+		f, ok := field.(*FormulaField)
+		if !ok {
+			continue
+		}
+
+		_, err := app.DB().Update(
+			collection.Name,
+			dbx.Params{f.Name: dbx.NewExp(f.updateExpr())},
+			where,
+		).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to recompute formula field %q - %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// updateExpr returns the SQL expression for computing the field value
+// in an UPDATE statement of the collection records.
+func (f *FormulaField) updateExpr() string {
+	return "COALESCE((" + f.Expression + "), " + f.defaultValueSQL() + ")"
+}
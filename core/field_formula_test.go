@@ -0,0 +1,425 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestFormulaFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeFormula)
+}
+
+func TestFormulaFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		resultType string
+		expected   string
+	}{
+		{"", "TEXT DEFAULT '' NOT NULL"},
+		{core.FormulaResultText, "TEXT DEFAULT '' NOT NULL"},
+		{core.FormulaResultNumber, "NUMERIC DEFAULT 0 NOT NULL"},
+		{core.FormulaResultBool, "BOOLEAN DEFAULT FALSE NOT NULL"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.resultType, func(t *testing.T) {
+			f := &core.FormulaField{ResultType: s.resultType}
+
+			if v := f.ColumnType(app); v != s.expected {
+				t.Fatalf("Expected\n%q\ngot\n%q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestFormulaFieldPrepareValue(t *testing.T) {
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		resultType string
+		raw        any
+		expected   any
+	}{
+		{"", nil, ""},
+		{"", 123, "123"},
+		{core.FormulaResultText, "abc", "abc"},
+		{core.FormulaResultNumber, nil, 0.0},
+		{core.FormulaResultNumber, "12.5", 12.5},
+		{core.FormulaResultNumber, "abc", 0.0},
+		{core.FormulaResultBool, nil, false},
+		{core.FormulaResultBool, "1", true},
+		{core.FormulaResultBool, "0", false},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%s_%#v", i, s.resultType, s.raw), func(t *testing.T) {
+			f := &core.FormulaField{ResultType: s.resultType}
+
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %#v, got %#v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestFormulaFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeFormula)
+	testDefaultFieldNameValidation(t, core.FieldTypeFormula)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(
+		&core.NumberField{Name: "qty"},
+		&core.FormulaField{Id: "f1", Name: "f1", Expression: "qty * 2"},
+		&core.FormulaField{Id: "test", Name: "test", Expression: "1"},
+		&core.FormulaField{Id: "f2", Name: "f2", Expression: "qty * 3"},
+	)
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.FormulaField
+		expectErrors []string
+	}{
+		{
+			"zero",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"invalid result type",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "1", ResultType: "abc"}
+			},
+			[]string{"resultType"},
+		},
+		{
+			"invalid expression syntax",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "qty *"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"unknown field",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "missing * 2"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"self reference",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "test + 1"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"reference to a formula field defined after the current one",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "f2 + 1"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"subquery",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "(SELECT count(*) FROM _superusers)"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"multiple statements",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "1; DROP TABLE users"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"comment",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "1 -- comment"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"multiple statements hidden with a quote inside a quoted identifier",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "[qty'] ; DROP TABLE users; [']"}
+			},
+			[]string{"expression"},
+		},
+		{
+			"comment and statements separator inside text literals",
+			func() *core.FormulaField {
+				return &core.FormulaField{Id: "test", Name: "test", Expression: "f1 || ' -- ' || qty || ';' || 'it''s /* select */'"}
+			},
+			[]string{},
+		},
+		{
+			"valid expression",
+			func() *core.FormulaField {
+				return &core.FormulaField{
+					Id:         "test",
+					Name:       "test",
+					Expression: "round(qty * f1 / 2.0, 2) + length('selected')",
+					ResultType: core.FormulaResultNumber,
+				}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestFormulaFieldFindSetter(t *testing.T) {
+	field := &core.FormulaField{Name: "test"}
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(field)
+
+	record := core.NewRecord(collection)
+	record.SetRaw("test", "abc")
+
+	t.Run("no matching setter", func(t *testing.T) {
+		f := field.FindSetter("abc")
+		if f != nil {
+			t.Fatal("Expected nil setter")
+		}
+	})
+
+	t.Run("matching setter", func(t *testing.T) {
+		f := field.FindSetter("test")
+		if f == nil {
+			t.Fatal("Expected non-nil setter")
+		}
+
+		f(record, "new") // should be ignored
+
+		if v := record.GetString("test"); v != "abc" {
+			t.Fatalf("Expected no value change, got %q", v)
+		}
+	})
+}
+
+func TestFormulaFieldIntercept(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_formula")
+	collection.Fields.Add(
+		// note: intentionally defined before the referenced fields
+		&core.FormulaField{Name: "total", Expression: "qty * price", ResultType: core.FormulaResultNumber},
+		&core.FormulaField{Name: "label", Expression: "first || ' ' || last || ' (' || total || ')'"},
+		&core.FormulaField{Name: "expensive", Expression: "total > 100", ResultType: core.FormulaResultBool},
+		&core.FormulaField{Name: "hasCreated", Expression: "created != ''", ResultType: core.FormulaResultBool},
+		&core.NumberField{Name: "qty"},
+		&core.NumberField{Name: "price"},
+		&core.TextField{Name: "first"},
+		&core.TextField{Name: "last"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("qty", 3)
+	record.Set("price", 10)
+	record.Set("first", "John")
+	record.Set("last", "Doe")
+	record.Set("total", 999) // should be ignored
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	assertValues := func(t *testing.T, record *core.Record, total float64, label string, expensive bool) {
+		t.Helper()
+
+		if v := record.GetFloat("total"); v != total {
+			t.Fatalf("Expected total %v, got %v", total, v)
+		}
+
+		if v := record.GetString("label"); v != label {
+			t.Fatalf("Expected label %q, got %q", label, v)
+		}
+
+		if v := record.GetBool("expensive"); v != expensive {
+			t.Fatalf("Expected expensive %v, got %v", expensive, v)
+		}
+
+		if !record.GetBool("hasCreated") {
+			t.Fatal("Expected hasCreated to be true")
+		}
+	}
+
+	t.Run("create", func(t *testing.T) {
+		assertValues(t, record, 30, "John Doe (30)", false)
+
+		fresh, err := app.FindRecordById(collection, record.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertValues(t, fresh, 30, "John Doe (30)", false)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		record.Set("qty", 20)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+		assertValues(t, record, 200, "John Doe (200)", true)
+
+		fresh, err := app.FindRecordById(collection, record.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertValues(t, fresh, 200, "John Doe (200)", true)
+	})
+
+	t.Run("filter and sort", func(t *testing.T) {
+		other := core.NewRecord(collection)
+		other.Set("qty", 1)
+		other.Set("price", 1)
+		if err := app.Save(other); err != nil {
+			t.Fatal(err)
+		}
+
+		records, err := app.FindRecordsByFilter(collection, "total > 100 && expensive = true", "-total", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Id != record.Id {
+			t.Fatalf("Expected only record %q, got %v", record.Id, records)
+		}
+
+		records, err = app.FindRecordsByFilter(collection, "", "total", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || records[0].Id != other.Id {
+			t.Fatalf("Expected record %q to be first, got %v", other.Id, records)
+		}
+	})
+}
+
+func TestFormulaFieldRecompute(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection := core.NewBaseCollection("test_formula")
+	collection.Fields.Add(
+		&core.NumberField{Name: "qty"},
+		&core.NumberField{Name: "price"},
+	)
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 2; i++ {
+		record := core.NewRecord(collection)
+		record.Set("qty", i)
+		record.Set("price", 10)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	assertTotals := func(t *testing.T, expected ...float64) {
+		t.Helper()
+
+		records, err := app.FindRecordsByFilter(collection, "", "qty", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, r := range records {
+			if v := r.GetFloat("total"); v != expected[i] {
+				t.Fatalf("[%d] Expected total %v, got %v", i, expected[i], v)
+			}
+		}
+	}
+
+	t.Run("new field", func(t *testing.T) {
+		collection.Fields.Add(&core.FormulaField{Name: "total", Expression: "qty * price", ResultType: core.FormulaResultNumber})
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		assertTotals(t, 10, 20)
+	})
+
+	t.Run("changed expression", func(t *testing.T) {
+		collection.Fields.GetByName("total").(*core.FormulaField).Expression = "qty * price * 2"
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		assertTotals(t, 20, 40)
+	})
+
+	t.Run("unchanged formula referencing a changed one", func(t *testing.T) {
+		collection.Fields.Add(&core.FormulaField{Name: "total_label", Expression: "'total: ' || total"})
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		collection.Fields.GetByName("total").(*core.FormulaField).Expression = "qty * price * 3"
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		assertTotals(t, 30, 60)
+
+		records, err := app.FindRecordsByFilter(collection, "", "qty", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, expected := range []string{"total: 30", "total: 60"} {
+			if v := records[i].GetString("total_label"); v != expected {
+				t.Fatalf("[%d] Expected total_label %q, got %q", i, expected, v)
+			}
+		}
+
+		collection.Fields.RemoveByName("total_label")
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("renamed referenced field with invalid expression", func(t *testing.T) {
+		collection.Fields.GetByName("price").SetName("price2")
+		if err := app.Save(collection); err == nil {
+			t.Fatal("Expected validation error")
+		}
+
+		collection.Fields.GetByName("total").(*core.FormulaField).Expression = "qty * price2"
+		if err := app.Save(collection); err != nil {
+			t.Fatal(err)
+		}
+
+		assertTotals(t, 10, 20)
+	})
+}
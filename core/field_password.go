@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
+	"unicode"
 
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/pocketbase/pocketbase/core/validators"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/spf13/cast"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -21,6 +25,22 @@ func init() {
 
 const FieldTypePassword = "password"
 
+// Supported PasswordField.CharClasses values.
+const (
+	PasswordCharClassLower  = "lower"
+	PasswordCharClassUpper  = "upper"
+	PasswordCharClassDigit  = "digit"
+	PasswordCharClassSymbol = "symbol"
+)
+
+// PasswordCharClasses lists all supported PasswordField.CharClasses values.
+var PasswordCharClasses = []string{
+	PasswordCharClassLower,
+	PasswordCharClassUpper,
+	PasswordCharClassDigit,
+	PasswordCharClassSymbol,
+}
+
 var (
 	_ Field             = (*PasswordField)(nil)
 	_ GetterFinder      = (*PasswordField)(nil)
@@ -89,6 +109,37 @@ type PasswordField struct {
 
 	// Required will require the field value to be non-empty string.
 	Required bool `form:"required" json:"required"`
+
+	// CharClasses specifies an optional list of character classes
+	// that the field value must contain at least one character from
+	// (see [PasswordCharClasses]).
+	CharClasses []string `form:"charClasses" json:"charClasses"`
+
+	// DisallowIdentity rejects the field value if it contains the
+	// value of any of the auth record identity fields
+	// (for the email field only its local part is checked).
+	DisallowIdentity bool `form:"disallowIdentity" json:"disallowIdentity"`
+
+	// HistorySize specifies the number of the last auth record passwords
+	// that cannot be reused (leave it 0 to disable the check).
+	//
+	// It is supported only for the auth collection "password" system field.
+	HistorySize int `form:"historySize" json:"historySize"`
+
+	// MaxAge specifies the max allowed auth record password age in seconds
+	// after which the auth response is marked with "passwordExpired"
+	// to indicate that the password must be changed (leave it 0 to disable).
+	//
+	// It is supported only for the auth collection "password" system field.
+	MaxAge int64 `form:"maxAge" json:"maxAge"`
+
+	// BreachedHashesPath specifies an optional local path to a list
+	// of SHA-1 hashes of known breached passwords that will be rejected.
+	//
+	// The path could be either a single file with sorted "HASH[:COUNT]" lines
+	// or a directory with k-anonymity range files named after the first
+	// 5 hash characters (e.g. "21BD1.txt") and containing "SUFFIX[:COUNT]" lines.
+	BreachedHashesPath string `form:"breachedHashesPath" json:"breachedHashesPath"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -201,9 +252,123 @@ func (f *PasswordField) ValidateValue(ctx context.Context, app App, record *Reco
 		}
 	}
 
+	if err := f.checkCharClasses(fp.Plain); err != nil {
+		return err
+	}
+
+	if f.DisallowIdentity {
+		if err := f.checkIdentity(record, fp.Plain); err != nil {
+			return err
+		}
+	}
+
+	if f.HistorySize > 0 && f.isAuthPassword(record.Collection()) && !record.IsNew() {
+		if err := f.checkHistory(app, record, fp.Plain); err != nil {
+			return err
+		}
+	}
+
+	if f.BreachedHashesPath != "" {
+		breached, err := security.IsBreachedPassword(f.BreachedHashesPath, fp.Plain)
+		if err != nil {
+			app.Logger().Warn("Failed to check the breached passwords list", "error", err, "path", f.BreachedHashesPath)
+			return validation.NewError("validation_password_breached_check_failure", "Failed to verify the password safety")
+		}
+		if breached {
+			return validation.NewError("validation_password_breached", "The password has appeared in a data breach and cannot be used")
+		}
+	}
+
 	return nil
 }
 
+func (f *PasswordField) checkCharClasses(plain string) error {
+	for _, class := range f.CharClasses {
+		var check func(r rune) bool
+		var message string
+
+		switch class {
+		case PasswordCharClassLower:
+			check = unicode.IsLower
+			message = "Must contain at least one lowercase letter"
+		case PasswordCharClassUpper:
+			check = unicode.IsUpper
+			message = "Must contain at least one uppercase letter"
+		case PasswordCharClassDigit:
+			check = unicode.IsDigit
+			message = "Must contain at least one digit"
+		case PasswordCharClassSymbol:
+			check = func(r rune) bool {
+				return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+			}
+			message = "Must contain at least one symbol"
+		default:
+			continue
+		}
+
+		if !strings.ContainsFunc(plain, check) {
+			return validation.NewError("validation_password_missing_"+class, message)
+		}
+	}
+
+	return nil
+}
+
+func (f *PasswordField) checkIdentity(record *Record, plain string) error {
+	collection := record.Collection()
+	if !collection.IsAuth() {
+		return nil
+	}
+
+	plain = strings.ToLower(plain)
+
+	for _, name := range collection.PasswordAuth.IdentityFields {
+		identity := record.GetString(name)
+		if name == FieldNameEmail {
+			identity, _, _ = strings.Cut(identity, "@")
+		}
+
+		// ignore too short values to minimize the false positives
+		if len([]rune(identity)) < 3 {
+			continue
+		}
+
+		if strings.Contains(plain, strings.ToLower(identity)) {
+			return validation.NewError("validation_password_contains_identity", "Must not contain your "+name)
+		}
+	}
+
+	return nil
+}
+
+func (f *PasswordField) checkHistory(app App, record *Record, plain string) error {
+	err := validation.NewError("validation_password_reused", fmt.Sprintf("Must not be any of your last %d password(s)", f.HistorySize))
+
+	// the current password may not be tracked yet (e.g. created before enabling the option)
+	original := record.Original()
+	if original != nil && original.ValidatePassword(plain) {
+		return err
+	}
+
+	changes, findErr := app.FindRecentPasswordChanges(record, f.HistorySize)
+	if findErr != nil {
+		return findErr
+	}
+
+	for _, change := range changes {
+		if (&PasswordFieldValue{Hash: change.Hash()}).Validate(plain) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isAuthPassword checks whether the field is the auth collection "password" system field.
+func (f *PasswordField) isAuthPassword(collection *Collection) bool {
+	return collection != nil && collection.IsAuth() && f.Name == FieldNamePassword
+}
+
 // ValidateSettings implements [Field.ValidateSettings] interface method.
 func (f *PasswordField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
 	return validation.ValidateStruct(f,
@@ -213,9 +378,47 @@ func (f *PasswordField) ValidateSettings(ctx context.Context, app App, collectio
 		validation.Field(&f.Max, validation.Min(f.Min), validation.Max(71)),
 		validation.Field(&f.Cost, validation.Min(bcrypt.MinCost), validation.Max(bcrypt.MaxCost)),
 		validation.Field(&f.Pattern, validation.By(validators.IsRegex)),
+		validation.Field(&f.CharClasses, validation.Each(validation.In(list.ToInterfaceSlice(PasswordCharClasses)...))),
+		validation.Field(
+			&f.HistorySize,
+			validation.Min(0),
+			validation.Max(24),
+			validation.When(!f.isAuthPassword(collection), validation.Empty),
+		),
+		validation.Field(
+			&f.MaxAge,
+			validation.Min(0),
+			validation.Max(315360000),
+			validation.When(!f.isAuthPassword(collection), validation.Empty),
+		),
 	)
 }
 
+// HasExpired reports whether the password of the provided auth record
+// is older than the field MaxAge (if set).
+//
+// The password age is determined by its last tracked change.
+// Records without a tracked change are not considered expired
+// (their current password is tracked when the MaxAge option is enabled
+// or on the next password set).
+func (f *PasswordField) HasExpired(app App, record *Record) bool {
+	if f.MaxAge <= 0 || !f.isAuthPassword(record.Collection()) {
+		return false
+	}
+
+	changes, err := app.FindRecentPasswordChanges(record, 1)
+	if err != nil || len(changes) == 0 {
+		return false
+	}
+
+	changedAt := changes[0].Created()
+	if changedAt.IsZero() {
+		return false
+	}
+
+	return changedAt.Time().Add(time.Duration(f.MaxAge) * time.Second).Before(time.Now())
+}
+
 func (f *PasswordField) getPasswordValue(record *Record) *PasswordFieldValue {
 	raw := record.GetRaw(f.Name)
 
@@ -5,11 +5,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -124,6 +128,15 @@ func TestPasswordFieldValidateValue(t *testing.T) {
 
 	collection := core.NewBaseCollection("test_collection")
 
+	authCollection := core.NewAuthCollection("test_auth_collection")
+
+	// sha1("123456") and sha1("password")
+	breachedHashesPath := filepath.Join(t.TempDir(), "breached.txt")
+	err := os.WriteFile(breachedHashesPath, []byte("5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:10\n7C4A8D09CA3762AF61E59520943DC26494F8941B:20\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	scenarios := []struct {
 		name        string
 		field       *core.PasswordField
@@ -270,6 +283,89 @@ func TestPasswordFieldValidateValue(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"missing char class",
+			&core.PasswordField{Name: "test", CharClasses: []string{core.PasswordCharClassLower, core.PasswordCharClassDigit}},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "abc"})
+				return record
+			},
+			true,
+		},
+		{
+			"matching all char classes",
+			&core.PasswordField{Name: "test", CharClasses: core.PasswordCharClasses},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "aB1!"})
+				return record
+			},
+			false,
+		},
+		{
+			"containing identity in non-auth collection",
+			&core.PasswordField{Name: "test", DisallowIdentity: true},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.Set("email", "test@example.com")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123test456"})
+				return record
+			},
+			false,
+		},
+		{
+			"containing identity (email local part)",
+			&core.PasswordField{Name: "test", DisallowIdentity: true},
+			func() *core.Record {
+				record := core.NewRecord(authCollection)
+				record.SetEmail("test@example.com")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123TEST456"})
+				return record
+			},
+			true,
+		},
+		{
+			"not containing identity",
+			&core.PasswordField{Name: "test", DisallowIdentity: true},
+			func() *core.Record {
+				record := core.NewRecord(authCollection)
+				record.SetEmail("test@example.com")
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123tes456"})
+				return record
+			},
+			false,
+		},
+		{
+			"missing breached hashes path",
+			&core.PasswordField{Name: "test", BreachedHashesPath: filepath.Join(t.TempDir(), "missing.txt")},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123456"})
+				return record
+			},
+			true,
+		},
+		{
+			"breached password",
+			&core.PasswordField{Name: "test", BreachedHashesPath: breachedHashesPath},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "123456"})
+				return record
+			},
+			true,
+		},
+		{
+			"non-breached password",
+			&core.PasswordField{Name: "test", BreachedHashesPath: breachedHashesPath},
+			func() *core.Record {
+				record := core.NewRecord(collection)
+				record.SetRaw("test", &core.PasswordFieldValue{Plain: "1234567"})
+				return record
+			},
+			false,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -429,6 +525,40 @@ func TestPasswordFieldValidateSettings(t *testing.T) {
 			},
 			[]string{},
 		},
+		{
+			"invalid char classes",
+			func(col *core.Collection) *core.PasswordField {
+				return &core.PasswordField{
+					Id:          "test",
+					Name:        "test",
+					CharClasses: []string{core.PasswordCharClassLower, "invalid"},
+				}
+			},
+			[]string{"charClasses"},
+		},
+		{
+			"valid char classes",
+			func(col *core.Collection) *core.PasswordField {
+				return &core.PasswordField{
+					Id:          "test",
+					Name:        "test",
+					CharClasses: core.PasswordCharClasses,
+				}
+			},
+			[]string{},
+		},
+		{
+			"history size and max age for non-auth collection",
+			func(col *core.Collection) *core.PasswordField {
+				return &core.PasswordField{
+					Id:          "test",
+					Name:        core.FieldNamePassword,
+					HistorySize: 1,
+					MaxAge:      1,
+				}
+			},
+			[]string{"historySize", "maxAge"},
+		},
 	}
 
 	for _, s := range scenarios {
@@ -447,6 +577,151 @@ func TestPasswordFieldValidateSettings(t *testing.T) {
 	}
 }
 
+func TestPasswordFieldValidateSettingsAuthPolicy(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name         string
+		field        *core.PasswordField
+		expectErrors []string
+	}{
+		{
+			"non-password field",
+			&core.PasswordField{Id: "test", Name: "test", HistorySize: 1, MaxAge: 1},
+			[]string{"historySize", "maxAge"},
+		},
+		{
+			"password field with invalid values",
+			&core.PasswordField{Id: "test", Name: core.FieldNamePassword, HistorySize: 25, MaxAge: -1},
+			[]string{"historySize", "maxAge"},
+		},
+		{
+			"password field with valid values",
+			&core.PasswordField{Id: "test", Name: core.FieldNamePassword, HistorySize: 24, MaxAge: 3600},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			collection := core.NewAuthCollection("test_collection")
+
+			errs := s.field.ValidateSettings(context.Background(), app, collection)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestPasswordFieldValidateValueHistory(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	field := user.Collection().Fields.GetByName(core.FieldNamePassword).(*core.PasswordField)
+	field.HistorySize = 2
+	if err = app.Save(user.Collection()); err != nil {
+		t.Fatal(err)
+	}
+
+	// note: the current "1234567890" password is not tracked
+	for _, password := range []string{"1234567890_a", "1234567890_b", "1234567890_c"} {
+		user.SetPassword(password)
+		if err = app.Save(user); err != nil {
+			t.Fatalf("Failed to change the password to %q: %v", password, err)
+		}
+	}
+
+	scenarios := []struct {
+		password    string
+		expectError bool
+	}{
+		{"1234567890", false},   // outside of the history
+		{"1234567890_a", false}, // outside of the history
+		{"1234567890_b", true},
+		{"1234567890_c", true}, // current
+		{"1234567890_d", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.password, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			user.SetPassword(s.password)
+
+			err = field.ValidateValue(context.Background(), app, user)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestPasswordFieldHasExpired(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("disabled max age", func(t *testing.T) {
+		field := &core.PasswordField{Name: core.FieldNamePassword}
+		if field.HasExpired(app, user) {
+			t.Fatal("Expected false, got true")
+		}
+	})
+
+	t.Run("non-auth password field", func(t *testing.T) {
+		field := &core.PasswordField{Name: "test", MaxAge: 1}
+		if field.HasExpired(app, user) {
+			t.Fatal("Expected false, got true")
+		}
+	})
+
+	t.Run("no tracked password change", func(t *testing.T) {
+		field := &core.PasswordField{Name: core.FieldNamePassword, MaxAge: 1}
+		if field.HasExpired(app, user) {
+			t.Fatal("Expected false, got true")
+		}
+	})
+
+	t.Run("recent password change", func(t *testing.T) {
+		change := core.NewPasswordChange(app)
+		change.SetCollectionRef(user.Collection().Id)
+		change.SetRecordRef(user.Id)
+		change.SetHash(user.GetString("password:hash"))
+		if err := app.Save(change); err != nil {
+			t.Fatal(err)
+		}
+
+		field := &core.PasswordField{Name: core.FieldNamePassword, MaxAge: 3600}
+		if field.HasExpired(app, user) {
+			t.Fatal("Expected false, got true")
+		}
+
+		// make the change older than the max age
+		change.SetRaw("created", types.NowDateTime().Add(-2*time.Hour))
+		if err := app.SaveNoValidate(change); err != nil {
+			t.Fatal(err)
+		}
+
+		if !field.HasExpired(app, user) {
+			t.Fatal("Expected true, got false")
+		}
+	})
+}
+
 func TestPasswordFieldFindSetter(t *testing.T) {
 	scenarios := []struct {
 		name      string
@@ -0,0 +1,568 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/spf13/cast"
+)
+
+func init() {
+	Fields[FieldTypeRollup] = func() Field {
+		return &RollupField{}
+	}
+}
+
+const FieldTypeRollup = "rollup"
+
+// Supported rollup field aggregate functions.
+const (
+	RollupFunctionCount = "count"
+	RollupFunctionSum   = "sum"
+	RollupFunctionMin   = "min"
+	RollupFunctionMax   = "max"
+)
+
+var (
+	_ Field             = (*RollupField)(nil)
+	_ SetterFinder      = (*RollupField)(nil)
+	_ RecordInterceptor = (*RollupField)(nil)
+)
+
+// RollupField defines "rollup" type field, aka. a read-only number field
+// which value is an aggregate over the records of a back-relation.
+//
+// The back-relation is specified in the same format as in the filter
+// expressions - "collectionName_via_relFieldName", for example
+// a "posts" collection could have the following rollup fields:
+//
+//	{Name: "totalComments", Relation: "comments_via_post", Function: "count"}
+//	{Name: "totalLikes", Relation: "comments_via_post", Function: "sum", Field: "likes"}
+//
+// The computed value is stored in a regular column so it could be
+// filtered and sorted like any other field.
+//
+// The value is automatically recomputed every time a related record is
+// created, updated or deleted, and when the field options change.
+//
+// Note that the recompute of the related records is performed with a
+// direct db query, aka. no record hooks are triggered for them
+// (their formula fields are recomputed as well).
+type RollupField struct {
+	// Name (required) is the unique name of the field.
+	Name string `form:"name" json:"name"`
+
+	// Id is the unique stable field identifier.
+	//
+	// It is automatically generated from the name when adding to a collection FieldsList.
+	Id string `form:"id" json:"id"`
+
+	// System prevents the renaming and removal of the field.
+	System bool `form:"system" json:"system"`
+
+	// Hidden hides the field from the API response.
+	Hidden bool `form:"hidden" json:"hidden"`
+
+	// Presentable hints the Dashboard UI to use the underlying
+	// field record value in the relation preview label.
+	Presentable bool `form:"presentable" json:"presentable"`
+
+	// ---
+
+	// Relation (required) is the back-relation to aggregate
+	// in the format "collectionName_via_relFieldName".
+	Relation string `form:"relation" json:"relation"`
+
+	// Function (required) is the aggregate function to apply
+	// ("count", "sum", "min" or "max").
+	Function string `form:"function" json:"function"`
+
+	// Field is the name of the back-related collection number field to aggregate.
+	//
+	// It is required for all functions except "count".
+	Field string `form:"field" json:"field"`
+}
+
+// Type implements [Field.Type] interface method.
+func (f *RollupField) Type() string {
+	return FieldTypeRollup
+}
+
+// GetId implements [Field.GetId] interface method.
+func (f *RollupField) GetId() string {
+	return f.Id
+}
+
+// SetId implements [Field.SetId] interface method.
+func (f *RollupField) SetId(id string) {
+	f.Id = id
+}
+
+// GetName implements [Field.GetName] interface method.
+func (f *RollupField) GetName() string {
+	return f.Name
+}
+
+// SetName implements [Field.SetName] interface method.
+func (f *RollupField) SetName(name string) {
+	f.Name = name
+}
+
+// GetSystem implements [Field.GetSystem] interface method.
+func (f *RollupField) GetSystem() bool {
+	return f.System
+}
+
+// SetSystem implements [Field.SetSystem] interface method.
+func (f *RollupField) SetSystem(system bool) {
+	f.System = system
+}
+
+// GetHidden implements [Field.GetHidden] interface method.
+func (f *RollupField) GetHidden() bool {
+	return f.Hidden
+}
+
+// SetHidden implements [Field.SetHidden] interface method.
+func (f *RollupField) SetHidden(hidden bool) {
+	f.Hidden = hidden
+}
+
+// ColumnType implements [Field.ColumnType] interface method.
+func (f *RollupField) ColumnType(app App) string {
+	return "NUMERIC DEFAULT 0 NOT NULL"
+}
+
+// PrepareValue implements [Field.PrepareValue] interface method.
+func (f *RollupField) PrepareValue(record *Record, raw any) (any, error) {
+	return cast.ToFloat64(raw), nil
+}
+
+// ValidateValue implements [Field.ValidateValue] interface method.
+func (f *RollupField) ValidateValue(ctx context.Context, app App, record *Record) error {
+	return nil
+}
+
+// ValidateSettings implements [Field.ValidateSettings] interface method.
+func (f *RollupField) ValidateSettings(ctx context.Context, app App, collection *Collection) error {
+	return validation.ValidateStruct(f,
+		validation.Field(&f.Id, validation.By(DefaultFieldIdValidationRule)),
+		validation.Field(&f.Name, validation.By(DefaultFieldNameValidationRule)),
+		validation.Field(&f.Relation, validation.Required, validation.By(f.checkRelation(app, collection))),
+		validation.Field(
+			&f.Function,
+			validation.Required,
+			validation.In(RollupFunctionCount, RollupFunctionSum, RollupFunctionMin, RollupFunctionMax),
+		),
+		validation.Field(
+			&f.Field,
+			validation.When(
+				slices.Contains([]string{RollupFunctionSum, RollupFunctionMin, RollupFunctionMax}, f.Function),
+				validation.Required,
+			),
+			validation.By(f.checkField(app, collection)),
+		),
+	)
+}
+
+func (f *RollupField) checkRelation(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		relCollection, relField := f.findRelation(app, collection)
+		if relCollection == nil {
+			return validation.NewError(
+				"validation_invalid_rollup_relation",
+				`The relation must be an existing back-relation in the format "collectionName_via_relFieldName".`,
+			)
+		}
+
+		if relCollection.IsView() {
+			return validation.NewError("validation_rollup_view_relation", "View collections are not supported.")
+		}
+
+		if relField.CollectionId != collection.Id {
+			return validation.NewError(
+				"validation_invalid_rollup_relation",
+				"The back-relation field must reference the current collection.",
+			)
+		}
+
+		return nil
+	}
+}
+
+func (f *RollupField) checkField(app App, collection *Collection) validation.RuleFunc {
+	return func(value any) error {
+		v, _ := value.(string)
+		if v == "" {
+			return nil // nothing to check
+		}
+
+		relCollection, _ := f.findRelation(app, collection)
+		if relCollection == nil {
+			return nil // the relation is invalid and will be reported separately
+		}
+
+		if _, ok := relCollection.Fields.GetByName(v).(*NumberField); !ok {
+			return validation.NewError(
+				"validation_invalid_rollup_field",
+				"The field must be an existing number field of the back-related collection.",
+			)
+		}
+
+		return nil
+	}
+}
+
+// findRelation returns the back-related collection and its relation field
+// referenced by the field Relation option.
+//
+// Returns nil values if the back-relation cannot be resolved.
+func (f *RollupField) findRelation(app App, collection *Collection) (*Collection, *RelationField) {
+	parts := viaRegex.FindStringSubmatch(f.Relation)
+	if len(parts) != 3 {
+		return nil, nil
+	}
+
+	var relCollection *Collection
+	if strings.EqualFold(parts[1], collection.Name) {
+		relCollection = collection // self-reference
+	} else {
+		relCollection, _ = app.FindCachedCollectionByNameOrId(parts[1])
+		if relCollection == nil {
+			return nil, nil
+		}
+	}
+
+	relField, _ := relCollection.Fields.GetByName(parts[2]).(*RelationField)
+	if relField == nil {
+		return nil, nil
+	}
+
+	return relCollection, relField
+}
+
+// FindSetter implements the [SetterFinder] interface.
+func (f *RollupField) FindSetter(key string) SetterFunc {
+	switch key {
+	case f.Name:
+		// return noopSetter to disallow updating the value with record.Set()
+		return noopSetter
+	default:
+		return nil
+	}
+}
+
+// Intercept implements the [RecordInterceptor] interface.
+func (f *RollupField) Intercept(
+	ctx context.Context,
+	app App,
+	record *Record,
+	actionName string,
+	actionFunc func() error,
+) error {
+	switch actionName {
+	case InterceptorActionCreateExecute, InterceptorActionUpdateExecute:
+		value, err := f.Eval(app, record)
+		if err != nil {
+			return fmt.Errorf("failed to evaluate rollup field %q: %w", f.Name, err)
+		}
+
+		record.SetRaw(f.Name, value)
+
+		return actionFunc()
+	default:
+		return actionFunc()
+	}
+}
+
+// Eval computes the field aggregate for the provided record
+// (without updating the record).
+func (f *RollupField) Eval(app App, record *Record) (float64, error) {
+	var result sql.NullFloat64
+
+	err := app.DB().NewQuery("SELECT " + f.aggregateExpr("{:rollupRecordId}")).
+		Bind(dbx.Params{"rollupRecordId": record.Id}).
+		Row(&result)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.Float64, nil
+}
+
+// aggregateExpr returns the SQL expression that computes the field
+// aggregate for the record with the specified id expression.
+func (f *RollupField) aggregateExpr(recordIdExpr string) string {
+	parts := viaRegex.FindStringSubmatch(f.Relation)
+	if len(parts) != 3 {
+		return "0"
+	}
+
+	var fn string
+	if f.Function == RollupFunctionCount {
+		fn = "count(*)"
+	} else {
+		fn = f.Function + "([[__rollup." + f.Field + "]])"
+	}
+
+	return fmt.Sprintf(
+		"COALESCE((SELECT %s FROM {{%s}} [[__rollup]], %s [[__rollup_je]] WHERE [[__rollup_je.value]] = %s), 0)",
+		fn,
+		parts[1],
+		dbutils.JSONEach("__rollup."+parts[2]),
+		recordIdExpr,
+	)
+}
+
+// syncRollupFields recomputes the values of the existing newCollection records
+// for all new rollup fields and the ones with changed options.
+//
+// oldCollection could be nil in case of a newly created collection.
+func syncRollupFields(app App, newCollection *Collection, oldCollection *Collection) error {
+	var changed bool
+
+	for _, field := range newCollection.Fields {
+		f, ok := field.(*RollupField)
+		if !ok {
+			continue
+		}
+
+		if oldCollection != nil {
+			old, _ := oldCollection.Fields.GetById(f.Id).(*RollupField)
+			if old != nil && old.Relation == f.Relation && old.Function == f.Function && old.Field == f.Field {
+				continue // no change
+			}
+		}
+
+		_, err := app.DB().NewQuery(fmt.Sprintf(
+			"UPDATE {{%s}} SET [[%s]] = %s",
+			newCollection.Name,
+			f.Name,
+			f.aggregateExpr("{{"+newCollection.Name+"}}.[[id]]"),
+		)).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to compute rollup field %q - %w", f.Name, err)
+		}
+
+		changed = true
+	}
+
+	// the formula fields could depend on the rollup values
+	if changed {
+		return recomputeFormulaFields(app, newCollection, nil)
+	}
+
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+func (app *BaseApp) registerRollupHooks() {
+	app.OnRecordCreateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if len(findRelatedRollups(e.App, e.Record.Collection())) == 0 {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return syncRelatedRollups(txApp, e.Record, nil)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	app.OnRecordUpdateExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if len(findRelatedRollups(e.App, e.Record.Collection())) == 0 {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				// note: the record Original() is not used because it is not
+				// refreshed after save and may not reflect the last db state
+				old, err := txApp.FindRecordById(e.Record.Collection(), cast.ToString(e.Record.LastSavedPK()))
+				if err != nil {
+					return err
+				}
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return syncRelatedRollups(txApp, e.Record, old)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+
+	app.OnRecordDeleteExecute().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if len(findRelatedRollups(e.App, e.Record.Collection())) == 0 {
+				return e.Next()
+			}
+
+			originalApp := e.App
+			txErr := e.App.RunInTransaction(func(txApp App) error {
+				e.App = txApp
+
+				if err := e.Next(); err != nil {
+					return err
+				}
+
+				return syncRelatedRollups(txApp, e.Record, nil)
+			})
+			e.App = originalApp
+
+			return txErr
+		},
+		Priority: 99,
+	})
+}
+
+// relatedRollup describes a rollup field that aggregates over
+// a back-relation of a specific relation field.
+type relatedRollup struct {
+	relField   *RelationField
+	collection *Collection
+	rollup     *RollupField
+}
+
+// findRelatedRollups returns all rollup fields that aggregate
+// over the relation fields of the provided collection.
+func findRelatedRollups(app App, collection *Collection) []*relatedRollup {
+	var result []*relatedRollup
+
+	for _, field := range collection.Fields {
+		relField, ok := field.(*RelationField)
+		if !ok {
+			continue
+		}
+
+		relCollection, _ := app.FindCachedCollectionByNameOrId(relField.CollectionId)
+		if relCollection == nil {
+			continue
+		}
+
+		for _, rawRollup := range relCollection.Fields {
+			rollup, ok := rawRollup.(*RollupField)
+			if !ok {
+				continue
+			}
+
+			parts := viaRegex.FindStringSubmatch(rollup.Relation)
+			if len(parts) == 3 && strings.EqualFold(parts[1], collection.Name) && parts[2] == relField.Name {
+				result = append(result, &relatedRollup{relField, relCollection, rollup})
+			}
+		}
+	}
+
+	return result
+}
+
+// syncRelatedRollups recomputes the rollup fields (and the formula fields
+// depending on them) of the records referenced by the relation fields of the changed record.
+//
+// old is the record state before the change and it is used to
+// recompute also the previously referenced records (could be nil).
+func syncRelatedRollups(app App, record *Record, old *Record) error {
+	for _, r := range findRelatedRollups(app, record.Collection()) {
+		ids := record.GetStringSlice(r.relField.Name)
+
+		if old != nil {
+			oldIds := old.GetStringSlice(r.relField.Name)
+
+			if slices.Equal(oldIds, ids) &&
+				(r.rollup.Function == RollupFunctionCount || old.Get(r.rollup.Field) == record.Get(r.rollup.Field)) {
+				continue // no change
+			}
+
+			ids = list.ToUniqueStringSlice(append(ids, oldIds...))
+		}
+
+		if len(ids) == 0 {
+			continue
+		}
+
+		where := dbx.In("id", list.ToInterfaceSlice(ids)...)
+
+		_, err := app.DB().Update(
+			r.collection.Name,
+			dbx.Params{r.rollup.Name: dbx.NewExp(r.rollup.aggregateExpr("{{" + r.collection.Name + "}}.[[id]]"))},
+			where,
+		).Execute()
+		if err != nil {
+			return fmt.Errorf("failed to recompute the related rollup field %q: %w", r.rollup.Name, err)
+		}
+
+		err = recomputeFormulaFields(app, r.collection, where)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findRollupReferences returns all rollup fields (grouped by their collection)
+// that aggregate over a back-relation of the provided collection.
+//
+// If the provided collection has a rollup field referencing itself then
+// it will be also included in the result. To exclude it, pass the
+// collection id as the excludeIds argument.
+func findRollupReferences(app App, collection *Collection, excludeIds ...string) (map[*Collection][]*RollupField, error) {
+	collections, err := app.FindAllCollections()
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[*Collection][]*RollupField{}
+
+	for _, c := range collections {
+		if slices.Contains(excludeIds, c.Id) {
+			continue
+		}
+
+		for _, field := range c.Fields {
+			rollup, ok := field.(*RollupField)
+			if !ok {
+				continue
+			}
+
+			parts := viaRegex.FindStringSubmatch(rollup.Relation)
+			if len(parts) == 3 && strings.EqualFold(parts[1], collection.Name) {
+				result[c] = append(result[c], rollup)
+			}
+		}
+	}
+
+	return result, nil
+}
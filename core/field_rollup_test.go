@@ -0,0 +1,555 @@
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestRollupFieldBaseMethods(t *testing.T) {
+	testFieldBaseMethods(t, core.FieldTypeRollup)
+}
+
+func TestRollupFieldColumnType(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	f := &core.RollupField{}
+
+	expected := "NUMERIC DEFAULT 0 NOT NULL"
+
+	if v := f.ColumnType(app); v != expected {
+		t.Fatalf("Expected\n%q\ngot\n%q", expected, v)
+	}
+}
+
+func TestRollupFieldPrepareValue(t *testing.T) {
+	f := &core.RollupField{}
+	record := core.NewRecord(core.NewBaseCollection("test"))
+
+	scenarios := []struct {
+		raw      any
+		expected float64
+	}{
+		{nil, 0},
+		{"", 0},
+		{"abc", 0},
+		{"12.5", 12.5},
+		{3, 3},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.raw), func(t *testing.T) {
+			v, err := f.PrepareValue(record, s.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if v != s.expected {
+				t.Fatalf("Expected %v, got %#v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestRollupFieldValidateSettings(t *testing.T) {
+	testDefaultFieldIdValidation(t, core.FieldTypeRollup)
+	testDefaultFieldNameValidation(t, core.FieldTypeRollup)
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, comments := createRollupTestCollections(t, app)
+
+	scenarios := []struct {
+		name         string
+		field        func() *core.RollupField
+		expectErrors []string
+	}{
+		{
+			"zero",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test"}
+			},
+			[]string{"relation", "function"},
+		},
+		{
+			"invalid relation format",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "comments.post", Function: "count"}
+			},
+			[]string{"relation"},
+		},
+		{
+			"missing back-relation collection",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "missing_via_post", Function: "count"}
+			},
+			[]string{"relation"},
+		},
+		{
+			"non-relation back-relation field",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_likes", Function: "count"}
+			},
+			[]string{"relation"},
+		},
+		{
+			"back-relation field referencing another collection",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_author", Function: "count"}
+			},
+			[]string{"relation"},
+		},
+		{
+			"invalid function",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_post", Function: "avg"}
+			},
+			[]string{"function"},
+		},
+		{
+			"missing field for non-count function",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_post", Function: "sum"}
+			},
+			[]string{"field"},
+		},
+		{
+			"non-number field",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_post", Function: "sum", Field: "message"}
+			},
+			[]string{"field"},
+		},
+		{
+			"valid count",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_post", Function: "count"}
+			},
+			[]string{},
+		},
+		{
+			"valid sum",
+			func() *core.RollupField {
+				return &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_post", Function: "sum", Field: "likes"}
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := s.field().ValidateSettings(context.Background(), app, posts)
+
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+
+	// self-reference
+	comments.Fields.Add(&core.RelationField{Name: "parent", CollectionId: comments.Id, MaxSelect: 1})
+	if err := app.Save(comments); err != nil {
+		t.Fatal(err)
+	}
+	field := &core.RollupField{Id: "test", Name: "test", Relation: "rollup_comments_via_parent", Function: "count"}
+	tests.TestValidationErrors(t, field.ValidateSettings(context.Background(), app, comments), []string{})
+}
+
+func TestRollupFieldFindSetter(t *testing.T) {
+	field := &core.RollupField{Name: "test"}
+
+	collection := core.NewBaseCollection("test_collection")
+	collection.Fields.Add(field)
+
+	record := core.NewRecord(collection)
+	record.SetRaw("test", 1)
+
+	t.Run("no matching setter", func(t *testing.T) {
+		f := field.FindSetter("abc")
+		if f != nil {
+			t.Fatal("Expected nil setter")
+		}
+	})
+
+	t.Run("matching setter", func(t *testing.T) {
+		f := field.FindSetter("test")
+		if f == nil {
+			t.Fatal("Expected non-nil setter")
+		}
+
+		f(record, 123) // should be ignored
+
+		if v := record.GetInt("test"); v != 1 {
+			t.Fatalf("Expected no value change, got %v", v)
+		}
+	})
+}
+
+func TestRollupFieldSync(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, comments := createRollupTestCollections(t, app)
+
+	newPost := func() *core.Record {
+		post := core.NewRecord(posts)
+		if err := app.Save(post); err != nil {
+			t.Fatal(err)
+		}
+		return post
+	}
+
+	newComment := func(postId string, likes int) *core.Record {
+		comment := core.NewRecord(comments)
+		comment.Set("post", postId)
+		comment.Set("likes", likes)
+		if err := app.Save(comment); err != nil {
+			t.Fatal(err)
+		}
+		return comment
+	}
+
+	post1 := newPost()
+	post2 := newPost()
+	comment1 := newComment(post1.Id, 3)
+	newComment(post1.Id, 5)
+
+	// existing records backfill
+	posts.Fields.Add(
+		&core.RollupField{Name: "total", Relation: "rollup_comments_via_post", Function: "count"},
+		&core.RollupField{Name: "likes", Relation: "rollup_comments_via_post", Function: "sum", Field: "likes"},
+		&core.RollupField{Name: "maxLikes", Relation: "rollup_comments_via_post", Function: "max", Field: "likes"},
+	)
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	assertValues := func(t *testing.T, postId string, total, likes, maxLikes float64) {
+		t.Helper()
+
+		post, err := app.FindRecordById(posts, postId)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := post.GetFloat("total"); v != total {
+			t.Fatalf("Expected total %v, got %v", total, v)
+		}
+
+		if v := post.GetFloat("likes"); v != likes {
+			t.Fatalf("Expected likes %v, got %v", likes, v)
+		}
+
+		if v := post.GetFloat("maxLikes"); v != maxLikes {
+			t.Fatalf("Expected maxLikes %v, got %v", maxLikes, v)
+		}
+	}
+
+	t.Run("backfill", func(t *testing.T) {
+		assertValues(t, post1.Id, 2, 8, 5)
+		assertValues(t, post2.Id, 0, 0, 0)
+	})
+
+	t.Run("create related record", func(t *testing.T) {
+		newComment(post2.Id, 10)
+
+		assertValues(t, post1.Id, 2, 8, 5)
+		assertValues(t, post2.Id, 1, 10, 10)
+	})
+
+	t.Run("update related record aggregated field", func(t *testing.T) {
+		comment1.Set("likes", 7)
+		if err := app.Save(comment1); err != nil {
+			t.Fatal(err)
+		}
+
+		assertValues(t, post1.Id, 2, 12, 7)
+	})
+
+	t.Run("move related record", func(t *testing.T) {
+		comment1.Set("post", post2.Id)
+		if err := app.Save(comment1); err != nil {
+			t.Fatal(err)
+		}
+
+		assertValues(t, post1.Id, 1, 5, 5)
+		assertValues(t, post2.Id, 2, 17, 10)
+	})
+
+	t.Run("delete related record", func(t *testing.T) {
+		if err := app.Delete(comment1); err != nil {
+			t.Fatal(err)
+		}
+
+		assertValues(t, post2.Id, 1, 10, 10)
+	})
+
+	t.Run("ignore submitted value", func(t *testing.T) {
+		post, err := app.FindRecordById(posts, post1.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		post.Set("total", 100)
+		if err := app.Save(post); err != nil {
+			t.Fatal(err)
+		}
+
+		assertValues(t, post1.Id, 1, 5, 5)
+	})
+
+	t.Run("filter and sort", func(t *testing.T) {
+		newComment(post2.Id, 1)
+
+		records, err := app.FindRecordsByFilter(posts, "total > 1", "-likes", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Id != post2.Id {
+			t.Fatalf("Expected only record %q, got %v", post2.Id, records)
+		}
+
+		records, err = app.FindRecordsByFilter(posts, "", "likes", 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 2 || records[0].Id != post1.Id {
+			t.Fatalf("Expected record %q to be first, got %v", post1.Id, records)
+		}
+	})
+}
+
+func TestRollupFieldMultipleRelation(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, _ := createRollupTestCollections(t, app)
+
+	tags := core.NewBaseCollection("rollup_tags")
+	tags.Fields.Add(&core.RelationField{Name: "posts", CollectionId: posts.Id, MaxSelect: 5})
+	if err := app.Save(tags); err != nil {
+		t.Fatal(err)
+	}
+
+	posts.Fields.Add(&core.RollupField{Name: "totalTags", Relation: "rollup_tags_via_posts", Function: "count"})
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	post1 := core.NewRecord(posts)
+	post2 := core.NewRecord(posts)
+	for _, p := range []*core.Record{post1, post2} {
+		if err := app.Save(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, ids := range [][]string{{post1.Id, post2.Id}, {post1.Id}} {
+		tag := core.NewRecord(tags)
+		tag.Set("posts", ids)
+		if err := app.Save(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for id, expected := range map[string]int{post1.Id: 2, post2.Id: 1} {
+		post, err := app.FindRecordById(posts, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if v := post.GetInt("totalTags"); v != expected {
+			t.Fatalf("Expected %q totalTags %d, got %d", id, expected, v)
+		}
+	}
+}
+
+func TestRollupFieldDependentFormula(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, comments := createRollupTestCollections(t, app)
+
+	posts.Fields.Add(
+		&core.RollupField{Name: "likes", Relation: "rollup_comments_via_post", Function: "sum", Field: "likes"},
+		&core.FormulaField{Name: "doubleLikes", Expression: "likes * 2", ResultType: core.FormulaResultNumber},
+		&core.FormulaField{Name: "label", Expression: "'likes: ' || doubleLikes"},
+	)
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	post := core.NewRecord(posts)
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	comment := core.NewRecord(comments)
+	comment.Set("post", post.Id)
+	comment.Set("likes", 3)
+	if err := app.Save(comment); err != nil {
+		t.Fatal(err)
+	}
+
+	post, err := app.FindRecordById(posts, post.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := post.GetInt("doubleLikes"); v != 6 {
+		t.Fatalf("Expected doubleLikes 6, got %d", v)
+	}
+
+	if v := post.GetString("label"); v != "likes: 6" {
+		t.Fatalf("Expected label %q, got %q", "likes: 6", v)
+	}
+}
+
+func TestRollupFieldSyncError(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	posts, comments := createRollupTestCollections(t, app)
+
+	posts.Fields.Add(&core.RollupField{Name: "total", Relation: "rollup_comments_via_post", Function: "count"})
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	post := core.NewRecord(posts)
+	if err := app.Save(post); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate a rollup sync failure
+	if _, err := app.DB().NewQuery("ALTER TABLE {{rollup_posts}} DROP COLUMN [[total]]").Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	comment := core.NewRecord(comments)
+	comment.Set("post", post.Id)
+	if err := app.Save(comment); err == nil {
+		t.Fatal("Expected the related record save to fail")
+	}
+
+	total, err := app.CountRecords(comments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 0 {
+		t.Fatalf("Expected the related record create to be rolled back, found %d records", total)
+	}
+}
+
+func TestRollupFieldReferencesValidation(t *testing.T) {
+	scenarios := []struct {
+		name   string
+		change func(app core.App, comments *core.Collection) error
+	}{
+		{
+			"rename the back-relation collection",
+			func(app core.App, comments *core.Collection) error {
+				comments.Name = "rollup_comments_new"
+				return app.Save(comments)
+			},
+		},
+		{
+			"rename the back-relation field",
+			func(app core.App, comments *core.Collection) error {
+				comments.Fields.GetByName("post").SetName("post_new")
+				return app.Save(comments)
+			},
+		},
+		{
+			"remove the back-relation field",
+			func(app core.App, comments *core.Collection) error {
+				comments.Fields.RemoveByName("post")
+				return app.Save(comments)
+			},
+		},
+		{
+			"rename the aggregated field",
+			func(app core.App, comments *core.Collection) error {
+				comments.Fields.GetByName("likes").SetName("likes_new")
+				return app.Save(comments)
+			},
+		},
+		{
+			"remove the aggregated field",
+			func(app core.App, comments *core.Collection) error {
+				comments.Fields.RemoveByName("likes")
+				return app.Save(comments)
+			},
+		},
+		{
+			"delete the back-relation collection",
+			func(app core.App, comments *core.Collection) error {
+				return app.Delete(comments)
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			app, _ := tests.NewTestApp()
+			defer app.Cleanup()
+
+			posts, comments := createRollupTestCollections(t, app)
+
+			posts.Fields.Add(&core.RollupField{Name: "likes", Relation: "rollup_comments_via_post", Function: "sum", Field: "likes"})
+			if err := app.Save(posts); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.change(app, comments); err == nil {
+				t.Fatal("Expected the rollup references change to fail")
+			}
+		})
+	}
+
+	t.Run("change unrelated fields", func(t *testing.T) {
+		app, _ := tests.NewTestApp()
+		defer app.Cleanup()
+
+		posts, comments := createRollupTestCollections(t, app)
+
+		posts.Fields.Add(&core.RollupField{Name: "total", Relation: "rollup_comments_via_post", Function: "count"})
+		if err := app.Save(posts); err != nil {
+			t.Fatal(err)
+		}
+
+		comments.Fields.RemoveByName("likes")
+		comments.Fields.GetByName("message").SetName("message_new")
+		if err := app.Save(comments); err != nil {
+			t.Fatalf("Expected the unrelated fields change to succeed, got %v", err)
+		}
+	})
+}
+
+func createRollupTestCollections(t *testing.T, app core.App) (*core.Collection, *core.Collection) {
+	posts := core.NewBaseCollection("rollup_posts")
+	if err := app.Save(posts); err != nil {
+		t.Fatal(err)
+	}
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	comments := core.NewBaseCollection("rollup_comments")
+	comments.Fields.Add(
+		&core.RelationField{Name: "post", CollectionId: posts.Id, MaxSelect: 1},
+		&core.RelationField{Name: "author", CollectionId: users.Id, MaxSelect: 1},
+		&core.NumberField{Name: "likes"},
+		&core.TextField{Name: "message"},
+	)
+	if err := app.Save(comments); err != nil {
+		t.Fatal(err)
+	}
+
+	return posts, comments
+}
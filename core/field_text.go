@@ -29,6 +29,7 @@ var (
 	_ Field             = (*TextField)(nil)
 	_ SetterFinder      = (*TextField)(nil)
 	_ RecordInterceptor = (*TextField)(nil)
+	_ FullTextIndexer   = (*TextField)(nil)
 )
 
 // TextField defines "text" type field for storing any string value.
@@ -91,6 +92,10 @@ type TextField struct {
 	//
 	// A single collection can have only 1 field marked as primary key.
 	PrimaryKey bool `form:"primaryKey" json:"primaryKey"`
+
+	// FullText includes the field in the collection full-text search index
+	// allowing it to be queried with the match() filter function.
+	FullText bool `form:"fullText" json:"fullText"`
 }
 
 // Type implements [Field.Type] interface method.
@@ -138,6 +143,11 @@ func (f *TextField) SetHidden(hidden bool) {
 	f.Hidden = hidden
 }
 
+// IsFullText implements [FullTextIndexer] interface method.
+func (f *TextField) IsFullText() bool {
+	return f.FullText
+}
+
 // ColumnType implements [Field.ColumnType] interface method.
 func (f *TextField) ColumnType(app App) string {
 	if f.PrimaryKey {
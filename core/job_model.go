@@ -0,0 +1,55 @@
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+var (
+	_ Model = (*Job)(nil)
+)
+
+const JobsTableName = "_jobs"
+
+// The supported job statuses.
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusFailed    = "failed"
+	JobStatusCanceled  = "canceled"
+)
+
+// Job defines a single persistent background job stored in the auxiliary database.
+//
+// Jobs are created with [Jobs.Enqueue] and processed by the handler
+// registered with the same name via [Jobs.Add].
+type Job struct {
+	BaseModel
+
+	Name        string         `db:"name" json:"name"`
+	Queue       string         `db:"queue" json:"queue"`
+	Payload     types.JSONRaw  `db:"payload" json:"payload"`
+	Status      string         `db:"status" json:"status"`
+	Attempts    int            `db:"attempts" json:"attempts"`
+	MaxAttempts int            `db:"maxAttempts" json:"maxAttempts"`
+	UniqueKey   string         `db:"uniqueKey" json:"uniqueKey"`
+	LastError   string         `db:"lastError" json:"lastError"`
+	RunAt       types.DateTime `db:"runAt" json:"runAt"`
+	Created     types.DateTime `db:"created" json:"created"`
+	Updated     types.DateTime `db:"updated" json:"updated"`
+}
+
+func (m *Job) TableName() string {
+	return JobsTableName
+}
+
+// UnmarshalPayload unmarshals the job JSON payload into the provided result.
+func (m *Job) UnmarshalPayload(result any) error {
+	if len(m.Payload) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(m.Payload, result)
+}
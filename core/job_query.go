@@ -0,0 +1,45 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// JobQuery returns a new Job select query.
+func (app *BaseApp) JobQuery() *dbx.SelectQuery {
+	return app.AuxModelQuery(&Job{})
+}
+
+// FindJobById finds a single Job entry by its id.
+func (app *BaseApp) FindJobById(id string) (*Job, error) {
+	model := &Job{}
+
+	err := app.JobQuery().
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(model)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// DeleteOldJobs deletes all finished (completed, failed or canceled)
+// jobs that were last updated before updatedBefore.
+//
+// For better performance the jobs delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteOldJobs(updatedBefore time.Time) error {
+	formattedDate := updatedBefore.UTC().Format(types.DefaultDateLayout)
+
+	_, err := app.auxNonconcurrentDB.Delete(JobsTableName, dbx.And(
+		dbx.In("status", JobStatusCompleted, JobStatusFailed, JobStatusCanceled),
+		dbx.NewExp("[[updated]] <= {:date}", dbx.Params{"date": formattedDate}),
+	)).Execute()
+
+	return err
+}
@@ -0,0 +1,117 @@
+package core_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestFindJobById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	job, err := app.Jobs().Enqueue("test", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{job.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindJobById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result.Id != s.id {
+				t.Fatalf("Expected result with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestDeleteOldJobs(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	statuses := []string{
+		core.JobStatusPending,
+		core.JobStatusRunning,
+		core.JobStatusCompleted,
+		core.JobStatusFailed,
+		core.JobStatusCanceled,
+	}
+
+	old := types.NowDateTime().Add(-2 * time.Hour).String()
+
+	ids := map[string]string{}
+	for _, status := range statuses {
+		job, err := app.Jobs().Enqueue("test_"+status, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = app.AuxNonconcurrentDB().Update(
+			core.JobsTableName,
+			dbx.Params{"status": status, "updated": old},
+			dbx.HashExp{"id": job.Id},
+		).Execute()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ids[status] = job.Id
+	}
+
+	// recent finished job
+	recent, err := app.Jobs().Enqueue("test_recent", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = app.AuxNonconcurrentDB().Update(
+		core.JobsTableName,
+		dbx.Params{"status": core.JobStatusCompleted},
+		dbx.HashExp{"id": recent.Id},
+	).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DeleteOldJobs(time.Now().Add(-1 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedExisting := map[string]bool{
+		ids[core.JobStatusPending]:   true,
+		ids[core.JobStatusRunning]:   true,
+		ids[core.JobStatusCompleted]: false,
+		ids[core.JobStatusFailed]:    false,
+		ids[core.JobStatusCanceled]:  false,
+		recent.Id:                    true,
+	}
+
+	for id, exists := range expectedExisting {
+		_, err := app.FindJobById(id)
+		if (err == nil) != exists {
+			t.Errorf("Expected job %q to exist %v, got error %v", id, exists, err)
+		}
+	}
+}
@@ -0,0 +1,647 @@
+package core
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/list"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const (
+	// DefaultJobsQueue is the name of the queue used when [JobOptions.Queue] is not set.
+	DefaultJobsQueue = "default"
+
+	// DefaultJobsConcurrency is the default max number of concurrently running jobs per queue.
+	DefaultJobsConcurrency = 5
+
+	// DefaultJobMaxAttempts is the default max number of runs of a single failing job.
+	DefaultJobMaxAttempts = 3
+)
+
+const (
+	jobsPollInterval      = 1 * time.Second
+	jobsDispatchBatchSize = 100
+	jobsMaxAge            = 7 * 24 * time.Hour
+	jobBaseRetryDelay     = 10 * time.Second
+	jobMaxRetryDelay      = 1 * time.Hour
+	jobMaxErrorLength     = 1000
+)
+
+// JobHandler defines a function for processing a single job.
+type JobHandler func(e *JobEvent) error
+
+// JobEvent defines the argument passed to a [JobHandler].
+type JobEvent struct {
+	// Context is canceled when the job is canceled or when the jobs worker pool is stopped.
+	Context context.Context
+
+	App App
+	Job *Job
+}
+
+// JobOptions defines the optional settings of a new job.
+type JobOptions struct {
+	// Queue is the name of the queue in which the job will be run
+	// (see [Jobs.SetConcurrency]).
+	//
+	// Default to [DefaultJobsQueue].
+	Queue string
+
+	// Delay postpones the first job run with the specified duration.
+	Delay time.Duration
+
+	// MaxAttempts is the max number of job runs before marking it as failed.
+	//
+	// The failed runs are retried with exponential backoff (10s, 20s, 40s, ... up to 1h).
+	//
+	// Default to [DefaultJobMaxAttempts].
+	MaxAttempts int
+
+	// UniqueKey prevents enqueueing the same job more than once.
+	//
+	// If there is already a pending or running job with the same name
+	// and unique key, Enqueue will return the existing job.
+	UniqueKey string
+}
+
+// Jobs defines a persistent background jobs queue stored in the
+// auxiliary database and processed by a pool of workers.
+//
+// Example:
+//
+//	app.Jobs().Add("sendReport", func(e *core.JobEvent) error {
+//		var payload struct{ Email string }
+//		if err := e.Job.UnmarshalPayload(&payload); err != nil {
+//			return err
+//		}
+//		// ...
+//		return nil
+//	})
+//
+//	app.Jobs().Enqueue("sendReport", map[string]any{"email": "test@example.com"}, &core.JobOptions{
+//		Delay: 1 * time.Minute,
+//	})
+//
+// The worker pool is started automatically on app serve.
+type Jobs struct {
+	app *BaseApp
+
+	mu          sync.Mutex
+	handlers    map[string]JobHandler
+	concurrency map[string]int
+	running     map[string]int
+	cancels     map[string]context.CancelFunc
+	stop        context.CancelFunc
+	wg          sync.WaitGroup
+	wakeup      chan struct{}
+}
+
+func newJobs(app *BaseApp) *Jobs {
+	return &Jobs{
+		app:         app,
+		handlers:    map[string]JobHandler{},
+		concurrency: map[string]int{},
+		running:     map[string]int{},
+		cancels:     map[string]context.CancelFunc{},
+		wakeup:      make(chan struct{}, 1),
+	}
+}
+
+// Add registers the handler of the jobs with the specified name.
+//
+// If there is already a handler with the same name, it will be replaced.
+//
+// Note that only the jobs with registered handler are processed
+// (the others remain pending until a handler is registered).
+func (j *Jobs) Add(name string, handler JobHandler) {
+	j.mu.Lock()
+	j.handlers[name] = handler
+	j.mu.Unlock()
+
+	j.notify()
+}
+
+// Remove unregisters the handler of the jobs with the specified name (if any).
+func (j *Jobs) Remove(name string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	delete(j.handlers, name)
+}
+
+// SetConcurrency changes the max number of concurrently running jobs of the specified queue.
+//
+// Zero or negative limit resets the queue concurrency to [DefaultJobsConcurrency].
+func (j *Jobs) SetConcurrency(queue string, limit int) {
+	j.mu.Lock()
+	if limit <= 0 {
+		delete(j.concurrency, queue)
+	} else {
+		j.concurrency[queue] = limit
+	}
+	j.mu.Unlock()
+
+	j.notify()
+}
+
+// Enqueue persists a new pending job with the specified name and JSON serializable payload.
+//
+// opts could be nil, in which case the job will be run as soon as possible
+// in the [DefaultJobsQueue] queue.
+func (j *Jobs) Enqueue(name string, payload any, opts *JobOptions) (*Job, error) {
+	if name == "" {
+		return nil, errors.New("missing job name")
+	}
+
+	if opts == nil {
+		opts = &JobOptions{}
+	}
+
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize the job payload: %w", err)
+	}
+
+	now := types.NowDateTime()
+
+	job := &Job{
+		Name:        name,
+		Queue:       opts.Queue,
+		Payload:     rawPayload,
+		Status:      JobStatusPending,
+		MaxAttempts: opts.MaxAttempts,
+		UniqueKey:   opts.UniqueKey,
+		RunAt:       now.Add(opts.Delay),
+		Created:     now,
+		Updated:     now,
+	}
+	job.Id = GenerateDefaultRandomId()
+
+	if job.Queue == "" {
+		job.Queue = DefaultJobsQueue
+	}
+
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultJobMaxAttempts
+	}
+
+	err = j.app.AuxRunInTransaction(func(txApp App) error {
+		if job.UniqueKey != "" {
+			existing := &Job{}
+
+			err := txApp.JobQuery().
+				AndWhere(dbx.HashExp{"name": job.Name, "uniqueKey": job.UniqueKey}).
+				AndWhere(dbx.In("status", JobStatusPending, JobStatusRunning)).
+				Limit(1).
+				One(existing)
+			if err == nil {
+				job = existing
+				return nil
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+
+		return txApp.AuxSaveNoValidate(job)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	j.notify()
+
+	return job, nil
+}
+
+// Retry resets the attempts of a failed or canceled job
+// and schedules it to run again as soon as possible.
+//
+// A canceled job couldn't be retried until its running attempt (if any) returns.
+func (j *Jobs) Retry(id string) (*Job, error) {
+	// note: the canceled/failed jobs can't be claimed again so there is
+	// no risk of a new running attempt between the check and the update
+	j.mu.Lock()
+	_, isRunning := j.cancels[id]
+	j.mu.Unlock()
+
+	if isRunning {
+		return nil, errors.New("the job is still running, try again after its handler returns")
+	}
+
+	result, err := j.app.AuxNonconcurrentDB().Update(
+		JobsTableName,
+		dbx.Params{
+			"status":   JobStatusPending,
+			"attempts": 0,
+			"runAt":    types.NowDateTime().String(),
+			"updated":  types.NowDateTime().String(),
+		},
+		dbx.And(
+			dbx.HashExp{"id": id},
+			dbx.In("status", JobStatusFailed, JobStatusCanceled),
+		),
+	).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, errors.New("only failed or canceled jobs can be retried")
+	}
+
+	j.notify()
+
+	return j.app.FindJobById(id)
+}
+
+// Cancel cancels a pending or running job.
+//
+// The context of a running job is canceled and its handler
+// is expected to return as soon as possible.
+func (j *Jobs) Cancel(id string) (*Job, error) {
+	result, err := j.app.AuxNonconcurrentDB().Update(
+		JobsTableName,
+		dbx.Params{
+			"status":  JobStatusCanceled,
+			"updated": types.NowDateTime().String(),
+		},
+		dbx.And(
+			dbx.HashExp{"id": id},
+			dbx.In("status", JobStatusPending, JobStatusRunning),
+		),
+	).Execute()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, errors.New("only pending or running jobs can be canceled")
+	}
+
+	j.mu.Lock()
+	if cancel, ok := j.cancels[id]; ok {
+		cancel()
+	}
+	j.mu.Unlock()
+
+	return j.app.FindJobById(id)
+}
+
+// HasStarted checks whether the jobs worker pool has started.
+func (j *Jobs) HasStarted() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.stop != nil
+}
+
+// Start starts the jobs worker pool (if not already).
+//
+// It is called automatically on app serve.
+func (j *Jobs) Start() {
+	j.mu.Lock()
+	if j.stop != nil {
+		j.mu.Unlock()
+		return
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	j.stop = stop
+	j.mu.Unlock()
+
+	// the auxiliary db is local to the app instance so any
+	// previously running job was interrupted by a crash or restart
+	_, err := j.app.AuxNonconcurrentDB().Update(
+		JobsTableName,
+		dbx.Params{
+			"status":  JobStatusPending,
+			"updated": types.NowDateTime().String(),
+		},
+		dbx.HashExp{"status": JobStatusRunning},
+	).Execute()
+	if err != nil {
+		j.app.Logger().Warn("Failed to requeue the interrupted jobs", "error", err)
+	}
+
+	j.wg.Add(1)
+	go j.loop(ctx)
+}
+
+// Stop stops the jobs worker pool and waits for the running jobs to return.
+//
+// The context of the running jobs is canceled and if their handlers return
+// with an error, the jobs are returned to the queue without consuming an attempt.
+func (j *Jobs) Stop() {
+	j.mu.Lock()
+	stop := j.stop
+	j.stop = nil
+	j.mu.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	stop()
+
+	j.wg.Wait()
+}
+
+// notify wakes up the worker pool to check for new due jobs.
+func (j *Jobs) notify() {
+	select {
+	case j.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+func (j *Jobs) loop(ctx context.Context) {
+	defer j.wg.Done()
+
+	ticker := time.NewTicker(jobsPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := j.dispatch(ctx); err != nil && ctx.Err() == nil {
+			j.app.Logger().Warn("Failed to dispatch jobs", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-j.wakeup:
+		}
+	}
+}
+
+// dispatch claims and starts the due pending jobs that have a
+// registered handler and a free slot in their queue.
+func (j *Jobs) dispatch(ctx context.Context) error {
+	if !j.app.IsBootstrapped() {
+		return nil
+	}
+
+	j.mu.Lock()
+	names := make([]string, 0, len(j.handlers))
+	for name := range j.handlers {
+		names = append(names, name)
+	}
+	j.mu.Unlock()
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	jobs := []*Job{}
+
+	err := j.app.JobQuery().
+		AndWhere(dbx.HashExp{"status": JobStatusPending}).
+		AndWhere(dbx.In("name", list.ToInterfaceSlice(names)...)).
+		AndWhere(dbx.NewExp("[[runAt]] <= {:now}", dbx.Params{"now": types.NowDateTime().String()})).
+		OrderBy("runAt ASC", "rowid ASC").
+		Limit(jobsDispatchBatchSize).
+		All(&jobs)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		handler, jobCtx := j.reserve(ctx, job)
+		if handler == nil {
+			continue // the queue is full, the handler was removed or the job is still running
+		}
+
+		claimed, err := j.claim(job)
+		if err != nil || !claimed {
+			j.release(job)
+
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		j.run(ctx, jobCtx, handler, job)
+	}
+
+	return nil
+}
+
+// reserve occupies a slot in the job queue and returns the job handler
+// together with its cancelable context (or nil if the queue is full,
+// there is no handler or a previous attempt of the job is still running).
+func (j *Jobs) reserve(poolCtx context.Context, job *Job) (JobHandler, context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	handler := j.handlers[job.Name]
+	if handler == nil {
+		return nil, nil
+	}
+
+	// ex. canceled and retried while the previous attempt handler hasn't returned yet
+	if _, ok := j.cancels[job.Id]; ok {
+		return nil, nil
+	}
+
+	limit := j.concurrency[job.Queue]
+	if limit <= 0 {
+		limit = DefaultJobsConcurrency
+	}
+
+	if j.running[job.Queue] >= limit {
+		return nil, nil
+	}
+
+	j.running[job.Queue]++
+
+	ctx, cancel := context.WithCancel(poolCtx)
+	j.cancels[job.Id] = cancel
+
+	return handler, ctx
+}
+
+// release frees the previously reserved job queue slot and context.
+func (j *Jobs) release(job *Job) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.running[job.Queue]--
+	if j.running[job.Queue] <= 0 {
+		delete(j.running, job.Queue)
+	}
+
+	if cancel, ok := j.cancels[job.Id]; ok {
+		cancel()
+		delete(j.cancels, job.Id)
+	}
+}
+
+// claim atomically marks the pending job as running
+// so that it couldn't be picked by another dispatch.
+func (j *Jobs) claim(job *Job) (bool, error) {
+	now := types.NowDateTime()
+
+	result, err := j.app.AuxNonconcurrentDB().Update(
+		JobsTableName,
+		dbx.Params{
+			"status":   JobStatusRunning,
+			"attempts": job.Attempts + 1,
+			"updated":  now.String(),
+		},
+		dbx.HashExp{"id": job.Id, "status": JobStatusPending},
+	).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return false, nil
+	}
+
+	job.Status = JobStatusRunning
+	job.Attempts++
+	job.Updated = now
+
+	return true, nil
+}
+
+func (j *Jobs) run(poolCtx context.Context, ctx context.Context, handler JobHandler, job *Job) {
+	j.wg.Add(1)
+	go func() {
+		defer j.wg.Done()
+
+		start := time.Now()
+
+		err := execJobHandler(handler, &JobEvent{Context: ctx, App: j.app, Job: job})
+
+		execTime := float64(time.Since(start)) / float64(time.Millisecond)
+
+		if err := j.finish(ctx, poolCtx, job, err, execTime); err != nil {
+			j.app.Logger().Warn("Failed to update the job status", "error", err, "jobId", job.Id)
+		}
+
+		// release after persisting the result to prevent picking the job again in the meantime
+		j.release(job)
+
+		j.notify()
+	}()
+}
+
+// finish persists the result of the running job.
+//
+// The update is skipped if the job is no longer running (ex. it was canceled).
+func (j *Jobs) finish(ctx context.Context, poolCtx context.Context, job *Job, jobErr error, execTime float64) error {
+	params := dbx.Params{"updated": types.NowDateTime().String()}
+
+	switch {
+	case jobErr == nil:
+		params["status"] = JobStatusCompleted
+		params["lastError"] = ""
+	case poolCtx.Err() != nil:
+		// the worker pool was stopped - return the job to the queue without consuming an attempt
+		params["status"] = JobStatusPending
+		params["attempts"] = job.Attempts - 1
+		params["runAt"] = types.NowDateTime().String()
+	case ctx.Err() != nil:
+		return nil // canceled
+	default:
+		lastError := []rune(jobErr.Error())
+		if len(lastError) > jobMaxErrorLength {
+			lastError = lastError[:jobMaxErrorLength]
+		}
+		params["lastError"] = string(lastError)
+
+		if job.Attempts >= job.MaxAttempts {
+			params["status"] = JobStatusFailed
+		} else {
+			params["status"] = JobStatusPending
+			params["runAt"] = types.NowDateTime().Add(jobRetryDelay(job.Attempts)).String()
+		}
+
+		j.app.Logger().Warn(
+			"Job failed",
+			slog.String("type", "job"),
+			slog.Float64("execTime", execTime),
+			slog.String("jobId", job.Id),
+			slog.String("name", job.Name),
+			slog.String("queue", job.Queue),
+			slog.Int("attempt", job.Attempts),
+			slog.String("error", jobErr.Error()),
+		)
+	}
+
+	_, err := j.app.AuxNonconcurrentDB().Update(
+		JobsTableName,
+		params,
+		dbx.HashExp{"id": job.Id, "status": JobStatusRunning},
+	).Execute()
+
+	return err
+}
+
+// execJobHandler calls the job handler and converts its panic (if any) to an error.
+func execJobHandler(handler JobHandler, e *JobEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panic: %v", r)
+		}
+	}()
+
+	return handler(e)
+}
+
+// jobRetryDelay returns the exponential backoff delay after the specified failed attempt
+// (10s, 20s, 40s, ... up to 1h).
+func jobRetryDelay(attempt int) time.Duration {
+	delay := jobBaseRetryDelay
+	for i := 1; i < attempt && delay < jobMaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, jobMaxRetryDelay)
+}
+
+func (app *BaseApp) registerJobsHooks() {
+	app.OnServe().Bind(&hook.Handler[*ServeEvent]{
+		Id: "__pbJobsStart__",
+		Func: func(e *ServeEvent) error {
+			app.Jobs().Start()
+
+			return e.Next()
+		},
+		Priority: 999,
+	})
+
+	// stop the workers before the logger flush and the db connections close
+	app.OnTerminate().Bind(&hook.Handler[*TerminateEvent]{
+		Id: "__pbJobsOnTerminate__",
+		Func: func(e *TerminateEvent) error {
+			app.Jobs().Stop()
+
+			return e.Next()
+		},
+		Priority: -1000,
+	})
+
+	// run every hour to cleanup the old finished jobs
+	app.Cron().Add("__pbJobsCleanup__", "0 * * * *", func() {
+		if err := app.DeleteOldJobs(time.Now().Add(-jobsMaxAge)); err != nil {
+			app.Logger().Warn("Failed to delete old jobs", "error", err)
+		}
+	})
+}
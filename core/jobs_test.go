@@ -0,0 +1,518 @@
+package core_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestJobsEnqueue(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	if _, err := app.Jobs().Enqueue("", nil, nil); err == nil {
+		t.Fatal("Expected error for missing job name")
+	}
+
+	t.Run("default options", func(t *testing.T) {
+		job, err := app.Jobs().Enqueue("test", map[string]any{"a": 123}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stored, err := app.FindJobById(job.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if stored.Name != "test" {
+			t.Fatalf("Expected name %q, got %q", "test", stored.Name)
+		}
+		if stored.Queue != core.DefaultJobsQueue {
+			t.Fatalf("Expected queue %q, got %q", core.DefaultJobsQueue, stored.Queue)
+		}
+		if stored.Status != core.JobStatusPending {
+			t.Fatalf("Expected status %q, got %q", core.JobStatusPending, stored.Status)
+		}
+		if stored.MaxAttempts != core.DefaultJobMaxAttempts {
+			t.Fatalf("Expected maxAttempts %d, got %d", core.DefaultJobMaxAttempts, stored.MaxAttempts)
+		}
+		if stored.RunAt.Time().After(time.Now()) {
+			t.Fatalf("Expected runAt to be in the past, got %v", stored.RunAt)
+		}
+
+		payload := map[string]int{}
+		if err := stored.UnmarshalPayload(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload["a"] != 123 {
+			t.Fatalf("Expected payload a=123, got %v", payload)
+		}
+	})
+
+	t.Run("custom options", func(t *testing.T) {
+		job, err := app.Jobs().Enqueue("test", nil, &core.JobOptions{
+			Queue:       "custom",
+			Delay:       1 * time.Hour,
+			MaxAttempts: 10,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stored, err := app.FindJobById(job.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if stored.Queue != "custom" {
+			t.Fatalf("Expected queue %q, got %q", "custom", stored.Queue)
+		}
+		if stored.MaxAttempts != 10 {
+			t.Fatalf("Expected maxAttempts %d, got %d", 10, stored.MaxAttempts)
+		}
+		if min := time.Now().Add(50 * time.Minute); stored.RunAt.Time().Before(min) {
+			t.Fatalf("Expected runAt to be after %v, got %v", min, stored.RunAt)
+		}
+	})
+
+	t.Run("unique key", func(t *testing.T) {
+		opts := &core.JobOptions{UniqueKey: "abc"}
+
+		job1, err := app.Jobs().Enqueue("unique", nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job2, err := app.Jobs().Enqueue("unique", nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job1.Id != job2.Id {
+			t.Fatalf("Expected the existing job %q to be returned, got %q", job1.Id, job2.Id)
+		}
+
+		// different name
+		job3, err := app.Jobs().Enqueue("unique2", nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job3.Id == job1.Id {
+			t.Fatal("Expected a new job to be created for a different name")
+		}
+
+		// no longer active
+		if _, err := app.Jobs().Cancel(job1.Id); err != nil {
+			t.Fatal(err)
+		}
+		job4, err := app.Jobs().Enqueue("unique", nil, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job4.Id == job1.Id {
+			t.Fatal("Expected a new job to be created after the previous one was canceled")
+		}
+	})
+}
+
+func TestJobsProcess(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	received := make(chan string, 1)
+
+	app.Jobs().Add("test", func(e *core.JobEvent) error {
+		payload := map[string]string{}
+		if err := e.Job.UnmarshalPayload(&payload); err != nil {
+			return err
+		}
+
+		received <- payload["message"]
+
+		return nil
+	})
+
+	app.Jobs().Start()
+	defer app.Jobs().Stop()
+
+	if !app.Jobs().HasStarted() {
+		t.Fatal("Expected the worker pool to be started")
+	}
+
+	job, err := app.Jobs().Enqueue("test", map[string]any{"message": "hello"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case message := <-received:
+		if message != "hello" {
+			t.Fatalf("Expected message %q, got %q", "hello", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The job was not processed")
+	}
+
+	job = waitJobStatus(t, app, job.Id, core.JobStatusCompleted)
+	if job.Attempts != 1 {
+		t.Fatalf("Expected 1 attempt, got %d", job.Attempts)
+	}
+}
+
+func TestJobsDelayAndMissingHandler(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	var calls atomic.Int32
+
+	app.Jobs().Add("test", func(e *core.JobEvent) error {
+		calls.Add(1)
+		return nil
+	})
+
+	delayed, err := app.Jobs().Enqueue("test", nil, &core.JobOptions{Delay: 1 * time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unknown, err := app.Jobs().Enqueue("unknown", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Jobs().Start()
+
+	// wait for at least one dispatch
+	time.Sleep(300 * time.Millisecond)
+
+	app.Jobs().Stop()
+
+	if calls.Load() != 0 {
+		t.Fatalf("Expected the delayed job to not be processed, got %d calls", calls.Load())
+	}
+
+	for _, id := range []string{delayed.Id, unknown.Id} {
+		job, err := app.FindJobById(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != core.JobStatusPending || job.Attempts != 0 {
+			t.Fatalf("Expected job %q to be still pending, got %q (attempts %d)", id, job.Status, job.Attempts)
+		}
+	}
+}
+
+func TestJobsFailure(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	app.Jobs().Add("error", func(e *core.JobEvent) error {
+		return errors.New("test_error")
+	})
+
+	app.Jobs().Add("panic", func(e *core.JobEvent) error {
+		panic("test_panic")
+	})
+
+	app.Jobs().Start()
+	defer app.Jobs().Stop()
+
+	t.Run("retry with backoff", func(t *testing.T) {
+		job, err := app.Jobs().Enqueue("error", nil, &core.JobOptions{MaxAttempts: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job = waitJob(t, app, job.Id, func(job *core.Job) bool {
+			return job.Attempts == 1 && job.Status == core.JobStatusPending
+		})
+
+		if job.LastError != "test_error" {
+			t.Fatalf("Expected lastError %q, got %q", "test_error", job.LastError)
+		}
+
+		if min := types.NowDateTime().Add(5 * time.Second); job.RunAt.Time().Before(min.Time()) {
+			t.Fatalf("Expected the next run to be after %v, got %v", min, job.RunAt)
+		}
+	})
+
+	t.Run("max attempts", func(t *testing.T) {
+		job, err := app.Jobs().Enqueue("error", nil, &core.JobOptions{MaxAttempts: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job = waitJobStatus(t, app, job.Id, core.JobStatusFailed)
+		if job.Attempts != 1 {
+			t.Fatalf("Expected 1 attempt, got %d", job.Attempts)
+		}
+	})
+
+	t.Run("panic", func(t *testing.T) {
+		job, err := app.Jobs().Enqueue("panic", nil, &core.JobOptions{MaxAttempts: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		job = waitJobStatus(t, app, job.Id, core.JobStatusFailed)
+		if job.LastError != "job handler panic: test_panic" {
+			t.Fatalf("Expected the panic error, got %q", job.LastError)
+		}
+	})
+}
+
+func TestJobsConcurrency(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	var mu sync.Mutex
+	var running, maxRunning int
+
+	app.Jobs().SetConcurrency("limited", 2)
+
+	app.Jobs().Add("test", func(e *core.JobEvent) error {
+		mu.Lock()
+		running++
+		maxRunning = max(maxRunning, running)
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return nil
+	})
+
+	ids := make([]string, 6)
+	for i := range ids {
+		job, err := app.Jobs().Enqueue("test", nil, &core.JobOptions{Queue: "limited"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = job.Id
+	}
+
+	app.Jobs().Start()
+	defer app.Jobs().Stop()
+
+	for _, id := range ids {
+		waitJobStatus(t, app, id, core.JobStatusCompleted)
+	}
+
+	if maxRunning != 2 {
+		t.Fatalf("Expected max 2 concurrently running jobs, got %d", maxRunning)
+	}
+}
+
+func TestJobsCancelAndRetry(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	started := make(chan struct{}, 1)
+	canceled := make(chan struct{}, 1)
+	unblock := make(chan struct{})
+
+	app.Jobs().Add("blocking", func(e *core.JobEvent) error {
+		started <- struct{}{}
+		<-e.Context.Done()
+		canceled <- struct{}{}
+		<-unblock
+		return e.Context.Err()
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		job, err := app.Jobs().Enqueue("pending", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := app.Jobs().Retry(job.Id); err == nil {
+			t.Fatal("Expected pending jobs to be not retryable")
+		}
+
+		job, err = app.Jobs().Cancel(job.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != core.JobStatusCanceled {
+			t.Fatalf("Expected status %q, got %q", core.JobStatusCanceled, job.Status)
+		}
+
+		if _, err := app.Jobs().Cancel(job.Id); err == nil {
+			t.Fatal("Expected already canceled jobs to be not cancelable")
+		}
+
+		job, err = app.Jobs().Retry(job.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != core.JobStatusPending || job.Attempts != 0 {
+			t.Fatalf("Expected pending job with 0 attempts, got %q (attempts %d)", job.Status, job.Attempts)
+		}
+	})
+
+	t.Run("running", func(t *testing.T) {
+		app.Jobs().Start()
+		defer app.Jobs().Stop()
+
+		job, err := app.Jobs().Enqueue("blocking", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("The job was not started")
+		}
+
+		if _, err := app.Jobs().Cancel(job.Id); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("The job context was not canceled")
+		}
+
+		// the canceled handler hasn't returned yet
+		if _, err := app.Jobs().Retry(job.Id); err == nil {
+			t.Fatal("Expected the still running canceled job to be not retryable")
+		}
+
+		close(unblock)
+
+		// give some time to the worker to finish the job
+		time.Sleep(100 * time.Millisecond)
+
+		job, err = app.FindJobById(job.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Status != core.JobStatusCanceled {
+			t.Fatalf("Expected status %q, got %q", core.JobStatusCanceled, job.Status)
+		}
+
+		// retryable once the handler returns
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			job, err = app.Jobs().Retry(job.Id)
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal(err)
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		select {
+		case <-started:
+		case <-time.After(5 * time.Second):
+			t.Fatal("The retried job was not started")
+		}
+
+		if _, err := app.Jobs().Cancel(job.Id); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case <-canceled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("The retried job context was not canceled")
+		}
+	})
+}
+
+func TestJobsStop(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	started := make(chan struct{}, 1)
+
+	app.Jobs().Add("blocking", func(e *core.JobEvent) error {
+		started <- struct{}{}
+		<-e.Context.Done()
+		return e.Context.Err()
+	})
+
+	app.Jobs().Start()
+
+	job, err := app.Jobs().Enqueue("blocking", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("The job was not started")
+	}
+
+	app.Jobs().Stop()
+
+	if app.Jobs().HasStarted() {
+		t.Fatal("Expected the worker pool to be stopped")
+	}
+
+	job, err = app.FindJobById(job.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job.Status != core.JobStatusPending || job.Attempts != 0 {
+		t.Fatalf("Expected the interrupted job to be returned to the queue, got %q (attempts %d)", job.Status, job.Attempts)
+	}
+}
+
+func waitJobStatus(t *testing.T, app core.App, id string, status string) *core.Job {
+	return waitJob(t, app, id, func(job *core.Job) bool {
+		return job.Status == status
+	})
+}
+
+func waitJob(t *testing.T, app core.App, id string, check func(job *core.Job) bool) *core.Job {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		job, err := app.FindJobById(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if check(job) {
+			return job
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for job %q (status %q, attempts %d)", id, job.Status, job.Attempts)
+		}
+
+		time.Sleep(20 * time.Millisecond)
+	}
+}
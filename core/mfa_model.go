@@ -13,6 +13,9 @@ const (
 	MFAMethodPassword = "password"
 	MFAMethodOAuth2   = "oauth2"
 	MFAMethodOTP      = "otp"
+	MFAMethodTOTP     = "totp"
+	MFAMethodWebAuthn = "webauthn"
+	MFAMethodSAML     = "saml"
 )
 
 const CollectionNameMFAs = "_mfas"
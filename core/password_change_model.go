@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNamePasswordChanges = "_passwordChanges"
+
+var (
+	_ Model        = (*PasswordChange)(nil)
+	_ PreValidator = (*PasswordChange)(nil)
+	_ RecordProxy  = (*PasswordChange)(nil)
+)
+
+// PasswordChange defines a Record proxy for working with the passwordChanges collection.
+//
+// Each PasswordChange model stores the bcrypt hash of a single auth record password
+// and it is used for enforcing the password field history and max age policies.
+type PasswordChange struct {
+	*Record
+}
+
+// NewPasswordChange instantiates and returns a new blank *PasswordChange model.
+//
+// Example usage:
+//
+//	change := core.NewPasswordChange(app)
+//	change.SetRecordRef(user.Id)
+//	change.SetCollectionRef(user.Collection().Id)
+//	change.SetHash(user.GetString("password:hash"))
+//	app.Save(change)
+func NewPasswordChange(app App) *PasswordChange {
+	m := &PasswordChange{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNamePasswordChanges)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on PasswordChange.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *PasswordChange) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNamePasswordChanges {
+		return errors.New("missing or invalid PasswordChange ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *PasswordChange) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *PasswordChange) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *PasswordChange) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *PasswordChange) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *PasswordChange) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *PasswordChange) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Hash returns the "hash" record field value (aka. the password bcrypt hash).
+func (m *PasswordChange) Hash() string {
+	return m.GetString("hash")
+}
+
+// SetHash updates the "hash" record field value.
+func (m *PasswordChange) SetHash(hash string) {
+	m.Set("hash", hash)
+}
+
+// Created returns the "created" record field value
+// (aka. the date when the password was changed).
+func (m *PasswordChange) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+func (app *BaseApp) registerPasswordChangeHooks() {
+	recordRefHooks[*PasswordChange](app, CollectionNamePasswordChanges, CollectionTypeAuth)
+
+	trackPasswordChange := func(e *RecordEvent) error {
+		if !e.Record.Collection().IsAuth() {
+			return e.Next()
+		}
+
+		field, ok := e.Record.Collection().Fields.GetByName(FieldNamePassword).(*PasswordField)
+		if !ok || (field.HistorySize <= 0 && field.MaxAge <= 0) {
+			return e.Next()
+		}
+
+		// the plain password is available only until the record is persisted
+		// (aka. when a new password was assigned)
+		changed := e.Record.GetString(FieldNamePassword) != ""
+
+		err := e.Next()
+		if err != nil || !changed {
+			return err
+		}
+
+		hash := e.Record.GetString(FieldNamePassword + ":hash")
+		if hash == "" {
+			return nil
+		}
+
+		err = trackPasswordChange(e.App, e.Record, hash, max(field.HistorySize, 1))
+		if err != nil {
+			e.App.Logger().Warn(
+				"Failed to track the auth record password change",
+				"error", err,
+				"recordId", e.Record.Id,
+				"collectionId", e.Record.Collection().Id,
+			)
+		}
+
+		return nil
+	}
+
+	app.OnRecordCreate().Bind(&hook.Handler[*RecordEvent]{
+		Func:     trackPasswordChange,
+		Priority: 99,
+	})
+
+	app.OnRecordUpdate().Bind(&hook.Handler[*RecordEvent]{
+		Func:     trackPasswordChange,
+		Priority: 99,
+	})
+
+	// track the current password of the existing auth records when
+	// the password max age is enabled so that their age starts from now
+	app.OnCollectionUpdateExecute().BindFunc(func(e *CollectionEvent) error {
+		if !e.Collection.IsAuth() || passwordMaxAge(e.Collection) <= 0 {
+			return e.Next()
+		}
+
+		// note: load the old collection from the db because the
+		// cached one could have been modified in place
+		oldCollection, err := e.App.FindCollectionByNameOrId(e.Collection.Id)
+		if err != nil || passwordMaxAge(oldCollection) > 0 {
+			return e.Next()
+		}
+
+		originalApp := e.App
+		txErr := e.App.RunInTransaction(func(txApp App) error {
+			e.App = txApp
+
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			return trackInitialPasswordChanges(txApp, e.Collection)
+		})
+		e.App = originalApp
+
+		return txErr
+	})
+}
+
+// passwordMaxAge returns the auth collection password field MaxAge option
+// (or 0 if the collection doesn't have a valid password field).
+func passwordMaxAge(collection *Collection) int64 {
+	field, ok := collection.Fields.GetByName(FieldNamePassword).(*PasswordField)
+	if !ok {
+		return 0
+	}
+
+	return field.MaxAge
+}
@@ -0,0 +1,316 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewPasswordChange(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	change := core.NewPasswordChange(app)
+
+	if change.Collection().Name != core.CollectionNamePasswordChanges {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNamePasswordChanges, change.Collection().Name)
+	}
+}
+
+func TestPasswordChangeProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	change := core.PasswordChange{}
+	change.SetProxyRecord(record)
+
+	if change.ProxyRecord() == nil || change.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, change.ProxyRecord())
+	}
+}
+
+func TestPasswordChangeStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	change := core.NewPasswordChange(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", change.SetCollectionRef, change.CollectionRef},
+		{"recordRef", change.SetRecordRef, change.RecordRef},
+		{"hash", change.SetHash, change.Hash},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := change.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestPasswordChangePreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	changesCol, err := app.FindCollectionByNameOrId(core.CollectionNamePasswordChanges)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		change := &core.PasswordChange{}
+
+		if err := app.Validate(change); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-PasswordChange collection", func(t *testing.T) {
+		change := &core.PasswordChange{}
+		change.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		change.SetRecordRef(user.Id)
+		change.SetCollectionRef(user.Collection().Id)
+		change.SetHash("test")
+
+		if err := app.Validate(change); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("PasswordChange collection", func(t *testing.T) {
+		change := &core.PasswordChange{}
+		change.SetProxyRecord(core.NewRecord(changesCol))
+		change.SetRecordRef(user.Id)
+		change.SetCollectionRef(user.Collection().Id)
+		change.SetHash("test")
+
+		if err := app.Validate(change); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestPasswordChangeValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		change       func() *core.PasswordChange
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.PasswordChange {
+				return core.NewPasswordChange(app)
+			},
+			[]string{"collectionRef", "recordRef", "hash"},
+		},
+		{
+			"non-auth collection",
+			func() *core.PasswordChange {
+				change := core.NewPasswordChange(app)
+				change.SetCollectionRef(demo1.Collection().Id)
+				change.SetRecordRef(demo1.Id)
+				change.SetHash("test")
+				return change
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.PasswordChange {
+				change := core.NewPasswordChange(app)
+				change.SetCollectionRef(user.Collection().Id)
+				change.SetRecordRef("missing")
+				change.SetHash("test")
+				return change
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.PasswordChange {
+				change := core.NewPasswordChange(app)
+				change.SetCollectionRef(user.Collection().Id)
+				change.SetRecordRef(user.Id)
+				change.SetHash("test")
+				return change
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.change())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestPasswordChangeTrackHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertChanges := func(t *testing.T, expected int) {
+		t.Helper()
+
+		changes, err := app.FindRecentPasswordChanges(user, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(changes) != expected {
+			t.Fatalf("Expected %d password changes, got %d", expected, len(changes))
+		}
+
+		if expected > 0 && changes[0].Hash() != user.GetString("password:hash") {
+			t.Fatalf("Expected the latest change to store the current password hash")
+		}
+	}
+
+	// disabled history and max age
+	user.SetPassword("1234567890_a")
+	if err = app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+	assertChanges(t, 0)
+
+	field := user.Collection().Fields.GetByName(core.FieldNamePassword).(*core.PasswordField)
+	field.HistorySize = 2
+	if err = app.Save(user.Collection()); err != nil {
+		t.Fatal(err)
+	}
+
+	// no password change
+	user.Set("name", "new_name")
+	if err = app.Save(user); err != nil {
+		t.Fatal(err)
+	}
+	assertChanges(t, 0)
+
+	for i, password := range []string{"1234567890_b", "1234567890_c", "1234567890_d"} {
+		user.SetPassword(password)
+		if err = app.Save(user); err != nil {
+			t.Fatal(err)
+		}
+		assertChanges(t, min(i+1, 2))
+	}
+}
+
+func TestPasswordChangeMaxAgeEnableHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totalUsers, err := app.CountRecords(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// existing tracked change
+	change := core.NewPasswordChange(app)
+	change.SetCollectionRef(users.Id)
+	change.SetRecordRef(user.Id)
+	change.SetHash("old_hash")
+	if err = app.Save(change); err != nil {
+		t.Fatal(err)
+	}
+
+	countChanges := func() int {
+		total, err := app.CountRecords(core.CollectionNamePasswordChanges, dbx.HashExp{"collectionRef": users.Id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return int(total)
+	}
+
+	field := users.Fields.GetByName(core.FieldNamePassword).(*core.PasswordField)
+	field.MaxAge = 3600
+	if err = app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := countChanges(); v != int(totalUsers) {
+		t.Fatalf("Expected %d password changes, got %d", totalUsers, v)
+	}
+
+	changes, err := app.FindRecentPasswordChanges(user, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Hash() != "old_hash" {
+		t.Fatalf("Expected the existing tracked change to remain unchanged, got %v", changes)
+	}
+
+	// already enabled max age
+	field.MaxAge = 7200
+	if err = app.Save(users); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := countChanges(); v != int(totalUsers) {
+		t.Fatalf("Expected %d password changes after the max age change, got %d", totalUsers, v)
+	}
+}
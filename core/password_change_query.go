@@ -0,0 +1,107 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// FindRecentPasswordChanges returns the latest limit PasswordChange models
+// of the provided auth record ordered by their created date DESC.
+//
+// If limit is <= 0, all PasswordChange models of the auth record are returned.
+func (app *BaseApp) FindRecentPasswordChanges(authRecord *Record, limit int) ([]*PasswordChange, error) {
+	result := []*PasswordChange{}
+
+	query := app.RecordQuery(CollectionNamePasswordChanges).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC", "rowid DESC")
+
+	if limit > 0 {
+		query.Limit(int64(limit))
+	}
+
+	err := query.All(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteAllPasswordChangesByRecord deletes all PasswordChange models associated with the provided record.
+func (app *BaseApp) DeleteAllPasswordChangesByRecord(authRecord *Record) error {
+	changes, err := app.FindRecentPasswordChanges(authRecord, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if err := app.Delete(change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// trackPasswordChange stores the new password hash of the auth record
+// and deletes its older PasswordChange models keeping only the latest keep ones.
+func trackPasswordChange(app App, authRecord *Record, hash string, keep int) error {
+	return app.RunInTransaction(func(txApp App) error {
+		change := NewPasswordChange(txApp)
+		change.SetCollectionRef(authRecord.Collection().Id)
+		change.SetRecordRef(authRecord.Id)
+		change.SetHash(hash)
+		if err := txApp.Save(change); err != nil {
+			return err
+		}
+
+		changes, err := txApp.FindRecentPasswordChanges(authRecord, 0)
+		if err != nil {
+			return err
+		}
+
+		for i := keep; i < len(changes); i++ {
+			if err := txApp.Delete(changes[i]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// trackInitialPasswordChanges stores the current password hash of
+// all auth collection records that don't have a tracked password change yet.
+func trackInitialPasswordChanges(app App, collection *Collection) error {
+	records := []*Record{}
+
+	err := app.RecordQuery(collection).
+		AndWhere(dbx.NewExp(
+			"[["+collection.Name+".id]] NOT IN (SELECT [[recordRef]] FROM {{"+CollectionNamePasswordChanges+"}} WHERE [[collectionRef]] = {:collectionId})",
+			dbx.Params{"collectionId": collection.Id},
+		)).
+		All(&records)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		hash := record.GetString(FieldNamePassword + ":hash")
+		if hash == "" {
+			continue
+		}
+
+		change := NewPasswordChange(app)
+		change.SetCollectionRef(collection.Id)
+		change.SetRecordRef(record.Id)
+		change.SetHash(hash)
+		if err := app.Save(change); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
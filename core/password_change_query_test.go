@@ -0,0 +1,126 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// stubPasswordChanges creates 3 password changes for test@example.com
+// and 1 password change for test2@example.com.
+func stubPasswordChanges(t *testing.T, app core.App) {
+	items := []struct {
+		email string
+		hash  string
+	}{
+		{"test@example.com", "hash1"},
+		{"test@example.com", "hash2"},
+		{"test@example.com", "hash3"},
+		{"test2@example.com", "hash4"},
+	}
+
+	for _, item := range items {
+		user, err := app.FindAuthRecordByEmail("users", item.email)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		change := core.NewPasswordChange(app)
+		change.SetCollectionRef(user.Collection().Id)
+		change.SetRecordRef(user.Id)
+		change.SetHash(item.hash)
+		if err := app.Save(change); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindRecentPasswordChanges(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubPasswordChanges(t, app)
+
+	scenarios := []struct {
+		email    string
+		limit    int
+		expected []string
+	}{
+		{"test@example.com", 0, []string{"hash3", "hash2", "hash1"}},
+		{"test@example.com", -1, []string{"hash3", "hash2", "hash1"}},
+		{"test@example.com", 2, []string{"hash3", "hash2"}},
+		{"test2@example.com", 2, []string{"hash4"}},
+		{"test3@example.com", 0, []string{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.email, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.FindRecentPasswordChanges(user, s.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected %d changes, got %d", len(s.expected), len(result))
+			}
+
+			for i, change := range result {
+				if change.Hash() != s.expected[i] {
+					t.Fatalf("Expected change %d with hash %q, got %q", i, s.expected[i], change.Hash())
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteAllPasswordChangesByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubPasswordChanges(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.DeleteAllPasswordChangesByRecord(user1); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected int
+	}{
+		{user1, 0},
+		{user2, 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Email(), func(t *testing.T) {
+			result, err := app.FindRecentPasswordChanges(s.record, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != s.expected {
+				t.Fatalf("Expected %d changes, got %d", s.expected, len(result))
+			}
+		})
+	}
+}
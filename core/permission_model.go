@@ -0,0 +1,97 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNamePermissions = "_permissions"
+
+var (
+	_ Model        = (*Permission)(nil)
+	_ PreValidator = (*Permission)(nil)
+	_ RecordProxy  = (*Permission)(nil)
+)
+
+// Permission defines a Record proxy for working with the permissions collection.
+//
+// A permission is a named action (ex. "posts.update") that could be granted
+// to the auth records through roles and checked in the API rules with the
+// `@request.auth.can("posts.update")` filter function.
+type Permission struct {
+	*Record
+}
+
+// NewPermission instantiates and returns a new blank *Permission model.
+//
+// Example usage:
+//
+//	permission := core.NewPermission(app)
+//	permission.SetName("posts.update")
+//	app.Save(permission)
+func NewPermission(app App) *Permission {
+	m := &Permission{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNamePermissions)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on Permission.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *Permission) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNamePermissions {
+		return errors.New("missing or invalid Permission ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *Permission) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *Permission) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// Name returns the "name" record field value.
+func (m *Permission) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *Permission) SetName(name string) {
+	m.Set("name", name)
+}
+
+// Description returns the "description" record field value.
+func (m *Permission) Description() string {
+	return m.GetString("description")
+}
+
+// SetDescription updates the "description" record field value.
+func (m *Permission) SetDescription(description string) {
+	m.Set("description", description)
+}
+
+// Created returns the "created" record field value.
+func (m *Permission) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *Permission) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
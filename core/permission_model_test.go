@@ -0,0 +1,152 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewPermission(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	permission := core.NewPermission(app)
+
+	if permission.Collection().Name != core.CollectionNamePermissions {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNamePermissions, permission.Collection().Name)
+	}
+}
+
+func TestPermissionProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	permission := core.Permission{}
+	permission.SetProxyRecord(record)
+
+	if permission.ProxyRecord() == nil || permission.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, permission.ProxyRecord())
+	}
+}
+
+func TestPermissionStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	permission := core.NewPermission(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"name", permission.SetName, permission.Name},
+		{"description", permission.SetDescription, permission.Description},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := permission.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestPermissionPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	permissionsCol, err := app.FindCollectionByNameOrId(core.CollectionNamePermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		permission := &core.Permission{}
+
+		if err := app.Validate(permission); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-Permission collection", func(t *testing.T) {
+		permission := &core.Permission{}
+		permission.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		permission.SetName("posts.update")
+
+		if err := app.Validate(permission); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("Permission collection", func(t *testing.T) {
+		permission := &core.Permission{}
+		permission.SetProxyRecord(core.NewRecord(permissionsCol))
+		permission.SetName("posts.update")
+
+		if err := app.Validate(permission); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestPermissionValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	existing := core.NewPermission(app)
+	existing.SetName("posts.delete")
+	if err := app.Save(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		permission   string
+		expectErrors []string
+	}{
+		{"empty", "", []string{"name"}},
+		{"invalid characters", "posts update", []string{"name"}},
+		{"valid name", "posts:update-all_1", []string{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			permission := core.NewPermission(app)
+			permission.SetName(s.permission)
+
+			errs := app.Validate(permission)
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+
+	t.Run("duplicated name", func(t *testing.T) {
+		permission := core.NewPermission(app)
+		permission.SetName("posts.delete")
+
+		if err := app.Save(permission); err == nil {
+			t.Fatal("Expected unique name error")
+		}
+	})
+}
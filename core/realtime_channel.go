@@ -0,0 +1,77 @@
+package core
+
+import (
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// realtimeChannelTable is the name of the single row CTE that is
+// used as base table when evaluating the realtime channel rules.
+const realtimeChannelTable = "_realtimeChannel"
+
+// newRealtimeChannelFieldResolver creates a new field resolver for
+// the realtime channel rules that allows only the @request.* and
+// @collection.* fields (aka. there is no base record to filter).
+func newRealtimeChannelFieldResolver(app App, requestInfo *RequestInfo) *RecordFieldResolver {
+	resolver := NewRecordFieldResolver(app, NewBaseCollection(realtimeChannelTable), requestInfo, true)
+
+	resolver.SetAllowedFields(slices.DeleteFunc(resolver.AllowedFields(), func(pattern string) bool {
+		return !strings.HasPrefix(pattern, `^\@`)
+	}))
+
+	return resolver
+}
+
+// CanAccessRealtimeChannel checks if the specified requestInfo
+// satisfies a realtime channel subscribe or publish accessRule.
+//
+// Similar to CanAccessRecord, the rule checks are ignored in case
+// requestInfo.Auth is a superuser, nil rule means "superusers only"
+// and empty string rule means "everyone".
+//
+// Only the @request.* and @collection.* fields are allowed in the rule.
+//
+// The method always return false on invalid rule or db query error.
+func (app *BaseApp) CanAccessRealtimeChannel(requestInfo *RequestInfo, accessRule *string) (bool, error) {
+	// superusers can access everything
+	if requestInfo.HasSuperuserAuth() {
+		return true, nil
+	}
+
+	// only superusers can access the channel
+	if accessRule == nil {
+		return false, nil
+	}
+
+	// empty public rule, aka. everyone can access
+	if *accessRule == "" {
+		return true, nil
+	}
+
+	resolver := newRealtimeChannelFieldResolver(app, requestInfo)
+	expr, err := search.FilterData(*accessRule).BuildExpr(resolver)
+	if err != nil {
+		return false, err
+	}
+
+	query := app.ConcurrentDB().Select("(1)").From(realtimeChannelTable).AndWhere(expr).Limit(1)
+	resolver.UpdateQuery(query)
+
+	built := query.Build()
+
+	var exists int
+
+	// note: the CTE is used so that the base table could be referenced also in the multi-match subqueries
+	err = app.ConcurrentDB().NewQuery(
+		"WITH {{" + realtimeChannelTable + "}} AS (SELECT '' AS [[id]]) " + built.SQL(),
+	).Bind(built.Params()).Row(&exists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	return exists > 0, nil
+}
@@ -0,0 +1,141 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestCanAccessRealtimeChannel(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		requestInfo *core.RequestInfo
+		rule        *string
+		expected    bool
+		expectError bool
+	}{
+		{
+			"as superuser with nil rule",
+			&core.RequestInfo{Auth: superuser},
+			nil,
+			true,
+			false,
+		},
+		{
+			"as superuser with invalid rule",
+			&core.RequestInfo{Auth: superuser},
+			types.Pointer("id = ''"), // the filter rule should be ignored
+			true,
+			false,
+		},
+		{
+			"as guest with nil rule",
+			&core.RequestInfo{},
+			nil,
+			false,
+			false,
+		},
+		{
+			"as guest with empty rule",
+			&core.RequestInfo{},
+			types.Pointer(""),
+			true,
+			false,
+		},
+		{
+			"as guest with non-@request field rule",
+			&core.RequestInfo{},
+			types.Pointer("id = ''"),
+			false,
+			true,
+		},
+		{
+			"as guest with auth only rule",
+			&core.RequestInfo{},
+			types.Pointer("@request.auth.id != ''"),
+			false,
+			false,
+		},
+		{
+			"as user with auth only rule",
+			&core.RequestInfo{Auth: user},
+			types.Pointer("@request.auth.id != ''"),
+			true,
+			false,
+		},
+		{
+			"as user with auth relation rule",
+			&core.RequestInfo{Auth: user},
+			types.Pointer("@request.auth.rel.title = 'test'"),
+			false,
+			false,
+		},
+		{
+			"with matching body rule",
+			&core.RequestInfo{Body: map[string]any{"channel": "chat/a", "data": map[string]any{"text": "hello"}}},
+			types.Pointer("@request.body.channel = 'chat/a' && @request.body.data.text = 'hello'"),
+			true,
+			false,
+		},
+		{
+			"with non-matching body rule",
+			&core.RequestInfo{Body: map[string]any{"channel": "chat/a", "data": map[string]any{"text": "hello"}}},
+			types.Pointer("@request.body.channel = 'chat/b'"),
+			false,
+			false,
+		},
+		{
+			"with matching @collection rule",
+			&core.RequestInfo{Auth: user},
+			types.Pointer("@collection.demo1.text ?= 'test' && @request.auth.email ?= @collection.users.email"),
+			true,
+			false,
+		},
+		{
+			"with non-matching @collection rule (multi-match)",
+			&core.RequestInfo{Auth: user},
+			types.Pointer("@collection.demo1.text = 'test'"),
+			false,
+			false,
+		},
+		{
+			"with non-matching @collection rule",
+			&core.RequestInfo{Auth: user},
+			types.Pointer("@collection.demo1.text ?= 'missing'"),
+			false,
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := app.CanAccessRealtimeChannel(s.requestInfo, s.rule)
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
 	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
@@ -23,8 +24,12 @@ const (
 	lowerModifier  string = "lower"
 )
 
-// ensure that `search.FieldResolver` interface is implemented
-var _ search.FieldResolver = (*RecordFieldResolver)(nil)
+// ensure that `search.FieldResolver`, `search.FullTextResolver` and `search.PermissionResolver` interfaces are implemented
+var (
+	_ search.FieldResolver      = (*RecordFieldResolver)(nil)
+	_ search.FullTextResolver   = (*RecordFieldResolver)(nil)
+	_ search.PermissionResolver = (*RecordFieldResolver)(nil)
+)
 
 // RecordFieldResolver defines a custom search resolver struct for
 // managing Record model search fields.
@@ -48,6 +53,25 @@ type RecordFieldResolver struct {
 	allowedFields     []string
 	joins             []*join
 	allowHiddenFields bool
+	lastMatch         *fullTextMatch
+}
+
+// fullTextMatch stores the db details of a single resolved match() expression.
+type fullTextMatch struct {
+	columns []string
+	query   string
+	params  dbx.Params
+}
+
+// expr returns the match expression for the specified FTS5 table alias
+// (one MATCH term per column, OR-ed together).
+func (m *fullTextMatch) expr(tableAlias string) string {
+	terms := make([]string, len(m.columns))
+	for i, col := range m.columns {
+		terms[i] = fmt.Sprintf("[[%s.%s]] MATCH %s", tableAlias, col, m.query)
+	}
+
+	return "(" + strings.Join(terms, " OR ") + ")"
 }
 
 // AllowedFields returns a copy of the resolver's allowed fields.
@@ -105,10 +129,19 @@ func NewRecordFieldResolver(
 		r.staticRequestInfo["auth"] = nil
 		if r.requestInfo.Auth != nil {
 			authClone := r.requestInfo.Auth.Clone()
-			r.staticRequestInfo["auth"] = authClone.
+			authData := authClone.
 				Unhide(authClone.Collection().Fields.FieldNames()...).
 				IgnoreEmailVisibility(true).
 				PublicExport()
+
+			// the virtual apiKey prop shadows any auth collection field with the same name
+			if r.requestInfo.APIKey != nil {
+				authData[requestAuthAPIKeyProp] = r.requestInfo.APIKey.PublicExport()
+			} else {
+				delete(authData, requestAuthAPIKeyProp)
+			}
+
+			r.staticRequestInfo["auth"] = authData
 		}
 	}
 
@@ -156,6 +189,173 @@ func (r *RecordFieldResolver) Resolve(fieldName string) (*search.ResolverResult,
 	return parseAndRun(fieldName, r)
 }
 
+// ResolveMatch implements `search.FullTextResolver` interface.
+//
+// It resolves a full-text search expression against the base collection
+// full-text index (see [FullTextIndexer]).
+//
+// If field is empty, the query is matched against all indexed fields
+// that the resolver is allowed to access.
+func (r *RecordFieldResolver) ResolveMatch(field string, query *search.ResolverResult) (*search.ResolverResult, error) {
+	fields := fullTextFields(r.baseCollection)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("collection %q doesn't have full-text indexed fields", r.baseCollection.Name)
+	}
+
+	ftsTable := FullTextTableName(r.baseCollection)
+
+	match := &fullTextMatch{
+		columns: []string{ftsTable}, // the FTS5 hidden column with the same name as the table
+		// normalize empty and NULL queries to an empty phrase (aka. no match) to prevent FTS5 syntax errors
+		query:  "COALESCE(NULLIF(" + query.Identifier + `, ''), '""')`,
+		params: dbx.Params{},
+	}
+	for k, v := range query.Params {
+		match.params[k] = v
+	}
+
+	if field != "" {
+		var f Field
+		for _, ff := range fields {
+			if ff.GetName() == field {
+				f = ff
+				break
+			}
+		}
+		if f == nil {
+			return nil, fmt.Errorf("field %q is not full-text indexed", field)
+		}
+		if f.GetHidden() && !r.allowHiddenFields {
+			return nil, fmt.Errorf("non-filterable field %q", field)
+		}
+
+		match.columns = []string{f.GetId()}
+	} else if !r.allowHiddenFields {
+		visible := make([]string, 0, len(fields))
+		for _, f := range fields {
+			if !f.GetHidden() {
+				visible = append(visible, f.GetId())
+			}
+		}
+
+		if len(visible) == 0 {
+			return nil, fmt.Errorf("collection %q doesn't have filterable full-text indexed fields", r.baseCollection.Name)
+		}
+
+		// restrict the query only to the non-hidden columns
+		// (the query is matched separately against each column instead of using
+		// an FTS5 column filter to prevent escaping it with the query syntax)
+		if len(visible) != len(fields) {
+			match.columns = visible
+		}
+	}
+
+	r.lastMatch = match
+
+	return &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: fmt.Sprintf(
+			"([[%s._rowid_]] IN (SELECT [[rowid]] FROM {{%s}} WHERE %s))",
+			inflector.Columnify(r.baseCollection.Name),
+			ftsTable,
+			match.expr(ftsTable),
+		),
+		Params: match.params,
+	}, nil
+}
+
+// ResolveRank implements `search.FullTextResolver` interface.
+//
+// It returns the FTS5 rank of the last resolved match() expression
+// (lower values are better matches).
+func (r *RecordFieldResolver) ResolveRank() (string, error) {
+	if r.lastMatch == nil {
+		return "", errors.New("missing match() filter expression")
+	}
+
+	alias := "__fts_rank"
+
+	r.registerJoin(
+		FullTextTableName(r.baseCollection),
+		alias,
+		dbx.NewExp(
+			fmt.Sprintf(
+				"%s AND [[%s.rowid]] = [[%s._rowid_]]",
+				r.lastMatch.expr(alias),
+				alias,
+				inflector.Columnify(r.baseCollection.Name),
+			),
+			r.lastMatch.params,
+		),
+	)
+
+	return "[[" + alias + ".rank]]", nil
+}
+
+// ResolvePermission implements `search.PermissionResolver` interface.
+//
+// It resolves the @request.auth.can(permission, [scope]) filter function
+// to an EXISTS subquery against the role assignments of the request auth record.
+// Guests and requests without request info never have permissions.
+//
+// Multi-valued scopes (ex. a multiple relation field) are checked against
+// each scope value following the regular multi-match operators semantic,
+// aka. "=" requires the permission for all values and "?=" for at least one.
+func (r *RecordFieldResolver) ResolvePermission(permission *search.ResolverResult, scope *search.ResolverResult) (*search.ResolverResult, error) {
+	if permission.MultiMatchSubQuery != nil {
+		return nil, errors.New("the @request.auth.can() permission argument must be a single value")
+	}
+
+	if r.requestInfo == nil || r.requestInfo.Auth == nil {
+		return &search.ResolverResult{Identifier: "0", NoCoalesce: true}, nil
+	}
+
+	collectionPlaceholder := "can" + security.PseudorandomString(6)
+	recordPlaceholder := "can" + security.PseudorandomString(6)
+
+	params := dbx.Params{
+		collectionPlaceholder: r.requestInfo.Auth.Collection().Id,
+		recordPlaceholder:     r.requestInfo.Auth.Id,
+	}
+	for k, v := range permission.Params {
+		params[k] = v
+	}
+
+	var scopeIdentifier string
+	if scope != nil {
+		scopeIdentifier = scope.Identifier
+		for k, v := range scope.Params {
+			params[k] = v
+		}
+	}
+
+	result := &search.ResolverResult{
+		NoCoalesce: true,
+		Identifier: permissionCheckExpr(
+			"{:"+collectionPlaceholder+"}",
+			"{:"+recordPlaceholder+"}",
+			permission.Identifier,
+			scopeIdentifier,
+		),
+		Params: params,
+	}
+
+	if scope != nil {
+		result.AfterBuild = scope.AfterBuild
+
+		if scope.MultiMatchSubQuery != nil {
+			result.MultiMatchSubQuery = &permissionMultiMatchSubquery{
+				scopeSubQuery: scope.MultiMatchSubQuery,
+				collectionId:  "{:" + collectionPlaceholder + "}",
+				recordId:      "{:" + recordPlaceholder + "}",
+				permission:    permission.Identifier,
+			}
+		}
+	}
+
+	return result, nil
+}
+
 func (r *RecordFieldResolver) resolveStaticRequestField(path ...string) (*search.ResolverResult, error) {
 	if len(path) == 0 {
 		return nil, errors.New("at least one path key should be provided")
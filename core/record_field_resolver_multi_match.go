@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
 )
 
 var _ dbx.Expression = (*multiMatchSubquery)(nil)
@@ -68,3 +69,35 @@ func (m *multiMatchSubquery) Build(db *dbx.DB, params dbx.Params) string {
 		db.QuoteColumnName(m.baseTableAlias+".id"),
 	)
 }
+
+// -------------------------------------------------------------------
+
+var _ dbx.Expression = (*permissionMultiMatchSubquery)(nil)
+
+// permissionMultiMatchSubquery defines a multi-match subquery expression
+// that checks the @request.auth.can() permission against each value
+// of a multi-valued scope (ex. a multiple relation field).
+type permissionMultiMatchSubquery struct {
+	scopeSubQuery dbx.Expression
+	collectionId  string
+	recordId      string
+	permission    string
+}
+
+// Build converts the expression into a SQL fragment.
+//
+// Implements [dbx.Expression] interface.
+func (m *permissionMultiMatchSubquery) Build(db *dbx.DB, params dbx.Params) string {
+	if m.scopeSubQuery == nil {
+		return "0=1"
+	}
+
+	alias := "__pm" + security.PseudorandomString(6)
+
+	return fmt.Sprintf(
+		"SELECT %s as [[multiMatchValue]] FROM (%s) %s",
+		permissionCheckExpr(m.collectionId, m.recordId, m.permission, "[["+alias+".multiMatchValue]]"),
+		m.scopeSubQuery.Build(db, params),
+		db.QuoteTableName(alias),
+	)
+}
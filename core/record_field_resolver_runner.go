@@ -32,6 +32,10 @@ var plainRequestAuthFields = map[string]struct{}{
 	"@request.auth." + FieldNameVerified:        {},
 }
 
+// requestAuthAPIKeyProp is the name of the virtual @request.auth.apiKey.*
+// prop holding the API key used to authenticate the request (if any).
+const requestAuthAPIKeyProp = "apiKey"
+
 // parseAndRun starts a new one-off RecordFieldResolver.Resolve execution.
 func parseAndRun(fieldName string, resolver *RecordFieldResolver) (*search.ResolverResult, error) {
 	r := &runner{
@@ -207,6 +211,15 @@ func (r *runner) processRequestAuthField() (*search.ResolverResult, error) {
 		return r.resolver.resolveStaticRequestField(r.activeProps[1:]...)
 	}
 
+	// API key field (aka. @request.auth.apiKey.*)
+	// ---
+	if len(r.activeProps) > 2 {
+		name, _, err := splitModifier(r.activeProps[2])
+		if err == nil && name == requestAuthAPIKeyProp {
+			return r.resolver.resolveStaticRequestField(r.activeProps[1:]...)
+		}
+	}
+
 	// resolve the auth collection field
 	// ---
 	collection := r.resolver.requestInfo.Auth.Collection()
@@ -806,3 +806,265 @@ func TestRecordFieldResolverResolveStaticRequestInfoFields(t *testing.T) {
 		t.Fatalf("Expected the original authRecord email to not be exported, got %q", v)
 	}
 }
+
+func TestRecordFieldResolverResolveRequestAuthAPIKeyFields(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	collection, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authRecord, err := app.FindRecordById("users", "4q1xlclmfloku33")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiKey := core.NewAPIKey(app)
+	apiKey.Id = "test_api_key_id"
+	apiKey.SetCollectionRef(authRecord.Collection().Id)
+	apiKey.SetRecordRef(authRecord.Id)
+	apiKey.SetName("test")
+	apiKey.GenerateKey()
+
+	scenarios := []struct {
+		name          string
+		apiKey        *core.APIKey
+		fieldName     string
+		expectedParam string // encoded json
+	}{
+		{"without API key - id", nil, "@request.auth.apiKey.id", ``},
+		{"without API key - isset", nil, "@request.auth.apiKey:isset", `FALSE`},
+		{"with API key - id", apiKey, "@request.auth.apiKey.id", `"test_api_key_id"`},
+		{"with API key - name", apiKey, "@request.auth.apiKey.name", `"test"`},
+		{"with API key - hidden keyHash", apiKey, "@request.auth.apiKey.keyHash", ``},
+		{"with API key - isset", apiKey, "@request.auth.apiKey:isset", `TRUE`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			requestInfo := &core.RequestInfo{
+				Auth:   authRecord,
+				APIKey: s.apiKey,
+			}
+
+			r := core.NewRecordFieldResolver(app, collection, requestInfo, true)
+
+			result, err := r.Resolve(s.fieldName)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result.Params) == 0 {
+				expected := s.expectedParam
+				if expected == "" {
+					expected = "NULL"
+				}
+				if result.Identifier != expected {
+					t.Fatalf("Expected identifier %q, got %q", expected, result.Identifier)
+				}
+				return
+			}
+
+			for _, v := range result.Params {
+				encoded, _ := json.Marshal(v)
+				if string(encoded) != s.expectedParam {
+					t.Fatalf("Expected param %s, got %s", s.expectedParam, encoded)
+				}
+			}
+		})
+	}
+}
+
+func TestRecordFieldResolverResolvePermission(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubRoleAssignments(t, app)
+
+	orgsCollection := core.NewBaseCollection("rbac_orgs")
+	orgsCollection.Fields.Add(&core.TextField{Name: "name"})
+	if err := app.Save(orgsCollection); err != nil {
+		t.Fatal(err)
+	}
+
+	orgIds := map[string]string{}
+	for _, name := range []string{"org1", "org2"} {
+		org := core.NewRecord(orgsCollection)
+		org.Set("name", name)
+		if err := app.Save(org); err != nil {
+			t.Fatal(err)
+		}
+		orgIds[name] = org.Id
+	}
+
+	collection := core.NewBaseCollection("rbac_test")
+	collection.Fields.Add(&core.TextField{Name: "org"})
+	collection.Fields.Add(&core.RelationField{Name: "orgs", CollectionId: orgsCollection.Id, MaxSelect: 2})
+	if err := app.Save(collection); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range []struct {
+		org  string
+		orgs []string
+	}{
+		{"org1", []string{orgIds["org1"]}},
+		{"org2", []string{orgIds["org1"], orgIds["org2"]}},
+		{"", nil},
+	} {
+		record := core.NewRecord(collection)
+		record.Set("org", item.org)
+		record.Set("orgs", item.orgs)
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		requestInfo  *core.RequestInfo
+		filter       string
+		expectError  bool
+		expectedOrgs []string
+	}{
+		{
+			"missing request info",
+			nil,
+			"@request.auth.can('posts.view') = true",
+			false,
+			nil,
+		},
+		{
+			"guest",
+			&core.RequestInfo{},
+			"@request.auth.can('posts.view') = true",
+			false,
+			nil,
+		},
+		{
+			"invalid number of arguments",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can() = true",
+			true,
+			nil,
+		},
+		{
+			"global permission",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can('posts.view') = true",
+			false,
+			[]string{"org1", "org2", ""},
+		},
+		{
+			"negated global permission",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can('posts.view') != true",
+			false,
+			nil,
+		},
+		{
+			"scoped permission with field scope",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can('posts.delete', org) = true",
+			false,
+			[]string{"org1"},
+		},
+		{
+			"scoped permission with text scope",
+			&core.RequestInfo{Auth: user2},
+			"@request.auth.can('posts.update', 'org2') = true",
+			false,
+			[]string{"org1", "org2", ""},
+		},
+		{
+			"scoped permission combined with other expressions",
+			&core.RequestInfo{Auth: user2},
+			"org != 'org1' && @request.auth.can(@request.query.permission, org) = true",
+			false,
+			[]string{"org2"},
+		},
+		{
+			"multi-valued permission",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can(orgs.name, org) = true",
+			true,
+			nil,
+		},
+		{
+			"scoped permission with multi-valued scope (all)",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can('posts.delete', orgs.name) = true",
+			false,
+			[]string{"org1"},
+		},
+		{
+			"scoped permission with multi-valued scope (any)",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can('posts.delete', orgs.name) ?= true",
+			false,
+			[]string{"org1", "org2"},
+		},
+		{
+			"global permission with multi-valued scope",
+			&core.RequestInfo{Auth: user1},
+			"@request.auth.can('posts.view', orgs.name) = true",
+			false,
+			[]string{"org1", "org2", ""},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if s.requestInfo != nil {
+				s.requestInfo.Query = map[string]string{"permission": "posts.view"}
+			}
+
+			resolver := core.NewRecordFieldResolver(app, collection, s.requestInfo, true)
+
+			expr, err := search.FilterData(s.filter).BuildExpr(resolver)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			query := app.RecordQuery(collection).Distinct(true).AndWhere(expr).OrderBy("rbac_test.rowid ASC")
+			if err := resolver.UpdateQuery(query); err != nil {
+				t.Fatal(err)
+			}
+
+			var orgs []string
+			if err := query.Select("org").Column(&orgs); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(orgs) != len(s.expectedOrgs) {
+				t.Fatalf("Expected orgs %v, got %v", s.expectedOrgs, orgs)
+			}
+
+			for i, org := range orgs {
+				if org != s.expectedOrgs[i] {
+					t.Fatalf("Expected orgs %v, got %v", s.expectedOrgs, orgs)
+				}
+			}
+		})
+	}
+}
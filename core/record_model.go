@@ -1397,8 +1397,23 @@ func (m *Record) callFieldInterceptors(
 	actionName string,
 	actionFunc func() error,
 ) error {
-	// the firing order of the fields doesn't matter
+	// the firing order of the fields doesn't matter with the exception of the
+	// formula fields which are registered first and in reverse order (aka. executed
+	// right before the action in their definition order) so that they could be
+	// evaluated with the final values of the other fields
+	fields := make([]Field, 0, len(m.Collection().Fields))
 	for _, field := range m.Collection().Fields {
+		if _, ok := field.(*FormulaField); ok {
+			fields = append([]Field{field}, fields...)
+		}
+	}
+	for _, field := range m.Collection().Fields {
+		if _, ok := field.(*FormulaField); !ok {
+			fields = append(fields, field)
+		}
+	}
+
+	for _, field := range fields {
 		if f, ok := field.(RecordInterceptor); ok {
 			oldfn := actionFunc
 			actionFunc = func() error {
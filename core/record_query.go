@@ -466,7 +466,8 @@ func (app *BaseApp) CountRecords(collectionModelOrIdentifier any, exprs ...dbx.E
 //
 // Optionally specify a list of validTypes to check tokens only from those types.
 //
-// Returns an error if the JWT is invalid, expired or not associated to an auth collection record.
+// Returns an error if the JWT is invalid, expired, linked to a revoked auth session
+// or not associated to an auth collection record.
 func (app *BaseApp) FindAuthRecordByToken(token string, validTypes ...string) (*Record, error) {
 	if token == "" {
 		return nil, errors.New("missing token")
@@ -518,11 +519,27 @@ func (app *BaseApp) FindAuthRecordByToken(token string, validTypes ...string) (*
 	secret := record.TokenKey() + baseTokenKey
 
 	// verify token signature
-	_, err = security.ParseJWT(token, secret)
+	claims, err := security.ParseJWT(token, secret)
 	if err != nil {
 		return nil, err
 	}
 
+	// check the linked auth session (if any)
+	if sessionId, _ := claims[TokenClaimSessionId].(string); sessionId != "" && tokenType == TokenTypeAuth {
+		session, err := app.FindAuthSessionById(sessionId)
+		if err != nil {
+			return nil, errors.New("missing or revoked auth session")
+		}
+
+		if session.RecordRef() != record.Id || session.CollectionRef() != record.Collection().Id {
+			return nil, errors.New("the auth session is not associated to the token record")
+		}
+
+		if session.HasExpired() {
+			return nil, errors.New("the auth session has expired")
+		}
+	}
+
 	return record, nil
 }
 
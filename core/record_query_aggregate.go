@@ -0,0 +1,504 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/inflector"
+	"github.com/pocketbase/pocketbase/tools/search"
+)
+
+// supported aggregate functions
+const (
+	AggregateFuncCount string = "count"
+	AggregateFuncSum   string = "sum"
+	AggregateFuncAvg   string = "avg"
+	AggregateFuncMin   string = "min"
+	AggregateFuncMax   string = "max"
+)
+
+// MaxAggregateExprs specifies the max allowed number of
+// individual groupBy and aggregate expressions.
+const MaxAggregateExprs int = 10
+
+// RecordsAggregateOptions defines the options of a records aggregation query.
+type RecordsAggregateOptions struct {
+	// RequestInfo is an optional request info that will be used to resolve
+	// the @request.* fields in the Rule and Filter expressions.
+	//
+	// If set, the hidden collection fields are accessible only
+	// if the request info has a superuser auth.
+	RequestInfo *RequestInfo
+
+	// Rule is an optional access rule (ex. the collection ListRule)
+	// that the records must satisfy in order to be aggregated.
+	//
+	// Hidden fields are always allowed in the rule expression.
+	Rule string
+
+	// Filter is an optional filter expression that the records
+	// must satisfy in order to be aggregated.
+	Filter string
+
+	// Params are the optional Filter placeholder parameters.
+	//
+	// NB! Use it to bind untrusted user variables!
+	Params dbx.Params
+
+	// GroupBy is a list of field expressions to group the records by
+	// (ex. "status", "author.name", "tags:each", "tags:length").
+	//
+	// Each expression could have an optional alias that will be used
+	// as result key, ex. "author.name as authorName".
+	//
+	// If RequestInfo is set and it doesn't have a superuser auth, the
+	// GroupBy and Aggregates fields are further restricted:
+	//   - relation paths are allowed only into collections with public ViewRule
+	//   - the auth collection "email" field is allowed only if the request
+	//     auth satisfies the collection ManageRule for all aggregated records
+	GroupBy []string
+
+	// Aggregates is a list of aggregate expressions in the format
+	// "func(field)" with an optional alias, ex.:
+	//
+	//	count()
+	//	sum(total)
+	//	avg(author.age) as avgAge
+	//
+	// The supported functions are count, sum, avg, min and max.
+	//
+	// Without an explicit alias, the result key is "count" for count()
+	// and "func_field" for the others (ex. "sum_total", "avg_author_age").
+	Aggregates []string
+
+	// Limit specifies the max number of returned groups (<=0 for no limit).
+	Limit int
+}
+
+var (
+	aggregateExprRegex = regexp.MustCompile(`(?i)^(\w+)\(\s*([\w\.\:]*)\s*\)(?:\s+as\s+(\w+))?$`)
+	groupByExprRegex   = regexp.MustCompile(`(?i)^([\w\.\:]+)(?:\s+as\s+(\w+))?$`)
+)
+
+type aggregateColumn struct {
+	key   string
+	expr  string
+	field string
+}
+
+// AggregateRecords returns the aggregated values of the records from
+// the specified collection, grouped by the options.GroupBy field expressions.
+//
+// Each returned item is a map with the group values and the aggregate
+// results keyed by their expression (or alias).
+// The values are returned as they are stored in the database
+// (ex. bool fields are returned as 0 or 1).
+//
+// NB! The group by field expressions may join multiple rows for a single
+// record (ex. "tags:each"), so that the same record could be part of multiple groups.
+//
+// Example:
+//
+//	items, err := app.AggregateRecords("orders", core.RecordsAggregateOptions{
+//		GroupBy:    []string{"status", "customer.country as country"},
+//		Aggregates: []string{"count()", "sum(total)", "max(created) as lastOrder"},
+//		Filter:     "created >= {:date}",
+//		Params:     dbx.Params{"date": "2025-01-01 00:00:00.000Z"},
+//	})
+//	// [{"status": "paid", "country": "BG", "count": 10, "sum_total": 123.45, "lastOrder": "..."}, ...]
+func (app *BaseApp) AggregateRecords(collectionModelOrIdentifier any, options RecordsAggregateOptions) ([]map[string]any, error) {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options.Aggregates) == 0 {
+		return nil, errors.New("at least one aggregate expression is required")
+	}
+
+	if len(options.GroupBy) > MaxAggregateExprs || len(options.Aggregates) > MaxAggregateExprs {
+		return nil, fmt.Errorf("max %d groupBy and aggregate expressions are allowed", MaxAggregateExprs)
+	}
+
+	allowHiddenFields := options.RequestInfo == nil || options.RequestInfo.HasSuperuserAuth()
+
+	tableName := inflector.Columnify(collection.Name)
+
+	// resolve the group and aggregate columns
+	// ---
+	groupResolver := NewRecordFieldResolver(app, collection, options.RequestInfo, allowHiddenFields)
+	// allow only the collection fields (aka. no @request.*, @collection.*, etc.)
+	groupResolver.SetAllowedFields([]string{`^\w+[\w\.\:]*$`})
+
+	keys := make(map[string]struct{}, len(options.GroupBy)+len(options.Aggregates))
+
+	// the fields whose values are returned and that have to be checked
+	// against the collections view and manage rules (if not superuser)
+	returnedFields := make([]string, 0, len(options.GroupBy)+len(options.Aggregates))
+
+	groupColumns := make([]aggregateColumn, 0, len(options.GroupBy))
+	for _, raw := range options.GroupBy {
+		col, err := resolveGroupByColumn(groupResolver, strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		returnedFields = append(returnedFields, col.field)
+		if _, ok := keys[col.key]; ok {
+			return nil, fmt.Errorf("duplicated result key %q", col.key)
+		}
+		keys[col.key] = struct{}{}
+		groupColumns = append(groupColumns, col)
+	}
+
+	aggregateColumns := make([]aggregateColumn, 0, len(options.Aggregates))
+	for _, raw := range options.Aggregates {
+		col, err := resolveAggregateColumn(groupResolver, tableName, strings.TrimSpace(raw))
+		if err != nil {
+			return nil, err
+		}
+		if col.field != "" {
+			returnedFields = append(returnedFields, col.field)
+		}
+		if _, ok := keys[col.key]; ok {
+			return nil, fmt.Errorf("duplicated result key %q", col.key)
+		}
+		keys[col.key] = struct{}{}
+		aggregateColumns = append(aggregateColumns, col)
+	}
+
+	selects := make([]string, 0, len(groupColumns)+len(aggregateColumns))
+	groupAliases := make([]string, 0, len(groupColumns))
+	for i, col := range groupColumns {
+		alias := "__g" + strconv.Itoa(i)
+		groupAliases = append(groupAliases, "[["+alias+"]]")
+		selects = append(selects, col.expr+" AS [["+alias+"]]")
+	}
+	for i, col := range aggregateColumns {
+		selects = append(selects, col.expr+" AS [[__a"+strconv.Itoa(i)+"]]")
+	}
+
+	if !allowHiddenFields {
+		requireManage := false
+		for _, field := range returnedFields {
+			isEmail, err := checkAggregateFieldAccess(app, collection, field)
+			if err != nil {
+				return nil, err
+			}
+			requireManage = requireManage || isEmail
+		}
+
+		if requireManage {
+			if err := checkAggregateManageAccess(app, collection, options); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	query := app.RecordQuery(collection).Select(selects...)
+
+	// filter the aggregated records
+	// ---
+	if options.Rule != "" || options.Filter != "" {
+		idsQuery, err := aggregateIdsQuery(app, collection, options)
+		if err != nil {
+			return nil, err
+		}
+
+		// use a subquery to prevent counting the filter joins duplicates
+		built := idsQuery.Build()
+		query.AndWhere(dbx.NewExp("[["+tableName+".id]] IN ("+built.SQL()+")", built.Params()))
+	}
+
+	if err := groupResolver.UpdateQuery(query); err != nil {
+		return nil, err
+	}
+	query.Distinct(false) // not needed since the result rows are grouped
+
+	if len(groupAliases) > 0 {
+		query.GroupBy(groupAliases...).OrderBy(groupAliases...)
+	}
+
+	if options.Limit > 0 {
+		query.Limit(int64(options.Limit))
+	}
+
+	// fetch the aggregated rows
+	// ---
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	totalColumns := len(groupColumns) + len(aggregateColumns)
+
+	result := []map[string]any{}
+
+	for rows.Next() {
+		values := make([]any, totalColumns)
+		ptrs := make([]any, totalColumns)
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		item := make(map[string]any, totalColumns)
+		for i, v := range values {
+			// the sqlite driver may return TEXT values as raw bytes
+			if b, ok := v.([]byte); ok {
+				v = string(b)
+			}
+
+			if i < len(groupColumns) {
+				item[groupColumns[i].key] = v
+			} else {
+				item[aggregateColumns[i-len(groupColumns)].key] = v
+			}
+		}
+
+		result = append(result, item)
+	}
+
+	return result, rows.Err()
+}
+
+func resolveGroupByColumn(resolver *RecordFieldResolver, raw string) (aggregateColumn, error) {
+	parts := groupByExprRegex.FindStringSubmatch(raw)
+	if len(parts) != 3 {
+		return aggregateColumn{}, fmt.Errorf("invalid groupBy expression %q", raw)
+	}
+
+	identifier, err := resolveAggregateIdentifier(resolver, parts[1])
+	if err != nil {
+		return aggregateColumn{}, fmt.Errorf("invalid groupBy expression %q: %w", raw, err)
+	}
+
+	key := parts[1]
+	if parts[2] != "" {
+		key = parts[2]
+	}
+
+	return aggregateColumn{key: key, expr: identifier, field: parts[1]}, nil
+}
+
+func resolveAggregateColumn(resolver *RecordFieldResolver, tableName string, raw string) (aggregateColumn, error) {
+	parts := aggregateExprRegex.FindStringSubmatch(raw)
+	if len(parts) != 4 {
+		return aggregateColumn{}, fmt.Errorf("invalid aggregate expression %q", raw)
+	}
+
+	fn := strings.ToLower(parts[1])
+	field := parts[2]
+
+	col := aggregateColumn{key: parts[3]}
+
+	switch fn {
+	case AggregateFuncCount:
+		if field != "" {
+			return aggregateColumn{}, fmt.Errorf("invalid aggregate expression %q: count() doesn't accept arguments", raw)
+		}
+
+		// count each record only once even if it was "duplicated" by the joins
+		col.expr = "COUNT(DISTINCT [[" + tableName + ".id]])"
+
+		if col.key == "" {
+			col.key = fn
+		}
+	case AggregateFuncSum, AggregateFuncAvg, AggregateFuncMin, AggregateFuncMax:
+		if field == "" {
+			return aggregateColumn{}, fmt.Errorf("invalid aggregate expression %q: missing field argument", raw)
+		}
+
+		identifier, err := resolveAggregateIdentifier(resolver, field)
+		if err != nil {
+			return aggregateColumn{}, fmt.Errorf("invalid aggregate expression %q: %w", raw, err)
+		}
+
+		col.expr = strings.ToUpper(fn) + "(" + identifier + ")"
+		col.field = field
+
+		if col.key == "" {
+			col.key = fn + "_" + inflector.Columnify(strings.NewReplacer(".", "_", ":", "_").Replace(field))
+		}
+	default:
+		return aggregateColumn{}, fmt.Errorf("unsupported aggregate function %q", parts[1])
+	}
+
+	return col, nil
+}
+
+func resolveAggregateIdentifier(resolver *RecordFieldResolver, field string) (string, error) {
+	r, err := resolver.Resolve(field)
+	if err != nil {
+		return "", err
+	}
+
+	// invalidate empty fields and non-column identifiers
+	if len(r.Params) > 0 || r.Identifier == "" || strings.EqualFold(r.Identifier, "null") {
+		return "", fmt.Errorf("invalid field %q", field)
+	}
+
+	return r.Identifier, nil
+}
+
+// aggregateIdsQuery returns a query that selects the ids of the collection
+// records matching the options Rule and Filter and the optional extraRules.
+//
+// The extraRules are trusted and hidden fields are allowed in them.
+func aggregateIdsQuery(app App, collection *Collection, options RecordsAggregateOptions, extraRules ...string) (*dbx.SelectQuery, error) {
+	allowHiddenFields := options.RequestInfo == nil || options.RequestInfo.HasSuperuserAuth()
+
+	tableName := inflector.Columnify(collection.Name)
+
+	resolver := NewRecordFieldResolver(app, collection, options.RequestInfo, true)
+
+	query := app.ConcurrentDB().Select("[[" + tableName + ".id]]").From(collection.Name)
+
+	for _, rule := range append([]string{options.Rule}, extraRules...) {
+		if rule == "" {
+			continue
+		}
+
+		expr, err := search.FilterData(rule).BuildExprWithLimit(resolver, search.DefaultFilterExprLimit)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rule expression: %w", err)
+		}
+		query.AndWhere(expr)
+	}
+
+	resolver.SetAllowHiddenFields(allowHiddenFields)
+
+	if options.Filter != "" {
+		expr, err := search.FilterData(options.Filter).BuildExprWithLimit(resolver, search.DefaultFilterExprLimit, options.Params)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter expression: %w", err)
+		}
+		query.AndWhere(expr)
+	}
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// checkAggregateFieldAccess checks whether a non-superuser is allowed
+// to read the values of the specified group or aggregate field path.
+//
+// It returns true if the field is the email of the aggregated auth collection
+// (in which case the caller must also check the collection ManageRule).
+func checkAggregateFieldAccess(app App, collection *Collection, fieldPath string) (bool, error) {
+	props := strings.Split(fieldPath, ".")
+
+	current := collection
+
+	for i, prop := range props {
+		name, _, _ := strings.Cut(prop, ":")
+
+		if i == len(props)-1 {
+			if current.IsAuth() && name == FieldNameEmail {
+				if current.Id != collection.Id {
+					return false, fmt.Errorf("the related records %q field is not allowed", fieldPath)
+				}
+				return true, nil
+			}
+			return false, nil
+		}
+
+		field := current.Fields.GetByName(name)
+
+		// json path
+		if field != nil && (field.Type() == FieldTypeJSON || field.Type() == FieldTypeGeoPoint) {
+			return false, nil
+		}
+
+		var next *Collection
+
+		if field == nil {
+			parts := viaRegex.FindStringSubmatch(name)
+			if len(parts) != 3 {
+				return false, fmt.Errorf("invalid field %q", fieldPath)
+			}
+
+			backCollection, err := getCollectionByModelOrIdentifier(app, parts[1])
+			if err != nil {
+				return false, fmt.Errorf("invalid field %q", fieldPath)
+			}
+			next = backCollection
+		} else {
+			relField, ok := field.(*RelationField)
+			if !ok {
+				return false, fmt.Errorf("invalid field %q", fieldPath)
+			}
+
+			// the related record id is stored in the current record
+			if i == len(props)-2 && props[i+1] == FieldNameId {
+				return false, nil
+			}
+
+			relCollection, err := getCollectionByModelOrIdentifier(app, relField.CollectionId)
+			if err != nil {
+				return false, fmt.Errorf("invalid field %q", fieldPath)
+			}
+			next = relCollection
+		}
+
+		// the aggregates are not filtered per related record so
+		// only the publicly viewable collections are allowed
+		if next.ViewRule == nil || *next.ViewRule != "" {
+			return false, fmt.Errorf("the field %q requires access to the %q collection records", fieldPath, next.Name)
+		}
+
+		current = next
+	}
+
+	return false, nil
+}
+
+// checkAggregateManageAccess checks whether all aggregated records
+// satisfy the collection ManageRule for the options request auth.
+func checkAggregateManageAccess(app App, collection *Collection, options RecordsAggregateOptions) error {
+	errForbidden := fmt.Errorf("the %q field requires manage access to all aggregated records", FieldNameEmail)
+
+	if collection.ManageRule == nil || options.RequestInfo == nil || options.RequestInfo.Auth == nil {
+		return errForbidden
+	}
+
+	tableName := inflector.Columnify(collection.Name)
+
+	countIds := func(extraRules ...string) (int, error) {
+		query, err := aggregateIdsQuery(app, collection, options, extraRules...)
+		if err != nil {
+			return 0, err
+		}
+
+		var total int
+		err = query.Distinct(false).Select("COUNT(DISTINCT [[" + tableName + ".id]])").Row(&total)
+
+		return total, err
+	}
+
+	total, err := countIds()
+	if err != nil {
+		return err
+	}
+
+	managed, err := countIds(*collection.ManageRule)
+	if err != nil {
+		return err
+	}
+
+	if managed != total {
+		return errForbidden
+	}
+
+	return nil
+}
@@ -0,0 +1,321 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestAggregateRecords(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	superuser, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := app.FindAuthRecordByEmail("clients", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name               string
+		collectionIdOrName string
+		options            core.RecordsAggregateOptions
+		expectError        bool
+		expectResult       string
+	}{
+		{
+			"missing collection",
+			"missing",
+			core.RecordsAggregateOptions{Aggregates: []string{"count()"}},
+			true,
+			"",
+		},
+		{
+			"missing aggregates",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"select_one"}},
+			true,
+			"",
+		},
+		{
+			"unsupported aggregate function",
+			"demo1",
+			core.RecordsAggregateOptions{Aggregates: []string{"total(number)"}},
+			true,
+			"",
+		},
+		{
+			"count with argument",
+			"demo1",
+			core.RecordsAggregateOptions{Aggregates: []string{"count(number)"}},
+			true,
+			"",
+		},
+		{
+			"sum without argument",
+			"demo1",
+			core.RecordsAggregateOptions{Aggregates: []string{"sum()"}},
+			true,
+			"",
+		},
+		{
+			"unknown aggregate field",
+			"demo1",
+			core.RecordsAggregateOptions{Aggregates: []string{"sum(missing)"}},
+			true,
+			"",
+		},
+		{
+			"unknown group field",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"missing"}, Aggregates: []string{"count()"}},
+			true,
+			"",
+		},
+		{
+			"@request group field",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"@request.auth.id"}, Aggregates: []string{"count()"}},
+			true,
+			"",
+		},
+		{
+			"duplicated result keys",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"select_one as count"}, Aggregates: []string{"count()"}},
+			true,
+			"",
+		},
+		{
+			"invalid filter",
+			"demo1",
+			core.RecordsAggregateOptions{Aggregates: []string{"count()"}, Filter: "missing > 1"},
+			true,
+			"",
+		},
+		{
+			"no group by",
+			"demo1",
+			core.RecordsAggregateOptions{Aggregates: []string{"count()", "sum(number)", "min(number) as minNumber", "MAX(number)"}},
+			false,
+			`[{"count":3,"max_number":123456,"minNumber":0,"sum_number":123912}]`,
+		},
+		{
+			"single group by field",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"select_one"}, Aggregates: []string{"count()", "avg(number)"}},
+			false,
+			`[{"avg_number":0,"count":1,"select_one":""},{"avg_number":61956,"count":2,"select_one":"optionB"}]`,
+		},
+		{
+			"group by :each modifier",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"select_many:each as option"}, Aggregates: []string{"count()"}},
+			false,
+			`[{"count":1,"option":null},{"count":2,"option":"optionB"},{"count":1,"option":"optionC"}]`,
+		},
+		{
+			"group by :length modifier",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"rel_many:length"}, Aggregates: []string{"count()"}},
+			false,
+			`[{"count":1,"rel_many:length":0},{"count":1,"rel_many:length":1},{"count":1,"rel_many:length":3}]`,
+		},
+		{
+			"group by relation field",
+			"demo1",
+			core.RecordsAggregateOptions{GroupBy: []string{"rel_one.select_one", "bool"}, Aggregates: []string{"count()"}},
+			false,
+			`[{"bool":0,"count":1,"rel_one.select_one":null},{"bool":1,"count":1,"rel_one.select_one":null},{"bool":0,"count":1,"rel_one.select_one":"optionB"}]`,
+		},
+		{
+			"filter with multiple relation joins (should be counted only once)",
+			"demo1",
+			core.RecordsAggregateOptions{
+				Aggregates: []string{"count()", "sum(number)"},
+				Filter:     "rel_many.id != {:id}",
+				Params:     dbx.Params{"id": ""},
+			},
+			false,
+			`[{"count":3,"sum_number":123912}]`,
+		},
+		{
+			"rule and filter",
+			"demo1",
+			core.RecordsAggregateOptions{
+				GroupBy:    []string{"select_one"},
+				Aggregates: []string{"count()"},
+				Rule:       "number > 0",
+				Filter:     "bool = false",
+			},
+			false,
+			`[{"count":1,"select_one":"optionB"}]`,
+		},
+		{
+			"hidden field with non-superuser request info",
+			"users",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				GroupBy:     []string{"tokenKey"},
+				Aggregates:  []string{"count()"},
+			},
+			true,
+			"",
+		},
+		{
+			"hidden field in the rule with non-superuser request info",
+			"users",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				Aggregates:  []string{"count()"},
+				Rule:        "tokenKey != '' && id = @request.auth.id",
+			},
+			false,
+			`[{"count":1}]`,
+		},
+		{
+			"hidden field with superuser request info",
+			"users",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: superuser},
+				Aggregates:  []string{"count()", "max(tokenKey) as max"},
+				Filter:      "tokenKey != ''",
+				Limit:       1,
+			},
+			false,
+			`[{"count":3,"max":"x6vHUi00LvM5bFeGIpwXN9xuol8k1BknfTmlySQ7YQWoTLKOa7"}]`,
+		},
+		{
+			"auth email group field with non-superuser request info and missing manage rule",
+			"users",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				GroupBy:     []string{"email"},
+				Aggregates:  []string{"count()"},
+			},
+			true,
+			"",
+		},
+		{
+			"auth email aggregate field with non-superuser request info and missing manage rule",
+			"users",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				Aggregates:  []string{"max(email:lower)"},
+			},
+			true,
+			"",
+		},
+		{
+			"auth email field with non-superuser request info and unsatisfied manage rule",
+			"nologin",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: client},
+				Aggregates:  []string{"count()", "max(email)"},
+			},
+			true,
+			"",
+		},
+		{
+			"auth email field with non-superuser request info and satisfied manage rule",
+			"nologin",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				Aggregates:  []string{"count()", "max(email)"},
+			},
+			false,
+			`[{"count":3,"max_email":"test@example.com"}]`,
+		},
+		{
+			"relation field into non-public collection with non-superuser request info",
+			"demo1",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				GroupBy:     []string{"rel_many.username"},
+				Aggregates:  []string{"count()"},
+			},
+			true,
+			"",
+		},
+		{
+			"relation field into non-public collection with non-superuser request info (aggregate)",
+			"demo1",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				Aggregates:  []string{"min(rel_one.select_one)"},
+			},
+			true,
+			"",
+		},
+		{
+			"relation id field into non-public collection with non-superuser request info",
+			"demo1",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				GroupBy:     []string{"rel_one.id as rel"},
+				Aggregates:  []string{"count()"},
+			},
+			false,
+			`[{"count":2,"rel":""},{"count":1,"rel":"84nmscqy84lsi1t"}]`,
+		},
+		{
+			"relation field into public collection with non-superuser request info",
+			"users",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: user},
+				GroupBy:     []string{"rel.title"},
+				Aggregates:  []string{"count()"},
+			},
+			false,
+			`[{"count":1,"rel.title":null},{"count":1,"rel.title":"test1"},{"count":1,"rel.title":"test3"}]`,
+		},
+		{
+			"relation field into non-public collection with superuser request info",
+			"demo1",
+			core.RecordsAggregateOptions{
+				RequestInfo: &core.RequestInfo{Auth: superuser},
+				Aggregates:  []string{"count()", "max(rel_many.email) as email"},
+			},
+			false,
+			`[{"count":3,"email":"test@example.com"}]`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := app.AggregateRecords(s.collectionIdOrName, s.options)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			raw, err := json.Marshal(result)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(raw) != s.expectResult {
+				t.Fatalf("Expected result\n%s\ngot\n%s", s.expectResult, raw)
+			}
+		})
+	}
+}
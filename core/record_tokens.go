@@ -25,6 +25,7 @@ const (
 	TokenClaimEmail        = "email"
 	TokenClaimNewEmail     = "newEmail"
 	TokenClaimRefreshable  = "refreshable"
+	TokenClaimSessionId    = "sid"
 )
 
 // Common token related errors
@@ -40,15 +41,27 @@ var (
 //
 // Zero or negative duration will fallback to the duration from the auth collection settings.
 func (m *Record) NewStaticAuthToken(duration time.Duration) (string, error) {
-	return m.newAuthToken(duration, false)
+	return m.newAuthToken(duration, false, "")
 }
 
 // NewAuthToken generates and returns a new record authentication token.
 func (m *Record) NewAuthToken() (string, error) {
-	return m.newAuthToken(0, true)
+	return m.newAuthToken(0, true, "")
 }
 
-func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string, error) {
+// NewSessionAuthToken generates and returns a new record authentication
+// token bound to the specified auth session.
+//
+// The token is valid only as long as its auth session exists and is not expired.
+func (m *Record) NewSessionAuthToken(sessionId string) (string, error) {
+	if sessionId == "" {
+		return "", errors.New("missing auth session id")
+	}
+
+	return m.newAuthToken(0, true, sessionId)
+}
+
+func (m *Record) newAuthToken(duration time.Duration, refreshable bool, sessionId string) (string, error) {
 	if !m.Collection().IsAuth() {
 		return "", ErrNotAuthRecord
 	}
@@ -65,6 +78,10 @@ func (m *Record) newAuthToken(duration time.Duration, refreshable bool) (string,
 		TokenClaimRefreshable:  refreshable,
 	}
 
+	if sessionId != "" {
+		claims[TokenClaimSessionId] = sessionId
+	}
+
 	if duration <= 0 {
 		duration = m.Collection().AuthToken.DurationTime()
 	}
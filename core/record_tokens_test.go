@@ -83,6 +83,95 @@ func TestNewAuthToken(t *testing.T) {
 	})
 }
 
+func TestNewSessionAuthToken(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	tokens := stubAuthSessions(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	activeSession, err := app.FindAuthSessionByRefreshToken(tokens[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiredSession, err := app.FindAuthSessionByRefreshToken(tokens[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherUserSession, err := app.FindAuthSessionByRefreshToken(tokens[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("empty session id", func(t *testing.T) {
+		if _, err := user.NewSessionAuthToken(""); err == nil {
+			t.Fatal("Expected error for empty session id")
+		}
+	})
+
+	scenarios := []struct {
+		name        string
+		sessionId   string
+		expectError bool
+	}{
+		{"missing session", "missing", true},
+		{"expired session", expiredSession.Id, true},
+		{"session of another record", otherUserSession.Id, true},
+		{"active session", activeSession.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			token, err := user.NewSessionAuthToken(s.sessionId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, _ := security.ParseUnverifiedJWT(token)
+			if v := claims[core.TokenClaimSessionId]; v != s.sessionId {
+				t.Fatalf("Expected sid claim %q, got %#v", s.sessionId, v)
+			}
+			if v := claims[core.TokenClaimRefreshable]; v != true {
+				t.Fatalf("Expected refreshable claim true, got %#v", v)
+			}
+
+			tokenRecord, err := app.FindAuthRecordByToken(token, core.TokenTypeAuth)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && tokenRecord.Id != user.Id {
+				t.Fatalf("Expected auth record %q, got %q", user.Id, tokenRecord.Id)
+			}
+		})
+	}
+
+	t.Run("revoked session", func(t *testing.T) {
+		token, err := user.NewSessionAuthToken(activeSession.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = app.Delete(activeSession); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = app.FindAuthRecordByToken(token, core.TokenTypeAuth); err == nil {
+			t.Fatal("Expected error for revoked session token")
+		}
+	})
+}
+
 func TestNewVerificationToken(t *testing.T) {
 	t.Parallel()
 
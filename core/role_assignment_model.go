@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameRoleAssignments = "_roleAssignments"
+
+var (
+	_ Model        = (*RoleAssignment)(nil)
+	_ PreValidator = (*RoleAssignment)(nil)
+	_ RecordProxy  = (*RoleAssignment)(nil)
+)
+
+// RoleAssignment defines a Record proxy for working with the roleAssignments collection.
+//
+// Each role assignment grants a single role to an auth record,
+// either globally (empty scope) or only for a single scope record
+// (ex. the id of an organization).
+type RoleAssignment struct {
+	*Record
+}
+
+// NewRoleAssignment instantiates and returns a new blank *RoleAssignment model.
+//
+// Example usage:
+//
+//	assignment := core.NewRoleAssignment(app)
+//	assignment.SetRecordRef(user.Id)
+//	assignment.SetCollectionRef(user.Collection().Id)
+//	assignment.SetRole(role.Id)
+//	assignment.SetScope(org.Id) // optional
+//	app.Save(assignment)
+func NewRoleAssignment(app App) *RoleAssignment {
+	m := &RoleAssignment{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameRoleAssignments)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on RoleAssignment.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *RoleAssignment) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameRoleAssignments {
+		return errors.New("missing or invalid RoleAssignment ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *RoleAssignment) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *RoleAssignment) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *RoleAssignment) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *RoleAssignment) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *RoleAssignment) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *RoleAssignment) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Role returns the "role" record field value (aka. the assigned Role model id).
+func (m *RoleAssignment) Role() string {
+	return m.GetString("role")
+}
+
+// SetRole updates the "role" record field value.
+func (m *RoleAssignment) SetRole(roleId string) {
+	m.Set("role", roleId)
+}
+
+// Scope returns the "scope" record field value.
+//
+// An empty scope means that the role is assigned globally.
+func (m *RoleAssignment) Scope() string {
+	return m.GetString("scope")
+}
+
+// SetScope updates the "scope" record field value.
+func (m *RoleAssignment) SetScope(scope string) {
+	m.Set("scope", scope)
+}
+
+// Created returns the "created" record field value.
+func (m *RoleAssignment) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *RoleAssignment) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerRoleAssignmentHooks() {
+	recordRefHooks[*RoleAssignment](app, CollectionNameRoleAssignments, CollectionTypeAuth)
+}
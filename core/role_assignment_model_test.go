@@ -0,0 +1,264 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewRoleAssignment(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	assignment := core.NewRoleAssignment(app)
+
+	if assignment.Collection().Name != core.CollectionNameRoleAssignments {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameRoleAssignments, assignment.Collection().Name)
+	}
+}
+
+func TestRoleAssignmentProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	assignment := core.RoleAssignment{}
+	assignment.SetProxyRecord(record)
+
+	if assignment.ProxyRecord() == nil || assignment.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, assignment.ProxyRecord())
+	}
+}
+
+func TestRoleAssignmentStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	assignment := core.NewRoleAssignment(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", assignment.SetCollectionRef, assignment.CollectionRef},
+		{"recordRef", assignment.SetRecordRef, assignment.RecordRef},
+		{"role", assignment.SetRole, assignment.Role},
+		{"scope", assignment.SetScope, assignment.Scope},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := assignment.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestRoleAssignmentPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	assignmentsCol, err := app.FindCollectionByNameOrId(core.CollectionNameRoleAssignments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := core.NewRole(app)
+	role.SetName("editor")
+	if err = app.Save(role); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		assignment := &core.RoleAssignment{}
+
+		if err := app.Validate(assignment); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-RoleAssignment collection", func(t *testing.T) {
+		assignment := &core.RoleAssignment{}
+		assignment.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		assignment.SetRecordRef(user.Id)
+		assignment.SetCollectionRef(user.Collection().Id)
+		assignment.SetRole(role.Id)
+
+		if err := app.Validate(assignment); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("RoleAssignment collection", func(t *testing.T) {
+		assignment := &core.RoleAssignment{}
+		assignment.SetProxyRecord(core.NewRecord(assignmentsCol))
+		assignment.SetRecordRef(user.Id)
+		assignment.SetCollectionRef(user.Collection().Id)
+		assignment.SetRole(role.Id)
+
+		if err := app.Validate(assignment); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestRoleAssignmentValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role := core.NewRole(app)
+	role.SetName("editor")
+	if err = app.Save(role); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		assignment   func() *core.RoleAssignment
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.RoleAssignment {
+				return core.NewRoleAssignment(app)
+			},
+			[]string{"collectionRef", "recordRef", "role"},
+		},
+		{
+			"non-auth collection",
+			func() *core.RoleAssignment {
+				assignment := core.NewRoleAssignment(app)
+				assignment.SetCollectionRef(demo1.Collection().Id)
+				assignment.SetRecordRef(demo1.Id)
+				assignment.SetRole(role.Id)
+				return assignment
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.RoleAssignment {
+				assignment := core.NewRoleAssignment(app)
+				assignment.SetCollectionRef(user.Collection().Id)
+				assignment.SetRecordRef("missing")
+				assignment.SetRole(role.Id)
+				return assignment
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"missing role",
+			func() *core.RoleAssignment {
+				assignment := core.NewRoleAssignment(app)
+				assignment.SetCollectionRef(user.Collection().Id)
+				assignment.SetRecordRef(user.Id)
+				assignment.SetRole("missing")
+				return assignment
+			},
+			[]string{"role"},
+		},
+		{
+			"valid ref",
+			func() *core.RoleAssignment {
+				assignment := core.NewRoleAssignment(app)
+				assignment.SetCollectionRef(user.Collection().Id)
+				assignment.SetRecordRef(user.Id)
+				assignment.SetRole(role.Id)
+				assignment.SetScope("org1")
+				return assignment
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.assignment())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestRoleAssignmentDeleteCascade(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubRoleAssignments(t, app)
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	role, err := app.FindRoleByName("editor")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = app.Delete(role); err != nil {
+		t.Fatal(err)
+	}
+
+	assignments, err := app.FindAllRoleAssignmentsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, a := range assignments {
+		if a.Role() == role.Id {
+			t.Fatalf("Expected the %q role assignments to be deleted", role.Name())
+		}
+	}
+
+	// deleting the auth record should delete its assignments too
+	if err = app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	assignments, err = app.FindAllRoleAssignmentsByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(assignments) != 0 {
+		t.Fatalf("Expected no assignments after the auth record deletion, got %d", len(assignments))
+	}
+}
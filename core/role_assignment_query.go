@@ -0,0 +1,117 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/dbutils"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// FindPermissionByName returns a single Permission model by its name.
+func (app *BaseApp) FindPermissionByName(name string) (*Permission, error) {
+	result := &Permission{}
+
+	err := app.RecordQuery(CollectionNamePermissions).
+		AndWhere(dbx.HashExp{"name": name}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindRoleByName returns a single Role model by its name.
+func (app *BaseApp) FindRoleByName(name string) (*Role, error) {
+	result := &Role{}
+
+	err := app.RecordQuery(CollectionNameRoles).
+		AndWhere(dbx.HashExp{"name": name}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAllRoleAssignmentsByRecord returns all RoleAssignment models
+// linked to the provided auth record (in DESC order).
+func (app *BaseApp) FindAllRoleAssignmentsByRecord(authRecord *Record) ([]*RoleAssignment, error) {
+	result := []*RoleAssignment{}
+
+	err := app.RecordQuery(CollectionNameRoleAssignments).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC", "rowid DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// HasPermission checks whether the provided auth record has been
+// assigned a role with the specified permission name.
+//
+// If scope is empty, only the global role assignments are checked,
+// otherwise - both the global and the scope specific ones.
+func (app *BaseApp) HasPermission(authRecord *Record, permission string, scope string) (bool, error) {
+	params := dbx.Params{
+		"collectionId": authRecord.Collection().Id,
+		"recordId":     authRecord.Id,
+		"permission":   permission,
+	}
+
+	scopeExpr := ""
+	if scope != "" {
+		scopeExpr = "{:scope}"
+		params["scope"] = scope
+	}
+
+	var exists bool
+
+	err := app.DB().
+		NewQuery("SELECT " + permissionCheckExpr("{:collectionId}", "{:recordId}", "{:permission}", scopeExpr)).
+		Bind(params).
+		Row(&exists)
+
+	return exists, err
+}
+
+// permissionCheckExpr returns a raw EXISTS SQL expression that checks
+// whether the auth record (identified by the collectionId and recordId
+// SQL expressions) has been assigned a role with the permission matching
+// the permission SQL expression.
+//
+// If scope is empty, only the global role assignments are checked,
+// otherwise - both the global and the ones matching the scope SQL expression.
+func permissionCheckExpr(collectionId, recordId, permission, scope string) string {
+	// unique aliases to avoid collisions with the outer query tables
+	suffix := security.PseudorandomString(6)
+	ra := "__ra" + suffix
+	r := "__r" + suffix
+	rp := "__rp" + suffix
+	p := "__p" + suffix
+
+	scopeCond := "[[" + ra + ".scope]] = ''"
+	if scope != "" {
+		scopeCond = "(" + scopeCond + " OR [[" + ra + ".scope]] = " + scope + ")"
+	}
+
+	return "EXISTS (SELECT 1 FROM {{" + CollectionNameRoleAssignments + "}} [[" + ra + "]]" +
+		" INNER JOIN {{" + CollectionNameRoles + "}} [[" + r + "]] ON [[" + r + ".id]] = [[" + ra + ".role]]" +
+		" INNER JOIN " + dbutils.JSONEach(r+".permissions") + " [[" + rp + "]]" +
+		" INNER JOIN {{" + CollectionNamePermissions + "}} [[" + p + "]] ON [[" + p + ".id]] = [[" + rp + ".value]]" +
+		" WHERE [[" + ra + ".collectionRef]] = " + collectionId +
+		" AND [[" + ra + ".recordRef]] = " + recordId +
+		" AND [[" + p + ".name]] = " + permission +
+		" AND " + scopeCond + ")"
+}
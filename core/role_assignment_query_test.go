@@ -0,0 +1,224 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+// stubRoleAssignments creates the following RBAC data:
+//   - "editor" role with "posts.view" and "posts.update" permissions
+//   - "admin" role with "posts.delete" permission
+//   - test@example.com - global "editor" and "org1" scoped "admin" assignments
+//   - test2@example.com - "org2" scoped "editor" assignment
+func stubRoleAssignments(t *testing.T, app core.App) {
+	permissions := map[string]*core.Permission{}
+	for _, name := range []string{"posts.view", "posts.update", "posts.delete"} {
+		permission := core.NewPermission(app)
+		permission.SetName(name)
+		if err := app.Save(permission); err != nil {
+			t.Fatal(err)
+		}
+		permissions[name] = permission
+	}
+
+	roles := map[string]*core.Role{}
+	for name, permissionNames := range map[string][]string{
+		"editor": {"posts.view", "posts.update"},
+		"admin":  {"posts.delete"},
+	} {
+		role := core.NewRole(app)
+		role.SetName(name)
+		ids := make([]string, len(permissionNames))
+		for i, pn := range permissionNames {
+			ids[i] = permissions[pn].Id
+		}
+		role.SetPermissions(ids)
+		if err := app.Save(role); err != nil {
+			t.Fatal(err)
+		}
+		roles[name] = role
+	}
+
+	assignments := []struct {
+		email string
+		role  string
+		scope string
+	}{
+		{"test@example.com", "editor", ""},
+		{"test@example.com", "admin", "org1"},
+		{"test2@example.com", "editor", "org2"},
+	}
+
+	for _, item := range assignments {
+		user, err := app.FindAuthRecordByEmail("users", item.email)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assignment := core.NewRoleAssignment(app)
+		assignment.SetCollectionRef(user.Collection().Id)
+		assignment.SetRecordRef(user.Id)
+		assignment.SetRole(roles[item.role].Id)
+		assignment.SetScope(item.scope)
+		if err := app.Save(assignment); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindPermissionByName(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubRoleAssignments(t, app)
+
+	scenarios := []struct {
+		name        string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"posts.view", false},
+		{"posts.delete", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			permission, err := app.FindPermissionByName(s.name)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && permission.Name() != s.name {
+				t.Fatalf("Expected permission %q, got %q", s.name, permission.Name())
+			}
+		})
+	}
+}
+
+func TestFindRoleByName(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubRoleAssignments(t, app)
+
+	scenarios := []struct {
+		name        string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{"editor", false},
+		{"admin", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			role, err := app.FindRoleByName(s.name)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && role.Name() != s.name {
+				t.Fatalf("Expected role %q, got %q", s.name, role.Name())
+			}
+		})
+	}
+}
+
+func TestFindAllRoleAssignmentsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubRoleAssignments(t, app)
+
+	scenarios := []struct {
+		email          string
+		expectedScopes []string
+	}{
+		{"test@example.com", []string{"org1", ""}},
+		{"test2@example.com", []string{"org2"}},
+		{"test3@example.com", []string{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.email, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.FindAllRoleAssignmentsByRecord(user)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expectedScopes) {
+				t.Fatalf("Expected %d assignments, got %d", len(s.expectedScopes), len(result))
+			}
+
+			for i, assignment := range result {
+				if assignment.Scope() != s.expectedScopes[i] {
+					t.Fatalf("Expected assignment %d with scope %q, got %q", i, s.expectedScopes[i], assignment.Scope())
+				}
+			}
+		})
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubRoleAssignments(t, app)
+
+	scenarios := []struct {
+		name       string
+		email      string
+		permission string
+		scope      string
+		expected   bool
+	}{
+		{"missing permission", "test@example.com", "missing", "", false},
+		{"global assignment", "test@example.com", "posts.update", "", true},
+		{"global assignment with scope", "test@example.com", "posts.update", "org2", true},
+		{"scoped assignment without scope", "test@example.com", "posts.delete", "", false},
+		{"scoped assignment with matching scope", "test@example.com", "posts.delete", "org1", true},
+		{"scoped assignment with different scope", "test@example.com", "posts.delete", "org2", false},
+		{"other user scoped assignment", "test2@example.com", "posts.view", "org2", true},
+		{"other user without scope", "test2@example.com", "posts.view", "", false},
+		{"user without assignments", "test3@example.com", "posts.view", "", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			user, err := app.FindAuthRecordByEmail("users", s.email)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := app.HasPermission(user, s.permission, s.scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameRoles = "_roles"
+
+var (
+	_ Model        = (*Role)(nil)
+	_ PreValidator = (*Role)(nil)
+	_ RecordProxy  = (*Role)(nil)
+)
+
+// Role defines a Record proxy for working with the roles collection.
+//
+// A role is a named set of permissions that could be assigned to the
+// auth records (optionally scoped to a single record, ex. an organization).
+type Role struct {
+	*Record
+}
+
+// NewRole instantiates and returns a new blank *Role model.
+//
+// Example usage:
+//
+//	role := core.NewRole(app)
+//	role.SetName("editor")
+//	role.SetPermissions([]string{permission.Id})
+//	app.Save(role)
+func NewRole(app App) *Role {
+	m := &Role{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameRoles)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on Role.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *Role) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameRoles {
+		return errors.New("missing or invalid Role ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *Role) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *Role) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// Name returns the "name" record field value.
+func (m *Role) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *Role) SetName(name string) {
+	m.Set("name", name)
+}
+
+// Description returns the "description" record field value.
+func (m *Role) Description() string {
+	return m.GetString("description")
+}
+
+// SetDescription updates the "description" record field value.
+func (m *Role) SetDescription(description string) {
+	m.Set("description", description)
+}
+
+// Permissions returns the "permissions" record field value
+// (aka. the ids of the role Permission models).
+func (m *Role) Permissions() []string {
+	return m.GetStringSlice("permissions")
+}
+
+// SetPermissions updates the "permissions" record field value.
+func (m *Role) SetPermissions(permissionIds []string) {
+	m.Set("permissions", permissionIds)
+}
+
+// Created returns the "created" record field value.
+func (m *Role) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *Role) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
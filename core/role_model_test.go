@@ -0,0 +1,184 @@
+package core_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewRole(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	role := core.NewRole(app)
+
+	if role.Collection().Name != core.CollectionNameRoles {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameRoles, role.Collection().Name)
+	}
+}
+
+func TestRoleProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	role := core.Role{}
+	role.SetProxyRecord(record)
+
+	if role.ProxyRecord() == nil || role.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, role.ProxyRecord())
+	}
+}
+
+func TestRoleStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	role := core.NewRole(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"name", role.SetName, role.Name},
+		{"description", role.SetDescription, role.Description},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := role.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestRolePermissions(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	role := core.NewRole(app)
+
+	for i, testValue := range [][]string{{"a", "b"}, {"c"}, {}} {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			role.SetPermissions(testValue)
+
+			if v := role.Permissions(); !slices.Equal(v, testValue) {
+				t.Fatalf("Expected permissions %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestRolePreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	rolesCol, err := app.FindCollectionByNameOrId(core.CollectionNameRoles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		role := &core.Role{}
+
+		if err := app.Validate(role); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-Role collection", func(t *testing.T) {
+		role := &core.Role{}
+		role.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		role.SetName("editor")
+
+		if err := app.Validate(role); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("Role collection", func(t *testing.T) {
+		role := &core.Role{}
+		role.SetProxyRecord(core.NewRecord(rolesCol))
+		role.SetName("editor")
+
+		if err := app.Validate(role); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestRoleValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	permission := core.NewPermission(app)
+	permission.SetName("posts.update")
+	if err := app.Save(permission); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		role         func() *core.Role
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.Role {
+				return core.NewRole(app)
+			},
+			[]string{"name"},
+		},
+		{
+			"missing permission",
+			func() *core.Role {
+				role := core.NewRole(app)
+				role.SetName("editor")
+				role.SetPermissions([]string{permission.Id, "missing"})
+				return role
+			},
+			[]string{"permissions"},
+		},
+		{
+			"valid role",
+			func() *core.Role {
+				role := core.NewRole(app)
+				role.SetName("editor")
+				role.SetPermissions([]string{permission.Id})
+				return role
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.role())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/search"
 	"github.com/pocketbase/pocketbase/tools/security"
 	"github.com/pocketbase/pocketbase/tools/types"
 )
@@ -128,6 +129,7 @@ type settings struct {
 	TrustedProxy TrustedProxyConfig `form:"trustedProxy" json:"trustedProxy"`
 	Batch        BatchConfig        `form:"batch" json:"batch"`
 	Logs         LogsConfig         `form:"logs" json:"logs"`
+	Realtime     RealtimeConfig     `form:"realtime" json:"realtime"`
 }
 
 // Settings defines the PocketBase app settings.
@@ -287,6 +289,7 @@ func (s *Settings) PostValidate(ctx context.Context, app App) error {
 		validation.Field(&s.Batch),
 		validation.Field(&s.RateLimits),
 		validation.Field(&s.TrustedProxy),
+		validation.Field(&s.Realtime, validation.By(checkRealtimeChannelRules(app))),
 	)
 }
 
@@ -714,3 +717,166 @@ func (c RateLimitRule) String() string {
 
 	return string(raw)
 }
+
+// -------------------------------------------------------------------
+
+type RealtimeConfig struct {
+	// Channels is a list with the custom realtime broadcast channels
+	// that the clients could subscribe and publish to.
+	Channels []RealtimeChannel `form:"channels" json:"channels"`
+}
+
+// FindChannel returns the channel configuration matching the provided channel name.
+//
+// Exact name matches have priority over the wildcard ones
+// and if multiple wildcard channels match, the longest one is returned.
+func (c *RealtimeConfig) FindChannel(name string) (RealtimeChannel, bool) {
+	var found *RealtimeChannel
+
+	for i, ch := range c.Channels {
+		if ch.Name == name {
+			return ch, true
+		}
+
+		prefix, ok := strings.CutSuffix(ch.Name, "*")
+		if ok && strings.HasPrefix(name, prefix) && (found == nil || len(ch.Name) > len(found.Name)) {
+			found = &c.Channels[i]
+		}
+	}
+
+	if found == nil {
+		return RealtimeChannel{}, false
+	}
+
+	return *found, true
+}
+
+// MarshalJSON implements the [json.Marshaler] interface.
+func (c RealtimeConfig) MarshalJSON() ([]byte, error) {
+	type alias RealtimeConfig
+
+	// serialize as empty array
+	if c.Channels == nil {
+		c.Channels = []RealtimeChannel{}
+	}
+
+	return json.Marshal(alias(c))
+}
+
+// Validate makes RealtimeConfig validatable by implementing [validation.Validatable] interface.
+func (c RealtimeConfig) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Channels, validation.By(checkUniqueChannelName)),
+	)
+}
+
+func checkUniqueChannelName(value any) error {
+	channels, ok := value.([]RealtimeChannel)
+	if !ok {
+		return validators.ErrUnsupportedValueType
+	}
+
+	existing := make([]string, 0, len(channels))
+
+	for i, ch := range channels {
+		if slices.Contains(existing, ch.Name) {
+			return validation.Errors{
+				strconv.Itoa(i): validation.Errors{
+					"name": validation.NewError("validation_duplicated_channel_name", "Realtime channel with name {{.name}} already exists.").
+						SetParams(map[string]any{"name": ch.Name}),
+				},
+			}
+		}
+
+		existing = append(existing, ch.Name)
+	}
+
+	return nil
+}
+
+func checkRealtimeChannelRules(app App) validation.RuleFunc {
+	return func(value any) error {
+		config, ok := value.(RealtimeConfig)
+		if !ok {
+			return validators.ErrUnsupportedValueType
+		}
+
+		for i, ch := range config.Channels {
+			rules := map[string]*string{
+				"subscribeRule": ch.SubscribeRule,
+				"publishRule":   ch.PublishRule,
+			}
+
+			for key, rule := range rules {
+				if rule == nil || *rule == "" {
+					continue
+				}
+
+				resolver := newRealtimeChannelFieldResolver(app, &RequestInfo{})
+				if _, err := search.FilterData(*rule).BuildExpr(resolver); err != nil {
+					return validation.Errors{
+						"channels": validation.Errors{
+							strconv.Itoa(i): validation.Errors{
+								key: validation.NewError("validation_invalid_rule", "Invalid rule. Raw error: "+err.Error()),
+							},
+						},
+					}
+				}
+			}
+		}
+
+		return nil
+	}
+}
+
+// realtimeChannelNamePattern is the regex pattern of a single concrete realtime channel name.
+const realtimeChannelNamePattern = `[\w\-]+(\/[\w\-]+)*`
+
+// RealtimeChannelNameRegex matches a single concrete realtime channel name (eg. "chat/room123").
+var RealtimeChannelNameRegex = regexp.MustCompile(`^` + realtimeChannelNamePattern + `$`)
+
+// realtimeChannelConfigNameRegex matches a configured realtime channel name,
+// optionally ending with "*" wildcard.
+//
+// Example supported names: chat, chat/room123, chat/*, typing-*
+var realtimeChannelConfigNameRegex = regexp.MustCompile(`^` + realtimeChannelNamePattern + `(\/?\*)?$`)
+
+type RealtimeChannel struct {
+	// Name is the channel name or channel name prefix when ends with "*".
+	//
+	// Example supported names:
+	//   - chat (matches only "chat")
+	//   - chat/room123 (matches only "chat/room123")
+	//   - chat/* (matches "chat/room123", "chat/room123/typing", etc.)
+	//
+	// Note that the channel names shouldn't clash with the collection
+	// record subscription topics (aka. "COLLECTION/RECORD_ID").
+	Name string `form:"name" json:"name"`
+
+	// SubscribeRule is an optional filter expression that is checked for each
+	// subscribed client every time when a message is published to the channel.
+	//
+	// Similar to the collection API rules, nil means that only superusers
+	// could subscribe and empty string means that everyone could subscribe.
+	//
+	// Only the @request.* and @collection.* fields are available in the rule
+	// (@request.body contains the published "channel" name and message "data").
+	SubscribeRule *string `form:"subscribeRule" json:"subscribeRule"`
+
+	// PublishRule is an optional filter expression that is checked
+	// every time when a client tries to publish to the channel.
+	//
+	// Similar to the collection API rules, nil means that only superusers
+	// could publish and empty string means that everyone could publish.
+	//
+	// Only the @request.* and @collection.* fields are available in the rule
+	// (@request.body contains the published "channel" name and message "data").
+	PublishRule *string `form:"publishRule" json:"publishRule"`
+}
+
+// Validate makes RealtimeChannel validatable by implementing [validation.Validatable] interface.
+func (c RealtimeChannel) Validate() error {
+	return validation.ValidateStruct(&c,
+		validation.Field(&c.Name, validation.Required, validation.Length(1, 255), validation.Match(realtimeChannelConfigNameRegex)),
+	)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
 	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/types"
 )
 
 func TestSettingsDelete(t *testing.T) {
@@ -106,7 +107,7 @@ func TestSettingsMarshalJSON(t *testing.T) {
 	}
 	rawStr := string(raw)
 
-	expected := `{"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"meta":{"appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false}}`
+	expected := `{"smtp":{"enabled":false,"port":0,"host":"","username":"abc","authMethod":"","tls":false,"localName":""},"backups":{"cron":"","cronMaxKeep":0,"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false}},"s3":{"enabled":false,"bucket":"","region":"","endpoint":"","accessKey":"","forcePathStyle":false},"meta":{"appName":"test123","appURL":"","senderName":"","senderAddress":"","hideControls":false},"rateLimits":{"rules":[],"enabled":false},"trustedProxy":{"headers":[],"useLeftmostIP":false},"batch":{"enabled":false,"maxRequests":0,"timeout":0,"maxBodySize":0},"logs":{"maxDays":0,"minLevel":0,"logIP":false,"logAuthId":false},"realtime":{"channels":[]}}`
 
 	if rawStr != expected {
 		t.Fatalf("Expected\n%v\ngot\n%v", expected, rawStr)
@@ -135,6 +136,7 @@ func TestSettingsValidate(t *testing.T) {
 	s.Batch.Timeout = -1
 	s.RateLimits.Enabled = true
 	s.RateLimits.Rules = nil
+	s.Realtime.Channels = []core.RealtimeChannel{{Name: "invalid name"}}
 
 	// check if Validate() is triggering the members validate methods.
 	err := app.Validate(s)
@@ -150,6 +152,7 @@ func TestSettingsValidate(t *testing.T) {
 		`"backups":{`,
 		`"batch":{`,
 		`"rateLimits":{`,
+		`"realtime":{`,
 	}
 
 	errBytes, _ := json.Marshal(err)
@@ -844,3 +847,200 @@ func TestRateLimitRuleString(t *testing.T) {
 		})
 	}
 }
+
+func TestSettingsValidateRealtimeChannelRules(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name        string
+		channels    []core.RealtimeChannel
+		expectError bool
+	}{
+		{
+			"nil and empty rules",
+			[]core.RealtimeChannel{
+				{Name: "a", SubscribeRule: nil, PublishRule: types.Pointer("")},
+			},
+			false,
+		},
+		{
+			"valid @request and @collection rules",
+			[]core.RealtimeChannel{
+				{
+					Name:          "a",
+					SubscribeRule: types.Pointer("@request.auth.id != '' && @request.body.channel = 'a'"),
+					PublishRule:   types.Pointer("@collection.demo1.text ?= @request.body.data.text"),
+				},
+			},
+			false,
+		},
+		{
+			"invalid subscribe rule",
+			[]core.RealtimeChannel{
+				{Name: "a"},
+				{Name: "b", SubscribeRule: types.Pointer("id = 'test'")},
+			},
+			true,
+		},
+		{
+			"invalid publish rule",
+			[]core.RealtimeChannel{
+				{Name: "a", PublishRule: types.Pointer("@collection.missing.id = ''")},
+			},
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			settings, err := app.Settings().Clone()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			settings.Realtime.Channels = s.channels
+
+			err = app.Validate(settings)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				errBytes, _ := json.Marshal(err)
+				if !strings.Contains(string(errBytes), `"realtime":{"channels":{`) {
+					t.Fatalf("Expected realtime channels error, got %s", errBytes)
+				}
+			}
+		})
+	}
+}
+
+func TestRealtimeConfigValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		config         core.RealtimeConfig
+		expectedErrors []string
+	}{
+		{
+			"zero value",
+			core.RealtimeConfig{},
+			[]string{},
+		},
+		{
+			"invalid channel name",
+			core.RealtimeConfig{
+				Channels: []core.RealtimeChannel{
+					{Name: "chat/*"},
+					{Name: "chat room"},
+				},
+			},
+			[]string{"channels"},
+		},
+		{
+			"duplicated channel names",
+			core.RealtimeConfig{
+				Channels: []core.RealtimeChannel{
+					{Name: "chat/*"},
+					{Name: "chat"},
+					{Name: "chat/*"},
+				},
+			},
+			[]string{"channels"},
+		},
+		{
+			"valid data",
+			core.RealtimeConfig{
+				Channels: []core.RealtimeChannel{
+					{Name: "chat/*", SubscribeRule: types.Pointer("")},
+					{Name: "chat"},
+					{Name: "typing-*"},
+					{Name: "a/b/c", PublishRule: types.Pointer("@request.auth.id != ''")},
+				},
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.config.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestRealtimeChannelValidate(t *testing.T) {
+	scenarios := []struct {
+		name           string
+		channel        core.RealtimeChannel
+		expectedErrors []string
+	}{
+		{"zero value", core.RealtimeChannel{}, []string{"name"}},
+		{"too long name", core.RealtimeChannel{Name: strings.Repeat("a", 256)}, []string{"name"}},
+		{"name with space", core.RealtimeChannel{Name: "a b"}, []string{"name"}},
+		{"name with leading slash", core.RealtimeChannel{Name: "/a"}, []string{"name"}},
+		{"name with trailing slash", core.RealtimeChannel{Name: "a/"}, []string{"name"}},
+		{"name with middle wildcard", core.RealtimeChannel{Name: "a/*/b"}, []string{"name"}},
+		{"plain name", core.RealtimeChannel{Name: "a"}, []string{}},
+		{"nested name", core.RealtimeChannel{Name: "a/b-c/d_e"}, []string{}},
+		{"wildcard name", core.RealtimeChannel{Name: "a/*"}, []string{}},
+		{"prefix wildcard name", core.RealtimeChannel{Name: "a-*"}, []string{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result := s.channel.Validate()
+
+			tests.TestValidationErrors(t, result, s.expectedErrors)
+		})
+	}
+}
+
+func TestRealtimeConfigFindChannel(t *testing.T) {
+	config := core.RealtimeConfig{
+		Channels: []core.RealtimeChannel{
+			{Name: "t*"},
+			{Name: "chat/*"},
+			{Name: "chat/room1"},
+			{Name: "chat/room1/*"},
+			{Name: "typing"},
+		},
+	}
+
+	scenarios := []struct {
+		name     string
+		expected string
+	}{
+		{"", ""},
+		{"missing", ""},
+		{"chat", ""},
+		{"typing", "typing"},
+		{"typing/a", "t*"},
+		{"test", "t*"},
+		{"chat/room1", "chat/room1"},
+		{"chat/room2", "chat/*"},
+		{"chat/room1/typing", "chat/room1/*"},
+		{"chat/room2/typing", "chat/*"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			channel, ok := config.FindChannel(s.name)
+
+			hasName := channel.Name != ""
+			if hasName != ok {
+				t.Fatalf("Expected hasName %v, got %v", hasName, ok)
+			}
+
+			if channel.Name != s.expected {
+				t.Fatalf("Expected channel %q, got %q", s.expected, channel.Name)
+			}
+		})
+	}
+}
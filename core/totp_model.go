@@ -0,0 +1,261 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameTOTPs = "_totps"
+
+// totpSkew is the number of the allowed time steps for clock drift in each direction.
+const totpSkew = 1
+
+const totpRecoveryCodeAlphabet = "abcdefghijkmnpqrstuvwxyz23456789"
+
+var (
+	_ Model        = (*TOTP)(nil)
+	_ PreValidator = (*TOTP)(nil)
+	_ RecordProxy  = (*TOTP)(nil)
+)
+
+// TOTPEncryptionKey returns the 32 characters AES key used to encrypt
+// the stored TOTP secrets of the specified auth collection records.
+//
+// The key is derived from the app encryption env value (see [App.EncryptionEnv])
+// so that it is not stored in the database next to the encrypted secrets.
+// If the app encryption env is not set, the key is derived only from the
+// collection id, aka. similar to the app settings the TOTP secrets
+// are practically stored unencrypted.
+//
+// Note that changing the app encryption env value invalidates all existing TOTP enrollments.
+func TOTPEncryptionKey(app App, collection *Collection) string {
+	return security.HS256("totp_"+collection.Id, os.Getenv(app.EncryptionEnv()))[:32]
+}
+
+// TOTP defines a Record proxy for working with the totps collection
+// (aka. the authenticator app enrollments of the auth records).
+type TOTP struct {
+	*Record
+}
+
+// NewTOTP instantiates and returns a new blank *TOTP model.
+//
+// Example usage:
+//
+//	totp := core.NewTOTP(app)
+//	totp.SetRecordRef(user.Id)
+//	totp.SetCollectionRef(user.Collection().Id)
+//	totp.SetSecret(security.NewTOTPSecret(), core.TOTPEncryptionKey(app, user.Collection()))
+//	app.Save(totp)
+func NewTOTP(app App) *TOTP {
+	m := &TOTP{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameTOTPs)
+	if err != nil {
+		// this is just to make tests easier since totp is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on TOTP.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *TOTP) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameTOTPs {
+		return errors.New("missing or invalid totp ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *TOTP) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *TOTP) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// CollectionRef returns the "collectionRef" field value.
+func (m *TOTP) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *TOTP) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *TOTP) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *TOTP) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Secret decrypts and returns the "secret" record field value
+// using the specified 32 characters encryption key.
+func (m *TOTP) Secret(encryptionKey string) (string, error) {
+	raw, err := security.Decrypt(m.GetString("secret"), encryptionKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(raw), nil
+}
+
+// SetSecret encrypts the provided base32 encoded shared secret with
+// the specified 32 characters encryption key and updates the "secret"
+// record field value.
+func (m *TOTP) SetSecret(secret string, encryptionKey string) error {
+	encrypted, err := security.Encrypt([]byte(secret), encryptionKey)
+	if err != nil {
+		return err
+	}
+
+	m.Set("secret", encrypted)
+
+	return nil
+}
+
+// Verified returns the "verified" record field value
+// (aka. whether the enrollment was confirmed with a valid code).
+func (m *TOTP) Verified() bool {
+	return m.GetBool("verified")
+}
+
+// SetVerified updates the "verified" record field value.
+func (m *TOTP) SetVerified(verified bool) {
+	m.Set("verified", verified)
+}
+
+// LastStep returns the "lastStep" record field value
+// (aka. the time step of the last successfully used code).
+func (m *TOTP) LastStep() int64 {
+	return int64(m.GetInt("lastStep"))
+}
+
+// SetLastStep updates the "lastStep" record field value.
+func (m *TOTP) SetLastStep(step int64) {
+	m.Set("lastStep", step)
+}
+
+// VerifyCode checks whether the provided code is valid for the
+// current secret and it wasn't already used.
+//
+// On success the "lastStep" field is updated to prevent the code reuse
+// (the caller is responsible for persisting the change).
+// Use [App.ConsumeTOTPCode] if the code could be submitted concurrently.
+func (m *TOTP) VerifyCode(code string, encryptionKey string) bool {
+	secret, err := m.Secret(encryptionKey)
+	if err != nil {
+		return false
+	}
+
+	step, ok := security.ValidateTOTP(secret, code, time.Now(), totpSkew)
+	if !ok || step <= m.LastStep() {
+		return false
+	}
+
+	m.SetLastStep(step)
+
+	return true
+}
+
+// GenerateRecoveryCodes generates and returns total number of new
+// single-use recovery codes, replacing the existing ones.
+//
+// Only the hashes of the codes are stored in the "recoveryCodes" record field.
+func (m *TOTP) GenerateRecoveryCodes(total int) []string {
+	codes := make([]string, total)
+	hashes := make([]string, total)
+
+	for i := range codes {
+		code := security.RandomStringWithAlphabet(5, totpRecoveryCodeAlphabet) + "-" +
+			security.RandomStringWithAlphabet(5, totpRecoveryCodeAlphabet)
+
+		codes[i] = code
+		hashes[i] = security.SHA256(code)
+	}
+
+	m.Set("recoveryCodes", hashes)
+
+	return codes
+}
+
+// TotalRecoveryCodes returns the number of the remaining unused recovery codes.
+func (m *TOTP) TotalRecoveryCodes() int {
+	return len(m.recoveryCodeHashes())
+}
+
+// UseRecoveryCode checks whether the provided code matches one of the
+// remaining recovery codes and if so, removes it from the list.
+//
+// The caller is responsible for persisting the change.
+// Use [App.ConsumeTOTPCode] if the code could be submitted concurrently.
+func (m *TOTP) UseRecoveryCode(code string) bool {
+	hash := recoveryCodeHash(code)
+	if hash == "" {
+		return false
+	}
+
+	hashes := m.recoveryCodeHashes()
+
+	for i, h := range hashes {
+		if security.Equal(h, hash) {
+			m.Set("recoveryCodes", slices.Delete(hashes, i, i+1))
+			return true
+		}
+	}
+
+	return false
+}
+
+// recoveryCodeHash returns the hash of the normalized recovery code
+// or empty string if the code is empty.
+func recoveryCodeHash(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return ""
+	}
+
+	return security.SHA256(code)
+}
+
+func (m *TOTP) recoveryCodeHashes() []string {
+	var hashes []string
+
+	_ = m.UnmarshalJSONField("recoveryCodes", &hashes)
+
+	return hashes
+}
+
+// Created returns the "created" record field value.
+func (m *TOTP) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *TOTP) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+func (app *BaseApp) registerTOTPHooks() {
+	recordRefHooks[*TOTP](app, CollectionNameTOTPs, CollectionTypeAuth)
+}
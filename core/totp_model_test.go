@@ -0,0 +1,437 @@
+package core_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+const testTOTPEncryptionKey = "abcdefghijklmnopqrstuvwxyz123456"
+
+func TestNewTOTP(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	if totp.Collection().Name != core.CollectionNameTOTPs {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameTOTPs, totp.Collection().Name)
+	}
+}
+
+func TestTOTPEncryptionKey(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clients, err := app.FindCollectionByNameOrId("clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(app.EncryptionEnv(), "")
+	noEnvKey := core.TOTPEncryptionKey(app, users)
+
+	t.Setenv(app.EncryptionEnv(), "test_env_secret")
+	usersKey := core.TOTPEncryptionKey(app, users)
+	clientsKey := core.TOTPEncryptionKey(app, clients)
+
+	for _, key := range []string{noEnvKey, usersKey, clientsKey} {
+		if len(key) != 32 {
+			t.Fatalf("Expected 32 characters key, got %q", key)
+		}
+	}
+
+	if usersKey == noEnvKey {
+		t.Fatal("Expected the key to be derived from the app encryption env")
+	}
+
+	if usersKey == clientsKey {
+		t.Fatal("Expected different keys for the different collections")
+	}
+
+	if usersKey != core.TOTPEncryptionKey(app, users) {
+		t.Fatal("Expected the same key for the same collection")
+	}
+
+	if strings.Contains(string(users.RawOptions), usersKey) {
+		t.Fatal("Expected the key to not be stored in the collection options")
+	}
+}
+
+func TestTOTPProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	totp := core.TOTP{}
+	totp.SetProxyRecord(record)
+
+	if totp.ProxyRecord() == nil || totp.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, totp.ProxyRecord())
+	}
+}
+
+func TestTOTPRecordRef(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	testValues := []string{"test_1", "test2", ""}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%q", i, testValue), func(t *testing.T) {
+			totp.SetRecordRef(testValue)
+
+			if v := totp.RecordRef(); v != testValue {
+				t.Fatalf("Expected getter %q, got %q", testValue, v)
+			}
+
+			if v := totp.GetString("recordRef"); v != testValue {
+				t.Fatalf("Expected field value %q, got %q", testValue, v)
+			}
+		})
+	}
+}
+
+func TestTOTPCollectionRef(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	testValues := []string{"test_1", "test2", ""}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%q", i, testValue), func(t *testing.T) {
+			totp.SetCollectionRef(testValue)
+
+			if v := totp.CollectionRef(); v != testValue {
+				t.Fatalf("Expected getter %q, got %q", testValue, v)
+			}
+
+			if v := totp.GetString("collectionRef"); v != testValue {
+				t.Fatalf("Expected field value %q, got %q", testValue, v)
+			}
+		})
+	}
+}
+
+func TestTOTPVerified(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	testValues := []bool{true, false}
+	for i, testValue := range testValues {
+		t.Run(fmt.Sprintf("%d_%v", i, testValue), func(t *testing.T) {
+			totp.SetVerified(testValue)
+
+			if v := totp.Verified(); v != testValue {
+				t.Fatalf("Expected getter %v, got %v", testValue, v)
+			}
+
+			if v := totp.GetBool("verified"); v != testValue {
+				t.Fatalf("Expected field value %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestTOTPSecret(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	if err := totp.SetSecret("ABC", "invalid"); err == nil {
+		t.Fatal("Expected invalid encryption key error")
+	}
+
+	if err := totp.SetSecret("ABC", testTOTPEncryptionKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if raw := totp.GetString("secret"); raw == "" || raw == "ABC" {
+		t.Fatalf("Expected the stored secret to be encrypted, got %q", raw)
+	}
+
+	if _, err := totp.Secret("abcdefghijklmnopqrstuvwxyz000000"); err == nil {
+		t.Fatal("Expected decrypt error with different encryption key")
+	}
+
+	secret, err := totp.Secret(testTOTPEncryptionKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if secret != "ABC" {
+		t.Fatalf("Expected secret %q, got %q", "ABC", secret)
+	}
+}
+
+func TestTOTPVerifyCode(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	secret := security.NewTOTPSecret()
+
+	totp := core.NewTOTP(app)
+	if err := totp.SetSecret(secret, testTOTPEncryptionKey); err != nil {
+		t.Fatal(err)
+	}
+
+	step := security.TOTPStep(time.Now())
+
+	prevCode, _ := security.TOTPCode(secret, step-1)
+	code, _ := security.TOTPCode(secret, step)
+	oldCode, _ := security.TOTPCode(secret, step-5)
+
+	if totp.VerifyCode(code, "abcdefghijklmnopqrstuvwxyz000000") {
+		t.Fatal("Expected code verification to fail with different encryption key")
+	}
+
+	if totp.VerifyCode(oldCode, testTOTPEncryptionKey) {
+		t.Fatal("Expected old code verification to fail")
+	}
+
+	if !totp.VerifyCode(code, testTOTPEncryptionKey) {
+		t.Fatal("Expected current code verification to succeed")
+	}
+
+	if totp.LastStep() != step {
+		t.Fatalf("Expected last step %d, got %d", step, totp.LastStep())
+	}
+
+	if totp.VerifyCode(code, testTOTPEncryptionKey) {
+		t.Fatal("Expected the already used code verification to fail")
+	}
+
+	if totp.VerifyCode(prevCode, testTOTPEncryptionKey) {
+		t.Fatal("Expected the code before the last used step to fail")
+	}
+}
+
+func TestTOTPRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totp := core.NewTOTP(app)
+
+	if totp.TotalRecoveryCodes() != 0 {
+		t.Fatalf("Expected no recovery codes, got %d", totp.TotalRecoveryCodes())
+	}
+
+	codes := totp.GenerateRecoveryCodes(3)
+	if len(codes) != 3 {
+		t.Fatalf("Expected 3 recovery codes, got %v", codes)
+	}
+
+	if totp.TotalRecoveryCodes() != 3 {
+		t.Fatalf("Expected 3 stored recovery codes, got %d", totp.TotalRecoveryCodes())
+	}
+
+	if raw := totp.GetString("recoveryCodes"); slices.ContainsFunc(codes, func(c string) bool {
+		return strings.Contains(raw, c)
+	}) {
+		t.Fatalf("Expected only the recovery codes hashes to be stored, got %s", raw)
+	}
+
+	if totp.UseRecoveryCode("") || totp.UseRecoveryCode("invalid") {
+		t.Fatal("Expected invalid recovery codes to fail")
+	}
+
+	if !totp.UseRecoveryCode(" " + codes[1] + " ") {
+		t.Fatalf("Expected recovery code %q to be valid", codes[1])
+	}
+
+	if totp.UseRecoveryCode(codes[1]) {
+		t.Fatalf("Expected recovery code %q to be single use", codes[1])
+	}
+
+	if totp.TotalRecoveryCodes() != 2 {
+		t.Fatalf("Expected 2 remaining recovery codes, got %d", totp.TotalRecoveryCodes())
+	}
+
+	// regenerate
+	newCodes := totp.GenerateRecoveryCodes(2)
+	if totp.UseRecoveryCode(codes[0]) {
+		t.Fatalf("Expected the old recovery code %q to be invalidated", codes[0])
+	}
+	if !totp.UseRecoveryCode(newCodes[0]) {
+		t.Fatalf("Expected the new recovery code %q to be valid", newCodes[0])
+	}
+}
+
+func TestTOTPPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	totpsCol, err := app.FindCollectionByNameOrId(core.CollectionNameTOTPs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		totp := &core.TOTP{}
+
+		if err := app.Validate(totp); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-TOTP collection", func(t *testing.T) {
+		totp := &core.TOTP{}
+		totp.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		totp.SetRecordRef(user.Id)
+		totp.SetCollectionRef(user.Collection().Id)
+		totp.SetSecret("ABC", testTOTPEncryptionKey)
+
+		if err := app.Validate(totp); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("TOTP collection", func(t *testing.T) {
+		totp := &core.TOTP{}
+		totp.SetProxyRecord(core.NewRecord(totpsCol))
+		totp.SetRecordRef(user.Id)
+		totp.SetCollectionRef(user.Collection().Id)
+		totp.SetSecret("ABC", testTOTPEncryptionKey)
+
+		if err := app.Validate(totp); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestTOTPValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		totp         func() *core.TOTP
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.TOTP {
+				return core.NewTOTP(app)
+			},
+			[]string{"collectionRef", "recordRef", "secret"},
+		},
+		{
+			"non-auth collection",
+			func() *core.TOTP {
+				totp := core.NewTOTP(app)
+				totp.SetCollectionRef(demo1.Collection().Id)
+				totp.SetRecordRef(demo1.Id)
+				totp.SetSecret("ABC", testTOTPEncryptionKey)
+				return totp
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.TOTP {
+				totp := core.NewTOTP(app)
+				totp.SetCollectionRef(user.Collection().Id)
+				totp.SetRecordRef("missing")
+				totp.SetSecret("ABC", testTOTPEncryptionKey)
+				return totp
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.TOTP {
+				totp := core.NewTOTP(app)
+				totp.SetCollectionRef(user.Collection().Id)
+				totp.SetRecordRef(user.Id)
+				totp.SetSecret("ABC", testTOTPEncryptionKey)
+				return totp
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.totp())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestTOTPDeleteWithAuthRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totp := core.NewTOTP(app)
+	totp.SetCollectionRef(user.Collection().Id)
+	totp.SetRecordRef(user.Id)
+	totp.SetSecret("ABC", testTOTPEncryptionKey)
+	if err := app.Save(totp); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.Delete(user); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := app.FindRecordById(core.CollectionNameTOTPs, totp.Id); err == nil {
+		t.Fatal("Expected the TOTP to be deleted together with its auth record")
+	}
+}
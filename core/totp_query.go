@@ -0,0 +1,99 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// ErrInvalidTOTPCode is returned when the submitted TOTP or recovery
+// code is invalid or it was already used.
+var ErrInvalidTOTPCode = errors.New("invalid or already used TOTP code")
+
+// FindTOTPByRecord returns the TOTP model linked to the provided auth record.
+//
+// Returns [sql.ErrNoRows] if the auth record doesn't have a TOTP enrollment.
+func (app *BaseApp) FindTOTPByRecord(authRecord *Record) (*TOTP, error) {
+	result := &TOTP{}
+
+	err := app.RecordQuery(CollectionNameTOTPs).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ConsumeTOTPCode verifies the provided TOTP code (or one of the remaining
+// recovery codes if allowRecoveryCode is set) and marks it as used.
+//
+// The code is marked as used with a single conditional db update so
+// that when the same code is submitted concurrently only one of the calls succeeds.
+// The others fail with [ErrInvalidTOTPCode].
+//
+// Returns whether a recovery code was used.
+//
+// Note that no model hooks are triggered for the totp update.
+func (app *BaseApp) ConsumeTOTPCode(totp *TOTP, code string, encryptionKey string, allowRecoveryCode bool) (bool, error) {
+	// update a copy to leave the totp unchanged in case of a failure
+	consumed := &TOTP{Record: totp.Clone()}
+	consumed.SetRaw("updated", types.NowDateTime())
+
+	var usedRecoveryCode bool
+	var cols dbx.Params
+	var where dbx.Expression
+
+	if consumed.VerifyCode(code, encryptionKey) {
+		cols = dbx.Params{"lastStep": consumed.LastStep()}
+		where = dbx.And(
+			dbx.HashExp{"id": totp.Id},
+			dbx.NewExp("[[lastStep]] < {:step}", dbx.Params{"step": consumed.LastStep()}),
+		)
+	} else if allowRecoveryCode && consumed.UseRecoveryCode(code) {
+		usedRecoveryCode = true
+
+		hash := recoveryCodeHash(code)
+		cols = dbx.Params{
+			"recoveryCodes": dbx.NewExp(
+				"(SELECT json_group_array([[value]]) FROM json_each([[recoveryCodes]]) WHERE [[value]] != {:hash})",
+				dbx.Params{"hash": hash},
+			),
+		}
+		where = dbx.And(
+			dbx.HashExp{"id": totp.Id},
+			dbx.NewExp(
+				"EXISTS (SELECT 1 FROM json_each([[recoveryCodes]]) WHERE [[value]] = {:hash})",
+				dbx.Params{"hash": hash},
+			),
+		)
+	} else {
+		return false, ErrInvalidTOTPCode
+	}
+
+	cols["updated"] = consumed.Updated().String()
+
+	result, err := app.DB().Update(CollectionNameTOTPs, cols, where).Execute()
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if affected != 1 {
+		return false, ErrInvalidTOTPCode
+	}
+
+	totp.SetProxyRecord(consumed.Record)
+
+	return usedRecoveryCode, nil
+}
@@ -0,0 +1,212 @@
+package core_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestFindTOTPByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	totp := core.NewTOTP(app)
+	totp.SetCollectionRef(user1.Collection().Id)
+	totp.SetRecordRef(user1.Id)
+	totp.SetSecret("ABC", testTOTPEncryptionKey)
+	if err := app.Save(totp); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record     *core.Record
+		expectedId string
+	}{
+		{user1, totp.Id},
+		{user2, ""},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Id, func(t *testing.T) {
+			result, err := app.FindTOTPByRecord(s.record)
+
+			hasErr := err != nil
+			expectErr := s.expectedId == ""
+			if hasErr != expectErr {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", expectErr, hasErr, err)
+			}
+
+			if !hasErr && result.Id != s.expectedId {
+				t.Fatalf("Expected totp %q, got %q", s.expectedId, result.Id)
+			}
+		})
+	}
+}
+
+func TestConsumeTOTPCode(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := security.NewTOTPSecret()
+
+	totp := core.NewTOTP(app)
+	totp.SetCollectionRef(user.Collection().Id)
+	totp.SetRecordRef(user.Id)
+	totp.SetSecret(secret, testTOTPEncryptionKey)
+	totp.Set("recoveryCodes", []string{security.SHA256("aaaaa-11111"), security.SHA256("bbbbb-22222")})
+	if err := app.Save(totp); err != nil {
+		t.Fatal(err)
+	}
+
+	step := security.TOTPStep(time.Now())
+	code, _ := security.TOTPCode(secret, step)
+	prevCode, _ := security.TOTPCode(secret, step-1)
+
+	scenarios := []struct {
+		name                     string
+		code                     string
+		allowRecoveryCode        bool
+		expectedUsedRecoveryCode bool
+		expectedError            bool
+	}{
+		{"invalid code", "123", true, false, true},
+		{"recovery code when not allowed", "aaaaa-11111", false, false, true},
+		{"valid code", code, false, false, false},
+		{"already used code", code, true, false, true},
+		{"code before the last used step", prevCode, true, false, true},
+		{"valid recovery code", " AAAAA-11111 ", true, true, false},
+		{"already used recovery code", "aaaaa-11111", true, false, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			usedRecoveryCode, err := app.ConsumeTOTPCode(totp, s.code, testTOTPEncryptionKey, s.allowRecoveryCode)
+
+			hasErr := err != nil
+			if hasErr != s.expectedError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectedError, hasErr, err)
+			}
+
+			if hasErr && !errors.Is(err, core.ErrInvalidTOTPCode) {
+				t.Fatalf("Expected ErrInvalidTOTPCode, got %v", err)
+			}
+
+			if usedRecoveryCode != s.expectedUsedRecoveryCode {
+				t.Fatalf("Expected usedRecoveryCode %v, got %v", s.expectedUsedRecoveryCode, usedRecoveryCode)
+			}
+		})
+	}
+
+	// check the persisted state
+	stored, err := app.FindTOTPByRecord(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stored.LastStep() != step {
+		t.Fatalf("Expected stored last step %d, got %d", step, stored.LastStep())
+	}
+
+	if totp.LastStep() != step {
+		t.Fatalf("Expected totp last step %d, got %d", step, totp.LastStep())
+	}
+
+	for _, m := range []*core.TOTP{stored, totp} {
+		if total := m.TotalRecoveryCodes(); total != 1 {
+			t.Fatalf("Expected 1 remaining recovery code, got %d", total)
+		}
+
+		if !m.UseRecoveryCode("bbbbb-22222") {
+			t.Fatal("Expected the unused recovery code to remain")
+		}
+	}
+}
+
+func TestConsumeTOTPCodeConcurrent(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secret := security.NewTOTPSecret()
+
+	totp := core.NewTOTP(app)
+	totp.SetCollectionRef(user.Collection().Id)
+	totp.SetRecordRef(user.Id)
+	totp.SetSecret(secret, testTOTPEncryptionKey)
+	totp.Set("recoveryCodes", []string{security.SHA256("aaaaa-11111"), security.SHA256("bbbbb-22222")})
+	if err := app.Save(totp); err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ := security.TOTPCode(secret, security.TOTPStep(time.Now()))
+
+	for _, c := range []string{code, "aaaaa-11111"} {
+		t.Run(c, func(t *testing.T) {
+			const total = 10
+
+			var mu sync.Mutex
+			var succeeded int
+
+			var wg sync.WaitGroup
+			wg.Add(total)
+
+			for range total {
+				go func() {
+					defer wg.Done()
+
+					// each request loads its own copy of the enrollment
+					m, err := app.FindTOTPByRecord(user)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+
+					_, err = app.ConsumeTOTPCode(m, c, testTOTPEncryptionKey, true)
+					if err == nil {
+						mu.Lock()
+						succeeded++
+						mu.Unlock()
+					} else if !errors.Is(err, core.ErrInvalidTOTPCode) {
+						t.Errorf("Expected ErrInvalidTOTPCode, got %v", err)
+					}
+				}()
+			}
+
+			wg.Wait()
+
+			if succeeded != 1 {
+				t.Fatalf("Expected exactly 1 successful code use, got %d", succeeded)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package core
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+	"github.com/spf13/cast"
+)
+
+// TokenTypeWebAuthnChallenge is the token type of the signed WebAuthn ceremony challenges.
+const TokenTypeWebAuthnChallenge = "webauthnChallenge"
+
+const webauthnChallengeClaimNonce = "nonce"
+
+// WebAuthnChallenge represents a parsed and verified WebAuthn ceremony challenge.
+type WebAuthnChallenge struct {
+	// Nonce is the random unique challenge identifier.
+	Nonce string
+
+	// RecordId is the auth record id of a registration ceremony challenge
+	// (it is empty for the authentication ceremony challenges).
+	RecordId string
+
+	// ExpiresAt is the challenge expiration unix timestamp.
+	ExpiresAt int64
+}
+
+// NewWebAuthnChallenge generates and returns a new base64url encoded
+// WebAuthn ceremony challenge for the specified auth collection.
+//
+// The challenge is stateless - it is a token signed with the collection
+// auth token secret and it is valid for the WebAuthn.Duration seconds.
+//
+// Set recordId only for the registration ceremonies.
+func NewWebAuthnChallenge(collection *Collection, recordId string) (string, error) {
+	if !collection.IsAuth() {
+		return "", errors.New("not an auth collection")
+	}
+
+	key := collection.AuthToken.Secret
+	if key == "" {
+		return "", ErrMissingSigningKey
+	}
+
+	claims := jwt.MapClaims{
+		TokenClaimType:              TokenTypeWebAuthnChallenge,
+		TokenClaimCollectionId:      collection.Id,
+		TokenClaimId:                recordId,
+		webauthnChallengeClaimNonce: security.RandomString(30),
+	}
+
+	token, err := security.NewJWT(claims, key, collection.WebAuthn.DurationTime())
+	if err != nil {
+		return "", err
+	}
+
+	return webauthn.EncodeBase64([]byte(token)), nil
+}
+
+// ParseWebAuthnChallenge verifies and parses the provided base64url encoded
+// WebAuthn ceremony challenge generated with [NewWebAuthnChallenge].
+func ParseWebAuthnChallenge(collection *Collection, challenge string) (*WebAuthnChallenge, error) {
+	token, err := webauthn.DecodeBase64(challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := security.ParseJWT(string(token), collection.AuthToken.Secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if cast.ToString(claims[TokenClaimType]) != TokenTypeWebAuthnChallenge {
+		return nil, errors.New("invalid challenge type")
+	}
+
+	if cast.ToString(claims[TokenClaimCollectionId]) != collection.Id {
+		return nil, errors.New("the challenge is for a different collection")
+	}
+
+	nonce := cast.ToString(claims[webauthnChallengeClaimNonce])
+	if nonce == "" {
+		return nil, errors.New("missing challenge nonce")
+	}
+
+	return &WebAuthnChallenge{
+		Nonce:     nonce,
+		RecordId:  cast.ToString(claims[TokenClaimId]),
+		ExpiresAt: cast.ToInt64(claims["exp"]),
+	}, nil
+}
@@ -0,0 +1,88 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestWebAuthnChallenge(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	users, err := app.FindCollectionByNameOrId("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	users.WebAuthn.Duration = 180
+
+	clients, err := app.FindCollectionByNameOrId("clients")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := core.NewWebAuthnChallenge(core.NewBaseCollection("test"), ""); err == nil {
+		t.Fatal("Expected non-auth collection error")
+	}
+
+	for _, recordId := range []string{"", "test_id"} {
+		t.Run(recordId, func(t *testing.T) {
+			challenge1, err := core.NewWebAuthnChallenge(users, recordId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			challenge2, err := core.NewWebAuthnChallenge(users, recordId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed1, err := core.ParseWebAuthnChallenge(users, challenge1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parsed2, err := core.ParseWebAuthnChallenge(users, challenge2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if parsed1.Nonce == "" || parsed1.Nonce == parsed2.Nonce {
+				t.Fatalf("Expected unique nonces, got %q and %q", parsed1.Nonce, parsed2.Nonce)
+			}
+
+			if parsed1.RecordId != recordId {
+				t.Fatalf("Expected record id %q, got %q", recordId, parsed1.RecordId)
+			}
+
+			if parsed1.ExpiresAt == 0 {
+				t.Fatal("Expected non-zero expiration")
+			}
+
+			if _, err := core.ParseWebAuthnChallenge(clients, challenge1); err == nil {
+				t.Fatal("Expected error when parsing the challenge with a different collection")
+			}
+		})
+	}
+
+	if _, err := core.ParseWebAuthnChallenge(users, "invalid"); err == nil {
+		t.Fatal("Expected invalid challenge error")
+	}
+
+	// auth token with the same secret
+	user, err := app.FindAuthRecordByEmail(users, "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := user.NewAuthToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := core.ParseWebAuthnChallenge(users, token); err == nil {
+		t.Fatal("Expected auth token to not be a valid challenge")
+	}
+}
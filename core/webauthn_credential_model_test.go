@@ -0,0 +1,237 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewWebAuthnCredential(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	if credential.Collection().Name != core.CollectionNameWebAuthnCredentials {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameWebAuthnCredentials, credential.Collection().Name)
+	}
+}
+
+func TestWebAuthnCredentialProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	credential := core.WebAuthnCredential{}
+	credential.SetProxyRecord(record)
+
+	if credential.ProxyRecord() == nil || credential.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, credential.ProxyRecord())
+	}
+}
+
+func TestWebAuthnCredentialStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"collectionRef", credential.SetCollectionRef, credential.CollectionRef},
+		{"recordRef", credential.SetRecordRef, credential.RecordRef},
+		{"credentialId", credential.SetCredentialId, credential.CredentialId},
+		{"name", credential.SetName, credential.Name},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := credential.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebAuthnCredentialPublicKey(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	key := []byte{0xa5, 0x01, 0x02, 0xff}
+
+	credential.SetPublicKey(key)
+
+	if v := credential.GetString("publicKey"); v != "pQEC_w" {
+		t.Fatalf("Expected base64url encoded field value %q, got %q", "pQEC_w", v)
+	}
+
+	if v := credential.PublicKey(); string(v) != string(key) {
+		t.Fatalf("Expected public key %x, got %x", key, v)
+	}
+}
+
+func TestWebAuthnCredentialSignCount(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credential := core.NewWebAuthnCredential(app)
+
+	for _, testValue := range []uint32{0, 1, 4294967295} {
+		t.Run(fmt.Sprint(testValue), func(t *testing.T) {
+			credential.SetSignCount(testValue)
+
+			if v := credential.SignCount(); v != testValue {
+				t.Fatalf("Expected getter %d, got %d", testValue, v)
+			}
+		})
+	}
+}
+
+func TestWebAuthnCredentialPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	credentialsCol, err := app.FindCollectionByNameOrId(core.CollectionNameWebAuthnCredentials)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		credential := &core.WebAuthnCredential{}
+
+		if err := app.Validate(credential); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-WebAuthnCredential collection", func(t *testing.T) {
+		credential := &core.WebAuthnCredential{}
+		credential.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		credential.SetRecordRef(user.Id)
+		credential.SetCollectionRef(user.Collection().Id)
+		credential.SetCredentialId("test")
+		credential.SetPublicKey([]byte("test"))
+
+		if err := app.Validate(credential); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("WebAuthnCredential collection", func(t *testing.T) {
+		credential := &core.WebAuthnCredential{}
+		credential.SetProxyRecord(core.NewRecord(credentialsCol))
+		credential.SetRecordRef(user.Id)
+		credential.SetCollectionRef(user.Collection().Id)
+		credential.SetCredentialId("test")
+		credential.SetPublicKey([]byte("test"))
+
+		if err := app.Validate(credential); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestWebAuthnCredentialValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	user, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo1, err := app.FindRecordById("demo1", "84nmscqy84lsi1t")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name         string
+		credential   func() *core.WebAuthnCredential
+		expectErrors []string
+	}{
+		{
+			"empty",
+			func() *core.WebAuthnCredential {
+				return core.NewWebAuthnCredential(app)
+			},
+			[]string{"collectionRef", "recordRef", "credentialId", "publicKey"},
+		},
+		{
+			"non-auth collection",
+			func() *core.WebAuthnCredential {
+				credential := core.NewWebAuthnCredential(app)
+				credential.SetCollectionRef(demo1.Collection().Id)
+				credential.SetRecordRef(demo1.Id)
+				credential.SetCredentialId("test")
+				credential.SetPublicKey([]byte("test"))
+				return credential
+			},
+			[]string{"collectionRef"},
+		},
+		{
+			"missing record id",
+			func() *core.WebAuthnCredential {
+				credential := core.NewWebAuthnCredential(app)
+				credential.SetCollectionRef(user.Collection().Id)
+				credential.SetRecordRef("missing")
+				credential.SetCredentialId("test")
+				credential.SetPublicKey([]byte("test"))
+				return credential
+			},
+			[]string{"recordRef"},
+		},
+		{
+			"valid ref",
+			func() *core.WebAuthnCredential {
+				credential := core.NewWebAuthnCredential(app)
+				credential.SetCollectionRef(user.Collection().Id)
+				credential.SetRecordRef(user.Id)
+				credential.SetCredentialId("test")
+				credential.SetPublicKey([]byte("test"))
+				return credential
+			},
+			[]string{},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			errs := app.Validate(s.credential())
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
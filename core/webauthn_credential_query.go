@@ -0,0 +1,52 @@
+package core
+
+import (
+	"github.com/pocketbase/dbx"
+)
+
+// FindAllWebAuthnCredentialsByRecord returns all WebAuthnCredential models
+// linked to the provided auth record (in DESC order).
+func (app *BaseApp) FindAllWebAuthnCredentialsByRecord(authRecord *Record) ([]*WebAuthnCredential, error) {
+	result := []*WebAuthnCredential{}
+
+	err := app.RecordQuery(CollectionNameWebAuthnCredentials).
+		AndWhere(dbx.HashExp{
+			"collectionRef": authRecord.Collection().Id,
+			"recordRef":     authRecord.Id,
+		}).
+		OrderBy("created DESC").
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindWebAuthnCredentialByCredentialId returns a single WebAuthnCredential model
+// by its base64url encoded authenticator credential id.
+//
+// collectionModelOrIdentifier could be the auth collection model, id or name.
+func (app *BaseApp) FindWebAuthnCredentialByCredentialId(collectionModelOrIdentifier any, credentialId string) (*WebAuthnCredential, error) {
+	collection, err := getCollectionByModelOrIdentifier(app, collectionModelOrIdentifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WebAuthnCredential{}
+
+	err = app.RecordQuery(CollectionNameWebAuthnCredentials).
+		AndWhere(dbx.HashExp{
+			"collectionRef": collection.Id,
+			"credentialId":  credentialId,
+		}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
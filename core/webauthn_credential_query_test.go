@@ -0,0 +1,124 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func stubWebAuthnCredentials(t *testing.T, app core.App) {
+	users := []string{"test@example.com", "test@example.com", "test2@example.com"}
+
+	for i, email := range users {
+		user, err := app.FindAuthRecordByEmail("users", email)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		credential := core.NewWebAuthnCredential(app)
+		credential.SetCollectionRef(user.Collection().Id)
+		credential.SetRecordRef(user.Id)
+		credential.SetCredentialId("cred" + string(rune('0'+i)))
+		credential.SetPublicKey([]byte("test"))
+		if err := app.Save(credential); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindAllWebAuthnCredentialsByRecord(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubWebAuthnCredentials(t, app)
+
+	user1, err := app.FindAuthRecordByEmail("users", "test@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user2, err := app.FindAuthRecordByEmail("users", "test2@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user3, err := app.FindAuthRecordByEmail("users", "test3@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		record   *core.Record
+		expected []string
+	}{
+		{user1, []string{"cred0", "cred1"}},
+		{user2, []string{"cred2"}},
+		{user3, nil},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.record.Id, func(t *testing.T) {
+			result, err := app.FindAllWebAuthnCredentialsByRecord(s.record)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected total credentials %d, got %d", len(s.expected), len(result))
+			}
+
+			for _, id := range s.expected {
+				var found bool
+				for _, c := range result {
+					if c.CredentialId() == id {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("Missing credential %q", id)
+				}
+			}
+		})
+	}
+}
+
+func TestFindWebAuthnCredentialByCredentialId(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	stubWebAuthnCredentials(t, app)
+
+	scenarios := []struct {
+		collection   any
+		credentialId string
+		expectError  bool
+	}{
+		{"users", "", true},
+		{"users", "missing", true},
+		{"clients", "cred0", true},
+		{"missing", "cred0", true},
+		{"users", "cred0", false},
+		{"_pb_users_auth_", "cred2", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.credentialId, func(t *testing.T) {
+			result, err := app.FindWebAuthnCredentialByCredentialId(s.collection, s.credentialId)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result.CredentialId() != s.credentialId {
+				t.Fatalf("Expected credential %q, got %q", s.credentialId, result.CredentialId())
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/routine"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameWebhookDeliveries = "_webhookDeliveries"
+
+// The supported webhook delivery statuses.
+const (
+	WebhookDeliveryStatusPending = "pending"
+	WebhookDeliveryStatusSuccess = "success"
+	WebhookDeliveryStatusFailed  = "failed"
+)
+
+var (
+	_ Model        = (*WebhookDelivery)(nil)
+	_ PreValidator = (*WebhookDelivery)(nil)
+	_ RecordProxy  = (*WebhookDelivery)(nil)
+)
+
+// WebhookDelivery defines a Record proxy for working with the webhookDeliveries collection.
+//
+// The webhook deliveries act as a persistent queue - they are created
+// together with the record change that triggered them and are sent
+// (and retried with exponential backoff on failure) in the background.
+type WebhookDelivery struct {
+	*Record
+}
+
+// NewWebhookDelivery instantiates and returns a new blank *WebhookDelivery model.
+func NewWebhookDelivery(app App) *WebhookDelivery {
+	m := &WebhookDelivery{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameWebhookDeliveries)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on WebhookDelivery.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *WebhookDelivery) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameWebhookDeliveries {
+		return errors.New("missing or invalid WebhookDelivery ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *WebhookDelivery) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *WebhookDelivery) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// Webhook returns the "webhook" record field value (aka. the Webhook model id).
+func (m *WebhookDelivery) Webhook() string {
+	return m.GetString("webhook")
+}
+
+// SetWebhook updates the "webhook" record field value.
+func (m *WebhookDelivery) SetWebhook(webhookId string) {
+	m.Set("webhook", webhookId)
+}
+
+// Event returns the "event" record field value.
+func (m *WebhookDelivery) Event() string {
+	return m.GetString("event")
+}
+
+// SetEvent updates the "event" record field value.
+func (m *WebhookDelivery) SetEvent(event string) {
+	m.Set("event", event)
+}
+
+// CollectionRef returns the "collectionRef" record field value.
+func (m *WebhookDelivery) CollectionRef() string {
+	return m.GetString("collectionRef")
+}
+
+// SetCollectionRef updates the "collectionRef" record field value.
+func (m *WebhookDelivery) SetCollectionRef(collectionId string) {
+	m.Set("collectionRef", collectionId)
+}
+
+// RecordRef returns the "recordRef" record field value.
+func (m *WebhookDelivery) RecordRef() string {
+	return m.GetString("recordRef")
+}
+
+// SetRecordRef updates the "recordRef" record field value.
+func (m *WebhookDelivery) SetRecordRef(recordId string) {
+	m.Set("recordRef", recordId)
+}
+
+// Payload returns the "payload" record field value
+// (aka. the raw JSON request body of the delivery).
+func (m *WebhookDelivery) Payload() types.JSONRaw {
+	raw, _ := m.GetRaw("payload").(types.JSONRaw)
+	return raw
+}
+
+// SetPayload updates the "payload" record field value.
+func (m *WebhookDelivery) SetPayload(payload any) {
+	m.Set("payload", payload)
+}
+
+// Status returns the "status" record field value.
+func (m *WebhookDelivery) Status() string {
+	return m.GetString("status")
+}
+
+// SetStatus updates the "status" record field value.
+func (m *WebhookDelivery) SetStatus(status string) {
+	m.Set("status", status)
+}
+
+// Attempts returns the "attempts" record field value.
+func (m *WebhookDelivery) Attempts() int {
+	return m.GetInt("attempts")
+}
+
+// SetAttempts updates the "attempts" record field value.
+func (m *WebhookDelivery) SetAttempts(attempts int) {
+	m.Set("attempts", attempts)
+}
+
+// NextAttemptAt returns the "nextAttemptAt" record field value.
+func (m *WebhookDelivery) NextAttemptAt() types.DateTime {
+	return m.GetDateTime("nextAttemptAt")
+}
+
+// SetNextAttemptAt updates the "nextAttemptAt" record field value.
+func (m *WebhookDelivery) SetNextAttemptAt(date types.DateTime) {
+	m.Set("nextAttemptAt", date)
+}
+
+// LastStatusCode returns the "lastStatusCode" record field value
+// (aka. the HTTP response status code of the last delivery attempt).
+func (m *WebhookDelivery) LastStatusCode() int {
+	return m.GetInt("lastStatusCode")
+}
+
+// SetLastStatusCode updates the "lastStatusCode" record field value.
+func (m *WebhookDelivery) SetLastStatusCode(code int) {
+	m.Set("lastStatusCode", code)
+}
+
+// LastError returns the "lastError" record field value.
+func (m *WebhookDelivery) LastError() string {
+	return m.GetString("lastError")
+}
+
+// SetLastError updates the "lastError" record field value.
+func (m *WebhookDelivery) SetLastError(lastError string) {
+	m.Set("lastError", lastError)
+}
+
+// Created returns the "created" record field value.
+func (m *WebhookDelivery) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *WebhookDelivery) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// -------------------------------------------------------------------
+
+const (
+	webhooksStoreKey          = "@webhooks"
+	webhookDispatcherStoreKey = "@webhookDispatcher"
+	webhookDeliveriesMaxAge   = 7 * 24 * time.Hour
+)
+
+// webhookDispatcher coordinates the background webhook deliveries dispatch.
+type webhookDispatcher struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	running atomic.Bool
+	pending atomic.Bool
+}
+
+// context returns the current dispatch context.
+func (d *webhookDispatcher) context() context.Context {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.ctx == nil {
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+	}
+
+	return d.ctx
+}
+
+// stop cancels the current dispatch (if any) and waits for it to complete.
+func (d *webhookDispatcher) stop() {
+	d.mu.Lock()
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.ctx = nil
+	d.cancel = nil
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (app *BaseApp) registerWebhookDeliveryHooks() {
+	// reset the cached webhooks on change
+	resetCache := func(e *RecordEvent) error {
+		app.Store().Remove(webhooksStoreKey)
+		return e.Next()
+	}
+	app.OnRecordAfterCreateSuccess(CollectionNameWebhooks).BindFunc(resetCache)
+	app.OnRecordAfterUpdateSuccess(CollectionNameWebhooks).BindFunc(resetCache)
+	app.OnRecordAfterDeleteSuccess(CollectionNameWebhooks).BindFunc(resetCache)
+
+	// enqueue the webhook deliveries as part of the record change
+	// (aka. in the same transaction if the save is wrapped in one)
+	app.OnRecordCreate().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			enqueueWebhookDeliveries(e.App, e.Record, WebhookEventCreate, app.matchingWebhooks(e.App, e.Record, WebhookEventCreate))
+
+			return nil
+		},
+		Priority: -99,
+	})
+	app.OnRecordUpdate().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			enqueueWebhookDeliveries(e.App, e.Record, WebhookEventUpdate, app.matchingWebhooks(e.App, e.Record, WebhookEventUpdate))
+
+			return nil
+		},
+		Priority: -99,
+	})
+	app.OnRecordDelete().Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			// the filter must be checked before the record deletion
+			webhooks := app.matchingWebhooks(e.App, e.Record, WebhookEventDelete)
+
+			if err := e.Next(); err != nil {
+				return err
+			}
+
+			enqueueWebhookDeliveries(e.App, e.Record, WebhookEventDelete, webhooks)
+
+			return nil
+		},
+		Priority: -99,
+	})
+
+	// send the new deliveries once the record change is persisted
+	triggerDispatch := func(event string) func(e *RecordEvent) error {
+		return func(e *RecordEvent) error {
+			if app.hasWebhooks(e.Record.Collection(), event) {
+				app.triggerWebhookDispatch()
+			}
+			return e.Next()
+		}
+	}
+	app.OnRecordAfterCreateSuccess().BindFunc(triggerDispatch(WebhookEventCreate))
+	app.OnRecordAfterUpdateSuccess().BindFunc(triggerDispatch(WebhookEventUpdate))
+	app.OnRecordAfterDeleteSuccess().BindFunc(triggerDispatch(WebhookEventDelete))
+
+	// stop the running dispatch before the logger flush and the db connections close
+	app.OnTerminate().Bind(&hook.Handler[*TerminateEvent]{
+		Id: "__pbWebhooksOnTerminate__",
+		Func: func(e *TerminateEvent) error {
+			app.webhookDispatcher().stop()
+
+			return e.Next()
+		},
+		Priority: -1000,
+	})
+
+	// run every minute to retry the failed deliveries
+	app.Cron().Add("__pbWebhooksDispatch__", "* * * * *", func() {
+		app.triggerWebhookDispatch()
+	})
+
+	// run every hour to cleanup the old completed deliveries
+	app.Cron().Add("__pbWebhookDeliveriesCleanup__", "0 * * * *", func() {
+		if err := app.DeleteOldWebhookDeliveries(time.Now().Add(-webhookDeliveriesMaxAge)); err != nil {
+			app.Logger().Warn("Failed to delete old webhook deliveries", "error", err)
+		}
+	})
+}
+
+// cachedWebhooks returns the cached list with all enabled webhooks.
+func (app *BaseApp) cachedWebhooks() []*Webhook {
+	// note: not using GetOrSet because the query itself accesses the app store (for the cached collections)
+	if webhooks, ok := app.Store().Get(webhooksStoreKey).([]*Webhook); ok {
+		return webhooks
+	}
+
+	webhooks := []*Webhook{}
+
+	err := app.RecordQuery(CollectionNameWebhooks).
+		AndWhere(dbx.HashExp{"enabled": true}).
+		All(&webhooks)
+	if err != nil {
+		app.Logger().Warn("Failed to load the webhooks", "error", err)
+		return webhooks
+	}
+
+	app.Store().Set(webhooksStoreKey, webhooks)
+
+	return webhooks
+}
+
+// hasWebhooks reports whether there is at least one enabled webhook
+// for the specified collection event.
+func (app *BaseApp) hasWebhooks(collection *Collection, event string) bool {
+	for _, webhook := range app.cachedWebhooks() {
+		if webhook.Matches(collection, event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchingWebhooks returns the enabled webhooks for the specified
+// record event that also satisfy their filter expression (if any).
+func (app *BaseApp) matchingWebhooks(txApp App, record *Record, event string) []*Webhook {
+	if isWebhookCollection(record.Collection()) {
+		return nil
+	}
+
+	var result []*Webhook
+
+	for _, webhook := range app.cachedWebhooks() {
+		if !webhook.Matches(record.Collection(), event) {
+			continue
+		}
+
+		if filter := webhook.Filter(); filter != "" {
+			ok, err := txApp.CanAccessRecord(record, &RequestInfo{}, &filter)
+			if err != nil {
+				txApp.Logger().Warn(
+					"Failed to check the webhook filter",
+					"error", err,
+					"webhookId", webhook.Id,
+					"recordId", record.Id,
+					"collectionId", record.Collection().Id,
+				)
+				continue
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		result = append(result, webhook)
+	}
+
+	return result
+}
+
+// enqueueWebhookDeliveries creates a new pending delivery for each of the provided webhooks.
+//
+// Errors are only logged because a failed delivery shouldn't prevent the record change.
+func enqueueWebhookDeliveries(txApp App, record *Record, event string, webhooks []*Webhook) {
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload := map[string]any{
+		"event": event,
+		"collection": map[string]any{
+			"id":   record.Collection().Id,
+			"name": record.Collection().Name,
+		},
+		"record": record,
+	}
+
+	now := types.NowDateTime()
+
+	for _, webhook := range webhooks {
+		delivery := NewWebhookDelivery(txApp)
+		delivery.SetWebhook(webhook.Id)
+		delivery.SetEvent(event)
+		delivery.SetCollectionRef(record.Collection().Id)
+		delivery.SetRecordRef(record.Id)
+		delivery.SetPayload(payload)
+		delivery.SetStatus(WebhookDeliveryStatusPending)
+		delivery.SetNextAttemptAt(now)
+
+		if err := txApp.Save(delivery); err != nil {
+			txApp.Logger().Warn(
+				"Failed to enqueue webhook delivery",
+				"error", err,
+				"webhookId", webhook.Id,
+				"recordId", record.Id,
+				"collectionId", record.Collection().Id,
+			)
+		}
+	}
+}
+
+func (app *BaseApp) webhookDispatcher() *webhookDispatcher {
+	return app.Store().GetOrSet(webhookDispatcherStoreKey, func() any {
+		return &webhookDispatcher{}
+	}).(*webhookDispatcher)
+}
+
+// triggerWebhookDispatch starts sending the due webhook deliveries
+// in the background (if not already started).
+func (app *BaseApp) triggerWebhookDispatch() {
+	d := app.webhookDispatcher()
+
+	d.pending.Store(true)
+
+	if !d.running.CompareAndSwap(false, true) {
+		return // the running dispatch will pick the new deliveries
+	}
+
+	ctx := d.context()
+
+	routine.FireAndForget(func() {
+		defer d.running.Store(false)
+
+		for {
+			for d.pending.Swap(false) {
+				// the app could have been terminated in the meantime
+				if ctx.Err() != nil || !app.IsBootstrapped() {
+					return
+				}
+
+				if err := app.dispatchWebhookDeliveries(ctx); err != nil {
+					app.Logger().Warn("Failed to dispatch webhook deliveries", "error", err)
+				}
+			}
+
+			d.running.Store(false)
+
+			// check again in case of a trigger between the last dispatch and the running flag reset
+			if !d.pending.Load() || !d.running.CompareAndSwap(false, true) {
+				return
+			}
+		}
+	}, &d.wg)
+}
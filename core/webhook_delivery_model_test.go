@@ -0,0 +1,204 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestNewWebhookDelivery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	delivery := core.NewWebhookDelivery(app)
+
+	if delivery.Collection().Name != core.CollectionNameWebhookDeliveries {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameWebhookDeliveries, delivery.Collection().Name)
+	}
+}
+
+func TestWebhookDeliveryProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	delivery := core.WebhookDelivery{}
+	delivery.SetProxyRecord(record)
+
+	if delivery.ProxyRecord() == nil || delivery.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, delivery.ProxyRecord())
+	}
+}
+
+func TestWebhookDeliveryStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	delivery := core.NewWebhookDelivery(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"webhook", delivery.SetWebhook, delivery.Webhook},
+		{"event", delivery.SetEvent, delivery.Event},
+		{"collectionRef", delivery.SetCollectionRef, delivery.CollectionRef},
+		{"recordRef", delivery.SetRecordRef, delivery.RecordRef},
+		{"status", delivery.SetStatus, delivery.Status},
+		{"lastError", delivery.SetLastError, delivery.LastError},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := delivery.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebhookDeliveryIntFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	delivery := core.NewWebhookDelivery(app)
+
+	fields := []struct {
+		name   string
+		setter func(int)
+		getter func() int
+	}{
+		{"attempts", delivery.SetAttempts, delivery.Attempts},
+		{"lastStatusCode", delivery.SetLastStatusCode, delivery.LastStatusCode},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []int{-1, 0, 200} {
+			t.Run(fmt.Sprintf("%s_%d_%d", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %d, got %d", testValue, v)
+				}
+
+				if v := delivery.GetInt(f.name); v != testValue {
+					t.Fatalf("Expected field value %d, got %d", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebhookDeliveryPayload(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	delivery := core.NewWebhookDelivery(app)
+
+	scenarios := []struct {
+		value    any
+		expected string
+	}{
+		{nil, "null"},
+		{map[string]any{"a": 123}, `{"a":123}`},
+		{types.JSONRaw(`[1,2]`), `[1,2]`},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d_%#v", i, s.value), func(t *testing.T) {
+			delivery.SetPayload(s.value)
+
+			if v := delivery.Payload().String(); v != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveryNextAttemptAt(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	delivery := core.NewWebhookDelivery(app)
+
+	date := types.NowDateTime()
+
+	delivery.SetNextAttemptAt(date)
+
+	if v := delivery.NextAttemptAt(); !v.Equal(date) {
+		t.Fatalf("Expected %v, got %v", date, v)
+	}
+}
+
+func TestWebhookDeliveryPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	deliveriesCol, err := app.FindCollectionByNameOrId(core.CollectionNameWebhookDeliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	webhook := createTestWebhook(t, app, "https://example.com", nil, "")
+
+	fill := func(delivery *core.WebhookDelivery) {
+		delivery.SetWebhook(webhook.Id)
+		delivery.SetEvent(core.WebhookEventCreate)
+		delivery.SetCollectionRef("test")
+		delivery.SetRecordRef("test")
+		delivery.SetStatus(core.WebhookDeliveryStatusPending)
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		delivery := &core.WebhookDelivery{}
+
+		if err := app.Validate(delivery); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-WebhookDelivery collection", func(t *testing.T) {
+		delivery := &core.WebhookDelivery{}
+		delivery.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(delivery)
+
+		if err := app.Validate(delivery); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("WebhookDelivery collection", func(t *testing.T) {
+		delivery := &core.WebhookDelivery{}
+		delivery.SetProxyRecord(core.NewRecord(deliveriesCol))
+		fill(delivery)
+
+		if err := app.Validate(delivery); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// FindWebhookDeliveryById returns a single WebhookDelivery model by its id.
+func (app *BaseApp) FindWebhookDeliveryById(id string) (*WebhookDelivery, error) {
+	result := &WebhookDelivery{}
+
+	err := app.RecordQuery(CollectionNameWebhookDeliveries).
+		AndWhere(dbx.HashExp{"id": id}).
+		Limit(1).
+		One(result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// FindAllDueWebhookDeliveries returns up to limit pending WebhookDelivery
+// models which next attempt date is in the past (oldest first).
+func (app *BaseApp) FindAllDueWebhookDeliveries(limit int) ([]*WebhookDelivery, error) {
+	result := []*WebhookDelivery{}
+
+	err := app.RecordQuery(CollectionNameWebhookDeliveries).
+		AndWhere(dbx.HashExp{"status": WebhookDeliveryStatusPending}).
+		AndWhere(dbx.NewExp("[[nextAttemptAt]] <= {:now}", dbx.Params{"now": types.NowDateTime()})).
+		OrderBy("nextAttemptAt ASC", "rowid ASC").
+		Limit(int64(limit)).
+		All(&result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteOldWebhookDeliveries deletes all completed (successful or failed)
+// webhook deliveries that were last updated before the specified date.
+//
+// For better performance the deliveries delete is executed as plain SQL statement,
+// aka. no delete model hook events will be fired.
+func (app *BaseApp) DeleteOldWebhookDeliveries(updatedBefore time.Time) error {
+	formattedDate := updatedBefore.UTC().Format(types.DefaultDateLayout)
+
+	_, err := app.NonconcurrentDB().Delete(CollectionNameWebhookDeliveries, dbx.And(
+		dbx.NewExp("[[status]] != {:status}", dbx.Params{"status": WebhookDeliveryStatusPending}),
+		dbx.NewExp("[[updated]] <= {:date}", dbx.Params{"date": formattedDate}),
+	)).Execute()
+
+	return err
+}
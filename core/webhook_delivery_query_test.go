@@ -0,0 +1,150 @@
+package core_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+func TestFindWebhookDeliveryById(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := createTestWebhook(t, app, "https://example.com", nil, "")
+	delivery := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+
+	scenarios := []struct {
+		id          string
+		expectError bool
+	}{
+		{"", true},
+		{"missing", true},
+		{webhook.Id, true},
+		{delivery.Id, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.id, func(t *testing.T) {
+			result, err := app.FindWebhookDeliveryById(s.id)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if !hasErr && result.Id != s.id {
+				t.Fatalf("Expected result with id %q, got %q", s.id, result.Id)
+			}
+		})
+	}
+}
+
+func TestFindAllDueWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := createTestWebhook(t, app, "https://example.com", nil, "")
+
+	due2 := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(-1*time.Minute))
+	due1 := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(-1*time.Hour))
+	createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+
+	completed := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(-2*time.Hour))
+	completed.SetStatus(core.WebhookDeliveryStatusSuccess)
+	if err := app.Save(completed); err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		limit    int
+		expected []string
+	}{
+		{1, []string{due1.Id}},
+		{10, []string{due1.Id, due2.Id}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("limit_%d", s.limit), func(t *testing.T) {
+			result, err := app.FindAllDueWebhookDeliveries(s.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(result) != len(s.expected) {
+				t.Fatalf("Expected %d deliveries, got %d", len(s.expected), len(result))
+			}
+
+			for i, id := range s.expected {
+				if result[i].Id != id {
+					t.Fatalf("Expected delivery %d to be %q, got %q", i, id, result[i].Id)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteOldWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := createTestWebhook(t, app, "https://example.com", nil, "")
+
+	pending := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+
+	success := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+	success.SetStatus(core.WebhookDeliveryStatusSuccess)
+	if err := app.Save(success); err != nil {
+		t.Fatal(err)
+	}
+
+	failed := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+	failed.SetStatus(core.WebhookDeliveryStatusFailed)
+	if err := app.Save(failed); err != nil {
+		t.Fatal(err)
+	}
+
+	recent := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+	recent.SetStatus(core.WebhookDeliveryStatusSuccess)
+	if err := app.Save(recent); err != nil {
+		t.Fatal(err)
+	}
+
+	// move the updated date of all deliveries except the recent one in the past
+	_, err := app.DB().Update(
+		core.CollectionNameWebhookDeliveries,
+		dbx.Params{"updated": types.NowDateTime().Add(-48 * time.Hour).String()},
+		dbx.Not(dbx.HashExp{"id": recent.Id}),
+	).Execute()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.DeleteOldWebhookDeliveries(time.Now().Add(-24 * time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	expectedExisting := map[string]bool{
+		pending.Id: true,
+		success.Id: false,
+		failed.Id:  false,
+		recent.Id:  true,
+	}
+
+	for id, exists := range expectedExisting {
+		_, err := app.FindWebhookDeliveryById(id)
+		if (err == nil) != exists {
+			t.Fatalf("Expected delivery %q exists %v, got error %v", id, exists, err)
+		}
+	}
+}
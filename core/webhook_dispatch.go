@@ -0,0 +1,241 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"golang.org/x/sync/errgroup"
+)
+
+// The headers sent with each webhook delivery request.
+//
+// The signature header has the format "sha256={hex}" where {hex} is the
+// HMAC-SHA256 of "{timestamp}.{body}" with the webhook secret as key
+// (see also [WebhookSignature]).
+const (
+	WebhookHeaderId        = "X-Webhook-Id"
+	WebhookHeaderEvent     = "X-Webhook-Event"
+	WebhookHeaderTimestamp = "X-Webhook-Timestamp"
+	WebhookHeaderSignature = "X-Webhook-Signature"
+)
+
+const (
+	webhookMaxAttempts         = 8
+	webhookTimeout             = 15 * time.Second
+	webhookLease               = 5 * time.Minute // should be larger than webhookTimeout
+	webhookBaseRetryDelay      = 30 * time.Second
+	webhookMaxRetryDelay       = 12 * time.Hour
+	webhookDispatchBatchSize   = 100
+	webhookDispatchConcurrency = 5
+	webhookMaxErrorLength      = 1000
+)
+
+// WebhookSignature returns the signature of a webhook delivery request
+// body sent at the specified unix timestamp (in seconds).
+//
+// Receivers could use it to verify the [WebhookHeaderSignature] header value.
+func WebhookSignature(secret string, timestamp string, body []byte) string {
+	return "sha256=" + security.HS256(timestamp+"."+string(body), secret)
+}
+
+// webhookRetryDelay returns the exponential backoff delay after the specified failed attempt
+// (30s, 1m, 2m, 4m, ... up to 12h).
+func webhookRetryDelay(attempt int) time.Duration {
+	delay := webhookBaseRetryDelay
+	for i := 1; i < attempt && delay < webhookMaxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, webhookMaxRetryDelay)
+}
+
+// DispatchWebhookDeliveries sends all currently due pending webhook deliveries.
+//
+// It is usually called automatically in the background after a record
+// change and on every minute to retry the previously failed deliveries.
+func (app *BaseApp) DispatchWebhookDeliveries() error {
+	return app.dispatchWebhookDeliveries(context.Background())
+}
+
+func (app *BaseApp) dispatchWebhookDeliveries(ctx context.Context) error {
+	for ctx.Err() == nil {
+		deliveries, err := app.FindAllDueWebhookDeliveries(webhookDispatchBatchSize)
+		if err != nil {
+			return err
+		}
+
+		g := new(errgroup.Group)
+		g.SetLimit(webhookDispatchConcurrency)
+
+		for _, delivery := range deliveries {
+			g.Go(func() error {
+				if ctx.Err() != nil {
+					return nil // the remaining deliveries will be picked on the next dispatch
+				}
+
+				return app.sendWebhookDelivery(ctx, delivery)
+			})
+		}
+
+		if err := g.Wait(); err != nil {
+			return err
+		}
+
+		if len(deliveries) < webhookDispatchBatchSize {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// SendWebhookDelivery sends a single pending webhook delivery
+// and persists the attempt result in the delivery model.
+//
+// On failure the delivery is scheduled to be retried with exponential
+// backoff until the max allowed attempts are reached.
+//
+// The returned error is only for the delivery model persistence.
+// The request failure is available with [WebhookDelivery.LastError].
+//
+// Deliveries that are already being sent by another dispatcher are skipped.
+func (app *BaseApp) SendWebhookDelivery(delivery *WebhookDelivery) error {
+	return app.sendWebhookDelivery(context.Background(), delivery)
+}
+
+func (app *BaseApp) sendWebhookDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	if delivery.Status() != WebhookDeliveryStatusPending {
+		return errors.New("the webhook delivery is not pending")
+	}
+
+	// atomically claim the delivery by moving its next attempt date in the
+	// future so that the concurrent dispatchers could skip it
+	// (it also allows the delivery to be retried after the lease if the app crashes while sending)
+	result, err := app.NonconcurrentDB().Update(
+		CollectionNameWebhookDeliveries,
+		dbx.Params{"nextAttemptAt": types.NowDateTime().Add(webhookLease).String()},
+		dbx.HashExp{
+			"id":            delivery.Id,
+			"status":        WebhookDeliveryStatusPending,
+			"nextAttemptAt": delivery.GetString("nextAttemptAt"),
+		},
+	).Execute()
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil // already claimed
+	}
+
+	attempt := delivery.Attempts() + 1
+	delivery.SetAttempts(attempt)
+
+	webhook := &Webhook{}
+	var statusCode int
+	var sendErr error
+	var permanent bool
+
+	start := time.Now()
+
+	err = app.RecordQuery(CollectionNameWebhooks).
+		AndWhere(dbx.HashExp{"id": delivery.Webhook()}).
+		Limit(1).
+		One(webhook)
+	switch {
+	case err != nil:
+		sendErr = fmt.Errorf("failed to load the webhook: %w", err)
+		permanent = true
+	case !webhook.Enabled():
+		sendErr = errors.New("the webhook is disabled")
+		permanent = true
+	default:
+		statusCode, sendErr = sendWebhookRequest(ctx, webhook, delivery)
+	}
+
+	execTime := float64(time.Since(start)) / float64(time.Millisecond)
+
+	delivery.SetLastStatusCode(statusCode)
+
+	if sendErr == nil {
+		delivery.SetStatus(WebhookDeliveryStatusSuccess)
+		delivery.SetLastError("")
+	} else {
+		lastError := []rune(sendErr.Error())
+		if len(lastError) > webhookMaxErrorLength {
+			lastError = lastError[:webhookMaxErrorLength]
+		}
+		delivery.SetLastError(string(lastError))
+
+		if permanent || attempt >= webhookMaxAttempts {
+			delivery.SetStatus(WebhookDeliveryStatusFailed)
+		} else {
+			delivery.SetNextAttemptAt(types.NowDateTime().Add(webhookRetryDelay(attempt)))
+		}
+	}
+
+	attrs := []any{
+		slog.String("type", "webhook"),
+		slog.Float64("execTime", execTime),
+		slog.String("webhookId", delivery.Webhook()),
+		slog.String("deliveryId", delivery.Id),
+		slog.String("url", webhook.URL()),
+		slog.String("event", delivery.Event()),
+		slog.String("collectionId", delivery.CollectionRef()),
+		slog.String("recordId", delivery.RecordRef()),
+		slog.Int("status", statusCode),
+		slog.Int("attempt", attempt),
+		slog.String("deliveryStatus", delivery.Status()),
+	}
+	if sendErr != nil {
+		app.Logger().Warn("Webhook delivery failed", append(attrs, slog.String("error", sendErr.Error()))...)
+	} else {
+		app.Logger().Info("Webhook delivery", attrs...)
+	}
+
+	return app.Save(delivery)
+}
+
+func sendWebhookRequest(ctx context.Context, webhook *Webhook, delivery *WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload())
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL(), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PocketBase-Webhook")
+	req.Header.Set(WebhookHeaderId, delivery.Id)
+	req.Header.Set(WebhookHeaderEvent, delivery.Event())
+	req.Header.Set(WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(WebhookHeaderSignature, WebhookSignature(webhook.Secret(), timestamp, body))
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	// drain a limited part of the body to allow connection reuse
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected response status code %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
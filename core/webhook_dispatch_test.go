@@ -0,0 +1,442 @@
+package core_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type testWebhookRequest struct {
+	header http.Header
+	body   string
+}
+
+// testWebhookReceiver is a local webhooks receiver
+// responding with a configurable status code.
+type testWebhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	requests []testWebhookRequest
+}
+
+func newTestWebhookReceiver(t *testing.T, status int) *testWebhookReceiver {
+	r := &testWebhookReceiver{status: status}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.requests = append(r.requests, testWebhookRequest{header: req.Header.Clone(), body: string(body)})
+
+		w.WriteHeader(r.status)
+	}))
+
+	t.Cleanup(r.Close)
+
+	return r
+}
+
+func (r *testWebhookReceiver) Requests() []testWebhookRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]testWebhookRequest{}, r.requests...)
+}
+
+func createTestWebhook(t *testing.T, app core.App, url string, collections []string, filter string) *core.Webhook {
+	if len(collections) == 0 {
+		collections = []string{"demo3"}
+	}
+
+	webhook := core.NewWebhook(app)
+	webhook.SetName("test")
+	webhook.SetURL(url)
+	webhook.SetCollections(collections)
+	webhook.SetEvents([]string{core.WebhookEventCreate, core.WebhookEventUpdate, core.WebhookEventDelete})
+	webhook.SetFilter(filter)
+	webhook.SetEnabled(true)
+	if err := app.Save(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	return webhook
+}
+
+func createTestWebhookDelivery(t *testing.T, app core.App, webhook *core.Webhook, nextAttemptAt types.DateTime) *core.WebhookDelivery {
+	delivery := core.NewWebhookDelivery(app)
+	delivery.SetWebhook(webhook.Id)
+	delivery.SetEvent(core.WebhookEventCreate)
+	delivery.SetCollectionRef("test_collection")
+	delivery.SetRecordRef("test_record")
+	delivery.SetPayload(map[string]any{"test": 123})
+	delivery.SetStatus(core.WebhookDeliveryStatusPending)
+	delivery.SetNextAttemptAt(nextAttemptAt)
+	if err := app.Save(delivery); err != nil {
+		t.Fatal(err)
+	}
+
+	return delivery
+}
+
+func findTestWebhookDeliveries(t *testing.T, app core.App, recordId string) []*core.WebhookDelivery {
+	deliveries := []*core.WebhookDelivery{}
+
+	err := app.RecordQuery(core.CollectionNameWebhookDeliveries).
+		AndWhere(dbx.HashExp{"recordRef": recordId}).
+		OrderBy("created ASC", "rowid ASC").
+		All(&deliveries)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return deliveries
+}
+
+func TestWebhookSignature(t *testing.T) {
+	t.Parallel()
+
+	// echo -n '123.{"a":1}' | openssl dgst -sha256 -hmac "secret"
+	expected := "sha256=979e3c2c30ebc0b46dd7165b75ee282921dd508ff4a0b4a4e072ba27b16970ae"
+
+	result := core.WebhookSignature("secret", "123", []byte(`{"a":1}`))
+	if result != expected {
+		t.Fatalf("Expected %q, got %q", expected, result)
+	}
+}
+
+func TestWebhookDeliveriesEnqueue(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	receiver := newTestWebhookReceiver(t, http.StatusOK)
+
+	createTestWebhook(t, app, receiver.URL, []string{"demo3"}, "")
+	createTestWebhook(t, app, receiver.URL, []string{"demo3"}, "title = 'match'")
+
+	disabled := createTestWebhook(t, app, receiver.URL, []string{"demo3"}, "")
+	disabled.SetEnabled(false)
+	if err := app.Save(disabled); err != nil {
+		t.Fatal(err)
+	}
+
+	collection, err := app.FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("create, update and delete", func(t *testing.T) {
+		record := core.NewRecord(collection)
+		record.Set("title", "abc")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		record.Set("title", "match")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.Delete(record); err != nil {
+			t.Fatal(err)
+		}
+
+		deliveries := findTestWebhookDeliveries(t, app, record.Id)
+
+		expectedEvents := []string{"create", "update", "update", "delete", "delete"}
+		if len(deliveries) != len(expectedEvents) {
+			t.Fatalf("Expected %d deliveries, got %d", len(expectedEvents), len(deliveries))
+		}
+
+		for i, delivery := range deliveries {
+			if delivery.Event() != expectedEvents[i] {
+				t.Fatalf("Expected delivery %d event %q, got %q", i, expectedEvents[i], delivery.Event())
+			}
+
+			if delivery.CollectionRef() != collection.Id {
+				t.Fatalf("Expected delivery %d collectionRef %q, got %q", i, collection.Id, delivery.CollectionRef())
+			}
+
+			payload := delivery.Payload().String()
+			if !strings.Contains(payload, `"event":"`+expectedEvents[i]+`"`) || !strings.Contains(payload, `"id":"`+record.Id+`"`) {
+				t.Fatalf("Unexpected delivery %d payload %s", i, payload)
+			}
+		}
+	})
+
+	t.Run("rolled back transaction", func(t *testing.T) {
+		record := core.NewRecord(collection)
+
+		err := app.RunInTransaction(func(txApp core.App) error {
+			record.Set("title", "match")
+			if err := txApp.Save(record); err != nil {
+				return err
+			}
+
+			if total := len(findTestWebhookDeliveries(t, txApp, record.Id)); total != 2 {
+				t.Fatalf("Expected 2 deliveries in the transaction, got %d", total)
+			}
+
+			return errors.New("rollback")
+		})
+		if err == nil {
+			t.Fatal("Expected the transaction to fail")
+		}
+
+		if total := len(findTestWebhookDeliveries(t, app, record.Id)); total != 0 {
+			t.Fatalf("Expected no deliveries after rollback, got %d", total)
+		}
+	})
+
+	t.Run("other collection", func(t *testing.T) {
+		record, err := app.FindRecordById("demo2", "achvryl401bhse3")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record.Set("title", "match")
+		if err := app.Save(record); err != nil {
+			t.Fatal(err)
+		}
+
+		if total := len(findTestWebhookDeliveries(t, app, record.Id)); total != 0 {
+			t.Fatalf("Expected no deliveries, got %d", total)
+		}
+	})
+}
+
+func TestWebhookDeliveriesAutoDispatch(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	receiver := newTestWebhookReceiver(t, http.StatusOK)
+
+	createTestWebhook(t, app, receiver.URL, []string{"demo3"}, "")
+
+	collection, err := app.FindCollectionByNameOrId("demo3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("title", "abc")
+	if err := app.Save(record); err != nil {
+		t.Fatal(err)
+	}
+
+	// wait for the background dispatch
+	for i := 0; i < 50 && len(receiver.Requests()) == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	requests := receiver.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 webhook request, got %d", len(requests))
+	}
+
+	if v := requests[0].header.Get(core.WebhookHeaderEvent); v != core.WebhookEventCreate {
+		t.Fatalf("Expected event header %q, got %q", core.WebhookEventCreate, v)
+	}
+}
+
+func TestSendWebhookDelivery(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	okReceiver := newTestWebhookReceiver(t, http.StatusNoContent)
+	failReceiver := newTestWebhookReceiver(t, http.StatusInternalServerError)
+
+	okWebhook := createTestWebhook(t, app, okReceiver.URL, nil, "")
+	failWebhook := createTestWebhook(t, app, failReceiver.URL, nil, "")
+
+	disabledWebhook := createTestWebhook(t, app, okReceiver.URL, nil, "")
+	disabledWebhook.SetEnabled(false)
+	if err := app.Save(disabledWebhook); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("successful delivery", func(t *testing.T) {
+		delivery := createTestWebhookDelivery(t, app, okWebhook, types.NowDateTime())
+
+		if err := app.SendWebhookDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+
+		if delivery.Status() != core.WebhookDeliveryStatusSuccess {
+			t.Fatalf("Expected status %q, got %q", core.WebhookDeliveryStatusSuccess, delivery.Status())
+		}
+
+		if delivery.Attempts() != 1 || delivery.LastStatusCode() != http.StatusNoContent || delivery.LastError() != "" {
+			t.Fatalf("Unexpected delivery attempt result %v", delivery)
+		}
+
+		requests := okReceiver.Requests()
+		if len(requests) != 1 {
+			t.Fatalf("Expected 1 request, got %d", len(requests))
+		}
+
+		req := requests[0]
+
+		if req.body != delivery.Payload().String() {
+			t.Fatalf("Expected body %s, got %s", delivery.Payload(), req.body)
+		}
+
+		if v := req.header.Get(core.WebhookHeaderId); v != delivery.Id {
+			t.Fatalf("Expected id header %q, got %q", delivery.Id, v)
+		}
+
+		timestamp := req.header.Get(core.WebhookHeaderTimestamp)
+		expectedSignature := core.WebhookSignature(okWebhook.Secret(), timestamp, []byte(req.body))
+		if v := req.header.Get(core.WebhookHeaderSignature); v != expectedSignature {
+			t.Fatalf("Expected signature header %q, got %q", expectedSignature, v)
+		}
+
+		// already sent
+		if err := app.SendWebhookDelivery(delivery); err == nil {
+			t.Fatal("Expected non-pending delivery error")
+		}
+	})
+
+	t.Run("failed delivery", func(t *testing.T) {
+		delivery := createTestWebhookDelivery(t, app, failWebhook, types.NowDateTime())
+
+		if err := app.SendWebhookDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+
+		if delivery.Status() != core.WebhookDeliveryStatusPending {
+			t.Fatalf("Expected status %q, got %q", core.WebhookDeliveryStatusPending, delivery.Status())
+		}
+
+		if delivery.Attempts() != 1 || delivery.LastStatusCode() != http.StatusInternalServerError || delivery.LastError() == "" {
+			t.Fatalf("Unexpected delivery attempt result %v", delivery)
+		}
+
+		// first retry after ~30s
+		minNext := time.Now().Add(25 * time.Second)
+		maxNext := time.Now().Add(35 * time.Second)
+		if next := delivery.NextAttemptAt().Time(); next.Before(minNext) || next.After(maxNext) {
+			t.Fatalf("Expected next attempt between %v and %v, got %v", minNext, maxNext, next)
+		}
+
+		// simulate the remaining retries
+		for i := 2; i <= 8; i++ {
+			delivery.SetNextAttemptAt(types.NowDateTime())
+			if err := app.Save(delivery); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := app.SendWebhookDelivery(delivery); err != nil {
+				t.Fatal(err)
+			}
+
+			if delivery.Attempts() != i {
+				t.Fatalf("Expected %d attempts, got %d", i, delivery.Attempts())
+			}
+		}
+
+		if delivery.Status() != core.WebhookDeliveryStatusFailed {
+			t.Fatalf("Expected status %q after the max attempts, got %q", core.WebhookDeliveryStatusFailed, delivery.Status())
+		}
+
+		if total := len(failReceiver.Requests()); total != 8 {
+			t.Fatalf("Expected 8 requests, got %d", total)
+		}
+	})
+
+	t.Run("disabled webhook", func(t *testing.T) {
+		delivery := createTestWebhookDelivery(t, app, disabledWebhook, types.NowDateTime())
+
+		if err := app.SendWebhookDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+
+		if delivery.Status() != core.WebhookDeliveryStatusFailed {
+			t.Fatalf("Expected status %q, got %q", core.WebhookDeliveryStatusFailed, delivery.Status())
+		}
+	})
+
+	t.Run("already claimed delivery", func(t *testing.T) {
+		delivery := createTestWebhookDelivery(t, app, okWebhook, types.NowDateTime())
+
+		// simulate a concurrent claim by loading a stale copy
+		stale, err := app.FindWebhookDeliveryById(delivery.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := app.SendWebhookDelivery(delivery); err != nil {
+			t.Fatal(err)
+		}
+
+		before := len(okReceiver.Requests())
+
+		stale.SetStatus(core.WebhookDeliveryStatusPending)
+		if err := app.SendWebhookDelivery(stale); err != nil {
+			t.Fatal(err)
+		}
+
+		if total := len(okReceiver.Requests()); total != before {
+			t.Fatalf("Expected the claimed delivery to be skipped, got %d new requests", total-before)
+		}
+	})
+}
+
+func TestDispatchWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	receiver := newTestWebhookReceiver(t, http.StatusOK)
+
+	webhook := createTestWebhook(t, app, receiver.URL, nil, "")
+
+	due1 := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(-1*time.Minute))
+	due2 := createTestWebhookDelivery(t, app, webhook, types.NowDateTime())
+	future := createTestWebhookDelivery(t, app, webhook, types.NowDateTime().Add(1*time.Hour))
+
+	if err := app.DispatchWebhookDeliveries(); err != nil {
+		t.Fatal(err)
+	}
+
+	if total := len(receiver.Requests()); total != 2 {
+		t.Fatalf("Expected 2 requests, got %d", total)
+	}
+
+	expectedStatuses := map[string]string{
+		due1.Id:   core.WebhookDeliveryStatusSuccess,
+		due2.Id:   core.WebhookDeliveryStatusSuccess,
+		future.Id: core.WebhookDeliveryStatusPending,
+	}
+
+	for id, status := range expectedStatuses {
+		delivery, err := app.FindWebhookDeliveryById(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if delivery.Status() != status {
+			t.Fatalf("Expected delivery %q status %q, got %q", id, status, delivery.Status())
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/search"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+const CollectionNameWebhooks = "_webhooks"
+
+// The supported webhook record events.
+const (
+	WebhookEventCreate = "create"
+	WebhookEventUpdate = "update"
+	WebhookEventDelete = "delete"
+)
+
+var (
+	_ Model        = (*Webhook)(nil)
+	_ PreValidator = (*Webhook)(nil)
+	_ RecordProxy  = (*Webhook)(nil)
+)
+
+// Webhook defines a Record proxy for working with the webhooks collection.
+//
+// A webhook sends a signed HTTP POST request to its URL every time
+// a record from one of its collections is created, updated or deleted
+// (optionally only when the record matches the webhook filter).
+type Webhook struct {
+	*Record
+}
+
+// NewWebhook instantiates and returns a new blank *Webhook model.
+//
+// Example usage:
+//
+//	webhook := core.NewWebhook(app)
+//	webhook.SetName("new posts")
+//	webhook.SetURL("https://example.com/hook")
+//	webhook.SetCollections([]string{"posts"})
+//	webhook.SetEvents([]string{core.WebhookEventCreate})
+//	webhook.SetEnabled(true)
+//	app.Save(webhook)
+func NewWebhook(app App) *Webhook {
+	m := &Webhook{}
+
+	c, err := app.FindCachedCollectionByNameOrId(CollectionNameWebhooks)
+	if err != nil {
+		// this is just to make tests easier since it is a system collection and it is expected to be always accessible
+		// (note: the loaded record is further checked on Webhook.PreValidate())
+		c = NewBaseCollection("@__invalid__")
+	}
+
+	m.Record = NewRecord(c)
+
+	return m
+}
+
+// PreValidate implements the [PreValidator] interface and checks
+// whether the proxy is properly loaded.
+func (m *Webhook) PreValidate(ctx context.Context, app App) error {
+	if m.Record == nil || m.Record.Collection().Name != CollectionNameWebhooks {
+		return errors.New("missing or invalid Webhook ProxyRecord")
+	}
+
+	return nil
+}
+
+// ProxyRecord returns the proxied Record model.
+func (m *Webhook) ProxyRecord() *Record {
+	return m.Record
+}
+
+// SetProxyRecord loads the specified record model into the current proxy.
+func (m *Webhook) SetProxyRecord(record *Record) {
+	m.Record = record
+}
+
+// Name returns the "name" record field value.
+func (m *Webhook) Name() string {
+	return m.GetString("name")
+}
+
+// SetName updates the "name" record field value.
+func (m *Webhook) SetName(name string) {
+	m.Set("name", name)
+}
+
+// URL returns the "url" record field value.
+func (m *Webhook) URL() string {
+	return m.GetString("url")
+}
+
+// SetURL updates the "url" record field value.
+func (m *Webhook) SetURL(url string) {
+	m.Set("url", url)
+}
+
+// Collections returns the "collections" record field value
+// (aka. the names or ids of the watched collections).
+func (m *Webhook) Collections() []string {
+	return m.GetStringSlice("collections")
+}
+
+// SetCollections updates the "collections" record field value.
+func (m *Webhook) SetCollections(collections []string) {
+	m.Set("collections", collections)
+}
+
+// Events returns the "events" record field value.
+func (m *Webhook) Events() []string {
+	return m.GetStringSlice("events")
+}
+
+// SetEvents updates the "events" record field value.
+func (m *Webhook) SetEvents(events []string) {
+	m.Set("events", events)
+}
+
+// Filter returns the "filter" record field value.
+func (m *Webhook) Filter() string {
+	return m.GetString("filter")
+}
+
+// SetFilter updates the "filter" record field value.
+func (m *Webhook) SetFilter(filter string) {
+	m.Set("filter", filter)
+}
+
+// Secret returns the "secret" record field value
+// (aka. the key used to sign the webhook deliveries).
+func (m *Webhook) Secret() string {
+	return m.GetString("secret")
+}
+
+// SetSecret updates the "secret" record field value.
+func (m *Webhook) SetSecret(secret string) {
+	m.Set("secret", secret)
+}
+
+// Enabled returns the "enabled" record field value.
+func (m *Webhook) Enabled() bool {
+	return m.GetBool("enabled")
+}
+
+// SetEnabled updates the "enabled" record field value.
+func (m *Webhook) SetEnabled(enabled bool) {
+	m.Set("enabled", enabled)
+}
+
+// Created returns the "created" record field value.
+func (m *Webhook) Created() types.DateTime {
+	return m.GetDateTime("created")
+}
+
+// Updated returns the "updated" record field value.
+func (m *Webhook) Updated() types.DateTime {
+	return m.GetDateTime("updated")
+}
+
+// Matches reports whether the webhook is enabled and listens
+// for the specified event of the provided collection.
+func (m *Webhook) Matches(collection *Collection, event string) bool {
+	if !m.Enabled() || !slices.Contains(m.Events(), event) {
+		return false
+	}
+
+	for _, c := range m.Collections() {
+		if c == collection.Id || strings.EqualFold(c, collection.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (app *BaseApp) registerWebhookHooks() {
+	app.OnRecordValidate(CollectionNameWebhooks).Bind(&hook.Handler[*RecordEvent]{
+		Func: func(e *RecordEvent) error {
+			webhook := &Webhook{}
+			webhook.SetProxyRecord(e.Record)
+
+			collections := webhook.Collections()
+
+			err := validation.Validate(collections,
+				validation.Required,
+				validation.Each(validation.By(func(value any) error {
+					collection, err := e.App.FindCachedCollectionByNameOrId(value.(string))
+					if err != nil {
+						return validation.NewError("validation_missing_collection", "Missing collection {{.name}}.").
+							SetParams(map[string]any{"name": value})
+					}
+
+					if collection.IsView() || isWebhookCollection(collection) {
+						return validation.NewError("validation_invalid_collection", "Webhooks cannot be attached to {{.name}}.").
+							SetParams(map[string]any{"name": collection.Name})
+					}
+
+					return nil
+				})),
+			)
+			if err != nil {
+				return validation.Errors{"collections": err}
+			}
+
+			if filter := webhook.Filter(); filter != "" {
+				for _, name := range collections {
+					collection, _ := e.App.FindCachedCollectionByNameOrId(name)
+
+					resolver := NewRecordFieldResolver(e.App, collection, &RequestInfo{}, true)
+
+					if _, err := search.FilterData(filter).BuildExpr(resolver); err != nil {
+						return validation.Errors{"filter": validation.NewError(
+							"validation_invalid_filter",
+							"Invalid filter for collection {{.name}} - {{.error}}.",
+						).SetParams(map[string]any{"name": collection.Name, "error": err.Error()})}
+					}
+				}
+			}
+
+			return e.Next()
+		},
+		Priority: 99,
+	})
+
+	app.registerWebhookDeliveryHooks()
+}
+
+// isWebhookCollection reports whether the provided collection is
+// one of the webhooks system collections (used to prevent delivery loops).
+func isWebhookCollection(collection *Collection) bool {
+	return collection.Name == CollectionNameWebhooks || collection.Name == CollectionNameWebhookDeliveries
+}
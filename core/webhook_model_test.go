@@ -0,0 +1,273 @@
+package core_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+)
+
+func TestNewWebhook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := core.NewWebhook(app)
+
+	if webhook.Collection().Name != core.CollectionNameWebhooks {
+		t.Fatalf("Expected record with %q collection, got %q", core.CollectionNameWebhooks, webhook.Collection().Name)
+	}
+}
+
+func TestWebhookProxyRecord(t *testing.T) {
+	t.Parallel()
+
+	record := core.NewRecord(core.NewBaseCollection("test"))
+	record.Id = "test_id"
+
+	webhook := core.Webhook{}
+	webhook.SetProxyRecord(record)
+
+	if webhook.ProxyRecord() == nil || webhook.ProxyRecord().Id != record.Id {
+		t.Fatalf("Expected proxy record with id %q, got %v", record.Id, webhook.ProxyRecord())
+	}
+}
+
+func TestWebhookStringFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := core.NewWebhook(app)
+
+	fields := []struct {
+		name   string
+		setter func(string)
+		getter func() string
+	}{
+		{"name", webhook.SetName, webhook.Name},
+		{"url", webhook.SetURL, webhook.URL},
+		{"filter", webhook.SetFilter, webhook.Filter},
+		{"secret", webhook.SetSecret, webhook.Secret},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range []string{"test_1", "test2", ""} {
+			t.Run(fmt.Sprintf("%s_%d_%q", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); v != testValue {
+					t.Fatalf("Expected getter %q, got %q", testValue, v)
+				}
+
+				if v := webhook.GetString(f.name); v != testValue {
+					t.Fatalf("Expected field value %q, got %q", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebhookSliceFields(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := core.NewWebhook(app)
+
+	fields := []struct {
+		name   string
+		setter func([]string)
+		getter func() []string
+	}{
+		{"collections", webhook.SetCollections, webhook.Collections},
+		{"events", webhook.SetEvents, webhook.Events},
+	}
+
+	for _, f := range fields {
+		for i, testValue := range [][]string{{"create", "update"}, {"delete"}, {}} {
+			t.Run(fmt.Sprintf("%s_%d_%v", f.name, i, testValue), func(t *testing.T) {
+				f.setter(testValue)
+
+				if v := f.getter(); !slices.Equal(v, testValue) {
+					t.Fatalf("Expected %v, got %v", testValue, v)
+				}
+			})
+		}
+	}
+}
+
+func TestWebhookEnabled(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := core.NewWebhook(app)
+
+	for _, testValue := range []bool{true, false} {
+		t.Run(fmt.Sprintf("%v", testValue), func(t *testing.T) {
+			webhook.SetEnabled(testValue)
+
+			if v := webhook.Enabled(); v != testValue {
+				t.Fatalf("Expected %v, got %v", testValue, v)
+			}
+		})
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	demo1, err := app.FindCollectionByNameOrId("demo1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	demo2, err := app.FindCollectionByNameOrId("demo2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		enabled     bool
+		collections []string
+		events      []string
+		collection  *core.Collection
+		event       string
+		expected    bool
+	}{
+		{"disabled", false, []string{"demo1"}, []string{"create"}, demo1, "create", false},
+		{"different event", true, []string{"demo1"}, []string{"update", "delete"}, demo1, "create", false},
+		{"different collection", true, []string{"demo1"}, []string{"create"}, demo2, "create", false},
+		{"matching collection name", true, []string{"demo2", "DEMO1"}, []string{"create"}, demo1, "create", true},
+		{"matching collection id", true, []string{demo1.Id}, []string{"update", "delete"}, demo1, "delete", true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			webhook := core.NewWebhook(app)
+			webhook.SetEnabled(s.enabled)
+			webhook.SetCollections(s.collections)
+			webhook.SetEvents(s.events)
+
+			if v := webhook.Matches(s.collection, s.event); v != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, v)
+			}
+		})
+	}
+}
+
+func TestWebhookPreValidate(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhooksCol, err := app.FindCollectionByNameOrId(core.CollectionNameWebhooks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fill := func(webhook *core.Webhook) {
+		webhook.SetName("test")
+		webhook.SetURL("https://example.com")
+		webhook.SetCollections([]string{"demo1"})
+		webhook.SetEvents([]string{core.WebhookEventCreate})
+	}
+
+	t.Run("no proxy record", func(t *testing.T) {
+		webhook := &core.Webhook{}
+
+		if err := app.Validate(webhook); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("non-Webhook collection", func(t *testing.T) {
+		webhook := &core.Webhook{}
+		webhook.SetProxyRecord(core.NewRecord(core.NewBaseCollection("invalid")))
+		fill(webhook)
+
+		if err := app.Validate(webhook); err == nil {
+			t.Fatal("Expected collection validation error")
+		}
+	})
+
+	t.Run("Webhook collection", func(t *testing.T) {
+		webhook := &core.Webhook{}
+		webhook.SetProxyRecord(core.NewRecord(webhooksCol))
+		fill(webhook)
+
+		if err := app.Validate(webhook); err != nil {
+			t.Fatalf("Expected nil validation error, got %v", err)
+		}
+	})
+}
+
+func TestWebhookValidateHook(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	scenarios := []struct {
+		name         string
+		collections  []string
+		filter       string
+		expectErrors []string
+	}{
+		{"empty collections", nil, "", []string{"collections"}},
+		{"missing collection", []string{"demo1", "missing"}, "", []string{"collections"}},
+		{"view collection", []string{"view1"}, "", []string{"collections"}},
+		{"webhook deliveries collection", []string{core.CollectionNameWebhookDeliveries}, "", []string{"collections"}},
+		{"invalid filter", []string{"demo1"}, "missing = 1", []string{"filter"}},
+		{"filter invalid only for one of the collections", []string{"demo1", "demo2"}, "text = 'a'", []string{"filter"}},
+		{"valid filter", []string{"demo1", "users"}, "id != '' && created != ''", []string{}},
+		{"no filter", []string{"demo1", "users"}, "", []string{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			webhook := core.NewWebhook(app)
+			webhook.SetName("test")
+			webhook.SetURL("https://example.com")
+			webhook.SetEvents([]string{core.WebhookEventCreate})
+			webhook.SetCollections(s.collections)
+			webhook.SetFilter(s.filter)
+
+			errs := app.Validate(webhook)
+			tests.TestValidationErrors(t, errs, s.expectErrors)
+		})
+	}
+}
+
+func TestWebhookAutogeneratedSecret(t *testing.T) {
+	t.Parallel()
+
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	webhook := core.NewWebhook(app)
+	webhook.SetName("test")
+	webhook.SetURL("https://example.com")
+	webhook.SetCollections([]string{"demo1"})
+	webhook.SetEvents([]string{core.WebhookEventCreate})
+
+	if err := app.Save(webhook); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(webhook.Secret()) != 40 {
+		t.Fatalf("Expected 40 characters autogenerated secret, got %q", webhook.Secret())
+	}
+}
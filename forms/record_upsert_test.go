@@ -887,6 +887,44 @@ func TestRecordUpsertSubmitSuccess(t *testing.T) {
 	testFilesCount(t, testApp, record, 2) // the file + attrs
 }
 
+func TestRecordUpsertSubmitFormulaField(t *testing.T) {
+	testApp, _ := tests.NewTestApp()
+	defer testApp.Cleanup()
+
+	col := core.NewBaseCollection("test_formula")
+	col.Fields.Add(
+		&core.NumberField{Name: "qty"},
+		&core.NumberField{Name: "price"},
+		&core.FormulaField{Name: "total", Expression: "qty * price", ResultType: core.FormulaResultNumber},
+	)
+	if err := testApp.Save(col); err != nil {
+		t.Fatal(err)
+	}
+
+	record := core.NewRecord(col)
+
+	form := forms.NewRecordUpsert(testApp, record)
+	form.Load(map[string]any{
+		"qty":   2,
+		"price": 3.5,
+		"total": 100, // should be ignored
+	})
+
+	if err := form.Submit(); err != nil {
+		t.Fatalf("Expected Submit success, got error: %v", err)
+	}
+
+	// refresh the record to ensure that the changes were persisted
+	record, err := testApp.FindRecordById(col, record.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := record.GetFloat("total"); v != 7 {
+		t.Fatalf("Expected record.total %v, got %v", 7, v)
+	}
+}
+
 func TestRecordUpsertPasswordsSync(t *testing.T) {
 	testApp, _ := tests.NewTestApp()
 	defer testApp.Cleanup()
@@ -0,0 +1,85 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// create the _totps system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		if _, err := txApp.FindCollectionByNameOrId(core.CollectionNameTOTPs); err == nil {
+			return nil // already exists
+		}
+
+		return createTOTPsCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameTOTPs)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// unset the system flag because system collections cannot be deleted
+		col.System = false
+		if err := txApp.SaveNoValidate(col); err != nil {
+			return err
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+func createTOTPsCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameTOTPs)
+	col.System = true
+
+	ownerRule := "@request.auth.id != '' && recordRef = @request.auth.id && collectionRef = @request.auth.collectionId"
+	col.ListRule = types.Pointer(ownerRule)
+	col.ViewRule = types.Pointer(ownerRule)
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "secret",
+		System:   true,
+		Hidden:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:   "recoveryCodes",
+		System: true,
+		Hidden: true,
+	})
+	col.Fields.Add(&core.NumberField{
+		Name:    "lastStep",
+		System:  true,
+		Hidden:  true,
+		OnlyInt: true,
+	})
+	col.Fields.Add(&core.BoolField{
+		Name:   "verified",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_totps_collectionRef_recordRef", true, "collectionRef,recordRef", "")
+
+	return txApp.Save(col)
+}
@@ -0,0 +1,90 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// create the _apiKeys system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		if _, err := txApp.FindCollectionByNameOrId(core.CollectionNameAPIKeys); err == nil {
+			return nil // already exists
+		}
+
+		return createAPIKeysCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAPIKeys)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// unset the system flag because system collections cannot be deleted
+		col.System = false
+		if err := txApp.SaveNoValidate(col); err != nil {
+			return err
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+func createAPIKeysCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameAPIKeys)
+	col.System = true
+
+	ownerRule := "@request.auth.id != '' && recordRef = @request.auth.id && collectionRef = @request.auth.collectionId"
+	col.ListRule = types.Pointer(ownerRule)
+	col.ViewRule = types.Pointer(ownerRule)
+	col.DeleteRule = types.Pointer(ownerRule)
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "name",
+		System:   true,
+		Required: true,
+		Max:      100,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "keyHash",
+		System:   true,
+		Hidden:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "keyHint",
+		System: true,
+	})
+	col.Fields.Add(&core.JSONField{
+		Name:   "scopes",
+		System: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:   "expires",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_apiKeys_keyHash", true, "keyHash", "")
+	col.AddIndex("idx_apiKeys_collectionRef_recordRef", false, "collectionRef,recordRef", "")
+
+	return txApp.Save(col)
+}
@@ -0,0 +1,87 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// create the _authSessions system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		if _, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthSessions); err == nil {
+			return nil // already exists
+		}
+
+		return createAuthSessionsCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthSessions)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// unset the system flag because system collections cannot be deleted
+		col.System = false
+		if err := txApp.SaveNoValidate(col); err != nil {
+			return err
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+func createAuthSessionsCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameAuthSessions)
+	col.System = true
+
+	ownerRule := "@request.auth.id != '' && recordRef = @request.auth.id && collectionRef = @request.auth.collectionId"
+	col.ListRule = types.Pointer(ownerRule)
+	col.ViewRule = types.Pointer(ownerRule)
+	col.DeleteRule = types.Pointer(ownerRule)
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "fingerprint",
+		System: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "tokenHash",
+		System:   true,
+		Hidden:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:   "previousTokenHash",
+		System: true,
+		Hidden: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:     "expires",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_authSessions_tokenHash", true, "tokenHash", "")
+	col.AddIndex("idx_authSessions_previousTokenHash", false, "previousTokenHash", "")
+	col.AddIndex("idx_authSessions_collectionRef_recordRef", false, "collectionRef,recordRef", "")
+
+	return txApp.Save(col)
+}
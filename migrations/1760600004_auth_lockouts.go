@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the _authLockouts system collection (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		if _, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthLockouts); err == nil {
+			return nil // already exists
+		}
+
+		return createAuthLockoutsCollection(txApp)
+	}, func(txApp core.App) error {
+		col, err := txApp.FindCollectionByNameOrId(core.CollectionNameAuthLockouts)
+		if err != nil {
+			return nil // already deleted
+		}
+
+		// unset the system flag because system collections cannot be deleted
+		col.System = false
+		if err := txApp.SaveNoValidate(col); err != nil {
+			return err
+		}
+
+		return txApp.Delete(col)
+	})
+}
+
+// note: the collection API rules are intentionally left unset (aka. superusers only)
+func createAuthLockoutsCollection(txApp core.App) error {
+	col := core.NewBaseCollection(core.CollectionNameAuthLockouts)
+	col.System = true
+
+	col.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.TextField{
+		Name:     "method",
+		System:   true,
+		Required: true,
+	})
+	col.Fields.Add(&core.NumberField{
+		Name:    "failures",
+		System:  true,
+		OnlyInt: true,
+	})
+	col.Fields.Add(&core.DateField{
+		Name:   "lockedUntil",
+		System: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	col.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	col.AddIndex("idx_authLockouts_collectionRef_recordRef_method", true, "collectionRef,recordRef,method", "")
+
+	return txApp.Save(col)
+}
@@ -0,0 +1,165 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// create the _permissions, _roles and _roleAssignments system collections (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		if _, err := txApp.FindCollectionByNameOrId(core.CollectionNameRoleAssignments); err == nil {
+			return nil // already exists
+		}
+
+		return createRBACCollections(txApp)
+	}, func(txApp core.App) error {
+		names := []string{
+			core.CollectionNameRoleAssignments,
+			core.CollectionNameRoles,
+			core.CollectionNamePermissions,
+		}
+
+		for _, name := range names {
+			col, err := txApp.FindCollectionByNameOrId(name)
+			if err != nil {
+				continue // already deleted
+			}
+
+			// unset the system flag because system collections cannot be deleted
+			col.System = false
+			if err := txApp.SaveNoValidate(col); err != nil {
+				return err
+			}
+
+			if err := txApp.Delete(col); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func createRBACCollections(txApp core.App) error {
+	const namePattern = `^[\w\.\:\-]+$`
+
+	// permissions
+	// ---
+	permissions := core.NewBaseCollection(core.CollectionNamePermissions)
+	permissions.System = true
+	permissions.Fields.Add(&core.TextField{
+		Name:     "name",
+		System:   true,
+		Required: true,
+		Max:      100,
+		Pattern:  namePattern,
+	})
+	permissions.Fields.Add(&core.TextField{
+		Name:   "description",
+		System: true,
+		Max:    255,
+	})
+	permissions.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	permissions.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	permissions.AddIndex("idx_permissions_name", true, "name", "")
+
+	if err := txApp.Save(permissions); err != nil {
+		return err
+	}
+
+	// roles
+	// ---
+	roles := core.NewBaseCollection(core.CollectionNameRoles)
+	roles.System = true
+	roles.Fields.Add(&core.TextField{
+		Name:     "name",
+		System:   true,
+		Required: true,
+		Max:      100,
+		Pattern:  namePattern,
+	})
+	roles.Fields.Add(&core.TextField{
+		Name:   "description",
+		System: true,
+		Max:    255,
+	})
+	roles.Fields.Add(&core.RelationField{
+		Name:         "permissions",
+		System:       true,
+		CollectionId: permissions.Id,
+		MaxSelect:    999,
+	})
+	roles.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	roles.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	roles.AddIndex("idx_roles_name", true, "name", "")
+
+	if err := txApp.Save(roles); err != nil {
+		return err
+	}
+
+	// role assignments
+	// ---
+	assignments := core.NewBaseCollection(core.CollectionNameRoleAssignments)
+	assignments.System = true
+
+	ownerRule := "@request.auth.id != '' && recordRef = @request.auth.id && collectionRef = @request.auth.collectionId"
+	assignments.ListRule = types.Pointer(ownerRule)
+	assignments.ViewRule = types.Pointer(ownerRule)
+
+	assignments.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	assignments.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	assignments.Fields.Add(&core.RelationField{
+		Name:          "role",
+		System:        true,
+		Required:      true,
+		CollectionId:  roles.Id,
+		CascadeDelete: true,
+		MaxSelect:     1,
+	})
+	assignments.Fields.Add(&core.TextField{
+		Name:   "scope",
+		System: true,
+		Max:    255,
+	})
+	assignments.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	assignments.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	assignments.AddIndex("idx_roleAssignments_collectionRef_recordRef_role_scope", true, "collectionRef,recordRef,role,scope", "")
+
+	return txApp.Save(assignments)
+}
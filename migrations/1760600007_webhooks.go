@@ -0,0 +1,190 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the _webhooks and _webhookDeliveries system collections (if not already)
+func init() {
+	core.SystemMigrations.Register(func(txApp core.App) error {
+		if _, err := txApp.FindCollectionByNameOrId(core.CollectionNameWebhookDeliveries); err == nil {
+			return nil // already exists
+		}
+
+		return createWebhooksCollections(txApp)
+	}, func(txApp core.App) error {
+		names := []string{
+			core.CollectionNameWebhookDeliveries,
+			core.CollectionNameWebhooks,
+		}
+
+		for _, name := range names {
+			col, err := txApp.FindCollectionByNameOrId(name)
+			if err != nil {
+				continue // already deleted
+			}
+
+			// unset the system flag because system collections cannot be deleted
+			col.System = false
+			if err := txApp.SaveNoValidate(col); err != nil {
+				return err
+			}
+
+			if err := txApp.Delete(col); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func createWebhooksCollections(txApp core.App) error {
+	// webhooks
+	// ---
+	webhooks := core.NewBaseCollection(core.CollectionNameWebhooks)
+	webhooks.System = true
+	webhooks.Fields.Add(&core.TextField{
+		Name:     "name",
+		System:   true,
+		Required: true,
+		Max:      100,
+	})
+	webhooks.Fields.Add(&core.URLField{
+		Name:     "url",
+		System:   true,
+		Required: true,
+	})
+	webhooks.Fields.Add(&core.JSONField{
+		Name:     "collections",
+		System:   true,
+		Required: true,
+	})
+	webhooks.Fields.Add(&core.SelectField{
+		Name:     "events",
+		System:   true,
+		Required: true,
+		Values: []string{
+			core.WebhookEventCreate,
+			core.WebhookEventUpdate,
+			core.WebhookEventDelete,
+		},
+		MaxSelect: 3,
+	})
+	webhooks.Fields.Add(&core.TextField{
+		Name:   "filter",
+		System: true,
+		Max:    1000,
+	})
+	webhooks.Fields.Add(&core.TextField{
+		Name:                "secret",
+		System:              true,
+		Hidden:              true,
+		Required:            true,
+		Max:                 255,
+		AutogeneratePattern: `[a-zA-Z0-9]{40}`,
+	})
+	webhooks.Fields.Add(&core.BoolField{
+		Name:   "enabled",
+		System: true,
+	})
+	webhooks.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	webhooks.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+
+	if err := txApp.Save(webhooks); err != nil {
+		return err
+	}
+
+	// deliveries
+	// ---
+	deliveries := core.NewBaseCollection(core.CollectionNameWebhookDeliveries)
+	deliveries.System = true
+	deliveries.Fields.Add(&core.RelationField{
+		Name:          "webhook",
+		System:        true,
+		Required:      true,
+		CollectionId:  webhooks.Id,
+		CascadeDelete: true,
+		MaxSelect:     1,
+	})
+	deliveries.Fields.Add(&core.SelectField{
+		Name:     "event",
+		System:   true,
+		Required: true,
+		Values: []string{
+			core.WebhookEventCreate,
+			core.WebhookEventUpdate,
+			core.WebhookEventDelete,
+		},
+		MaxSelect: 1,
+	})
+	deliveries.Fields.Add(&core.TextField{
+		Name:     "collectionRef",
+		System:   true,
+		Required: true,
+	})
+	deliveries.Fields.Add(&core.TextField{
+		Name:     "recordRef",
+		System:   true,
+		Required: true,
+	})
+	deliveries.Fields.Add(&core.JSONField{
+		Name:    "payload",
+		System:  true,
+		MaxSize: 10 << 20,
+	})
+	deliveries.Fields.Add(&core.SelectField{
+		Name:     "status",
+		System:   true,
+		Required: true,
+		Values: []string{
+			core.WebhookDeliveryStatusPending,
+			core.WebhookDeliveryStatusSuccess,
+			core.WebhookDeliveryStatusFailed,
+		},
+		MaxSelect: 1,
+	})
+	deliveries.Fields.Add(&core.NumberField{
+		Name:    "attempts",
+		System:  true,
+		OnlyInt: true,
+	})
+	deliveries.Fields.Add(&core.DateField{
+		Name:   "nextAttemptAt",
+		System: true,
+	})
+	deliveries.Fields.Add(&core.NumberField{
+		Name:    "lastStatusCode",
+		System:  true,
+		OnlyInt: true,
+	})
+	deliveries.Fields.Add(&core.TextField{
+		Name:   "lastError",
+		System: true,
+		Max:    1000,
+	})
+	deliveries.Fields.Add(&core.AutodateField{
+		Name:     "created",
+		System:   true,
+		OnCreate: true,
+	})
+	deliveries.Fields.Add(&core.AutodateField{
+		Name:     "updated",
+		System:   true,
+		OnCreate: true,
+		OnUpdate: true,
+	})
+	deliveries.AddIndex("idx_webhookDeliveries_status_nextAttemptAt", false, "status,nextAttemptAt", "")
+	deliveries.AddIndex("idx_webhookDeliveries_webhook", false, "webhook", "")
+
+	return txApp.Save(deliveries)
+}
@@ -0,0 +1,44 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the auxiliary _jobs table (if not already)
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_jobs}} (
+					[[id]]          TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[name]]        TEXT DEFAULT "" NOT NULL,
+					[[queue]]       TEXT DEFAULT "" NOT NULL,
+					[[payload]]     JSON DEFAULT NULL,
+					[[status]]      TEXT DEFAULT "" NOT NULL,
+					[[attempts]]    INTEGER DEFAULT 0 NOT NULL,
+					[[maxAttempts]] INTEGER DEFAULT 0 NOT NULL,
+					[[uniqueKey]]   TEXT DEFAULT "" NOT NULL,
+					[[lastError]]   TEXT DEFAULT "" NOT NULL,
+					[[runAt]]       TEXT DEFAULT "" NOT NULL,
+					[[created]]     TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL,
+					[[updated]]     TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_jobs_status_runAt on {{_jobs}} ([[status]], [[runAt]]);
+				CREATE INDEX IF NOT EXISTS idx_jobs_name_uniqueKey on {{_jobs}} ([[name]], [[uniqueKey]]);
+				CREATE INDEX IF NOT EXISTS idx_jobs_updated on {{_jobs}} ([[updated]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_jobs").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _jobs table doesn't exist
+			exists := txApp.AuxHasTable("_jobs")
+			return !exists, nil
+		},
+	})
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// create the auxiliary _cronRuns table (if not already)
+func init() {
+	core.SystemMigrations.Add(&core.Migration{
+		Up: func(txApp core.App) error {
+			_, execErr := txApp.AuxDB().NewQuery(`
+				CREATE TABLE IF NOT EXISTS {{_cronRuns}} (
+					[[id]]       TEXT PRIMARY KEY DEFAULT ('r'||lower(hex(randomblob(7)))) NOT NULL,
+					[[jobId]]    TEXT DEFAULT "" NOT NULL,
+					[[error]]    TEXT DEFAULT "" NOT NULL,
+					[[started]]  TEXT DEFAULT "" NOT NULL,
+					[[duration]] REAL DEFAULT 0 NOT NULL,
+					[[manual]]   BOOLEAN DEFAULT FALSE NOT NULL,
+					[[panicked]] BOOLEAN DEFAULT FALSE NOT NULL,
+					[[created]]  TEXT DEFAULT (strftime('%Y-%m-%d %H:%M:%fZ')) NOT NULL
+				);
+
+				CREATE INDEX IF NOT EXISTS idx_cronRuns_jobId_started on {{_cronRuns}} ([[jobId]], [[started]]);
+				CREATE INDEX IF NOT EXISTS idx_cronRuns_created on {{_cronRuns}} ([[created]]);
+			`).Execute()
+
+			return execErr
+		},
+		Down: func(txApp core.App) error {
+			_, err := txApp.AuxDB().DropTable("_cronRuns").Execute()
+			return err
+		},
+		ReapplyCondition: func(txApp core.App, runner *core.MigrationsRunner, fileName string) (bool, error) {
+			// reapply only if the _cronRuns table doesn't exist
+			exists := txApp.AuxHasTable("_cronRuns")
+			return !exists, nil
+		},
+	})
+}
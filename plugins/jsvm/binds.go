@@ -25,6 +25,7 @@ import (
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/forms"
 	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/hook"
 	"github.com/pocketbase/pocketbase/tools/inflector"
@@ -101,11 +102,30 @@ func hooksBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 }
 
 func cronBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
-	cronAdd := func(jobId, cronExpr, handler string) {
+	cronAdd := func(jobId, cronExpr, handler string, rawOptions ...map[string]any) {
 		pr := goja.MustCompile(defaultScriptPath, "{("+handler+").apply(undefined)}", true)
 
-		err := app.Cron().Add(jobId, cronExpr, func() {
+		options, err := parseCronJobOptions(rawOptions...)
+		if err != nil {
+			panic("[cronAdd] failed to register cron job " + jobId + ": " + err.Error())
+		}
+
+		err = app.Cron().AddWithOptions(jobId, cronExpr, func(ctx context.Context) error {
 			err := executors.run(func(executor *goja.Runtime) error {
+				// interrupt the handler execution on timeout
+				interrupted := make(chan struct{})
+				stop := context.AfterFunc(ctx, func() {
+					executor.Interrupt(ctx.Err())
+					close(interrupted)
+				})
+				defer func() {
+					if !stop() {
+						// wait for the interrupt and reset it for the next pool usage
+						<-interrupted
+						executor.ClearInterrupt()
+					}
+				}()
+
 				_, err := executor.RunProgram(pr)
 				return err
 			})
@@ -117,7 +137,9 @@ func cronBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 					slog.String("error", err.Error()),
 				)
 			}
-		})
+
+			return err
+		}, options)
 		if err != nil {
 			panic("[cronAdd] failed to register cron job " + jobId + ": " + err.Error())
 		}
@@ -145,6 +167,78 @@ func cronBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	}
 }
 
+// parseCronJobOptions parses the optional plain cronAdd options object, eg.
+// {timezone: "Europe/Sofia", timeout: 30, jitter: 5, skipIfRunning: true}
+// (timeout and jitter are in seconds).
+func parseCronJobOptions(rawOptions ...map[string]any) (cron.JobOptions, error) {
+	options := cron.JobOptions{}
+
+	if len(rawOptions) == 0 || rawOptions[0] == nil {
+		return options, nil
+	}
+
+	raw := rawOptions[0]
+
+	if v := cast.ToString(raw["timezone"]); v != "" {
+		loc, err := time.LoadLocation(v)
+		if err != nil {
+			return options, err
+		}
+		options.Timezone = loc
+	}
+
+	options.Timeout = time.Duration(cast.ToFloat64(raw["timeout"]) * float64(time.Second))
+	options.Jitter = time.Duration(cast.ToFloat64(raw["jitter"]) * float64(time.Second))
+	options.SkipIfRunning = cast.ToBool(raw["skipIfRunning"])
+
+	return options, nil
+}
+
+func jobsBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
+	jobAdd := func(name string, handler string) {
+		// overwrite the global $app with the job scoped instance
+		handler = `function(e) { $app = e.app; return (` + handler + `).call(undefined, e) }`
+		pr := goja.MustCompile(defaultScriptPath, "{("+handler+").apply(undefined, __args)}", true)
+
+		app.Jobs().Add(name, func(e *core.JobEvent) error {
+			return executors.run(func(executor *goja.Runtime) error {
+				executor.Set("$app", goja.Undefined())
+				executor.Set("__args", []any{e})
+				res, err := executor.RunProgram(pr)
+				executor.Set("__args", goja.Undefined())
+
+				// check for returned Go error value
+				if resErr := checkGojaValueForError(app, res); resErr != nil {
+					return resErr
+				}
+
+				return normalizeException(err)
+			})
+		})
+	}
+	loader.Set("jobAdd", jobAdd)
+
+	jobRemove := func(name string) {
+		app.Jobs().Remove(name)
+	}
+	loader.Set("jobRemove", jobRemove)
+
+	// register the helpers also in the executors to allow managing the job handlers from everywhere
+	oldFactory := executors.factory
+	executors.factory = func() *goja.Runtime {
+		vm := oldFactory()
+
+		vm.Set("jobAdd", jobAdd)
+		vm.Set("jobRemove", jobRemove)
+
+		return vm
+	}
+	for _, item := range executors.items {
+		item.vm.Set("jobAdd", jobAdd)
+		item.vm.Set("jobRemove", jobRemove)
+	}
+}
+
 func routerBinds(app core.App, loader *goja.Runtime, executors *vmsPool) {
 	loader.Set("routerAdd", func(method string, path string, handler goja.Value, middlewares ...goja.Value) {
 		wrappedMiddlewares, err := wrapMiddlewares(executors, middlewares...)
@@ -537,6 +631,14 @@ func baseBinds(vm *goja.Runtime) {
 		instance := &core.GeoPointField{}
 		return structConstructorUnmarshal(vm, call, instance)
 	})
+	vm.Set("FormulaField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.FormulaField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
+	vm.Set("RollupField", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.RollupField{}
+		return structConstructorUnmarshal(vm, call, instance)
+	})
 	// ---
 
 	vm.Set("MailerMessage", func(call goja.ConstructorCall) *goja.Object {
@@ -630,6 +732,11 @@ func baseBinds(vm *goja.Runtime) {
 		instance := &subscriptions.Message{}
 		return structConstructor(vm, call, instance)
 	})
+
+	vm.Set("JobOptions", func(call goja.ConstructorCall) *goja.Object {
+		instance := &core.JobOptions{}
+		return structConstructor(vm, call, instance)
+	})
 }
 
 func dbxBinds(vm *goja.Runtime) {
@@ -20,6 +20,7 @@ import (
 	"github.com/pocketbase/pocketbase/apis"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/tests"
+	"github.com/pocketbase/pocketbase/tools/cron"
 	"github.com/pocketbase/pocketbase/tools/filesystem"
 	"github.com/pocketbase/pocketbase/tools/mailer"
 	"github.com/pocketbase/pocketbase/tools/router"
@@ -46,7 +47,7 @@ func TestBaseBindsCount(t *testing.T) {
 	vm := goja.New()
 	baseBinds(vm)
 
-	testBindsCount(vm, "this", 35, t)
+	testBindsCount(vm, "this", 38, t)
 }
 
 func TestBaseBindsSleep(t *testing.T) {
@@ -261,6 +262,39 @@ func TestBaseBindsSubscriptionMessage(t *testing.T) {
 	}
 }
 
+func TestBaseBindsJobOptions(t *testing.T) {
+	vm := goja.New()
+	baseBinds(vm)
+
+	_, err := vm.RunString(`
+		const result = new JobOptions({
+			queue:       "test",
+			delay:       1e9,
+			maxAttempts: 5,
+			uniqueKey:   "abc",
+		});
+
+		if (result.queue != "test") {
+			throw new Error("Expected queue test, got " + result.queue);
+		}
+
+		if (result.delay != 1e9) {
+			throw new Error("Expected delay 1e9, got " + result.delay);
+		}
+
+		if (result.maxAttempts != 5) {
+			throw new Error("Expected maxAttempts 5, got " + result.maxAttempts);
+		}
+
+		if (result.uniqueKey != "abc") {
+			throw new Error("Expected uniqueKey abc, got " + result.uniqueKey);
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestBaseBindsRecord(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -444,6 +478,14 @@ func TestBaseBindsNamedFields(t *testing.T) {
 			"new GeoPointField({name: 'test'})",
 			isType[*core.GeoPointField],
 		},
+		{
+			"new FormulaField({name: 'test'})",
+			isType[*core.FormulaField],
+		},
+		{
+			"new RollupField({name: 'test'})",
+			isType[*core.RollupField],
+		},
 	}
 
 	for _, s := range scenarios {
@@ -1522,6 +1564,162 @@ func TestCronBindsCount(t *testing.T) {
 	})
 }
 
+func TestCronBinds(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	vmFactory := func() *goja.Runtime {
+		vm := goja.New()
+		baseBinds(vm)
+		vm.Set("$app", app)
+		return vm
+	}
+
+	pool := newPool(1, vmFactory)
+
+	vm := vmFactory()
+	cronBinds(app, vm, pool)
+
+	_, err := vm.RunString(`
+		cronAdd("default", "*/10 * * * * *", () => {})
+
+		cronAdd("timeout", "@every 30s", () => {
+			while (true) {}
+		}, { timezone: "Europe/Sofia", timeout: 0.05, jitter: 1, skipIfRunning: true })
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("Expected invalid timezone panic")
+			}
+		}()
+
+		vm.RunString(`cronAdd("invalid", "* * * * *", () => {}, { timezone: "missing" })`)
+	}()
+
+	jobs := map[string]*cron.Job{}
+	for _, j := range app.Cron().Jobs() {
+		jobs[j.Id()] = j
+	}
+
+	if jobs["invalid"] != nil {
+		t.Fatal("Expected the invalid cron job to not be registered")
+	}
+
+	defaultJob := jobs["default"]
+	if defaultJob == nil {
+		t.Fatal("Missing default cron job")
+	}
+	if opts := defaultJob.Options(); opts != (cron.JobOptions{}) {
+		t.Fatalf("Expected zero default job options, got %#v", opts)
+	}
+
+	timeoutJob := jobs["timeout"]
+	if timeoutJob == nil {
+		t.Fatal("Missing timeout cron job")
+	}
+
+	opts := timeoutJob.Options()
+	if opts.Timezone == nil || opts.Timezone.String() != "Europe/Sofia" {
+		t.Fatalf("Expected Europe/Sofia timezone, got %v", opts.Timezone)
+	}
+	if opts.Timeout != 50*time.Millisecond {
+		t.Fatalf("Expected 50ms timeout, got %v", opts.Timeout)
+	}
+	if opts.Jitter != time.Second {
+		t.Fatalf("Expected 1s jitter, got %v", opts.Jitter)
+	}
+	if !opts.SkipIfRunning {
+		t.Fatal("Expected skipIfRunning to be true")
+	}
+
+	// the infinite loop should be interrupted on timeout
+	timeoutJob.Run()
+
+	lastRun := timeoutJob.LastRun()
+	if lastRun == nil || lastRun.Error == nil {
+		t.Fatalf("Expected timeout run error, got %v", lastRun)
+	}
+
+	// the pool executor should be reusable after the interrupt
+	defaultJob.Run()
+
+	if lastRun := defaultJob.LastRun(); lastRun == nil || lastRun.Error != nil {
+		t.Fatalf("Expected successful default job run, got %v", lastRun)
+	}
+}
+
+func TestJobsBindsCount(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	vm := goja.New()
+
+	pool := newPool(1, func() *goja.Runtime { return goja.New() })
+
+	jobsBinds(app, vm, pool)
+
+	testBindsCount(vm, "this", 2, t)
+
+	pool.run(func(poolVM *goja.Runtime) error {
+		testBindsCount(poolVM, "this", 2, t)
+		return nil
+	})
+}
+
+func TestJobsBinds(t *testing.T) {
+	app, _ := tests.NewTestApp()
+	defer app.Cleanup()
+
+	received := make(chan string, 1)
+
+	vmFactory := func() *goja.Runtime {
+		vm := goja.New()
+		baseBinds(vm)
+		vm.Set("$app", app)
+		vm.Set("received", func(message string) {
+			received <- message
+		})
+		return vm
+	}
+
+	pool := newPool(1, vmFactory)
+
+	vm := vmFactory()
+	jobsBinds(app, vm, pool)
+
+	_, err := vm.RunString(`
+		jobAdd("test", (e) => {
+			const payload = JSON.parse(toString(e.job.payload))
+			received(payload.message)
+		})
+
+		jobAdd("removed", (e) => {})
+		jobRemove("removed")
+
+		$app.jobs().enqueue("test", { message: "hello" }, new JobOptions({ maxAttempts: 1 }))
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.Jobs().Start()
+	defer app.Jobs().Stop()
+
+	select {
+	case message := <-received:
+		if message != "hello" {
+			t.Fatalf("Expected message %q, got %q", "hello", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The job handler was not called")
+	}
+}
+
 func TestHooksBindsCount(t *testing.T) {
 	app, _ := tests.NewTestApp()
 	defer app.Cleanup()
@@ -1529,7 +1727,7 @@ func TestHooksBindsCount(t *testing.T) {
 	vm := goja.New()
 	hooksBinds(app, vm, nil)
 
-	testBindsCount(vm, "this", 82, t)
+	testBindsCount(vm, "this", 87, t)
 }
 
 func TestHooksBinds(t *testing.T) {
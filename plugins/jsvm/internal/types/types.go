@@ -34,8 +34,22 @@ const heading = `
  * cronAdd("hello", "*\/30 * * * *", () => {
  *     console.log("Hello world!")
  * })
+ *
+ * // runs every day at 08:00 Europe/Sofia time
+ * cronAdd("report", "0 8 * * *", () => {
+ *     // ...
+ * }, { timezone: "Europe/Sofia", timeout: 60, skipIfRunning: true })
  * ` + "```" + `
  *
+ * The cron expression could have an optional leading seconds segment
+ * (eg. "*\/10 * * * * *") or to be an interval like "@every 30s".
+ *
+ * The optional options object could have:
+ * - timezone (the job schedule timezone, default to the app cron timezone)
+ * - timeout (max run duration in seconds after which the handler is interrupted)
+ * - jitter (max random delay in seconds before each scheduled run)
+ * - skipIfRunning (skips the scheduled run if the previous one hasn't finished yet)
+ *
  * _Note that this method is available only in pb_hooks context._
  *
  * @group PocketBase
@@ -44,6 +58,12 @@ declare function cronAdd(
   jobId:    string,
   cronExpr: string,
   handler:  () => void,
+  options?: {
+    timezone?:      string,
+    timeout?:       number,
+    jitter?:        number,
+    skipIfRunning?: boolean,
+  },
 ): void;
 
 /**
@@ -61,6 +81,55 @@ declare function cronAdd(
  */
 declare function cronRemove(jobId: string): void;
 
+// -------------------------------------------------------------------
+// jobsBinds
+// -------------------------------------------------------------------
+
+/**
+ * JobAdd registers the handler of the persistent background jobs
+ * with the specified name (see also ` + "`$app.jobs().enqueue(...)`" + `).
+ *
+ * If a job handler with the specified name already exist, it will be
+ * replaced with the new one.
+ *
+ * Example:
+ *
+ * ` + "```" + `js
+ * jobAdd("sendReport", (e) => {
+ *     const payload = JSON.parse(toString(e.job.payload))
+ *     console.log("sending report to", payload.email)
+ * })
+ *
+ * // somewhere else, e.g. in a route handler
+ * $app.jobs().enqueue("sendReport", { email: "test@example.com" }, new JobOptions({
+ *     maxAttempts: 5,
+ * }))
+ * ` + "```" + `
+ *
+ * _Note that this method is available only in pb_hooks context._
+ *
+ * @group PocketBase
+ */
+declare function jobAdd(
+  name:    string,
+  handler: (e: core.JobEvent) => void,
+): void;
+
+/**
+ * JobRemove removes a single registered job handler by its name.
+ *
+ * Example:
+ *
+ * ` + "```" + `js
+ * jobRemove("sendReport")
+ * ` + "```" + `
+ *
+ * _Note that this method is available only in pb_hooks context._
+ *
+ * @group PocketBase
+ */
+declare function jobRemove(name: string): void;
+
 // -------------------------------------------------------------------
 // routerBinds
 // -------------------------------------------------------------------
@@ -741,6 +810,28 @@ declare class SubscriptionMessage implements subscriptions.Message {
   constructor(options?: Partial<subscriptions.Message>)
 }
 
+interface JobOptions extends core.JobOptions{} // merge
+/**
+ * JobOptions defines the optional settings of a new background job.
+ *
+ * Note that the ` + "`delay`" + ` is in nanoseconds.
+ *
+ * Example:
+ *
+ * ` + "```" + `js
+ * $app.jobs().enqueue("sendReport", { email: "test@example.com" }, new JobOptions({
+ *     queue:     "reports",
+ *     delay:     60 * 1e9, // 1 minute
+ *     uniqueKey: "test@example.com",
+ * }))
+ * ` + "```" + `
+ *
+ * @group PocketBase
+ */
+declare class JobOptions implements core.JobOptions {
+  constructor(options?: Partial<core.JobOptions>)
+}
+
 // -------------------------------------------------------------------
 // dbxBinds
 // -------------------------------------------------------------------
@@ -308,6 +308,7 @@ func (p *plugin) registerHooks() error {
 	sharedBinds(loader)
 	hooksBinds(p.app, loader, executors)
 	cronBinds(p.app, loader, executors)
+	jobsBinds(p.app, loader, executors)
 	routerBinds(p.app, loader, executors)
 
 	for file, content := range files {
@@ -0,0 +1,399 @@
+// Package metrics implements an opt-in Prometheus metrics endpoint.
+//
+// The plugin registers a single GET /api/metrics endpoint that exposes
+// the collected metrics in the Prometheus text exposition format.
+// The endpoint could be accessed only by superusers or with the
+// configured static Config.Token (eg. "Authorization: Bearer TOKEN").
+//
+// The following metrics are collected:
+//
+//	pocketbase_http_request_duration_seconds - HTTP requests histogram by method, route pattern and status
+//	pocketbase_hook_executions_total         - the total number of the app hooks executions
+//	pocketbase_realtime_clients              - the current number of the realtime clients
+//	pocketbase_cron_job_duration_seconds     - cron job runs histogram by job id and status
+//	pocketbase_db_queries_total              - the total number of DB queries by db and query type
+//	pocketbase_backup_duration_seconds       - backup create and restore histogram by operation and status
+//
+// Example usage:
+//
+//	metrics.MustRegister(app, metrics.Config{
+//		Token: os.Getenv("METRICS_TOKEN"),
+//	})
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/cron"
+	"github.com/pocketbase/pocketbase/tools/hook"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+const (
+	// MiddlewareId is the id of the HTTP requests metrics middleware.
+	MiddlewareId = "pbMetrics"
+
+	// MiddlewarePriority is the priority of the HTTP requests metrics middleware
+	// (executed before the default activity logger to include its duration).
+	MiddlewarePriority = apis.DefaultActivityLoggerMiddlewarePriority - 10
+)
+
+// The status label values of the cron job and backup metrics.
+const (
+	statusSuccess = "success"
+	statusError   = "error"
+)
+
+// Config defines the config options of the metrics plugin.
+type Config struct {
+	// Token is an optional static token that allows accessing the metrics
+	// endpoint without superuser authentication (eg. for the Prometheus scraper).
+	//
+	// It is expected to be sent as "Authorization: Bearer TOKEN" header.
+	Token string
+
+	// Buckets specifies the HTTP request duration histogram buckets
+	// in seconds (default to [DefaultBuckets]).
+	Buckets []float64
+}
+
+// MustRegister registers the metrics plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the metrics plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	_, err := register(app, config)
+
+	return err
+}
+
+func register(app core.App, config Config) (*plugin, error) {
+	if len(config.Buckets) == 0 {
+		config.Buckets = DefaultBuckets
+	}
+
+	p := &plugin{
+		app:    app,
+		config: config,
+	}
+
+	p.httpRequests = newHistogram(
+		"pocketbase_http_request_duration_seconds",
+		"The HTTP requests duration in seconds.",
+		config.Buckets,
+		"method", "route", "status",
+	)
+	p.hookExecutions = newCounter(
+		"pocketbase_hook_executions_total",
+		"The total number of the app hooks executions.",
+		"hook",
+	)
+	p.realtimeClients = newGaugeFunc(
+		"pocketbase_realtime_clients",
+		"The current number of the connected realtime clients.",
+		func() float64 {
+			return float64(p.app.SubscriptionsBroker().TotalClients())
+		},
+	)
+	p.cronJobs = newHistogram(
+		"pocketbase_cron_job_duration_seconds",
+		"The cron job runs duration in seconds.",
+		longBuckets,
+		"job", "status",
+	)
+	p.dbQueries = newCounter(
+		"pocketbase_db_queries_total",
+		"The total number of the executed DB queries.",
+		"db", "type",
+	)
+	p.backups = newHistogram(
+		"pocketbase_backup_duration_seconds",
+		"The backup create and restore operations duration in seconds.",
+		longBuckets,
+		"operation", "status",
+	)
+
+	p.collectors = []collector{
+		p.httpRequests,
+		p.hookExecutions,
+		p.realtimeClients,
+		p.cronJobs,
+		p.dbQueries,
+		p.backups,
+	}
+
+	if err := p.bindHooksCounter(); err != nil {
+		return nil, err
+	}
+
+	p.bindCronListener()
+
+	p.bindBackupHooks()
+
+	if app.IsBootstrapped() {
+		p.bindDBLogFuncs()
+	}
+
+	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		// the db connections are recreated on each bootstrap
+		p.bindDBLogFuncs()
+
+		return nil
+	})
+
+	app.OnServe().Bind(&hook.Handler[*core.ServeEvent]{
+		Func: func(e *core.ServeEvent) error {
+			e.Router.Bind(&hook.Handler[*core.RequestEvent]{
+				Id:       MiddlewareId,
+				Priority: MiddlewarePriority,
+				Func:     p.requestsMiddleware,
+			})
+
+			e.Router.GET("/api/metrics", p.metricsHandler)
+
+			return e.Next()
+		},
+		Priority: 999, // execute as latest as possible to allow users to provide their own route
+	})
+
+	return p, nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	httpRequests    *histogram
+	hookExecutions  *counter
+	realtimeClients *gaugeFunc
+	cronJobs        *histogram
+	dbQueries       *counter
+	backups         *histogram
+
+	collectors []collector
+}
+
+func (p *plugin) metricsHandler(e *core.RequestEvent) error {
+	if !p.isAuthorized(e) {
+		return e.UnauthorizedError("The request requires valid superuser or metrics token authorization.", nil)
+	}
+
+	var buf bytes.Buffer
+
+	for _, c := range p.collectors {
+		c.write(&buf)
+	}
+
+	return e.Blob(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", buf.Bytes())
+}
+
+// isAuthorized checks whether the request is authorized
+// either with the configured token or as superuser.
+func (p *plugin) isAuthorized(e *core.RequestEvent) bool {
+	if e.HasSuperuserAuth() {
+		return true
+	}
+
+	if p.config.Token == "" {
+		return false
+	}
+
+	// the static token is accepted only with the Bearer scheme
+	token, ok := strings.CutPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(p.config.Token)) == 1
+}
+
+// requestsMiddleware observes the HTTP requests duration by their route pattern.
+func (p *plugin) requestsMiddleware(e *core.RequestEvent) error {
+	start := time.Now()
+
+	err := e.Next()
+
+	status := e.Status()
+	if status == 0 {
+		status = http.StatusOK
+
+		if err != nil {
+			status = http.StatusInternalServerError
+
+			var apiErr *router.ApiError
+			if errors.As(err, &apiErr) {
+				status = apiErr.Status
+			}
+		}
+	}
+
+	p.httpRequests.observe(
+		time.Since(start).Seconds(),
+		e.Request.Method,
+		routePattern(e.Request),
+		strconv.Itoa(status),
+	)
+
+	return err
+}
+
+// routePattern returns the matched route path pattern of the request
+// (without the method prefix) to avoid high cardinality labels.
+func routePattern(r *http.Request) string {
+	pattern := r.Pattern
+
+	// strip the method prefix (eg. "GET /api/...")
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+
+	return pattern
+}
+
+// bindHooksCounter registers a handler that counts the executions
+// of each app hook (aka. each App.On* method without required arguments).
+func (p *plugin) bindHooksCounter() error {
+	appType := reflect.TypeOf((*core.App)(nil)).Elem()
+	appValue := reflect.ValueOf(p.app)
+
+	resolverType := reflect.TypeOf((*hook.Resolver)(nil)).Elem()
+
+	for i := 0; i < appType.NumMethod(); i++ {
+		method := appType.Method(i)
+
+		if !strings.HasPrefix(method.Name, "On") {
+			continue
+		}
+
+		// skip hooks with required arguments (the tagged hooks have only optional variadic tags)
+		if method.Type.NumIn() > 1 || (method.Type.NumIn() == 1 && !method.Type.IsVariadic()) {
+			continue
+		}
+
+		results := appValue.MethodByName(method.Name).Call(nil)
+		if len(results) != 1 {
+			continue
+		}
+
+		bindFunc := results[0].MethodByName("BindFunc")
+		if !bindFunc.IsValid() || bindFunc.Type().NumIn() != 1 {
+			continue
+		}
+
+		handlerType := bindFunc.Type().In(0)
+		if handlerType.Kind() != reflect.Func || handlerType.NumIn() != 1 || !handlerType.In(0).Implements(resolverType) {
+			return errors.New("metrics: unsupported " + method.Name + " hook handler signature")
+		}
+
+		hookName := method.Name
+
+		handler := reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+			p.hookExecutions.inc(hookName)
+
+			err := args[0].Interface().(hook.Resolver).Next()
+
+			return []reflect.Value{reflect.ValueOf(&err).Elem()}
+		})
+
+		bindFunc.Call([]reflect.Value{handler})
+	}
+
+	return nil
+}
+
+// bindCronListener observes the cron job runs duration
+// (the previous cron run listener, if any, is preserved).
+func (p *plugin) bindCronListener() {
+	prev := p.app.Cron().RunListener()
+
+	p.app.Cron().SetRunListener(func(run *cron.Run) {
+		if prev != nil {
+			prev(run)
+		}
+
+		status := statusSuccess
+		if run.Error != nil {
+			status = statusError
+		}
+
+		p.cronJobs.observe(run.Duration.Seconds(), run.JobId, status)
+	})
+}
+
+// bindBackupHooks observes the backup create and restore operations duration.
+func (p *plugin) bindBackupHooks() {
+	observe := func(operation string) func(e *core.BackupEvent) error {
+		return func(e *core.BackupEvent) error {
+			start := time.Now()
+
+			err := e.Next()
+
+			status := statusSuccess
+			if err != nil {
+				status = statusError
+			}
+
+			p.backups.observe(time.Since(start).Seconds(), operation, status)
+
+			return err
+		}
+	}
+
+	p.app.OnBackupCreate().BindFunc(observe("create"))
+	p.app.OnBackupRestore().BindFunc(observe("restore"))
+}
+
+// bindDBLogFuncs counts the executed DB queries by wrapping the dbx
+// log funcs of the current db connections (the existing funcs are preserved).
+func (p *plugin) bindDBLogFuncs() {
+	p.wrapDB(p.app.ConcurrentDB(), "data")
+	p.wrapDB(p.app.NonconcurrentDB(), "data")
+	p.wrapDB(p.app.AuxConcurrentDB(), "auxiliary")
+	p.wrapDB(p.app.AuxNonconcurrentDB(), "auxiliary")
+}
+
+func (p *plugin) wrapDB(builder dbx.Builder, dbName string) {
+	db, ok := builder.(*dbx.DB)
+	if !ok {
+		return
+	}
+
+	prevQuery := db.QueryLogFunc
+	db.QueryLogFunc = func(ctx context.Context, t time.Duration, sql string, rows *sql.Rows, err error) {
+		p.dbQueries.inc(dbName, "query")
+
+		if prevQuery != nil {
+			prevQuery(ctx, t, sql, rows, err)
+		}
+	}
+
+	prevExec := db.ExecLogFunc
+	db.ExecLogFunc = func(ctx context.Context, t time.Duration, sql string, result sql.Result, err error) {
+		p.dbQueries.inc(dbName, "exec")
+
+		if prevExec != nil {
+			prevExec(ctx, t, sql, result, err)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets defines the default HTTP request duration histogram buckets (in seconds).
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// longBuckets defines the histogram buckets (in seconds) used
+// for the usually long running operations like cron jobs and backups.
+var longBuckets = []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 600, 1800, 3600}
+
+// collector defines a single metric family that could be exported
+// in the Prometheus text exposition format.
+type collector interface {
+	write(w io.Writer)
+}
+
+// -------------------------------------------------------------------
+
+// counter is a minimal Prometheus counter metric with optional labels.
+type counter struct {
+	series     map[string]*counterSeries
+	name       string
+	help       string
+	labelNames []string
+	mu         sync.Mutex
+}
+
+type counterSeries struct {
+	labelValues []string
+	value       float64
+}
+
+func newCounter(name string, help string, labelNames ...string) *counter {
+	return &counter{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		series:     map[string]*counterSeries{},
+	}
+}
+
+// inc increments the counter series with the specified label values by 1.
+func (c *counter) inc(labelValues ...string) {
+	c.add(1, labelValues...)
+}
+
+// add increments the counter series with the specified label values by v.
+func (c *counter) add(v float64, labelValues ...string) {
+	key := seriesKey(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labelValues: labelValues}
+		c.series[key] = s
+	}
+
+	s.value += v
+}
+
+func (c *counter) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labelNames, s.labelValues), formatFloat(s.value))
+	}
+}
+
+// -------------------------------------------------------------------
+
+// gaugeFunc is a minimal Prometheus gauge metric
+// whose value is resolved on export.
+type gaugeFunc struct {
+	fn   func() float64
+	name string
+	help string
+}
+
+func newGaugeFunc(name string, help string, fn func() float64) *gaugeFunc {
+	return &gaugeFunc{
+		name: name,
+		help: help,
+		fn:   fn,
+	}
+}
+
+func (g *gaugeFunc) write(w io.Writer) {
+	writeHeader(w, g.name, g.help, "gauge")
+
+	fmt.Fprintf(w, "%s %s\n", g.name, formatFloat(g.fn()))
+}
+
+// -------------------------------------------------------------------
+
+// histogram is a minimal Prometheus histogram metric with optional labels.
+type histogram struct {
+	series     map[string]*histogramSeries
+	name       string
+	help       string
+	labelNames []string
+	buckets    []float64
+	mu         sync.Mutex
+}
+
+type histogramSeries struct {
+	labelValues []string
+	counts      []uint64 // non-cumulative counts per bucket
+	sum         float64
+	count       uint64
+}
+
+func newHistogram(name string, help string, buckets []float64, labelNames ...string) *histogram {
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	return &histogram{
+		name:       name,
+		help:       help,
+		buckets:    buckets,
+		labelNames: labelNames,
+		series:     map[string]*histogramSeries{},
+	}
+}
+
+// observe adds a single observation to the histogram series with the specified label values.
+func (h *histogram) observe(v float64, labelValues ...string) {
+	key := seriesKey(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{
+			labelValues: labelValues,
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = s
+	}
+
+	for i, upper := range h.buckets {
+		if v <= upper {
+			s.counts[i]++
+			break
+		}
+	}
+
+	s.sum += v
+	s.count++
+}
+
+func (h *histogram) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+
+	bucketLabelNames := append(slices.Clone(h.labelNames), "le")
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		var cumulative uint64
+		for i, upper := range h.buckets {
+			cumulative += s.counts[i]
+			labels := formatLabels(bucketLabelNames, append(slices.Clone(s.labelValues), formatFloat(upper)))
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, cumulative)
+		}
+
+		labels := formatLabels(bucketLabelNames, append(slices.Clone(s.labelValues), "+Inf"))
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, labels, s.count)
+
+		plainLabels := formatLabels(h.labelNames, s.labelValues)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, plainLabels, formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, plainLabels, s.count)
+	}
+}
+
+// -------------------------------------------------------------------
+
+func writeHeader(w io.Writer, name string, help string, metricType string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func formatLabels(names []string, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+
+	sb.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+
+		var value string
+		if i < len(values) {
+			value = values[i]
+		}
+
+		sb.WriteString(name)
+		sb.WriteString(`="`)
+		sb.WriteString(labelValueReplacer.Replace(value))
+		sb.WriteString(`"`)
+	}
+	sb.WriteString("}")
+
+	return sb.String()
+}
+
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
@@ -27,6 +27,10 @@ func TestAutomigrateCollectionCreate(t *testing.T) {
 /// <reference path="../pb_data/types.d.ts" />
 migrate((app) => {
   const collection = new Collection({
+    "apiKeys": {
+      "enabled": false,
+      "maxDuration": 0
+    },
     "authAlert": {
       "emailTemplate": {
         "body": "<p>Hello,</p>\n<p>We noticed a login to your {APP_NAME} account from a new location.</p>\n<p>If this was you, you may disregard this email.</p>\n<p><strong>If this wasn't you, you should immediately change your {APP_NAME} account password to revoke access from all other locations.</strong></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
@@ -50,6 +54,7 @@ migrate((app) => {
     "fields": [
       {
         "autogeneratePattern": "[a-z0-9]{15}",
+        "fullText": false,
         "hidden": false,
         "id": "text@TEST_RANDOM",
         "max": 15,
@@ -63,10 +68,15 @@ migrate((app) => {
         "type": "text"
       },
       {
+        "breachedHashesPath": "",
+        "charClasses": null,
         "cost": 0,
+        "disallowIdentity": false,
         "hidden": true,
+        "historySize": 0,
         "id": "password@TEST_RANDOM",
         "max": 0,
+        "maxAge": 0,
         "min": 8,
         "name": "password",
         "pattern": "",
@@ -77,6 +87,7 @@ migrate((app) => {
       },
       {
         "autogeneratePattern": "[a-zA-Z0-9]{50}",
+        "fullText": false,
         "hidden": true,
         "id": "text@TEST_RANDOM",
         "max": 60,
@@ -152,22 +163,64 @@ migrate((app) => {
         "subject": "OTP for {APP_NAME}"
       },
       "enabled": false,
-      "length": 8
+      "length": 8,
+      "lockout": {
+        "duration": 60,
+        "enabled": false,
+        "maxAttempts": 5,
+        "maxDuration": 3600
+      }
     },
     "passwordAuth": {
       "enabled": true,
       "identityFields": [
         "email"
-      ]
+      ],
+      "lockout": {
+        "duration": 60,
+        "enabled": false,
+        "maxAttempts": 5,
+        "maxDuration": 3600
+      }
     },
     "passwordResetToken": {
       "duration": 1800
     },
+    "refreshToken": {
+      "duration": 2592000,
+      "enabled": false
+    },
     "resetPasswordTemplate": {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "saml": {
+      "duration": 300,
+      "enabled": false,
+      "entityId": "",
+      "idpCertificate": "",
+      "idpEntityId": "",
+      "idpSSOURL": "",
+      "mappedAttributes": {
+        "avatarURL": "",
+        "email": "",
+        "id": "",
+        "name": "",
+        "username": ""
+      },
+      "mappedFields": {
+        "avatarURL": "",
+        "id": "",
+        "name": "",
+        "username": ""
+      },
+      "redirectURL": ""
+    },
     "system": true,
+    "totp": {
+      "enabled": false,
+      "issuer": ""
+    },
     "type": "auth",
     "updateRule": "id = @request.auth.id",
     "verificationTemplate": {
@@ -177,7 +230,14 @@ migrate((app) => {
     "verificationToken": {
       "duration": 259200
     },
-    "viewRule": "id = \"1\""
+    "viewRule": "id = \"1\"",
+    "webauthn": {
+      "duration": 180,
+      "enabled": false,
+      "requireUserVerification": false,
+      "rpId": "",
+      "rpName": ""
+    }
   });
 
   return app.save(collection);
@@ -203,6 +263,10 @@ import (
 func init() {
 	m.Register(func(app core.App) error {
 		jsonData := ` + "`" + `{
+			"apiKeys": {
+				"enabled": false,
+				"maxDuration": 0
+			},
 			"authAlert": {
 				"emailTemplate": {
 					"body": "<p>Hello,</p>\n<p>We noticed a login to your {APP_NAME} account from a new location.</p>\n<p>If this was you, you may disregard this email.</p>\n<p><strong>If this wasn't you, you should immediately change your {APP_NAME} account password to revoke access from all other locations.</strong></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
@@ -226,6 +290,7 @@ func init() {
 			"fields": [
 				{
 					"autogeneratePattern": "[a-z0-9]{15}",
+					"fullText": false,
 					"hidden": false,
 					"id": "text@TEST_RANDOM",
 					"max": 15,
@@ -239,10 +304,15 @@ func init() {
 					"type": "text"
 				},
 				{
+					"breachedHashesPath": "",
+					"charClasses": null,
 					"cost": 0,
+					"disallowIdentity": false,
 					"hidden": true,
+					"historySize": 0,
 					"id": "password@TEST_RANDOM",
 					"max": 0,
+					"maxAge": 0,
 					"min": 8,
 					"name": "password",
 					"pattern": "",
@@ -253,6 +323,7 @@ func init() {
 				},
 				{
 					"autogeneratePattern": "[a-zA-Z0-9]{50}",
+					"fullText": false,
 					"hidden": true,
 					"id": "text@TEST_RANDOM",
 					"max": 60,
@@ -328,22 +399,64 @@ func init() {
 					"subject": "OTP for {APP_NAME}"
 				},
 				"enabled": false,
-				"length": 8
+				"length": 8,
+				"lockout": {
+					"duration": 60,
+					"enabled": false,
+					"maxAttempts": 5,
+					"maxDuration": 3600
+				}
 			},
 			"passwordAuth": {
 				"enabled": true,
 				"identityFields": [
 					"email"
-				]
+				],
+				"lockout": {
+					"duration": 60,
+					"enabled": false,
+					"maxAttempts": 5,
+					"maxDuration": 3600
+				}
 			},
 			"passwordResetToken": {
 				"duration": 1800
 			},
+			"refreshToken": {
+				"duration": 2592000,
+				"enabled": false
+			},
 			"resetPasswordTemplate": {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"saml": {
+				"duration": 300,
+				"enabled": false,
+				"entityId": "",
+				"idpCertificate": "",
+				"idpEntityId": "",
+				"idpSSOURL": "",
+				"mappedAttributes": {
+					"avatarURL": "",
+					"email": "",
+					"id": "",
+					"name": "",
+					"username": ""
+				},
+				"mappedFields": {
+					"avatarURL": "",
+					"id": "",
+					"name": "",
+					"username": ""
+				},
+				"redirectURL": ""
+			},
 			"system": true,
+			"totp": {
+				"enabled": false,
+				"issuer": ""
+			},
 			"type": "auth",
 			"updateRule": "id = @request.auth.id",
 			"verificationTemplate": {
@@ -353,7 +466,14 @@ func init() {
 			"verificationToken": {
 				"duration": 259200
 			},
-			"viewRule": "id = \"1\""
+			"viewRule": "id = \"1\"",
+			"webauthn": {
+				"duration": 180,
+				"enabled": false,
+				"requireUserVerification": false,
+				"rpId": "",
+				"rpName": ""
+			}
 		}` + "`" + `
 
 		collection := &core.Collection{}
@@ -468,6 +588,10 @@ migrate((app) => {
   return app.delete(collection);
 }, (app) => {
   const collection = new Collection({
+    "apiKeys": {
+      "enabled": false,
+      "maxDuration": 0
+    },
     "authAlert": {
       "emailTemplate": {
         "body": "<p>Hello,</p>\n<p>We noticed a login to your {APP_NAME} account from a new location.</p>\n<p>If this was you, you may disregard this email.</p>\n<p><strong>If this wasn't you, you should immediately change your {APP_NAME} account password to revoke access from all other locations.</strong></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
@@ -491,6 +615,7 @@ migrate((app) => {
     "fields": [
       {
         "autogeneratePattern": "[a-z0-9]{15}",
+        "fullText": false,
         "hidden": false,
         "id": "text@TEST_RANDOM",
         "max": 15,
@@ -504,10 +629,15 @@ migrate((app) => {
         "type": "text"
       },
       {
+        "breachedHashesPath": "",
+        "charClasses": null,
         "cost": 0,
+        "disallowIdentity": false,
         "hidden": true,
+        "historySize": 0,
         "id": "password@TEST_RANDOM",
         "max": 0,
+        "maxAge": 0,
         "min": 8,
         "name": "password",
         "pattern": "",
@@ -518,6 +648,7 @@ migrate((app) => {
       },
       {
         "autogeneratePattern": "[a-zA-Z0-9]{50}",
+        "fullText": false,
         "hidden": true,
         "id": "text@TEST_RANDOM",
         "max": 60,
@@ -593,22 +724,64 @@ migrate((app) => {
         "subject": "OTP for {APP_NAME}"
       },
       "enabled": false,
-      "length": 8
+      "length": 8,
+      "lockout": {
+        "duration": 60,
+        "enabled": false,
+        "maxAttempts": 5,
+        "maxDuration": 3600
+      }
     },
     "passwordAuth": {
       "enabled": true,
       "identityFields": [
         "email"
-      ]
+      ],
+      "lockout": {
+        "duration": 60,
+        "enabled": false,
+        "maxAttempts": 5,
+        "maxDuration": 3600
+      }
     },
     "passwordResetToken": {
       "duration": 1800
     },
+    "refreshToken": {
+      "duration": 2592000,
+      "enabled": false
+    },
     "resetPasswordTemplate": {
       "body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
       "subject": "Reset your {APP_NAME} password"
     },
+    "saml": {
+      "duration": 300,
+      "enabled": false,
+      "entityId": "",
+      "idpCertificate": "",
+      "idpEntityId": "",
+      "idpSSOURL": "",
+      "mappedAttributes": {
+        "avatarURL": "",
+        "email": "",
+        "id": "",
+        "name": "",
+        "username": ""
+      },
+      "mappedFields": {
+        "avatarURL": "",
+        "id": "",
+        "name": "",
+        "username": ""
+      },
+      "redirectURL": ""
+    },
     "system": false,
+    "totp": {
+      "enabled": false,
+      "issuer": ""
+    },
     "type": "auth",
     "updateRule": "id = @request.auth.id",
     "verificationTemplate": {
@@ -618,7 +791,14 @@ migrate((app) => {
     "verificationToken": {
       "duration": 259200
     },
-    "viewRule": "id = \"1\""
+    "viewRule": "id = \"1\"",
+    "webauthn": {
+      "duration": 180,
+      "enabled": false,
+      "requireUserVerification": false,
+      "rpId": "",
+      "rpName": ""
+    }
   });
 
   return app.save(collection);
@@ -647,6 +827,10 @@ func init() {
 		return app.Delete(collection)
 	}, func(app core.App) error {
 		jsonData := ` + "`" + `{
+			"apiKeys": {
+				"enabled": false,
+				"maxDuration": 0
+			},
 			"authAlert": {
 				"emailTemplate": {
 					"body": "<p>Hello,</p>\n<p>We noticed a login to your {APP_NAME} account from a new location.</p>\n<p>If this was you, you may disregard this email.</p>\n<p><strong>If this wasn't you, you should immediately change your {APP_NAME} account password to revoke access from all other locations.</strong></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
@@ -670,6 +854,7 @@ func init() {
 			"fields": [
 				{
 					"autogeneratePattern": "[a-z0-9]{15}",
+					"fullText": false,
 					"hidden": false,
 					"id": "text@TEST_RANDOM",
 					"max": 15,
@@ -683,10 +868,15 @@ func init() {
 					"type": "text"
 				},
 				{
+					"breachedHashesPath": "",
+					"charClasses": null,
 					"cost": 0,
+					"disallowIdentity": false,
 					"hidden": true,
+					"historySize": 0,
 					"id": "password@TEST_RANDOM",
 					"max": 0,
+					"maxAge": 0,
 					"min": 8,
 					"name": "password",
 					"pattern": "",
@@ -697,6 +887,7 @@ func init() {
 				},
 				{
 					"autogeneratePattern": "[a-zA-Z0-9]{50}",
+					"fullText": false,
 					"hidden": true,
 					"id": "text@TEST_RANDOM",
 					"max": 60,
@@ -772,22 +963,64 @@ func init() {
 					"subject": "OTP for {APP_NAME}"
 				},
 				"enabled": false,
-				"length": 8
+				"length": 8,
+				"lockout": {
+					"duration": 60,
+					"enabled": false,
+					"maxAttempts": 5,
+					"maxDuration": 3600
+				}
 			},
 			"passwordAuth": {
 				"enabled": true,
 				"identityFields": [
 					"email"
-				]
+				],
+				"lockout": {
+					"duration": 60,
+					"enabled": false,
+					"maxAttempts": 5,
+					"maxDuration": 3600
+				}
 			},
 			"passwordResetToken": {
 				"duration": 1800
 			},
+			"refreshToken": {
+				"duration": 2592000,
+				"enabled": false
+			},
 			"resetPasswordTemplate": {
 				"body": "<p>Hello,</p>\n<p>Click on the button below to reset your password.</p>\n<p>\n  <a class=\"btn\" href=\"{APP_URL}/_/#/auth/confirm-password-reset/{TOKEN}\" target=\"_blank\" rel=\"noopener\">Reset password</a>\n</p>\n<p><i>If you didn't ask to reset your password, you can ignore this email.</i></p>\n<p>\n  Thanks,<br/>\n  {APP_NAME} team\n</p>",
 				"subject": "Reset your {APP_NAME} password"
 			},
+			"saml": {
+				"duration": 300,
+				"enabled": false,
+				"entityId": "",
+				"idpCertificate": "",
+				"idpEntityId": "",
+				"idpSSOURL": "",
+				"mappedAttributes": {
+					"avatarURL": "",
+					"email": "",
+					"id": "",
+					"name": "",
+					"username": ""
+				},
+				"mappedFields": {
+					"avatarURL": "",
+					"id": "",
+					"name": "",
+					"username": ""
+				},
+				"redirectURL": ""
+			},
 			"system": false,
+			"totp": {
+				"enabled": false,
+				"issuer": ""
+			},
 			"type": "auth",
 			"updateRule": "id = @request.auth.id",
 			"verificationTemplate": {
@@ -797,7 +1030,14 @@ func init() {
 			"verificationToken": {
 				"duration": 259200
 			},
-			"viewRule": "id = \"1\""
+			"viewRule": "id = \"1\"",
+			"webauthn": {
+				"duration": 180,
+				"enabled": false,
+				"requireUserVerification": false,
+				"rpId": "",
+				"rpName": ""
+			}
 		}` + "`" + `
 
 		collection := &core.Collection{}
@@ -923,6 +1163,7 @@ migrate((app) => {
   // add field
   collection.fields.addAt(8, new Field({
     "autogeneratePattern": "",
+    "fullText": false,
     "hidden": false,
     "id": "f4_id",
     "max": 0,
@@ -1054,6 +1295,7 @@ func init() {
 		// add field
 		if err := collection.Fields.AddMarshaledJSONAt(8, []byte(` + "`" + `{
 			"autogeneratePattern": "",
+			"fullText": false,
 			"hidden": false,
 			"id": "f4_id",
 			"max": 0,
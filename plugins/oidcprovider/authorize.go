@@ -0,0 +1,316 @@
+package oidcprovider
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// authCode defines a single issued authorization code.
+type authCode struct {
+	expires       time.Time
+	authTime      time.Time
+	clientId      string
+	collectionId  string
+	recordId      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	scopes        []string
+}
+
+type authorizeForm struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientId            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+
+	// Approved is the consent screen decision (used only with POST).
+	Approved bool `form:"approved" json:"approved"`
+}
+
+// authorizeError defines an OAuth2 authorization error that
+// is sent back to the client redirect uri.
+type authorizeError struct {
+	code        string
+	description string
+}
+
+func (err *authorizeError) Error() string {
+	return err.code + ": " + err.description
+}
+
+// scopes returns the list of the requested supported scopes.
+func (form *authorizeForm) scopes() []string {
+	result := []string{}
+
+	for _, scope := range strings.Fields(form.Scope) {
+		if slices.Contains(SupportedScopes, scope) && !slices.Contains(result, scope) {
+			result = append(result, scope)
+		}
+	}
+
+	return result
+}
+
+// validateClient checks whether the form client and redirect uri are valid
+// and returns the found client record.
+//
+// On error the user must not be redirected back to the redirect uri.
+func (p *plugin) validateClient(form *authorizeForm) (*core.Record, error) {
+	if form.ClientId == "" {
+		return nil, errors.New("missing client_id")
+	}
+
+	client, err := p.app.FindRecordById(p.config.ClientsCollection, form.ClientId)
+	if err != nil {
+		return nil, errors.New("invalid client_id")
+	}
+
+	if form.RedirectURI == "" || !slices.Contains(clientRedirectURIs(client), form.RedirectURI) {
+		return nil, errors.New("invalid or unregistered redirect_uri")
+	}
+
+	return client, nil
+}
+
+// validateRequest checks the remaining authorization request parameters
+// (the error is expected to be sent back to the redirect uri).
+func (p *plugin) validateRequest(client *core.Record, form *authorizeForm) error {
+	if form.ResponseType != "code" {
+		return &authorizeError{"unsupported_response_type", "Only the code response type is supported."}
+	}
+
+	if form.CodeChallenge == "" {
+		if client.GetBool("public") {
+			return &authorizeError{"invalid_request", "PKCE code_challenge is required for public clients."}
+		}
+	} else if form.CodeChallengeMethod != "S256" {
+		return &authorizeError{"invalid_request", "Only the S256 code_challenge_method is supported."}
+	}
+
+	return nil
+}
+
+// authorizeRedirect validates the authorization request and
+// redirects to the frontend login and consent page.
+func (p *plugin) authorizeRedirect(e *core.RequestEvent) error {
+	form := &authorizeForm{}
+	if err := router.UnmarshalRequestData(e.Request.URL.Query(), form, "", ""); err != nil {
+		return e.BadRequestError("Failed to load the authorization request.", err)
+	}
+
+	client, err := p.validateClient(form)
+	if err != nil {
+		return e.BadRequestError("Invalid authorization request.", err)
+	}
+
+	if err = p.validateRequest(client, form); err != nil {
+		return e.Redirect(http.StatusFound, redirectURL(form.RedirectURI, form.State, err, ""))
+	}
+
+	loginURL, err := url.Parse(p.config.LoginURL)
+	if err != nil {
+		return e.InternalServerError("Invalid login url.", err)
+	}
+
+	query := loginURL.Query()
+	for k, v := range e.Request.URL.Query() {
+		query[k] = v
+	}
+	loginURL.RawQuery = query.Encode()
+
+	return e.Redirect(http.StatusFound, loginURL.String())
+}
+
+// authorize issues a new authorization code for the authenticated auth record
+// and returns the client redirect url with the code (or the error) as query parameters.
+func (p *plugin) authorize(e *core.RequestEvent) error {
+	if e.Auth == nil || (e.Auth.Collection().Name != p.config.AuthCollection && e.Auth.Collection().Id != p.config.AuthCollection) {
+		return e.ForbiddenError("The authorized record is not allowed to perform this action.", nil)
+	}
+
+	form := &authorizeForm{}
+	if err := e.BindBody(form); err != nil {
+		return e.BadRequestError("Failed to load the authorization request.", err)
+	}
+
+	client, err := p.validateClient(form)
+	if err != nil {
+		return e.BadRequestError("Invalid authorization request.", err)
+	}
+
+	respond := func(err error, code string) error {
+		return e.JSON(http.StatusOK, map[string]string{
+			"redirectURL": redirectURL(form.RedirectURI, form.State, err, code),
+		})
+	}
+
+	if err = p.validateRequest(client, form); err != nil {
+		return respond(err, "")
+	}
+
+	event := new(ConsentEvent)
+	event.RequestEvent = e
+	event.Client = client
+	event.Record = e.Auth
+	event.Scopes = form.scopes()
+	event.Approved = form.Approved
+
+	if p.config.OnConsent != nil {
+		if err = p.config.OnConsent(event); err != nil {
+			return err
+		}
+	}
+
+	if !event.Approved {
+		return respond(&authorizeError{"access_denied", "The user has denied the access."}, "")
+	}
+
+	code := security.RandomString(50)
+
+	err = p.saveCode(code, &authCode{
+		expires:       time.Now().Add(p.config.AuthCodeDuration),
+		authTime:      time.Now(),
+		clientId:      client.Id,
+		collectionId:  event.Record.Collection().Id,
+		recordId:      event.Record.Id,
+		redirectURI:   form.RedirectURI,
+		nonce:         form.Nonce,
+		codeChallenge: form.CodeChallenge,
+		scopes:        event.Scopes,
+	})
+	if err != nil {
+		return e.InternalServerError("Failed to issue the authorization code.", err)
+	}
+
+	return respond(nil, code)
+}
+
+// saveCode persists the authorization code data in the codes collection
+// (only the code hash is stored) and deletes the expired ones.
+func (p *plugin) saveCode(code string, data *authCode) error {
+	collection, err := p.app.FindCachedCollectionByNameOrId(CodesCollectionName)
+	if err != nil {
+		return err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("codeHash", security.SHA256(code))
+	record.Set("clientId", data.clientId)
+	record.Set("collectionRef", data.collectionId)
+	record.Set("recordRef", data.recordId)
+	record.Set("redirectURI", data.redirectURI)
+	record.Set("nonce", data.nonce)
+	record.Set("codeChallenge", data.codeChallenge)
+	record.Set("scopes", data.scopes)
+	record.Set("authTime", data.authTime)
+	record.Set("expires", data.expires)
+
+	if err := p.app.Save(record); err != nil {
+		return err
+	}
+
+	// cleanup the expired codes
+	_, err = p.app.DB().Delete(
+		CodesCollectionName,
+		dbx.NewExp("[[expires]] < {:now}", dbx.Params{"now": types.NowDateTime().String()}),
+	).Execute()
+	if err != nil {
+		p.app.Logger().Warn("Failed to delete the expired OIDC authorization codes", "error", err)
+	}
+
+	return nil
+}
+
+// consumeCode returns and deletes the specified authorization code (if exists and not expired).
+func (p *plugin) consumeCode(code string) (*authCode, bool) {
+	if code == "" {
+		return nil, false
+	}
+
+	var record *core.Record
+
+	// find and delete in a transaction to ensure that the code could be used only once
+	err := p.app.RunInTransaction(func(txApp core.App) error {
+		var err error
+
+		record, err = txApp.FindFirstRecordByData(CodesCollectionName, "codeHash", security.SHA256(code))
+		if err != nil {
+			return err
+		}
+
+		return txApp.Delete(record)
+	})
+	if err != nil {
+		return nil, false
+	}
+
+	result := &authCode{
+		expires:       record.GetDateTime("expires").Time(),
+		authTime:      record.GetDateTime("authTime").Time(),
+		clientId:      record.GetString("clientId"),
+		collectionId:  record.GetString("collectionRef"),
+		recordId:      record.GetString("recordRef"),
+		redirectURI:   record.GetString("redirectURI"),
+		nonce:         record.GetString("nonce"),
+		codeChallenge: record.GetString("codeChallenge"),
+	}
+	_ = record.UnmarshalJSONField("scopes", &result.scopes)
+
+	if time.Now().After(result.expires) {
+		return nil, false
+	}
+
+	return result, true
+}
+
+// clientRedirectURIs returns the registered redirect uris of the client record.
+func clientRedirectURIs(client *core.Record) []string {
+	var result []string
+
+	_ = client.UnmarshalJSONField("redirectURIs", &result)
+
+	return result
+}
+
+// redirectURL builds the client redirect url with the authorization response parameters.
+func redirectURL(redirectURI string, state string, err error, code string) string {
+	u, parseErr := url.Parse(redirectURI)
+	if parseErr != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+
+	var authErr *authorizeError
+	if errors.As(err, &authErr) {
+		query.Set("error", authErr.code)
+		query.Set("error_description", authErr.description)
+	} else if err != nil {
+		query.Set("error", "server_error")
+	} else {
+		query.Set("code", code)
+	}
+
+	if state != "" {
+		query.Set("state", state)
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
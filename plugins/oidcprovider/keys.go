@@ -0,0 +1,181 @@
+package oidcprovider
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+type signingKey struct {
+	id         string
+	privateKey *rsa.PrivateKey
+	created    types.DateTime
+}
+
+// activeKeys returns all not retired signing keys ordered by their created date DESC.
+//
+// A key is retired when it is no longer used for signing
+// and all tokens signed with it have expired.
+func (p *plugin) activeKeys() ([]*signingKey, error) {
+	records, err := p.app.FindRecordsByFilter(
+		KeysCollectionName,
+		"created > {:date}",
+		"-created",
+		0,
+		0,
+		dbx.Params{"date": p.retiredKeysDate()},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*signingKey, 0, len(records))
+
+	for _, r := range records {
+		key, err := decodePrivateKey(r.GetString("privateKey"))
+		if err != nil {
+			p.app.Logger().Warn("Failed to decode OIDC signing key", "error", err, "keyId", r.Id)
+			continue
+		}
+
+		keys = append(keys, &signingKey{
+			id:         r.Id,
+			privateKey: key,
+			created:    r.GetDateTime("created"),
+		})
+	}
+
+	return keys, nil
+}
+
+// retiredKeysDate returns the created date before which the signing keys are considered retired.
+func (p *plugin) retiredKeysDate() types.DateTime {
+	return types.NowDateTime().Add(-p.config.KeyRotationInterval - p.config.TokenDuration)
+}
+
+// currentKey returns the latest signing key and rotates it if
+// it is older than the configured KeyRotationInterval.
+func (p *plugin) currentKey() (*signingKey, error) {
+	p.keysMu.Lock()
+	defer p.keysMu.Unlock()
+
+	keys, err := p.activeKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) > 0 && time.Since(keys[0].created.Time()) < p.config.KeyRotationInterval {
+		return keys[0], nil
+	}
+
+	return p.rotateKey()
+}
+
+// rotateKey generates and persists a new signing key and
+// deletes the retired ones.
+func (p *plugin) rotateKey() (*signingKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := p.app.FindCachedCollectionByNameOrId(KeysCollectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	record := core.NewRecord(collection)
+	record.Set("privateKey", encodePrivateKey(privateKey))
+
+	if err := p.app.Save(record); err != nil {
+		return nil, err
+	}
+
+	// cleanup the retired keys
+	retired, err := p.app.FindRecordsByFilter(
+		KeysCollectionName,
+		"created <= {:date}",
+		"",
+		0,
+		0,
+		dbx.Params{"date": p.retiredKeysDate()},
+	)
+	if err != nil {
+		p.app.Logger().Warn("Failed to load the retired OIDC signing keys", "error", err)
+	}
+	for _, r := range retired {
+		if err := p.app.Delete(r); err != nil {
+			p.app.Logger().Warn("Failed to delete retired OIDC signing key", "error", err, "keyId", r.Id)
+		}
+	}
+
+	return &signingKey{
+		id:         record.Id,
+		privateKey: privateKey,
+		created:    record.GetDateTime("created"),
+	}, nil
+}
+
+// findPublicKey returns the public key of the active signing key with the specified id.
+func (p *plugin) findPublicKey(keyId string) (*rsa.PublicKey, error) {
+	keys, err := p.activeKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, key := range keys {
+		if key.id == keyId {
+			return &key.privateKey.PublicKey, nil
+		}
+	}
+
+	return nil, errors.New("missing or retired signing key")
+}
+
+func (p *plugin) jwks(e *core.RequestEvent) error {
+	keys, err := p.activeKeys()
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	result := make([]map[string]string, 0, len(keys))
+
+	for _, key := range keys {
+		result = append(result, map[string]string{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": "RS256",
+			"kid": key.id,
+			"n":   base64.RawURLEncoding.EncodeToString(key.privateKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.privateKey.E)).Bytes()),
+		})
+	}
+
+	return e.JSON(http.StatusOK, map[string]any{"keys": result})
+}
+
+func encodePrivateKey(key *rsa.PrivateKey) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+func decodePrivateKey(encoded string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(encoded))
+	if block == nil {
+		return nil, errors.New("invalid PEM encoded private key")
+	}
+
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
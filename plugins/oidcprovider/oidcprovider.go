@@ -0,0 +1,352 @@
+// Package oidcprovider implements a minimal OAuth2 authorization server
+// with OpenID Connect support that allows third party apps to
+// "Sign in with PocketBase" using the records of an auth collection.
+//
+// The plugin registers the following endpoints:
+//
+//	GET  /.well-known/openid-configuration - OIDC discovery document
+//	GET  /api/oauth2/jwks                  - the public keys used to verify the issued tokens
+//	GET  /api/oauth2/authorize             - validates the authorization request and redirects to Config.LoginURL
+//	POST /api/oauth2/authorize             - issues an authorization code for the authenticated auth record (aka. consent)
+//	POST /api/oauth2/token                 - exchanges an authorization code for access and id tokens
+//	GET  /api/oauth2/userinfo              - returns the claims of the access token auth record
+//
+// Only the "authorization_code" grant is supported and public clients are required to use PKCE (S256).
+//
+// Because PocketBase doesn't have server-side rendered pages, the login and consent
+// screen must be implemented by your frontend at Config.LoginURL. It will receive the
+// original authorization request query parameters and after authenticating the user
+// with the regular auth collection methods it should submit the same parameters
+// (+ "approved") to POST /api/oauth2/authorize and redirect to the returned "redirectURL".
+//
+// Example usage:
+//
+//	oidcprovider.MustRegister(app, oidcprovider.Config{
+//		AuthCollection: "users",
+//		LoginURL:       "https://example.com/oauth2-login",
+//	})
+package oidcprovider
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+)
+
+const (
+	// KeysCollectionName is the name of the system collection
+	// that stores the id tokens signing key pairs.
+	KeysCollectionName = "_oidcKeys"
+
+	// CodesCollectionName is the name of the system collection
+	// that stores the issued (not yet exchanged) authorization codes.
+	CodesCollectionName = "_oidcCodes"
+
+	// DefaultClientsCollectionName is the default name of the
+	// collection that stores the registered OAuth2 clients.
+	DefaultClientsCollectionName = "oidcClients"
+)
+
+// Supported scopes.
+const (
+	ScopeOpenId  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// SupportedScopes lists all scopes that could be requested by a client.
+var SupportedScopes = []string{ScopeOpenId, ScopeProfile, ScopeEmail}
+
+// ConsentEvent defines the event that is triggered before issuing
+// an authorization code for the authenticated auth record.
+type ConsentEvent struct {
+	*core.RequestEvent
+
+	// Client is the OAuth2 client record that requests access.
+	Client *core.Record
+
+	// Record is the authenticated auth record.
+	Record *core.Record
+
+	// Scopes is the list of the requested (and supported) scopes.
+	Scopes []string
+
+	// Approved indicates whether the user has approved the client access
+	// (initially loaded from the submitted consent screen form).
+	Approved bool
+}
+
+// Config defines the config options of the oidcprovider plugin.
+//
+// NB! This plugin is considered experimental and its config options may change in the future.
+type Config struct {
+	// AuthCollection specifies the name or id of the auth collection
+	// whose records could sign in to the clients (default to "users").
+	AuthCollection string
+
+	// ClientsCollection specifies the name of the collection that
+	// stores the registered clients (default to [DefaultClientsCollectionName]).
+	//
+	// The collection is created automatically on app bootstrap (if missing).
+	ClientsCollection string
+
+	// LoginURL specifies the url of your frontend login and consent page
+	// where the authorization requests will be redirected (required).
+	LoginURL string
+
+	// Issuer specifies the tokens issuer identifier
+	// (default to the application URL from the settings).
+	Issuer string
+
+	// AuthCodeDuration specifies how long the authorization code is valid (default to 1 minute).
+	AuthCodeDuration time.Duration
+
+	// TokenDuration specifies how long the access and id tokens are valid (default to 1 hour).
+	TokenDuration time.Duration
+
+	// KeyRotationInterval specifies how often a new signing key pair is generated
+	// (default to 30 days).
+	//
+	// The previous key pairs are still listed in the JWKS until
+	// all tokens signed with them expire.
+	KeyRotationInterval time.Duration
+
+	// OnConsent is an optional hook that is called before issuing the
+	// authorization code and that could be used to customize the consent
+	// decision (e.g. to always approve trusted clients by setting e.Approved).
+	//
+	// Return an error to abort the authorization request.
+	OnConsent func(e *ConsentEvent) error
+}
+
+// MustRegister registers the oidcprovider plugin to the provided app instance
+// and panic if it fails.
+func MustRegister(app core.App, config Config) {
+	if err := Register(app, config); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the oidcprovider plugin to the provided app instance.
+func Register(app core.App, config Config) error {
+	_, err := register(app, config)
+
+	return err
+}
+
+func register(app core.App, config Config) (*plugin, error) {
+	p := &plugin{
+		app:    app,
+		config: config,
+	}
+
+	if p.config.LoginURL == "" {
+		return nil, errors.New("oidcprovider: LoginURL is required")
+	}
+
+	if p.config.AuthCollection == "" {
+		p.config.AuthCollection = "users"
+	}
+
+	if p.config.ClientsCollection == "" {
+		p.config.ClientsCollection = DefaultClientsCollectionName
+	}
+
+	if p.config.AuthCodeDuration <= 0 {
+		p.config.AuthCodeDuration = 1 * time.Minute
+	}
+
+	if p.config.TokenDuration <= 0 {
+		p.config.TokenDuration = 1 * time.Hour
+	}
+
+	if p.config.KeyRotationInterval <= 0 {
+		p.config.KeyRotationInterval = 30 * 24 * time.Hour
+	}
+
+	if app.IsBootstrapped() {
+		if err := p.syncCollections(); err != nil {
+			return nil, err
+		}
+	}
+
+	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+
+		return p.syncCollections()
+	})
+
+	app.OnServe().Bind(&hook.Handler[*core.ServeEvent]{
+		Func: func(e *core.ServeEvent) error {
+			p.bindRoutes(e)
+
+			return e.Next()
+		},
+		Priority: 999, // execute as latest as possible to allow users to provide their own route
+	})
+
+	return p, nil
+}
+
+type plugin struct {
+	app    core.App
+	config Config
+
+	keysMu sync.Mutex
+}
+
+func (p *plugin) bindRoutes(e *core.ServeEvent) {
+	e.Router.GET("/.well-known/openid-configuration", p.discovery)
+
+	sub := e.Router.Group("/api/oauth2")
+	sub.GET("/jwks", p.jwks)
+	sub.GET("/authorize", p.authorizeRedirect)
+	sub.POST("/authorize", p.authorize).Bind(apis.RequireAuth())
+	sub.POST("/token", p.token)
+	sub.GET("/userinfo", p.userinfo)
+	sub.POST("/userinfo", p.userinfo)
+}
+
+// issuer returns the configured issuer identifier or the app url as fallback.
+func (p *plugin) issuer() string {
+	if p.config.Issuer != "" {
+		return strings.TrimRight(p.config.Issuer, "/")
+	}
+
+	return strings.TrimRight(p.app.Settings().Meta.AppURL, "/")
+}
+
+func (p *plugin) discovery(e *core.RequestEvent) error {
+	issuer := p.issuer()
+
+	return e.JSON(http.StatusOK, map[string]any{
+		"issuer":                                issuer,
+		"authorization_endpoint":                issuer + "/api/oauth2/authorize",
+		"token_endpoint":                        issuer + "/api/oauth2/token",
+		"userinfo_endpoint":                     issuer + "/api/oauth2/userinfo",
+		"jwks_uri":                              issuer + "/api/oauth2/jwks",
+		"scopes_supported":                      SupportedScopes,
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "auth_time", "nonce",
+			"name", "preferred_username", "picture", "email", "email_verified",
+		},
+	})
+}
+
+// syncCollections creates the plugin collections (if missing).
+func (p *plugin) syncCollections() error {
+	if _, err := p.app.FindCollectionByNameOrId(KeysCollectionName); err != nil {
+		keys := core.NewBaseCollection(KeysCollectionName)
+		keys.System = true
+		keys.Fields.Add(&core.TextField{
+			Name:     "privateKey",
+			System:   true,
+			Required: true,
+			Hidden:   true,
+		})
+		keys.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+
+		if err := p.app.Save(keys); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(CodesCollectionName); err != nil {
+		codes := core.NewBaseCollection(CodesCollectionName)
+		codes.System = true
+		codes.Fields.Add(&core.TextField{
+			Name:     "codeHash",
+			System:   true,
+			Required: true,
+			Hidden:   true,
+		})
+		for _, name := range []string{"clientId", "collectionRef", "recordRef", "redirectURI"} {
+			codes.Fields.Add(&core.TextField{
+				Name:     name,
+				System:   true,
+				Required: true,
+			})
+		}
+		for _, name := range []string{"nonce", "codeChallenge"} {
+			codes.Fields.Add(&core.TextField{
+				Name:   name,
+				System: true,
+			})
+		}
+		codes.Fields.Add(&core.JSONField{
+			Name:   "scopes",
+			System: true,
+		})
+		codes.Fields.Add(&core.DateField{
+			Name:   "authTime",
+			System: true,
+		})
+		codes.Fields.Add(&core.DateField{
+			Name:     "expires",
+			System:   true,
+			Required: true,
+		})
+		codes.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			System:   true,
+			OnCreate: true,
+		})
+		codes.AddIndex("idx_oidcCodes_codeHash", true, "codeHash", "")
+
+		if err := p.app.Save(codes); err != nil {
+			return err
+		}
+	}
+
+	if _, err := p.app.FindCollectionByNameOrId(p.config.ClientsCollection); err != nil {
+		clients := core.NewBaseCollection(p.config.ClientsCollection)
+		clients.Fields.Add(&core.TextField{
+			Name:     "name",
+			Required: true,
+		})
+		clients.Fields.Add(&core.PasswordField{
+			Name:   "secret",
+			Hidden: true,
+		})
+		clients.Fields.Add(&core.JSONField{
+			Name:     "redirectURIs",
+			Required: true,
+		})
+		clients.Fields.Add(&core.BoolField{
+			Name: "public",
+		})
+		clients.Fields.Add(&core.AutodateField{
+			Name:     "created",
+			OnCreate: true,
+		})
+		clients.Fields.Add(&core.AutodateField{
+			Name:     "updated",
+			OnCreate: true,
+			OnUpdate: true,
+		})
+
+		if err := p.app.Save(clients); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package oidcprovider
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// TokenTypeAccess is the "type" claim value of the issued access tokens.
+const TokenTypeAccess = "oidc_access"
+
+type tokenForm struct {
+	GrantType    string `form:"grant_type" json:"grant_type"`
+	Code         string `form:"code" json:"code"`
+	RedirectURI  string `form:"redirect_uri" json:"redirect_uri"`
+	ClientId     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+	CodeVerifier string `form:"code_verifier" json:"code_verifier"`
+}
+
+// tokenError writes an OAuth2 token endpoint error response.
+func tokenError(e *core.RequestEvent, status int, code string, description string) error {
+	return e.JSON(status, map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+// token exchanges an authorization code for access and id tokens.
+func (p *plugin) token(e *core.RequestEvent) error {
+	form := &tokenForm{}
+	if err := e.BindBody(form); err != nil {
+		return tokenError(e, http.StatusBadRequest, "invalid_request", "Failed to load the submitted data.")
+	}
+
+	// client_secret_basic
+	if id, secret, ok := e.Request.BasicAuth(); ok {
+		form.ClientId = id
+		form.ClientSecret = secret
+	}
+
+	if form.GrantType != "authorization_code" {
+		return tokenError(e, http.StatusBadRequest, "unsupported_grant_type", "Only the authorization_code grant type is supported.")
+	}
+
+	client, err := p.app.FindRecordById(p.config.ClientsCollection, form.ClientId)
+	if err != nil {
+		return tokenError(e, http.StatusUnauthorized, "invalid_client", "Invalid client credentials.")
+	}
+
+	if !client.GetBool("public") {
+		secret, _ := client.GetRaw("secret").(*core.PasswordFieldValue)
+		if secret == nil || form.ClientSecret == "" || !secret.Validate(form.ClientSecret) {
+			return tokenError(e, http.StatusUnauthorized, "invalid_client", "Invalid client credentials.")
+		}
+	}
+
+	code, ok := p.consumeCode(form.Code)
+	if !ok || code.clientId != client.Id || code.redirectURI != form.RedirectURI {
+		return tokenError(e, http.StatusBadRequest, "invalid_grant", "Invalid or expired authorization code.")
+	}
+
+	if code.codeChallenge != "" {
+		challenge := security.S256Challenge(form.CodeVerifier)
+		if form.CodeVerifier == "" || subtle.ConstantTimeCompare([]byte(challenge), []byte(code.codeChallenge)) != 1 {
+			return tokenError(e, http.StatusBadRequest, "invalid_grant", "Invalid PKCE code_verifier.")
+		}
+	}
+
+	record, err := p.app.FindRecordById(code.collectionId, code.recordId)
+	if err != nil {
+		return tokenError(e, http.StatusBadRequest, "invalid_grant", "The authorization code auth record is missing.")
+	}
+
+	key, err := p.currentKey()
+	if err != nil {
+		return e.InternalServerError("Failed to load the signing key.", err)
+	}
+
+	issuer := p.issuer()
+	issuedAt := time.Now().Unix()
+
+	accessToken, err := security.NewRS256JWT(jwt.MapClaims{
+		"type":      TokenTypeAccess,
+		"iss":       issuer,
+		"sub":       record.Id,
+		"aud":       client.Id,
+		"client_id": client.Id,
+		"scope":     strings.Join(code.scopes, " "),
+		"iat":       issuedAt,
+	}, key.privateKey, key.id, p.config.TokenDuration)
+	if err != nil {
+		return e.InternalServerError("Failed to generate access token.", err)
+	}
+
+	result := map[string]any{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"expires_in":   int64(p.config.TokenDuration.Seconds()),
+		"scope":        strings.Join(code.scopes, " "),
+	}
+
+	if slices.Contains(code.scopes, ScopeOpenId) {
+		claims := p.recordClaims(record, code.scopes)
+		claims["iss"] = issuer
+		claims["aud"] = client.Id
+		claims["iat"] = issuedAt
+		claims["auth_time"] = code.authTime.Unix()
+		if code.nonce != "" {
+			claims["nonce"] = code.nonce
+		}
+
+		idToken, err := security.NewRS256JWT(claims, key.privateKey, key.id, p.config.TokenDuration)
+		if err != nil {
+			return e.InternalServerError("Failed to generate id token.", err)
+		}
+
+		result["id_token"] = idToken
+	}
+
+	e.Response.Header().Set("Cache-Control", "no-store")
+
+	return e.JSON(http.StatusOK, result)
+}
+
+// recordClaims returns the standard OIDC claims of the auth record based on the granted scopes.
+func (p *plugin) recordClaims(record *core.Record, scopes []string) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"sub": record.Id,
+	}
+
+	if slices.Contains(scopes, ScopeEmail) {
+		claims["email"] = record.Email()
+		claims["email_verified"] = record.Verified()
+	}
+
+	if slices.Contains(scopes, ScopeProfile) {
+		if name := record.GetString("name"); name != "" {
+			claims["name"] = name
+		}
+
+		if username := record.GetString("username"); username != "" {
+			claims["preferred_username"] = username
+		}
+
+		if avatar := record.GetString("avatar"); avatar != "" {
+			if _, ok := record.Collection().Fields.GetByName("avatar").(*core.FileField); ok {
+				claims["picture"] = strings.TrimRight(p.app.Settings().Meta.AppURL, "/") +
+					"/api/files/" + record.Collection().Id + "/" + record.Id + "/" + avatar
+			}
+		}
+	}
+
+	return claims
+}
@@ -0,0 +1,40 @@
+package oidcprovider
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cast"
+)
+
+// userinfo returns the claims of the auth record associated with the Bearer access token.
+func (p *plugin) userinfo(e *core.RequestEvent) error {
+	token, ok := strings.CutPrefix(e.Request.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		e.Response.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return tokenError(e, http.StatusUnauthorized, "invalid_token", "Missing Bearer access token.")
+	}
+
+	claims, err := security.ParseRS256JWT(token, p.findPublicKey)
+	if err != nil || claims["type"] != TokenTypeAccess || claims["iss"] != p.issuer() {
+		e.Response.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return tokenError(e, http.StatusUnauthorized, "invalid_token", "Invalid or expired access token.")
+	}
+
+	collection, err := p.app.FindCachedCollectionByNameOrId(p.config.AuthCollection)
+	if err != nil {
+		return e.InternalServerError("", err)
+	}
+
+	record, err := p.app.FindRecordById(collection, cast.ToString(claims["sub"]))
+	if err != nil {
+		e.Response.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		return tokenError(e, http.StatusUnauthorized, "invalid_token", "The access token auth record is missing.")
+	}
+
+	scopes := strings.Fields(cast.ToString(claims["scope"]))
+
+	return e.JSON(http.StatusOK, p.recordClaims(record, scopes))
+}
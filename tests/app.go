@@ -572,6 +572,22 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRealtimePublishRequest().Bind(&hook.Handler[*core.RealtimePublishRequestEvent]{
+		Func: func(e *core.RealtimePublishRequestEvent) error {
+			t.registerEventCall("OnRealtimePublishRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRealtimePresenceChange().Bind(&hook.Handler[*core.RealtimePresenceEvent]{
+		Func: func(e *core.RealtimePresenceEvent) error {
+			t.registerEventCall("OnRealtimePresenceChange")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnSettingsListRequest().Bind(&hook.Handler[*core.SettingsListRequestEvent]{
 		Func: func(e *core.SettingsListRequestEvent) error {
 			t.registerEventCall("OnSettingsListRequest")
@@ -708,6 +724,30 @@ func NewTestAppWithConfig(config core.BaseAppConfig) (*TestApp, error) {
 		Priority: -99999,
 	})
 
+	t.OnRecordAuthWithTOTPRequest().Bind(&hook.Handler[*core.RecordAuthWithTOTPRequestEvent]{
+		Func: func(e *core.RecordAuthWithTOTPRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithTOTPRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthWithWebAuthnRequest().Bind(&hook.Handler[*core.RecordAuthWithWebAuthnRequestEvent]{
+		Func: func(e *core.RecordAuthWithWebAuthnRequestEvent) error {
+			t.registerEventCall("OnRecordAuthWithWebAuthnRequest")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
+	t.OnRecordAuthLockout().Bind(&hook.Handler[*core.RecordAuthLockoutEvent]{
+		Func: func(e *core.RecordAuthLockoutEvent) error {
+			t.registerEventCall("OnRecordAuthLockout")
+			return e.Next()
+		},
+		Priority: -99999,
+	})
+
 	t.OnRecordsListRequest().Bind(&hook.Handler[*core.RecordsListRequestEvent]{
 		Func: func(e *core.RecordsListRequestEvent) error {
 			t.registerEventCall("OnRecordsListRequest")
@@ -8,15 +8,18 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"math/big"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/pocketbase/pocketbase/tools/store"
 	"github.com/pocketbase/pocketbase/tools/types"
 	"github.com/spf13/cast"
 	"golang.org/x/oauth2"
@@ -28,6 +31,27 @@ import (
 // (the value must be in seconds, e.g. "PB_ID_TOKEN_LEEWAY=60" for 1 minute).
 var idTokenLeeway time.Duration = 5 * time.Minute
 
+// oidcCacheDuration specifies how long the fetched OIDC discovery documents and JWKS are cached.
+var oidcCacheDuration time.Duration = 1 * time.Hour
+
+// oidcDiscoveryErrorCacheDuration specifies how long the failed OIDC discovery
+// document fetches are cached (to avoid blocking every auth request on an unavailable issuer).
+var oidcDiscoveryErrorCacheDuration time.Duration = 1 * time.Minute
+
+type oidcCacheItem[T any] struct {
+	value   T
+	err     error
+	expires time.Time
+}
+
+var (
+	oidcDiscoveryCache = store.New[string, *oidcCacheItem[*oidcDiscoveryDocument]](nil)
+	oidcJWKSCache      = store.New[string, *oidcCacheItem[[]*jwk]](nil)
+
+	// oidcDiscoveryPending holds the issuers with in progress background discovery.
+	oidcDiscoveryPending sync.Map
+)
+
 func init() {
 	Providers[NameOIDC] = wrapFactory(NewOIDCProvider)
 	Providers[NameOIDC+"2"] = wrapFactory(NewOIDCProvider)
@@ -54,13 +78,24 @@ const NameOIDC string = "oidc"
 // The provider support the following Extra config options:
 //   - "jwksURL" - url to the keys to validate the id_token signature (optional and used only when reading the user data from the id_token)
 //   - "issuers" - list of valid issuers for the iss id_token claim (optioanl and used only when reading the user data from the id_token)
+//   - "issuer"  - the provider issuer url (optional, see below)
+//
+// When the "issuer" Extra config option is set, the provider operates in "discovery" mode:
+//   - the auth, token and userinfo urls (if not explicitly set) and the JWKS url are loaded
+//     from the "{issuer}/.well-known/openid-configuration" document (see [OIDC.Discover])
+//   - the discovery document and the JWKS are cached for [oidcCacheDuration]
+//   - the id_token is always validated (iss, aud, exp, iat, nonce and signature) and if
+//     a userinfo url is available its claims are merged with the id_token ones
+//   - the "jwksURL" and "issuers" Extra config options are ignored
 type OIDC struct {
 	BaseProvider
+
+	nonce string
 }
 
 // NewOIDCProvider creates new OpenID Connect (OIDC) provider instance with some defaults.
 func NewOIDCProvider() *OIDC {
-	return &OIDC{BaseProvider{
+	return &OIDC{BaseProvider: BaseProvider{
 		ctx:         context.Background(),
 		displayName: "OIDC",
 		pkce:        true,
@@ -72,6 +107,95 @@ func NewOIDCProvider() *OIDC {
 	}}
 }
 
+// OIDCNonce returns the id_token nonce value bound to the specified PKCE code verifier.
+//
+// Because the code verifier is submitted again when exchanging the authorization code,
+// this allows validating the id_token nonce without storing any server-side state.
+func OIDCNonce(codeVerifier string) string {
+	return security.S256Challenge("nonce:" + codeVerifier)
+}
+
+// Issuer returns the "issuer" Extra config option (if any).
+func (p *OIDC) Issuer() string {
+	return cast.ToString(p.Extra()["issuer"])
+}
+
+// SetNonce sets the expected id_token nonce claim value.
+//
+// The nonce is validated only in discovery mode and when nonempty.
+func (p *OIDC) SetNonce(nonce string) {
+	p.nonce = nonce
+}
+
+// Discover loads the missing provider urls from the issuer discovery document.
+//
+// It is no-op if the "issuer" Extra config option is not set.
+func (p *OIDC) Discover() error {
+	if p.Issuer() == "" {
+		return nil
+	}
+
+	doc, err := p.discoveryDocument()
+	if err != nil {
+		return err
+	}
+
+	p.loadDiscoveryDocumentURLs(doc)
+
+	return nil
+}
+
+// DiscoverCached is similar to [OIDC.Discover] but it never blocks on a network request.
+//
+// It loads the missing provider urls only from the already cached issuer
+// discovery document and returns false if there is no such document
+// (in which case a background discovery is started to warm the cache).
+//
+// It always returns true if the "issuer" Extra config option is not set.
+func (p *OIDC) DiscoverCached() bool {
+	issuer := p.Issuer()
+	if issuer == "" {
+		return true
+	}
+
+	item, ok := oidcDiscoveryCache.GetOk(issuer)
+
+	if !ok || time.Now().After(item.expires) {
+		if _, pending := oidcDiscoveryPending.LoadOrStore(issuer, struct{}{}); !pending {
+			go func() {
+				defer oidcDiscoveryPending.Delete(issuer)
+
+				_, _ = fetchOIDCDiscoveryDocument(context.Background(), issuer)
+			}()
+		}
+	}
+
+	// note: an expired (but previously successfully fetched) document is still used
+	// until the background refresh completes
+	if !ok || item.value == nil {
+		return false
+	}
+
+	p.loadDiscoveryDocumentURLs(item.value)
+
+	return true
+}
+
+// loadDiscoveryDocumentURLs sets the provider urls that are not explicitly configured.
+func (p *OIDC) loadDiscoveryDocumentURLs(doc *oidcDiscoveryDocument) {
+	if p.authURL == "" {
+		p.authURL = doc.AuthorizationEndpoint
+	}
+
+	if p.tokenURL == "" {
+		p.tokenURL = doc.TokenEndpoint
+	}
+
+	if p.userInfoURL == "" {
+		p.userInfoURL = doc.UserInfoEndpoint
+	}
+}
+
 // FetchAuthUser returns an AuthUser instance based the provider's user api.
 //
 // API reference: https://openid.net/specs/openid-connect-core-1_0.html#StandardClaims
@@ -120,7 +244,14 @@ func (p *OIDC) FetchAuthUser(token *oauth2.Token) (*AuthUser, error) {
 // FetchRawUserInfo implements Provider.FetchRawUserInfo interface method.
 //
 // It either fetch the data from p.userInfoURL, or if not set - returns the id_token claims.
+//
+// In discovery mode the id_token is always validated and the
+// p.userInfoURL data (if any) is merged with the id_token claims.
 func (p *OIDC) FetchRawUserInfo(token *oauth2.Token) ([]byte, error) {
+	if p.Issuer() != "" {
+		return p.fetchDiscoveredRawUserInfo(token)
+	}
+
 	if p.userInfoURL != "" {
 		return p.BaseProvider.FetchRawUserInfo(token)
 	}
@@ -133,8 +264,144 @@ func (p *OIDC) FetchRawUserInfo(token *oauth2.Token) ([]byte, error) {
 	return json.Marshal(claims)
 }
 
+func (p *OIDC) fetchDiscoveredRawUserInfo(token *oauth2.Token) ([]byte, error) {
+	claims, err := p.parseDiscoveredIdToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.userInfoURL != "" {
+		data, err := p.BaseProvider.FetchRawUserInfo(token)
+		if err != nil {
+			return nil, err
+		}
+
+		info := map[string]any{}
+		if err := json.Unmarshal(data, &info); err != nil {
+			return nil, err
+		}
+
+		// https://openid.net/specs/openid-connect-core-1_0.html#UserInfoResponse
+		if cast.ToString(info["sub"]) != cast.ToString(claims["sub"]) {
+			return nil, errors.New("the userinfo sub doesn't match with the id_token one")
+		}
+
+		maps.Copy(claims, info)
+	}
+
+	return json.Marshal(claims)
+}
+
+func (p *OIDC) parseDiscoveredIdToken(token *oauth2.Token) (jwt.MapClaims, error) {
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken == "" {
+		return nil, errors.New("empty id_token")
+	}
+
+	doc, err := p.discoveryDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	t, _, err := jwt.NewParser().ParseUnverified(idToken, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+
+	kid, _ := t.Header["kid"].(string)
+
+	key, err := findCachedJWK(p.ctx, doc.JWKSURI, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := key.rsaPublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods([]string{key.alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(idTokenLeeway),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.clientId),
+	)
+
+	claims := jwt.MapClaims{}
+
+	_, err = parser.ParseWithClaims(idToken, claims, func(t *jwt.Token) (any, error) {
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if p.nonce != "" && !security.Equal(cast.ToString(claims["nonce"]), p.nonce) {
+		return nil, errors.New("invalid id_token nonce")
+	}
+
+	return claims, nil
+}
+
+// discoveryDocument returns the (cached) issuer discovery document.
+func (p *OIDC) discoveryDocument() (*oidcDiscoveryDocument, error) {
+	issuer := p.Issuer()
+
+	if item, ok := oidcDiscoveryCache.GetOk(issuer); ok && time.Now().Before(item.expires) {
+		return item.value, item.err
+	}
+
+	return fetchOIDCDiscoveryDocument(p.ctx, issuer)
+}
+
+// fetchOIDCDiscoveryDocument fetches the issuer discovery document and caches the result.
+//
+// The fetch errors are also cached (for a shorter duration) with the exception
+// of the context cancellation ones because they are usually caller specific.
+func fetchOIDCDiscoveryDocument(ctx context.Context, issuer string) (*oidcDiscoveryDocument, error) {
+	doc := &oidcDiscoveryDocument{}
+
+	err := fetchJSON(ctx, strings.TrimRight(issuer, "/")+"/.well-known/openid-configuration", doc)
+	if err == nil {
+		// https://openid.net/specs/openid-connect-discovery-1_0.html#ProviderConfigurationValidation
+		if doc.Issuer != issuer {
+			err = fmt.Errorf("the discovery document issuer %q doesn't match with %q", doc.Issuer, issuer)
+		} else if doc.JWKSURI == "" {
+			err = errors.New("the discovery document is missing jwks_uri")
+		}
+	}
+
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			oidcDiscoveryCache.Set(issuer, &oidcCacheItem[*oidcDiscoveryDocument]{
+				err:     err,
+				expires: time.Now().Add(oidcDiscoveryErrorCacheDuration),
+			})
+		}
+
+		return nil, err
+	}
+
+	oidcDiscoveryCache.Set(issuer, &oidcCacheItem[*oidcDiscoveryDocument]{
+		value:   doc,
+		expires: time.Now().Add(oidcCacheDuration),
+	})
+
+	return doc, nil
+}
+
+type oidcDiscoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
 func (p *OIDC) parseIdToken(token *oauth2.Token) (jwt.MapClaims, error) {
-	idToken := token.Extra("id_token").(string)
+	idToken, _ := token.Extra("id_token").(string)
 	if idToken == "" {
 		return nil, errors.New("empty id_token")
 	}
@@ -203,25 +470,11 @@ func validateIdTokenSignature(ctx context.Context, idToken string, jwksURL strin
 		return err
 	}
 
-	// decode the key params per RFC 7518 (https://tools.ietf.org/html/rfc7518#section-6.3)
-	// and construct a valid publicKey from them
-	// ---
-	exponent, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.E, "="))
-	if err != nil {
-		return err
-	}
-
-	modulus, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.N, "="))
+	publicKey, err := key.rsaPublicKey()
 	if err != nil {
 		return err
 	}
 
-	publicKey := &rsa.PublicKey{
-		// https://tools.ietf.org/html/rfc7517#appendix-A.1
-		E: int(big.NewInt(0).SetBytes(exponent).Uint64()),
-		N: big.NewInt(0).SetBytes(modulus),
-	}
-
 	// verify the signiture
 	// ---
 	parser := jwt.NewParser(jwt.WithValidMethods([]string{key.Alg}))
@@ -249,36 +502,106 @@ type jwk struct {
 	E   string
 }
 
-func fetchJWK(ctx context.Context, jwksURL string, kid string) (*jwk, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", jwksURL, nil)
+// alg returns the key algorithm (fallbacks to RS256 because the "alg" param is optional).
+func (key *jwk) alg() string {
+	if key.Alg == "" {
+		return "RS256"
+	}
+
+	return key.Alg
+}
+
+// rsaPublicKey decodes the key params per RFC 7518 (https://tools.ietf.org/html/rfc7518#section-6.3)
+// and constructs a valid publicKey from them.
+func (key *jwk) rsaPublicKey() (*rsa.PublicKey, error) {
+	exponent, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.E, "="))
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := http.DefaultClient.Do(req)
+	modulus, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key.N, "="))
 	if err != nil {
 		return nil, err
 	}
+
+	return &rsa.PublicKey{
+		// https://tools.ietf.org/html/rfc7517#appendix-A.1
+		E: int(big.NewInt(0).SetBytes(exponent).Uint64()),
+		N: big.NewInt(0).SetBytes(modulus),
+	}, nil
+}
+
+// findCachedJWK returns the jwk with the specified kid from the cached JWKS.
+//
+// The JWKS is fetched again if the key is missing from the cache (e.g. due to a key rotation).
+func findCachedJWK(ctx context.Context, jwksURL string, kid string) (*jwk, error) {
+	if kid == "" {
+		return nil, errors.New("missing kid header value")
+	}
+
+	if item, ok := oidcJWKSCache.GetOk(jwksURL); ok && time.Now().Before(item.expires) {
+		for _, key := range item.value {
+			if key.Kid == kid {
+				return key, nil
+			}
+		}
+	}
+
+	jwks := struct {
+		Keys []*jwk
+	}{}
+	if err := fetchJSON(ctx, jwksURL, &jwks); err != nil {
+		return nil, err
+	}
+
+	oidcJWKSCache.Set(jwksURL, &oidcCacheItem[[]*jwk]{
+		value:   jwks.Keys,
+		expires: time.Now().Add(oidcCacheDuration),
+	})
+
+	for _, key := range jwks.Keys {
+		if key.Kid == kid {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("jwk with kid %q was not found", kid)
+}
+
+// fetchJSON sends a GET request to the specified url and decodes its JSON response into dst.
+func fetchJSON(ctx context.Context, url string, dst any) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
 	defer res.Body.Close()
 
 	rawBody, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// http.Client.Get doesn't treat non 2xx responses as error
 	if res.StatusCode >= 400 {
-		return nil, fmt.Errorf(
-			"failed to verify the provided id_token (%d):\n%s",
-			res.StatusCode,
-			string(rawBody),
-		)
+		return fmt.Errorf("failed to fetch %s (%d):\n%s", url, res.StatusCode, string(rawBody))
 	}
 
+	return json.Unmarshal(rawBody, dst)
+}
+
+func fetchJWK(ctx context.Context, jwksURL string, kid string) (*jwk, error) {
 	jwks := struct {
 		Keys []*jwk
 	}{}
-	if err := json.Unmarshal(rawBody, &jwks); err != nil {
+	if err := fetchJSON(ctx, jwksURL, &jwks); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,386 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"golang.org/x/oauth2"
+)
+
+type testIssuer struct {
+	*httptest.Server
+
+	key      *rsa.PrivateKey
+	keyId    string
+	userInfo map[string]any
+}
+
+func newTestIssuer(t testing.TB, docIssuerSuffix string) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &testIssuer{key: key, keyId: "test_kid"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"issuer":                 issuer.URL + docIssuerSuffix,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"userinfo_endpoint":      issuer.URL + "/userinfo",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": issuer.keyId,
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if issuer.userInfo == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(issuer.userInfo)
+	})
+
+	issuer.Server = httptest.NewServer(mux)
+
+	return issuer
+}
+
+func (issuer *testIssuer) idToken(t testing.TB, claims jwt.MapClaims, key *rsa.PrivateKey) string {
+	if _, ok := claims["exp"]; !ok {
+		claims["exp"] = time.Now().Add(1 * time.Minute).Unix()
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = issuer.keyId
+
+	result, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func TestOIDCNonce(t *testing.T) {
+	a := OIDCNonce("test")
+	b := OIDCNonce("test")
+	c := OIDCNonce("test2")
+
+	if a == "" || a != b {
+		t.Fatalf("Expected the same nonempty nonce, got %q and %q", a, b)
+	}
+
+	if a == c {
+		t.Fatalf("Expected different nonce for different code verifiers, got %q", c)
+	}
+
+	if a == security.S256Challenge("test") {
+		t.Fatal("Expected the nonce to be different from the code challenge")
+	}
+}
+
+func TestOIDCDiscover(t *testing.T) {
+	issuer := newTestIssuer(t, "")
+	defer issuer.Close()
+
+	invalidIssuer := newTestIssuer(t, "/invalid")
+	defer invalidIssuer.Close()
+
+	t.Run("without issuer", func(t *testing.T) {
+		p := NewOIDCProvider()
+
+		if err := p.Discover(); err != nil {
+			t.Fatal(err)
+		}
+
+		if p.AuthURL() != "" || p.TokenURL() != "" || p.UserInfoURL() != "" {
+			t.Fatal("Expected the provider urls to remain empty")
+		}
+	})
+
+	t.Run("with issuer", func(t *testing.T) {
+		p := NewOIDCProvider()
+		p.SetAuthURL("https://example.com/custom_authorize")
+		p.SetExtra(map[string]any{"issuer": issuer.URL})
+
+		if err := p.Discover(); err != nil {
+			t.Fatal(err)
+		}
+
+		if v := p.AuthURL(); v != "https://example.com/custom_authorize" {
+			t.Fatalf("Expected the explicit auth url to remain unchanged, got %q", v)
+		}
+
+		if v := p.TokenURL(); v != issuer.URL+"/token" {
+			t.Fatalf("Expected token url %q, got %q", issuer.URL+"/token", v)
+		}
+
+		if v := p.UserInfoURL(); v != issuer.URL+"/userinfo" {
+			t.Fatalf("Expected userinfo url %q, got %q", issuer.URL+"/userinfo", v)
+		}
+	})
+
+	t.Run("with non-matching discovery document issuer", func(t *testing.T) {
+		p := NewOIDCProvider()
+		p.SetExtra(map[string]any{"issuer": invalidIssuer.URL})
+
+		if err := p.Discover(); err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+}
+
+func TestOIDCDiscoverErrorCache(t *testing.T) {
+	var hits atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	for i := 0; i < 3; i++ {
+		p := NewOIDCProvider()
+		p.SetExtra(map[string]any{"issuer": server.URL})
+
+		if err := p.Discover(); err == nil {
+			t.Fatalf("[%d] Expected error, got nil", i)
+		}
+	}
+
+	if v := hits.Load(); v != 1 {
+		t.Fatalf("Expected the failed discovery to be cached (1 request), got %d requests", v)
+	}
+}
+
+func TestOIDCDiscoverCached(t *testing.T) {
+	issuer := newTestIssuer(t, "")
+	defer issuer.Close()
+
+	t.Run("without issuer", func(t *testing.T) {
+		p := NewOIDCProvider()
+
+		if !p.DiscoverCached() {
+			t.Fatal("Expected true, got false")
+		}
+	})
+
+	t.Run("with issuer", func(t *testing.T) {
+		p := NewOIDCProvider()
+		p.SetExtra(map[string]any{"issuer": issuer.URL})
+
+		if p.DiscoverCached() {
+			t.Fatal("Expected false for not loaded discovery document")
+		}
+
+		if p.AuthURL() != "" {
+			t.Fatalf("Expected the auth url to remain empty, got %q", p.AuthURL())
+		}
+
+		// wait for the background discovery
+		var loaded bool
+		for i := 0; i < 50 && !loaded; i++ {
+			time.Sleep(20 * time.Millisecond)
+			loaded = p.DiscoverCached()
+		}
+
+		if !loaded {
+			t.Fatal("Expected the discovery document to be loaded in the background")
+		}
+
+		if v := p.AuthURL(); v != issuer.URL+"/authorize" {
+			t.Fatalf("Expected auth url %q, got %q", issuer.URL+"/authorize", v)
+		}
+	})
+}
+
+func TestOIDCFetchAuthUserWithDiscovery(t *testing.T) {
+	issuer := newTestIssuer(t, "")
+	defer issuer.Close()
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":                issuer.URL,
+			"aud":                "test_client",
+			"sub":                "test_sub",
+			"nonce":              "test_nonce",
+			"iat":                time.Now().Unix(),
+			"name":               "test_name",
+			"preferred_username": "test_username",
+			"email":              "test@example.com",
+			"email_verified":     true,
+		}
+	}
+
+	scenarios := []struct {
+		name          string
+		claims        func() jwt.MapClaims
+		key           *rsa.PrivateKey
+		nonce         string
+		userInfo      map[string]any
+		expectError   bool
+		expectedEmail string
+		expectedName  string
+	}{
+		{
+			name:          "valid id_token without userinfo",
+			claims:        validClaims,
+			key:           issuer.key,
+			nonce:         "test_nonce",
+			expectedEmail: "test@example.com",
+			expectedName:  "test_name",
+		},
+		{
+			name:          "valid id_token without nonce check",
+			claims:        validClaims,
+			key:           issuer.key,
+			expectedEmail: "test@example.com",
+			expectedName:  "test_name",
+		},
+		{
+			name:          "valid id_token with userinfo",
+			claims:        validClaims,
+			key:           issuer.key,
+			nonce:         "test_nonce",
+			userInfo:      map[string]any{"sub": "test_sub", "name": "userinfo_name"},
+			expectedEmail: "test@example.com",
+			expectedName:  "userinfo_name",
+		},
+		{
+			name:        "userinfo with different sub",
+			claims:      validClaims,
+			key:         issuer.key,
+			userInfo:    map[string]any{"sub": "other_sub", "name": "userinfo_name"},
+			expectError: true,
+		},
+		{
+			name:        "invalid nonce",
+			claims:      validClaims,
+			key:         issuer.key,
+			nonce:       "other_nonce",
+			expectError: true,
+		},
+		{
+			name: "missing nonce",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				delete(claims, "nonce")
+				return claims
+			},
+			key:         issuer.key,
+			nonce:       "test_nonce",
+			expectError: true,
+		},
+		{
+			name: "invalid iss",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				claims["iss"] = "https://example.com"
+				return claims
+			},
+			key:         issuer.key,
+			expectError: true,
+		},
+		{
+			name: "invalid aud",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				claims["aud"] = "other_client"
+				return claims
+			},
+			key:         issuer.key,
+			expectError: true,
+		},
+		{
+			name: "expired",
+			claims: func() jwt.MapClaims {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-1 * time.Hour).Unix()
+				return claims
+			},
+			key:         issuer.key,
+			expectError: true,
+		},
+		{
+			name:        "invalid signature",
+			claims:      validClaims,
+			key:         otherKey,
+			expectError: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			issuer.userInfo = s.userInfo
+
+			p := NewOIDCProvider()
+			p.SetClientId("test_client")
+			p.SetExtra(map[string]any{"issuer": issuer.URL})
+			p.SetNonce(s.nonce)
+			if err := p.Discover(); err != nil {
+				t.Fatal(err)
+			}
+
+			if s.userInfo == nil {
+				p.SetUserInfoURL("") // use only the id_token claims
+			}
+
+			idToken := issuer.idToken(t, s.claims(), s.key)
+
+			token := (&oauth2.Token{AccessToken: "test"}).WithExtra(map[string]any{"id_token": idToken})
+
+			user, err := p.FetchAuthUser(token)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if user.Id != "test_sub" {
+				t.Fatalf("Expected id %q, got %q", "test_sub", user.Id)
+			}
+
+			if user.Email != s.expectedEmail {
+				t.Fatalf("Expected email %q, got %q", s.expectedEmail, user.Email)
+			}
+
+			if user.Name != s.expectedName {
+				t.Fatalf("Expected name %q, got %q", s.expectedName, user.Name)
+			}
+
+			if user.Username != "test_username" {
+				t.Fatalf("Expected username %q, got %q", "test_username", user.Username)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@
 package cron
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"slices"
@@ -23,7 +24,9 @@ type Cron struct {
 	startTimer *time.Timer
 	tickerDone chan bool
 	jobs       []*Job
+	onRun      func(run *Run)
 	interval   time.Duration
+	tick       time.Duration
 	mux        sync.RWMutex
 }
 
@@ -43,6 +46,9 @@ func New() *Cron {
 
 // SetInterval changes the current cron tick interval
 // (it usually should be >= 1 minute).
+//
+// Note that while there are registered jobs with seconds precision
+// the ticker runs every second regardless of the configured interval.
 func (c *Cron) SetInterval(d time.Duration) {
 	// update interval
 	c.mux.Lock()
@@ -57,6 +63,8 @@ func (c *Cron) SetInterval(d time.Duration) {
 }
 
 // SetTimezone changes the current cron tick timezone.
+//
+// It is used for all jobs without explicit JobOptions.Timezone.
 func (c *Cron) SetTimezone(l *time.Location) {
 	c.mux.Lock()
 	defer c.mux.Unlock()
@@ -64,6 +72,30 @@ func (c *Cron) SetTimezone(l *time.Location) {
 	c.timezone = l
 }
 
+// SetRunListener registers a function that will be called
+// after each finished cron job run (both scheduled and manual).
+//
+// The listener is invoked in the job run goroutine so it is safe
+// to perform blocking operations, like persisting the run result.
+//
+// Pass nil to unset the current listener.
+func (c *Cron) SetRunListener(fn func(run *Run)) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	c.onRun = fn
+}
+
+// RunListener returns the current cron run listener (if any).
+//
+// It could be used to chain the existing listener when registering a new one.
+func (c *Cron) RunListener() func(run *Run) {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	return c.onRun
+}
+
 // MustAdd is similar to Add() but panic on failure.
 func (c *Cron) MustAdd(jobId string, cronExpr string, run func()) {
 	if err := c.Add(jobId, cronExpr, run); err != nil {
@@ -83,13 +115,28 @@ func (c *Cron) Add(jobId string, cronExpr string, fn func()) error {
 		return errors.New("failed to add new cron job: fn must be non-nil function")
 	}
 
+	return c.AddWithOptions(jobId, cronExpr, func(ctx context.Context) error {
+		fn()
+		return nil
+	}, JobOptions{})
+}
+
+// AddWithOptions registers a single cron job with the specified options.
+//
+// It is similar to Add() but the job function receives a context
+// (canceled on timeout) and could return an error that will be
+// reported as part of the job run result.
+func (c *Cron) AddWithOptions(jobId string, cronExpr string, fn func(ctx context.Context) error, options JobOptions) error {
+	if fn == nil {
+		return errors.New("failed to add new cron job: fn must be non-nil function")
+	}
+
 	schedule, err := NewSchedule(cronExpr)
 	if err != nil {
 		return fmt.Errorf("failed to add new cron job: %w", err)
 	}
 
 	c.mux.Lock()
-	defer c.mux.Unlock()
 
 	// remove previous (if any)
 	c.jobs = slices.DeleteFunc(c.jobs, func(j *Job) bool {
@@ -101,31 +148,43 @@ func (c *Cron) Add(jobId string, cronExpr string, fn func()) error {
 		id:       jobId,
 		fn:       fn,
 		schedule: schedule,
+		options:  options,
+		cron:     c,
+		state:    &jobState{},
 	})
 
+	c.mux.Unlock()
+
+	c.syncTicker()
+
 	return nil
 }
 
 // Remove removes a single cron job by its id.
 func (c *Cron) Remove(jobId string) {
 	c.mux.Lock()
-	defer c.mux.Unlock()
 
 	if c.jobs == nil {
+		c.mux.Unlock()
 		return // nothing to remove
 	}
 
 	c.jobs = slices.DeleteFunc(c.jobs, func(j *Job) bool {
 		return j.Id() == jobId
 	})
+
+	c.mux.Unlock()
+
+	c.syncTicker()
 }
 
 // RemoveAll removes all registered cron jobs.
 func (c *Cron) RemoveAll() {
 	c.mux.Lock()
-	defer c.mux.Unlock()
-
 	c.jobs = []*Job{}
+	c.mux.Unlock()
+
+	c.syncTicker()
 }
 
 // Total returns the current total number of registered cron jobs.
@@ -176,15 +235,19 @@ func (c *Cron) Stop() {
 func (c *Cron) Start() {
 	c.Stop()
 
-	// delay the ticker to start at 00 of 1 c.interval duration
+	c.mux.Lock()
+
+	tick := c.tickInterval()
+	c.tick = tick
+
+	// delay the ticker to start at 00 of 1 tick duration
 	now := time.Now()
-	next := now.Add(c.interval).Truncate(c.interval)
+	next := now.Add(tick).Truncate(tick)
 	delay := next.Sub(now)
 
-	c.mux.Lock()
 	c.startTimer = time.AfterFunc(delay, func() {
 		c.mux.Lock()
-		c.ticker = time.NewTicker(c.interval)
+		c.ticker = time.NewTicker(tick)
 		c.mux.Unlock()
 
 		// run immediately at 00
@@ -218,11 +281,63 @@ func (c *Cron) runDue(t time.Time) {
 	c.mux.RLock()
 	defer c.mux.RUnlock()
 
-	moment := NewMoment(t.In(c.timezone))
+	// the ticker was lowered to 1s because of the seconds precision jobs
+	// so the regular minute based schedules are checked only at 00 seconds
+	secondsTick := c.tick < c.interval
+
+	for _, j := range c.jobs {
+		loc := c.timezone
+		if j.options.Timezone != nil {
+			loc = j.options.Timezone
+		}
+
+		jobTime := t.In(loc)
+
+		if secondsTick && !j.schedule.HasSeconds() && jobTime.Second() != 0 {
+			continue
+		}
+
+		if j.schedule.IsDue(NewMoment(jobTime)) {
+			go j.runScheduled()
+		}
+	}
+}
+
+// tickInterval returns the effective ticker interval, aka. the configured
+// interval or 1s if there are registered jobs with seconds precision.
+//
+// Must be called under lock.
+func (c *Cron) tickInterval() time.Duration {
+	if c.interval <= time.Second {
+		return c.interval
+	}
 
 	for _, j := range c.jobs {
-		if j.schedule.IsDue(moment) {
-			go j.Run()
+		if j.schedule.HasSeconds() {
+			return time.Second
 		}
 	}
+
+	return c.interval
+}
+
+// syncTicker restarts the started cron ticker if its effective
+// interval has changed after registering or removing jobs.
+func (c *Cron) syncTicker() {
+	c.mux.RLock()
+	started := c.ticker != nil || c.startTimer != nil
+	changed := c.tick != c.tickInterval()
+	c.mux.RUnlock()
+
+	if started && changed {
+		c.Start()
+	}
+}
+
+// location returns the current cron timezone.
+func (c *Cron) location() *time.Location {
+	c.mux.RLock()
+	defer c.mux.RUnlock()
+
+	return c.timezone
 }
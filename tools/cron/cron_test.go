@@ -1,8 +1,10 @@
 package cron
 
 import (
+	"context"
 	"encoding/json"
 	"slices"
+	"sync"
 	"testing"
 	"time"
 )
@@ -303,3 +305,118 @@ func TestCronStartStop(t *testing.T) {
 		t.Fatalf("Expected %d test2, got %d", expectedCalls, test2)
 	}
 }
+
+func TestCronRunDueTimezone(t *testing.T) {
+	t.Parallel()
+
+	sofia, err := time.LoadLocation("Europe/Sofia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := New()
+
+	calls := make(chan string, 10)
+
+	c.MustAdd("utc", "0 8 * * *", func() { calls <- "utc" })
+
+	err = c.AddWithOptions("sofia", "0 8 * * *", func(ctx context.Context) error {
+		calls <- "sofia"
+		return nil
+	}, JobOptions{Timezone: sofia})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		time     time.Time
+		expected string
+	}{
+		{time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC), "utc"},
+		{time.Date(2024, 1, 1, 6, 0, 0, 0, time.UTC), "sofia"}, // 08:00 EET
+		{time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC), ""},
+	}
+
+	for _, s := range scenarios {
+		c.runDue(s.time)
+
+		var result string
+		select {
+		case result = <-calls:
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		if result != s.expected {
+			t.Fatalf("[%v] Expected %q job to run, got %q", s.time, s.expected, result)
+		}
+	}
+
+	for _, j := range c.Jobs() {
+		expectedLocation := time.UTC
+		if j.Id() == "sofia" {
+			expectedLocation = sofia
+		}
+
+		if j.Location() != expectedLocation {
+			t.Fatalf("Expected job %q location %v, got %v", j.Id(), expectedLocation, j.Location())
+		}
+	}
+}
+
+func TestCronSecondsTick(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	c.MustAdd("minutes", "* * * * *", func() {})
+
+	if v := c.tickInterval(); v != time.Minute {
+		t.Fatalf("Expected tick interval %v, got %v", time.Minute, v)
+	}
+
+	var mu sync.Mutex
+	calls := 0
+	c.MustAdd("seconds", "* * * * * *", func() {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	if v := c.tickInterval(); v != time.Second {
+		t.Fatalf("Expected tick interval %v, got %v", time.Second, v)
+	}
+
+	c.Start()
+	time.Sleep(2500 * time.Millisecond)
+	c.Stop()
+
+	mu.Lock()
+	total := calls
+	mu.Unlock()
+
+	if total < 2 {
+		t.Fatalf("Expected at least 2 seconds job calls, got %d", total)
+	}
+
+	// minute based jobs are due only at 00 seconds with the lowered tick
+	c.tick = time.Second
+	moments := []time.Time{
+		time.Date(2024, 1, 1, 8, 0, 5, 0, time.UTC),
+		time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC),
+	}
+	minuteCalls := make(chan struct{}, 10)
+	c.MustAdd("minutes", "* * * * *", func() { minuteCalls <- struct{}{} })
+	for _, m := range moments {
+		c.runDue(m)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if v := len(minuteCalls); v != 1 {
+		t.Fatalf("Expected 1 minute job call, got %d", v)
+	}
+
+	c.Remove("seconds")
+
+	if v := c.tickInterval(); v != time.Minute {
+		t.Fatalf("Expected tick interval to be restored to %v, got %v", time.Minute, v)
+	}
+}
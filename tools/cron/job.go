@@ -1,12 +1,75 @@
 package cron
 
-import "encoding/json"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// JobOptions defines the optional settings of a single cron job.
+type JobOptions struct {
+	// Timeout specifies the max duration of a single job run.
+	//
+	// Once the timeout elapses the context passed to the job function
+	// is canceled and the run is marked as failed.
+	// Note that the job function is expected to respect the context cancellation.
+	Timeout time.Duration
+
+	// Jitter delays each scheduled run with a random duration
+	// in the [0, Jitter) range (manual runs are not delayed).
+	Jitter time.Duration
+
+	// Timezone specifies the timezone in which the job schedule is evaluated
+	// (if not set, fallbacks to the cron timezone).
+	Timezone *time.Location
+
+	// SkipIfRunning skips the scheduled run if the previous
+	// one hasn't finished yet (manual runs are not skipped).
+	SkipIfRunning bool
+}
+
+// Run holds information about a single executed cron job run.
+type Run struct {
+	// Started is the time when the job function was invoked.
+	Started time.Time
+
+	// Error is the error returned by the job function (if any),
+	// including timeouts and recovered panics.
+	Error error
+
+	// JobId is the id of the executed job.
+	JobId string
+
+	// Duration is the total execution time of the job function.
+	Duration time.Duration
+
+	// Manual indicates whether the run was triggered manually
+	// with Job.Run() instead of by the cron ticker.
+	Manual bool
+
+	// Panicked indicates whether the job function has panicked.
+	Panicked bool
+}
 
 // Job defines a single registered cron job.
 type Job struct {
-	fn       func()
+	fn       func(ctx context.Context) error
 	schedule *Schedule
+	cron     *Cron
+	state    *jobState
 	id       string
+	options  JobOptions
+}
+
+// jobState holds the mutable runs state of a cron job
+// (it is stored as pointer to keep Job safe to copy).
+type jobState struct {
+	lastRun *Run
+	running int
+	mux     sync.RWMutex
 }
 
 // Id returns the cron job id.
@@ -19,11 +82,140 @@ func (j *Job) Expression() string {
 	return j.schedule.rawExpr
 }
 
+// Options returns the cron job options.
+func (j *Job) Options() JobOptions {
+	return j.options
+}
+
+// IsRunning reports whether there is at least one active run of the cron job.
+func (j *Job) IsRunning() bool {
+	if j.state == nil {
+		return false
+	}
+
+	j.state.mux.RLock()
+	defer j.state.mux.RUnlock()
+
+	return j.state.running > 0
+}
+
+// LastRun returns a copy of the last finished cron job run
+// or nil if the job hasn't been executed yet.
+func (j *Job) LastRun() *Run {
+	if j.state == nil {
+		return nil
+	}
+
+	j.state.mux.RLock()
+	defer j.state.mux.RUnlock()
+
+	if j.state.lastRun == nil {
+		return nil
+	}
+
+	clone := *j.state.lastRun
+
+	return &clone
+}
+
+// Location returns the timezone in which the cron job schedule is evaluated,
+// aka. the job Timezone option or the cron timezone as fallback.
+func (j *Job) Location() *time.Location {
+	if j.options.Timezone != nil {
+		return j.options.Timezone
+	}
+
+	if j.cron != nil {
+		return j.cron.location()
+	}
+
+	return time.UTC
+}
+
+// NextRun returns the next scheduled run time of the cron job after the provided one
+// (or zero time if there is no matching time in the next few years).
+func (j *Job) NextRun(after time.Time) time.Time {
+	return j.schedule.Next(after.In(j.Location()))
+}
+
 // Run runs the cron job function.
+//
+// Manual runs are executed synchronously and immediately,
+// ignoring the Jitter and SkipIfRunning job options.
 func (j *Job) Run() {
-	if j.fn != nil {
-		j.fn()
+	j.run(true)
+}
+
+// runScheduled runs the cron job function applying the Jitter
+// and SkipIfRunning job options.
+func (j *Job) runScheduled() {
+	if j.options.Jitter > 0 {
+		time.Sleep(rand.N(j.options.Jitter))
+	}
+
+	j.run(false)
+}
+
+func (j *Job) run(manual bool) {
+	if j.fn == nil {
+		return
+	}
+
+	// note: jobs not created with Cron.Add don't track their runs state
+	if j.state != nil {
+		j.state.mux.Lock()
+		if !manual && j.options.SkipIfRunning && j.state.running > 0 {
+			j.state.mux.Unlock()
+			return
+		}
+		j.state.running++
+		j.state.mux.Unlock()
 	}
+
+	run := &Run{
+		JobId:   j.id,
+		Manual:  manual,
+		Started: time.Now(),
+	}
+
+	run.Panicked, run.Error = j.exec()
+	run.Duration = time.Since(run.Started)
+
+	if j.state != nil {
+		j.state.mux.Lock()
+		j.state.running--
+		j.state.lastRun = run
+		j.state.mux.Unlock()
+	}
+
+	if j.cron != nil {
+		if listener := j.cron.RunListener(); listener != nil {
+			listener(run)
+		}
+	}
+}
+
+func (j *Job) exec() (panicked bool, err error) {
+	ctx := context.Background()
+	if j.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.options.Timeout)
+		defer cancel()
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			panicked = true
+		}
+	}()
+
+	err = j.fn(ctx)
+	if err == nil && ctx.Err() != nil {
+		err = fmt.Errorf("exceeded the %s timeout: %w", j.options.Timeout, ctx.Err())
+	}
+
+	return false, err
 }
 
 // MarshalJSON implements [json.Marshaler] and export the current
@@ -1,8 +1,13 @@
 package cron
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestJobId(t *testing.T) {
@@ -40,7 +45,10 @@ func TestJobRun(t *testing.T) {
 	calls := ""
 
 	j1 := Job{}
-	j2 := Job{fn: func() { calls += "2" }}
+	j2 := Job{fn: func(ctx context.Context) error {
+		calls += "2"
+		return nil
+	}}
 
 	j1.Run()
 	j2.Run()
@@ -51,6 +59,187 @@ func TestJobRun(t *testing.T) {
 	}
 }
 
+func TestJobRunResult(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name        string
+		fn          func(ctx context.Context) error
+		options     JobOptions
+		expectError string
+		expectPanic bool
+	}{
+		{
+			"success",
+			func(ctx context.Context) error { return nil },
+			JobOptions{},
+			"",
+			false,
+		},
+		{
+			"error",
+			func(ctx context.Context) error { return errors.New("test_error") },
+			JobOptions{},
+			"test_error",
+			false,
+		},
+		{
+			"panic",
+			func(ctx context.Context) error { panic("test_panic") },
+			JobOptions{},
+			"panic: test_panic",
+			true,
+		},
+		{
+			"timeout",
+			func(ctx context.Context) error {
+				<-ctx.Done()
+				return nil
+			},
+			JobOptions{Timeout: 10 * time.Millisecond},
+			"exceeded the 10ms timeout",
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			c := New()
+
+			var listenerRun *Run
+			c.SetRunListener(func(run *Run) {
+				listenerRun = run
+			})
+
+			if err := c.AddWithOptions("test", "* * * * *", s.fn, s.options); err != nil {
+				t.Fatal(err)
+			}
+
+			j := c.Jobs()[0]
+
+			if j.LastRun() != nil {
+				t.Fatal("Expected nil last run before the first execution")
+			}
+
+			j.Run()
+
+			run := j.LastRun()
+			if run == nil {
+				t.Fatal("Expected non-nil last run")
+			}
+
+			if listenerRun == nil || listenerRun.Started != run.Started {
+				t.Fatalf("Expected the listener to be called with the last run, got %v", listenerRun)
+			}
+
+			if run.JobId != "test" {
+				t.Fatalf("Expected job id %q, got %q", "test", run.JobId)
+			}
+
+			if !run.Manual {
+				t.Fatal("Expected manual run")
+			}
+
+			if run.Panicked != s.expectPanic {
+				t.Fatalf("Expected panicked %v, got %v", s.expectPanic, run.Panicked)
+			}
+
+			if s.expectError == "" {
+				if run.Error != nil {
+					t.Fatalf("Expected nil error, got %v", run.Error)
+				}
+			} else if run.Error == nil || !strings.Contains(run.Error.Error(), s.expectError) {
+				t.Fatalf("Expected error containing %q, got %v", s.expectError, run.Error)
+			}
+
+			if j.IsRunning() {
+				t.Fatal("Expected the job to be marked as not running")
+			}
+		})
+	}
+}
+
+func TestJobSkipIfRunning(t *testing.T) {
+	t.Parallel()
+
+	for _, skip := range []bool{true, false} {
+		c := New()
+
+		release := make(chan struct{})
+
+		var mu sync.Mutex
+		calls := 0
+
+		err := c.AddWithOptions("test", "* * * * *", func(ctx context.Context) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+
+			<-release
+			return nil
+		}, JobOptions{SkipIfRunning: skip})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		j := c.Jobs()[0]
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.runScheduled()
+		}()
+
+		// wait for the first run to start
+		for !j.IsRunning() {
+			time.Sleep(time.Millisecond)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			j.runScheduled()
+		}()
+
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		expectedCalls := 2
+		if skip {
+			expectedCalls = 1
+		}
+
+		if calls != expectedCalls {
+			t.Fatalf("[skip %v] Expected %d calls, got %d", skip, expectedCalls, calls)
+		}
+	}
+}
+
+func TestJobNextRun(t *testing.T) {
+	t.Parallel()
+
+	c := New()
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetTimezone(tokyo)
+
+	c.MustAdd("test", "0 9 * * *", func() {})
+
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) // 09:00 in Tokyo
+
+	expected := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	next := c.Jobs()[0].NextRun(after)
+	if !next.Equal(expected) {
+		t.Fatalf("Expected next run %v, got %v", expected, next)
+	}
+}
+
 func TestJobMarshalJSON(t *testing.T) {
 	s, err := NewSchedule("1 2 3 4 5")
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 
 // Moment represents a parsed single time moment.
 type Moment struct {
+	Second    int `json:"second"`
 	Minute    int `json:"minute"`
 	Hour      int `json:"hour"`
 	Day       int `json:"day"`
@@ -20,6 +21,7 @@ type Moment struct {
 // NewMoment creates a new Moment from the specified time.
 func NewMoment(t time.Time) *Moment {
 	return &Moment{
+		Second:    t.Second(),
 		Minute:    t.Minute(),
 		Hour:      t.Hour(),
 		Day:       t.Day(),
@@ -30,17 +32,57 @@ func NewMoment(t time.Time) *Moment {
 
 // Schedule stores parsed information for each time component when a cron job should run.
 type Schedule struct {
+	// Seconds is nil for the regular 5 segments expressions
+	// (aka. the schedule doesn't care about the moment seconds).
+	Seconds    map[int]struct{} `json:"seconds,omitempty"`
 	Minutes    map[int]struct{} `json:"minutes"`
 	Hours      map[int]struct{} `json:"hours"`
 	Days       map[int]struct{} `json:"days"`
 	Months     map[int]struct{} `json:"months"`
 	DaysOfWeek map[int]struct{} `json:"daysOfWeek"`
 
+	// Every is the interval of the "@every <duration>" expressions.
+	//
+	// The intervals are aligned to the start of the day,
+	// aka. "@every 15m" is due at 00:00, 00:15, 00:30, etc.
+	// (the whole minutes intervals ignore the moment seconds).
+	Every time.Duration `json:"every,omitempty"`
+
 	rawExpr string
 }
 
+// HasSeconds reports whether the Schedule requires seconds precision
+// (aka. it could be due at a moment with non-zero seconds).
+func (s *Schedule) HasSeconds() bool {
+	if s.Every > 0 {
+		return s.Every%time.Minute != 0
+	}
+
+	return s.Seconds != nil
+}
+
 // IsDue checks whether the provided Moment satisfies the current Schedule.
 func (s *Schedule) IsDue(m *Moment) bool {
+	if s.Every > 0 {
+		// the whole minutes intervals ignore the moment seconds similar to
+		// the 5 segments expressions (e.g. in case of a delayed minute tick)
+		if !s.HasSeconds() {
+			minuteOfDay := m.Hour*60 + m.Minute
+
+			return minuteOfDay%int(s.Every/time.Minute) == 0
+		}
+
+		secondOfDay := m.Hour*3600 + m.Minute*60 + m.Second
+
+		return secondOfDay%int(s.Every/time.Second) == 0
+	}
+
+	if s.Seconds != nil {
+		if _, ok := s.Seconds[m.Second]; !ok {
+			return false
+		}
+	}
+
 	if _, ok := s.Minutes[m.Minute]; !ok {
 		return false
 	}
@@ -64,6 +106,83 @@ func (s *Schedule) IsDue(m *Moment) bool {
 	return true
 }
 
+// nextSearchYears is the max number of years after which Schedule.Next gives up.
+const nextSearchYears = 5
+
+const secondsInDay = 24 * 60 * 60
+
+// Next returns the first moment after t that satisfies the current Schedule.
+//
+// The result is in the same location as t.
+// Zero time is returned if there is no matching time in the next 5 years
+// (eg. for impossible schedules like "0 0 30 2 *").
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	if s.Every > 0 {
+		return s.nextEvery(t)
+	}
+
+	var next time.Time
+	if s.Seconds != nil {
+		next = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc).Add(time.Second)
+	} else {
+		next = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc).Add(time.Minute)
+	}
+
+	limit := next.AddDate(nextSearchYears, 0, 0)
+
+	for next.Before(limit) {
+		if _, ok := s.Months[int(next.Month())]; !ok {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		_, dayOk := s.Days[next.Day()]
+		_, weekdayOk := s.DaysOfWeek[int(next.Weekday())]
+		if !dayOk || !weekdayOk {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if _, ok := s.Hours[next.Hour()]; !ok {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if _, ok := s.Minutes[next.Minute()]; !ok {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour(), next.Minute()+1, 0, 0, loc)
+			continue
+		}
+
+		if s.Seconds != nil {
+			if _, ok := s.Seconds[next.Second()]; !ok {
+				next = next.Add(time.Second)
+				continue
+			}
+		}
+
+		return next
+	}
+
+	return time.Time{}
+}
+
+// nextEvery returns the next day aligned "@every" interval moment after t.
+func (s *Schedule) nextEvery(t time.Time) time.Time {
+	every := int(s.Every / time.Second)
+
+	secondOfDay := t.Hour()*3600 + t.Minute()*60 + t.Second()
+
+	nextSecond := (secondOfDay/every + 1) * every
+	if nextSecond >= secondsInDay {
+		// start of the next day
+		return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+	}
+
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, nextSecond, 0, t.Location())
+}
+
 var macros = map[string]string{
 	"@yearly":   "0 0 1 1 *",
 	"@annually": "0 0 1 1 *",
@@ -79,6 +198,9 @@ var macros = map[string]string{
 // A cron expression could be a macro OR 5 segments separated by space,
 // representing: minute, hour, day of the month, month and day of the week.
 //
+// For seconds precision the expression could have an optional 6th leading
+// segment, aka.: second, minute, hour, day of the month, month and day of the week.
+//
 // The following segment formats are supported:
 //   - wildcard: *
 //   - range:    1-30
@@ -91,14 +213,34 @@ var macros = map[string]string{
 //   - @weekly
 //   - @daily (or @midnight)
 //   - @hourly
+//   - @every <duration> (eg. "@every 30s", "@every 1h30m")
+//
+// The "@every" duration must be in whole seconds between 1s and 24h
+// and it is aligned to the start of the day (so it is recommended to be
+// a divisor of 24h, otherwise the last interval of the day will be shorter).
 func NewSchedule(cronExpr string) (*Schedule, error) {
+	if every, ok := strings.CutPrefix(cronExpr, "@every "); ok {
+		return newEverySchedule(cronExpr, strings.TrimSpace(every))
+	}
+
 	if v, ok := macros[cronExpr]; ok {
 		cronExpr = v
 	}
 
 	segments := strings.Split(cronExpr, " ")
-	if len(segments) != 5 {
-		return nil, errors.New("invalid cron expression - must be a valid macro or to have exactly 5 space separated segments")
+	if len(segments) != 5 && len(segments) != 6 {
+		return nil, errors.New("invalid cron expression - must be a valid macro or to have exactly 5 or 6 space separated segments")
+	}
+
+	var seconds map[int]struct{}
+	if len(segments) == 6 {
+		var err error
+		seconds, err = parseCronSegment(segments[0], 0, 59)
+		if err != nil {
+			return nil, err
+		}
+
+		segments = segments[1:]
 	}
 
 	minutes, err := parseCronSegment(segments[0], 0, 59)
@@ -127,6 +269,7 @@ func NewSchedule(cronExpr string) (*Schedule, error) {
 	}
 
 	return &Schedule{
+		Seconds:    seconds,
 		Minutes:    minutes,
 		Hours:      hours,
 		Days:       days,
@@ -136,6 +279,27 @@ func NewSchedule(cronExpr string) (*Schedule, error) {
 	}, nil
 }
 
+// newEverySchedule creates a new "@every <duration>" interval Schedule.
+func newEverySchedule(cronExpr string, rawDuration string) (*Schedule, error) {
+	every, err := time.ParseDuration(rawDuration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid @every duration: %w", err)
+	}
+
+	if every < time.Second || every > 24*time.Hour {
+		return nil, errors.New("invalid @every duration - must be between 1s and 24h")
+	}
+
+	if every%time.Second != 0 {
+		return nil, errors.New("invalid @every duration - must be in whole seconds")
+	}
+
+	return &Schedule{
+		Every:   every,
+		rawExpr: cronExpr,
+	}, nil
+}
+
 // parseCronSegment parses a single cron expression segment and
 // returns its time schedule slots.
 func parseCronSegment(segment string, min int, max int) (map[int]struct{}, error) {
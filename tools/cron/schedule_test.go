@@ -19,6 +19,10 @@ func TestNewMoment(t *testing.T) {
 
 	m := cron.NewMoment(date)
 
+	if m.Second != 0 {
+		t.Fatalf("Expected m.Second %d, got %d", 0, m.Second)
+	}
+
 	if m.Minute != 20 {
 		t.Fatalf("Expected m.Minute %d, got %d", 20, m.Minute)
 	}
@@ -59,7 +63,7 @@ func TestNewSchedule(t *testing.T) {
 			"",
 		},
 		{
-			"* * * * * *",
+			"* * * * * * *",
 			true,
 			"",
 		},
@@ -214,6 +218,65 @@ func TestNewSchedule(t *testing.T) {
 			`{"minutes":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"32":{},"33":{},"34":{},"35":{},"36":{},"37":{},"38":{},"39":{},"4":{},"40":{},"41":{},"42":{},"43":{},"44":{},"45":{},"46":{},"47":{},"48":{},"49":{},"5":{},"50":{},"51":{},"52":{},"53":{},"54":{},"55":{},"56":{},"57":{},"58":{},"59":{},"6":{},"7":{},"8":{},"9":{}},"hours":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"days":{"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"months":{"1":{},"10":{},"11":{},"12":{},"2":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"daysOfWeek":{"1":{},"2":{},"4":{}}}`,
 		},
 
+		// seconds segment
+		{
+			"-1 * * * * *",
+			true,
+			"",
+		},
+		{
+			"60 * * * * *",
+			true,
+			"",
+		},
+		{
+			"*/15 * * * * *",
+			false,
+			`{"seconds":{"0":{},"15":{},"30":{},"45":{}},"minutes":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"32":{},"33":{},"34":{},"35":{},"36":{},"37":{},"38":{},"39":{},"4":{},"40":{},"41":{},"42":{},"43":{},"44":{},"45":{},"46":{},"47":{},"48":{},"49":{},"5":{},"50":{},"51":{},"52":{},"53":{},"54":{},"55":{},"56":{},"57":{},"58":{},"59":{},"6":{},"7":{},"8":{},"9":{}},"hours":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"days":{"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"months":{"1":{},"10":{},"11":{},"12":{},"2":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"daysOfWeek":{"0":{},"1":{},"2":{},"3":{},"4":{},"5":{},"6":{}}}`,
+		},
+		{
+			"0,30 5 * * * *",
+			false,
+			`{"seconds":{"0":{},"30":{}},"minutes":{"5":{}},"hours":{"0":{},"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"days":{"1":{},"10":{},"11":{},"12":{},"13":{},"14":{},"15":{},"16":{},"17":{},"18":{},"19":{},"2":{},"20":{},"21":{},"22":{},"23":{},"24":{},"25":{},"26":{},"27":{},"28":{},"29":{},"3":{},"30":{},"31":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"months":{"1":{},"10":{},"11":{},"12":{},"2":{},"3":{},"4":{},"5":{},"6":{},"7":{},"8":{},"9":{}},"daysOfWeek":{"0":{},"1":{},"2":{},"3":{},"4":{},"5":{},"6":{}}}`,
+		},
+
+		// every
+		{
+			"@every",
+			true,
+			"",
+		},
+		{
+			"@every invalid",
+			true,
+			"",
+		},
+		{
+			"@every 500ms",
+			true,
+			"",
+		},
+		{
+			"@every 1500ms",
+			true,
+			"",
+		},
+		{
+			"@every 25h",
+			true,
+			"",
+		},
+		{
+			"@every 30s",
+			false,
+			`{"minutes":null,"hours":null,"days":null,"months":null,"daysOfWeek":null,"every":30000000000}`,
+		},
+		{
+			"@every 1h30m",
+			false,
+			`{"minutes":null,"hours":null,"days":null,"months":null,"daysOfWeek":null,"every":5400000000000}`,
+		},
+
 		// macros
 		{
 			"@yearly",
@@ -390,6 +453,118 @@ func TestScheduleIsDue(t *testing.T) {
 			},
 			true,
 		},
+
+		// seconds
+		{
+			"*/15 * * * * *",
+			&cron.Moment{
+				Second:    10,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			false,
+		},
+		{
+			"*/15 * * * * *",
+			&cron.Moment{
+				Second:    45,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
+		{
+			// 5 segments expression ignores the seconds
+			"* * * * *",
+			&cron.Moment{
+				Second:    10,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
+
+		// every
+		{
+			"@every 30s",
+			&cron.Moment{
+				Second:    10,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			false,
+		},
+		{
+			"@every 30s",
+			&cron.Moment{
+				Second:    30,
+				Minute:    1,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
+		{
+			"@every 1h30m",
+			&cron.Moment{
+				Minute:    0,
+				Hour:      2,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			false,
+		},
+		{
+			"@every 1h30m",
+			&cron.Moment{
+				Minute:    0,
+				Hour:      3,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
+		{
+			// whole minutes interval ignores the seconds
+			"@every 5m",
+			&cron.Moment{
+				Second:    10,
+				Minute:    15,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			true,
+		},
+		{
+			"@every 5m",
+			&cron.Moment{
+				Second:    0,
+				Minute:    16,
+				Hour:      1,
+				Day:       1,
+				Month:     1,
+				DayOfWeek: 1,
+			},
+			false,
+		},
 	}
 
 	for i, s := range scenarios {
@@ -407,3 +582,95 @@ func TestScheduleIsDue(t *testing.T) {
 		})
 	}
 }
+
+func TestScheduleNext(t *testing.T) {
+	t.Parallel()
+
+	sofia, err := time.LoadLocation("Europe/Sofia")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		cronExpr string
+		after    time.Time
+		expected time.Time
+	}{
+		{
+			"* * * * *",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 21, 0, 0, time.UTC),
+		},
+		{
+			"*/15 * * * *",
+			time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+		},
+		{
+			"0 */6 * * *",
+			time.Date(2024, 1, 1, 19, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"@yearly",
+			time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC),
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"30 8 * * 1",
+			time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), // Wednesday
+			time.Date(2024, 1, 8, 8, 30, 0, 0, time.UTC),
+		},
+		{
+			"0 0 29 2 *",
+			time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			"0 8 * * *",
+			time.Date(2024, 1, 1, 10, 0, 0, 0, sofia),
+			time.Date(2024, 1, 2, 8, 0, 0, 0, sofia),
+		},
+		{
+			"*/20 * * * * *",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 20, 40, 0, time.UTC),
+		},
+		{
+			"0 0 8 * * *",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			"@every 45s",
+			time.Date(2024, 1, 1, 10, 20, 30, 0, time.UTC),
+			time.Date(2024, 1, 1, 10, 21, 0, 0, time.UTC),
+		},
+		{
+			"@every 5h",
+			time.Date(2024, 1, 1, 21, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			// impossible date
+			"0 0 30 2 *",
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Time{},
+		},
+	}
+
+	for i, s := range scenarios {
+		t.Run(fmt.Sprintf("%d-%s", i, s.cronExpr), func(t *testing.T) {
+			schedule, err := cron.NewSchedule(s.cronExpr)
+			if err != nil {
+				t.Fatalf("Unexpected cron error: %v", err)
+			}
+
+			result := schedule.Next(s.after)
+
+			if !result.Equal(s.expected) {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
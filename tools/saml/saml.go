@@ -0,0 +1,402 @@
+// Package saml implements a minimal SAML 2.0 Web Browser SSO service provider
+// (HTTP-Redirect binding for the AuthnRequest and HTTP-POST binding for the Response).
+//
+// The responses must be signed (either the Response or the Assertion element)
+// with RSA SHA256/SHA512 and the exclusive XML canonicalization.
+// Encrypted assertions are not supported.
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// SAML 2.0 namespaces.
+const (
+	NSAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	NSProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	NSMetadata  = "urn:oasis:names:tc:SAML:2.0:metadata"
+)
+
+// Common SAML 2.0 identifiers.
+const (
+	BindingHTTPPost           = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	BindingHTTPRedirect       = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	StatusSuccess             = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	SubjectConfirmationBearer = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	NameIdFormatEmail         = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+)
+
+// DefaultClockSkew is the default allowed clock difference between the SP and the IdP.
+const DefaultClockSkew = 3 * time.Minute
+
+// ServiceProvider defines the SAML service provider (SP) and its trusted identity provider (IdP) settings.
+type ServiceProvider struct {
+	// EntityId is the unique SP identifier.
+	EntityId string
+
+	// ACSURL is the SP Assertion Consumer Service url (HTTP-POST binding).
+	ACSURL string
+
+	// IdPEntityId is the expected IdP assertions issuer.
+	IdPEntityId string
+
+	// IdPSSOURL is the IdP single sign-on service url (HTTP-Redirect binding).
+	IdPSSOURL string
+
+	// IdPCertificate is the IdP signing certificate.
+	IdPCertificate *x509.Certificate
+
+	// ClockSkew is the allowed clock difference between the SP and the IdP
+	// (default to [DefaultClockSkew]).
+	ClockSkew time.Duration
+}
+
+// Assertion represents the verified SAML assertion data.
+type Assertion struct {
+	// Id is the unique assertion identifier (could be used for replay protection).
+	Id string
+
+	// NameId is the subject name identifier.
+	NameId string
+
+	// NameIdFormat is the subject name identifier format (if any).
+	NameIdFormat string
+
+	// SessionIndex is the IdP session index (if any).
+	SessionIndex string
+
+	// ExpiresAt is the time after which the assertion must not be accepted.
+	ExpiresAt time.Time
+
+	// Attributes is the list of the assertion attributes values grouped by
+	// their Name (and FriendlyName if available).
+	Attributes map[string][]string
+}
+
+// Attribute returns the first value of the specified assertion attribute (if any).
+func (a *Assertion) Attribute(name string) string {
+	if values := a.Attributes[name]; len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// ParseCertificate parses a PEM or a plain base64 DER encoded X.509 certificate
+// (the format used in the SAML metadata documents).
+func ParseCertificate(value string) (*x509.Certificate, error) {
+	value = strings.TrimSpace(value)
+
+	if block, _ := pem.Decode([]byte(value)); block != nil {
+		return x509.ParseCertificate(block.Bytes)
+	}
+
+	der, err := decodeBase64(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// NewRequestId generates a new random AuthnRequest identifier.
+func NewRequestId() string {
+	// the id must be a valid xsd:ID (aka. it must not start with a digit)
+	return "_" + security.RandomStringWithAlphabet(40, "0123456789abcdef")
+}
+
+// AuthnRequestURL builds the IdP single sign-on url with a deflated and
+// base64 encoded AuthnRequest (HTTP-Redirect binding).
+func (sp *ServiceProvider) AuthnRequestURL(requestId string, relayState string) (string, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(`<samlp:AuthnRequest xmlns:samlp="` + NSProtocol + `" xmlns:saml="` + NSAssertion + `"`)
+	buf.WriteString(` ID="` + escapeXML(requestId) + `"`)
+	buf.WriteString(` Version="2.0"`)
+	buf.WriteString(` IssueInstant="` + time.Now().UTC().Format(time.RFC3339) + `"`)
+	buf.WriteString(` Destination="` + escapeXML(sp.IdPSSOURL) + `"`)
+	buf.WriteString(` ProtocolBinding="` + BindingHTTPPost + `"`)
+	buf.WriteString(` AssertionConsumerServiceURL="` + escapeXML(sp.ACSURL) + `">`)
+	buf.WriteString(`<saml:Issuer>` + escapeXML(sp.EntityId) + `</saml:Issuer>`)
+	buf.WriteString(`<samlp:NameIDPolicy AllowCreate="true"/>`)
+	buf.WriteString(`</samlp:AuthnRequest>`)
+
+	var deflated bytes.Buffer
+
+	w, err := flate.NewWriter(&deflated, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	ssoURL, err := url.Parse(sp.IdPSSOURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(deflated.Bytes()))
+	if relayState != "" {
+		query.Set("RelayState", relayState)
+	}
+	ssoURL.RawQuery = query.Encode()
+
+	return ssoURL.String(), nil
+}
+
+// Metadata returns the SP metadata XML document.
+func (sp *ServiceProvider) Metadata() []byte {
+	var buf bytes.Buffer
+
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<md:EntityDescriptor xmlns:md="` + NSMetadata + `" entityID="` + escapeXML(sp.EntityId) + `">`)
+	buf.WriteString(`<md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + NSProtocol + `">`)
+	buf.WriteString(`<md:AssertionConsumerService Binding="` + BindingHTTPPost + `" Location="` + escapeXML(sp.ACSURL) + `" index="0" isDefault="true"/>`)
+	buf.WriteString(`</md:SPSSODescriptor>`)
+	buf.WriteString(`</md:EntityDescriptor>`)
+
+	return buf.Bytes()
+}
+
+// ParseResponse verifies and parses the base64 encoded SAMLResponse
+// form value that was issued for the specified AuthnRequest id.
+func (sp *ServiceProvider) ParseResponse(encodedResponse string, requestId string) (*Assertion, error) {
+	if sp.IdPCertificate == nil {
+		return nil, errors.New("missing IdP certificate")
+	}
+
+	if requestId == "" {
+		return nil, errors.New("missing request id")
+	}
+
+	raw, err := decodeBase64(encodedResponse)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse encoding: %w", err)
+	}
+
+	response, err := parseXML(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SAMLResponse: %w", err)
+	}
+
+	if !response.is(NSProtocol, "Response") {
+		return nil, errors.New("the document is not a SAML Response")
+	}
+
+	// prevent signature wrapping attacks that rely on duplicated ids
+	ids := map[string]struct{}{}
+	var hasDuplicatedIds bool
+	response.walk(func(e *element) {
+		if id := e.attr("ID"); id != "" {
+			if _, ok := ids[id]; ok {
+				hasDuplicatedIds = true
+			}
+			ids[id] = struct{}{}
+		}
+	})
+	if hasDuplicatedIds {
+		return nil, errors.New("duplicated element ids")
+	}
+
+	if response.attr("Version") != "2.0" {
+		return nil, errors.New("unsupported SAML version")
+	}
+
+	if destination := response.attr("Destination"); destination != "" && destination != sp.ACSURL {
+		return nil, fmt.Errorf("invalid Response Destination %q", destination)
+	}
+
+	if response.attr("InResponseTo") != requestId {
+		return nil, errors.New("the Response InResponseTo doesn't match with the request id")
+	}
+
+	if issuer := response.child(NSAssertion, "Issuer"); issuer != nil && issuer.text() != sp.IdPEntityId {
+		return nil, fmt.Errorf("invalid Response Issuer %q", issuer.text())
+	}
+
+	var statusCode string
+	if status := response.child(NSProtocol, "Status"); status != nil {
+		if code := status.child(NSProtocol, "StatusCode"); code != nil {
+			statusCode = code.attr("Value")
+		}
+	}
+	if statusCode != StatusSuccess {
+		return nil, fmt.Errorf("unsuccessful Response status %q", statusCode)
+	}
+
+	if len(response.childElements(NSAssertion, "EncryptedAssertion")) > 0 {
+		return nil, errors.New("encrypted assertions are not supported")
+	}
+
+	assertions := response.childElements(NSAssertion, "Assertion")
+	if len(assertions) != 1 {
+		return nil, errors.New("exactly one Assertion is required")
+	}
+	assertion := assertions[0]
+
+	// verify the signatures
+	//
+	// note: the Assertion is a direct child of the Response so it
+	// is covered by the Response signature (if any)
+	// ---
+	responseErr := verifySignature(response, sp.IdPCertificate)
+	if responseErr != nil && !errors.Is(responseErr, errMissingSignature) {
+		return nil, fmt.Errorf("invalid Response signature: %w", responseErr)
+	}
+
+	assertionErr := verifySignature(assertion, sp.IdPCertificate)
+	if assertionErr != nil && !errors.Is(assertionErr, errMissingSignature) {
+		return nil, fmt.Errorf("invalid Assertion signature: %w", assertionErr)
+	}
+
+	if responseErr != nil && assertionErr != nil {
+		return nil, errors.New("either the Response or the Assertion must be signed")
+	}
+
+	return sp.parseAssertion(assertion, requestId)
+}
+
+func (sp *ServiceProvider) parseAssertion(assertion *element, requestId string) (*Assertion, error) {
+	now := time.Now()
+
+	skew := sp.ClockSkew
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+
+	result := &Assertion{
+		Id:         assertion.attr("ID"),
+		Attributes: map[string][]string{},
+	}
+
+	if result.Id == "" {
+		return nil, errors.New("missing Assertion ID")
+	}
+
+	issuer := assertion.child(NSAssertion, "Issuer")
+	if issuer == nil || issuer.text() != sp.IdPEntityId {
+		return nil, errors.New("invalid or missing Assertion Issuer")
+	}
+
+	// subject
+	// ---
+	subject := assertion.child(NSAssertion, "Subject")
+	if subject == nil {
+		return nil, errors.New("missing Assertion Subject")
+	}
+
+	nameId := subject.child(NSAssertion, "NameID")
+	if nameId == nil || nameId.text() == "" {
+		return nil, errors.New("missing Subject NameID")
+	}
+	result.NameId = nameId.text()
+	result.NameIdFormat = nameId.attr("Format")
+
+	// at least one valid bearer subject confirmation is required
+	for _, confirmation := range subject.childElements(NSAssertion, "SubjectConfirmation") {
+		if confirmation.attr("Method") != SubjectConfirmationBearer {
+			continue
+		}
+
+		data := confirmation.child(NSAssertion, "SubjectConfirmationData")
+		if data == nil ||
+			data.attr("Recipient") != sp.ACSURL ||
+			(data.attr("InResponseTo") != "" && data.attr("InResponseTo") != requestId) {
+			continue
+		}
+
+		notOnOrAfter, err := time.Parse(time.RFC3339, data.attr("NotOnOrAfter"))
+		if err != nil || !now.Add(-skew).Before(notOnOrAfter) {
+			continue
+		}
+
+		result.ExpiresAt = notOnOrAfter
+		break
+	}
+	if result.ExpiresAt.IsZero() {
+		return nil, errors.New("missing or invalid bearer SubjectConfirmation")
+	}
+
+	// conditions
+	// ---
+	if conditions := assertion.child(NSAssertion, "Conditions"); conditions != nil {
+		if v := conditions.attr("NotBefore"); v != "" {
+			notBefore, err := time.Parse(time.RFC3339, v)
+			if err != nil || now.Add(skew).Before(notBefore) {
+				return nil, errors.New("the Assertion is not yet valid")
+			}
+		}
+
+		if v := conditions.attr("NotOnOrAfter"); v != "" {
+			notOnOrAfter, err := time.Parse(time.RFC3339, v)
+			if err != nil || !now.Add(-skew).Before(notOnOrAfter) {
+				return nil, errors.New("the Assertion has expired")
+			}
+		}
+
+		for _, restriction := range conditions.childElements(NSAssertion, "AudienceRestriction") {
+			var found bool
+			for _, audience := range restriction.childElements(NSAssertion, "Audience") {
+				if audience.text() == sp.EntityId {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, errors.New("the Assertion is not intended for this service provider")
+			}
+		}
+	}
+
+	// authn statement
+	// ---
+	if authn := assertion.child(NSAssertion, "AuthnStatement"); authn != nil {
+		result.SessionIndex = authn.attr("SessionIndex")
+	}
+
+	// attributes
+	// ---
+	for _, statement := range assertion.childElements(NSAssertion, "AttributeStatement") {
+		for _, attribute := range statement.childElements(NSAssertion, "Attribute") {
+			var values []string
+			for _, v := range attribute.childElements(NSAssertion, "AttributeValue") {
+				values = append(values, v.text())
+			}
+
+			if name := attribute.attr("Name"); name != "" {
+				result.Attributes[name] = append(result.Attributes[name], values...)
+			}
+
+			if name := attribute.attr("FriendlyName"); name != "" && name != attribute.attr("Name") {
+				result.Attributes[name] = append(result.Attributes[name], values...)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+
+	_ = xml.EscapeText(&buf, []byte(s))
+
+	return buf.String()
+}
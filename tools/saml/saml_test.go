@@ -0,0 +1,531 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testRequestId   = "_request123"
+	testACSURL      = "https://sp.example.com/acs"
+	testSPEntityId  = "https://sp.example.com/metadata"
+	testIdPEntityId = "https://idp.example.com"
+)
+
+func newTestCertificate(t testing.TB) (*rsa.PrivateKey, *x509.Certificate) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-1 * time.Hour),
+		NotAfter:     time.Now().Add(1 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, cert
+}
+
+// signElement replaces the "<!--SIG:{id}-->" placeholder with an
+// enveloped signature of the element with the specified id.
+func signElement(t testing.TB, doc string, id string, key *rsa.PrivateKey) string {
+	root, err := parseXML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var target *element
+	root.walk(func(e *element) {
+		if e.attr("ID") == id {
+			target = e
+		}
+	})
+	if target == nil {
+		t.Fatalf("Missing element with id %q", id)
+	}
+
+	digest := sha256.Sum256(target.canonicalize(nil, nil))
+
+	signedInfo := `<ds:SignedInfo>` +
+		`<ds:CanonicalizationMethod Algorithm="` + AlgExcC14N + `"/>` +
+		`<ds:SignatureMethod Algorithm="` + AlgRSASHA256 + `"/>` +
+		`<ds:Reference URI="#` + id + `">` +
+		`<ds:Transforms>` +
+		`<ds:Transform Algorithm="` + AlgEnvelopedSignature + `"/>` +
+		`<ds:Transform Algorithm="` + AlgExcC14N + `"/>` +
+		`</ds:Transforms>` +
+		`<ds:DigestMethod Algorithm="` + AlgSHA256 + `"/>` +
+		`<ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>` +
+		`</ds:Reference>` +
+		`</ds:SignedInfo>`
+
+	signature := `<ds:Signature xmlns:ds="` + nsDSig + `">` + signedInfo + `<ds:SignatureValue></ds:SignatureValue></ds:Signature>`
+
+	sigRoot, err := parseXML([]byte(signature))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hashed := sha256.Sum256(sigRoot.child(nsDSig, "SignedInfo").canonicalize(nil, nil))
+
+	sigValue, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signature = strings.Replace(
+		signature,
+		`<ds:SignatureValue></ds:SignatureValue>`,
+		`<ds:SignatureValue>`+base64.StdEncoding.EncodeToString(sigValue)+`</ds:SignatureValue>`,
+		1,
+	)
+
+	return strings.Replace(doc, "<!--SIG:"+id+"-->", signature, 1)
+}
+
+type testResponseOptions struct {
+	inResponseTo string
+	destination  string
+	issuer       string
+	status       string
+	audience     string
+	recipient    string
+	notOnOrAfter time.Time
+	extra        string // extra elements appended to the Response
+}
+
+func testResponse(opts testResponseOptions) string {
+	if opts.inResponseTo == "" {
+		opts.inResponseTo = testRequestId
+	}
+	if opts.destination == "" {
+		opts.destination = testACSURL
+	}
+	if opts.issuer == "" {
+		opts.issuer = testIdPEntityId
+	}
+	if opts.status == "" {
+		opts.status = StatusSuccess
+	}
+	if opts.audience == "" {
+		opts.audience = testSPEntityId
+	}
+	if opts.recipient == "" {
+		opts.recipient = testACSURL
+	}
+	if opts.notOnOrAfter.IsZero() {
+		opts.notOnOrAfter = time.Now().Add(5 * time.Minute)
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	notOnOrAfter := opts.notOnOrAfter.UTC().Format(time.RFC3339)
+
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response1" Version="2.0" IssueInstant="` + now + `" Destination="` + opts.destination + `" InResponseTo="` + opts.inResponseTo + `">
+	<saml:Issuer>` + opts.issuer + `</saml:Issuer>
+	<!--SIG:_response1-->
+	<samlp:Status><samlp:StatusCode Value="` + opts.status + `"/></samlp:Status>
+	<saml:Assertion xmlns:xs="http://www.w3.org/2001/XMLSchema" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" ID="_assertion1" Version="2.0" IssueInstant="` + now + `">
+		<saml:Issuer>` + opts.issuer + `</saml:Issuer>
+		<!--SIG:_assertion1-->
+		<saml:Subject>
+			<saml:NameID Format="` + NameIdFormatEmail + `">test@example.com</saml:NameID>
+			<saml:SubjectConfirmation Method="` + SubjectConfirmationBearer + `">
+				<saml:SubjectConfirmationData NotOnOrAfter="` + notOnOrAfter + `" Recipient="` + opts.recipient + `" InResponseTo="` + opts.inResponseTo + `"/>
+			</saml:SubjectConfirmation>
+		</saml:Subject>
+		<saml:Conditions NotBefore="` + now + `" NotOnOrAfter="` + notOnOrAfter + `">
+			<saml:AudienceRestriction><saml:Audience>` + opts.audience + `</saml:Audience></saml:AudienceRestriction>
+		</saml:Conditions>
+		<saml:AuthnStatement AuthnInstant="` + now + `" SessionIndex="_session1"/>
+		<saml:AttributeStatement>
+			<saml:Attribute Name="urn:oid:2.5.4.42" FriendlyName="givenName"><saml:AttributeValue xsi:type="xs:string">John &amp; Co</saml:AttributeValue></saml:Attribute>
+			<saml:Attribute Name="groups"><saml:AttributeValue>a</saml:AttributeValue><saml:AttributeValue>b</saml:AttributeValue></saml:Attribute>
+		</saml:AttributeStatement>
+	</saml:Assertion>` + opts.extra + `
+</samlp:Response>`
+}
+
+func encodeResponse(doc string) string {
+	return base64.StdEncoding.EncodeToString([]byte(doc))
+}
+
+func TestParseCertificate(t *testing.T) {
+	_, cert := newTestCertificate(t)
+
+	pemCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	base64Cert := base64.StdEncoding.EncodeToString(cert.Raw)
+
+	for _, v := range []string{pemCert, base64Cert, "\n" + base64Cert[:10] + "\n  " + base64Cert[10:]} {
+		parsed, err := ParseCertificate(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !parsed.Equal(cert) {
+			t.Fatal("Expected the parsed certificate to match")
+		}
+	}
+
+	if _, err := ParseCertificate("invalid"); err == nil {
+		t.Fatal("Expected error for invalid certificate")
+	}
+}
+
+func TestNewRequestId(t *testing.T) {
+	a := NewRequestId()
+	b := NewRequestId()
+
+	if a == b {
+		t.Fatal("Expected unique request ids")
+	}
+
+	if !strings.HasPrefix(a, "_") || len(a) != 41 {
+		t.Fatalf("Expected _ prefixed 41 characters id, got %q", a)
+	}
+}
+
+func TestAuthnRequestURL(t *testing.T) {
+	sp := &ServiceProvider{
+		EntityId:  testSPEntityId,
+		ACSURL:    testACSURL,
+		IdPSSOURL: "https://idp.example.com/sso?tenant=1",
+	}
+
+	result, err := sp.AuthnRequestURL(testRequestId, "test_relay")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u.Host != "idp.example.com" || u.Path != "/sso" || u.Query().Get("tenant") != "1" {
+		t.Fatalf("Unexpected SSO url %q", result)
+	}
+
+	if v := u.Query().Get("RelayState"); v != "test_relay" {
+		t.Fatalf("Expected RelayState %q, got %q", "test_relay", v)
+	}
+
+	deflated, err := base64.StdEncoding.DecodeString(u.Query().Get("SAMLRequest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := io.ReadAll(flate.NewReader(bytes.NewReader(deflated)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := parseXML(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !request.is(NSProtocol, "AuthnRequest") {
+		t.Fatalf("Expected AuthnRequest, got %s", raw)
+	}
+
+	expectedAttrs := map[string]string{
+		"ID":                          testRequestId,
+		"Version":                     "2.0",
+		"Destination":                 sp.IdPSSOURL,
+		"ProtocolBinding":             BindingHTTPPost,
+		"AssertionConsumerServiceURL": testACSURL,
+	}
+	for k, v := range expectedAttrs {
+		if request.attr(k) != v {
+			t.Fatalf("Expected %s %q, got %q", k, v, request.attr(k))
+		}
+	}
+
+	if issuer := request.child(NSAssertion, "Issuer"); issuer == nil || issuer.text() != testSPEntityId {
+		t.Fatalf("Expected Issuer %q", testSPEntityId)
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	sp := &ServiceProvider{
+		EntityId: testSPEntityId + "?a=1&b=2",
+		ACSURL:   testACSURL,
+	}
+
+	root, err := parseXML(sp.Metadata())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !root.is(NSMetadata, "EntityDescriptor") || root.attr("entityID") != sp.EntityId {
+		t.Fatalf("Invalid EntityDescriptor %s", sp.Metadata())
+	}
+
+	descriptor := root.child(NSMetadata, "SPSSODescriptor")
+	if descriptor == nil {
+		t.Fatal("Missing SPSSODescriptor")
+	}
+
+	acs := descriptor.child(NSMetadata, "AssertionConsumerService")
+	if acs == nil || acs.attr("Location") != testACSURL || acs.attr("Binding") != BindingHTTPPost {
+		t.Fatal("Invalid AssertionConsumerService")
+	}
+}
+
+func TestParseResponse(t *testing.T) {
+	key, cert := newTestCertificate(t)
+	otherKey, otherCert := newTestCertificate(t)
+
+	sp := &ServiceProvider{
+		EntityId:       testSPEntityId,
+		ACSURL:         testACSURL,
+		IdPEntityId:    testIdPEntityId,
+		IdPSSOURL:      "https://idp.example.com/sso",
+		IdPCertificate: cert,
+	}
+
+	signAssertion := func(doc string) string {
+		return signElement(t, doc, "_assertion1", key)
+	}
+
+	signResponse := func(doc string) string {
+		return signElement(t, doc, "_response1", key)
+	}
+
+	scenarios := []struct {
+		name        string
+		sp          *ServiceProvider
+		response    string
+		requestId   string
+		expectError bool
+	}{
+		{
+			"signed assertion",
+			sp,
+			signAssertion(testResponse(testResponseOptions{})),
+			testRequestId,
+			false,
+		},
+		{
+			"signed response",
+			sp,
+			signResponse(testResponse(testResponseOptions{})),
+			testRequestId,
+			false,
+		},
+		{
+			"signed response and assertion",
+			sp,
+			signResponse(signAssertion(testResponse(testResponseOptions{}))),
+			testRequestId,
+			false,
+		},
+		{
+			"unsigned",
+			sp,
+			testResponse(testResponseOptions{}),
+			testRequestId,
+			true,
+		},
+		{
+			"signed with different key",
+			sp,
+			signElement(t, testResponse(testResponseOptions{}), "_assertion1", otherKey),
+			testRequestId,
+			true,
+		},
+		{
+			"signed assertion with invalid response signature",
+			sp,
+			signElement(t, signAssertion(testResponse(testResponseOptions{})), "_response1", otherKey),
+			testRequestId,
+			true,
+		},
+		{
+			"missing IdP certificate",
+			&ServiceProvider{EntityId: testSPEntityId, ACSURL: testACSURL, IdPEntityId: testIdPEntityId},
+			signAssertion(testResponse(testResponseOptions{})),
+			testRequestId,
+			true,
+		},
+		{
+			"different IdP certificate",
+			&ServiceProvider{EntityId: testSPEntityId, ACSURL: testACSURL, IdPEntityId: testIdPEntityId, IdPCertificate: otherCert},
+			signAssertion(testResponse(testResponseOptions{})),
+			testRequestId,
+			true,
+		},
+		{
+			"tampered signed assertion",
+			sp,
+			strings.Replace(signAssertion(testResponse(testResponseOptions{})), "test@example.com", "admin@example.com", 1),
+			testRequestId,
+			true,
+		},
+		{
+			"tampered signed response",
+			sp,
+			strings.Replace(signResponse(testResponse(testResponseOptions{})), ">a<", ">admins<", 1),
+			testRequestId,
+			true,
+		},
+		{
+			"missing request id",
+			sp,
+			signAssertion(testResponse(testResponseOptions{})),
+			"",
+			true,
+		},
+		{
+			"different request id",
+			sp,
+			signAssertion(testResponse(testResponseOptions{})),
+			"_other",
+			true,
+		},
+		{
+			"different destination",
+			sp,
+			signAssertion(testResponse(testResponseOptions{destination: "https://example.com"})),
+			testRequestId,
+			true,
+		},
+		{
+			"different issuer",
+			sp,
+			signAssertion(testResponse(testResponseOptions{issuer: "https://example.com"})),
+			testRequestId,
+			true,
+		},
+		{
+			"unsuccessful status",
+			sp,
+			signAssertion(testResponse(testResponseOptions{status: "urn:oasis:names:tc:SAML:2.0:status:Requester"})),
+			testRequestId,
+			true,
+		},
+		{
+			"different audience",
+			sp,
+			signAssertion(testResponse(testResponseOptions{audience: "https://example.com"})),
+			testRequestId,
+			true,
+		},
+		{
+			"different recipient",
+			sp,
+			signAssertion(testResponse(testResponseOptions{recipient: "https://example.com"})),
+			testRequestId,
+			true,
+		},
+		{
+			"expired",
+			sp,
+			signAssertion(testResponse(testResponseOptions{notOnOrAfter: time.Now().Add(-5 * time.Minute)})),
+			testRequestId,
+			true,
+		},
+		{
+			"expired but within the clock skew",
+			sp,
+			signAssertion(testResponse(testResponseOptions{notOnOrAfter: time.Now().Add(-1 * time.Minute)})),
+			testRequestId,
+			false,
+		},
+		{
+			"encrypted assertion",
+			sp,
+			signAssertion(testResponse(testResponseOptions{extra: `<saml:EncryptedAssertion></saml:EncryptedAssertion>`})),
+			testRequestId,
+			true,
+		},
+		{
+			"multiple assertions (wrapping)",
+			sp,
+			signAssertion(testResponse(testResponseOptions{extra: `<saml:Assertion ID="_assertion2"></saml:Assertion>`})),
+			testRequestId,
+			true,
+		},
+		{
+			"duplicated ids (wrapping)",
+			sp,
+			signAssertion(testResponse(testResponseOptions{extra: `<samlp:Extensions><saml:Assertion ID="_assertion1"></saml:Assertion></samlp:Extensions>`})),
+			testRequestId,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			assertion, err := s.sp.ParseResponse(encodeResponse(s.response), s.requestId)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if assertion.Id != "_assertion1" {
+				t.Fatalf("Expected assertion id %q, got %q", "_assertion1", assertion.Id)
+			}
+
+			if assertion.NameId != "test@example.com" || assertion.NameIdFormat != NameIdFormatEmail {
+				t.Fatalf("Unexpected NameID %q (%q)", assertion.NameId, assertion.NameIdFormat)
+			}
+
+			if assertion.SessionIndex != "_session1" {
+				t.Fatalf("Expected session index %q, got %q", "_session1", assertion.SessionIndex)
+			}
+
+			if assertion.ExpiresAt.IsZero() {
+				t.Fatal("Expected non-zero ExpiresAt")
+			}
+
+			if v := assertion.Attribute("urn:oid:2.5.4.42"); v != "John & Co" {
+				t.Fatalf("Expected urn:oid:2.5.4.42 attribute %q, got %q", "John & Co", v)
+			}
+
+			if v := assertion.Attribute("givenName"); v != "John & Co" {
+				t.Fatalf("Expected givenName attribute %q, got %q", "John & Co", v)
+			}
+
+			if v := strings.Join(assertion.Attributes["groups"], ","); v != "a,b" {
+				t.Fatalf("Expected groups attribute values %q, got %q", "a,b", v)
+			}
+
+			if v := assertion.Attribute("missing"); v != "" {
+				t.Fatalf("Expected empty missing attribute, got %q", v)
+			}
+		})
+	}
+}
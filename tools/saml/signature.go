@@ -0,0 +1,174 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha256" // register the hash implementations
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const nsDSig = "http://www.w3.org/2000/09/xmldsig#"
+
+// Supported XML signature algorithms.
+const (
+	AlgExcC14N            = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	AlgEnvelopedSignature = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	AlgRSASHA256          = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	AlgRSASHA512          = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	AlgSHA256             = "http://www.w3.org/2001/04/xmlenc#sha256"
+	AlgSHA512             = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+var errMissingSignature = errors.New("missing signature")
+
+var signatureHashes = map[string]crypto.Hash{
+	AlgRSASHA256: crypto.SHA256,
+	AlgRSASHA512: crypto.SHA512,
+}
+
+var digestHashes = map[string]crypto.Hash{
+	AlgSHA256: crypto.SHA256,
+	AlgSHA512: crypto.SHA512,
+}
+
+// verifySignature verifies the enveloped XML signature of the
+// specified element with the provided certificate public key.
+//
+// Only a single reference to the element itself is allowed and
+// only the exclusive canonicalization with RSA SHA256/SHA512 is supported.
+//
+// It returns errMissingSignature if the element is not signed.
+func verifySignature(el *element, cert *x509.Certificate) error {
+	signatures := el.childElements(nsDSig, "Signature")
+	if len(signatures) == 0 {
+		return errMissingSignature
+	}
+	if len(signatures) > 1 {
+		return errors.New("multiple signatures are not allowed")
+	}
+	signature := signatures[0]
+
+	signedInfo := signature.child(nsDSig, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("missing SignedInfo")
+	}
+
+	// canonicalization method
+	// ---
+	c14nMethod := signedInfo.child(nsDSig, "CanonicalizationMethod")
+	if c14nMethod == nil || c14nMethod.attr("Algorithm") != AlgExcC14N {
+		return errors.New("unsupported canonicalization method")
+	}
+
+	// signature method
+	// ---
+	signatureMethod := signedInfo.child(nsDSig, "SignatureMethod")
+	if signatureMethod == nil {
+		return errors.New("missing SignatureMethod")
+	}
+	signatureHash, ok := signatureHashes[signatureMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported signature method %q", signatureMethod.attr("Algorithm"))
+	}
+
+	// reference
+	// ---
+	references := signedInfo.childElements(nsDSig, "Reference")
+	if len(references) != 1 {
+		return errors.New("exactly one signature reference is required")
+	}
+	reference := references[0]
+
+	id := el.attr("ID")
+	if id == "" || reference.attr("URI") != "#"+id {
+		return errors.New("the signature reference doesn't match with the signed element")
+	}
+
+	var inclusivePrefixes []string
+	var hasEnveloped, hasC14N bool
+	if transforms := reference.child(nsDSig, "Transforms"); transforms != nil {
+		for _, transform := range transforms.childElements(nsDSig, "Transform") {
+			switch transform.attr("Algorithm") {
+			case AlgEnvelopedSignature:
+				hasEnveloped = true
+			case AlgExcC14N:
+				hasC14N = true
+				inclusivePrefixes = parseInclusivePrefixes(transform)
+			default:
+				return fmt.Errorf("unsupported transform %q", transform.attr("Algorithm"))
+			}
+		}
+	}
+	if !hasEnveloped || !hasC14N {
+		return errors.New("the enveloped signature and the exclusive canonicalization transforms are required")
+	}
+
+	digestMethod := reference.child(nsDSig, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("missing DigestMethod")
+	}
+	digestHash, ok := digestHashes[digestMethod.attr("Algorithm")]
+	if !ok {
+		return fmt.Errorf("unsupported digest method %q", digestMethod.attr("Algorithm"))
+	}
+
+	digestValue := reference.child(nsDSig, "DigestValue")
+	if digestValue == nil {
+		return errors.New("missing DigestValue")
+	}
+	expectedDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid DigestValue: %w", err)
+	}
+
+	// verify the referenced element digest
+	// ---
+	h := digestHash.New()
+	h.Write(el.canonicalize(inclusivePrefixes, signature))
+	if subtle.ConstantTimeCompare(h.Sum(nil), expectedDigest) != 1 {
+		return errors.New("digest mismatch")
+	}
+
+	// verify the SignedInfo signature
+	// ---
+	signatureValue := signature.child(nsDSig, "SignatureValue")
+	if signatureValue == nil {
+		return errors.New("missing SignatureValue")
+	}
+	sigBytes, err := decodeBase64(signatureValue.text())
+	if err != nil {
+		return fmt.Errorf("invalid SignatureValue: %w", err)
+	}
+
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("only RSA certificates are supported")
+	}
+
+	h = signatureHash.New()
+	h.Write(signedInfo.canonicalize(parseInclusivePrefixes(c14nMethod), nil))
+
+	return rsa.VerifyPKCS1v15(publicKey, signatureHash, h.Sum(nil), sigBytes)
+}
+
+// parseInclusivePrefixes returns the InclusiveNamespaces PrefixList of
+// an exclusive canonicalization method or transform element (if any).
+func parseInclusivePrefixes(el *element) []string {
+	inclusive := el.child(AlgExcC14N, "InclusiveNamespaces")
+	if inclusive == nil {
+		return nil
+	}
+
+	return strings.Fields(inclusive.attr("PrefixList"))
+}
+
+// decodeBase64 decodes a standard base64 string ignoring the whitespace characters.
+func decodeBase64(value string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+}
@@ -0,0 +1,392 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+// maxXMLDepth limits the nesting of the parsed XML elements.
+const maxXMLDepth = 64
+
+// element is a minimal DOM-like representation of a parsed XML element.
+//
+// Unlike the standard [xml.Decoder.Token] output, it preserves the original
+// namespace prefixes and declarations because they are required for the
+// XML signature canonicalization.
+type element struct {
+	parent *element
+
+	prefix string
+	name   string
+	space  string // the resolved namespace uri
+
+	nsDecls  []nsDecl
+	attrs    []attr
+	children []any // *element or charData
+}
+
+type nsDecl struct {
+	prefix string
+	uri    string
+}
+
+type attr struct {
+	prefix string
+	name   string
+	space  string // the resolved namespace uri (empty for the unprefixed attributes)
+	value  string
+}
+
+type charData string
+
+// parseXML parses the provided raw XML document and returns its root element.
+//
+// DTDs (and therefore custom entities) are not allowed.
+func parseXML(data []byte) (*element, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var root *element
+	var current *element
+	var depth int
+
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if current == nil && root != nil {
+				return nil, errors.New("multiple root elements")
+			}
+
+			depth++
+			if depth > maxXMLDepth {
+				return nil, errors.New("max XML nesting depth reached")
+			}
+
+			el := &element{
+				parent: current,
+				prefix: t.Name.Space,
+				name:   t.Name.Local,
+			}
+
+			for _, a := range t.Attr {
+				switch {
+				case a.Name.Space == "" && a.Name.Local == "xmlns":
+					el.nsDecls = append(el.nsDecls, nsDecl{"", a.Value})
+				case a.Name.Space == "xmlns":
+					el.nsDecls = append(el.nsDecls, nsDecl{a.Name.Local, a.Value})
+				default:
+					el.attrs = append(el.attrs, attr{prefix: a.Name.Space, name: a.Name.Local, value: a.Value})
+				}
+			}
+
+			var ok bool
+			if el.space, ok = el.lookupNamespace(el.prefix); !ok {
+				return nil, fmt.Errorf("undeclared namespace prefix %q", el.prefix)
+			}
+
+			for i, a := range el.attrs {
+				if a.prefix == "" {
+					continue
+				}
+				if el.attrs[i].space, ok = el.lookupNamespace(a.prefix); !ok {
+					return nil, fmt.Errorf("undeclared namespace prefix %q", a.prefix)
+				}
+			}
+
+			if current == nil {
+				root = el
+			} else {
+				current.children = append(current.children, el)
+			}
+
+			current = el
+		case xml.EndElement:
+			if current == nil || current.prefix != t.Name.Space || current.name != t.Name.Local {
+				return nil, fmt.Errorf("unexpected end element %q", t.Name.Local)
+			}
+
+			depth--
+			current = current.parent
+		case xml.CharData:
+			if current == nil {
+				if len(bytes.TrimSpace(t)) > 0 {
+					return nil, errors.New("unexpected character data outside of the root element")
+				}
+				continue
+			}
+
+			current.children = append(current.children, charData(t))
+		case xml.Directive:
+			return nil, errors.New("XML directives are not allowed")
+		}
+	}
+
+	if root == nil || current != nil {
+		return nil, errors.New("incomplete XML document")
+	}
+
+	return root, nil
+}
+
+// lookupNamespace returns the namespace uri associated with the
+// specified prefix in the scope of the current element.
+func (el *element) lookupNamespace(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+
+	for e := el; e != nil; e = e.parent {
+		for _, d := range e.nsDecls {
+			if d.prefix == prefix {
+				return d.uri, true
+			}
+		}
+	}
+
+	// the default namespace is empty unless declared
+	return "", prefix == ""
+}
+
+// is reports whether the element has the specified namespace and local name.
+func (el *element) is(space string, name string) bool {
+	return el.space == space && el.name == name
+}
+
+// attr returns the value of the unprefixed attribute with the specified name.
+func (el *element) attr(name string) string {
+	for _, a := range el.attrs {
+		if a.prefix == "" && a.name == name {
+			return a.value
+		}
+	}
+
+	return ""
+}
+
+// childElements returns all direct child elements with the specified namespace and local name.
+func (el *element) childElements(space string, name string) []*element {
+	var result []*element
+
+	for _, c := range el.children {
+		if child, ok := c.(*element); ok && child.is(space, name) {
+			result = append(result, child)
+		}
+	}
+
+	return result
+}
+
+// child returns the first direct child element with the specified namespace and local name.
+func (el *element) child(space string, name string) *element {
+	for _, c := range el.children {
+		if child, ok := c.(*element); ok && child.is(space, name) {
+			return child
+		}
+	}
+
+	return nil
+}
+
+// text returns the concatenated direct character data of the element.
+func (el *element) text() string {
+	var sb strings.Builder
+
+	for _, c := range el.children {
+		if data, ok := c.(charData); ok {
+			sb.WriteString(string(data))
+		}
+	}
+
+	return strings.TrimSpace(sb.String())
+}
+
+// walk calls fn for the element and all of its descendants (in document order).
+func (el *element) walk(fn func(e *element)) {
+	fn(el)
+
+	for _, c := range el.children {
+		if child, ok := c.(*element); ok {
+			child.walk(fn)
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+
+// canonicalize serializes the element subtree according to the
+// Exclusive XML Canonicalization 1.0 (omits comments) specification
+// (https://www.w3.org/TR/xml-exc-c14n/).
+//
+// inclusivePrefixes is the optional InclusiveNamespaces PrefixList
+// ("#default" stands for the default namespace).
+//
+// The exclude element (if any) and its subtree are omitted from the
+// output (aka. the enveloped signature transform).
+func (el *element) canonicalize(inclusivePrefixes []string, exclude *element) []byte {
+	inclusive := make([]string, len(inclusivePrefixes))
+	for i, p := range inclusivePrefixes {
+		if p == "#default" {
+			p = ""
+		}
+		inclusive[i] = p
+	}
+
+	var buf bytes.Buffer
+
+	el.writeCanonical(&buf, map[string]string{}, inclusive, exclude)
+
+	return buf.Bytes()
+}
+
+func (el *element) writeCanonical(buf *bytes.Buffer, rendered map[string]string, inclusive []string, exclude *element) {
+	if el == exclude {
+		return
+	}
+
+	// collect the visibly utilized prefixes
+	utilized := []string{el.prefix}
+	for _, a := range el.attrs {
+		if a.prefix != "" && a.prefix != "xml" && !slices.Contains(utilized, a.prefix) {
+			utilized = append(utilized, a.prefix)
+		}
+	}
+	for _, p := range inclusive {
+		if _, ok := el.lookupNamespace(p); ok && p != "xml" && !slices.Contains(utilized, p) {
+			utilized = append(utilized, p)
+		}
+	}
+
+	// determine the namespace nodes that need to be rendered
+	var decls []nsDecl
+	for _, p := range utilized {
+		uri, _ := el.lookupNamespace(p)
+
+		renderedURI, isRendered := rendered[p]
+		if isRendered && renderedURI == uri {
+			continue
+		}
+
+		// the empty default namespace is rendered only to "undeclare" a non-empty one
+		if p == "" && uri == "" && renderedURI == "" {
+			continue
+		}
+
+		decls = append(decls, nsDecl{p, uri})
+	}
+	slices.SortFunc(decls, func(a, b nsDecl) int {
+		return strings.Compare(a.prefix, b.prefix)
+	})
+
+	if len(decls) > 0 {
+		newRendered := make(map[string]string, len(rendered)+len(decls))
+		for k, v := range rendered {
+			newRendered[k] = v
+		}
+		for _, d := range decls {
+			newRendered[d.prefix] = d.uri
+		}
+		rendered = newRendered
+	}
+
+	attrs := slices.Clone(el.attrs)
+	slices.SortFunc(attrs, func(a, b attr) int {
+		if c := strings.Compare(a.space, b.space); c != 0 {
+			return c
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	qname := el.name
+	if el.prefix != "" {
+		qname = el.prefix + ":" + el.name
+	}
+
+	buf.WriteByte('<')
+	buf.WriteString(qname)
+
+	for _, d := range decls {
+		if d.prefix == "" {
+			buf.WriteString(` xmlns="`)
+		} else {
+			buf.WriteString(` xmlns:` + d.prefix + `="`)
+		}
+		writeCanonicalAttrValue(buf, d.uri)
+		buf.WriteByte('"')
+	}
+
+	for _, a := range attrs {
+		buf.WriteByte(' ')
+		if a.prefix != "" {
+			buf.WriteString(a.prefix + ":")
+		}
+		buf.WriteString(a.name + `="`)
+		writeCanonicalAttrValue(buf, a.value)
+		buf.WriteByte('"')
+	}
+
+	buf.WriteByte('>')
+
+	for _, c := range el.children {
+		switch v := c.(type) {
+		case *element:
+			v.writeCanonical(buf, rendered, inclusive, exclude)
+		case charData:
+			writeCanonicalText(buf, string(v))
+		}
+	}
+
+	buf.WriteString("</" + qname + ">")
+}
+
+func writeCanonicalText(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '>':
+			buf.WriteString("&gt;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
+
+func writeCanonicalAttrValue(buf *bytes.Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '&':
+			buf.WriteString("&amp;")
+		case '<':
+			buf.WriteString("&lt;")
+		case '"':
+			buf.WriteString("&quot;")
+		case '\t':
+			buf.WriteString("&#x9;")
+		case '\n':
+			buf.WriteString("&#xA;")
+		case '\r':
+			buf.WriteString("&#xD;")
+		default:
+			buf.WriteRune(r)
+		}
+	}
+}
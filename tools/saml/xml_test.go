@@ -0,0 +1,139 @@
+package saml
+
+import (
+	"testing"
+)
+
+func TestParseXML(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		xml         string
+		expectError bool
+	}{
+		{"empty", ``, true},
+		{"invalid", `<a>`, true},
+		{"mismatched end element", `<a></b>`, true},
+		{"mismatched end prefix", `<x:a xmlns:x="urn:x" xmlns:y="urn:x"></y:a>`, true},
+		{"multiple roots", `<a></a><b></b>`, true},
+		{"doctype", `<!DOCTYPE a [<!ENTITY x "y">]><a>&x;</a>`, true},
+		{"undeclared element prefix", `<x:a></x:a>`, true},
+		{"undeclared attribute prefix", `<a x:b="c"></a>`, true},
+		{"valid", `<?xml version="1.0"?><!-- comment --><a xmlns:x="urn:x" x:b="c"><x:d>e</x:d></a>`, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := parseXML([]byte(s.xml))
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+		})
+	}
+}
+
+func TestParseXMLNamespaces(t *testing.T) {
+	root, err := parseXML([]byte(`<a xmlns="urn:default" xmlns:x="urn:x"><x:b x:attr="1" attr="2"/><c xmlns=""/></a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !root.is("urn:default", "a") {
+		t.Fatalf("Expected root {urn:default}a, got {%s}%s", root.space, root.name)
+	}
+
+	b := root.child("urn:x", "b")
+	if b == nil {
+		t.Fatal("Expected {urn:x}b child")
+	}
+
+	if b.attr("attr") != "2" {
+		t.Fatalf("Expected the unprefixed attr value 2, got %q", b.attr("attr"))
+	}
+
+	if c := root.child("", "c"); c == nil {
+		t.Fatal("Expected c child without namespace")
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	scenarios := []struct {
+		name      string
+		xml       string
+		inclusive []string
+		expected  string
+	}{
+		{
+			"namespaces, attributes and escaping",
+			`<?xml version="1.0"?>` +
+				`<a:root xmlns:a="urn:a" xmlns:b="urn:b" xmlns="urn:default" z="1" b:y="2" a:x="3">` +
+				`<child attr="&quot;&#9;&#10;&lt;&gt;"/>text &amp; &lt;&gt; &#13;` +
+				`<!-- comment -->` +
+				`<b:el xmlns:c="urn:c"/>` +
+				`<plain xmlns="">x</plain>` +
+				`</a:root>`,
+			nil,
+			`<a:root xmlns:a="urn:a" xmlns:b="urn:b" z="1" a:x="3" b:y="2">` +
+				`<child xmlns="urn:default" attr="&quot;&#x9;&#xA;&lt;>"></child>text &amp; &lt;&gt; &#xD;` +
+				`<b:el></b:el>` +
+				`<plain>x</plain>` +
+				`</a:root>`,
+		},
+		{
+			"default namespace undeclaration",
+			`<root xmlns="urn:d"><inner xmlns=""><deep xmlns="urn:d"/></inner></root>`,
+			nil,
+			`<root xmlns="urn:d"><inner xmlns=""><deep xmlns="urn:d"></deep></inner></root>`,
+		},
+		{
+			"redeclared prefix",
+			`<x:root xmlns:x="urn:1"><x:inner xmlns:x="urn:2"/><x:same xmlns:x="urn:1"/></x:root>`,
+			nil,
+			`<x:root xmlns:x="urn:1"><x:inner xmlns:x="urn:2"></x:inner><x:same></x:same></x:root>`,
+		},
+		{
+			"without inclusive prefixes",
+			`<a:root xmlns:a="urn:a" xmlns:xs="urn:xs"><a:v type="xs:string">1</a:v></a:root>`,
+			nil,
+			`<a:root xmlns:a="urn:a"><a:v type="xs:string">1</a:v></a:root>`,
+		},
+		{
+			"with inclusive prefixes",
+			`<a:root xmlns:a="urn:a" xmlns:xs="urn:xs"><a:v type="xs:string">1</a:v></a:root>`,
+			[]string{"xs", "missing"},
+			`<a:root xmlns:a="urn:a" xmlns:xs="urn:xs"><a:v type="xs:string">1</a:v></a:root>`,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			root, err := parseXML([]byte(s.xml))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := string(root.canonicalize(s.inclusive, nil))
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestCanonicalizeSubtree(t *testing.T) {
+	root, err := parseXML([]byte(`<a:root xmlns:a="urn:a" xmlns:b="urn:b"><b:el x="1"><a:exclude/><b:keep/></b:el></a:root>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	el := root.child("urn:b", "el")
+	exclude := el.child("urn:a", "exclude")
+
+	expected := `<b:el xmlns:b="urn:b" x="1"><b:keep></b:keep></b:el>`
+
+	result := string(el.canonicalize(nil, exclude))
+	if result != expected {
+		t.Fatalf("Expected\n%s\ngot\n%s", expected, result)
+	}
+}
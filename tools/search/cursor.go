@@ -0,0 +1,222 @@
+package search
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pocketbase/dbx"
+)
+
+// keysetColumn describes a single resolved keyset pagination column.
+type keysetColumn struct {
+	identifier string
+	direction  string
+}
+
+// encodeCursor serializes the provided keyset values into an opaque url-safe string.
+func encodeCursor(values []any) (string, error) {
+	normalized := make([]any, len(values))
+	for i, v := range values {
+		// the sqlite driver may return TEXT values as raw bytes
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		normalized[i] = v
+	}
+
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+// decodeCursor parses the provided opaque cursor string
+// and returns its deserialized keyset values.
+//
+// Returns ErrInvalidCursor if the cursor is malformed or
+// doesn't have exactly totalValues values.
+func decodeCursor(cursor string, totalValues int) ([]any, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	values := []any{}
+	if err := decoder.Decode(&values); err != nil || len(values) != totalValues {
+		return nil, ErrInvalidCursor
+	}
+
+	for i, v := range values {
+		switch cv := v.(type) {
+		case json.Number:
+			if n, err := cv.Int64(); err == nil {
+				values[i] = n
+			} else if f, err := cv.Float64(); err == nil {
+				values[i] = f
+			} else {
+				return nil, ErrInvalidCursor
+			}
+		case string, bool, nil:
+			// allowed
+		default:
+			// arrays and objects are not valid column values
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
+
+// buildKeysetExpr builds a WHERE expression that matches all rows
+// positioned after the row with the provided keyset values
+// (following the sort order of the provided columns).
+//
+// NULL values are handled in accordance with the SQLite default
+// ordering, aka. NULLs are considered smaller than any other value.
+//
+// For example, for columns (a ASC, b DESC, id ASC) the generated expression is similar to:
+//
+//	(a > {:v0}) OR (a = {:v0} AND (b < {:v1} OR b IS NULL)) OR (a = {:v0} AND b = {:v1} AND id > {:v2})
+func buildKeysetExpr(columns []keysetColumn, values []any) dbx.Expression {
+	params := make(dbx.Params, len(values))
+	placeholders := make([]string, len(values))
+	for i, v := range values {
+		name := "cursor" + strconv.Itoa(i)
+		params[name] = v
+		placeholders[i] = "{:" + name + "}"
+	}
+
+	terms := make([]dbx.Expression, 0, len(columns))
+
+	for i, col := range columns {
+		var after string
+
+		if col.direction == SortDesc {
+			if values[i] == nil {
+				// NULLs are at the end of a DESC sort so there is nothing after them
+				continue
+			}
+			after = fmt.Sprintf("(%s < %s OR %s IS NULL)", col.identifier, placeholders[i], col.identifier)
+		} else {
+			if values[i] == nil {
+				after = fmt.Sprintf("%s IS NOT NULL", col.identifier)
+			} else {
+				after = fmt.Sprintf("%s > %s", col.identifier, placeholders[i])
+			}
+		}
+
+		parts := make([]dbx.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			if values[j] == nil {
+				parts = append(parts, dbx.NewExp(columns[j].identifier+" IS NULL"))
+			} else {
+				parts = append(parts, dbx.NewExp(columns[j].identifier+" = "+placeholders[j], params))
+			}
+		}
+		parts = append(parts, dbx.NewExp(after, params))
+
+		terms = append(terms, dbx.And(parts...))
+	}
+
+	if len(terms) == 0 {
+		// no rows after the cursor
+		return dbx.NewExp("1=0")
+	}
+
+	return dbx.Or(terms...)
+}
+
+// columnValues extracts the values of the specified db column
+// from each item of the provided slice pointer.
+//
+// The supported items are dbx.NullStringMap, string keyed maps
+// and structs (or pointers to structs) with a field mapped to the column.
+func columnValues(items any, column string) ([]any, error) {
+	rv := reflect.Indirect(reflect.ValueOf(items))
+	if rv.Kind() != reflect.Slice {
+		return nil, errors.New("the cursor pagination items must be a slice")
+	}
+
+	result := make([]any, rv.Len())
+	for i := range result {
+		v, ok := columnValue(rv.Index(i), column)
+		if !ok {
+			return nil, fmt.Errorf("missing cursor column %q in the fetched items", column)
+		}
+		result[i] = v
+	}
+
+	return result, nil
+}
+
+func columnValue(rv reflect.Value, column string) (any, bool) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, false
+		}
+		rv = rv.Elem()
+	}
+
+	switch rv.Kind() {
+	case reflect.Map:
+		if m, ok := rv.Interface().(dbx.NullStringMap); ok {
+			v, ok := m[column]
+			if !ok || !v.Valid {
+				return nil, ok
+			}
+			return v.String, true
+		}
+
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+
+		v := rv.MapIndex(reflect.ValueOf(column).Convert(rv.Type().Key()))
+		if !v.IsValid() {
+			return nil, false
+		}
+
+		return v.Interface(), true
+	case reflect.Struct:
+		rt := rv.Type()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			tag := field.Tag.Get("db")
+			if tag == "-" {
+				continue
+			}
+
+			if field.Anonymous && tag == "" {
+				if v, ok := columnValue(rv.Field(i), column); ok {
+					return v, true
+				}
+				continue
+			}
+
+			name := tag
+			if name == "" {
+				name = dbx.DefaultFieldMapFunc(field.Name)
+			}
+
+			if name == column {
+				return rv.Field(i).Interface(), true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,114 @@
+package search
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/pocketbase/dbx"
+)
+
+func TestCursorEncodeDecode(t *testing.T) {
+	values := []any{"abc", int64(123), 1.5, nil, true, []byte("test")}
+
+	cursor, err := encodeCursor(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := decodeCursor(cursor, len(values))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encoded, _ := json.Marshal(decoded)
+	expected := `["abc",123,1.5,null,true,"test"]`
+	if string(encoded) != expected {
+		t.Fatalf("Expected decoded values %s, got %s", expected, encoded)
+	}
+
+	if _, ok := decoded[1].(int64); !ok {
+		t.Fatalf("Expected the integer value to be decoded as int64, got %T", decoded[1])
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	scenarios := []struct {
+		name        string
+		cursor      string
+		totalValues int
+	}{
+		{"non base64", "!@#", 1},
+		{"non json", "YWJj", 1},
+		{"values count mismatch", "WzEsMl0", 3},
+		{"nested array value", "W1sxXV0", 1},
+		{"nested object value", "W3siYSI6MX1d", 1},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			_, err := decodeCursor(s.cursor, s.totalValues)
+			if err != ErrInvalidCursor {
+				t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildKeysetExpr(t *testing.T) {
+	columns := []keysetColumn{
+		{identifier: "[[a]]", direction: SortAsc},
+		{identifier: "[[b]]", direction: SortDesc},
+		{identifier: "[[id]]", direction: SortAsc},
+	}
+
+	scenarios := []struct {
+		name     string
+		values   []any
+		expected string
+	}{
+		{
+			"non-null values",
+			[]any{1, "b", "id1"},
+			"([[a]] > {:cursor0}) OR (([[a]] = {:cursor0}) AND (([[b]] < {:cursor1} OR [[b]] IS NULL))) OR (([[a]] = {:cursor0}) AND ([[b]] = {:cursor1}) AND ([[id]] > {:cursor2}))",
+		},
+		{
+			"null values",
+			[]any{nil, nil, "id1"},
+			"([[a]] IS NOT NULL) OR (([[a]] IS NULL) AND ([[b]] IS NULL) AND ([[id]] > {:cursor2}))",
+		},
+	}
+
+	db := dbx.NewFromDB(nil, "")
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			expr := buildKeysetExpr(columns, s.values)
+
+			params := dbx.Params{}
+			raw := expr.Build(db, params)
+			if raw != s.expected {
+				t.Fatalf("Expected expression\n%s\ngot\n%s", s.expected, raw)
+			}
+
+			for i, v := range s.values {
+				name := "cursor" + strconv.Itoa(i)
+				if v == nil {
+					continue
+				}
+				if params[name] != v {
+					t.Fatalf("Expected param %q to be %v, got %v", name, v, params[name])
+				}
+			}
+		})
+	}
+
+	t.Run("no rows after a DESC null", func(t *testing.T) {
+		expr := buildKeysetExpr([]keysetColumn{{identifier: "[[b]]", direction: SortDesc}}, []any{nil})
+
+		raw := expr.Build(db, dbx.Params{})
+		if raw != "1=0" {
+			t.Fatalf("Expected 1=0 expression, got %s", raw)
+		}
+	})
+}
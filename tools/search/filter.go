@@ -305,12 +305,23 @@ func resolveToken(token fexpr.Token, fieldResolver FieldResolver) (*ResolverResu
 			Params:     dbx.Params{placeholder: cast.ToFloat64(token.Literal)},
 		}, nil
 	case fexpr.TokenFunction:
+		args, _ := token.Meta.([]fexpr.Token)
+
+		// the full-text search function requires direct access to the field resolver
+		if token.Literal == matchFunctionName {
+			return resolveMatchFunction(fieldResolver, args...)
+		}
+
+		// the RBAC function requires direct access to the field resolver
+		if token.Literal == permissionFunctionName {
+			return resolvePermissionFunction(fieldResolver, args...)
+		}
+
 		fn, ok := TokenFunctions[token.Literal]
 		if !ok {
 			return nil, fmt.Errorf("unknown function %q", token.Literal)
 		}
 
-		args, _ := token.Meta.([]fexpr.Token)
 		return fn(func(argToken fexpr.Token) (*ResolverResult, error) {
 			return resolveToken(argToken, fieldResolver)
 		}, args...)
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"database/sql"
 	"errors"
 	"math"
 	"net/url"
@@ -39,6 +40,9 @@ var (
 	ErrFilterExprLimit      = errors.New("max filter expressions limit reached")
 	ErrFilterLengthLimit    = errors.New("max filter length limit reached")
 	ErrSortFieldLengthLimit = errors.New("max sort field length limit reached")
+	ErrInvalidCursor        = errors.New("invalid or malformed cursor")
+	ErrCursorRandomSort     = errors.New("random sort is not supported with cursor pagination")
+	ErrCursorOrderBy        = errors.New("the search query must not have its own ORDER BY with cursor pagination")
 )
 
 // URL search query params
@@ -48,6 +52,7 @@ const (
 	SortQueryParam      string = "sort"
 	FilterQueryParam    string = "filter"
 	SkipTotalQueryParam string = "skipTotal"
+	CursorQueryParam    string = "cursor"
 )
 
 // Result defines the returned search result structure.
@@ -57,6 +62,12 @@ type Result struct {
 	PerPage    int `json:"perPage"`
 	TotalItems int `json:"totalItems"`
 	TotalPages int `json:"totalPages"`
+
+	// NextCursor is the opaque cursor pointing to the next result page.
+	//
+	// It is set only when the cursor pagination is enabled
+	// and there could be more items after the current page.
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 // Provider represents a single configured search provider instance.
@@ -64,11 +75,14 @@ type Provider struct {
 	fieldResolver      FieldResolver
 	query              *dbx.SelectQuery
 	countCol           string
+	cursorCol          string
+	cursor             string
 	sort               []SortField
 	filter             []FilterData
 	page               int
 	perPage            int
 	skipTotal          bool
+	cursorEnabled      bool
 	maxFilterExprLimit int
 	maxSortExprLimit   int
 }
@@ -88,6 +102,7 @@ func NewProvider(fieldResolver FieldResolver) *Provider {
 	return &Provider{
 		fieldResolver:      fieldResolver,
 		countCol:           "id",
+		cursorCol:          "id",
 		page:               1,
 		perPage:            DefaultPerPage,
 		sort:               []SortField{},
@@ -132,6 +147,33 @@ func (s *Provider) CountCol(name string) *Provider {
 	return s
 }
 
+// CursorCol allows changing the default column (id) that is used
+// as unique tiebreaker when generating the cursor pagination keyset.
+//
+// This field is ignored if the cursor pagination is not enabled.
+func (s *Provider) CursorCol(name string) *Provider {
+	s.cursorCol = name
+	return s
+}
+
+// Cursor enables the keyset (aka. cursor) pagination and sets the
+// `cursor` field of the current search provider.
+//
+// The cursor is the opaque Result.NextCursor value of a previous
+// search with the same sort fields (empty string for the first page).
+//
+// When the cursor pagination is enabled the `page` field is ignored
+// and the items are fetched after the cursor position
+// based on the active sort fields (with the CursorCol as tiebreaker).
+//
+// Note that the search query must not have its own ORDER BY clause
+// and the fetched items must contain the CursorCol value.
+func (s *Provider) Cursor(cursor string) *Provider {
+	s.cursor = cursor
+	s.cursorEnabled = true
+	return s
+}
+
 // Page sets the `page` field of the current search provider.
 //
 // Normalization on the `page` value is done during `Exec()`.
@@ -194,6 +236,10 @@ func (s *Provider) Parse(urlQuery string) error {
 		s.SkipTotal(v)
 	}
 
+	if params.Has(CursorQueryParam) {
+		s.Cursor(params.Get(CursorQueryParam))
+	}
+
 	if raw := params.Get(PageQueryParam); raw != "" {
 		v, err := strconv.Atoi(raw)
 		if err != nil {
@@ -251,25 +297,58 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	if len(s.sort) > s.maxSortExprLimit {
 		return nil, ErrSortExprLimit
 	}
+
+	var keyset []keysetColumn
+	if s.cursorEnabled {
+		// the keyset must match exactly the applied ordering
+		if len(modelsQuery.Info().OrderBy) > 0 {
+			return nil, ErrCursorOrderBy
+		}
+		keyset = make([]keysetColumn, 0, len(s.sort)+1)
+	}
+
 	for _, sortField := range s.sort {
 		if len(sortField.Name) > MaxSortFieldLength {
 			return nil, ErrSortFieldLengthLimit
 		}
-		expr, err := sortField.BuildExpr(s.fieldResolver)
+
+		if !s.cursorEnabled {
+			expr, err := sortField.BuildExpr(s.fieldResolver)
+			if err != nil {
+				return nil, err
+			}
+			if expr != "" {
+				modelsQuery.AndOrderBy(prefixRowidExpr(&modelsQuery, sortField.Name, expr))
+			}
+			continue
+		}
+
+		if sortField.Name == randomSortKey {
+			return nil, ErrCursorRandomSort
+		}
+
+		identifier, err := sortField.buildIdentifier(s.fieldResolver)
 		if err != nil {
 			return nil, err
 		}
-		if expr != "" {
-			// ensure that _rowid_ expressions are always prefixed with the first FROM table
-			if sortField.Name == rowidSortKey && !strings.Contains(expr, ".") {
-				queryInfo := modelsQuery.Info()
-				if len(queryInfo.From) > 0 {
-					expr = "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + expr
-				}
-			}
+		identifier = prefixRowidExpr(&modelsQuery, sortField.Name, identifier)
+
+		keyset = append(keyset, keysetColumn{identifier: identifier, direction: sortField.Direction})
 
-			modelsQuery.AndOrderBy(expr)
+		modelsQuery.AndOrderBy(identifier + " " + sortField.Direction)
+	}
+
+	if s.cursorEnabled {
+		cursorCol := s.cursorCol
+		queryInfo := modelsQuery.Info()
+		if len(queryInfo.From) > 0 {
+			cursorCol = queryInfo.From[0] + "." + cursorCol
 		}
+		cursorCol = "[[" + cursorCol + "]]"
+
+		keyset = append(keyset, keysetColumn{identifier: cursorCol, direction: SortAsc})
+
+		modelsQuery.AndOrderBy(cursorCol + " " + SortAsc)
 	}
 
 	// apply field resolver query modifications (if any)
@@ -294,7 +373,23 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	totalPages := -1
 
 	// prepare a count query from the base one
+	// (before applying the cursor so that the total reflects the entire search)
 	countQuery := modelsQuery // shallow clone
+
+	if s.cursorEnabled && s.cursor != "" {
+		values, err := decodeCursor(s.cursor, len(keyset))
+		if err != nil {
+			return nil, err
+		}
+
+		modelsQuery.AndWhere(buildKeysetExpr(keyset, values))
+	}
+
+	// prepare a query for fetching the keyset values of the last page item
+	// (it is shallow cloned before the pagination is applied to the models query)
+	cursorQuery := modelsQuery
+	var nextCursor string
+
 	countExec := func() error {
 		queryInfo := countQuery.Info()
 		countCol := s.countCol
@@ -319,24 +414,32 @@ func (s *Provider) Exec(items any) (*Result, error) {
 	// apply pagination to the original query and fetch the models
 	modelsExec := func() error {
 		modelsQuery.Limit(int64(s.perPage))
-		modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
+		if !s.cursorEnabled {
+			modelsQuery.Offset(int64(s.perPage * (s.page - 1)))
+		}
 
-		return modelsQuery.All(items)
+		if err := modelsQuery.All(items); err != nil {
+			return err
+		}
+
+		if s.cursorEnabled {
+			var err error
+			nextCursor, err = s.resolveNextCursor(cursorQuery, keyset, items)
+			return err
+		}
+
+		return nil
 	}
 
+	// execute the queries concurrently
+	errg := new(errgroup.Group)
+	errg.SetLimit(3)
 	if !s.skipTotal {
-		// execute the 2 queries concurrently
-		errg := new(errgroup.Group)
-		errg.SetLimit(2)
 		errg.Go(countExec)
-		errg.Go(modelsExec)
-		if err := errg.Wait(); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := modelsExec(); err != nil {
-			return nil, err
-		}
+	}
+	errg.Go(modelsExec)
+	if err := errg.Wait(); err != nil {
+		return nil, err
 	}
 
 	result := &Result{
@@ -345,11 +448,95 @@ func (s *Provider) Exec(items any) (*Result, error) {
 		TotalItems: totalCount,
 		TotalPages: totalPages,
 		Items:      items,
+		NextCursor: nextCursor,
 	}
 
 	return result, nil
 }
 
+// resolveNextCursor returns the opaque cursor pointing right after
+// the last fetched items row.
+//
+// The keyset values are loaded with the provided (unpaginated) query
+// for the fetched items unique cursor column values, so that concurrent
+// inserts and deletes don't affect the cursor position.
+//
+// Returns an empty string if there are less than perPage items (aka. no next page).
+func (s *Provider) resolveNextCursor(query dbx.SelectQuery, keyset []keysetColumn, items any) (string, error) {
+	ids, err := columnValues(items, s.cursorCol)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ids) < s.perPage {
+		return "", nil
+	}
+
+	selects := make([]string, len(keyset))
+	reversedOrder := make([]string, len(keyset))
+	for i, col := range keyset {
+		selects[i] = col.identifier
+
+		if col.direction == SortDesc {
+			reversedOrder[i] = col.identifier + " " + SortAsc
+		} else {
+			reversedOrder[i] = col.identifier + " " + SortDesc
+		}
+	}
+
+	params := make(dbx.Params, len(ids))
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		name := "cursorId" + strconv.Itoa(i)
+		params[name] = id
+		placeholders[i] = "{:" + name + "}"
+	}
+
+	// the unique cursor column is always the last keyset column
+	cursorCol := keyset[len(keyset)-1].identifier
+
+	values := make([]any, len(keyset))
+	ptrs := make([]any, len(keyset))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	// select the last page item that still exists
+	// (in case the actual last one was deleted in the meantime)
+	//
+	// note: query is shallow cloned and slice/map in-place modifications should be avoided
+	err = query.
+		AndWhere(dbx.NewExp(cursorCol+" IN ("+strings.Join(placeholders, ",")+")", params)).
+		Select(selects...).
+		OrderBy(reversedOrder...).
+		Limit(1).
+		Row(ptrs...)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// all page items were deleted
+			return s.cursor, nil
+		}
+		return "", err
+	}
+
+	return encodeCursor(values)
+}
+
+// prefixRowidExpr ensures that the _rowid_ sort expressions are always
+// prefixed with the first FROM table of the query.
+func prefixRowidExpr(query *dbx.SelectQuery, sortName string, expr string) string {
+	if sortName != rowidSortKey || strings.Contains(expr, ".") {
+		return expr
+	}
+
+	queryInfo := query.Info()
+	if len(queryInfo.From) == 0 {
+		return expr
+	}
+
+	return "[[" + inflector.Columnify(queryInfo.From[0]) + "]]." + expr
+}
+
 // ParseAndExec is a short convenient method to trigger both
 // `Parse()` and `Exec()` in a single call.
 func (s *Provider) ParseAndExec(urlQuery string, modelsSlice any) (*Result, error) {
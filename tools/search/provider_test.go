@@ -605,6 +605,179 @@ func TestProviderFilterAndSortLimits(t *testing.T) {
 	}
 }
 
+func TestProviderExecCursor(t *testing.T) {
+	testDB, err := createTestDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer testDB.Close()
+
+	testDB.Insert("test", dbx.Params{"id": 3, "test1": 3, "test2": "test2.1"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 4, "test1": 4, "test2": "test2.3"}).Execute()
+	testDB.Insert("test", dbx.Params{"id": 5, "test1": 5, "test2": "test2.2"}).Execute()
+	defer testDB.Delete("test", dbx.NewExp("id > 2")).Execute()
+
+	query := testDB.Select("*").From("test")
+
+	scenarios := []struct {
+		name        string
+		sort        []SortField
+		filter      []FilterData
+		skipTotal   bool
+		expectPages []string
+		expectTotal int
+	}{
+		{
+			"no sort fields (id tiebreaker only)",
+			nil,
+			nil,
+			true,
+			[]string{"[1,2]", "[3,4]", "[5]"},
+			-1,
+		},
+		{
+			"desc sort with filter and total",
+			[]SortField{{"test2", SortDesc}},
+			[]FilterData{"test1 > 1"},
+			false,
+			[]string{"[4,2]", "[5,3]", "[]"}, // exactly perPage items in the last page
+			4,
+		},
+		{
+			"multiple sort fields",
+			[]SortField{{"test2", SortAsc}, {"test1", SortDesc}},
+			nil,
+			true,
+			[]string{"[3,1]", "[5,2]", "[4]"},
+			-1,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			pages := []string{}
+
+			var cursor string
+			for i := 0; ; i++ {
+				if i > len(s.expectPages) {
+					t.Fatalf("Too many pages, got %v", pages)
+				}
+
+				testDB.CalledQueries = []string{} // reset
+
+				items := []testCursorStruct{}
+
+				result, err := NewProvider(&testFieldResolver{}).
+					Query(query).
+					Sort(s.sort).
+					Filter(s.filter).
+					SkipTotal(s.skipTotal).
+					Page(10). // should be ignored
+					PerPage(2).
+					Cursor(cursor).
+					Exec(&items)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				// models + count (if not skipped) + next cursor (only for full pages)
+				expectQueries := 1
+				if !s.skipTotal {
+					expectQueries++
+				}
+				if len(items) == 2 {
+					expectQueries++
+				}
+				if len(testDB.CalledQueries) != expectQueries {
+					t.Fatalf("Expected %d queries, got %d: \n%v", expectQueries, len(testDB.CalledQueries), testDB.CalledQueries)
+				}
+
+				if result.TotalItems != s.expectTotal {
+					t.Fatalf("Expected total %d, got %d", s.expectTotal, result.TotalItems)
+				}
+
+				ids := make([]int, len(items))
+				for j, item := range items {
+					ids[j] = item.Test1
+				}
+				encoded, _ := json.Marshal(ids)
+				pages = append(pages, string(encoded))
+
+				if result.NextCursor == "" {
+					break
+				}
+				cursor = result.NextCursor
+			}
+
+			encodedPages, _ := json.Marshal(pages)
+			expectedPages, _ := json.Marshal(s.expectPages)
+			if string(encodedPages) != string(expectedPages) {
+				t.Fatalf("Expected pages %s, got %s", expectedPages, encodedPages)
+			}
+		})
+	}
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		_, err := NewProvider(&testFieldResolver{}).
+			Query(query).
+			Cursor("invalid").
+			Exec(&[]testTableStruct{})
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Fatalf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+
+	t.Run("deleted last page item", func(t *testing.T) {
+		items := []testCursorStruct{{Id: 1}, {Id: 2}, {Id: 999}} // 999 is missing
+
+		p := NewProvider(&testFieldResolver{}).PerPage(3).Cursor("")
+
+		keyset := []keysetColumn{{identifier: "[[test.id]]", direction: SortAsc}}
+
+		cursor, err := p.resolveNextCursor(*query, keyset, &items)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// should fallback to the last existing page item
+		if expected := "WzJd"; cursor != expected {
+			t.Fatalf("Expected cursor %q, got %q", expected, cursor)
+		}
+	})
+
+	t.Run("missing cursor column in the items", func(t *testing.T) {
+		_, err := NewProvider(&testFieldResolver{}).
+			Query(query).
+			PerPage(1).
+			Cursor("").
+			Exec(&[]testTableStruct{})
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+
+	t.Run("query with custom ORDER BY", func(t *testing.T) {
+		_, err := NewProvider(&testFieldResolver{}).
+			Query(testDB.Select("*").From("test").OrderBy("test1 ASC")).
+			Cursor("").
+			Exec(&[]testCursorStruct{})
+		if !errors.Is(err, ErrCursorOrderBy) {
+			t.Fatalf("Expected ErrCursorOrderBy, got %v", err)
+		}
+	})
+
+	t.Run("random sort", func(t *testing.T) {
+		_, err := NewProvider(&testFieldResolver{}).
+			Query(query).
+			Sort([]SortField{{"@random", SortAsc}}).
+			Cursor("").
+			Exec(&[]testTableStruct{})
+		if !errors.Is(err, ErrCursorRandomSort) {
+			t.Fatalf("Expected ErrCursorRandomSort, got %v", err)
+		}
+	})
+}
+
 func TestProviderParseAndExec(t *testing.T) {
 	testDB, err := createTestDB()
 	if err != nil {
@@ -677,6 +850,18 @@ func TestProviderParseAndExec(t *testing.T) {
 			false,
 			`{"items":[{"test1":2,"test2":"test2.2","test3":""}],"page":1,"perPage":1000,"totalItems":1,"totalPages":1}`,
 		},
+		{
+			"invalid cursor",
+			"cursor=invalid",
+			true,
+			"",
+		},
+		{
+			"cursor with custom query ORDER BY",
+			"cursor=&perPage=1&sort=-test1",
+			true,
+			"",
+		},
 		{
 			"valid query params with skipTotal=1",
 			"page=1&perPage=9999&filter=test1>1&sort=-test2,test3&skipTotal=1",
@@ -739,6 +924,11 @@ type testTableStruct struct {
 	Test3 string `db:"test3" json:"test3"`
 }
 
+type testCursorStruct struct {
+	Id    int `db:"id" json:"id"`
+	Test1 int `db:"test1" json:"test1"`
+}
+
 type testDB struct {
 	*dbx.DB
 	CalledQueries []string
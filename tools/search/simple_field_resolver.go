@@ -46,6 +46,31 @@ type FieldResolver interface {
 	Resolve(field string) (*ResolverResult, error)
 }
 
+// FullTextResolver defines an optional FieldResolver interface for
+// resolving the match() full-text search filter function and the @rank sort key.
+type FullTextResolver interface {
+	// ResolveMatch returns a boolean db expression that checks whether
+	// the records match the resolved full-text search query.
+	//
+	// field is an optional field name to restrict the search to
+	// (empty string for all full-text indexed fields).
+	ResolveMatch(field string, query *ResolverResult) (*ResolverResult, error)
+
+	// ResolveRank returns the db identifier of the full-text search
+	// rank of the last resolved match expression (lower is better).
+	ResolveRank() (string, error)
+}
+
+// PermissionResolver defines an optional FieldResolver interface for
+// resolving the @request.auth.can(permission, [scope]) filter function.
+type PermissionResolver interface {
+	// ResolvePermission returns a boolean db expression that checks whether
+	// the current request auth has the resolved permission.
+	//
+	// scope is optional and it is nil if the function was called without one.
+	ResolvePermission(permission *ResolverResult, scope *ResolverResult) (*ResolverResult, error)
+}
+
 // NewSimpleFieldResolver creates a new `SimpleFieldResolver` with the
 // provided `allowedFields`.
 //
@@ -8,6 +8,7 @@ import (
 const (
 	randomSortKey string = "@random"
 	rowidSortKey  string = "@rowid"
+	rankSortKey   string = "@rank"
 )
 
 // sort field directions
@@ -29,9 +30,32 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 		return "RANDOM()", nil
 	}
 
+	identifier, err := s.buildIdentifier(fieldResolver)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s %s", identifier, s.Direction), nil
+}
+
+// buildIdentifier resolves the sort field into a plain db column
+// identifier (aka. without the sort direction).
+func (s *SortField) buildIdentifier(fieldResolver FieldResolver) (string, error) {
 	// special case for the builtin SQLite rowid column
 	if s.Name == rowidSortKey {
-		return fmt.Sprintf("[[_rowid_]] %s", s.Direction), nil
+		return "[[_rowid_]]", nil
+	}
+
+	// special case for the full-text search rank
+	if s.Name == rankSortKey {
+		if ftr, ok := fieldResolver.(FullTextResolver); ok {
+			identifier, err := ftr.ResolveRank()
+			if err != nil {
+				return "", fmt.Errorf("invalid sort field %q: %w", s.Name, err)
+			}
+			return identifier, nil
+		}
+		return "", fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
 	result, err := fieldResolver.Resolve(s.Name)
@@ -41,7 +65,7 @@ func (s *SortField) BuildExpr(fieldResolver FieldResolver) (string, error) {
 		return "", fmt.Errorf("invalid sort field %q", s.Name)
 	}
 
-	return fmt.Sprintf("%s %s", result.Identifier, s.Direction), nil
+	return result.Identifier, nil
 }
 
 // ParseSortFromString parses the provided string expression
@@ -32,6 +32,8 @@ func TestSortFieldBuildExpr(t *testing.T) {
 		{search.SortField{"@random", search.SortDesc}, false, "RANDOM()"},
 		// special _rowid_ field
 		{search.SortField{"@rowid", search.SortDesc}, false, "[[_rowid_]] DESC"},
+		// special @rank field with resolver without full-text search support
+		{search.SortField{"@rank", search.SortAsc}, true, ""},
 	}
 
 	for _, s := range scenarios {
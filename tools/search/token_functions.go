@@ -54,3 +54,82 @@ var TokenFunctions = map[string]func(
 		}, nil
 	},
 }
+
+const matchFunctionName = "match"
+
+// resolveMatchFunction resolves the match([field], query) full-text search function.
+//
+// The function requires a FieldResolver that implements the [FullTextResolver] interface
+// and it resolves to a boolean expression, ex. `match(title, 'lorem*') = true`.
+//
+// The query argument could be either a plain text or an identifier (ex. @request.query.q)
+// and it uses the SQLite FTS5 query syntax (https://www.sqlite.org/fts5.html#full_text_query_syntax).
+func resolveMatchFunction(fieldResolver FieldResolver, args ...fexpr.Token) (*ResolverResult, error) {
+	ftr, ok := fieldResolver.(FullTextResolver)
+	if !ok {
+		return nil, fmt.Errorf("[%s] full-text search is not supported", matchFunctionName)
+	}
+
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("[%s] expected 1 or 2 arguments, got %d", matchFunctionName, len(args))
+	}
+
+	var field string
+	if len(args) == 2 {
+		if args[0].Type != fexpr.TokenIdentifier {
+			return nil, fmt.Errorf("[%s] the field argument must be an identifier", matchFunctionName)
+		}
+		field = args[0].Literal
+	}
+
+	queryArg := args[len(args)-1]
+	if queryArg.Type != fexpr.TokenText && queryArg.Type != fexpr.TokenIdentifier {
+		return nil, fmt.Errorf("[%s] the query argument must be a text or identifier", matchFunctionName)
+	}
+
+	query, err := resolveToken(queryArg, fieldResolver)
+	if err != nil {
+		return nil, fmt.Errorf("[%s] failed to resolve the query argument: %w", matchFunctionName, err)
+	}
+
+	return ftr.ResolveMatch(field, query)
+}
+
+const permissionFunctionName = "@request.auth.can"
+
+// resolvePermissionFunction resolves the @request.auth.can(permission, [scope]) RBAC function.
+//
+// The function requires a FieldResolver that implements the [PermissionResolver] interface
+// and it resolves to a boolean expression, ex. `@request.auth.can("posts.update", org) = true`.
+//
+// Both arguments could be either a plain text or an identifier (ex. a record field).
+func resolvePermissionFunction(fieldResolver FieldResolver, args ...fexpr.Token) (*ResolverResult, error) {
+	pr, ok := fieldResolver.(PermissionResolver)
+	if !ok {
+		return nil, fmt.Errorf("[%s] permissions are not supported", permissionFunctionName)
+	}
+
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("[%s] expected 1 or 2 arguments, got %d", permissionFunctionName, len(args))
+	}
+
+	resolved := make([]*ResolverResult, len(args))
+	for i, arg := range args {
+		if arg.Type != fexpr.TokenText && arg.Type != fexpr.TokenIdentifier {
+			return nil, fmt.Errorf("[%s] argument %d must be a text or identifier", permissionFunctionName, i)
+		}
+
+		result, err := resolveToken(arg, fieldResolver)
+		if err != nil {
+			return nil, fmt.Errorf("[%s] failed to resolve argument %d: %w", permissionFunctionName, i, err)
+		}
+		resolved[i] = result
+	}
+
+	var scope *ResolverResult
+	if len(resolved) == 2 {
+		scope = resolved[1]
+	}
+
+	return pr.ResolvePermission(resolved[0], scope)
+}
@@ -209,6 +209,236 @@ func TestTokenFunctionsGeoDistanceExec(t *testing.T) {
 	}
 }
 
+type testFullTextResolver struct {
+	*SimpleFieldResolver
+	field string
+	query *ResolverResult
+}
+
+func (r *testFullTextResolver) ResolveMatch(field string, query *ResolverResult) (*ResolverResult, error) {
+	r.field = field
+	r.query = query
+	return &ResolverResult{Identifier: "test_match"}, nil
+}
+
+func (r *testFullTextResolver) ResolveRank() (string, error) {
+	return "test_rank", nil
+}
+
+func TestMatchFunction(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name          string
+		resolver      FieldResolver
+		args          []fexpr.Token
+		expectError   bool
+		expectedField string
+	}{
+		{
+			"resolver without full-text search support",
+			NewSimpleFieldResolver("test"),
+			[]fexpr.Token{{Literal: "abc", Type: fexpr.TokenText}},
+			true,
+			"",
+		},
+		{
+			"no arguments",
+			&testFullTextResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			nil,
+			true,
+			"",
+		},
+		{
+			"too many arguments",
+			&testFullTextResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{
+				{Literal: "test", Type: fexpr.TokenIdentifier},
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			true,
+			"",
+		},
+		{
+			"non-identifier field argument",
+			&testFullTextResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{
+				{Literal: "test", Type: fexpr.TokenText},
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			true,
+			"",
+		},
+		{
+			"number query argument",
+			&testFullTextResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{{Literal: "123", Type: fexpr.TokenNumber}},
+			true,
+			"",
+		},
+		{
+			"query only",
+			&testFullTextResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{{Literal: "abc", Type: fexpr.TokenText}},
+			false,
+			"",
+		},
+		{
+			"field and query",
+			&testFullTextResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{
+				{Literal: "test", Type: fexpr.TokenIdentifier},
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			false,
+			"test",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := resolveMatchFunction(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Identifier != "test_match" {
+				t.Fatalf("Expected the ResolveMatch result, got %v", result)
+			}
+
+			ftr := s.resolver.(*testFullTextResolver)
+
+			if ftr.field != s.expectedField {
+				t.Fatalf("Expected field %q, got %q", s.expectedField, ftr.field)
+			}
+
+			if ftr.query == nil || len(ftr.query.Params) != 1 {
+				t.Fatalf("Expected the query to be resolved as a single placeholder param, got %v", ftr.query)
+			}
+		})
+	}
+}
+
+type testPermissionResolver struct {
+	*SimpleFieldResolver
+	permission *ResolverResult
+	scope      *ResolverResult
+}
+
+func (r *testPermissionResolver) ResolvePermission(permission *ResolverResult, scope *ResolverResult) (*ResolverResult, error) {
+	r.permission = permission
+	r.scope = scope
+	return &ResolverResult{Identifier: "test_can"}, nil
+}
+
+func TestPermissionFunction(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		name          string
+		resolver      FieldResolver
+		args          []fexpr.Token
+		expectError   bool
+		expectedScope bool
+	}{
+		{
+			"resolver without permissions support",
+			NewSimpleFieldResolver("test"),
+			[]fexpr.Token{{Literal: "abc", Type: fexpr.TokenText}},
+			true,
+			false,
+		},
+		{
+			"no arguments",
+			&testPermissionResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			nil,
+			true,
+			false,
+		},
+		{
+			"too many arguments",
+			&testPermissionResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "test", Type: fexpr.TokenIdentifier},
+				{Literal: "abc", Type: fexpr.TokenText},
+			},
+			true,
+			false,
+		},
+		{
+			"number permission argument",
+			&testPermissionResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{{Literal: "123", Type: fexpr.TokenNumber}},
+			true,
+			false,
+		},
+		{
+			"unresolvable scope argument",
+			&testPermissionResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "missing", Type: fexpr.TokenIdentifier},
+			},
+			true,
+			false,
+		},
+		{
+			"permission only",
+			&testPermissionResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{{Literal: "abc", Type: fexpr.TokenText}},
+			false,
+			false,
+		},
+		{
+			"permission and scope",
+			&testPermissionResolver{SimpleFieldResolver: NewSimpleFieldResolver("test")},
+			[]fexpr.Token{
+				{Literal: "abc", Type: fexpr.TokenText},
+				{Literal: "test", Type: fexpr.TokenIdentifier},
+			},
+			false,
+			true,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := resolvePermissionFunction(s.resolver, s.args...)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.Identifier != "test_can" {
+				t.Fatalf("Expected the ResolvePermission result, got %v", result)
+			}
+
+			pr := s.resolver.(*testPermissionResolver)
+
+			if pr.permission == nil || len(pr.permission.Params) != 1 {
+				t.Fatalf("Expected the permission to be resolved as a single placeholder param, got %v", pr.permission)
+			}
+
+			if hasScope := pr.scope != nil; hasScope != s.expectedScope {
+				t.Fatalf("Expected hasScope %v, got %v", s.expectedScope, hasScope)
+			}
+		})
+	}
+}
+
 // -------------------------------------------------------------------
 
 func testCompareResults(t *testing.T, a, b *ResolverResult) {
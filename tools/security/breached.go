@@ -0,0 +1,125 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// IsBreachedPassword checks whether the SHA-1 hash of the provided
+// password exists in the local breached password hashes list.
+//
+// The path could be either:
+//   - a single file with "HASH[:COUNT]" lines sorted in ascending order
+//     (e.g. the "Pwned Passwords" SHA-1 ordered by hash download);
+//     the file is binary searched so it is not loaded in memory
+//   - a directory with k-anonymity range files named after the first 5
+//     uppercased hash characters (e.g. "21BD1.txt") and containing
+//     "SUFFIX[:COUNT]" lines for the remaining 35 hash characters
+func IsBreachedPassword(path string, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+
+	if info.IsDir() {
+		f, err := os.Open(filepath.Join(path, hash[:5]+".txt"))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return false, nil // no hashes with the specified prefix
+			}
+			return false, err
+		}
+		defer f.Close()
+
+		return scanHashLines(f, hash[5:])
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	return searchSortedHashLines(f, info.Size(), hash)
+}
+
+// scanHashLines sequentially reads r and checks whether any of its lines hash part matches hash.
+func scanHashLines(r io.Reader, hash string) (bool, error) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		if strings.EqualFold(lineHash(scanner.Text()), hash) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
+
+// searchSortedHashLines performs a binary search over the sorted lines of r
+// and checks whether any of them hash part matches hash.
+func searchSortedHashLines(r io.ReaderAt, size int64, hash string) (bool, error) {
+	// lineAt returns the hash of the first full line starting at or after offset
+	// (an empty string is returned if there is no such line)
+	lineAt := func(offset int64) (string, error) {
+		br := bufio.NewReader(io.NewSectionReader(r, max(offset-1, 0), size))
+
+		// skip the remaining of the line that contains offset-1
+		if offset > 0 {
+			if _, err := br.ReadString('\n'); err != nil {
+				if errors.Is(err, io.EOF) {
+					return "", nil
+				}
+				return "", err
+			}
+		}
+
+		line, err := br.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+
+		return strings.ToUpper(lineHash(line)), nil
+	}
+
+	// find the smallest offset whose line hash is >= hash
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		current, err := lineAt(mid)
+		if err != nil {
+			return false, err
+		}
+
+		if current != "" && current < hash {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	current, err := lineAt(lo)
+	if err != nil {
+		return false, err
+	}
+
+	return current == hash, nil
+}
+
+// lineHash returns the hash part of a "HASH[:COUNT]" line.
+func lineHash(line string) string {
+	hash, _, _ := strings.Cut(line, ":")
+
+	return strings.TrimSpace(hash)
+}
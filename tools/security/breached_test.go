@@ -0,0 +1,105 @@
+package security_test
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+func TestIsBreachedPassword(t *testing.T) {
+	sha1Hex := func(str string) string {
+		sum := sha1.Sum([]byte(str))
+		return strings.ToUpper(hex.EncodeToString(sum[:]))
+	}
+
+	var breached []string
+	for i := 0; i < 500; i++ {
+		breached = append(breached, fmt.Sprintf("breached_%d", i))
+	}
+
+	lines := make([]string, 0, len(breached))
+	for i, password := range breached {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(password), i+1))
+	}
+	slices.Sort(lines)
+
+	dir := t.TempDir()
+
+	// single sorted file (with CRLF line endings)
+	sortedFile := filepath.Join(dir, "sorted.txt")
+	if err := os.WriteFile(sortedFile, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// k-anonymity range files
+	rangesDir := filepath.Join(dir, "ranges")
+	if err := os.Mkdir(rangesDir, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+	ranges := map[string][]string{}
+	for _, line := range lines {
+		ranges[line[:5]] = append(ranges[line[:5]], line[5:])
+	}
+	for prefix, suffixes := range ranges {
+		err := os.WriteFile(filepath.Join(rangesDir, prefix+".txt"), []byte(strings.Join(suffixes, "\n")), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// first and last sorted file entries
+	var first, last string
+	for _, password := range breached {
+		hash := sha1Hex(password)
+		if lines[0][:40] == hash {
+			first = password
+		}
+		if lines[len(lines)-1][:40] == hash {
+			last = password
+		}
+	}
+
+	scenarios := []struct {
+		password string
+		expected bool
+	}{
+		{"", false},
+		{"not_breached", false},
+		{"breached_", false},
+		{"breached_500", false},
+		{"breached_0", true},
+		{"breached_123", true},
+		{"breached_499", true},
+		{first, true},
+		{last, true},
+	}
+
+	for _, path := range []string{sortedFile, rangesDir} {
+		for _, s := range scenarios {
+			t.Run(fmt.Sprintf("%s_%s", filepath.Base(path), s.password), func(t *testing.T) {
+				result, err := security.IsBreachedPassword(path, s.password)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if result != s.expected {
+					t.Fatalf("Expected %v, got %v", s.expected, result)
+				}
+			})
+		}
+	}
+
+	t.Run("missing path", func(t *testing.T) {
+		_, err := security.IsBreachedPassword(filepath.Join(dir, "missing"), "breached_0")
+		if err == nil {
+			t.Fatal("Expected error, got nil")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package security
 
 import (
+	"crypto/rsa"
 	"errors"
 	"time"
 
@@ -54,3 +55,44 @@ func NewJWT(payload jwt.MapClaims, signingKey string, duration time.Duration) (s
 
 	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(signingKey))
 }
+
+// NewRS256JWT generates and returns new RS256 signed JWT.
+//
+// If keyId is not empty, it is set as "kid" token header.
+func NewRS256JWT(payload jwt.MapClaims, privateKey *rsa.PrivateKey, keyId string, duration time.Duration) (string, error) {
+	claims := jwt.MapClaims{
+		"exp": time.Now().Add(duration).Unix(),
+	}
+
+	for k, v := range payload {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	if keyId != "" {
+		token.Header["kid"] = keyId
+	}
+
+	return token.SignedString(privateKey)
+}
+
+// ParseRS256JWT verifies and parses RS256 signed JWT and returns its claims.
+//
+// The verification public key is resolved with findKey based on the token "kid" header.
+func ParseRS256JWT(token string, findKey func(keyId string) (*rsa.PublicKey, error)) (jwt.MapClaims, error) {
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256"}))
+
+	parsedToken, err := parser.Parse(token, func(t *jwt.Token) (any, error) {
+		keyId, _ := t.Header["kid"].(string)
+		return findKey(keyId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok && parsedToken.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("unable to parse token")
+}
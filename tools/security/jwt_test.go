@@ -1,6 +1,9 @@
 package security_test
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
 	"fmt"
 	"strconv"
 	"testing"
@@ -194,3 +197,101 @@ func TestNewJWT(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAndParseRS256JWT(t *testing.T) {
+	key1, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key2, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findKey := func(keyId string) (*rsa.PublicKey, error) {
+		switch keyId {
+		case "key1":
+			return &key1.PublicKey, nil
+		case "key2":
+			return &key2.PublicKey, nil
+		default:
+			return nil, errors.New("missing key")
+		}
+	}
+
+	hs256Token, err := security.NewJWT(jwt.MapClaims{"name": "test"}, "test", 10*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	scenarios := []struct {
+		name        string
+		token       func() (string, error)
+		expectError bool
+	}{
+		{
+			"HS256 token",
+			func() (string, error) {
+				return hs256Token, nil
+			},
+			true,
+		},
+		{
+			"zero duration",
+			func() (string, error) {
+				return security.NewRS256JWT(jwt.MapClaims{"name": "test"}, key1, "key1", 0)
+			},
+			true,
+		},
+		{
+			"missing kid",
+			func() (string, error) {
+				return security.NewRS256JWT(jwt.MapClaims{"name": "test"}, key1, "", 10*time.Second)
+			},
+			true,
+		},
+		{
+			"mismatched kid",
+			func() (string, error) {
+				return security.NewRS256JWT(jwt.MapClaims{"name": "test"}, key1, "key2", 10*time.Second)
+			},
+			true,
+		},
+		{
+			"valid token",
+			func() (string, error) {
+				return security.NewRS256JWT(jwt.MapClaims{"name": "test"}, key2, "key2", 10*time.Second)
+			},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			token, err := s.token()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			claims, err := security.ParseRS256JWT(token, findKey)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if claims["name"] != "test" {
+				t.Fatalf("Expected name claim %q, got %v", "test", claims["name"])
+			}
+
+			if _, ok := claims["exp"]; !ok {
+				t.Fatalf("Missing required claim exp, got %v", claims)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package security
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP defaults as defined in [RFC 6238] and supported by
+// the majority of the authenticator apps.
+//
+// [RFC 6238]: https://datatracker.ietf.org/doc/html/rfc6238
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 // in seconds
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewTOTPSecret generates a new random base32 encoded (without padding) TOTP shared secret.
+func NewTOTPSecret() string {
+	b := make([]byte, 20) // 160 bits as recommended by RFC 4226
+
+	if _, err := crand.Read(b); err != nil {
+		panic(err) // should never happen
+	}
+
+	return totpEncoding.EncodeToString(b)
+}
+
+// TOTPStep returns the TOTP time step (aka. counter) for the specified time.
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / TOTPPeriod
+}
+
+// TOTPCode generates the TOTP code for the specified base32 encoded secret and time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	h := hmac.New(sha1.New, key)
+	h.Write(msg)
+	sum := h.Sum(nil)
+
+	// dynamic truncation (https://datatracker.ietf.org/doc/html/rfc4226#section-5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TOTPDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TOTPDigits, value%mod), nil
+}
+
+// ValidateTOTP checks whether the provided code is valid for the specified
+// base32 encoded secret and time, allowing skew steps of clock drift in each direction.
+//
+// On success it returns the matched time step (could be used to prevent code reuse).
+func ValidateTOTP(secret string, code string, t time.Time, skew int) (int64, bool) {
+	if len(code) != TOTPDigits {
+		return 0, false
+	}
+
+	current := TOTPStep(t)
+
+	for i := -skew; i <= skew; i++ {
+		step := current + int64(i)
+
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// TOTPURL returns the "otpauth://" key URI for the specified secret
+// that could be used to generate a QR code for the authenticator apps.
+//
+// See https://github.com/google/google-authenticator/wiki/Key-Uri-Format.
+func TOTPURL(secret string, issuer string, account string) string {
+	label := url.PathEscape(account)
+	if issuer != "" {
+		label = url.PathEscape(issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", secret)
+	if issuer != "" {
+		query.Set("issuer", issuer)
+	}
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TOTPDigits))
+	query.Set("period", fmt.Sprint(TOTPPeriod))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
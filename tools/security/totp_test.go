@@ -0,0 +1,133 @@
+package security_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// base32 of the RFC 6238 SHA1 test secret "12345678901234567890"
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestNewTOTPSecret(t *testing.T) {
+	s1 := security.NewTOTPSecret()
+	s2 := security.NewTOTPSecret()
+
+	if len(s1) != 32 {
+		t.Fatalf("Expected secret with length 32, got %d (%q)", len(s1), s1)
+	}
+
+	if s1 == s2 {
+		t.Fatalf("Expected different secrets, got %q", s1)
+	}
+
+	if _, err := security.TOTPCode(s1, 1); err != nil {
+		t.Fatalf("Expected valid base32 secret, got %v", err)
+	}
+}
+
+func TestTOTPCode(t *testing.T) {
+	// https://datatracker.ietf.org/doc/html/rfc6238#appendix-B (truncated to 6 digits)
+	scenarios := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprint(s.unix), func(t *testing.T) {
+			code, err := security.TOTPCode(testTOTPSecret, security.TOTPStep(time.Unix(s.unix, 0)))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if code != s.expected {
+				t.Fatalf("Expected %q, got %q", s.expected, code)
+			}
+		})
+	}
+
+	t.Run("invalid secret", func(t *testing.T) {
+		if _, err := security.TOTPCode("invalid!", 1); err == nil {
+			t.Fatal("Expected error")
+		}
+	})
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := security.TOTPStep(now)
+
+	prev, _ := security.TOTPCode(testTOTPSecret, step-1)
+	next, _ := security.TOTPCode(testTOTPSecret, step+1)
+	farNext, _ := security.TOTPCode(testTOTPSecret, step+2)
+
+	scenarios := []struct {
+		name         string
+		secret       string
+		code         string
+		skew         int
+		expectedStep int64
+		expected     bool
+	}{
+		{"empty code", testTOTPSecret, "", 1, 0, false},
+		{"invalid length", testTOTPSecret, "0504710", 1, 0, false},
+		{"invalid secret", "invalid!", "050471", 1, 0, false},
+		{"current step", testTOTPSecret, "050471", 0, step, true},
+		{"previous step without skew", testTOTPSecret, prev, 0, 0, false},
+		{"previous step with skew", testTOTPSecret, prev, 1, step - 1, true},
+		{"next step with skew", testTOTPSecret, next, 1, step + 1, true},
+		{"out of the skew window", testTOTPSecret, farNext, 1, 0, false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			matchedStep, ok := security.ValidateTOTP(s.secret, s.code, now, s.skew)
+
+			if ok != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, ok)
+			}
+
+			if matchedStep != s.expectedStep {
+				t.Fatalf("Expected step %d, got %d", s.expectedStep, matchedStep)
+			}
+		})
+	}
+}
+
+func TestTOTPURL(t *testing.T) {
+	scenarios := []struct {
+		issuer   string
+		account  string
+		expected string
+	}{
+		{
+			"",
+			"test@example.com",
+			"otpauth://totp/test@example.com?algorithm=SHA1&digits=6&period=30&secret=ABC",
+		},
+		{
+			"Acme Inc",
+			"test@example.com",
+			"otpauth://totp/Acme%20Inc:test@example.com?algorithm=SHA1&digits=6&issuer=Acme+Inc&period=30&secret=ABC",
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.issuer, func(t *testing.T) {
+			result := security.TOTPURL("ABC", s.issuer, s.account)
+
+			if result != s.expected {
+				t.Fatalf("Expected\n%s\ngot\n%s", s.expected, result)
+			}
+		})
+	}
+}
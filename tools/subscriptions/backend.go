@@ -0,0 +1,208 @@
+package subscriptions
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// Event defines a broker event that could be fanned out to other broker instances
+// (ex. a record change that needs to be delivered to the clients connected to another node).
+type Event struct {
+	// Type is the event type identifier (ex. "record").
+	Type string `json:"type"`
+
+	// Data is the raw serialized event data.
+	Data json.RawMessage `json:"data"`
+}
+
+// Backend defines the interface of a broker events distribution backend.
+//
+// Note that the realtime clients are always connected (aka. registered)
+// to a single broker instance and the backend is responsible only for
+// delivering the broker events between the different instances.
+type Backend interface {
+	// Publish fans out the event to all other broker instances.
+	Publish(event Event) error
+
+	// Handle registers the handler that is invoked for each
+	// event received from the other broker instances.
+	//
+	// Calling Handle multiple times replaces the previous handler.
+	Handle(handler func(event Event))
+
+	// Close releases the backend resources.
+	Close() error
+}
+
+// -------------------------------------------------------------------
+
+var _ Backend = (*MemoryBackend)(nil)
+
+// MemoryBackend is the default single instance (in-memory only) broker backend.
+//
+// Its Publish method is a no-op because there are no other broker instances.
+type MemoryBackend struct{}
+
+// NewMemoryBackend creates a new single instance MemoryBackend.
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{}
+}
+
+// Publish implements [Backend.Publish] and does nothing.
+func (b *MemoryBackend) Publish(event Event) error {
+	return nil
+}
+
+// Handle implements [Backend.Handle] and does nothing.
+func (b *MemoryBackend) Handle(handler func(event Event)) {}
+
+// Close implements [Backend.Close] and does nothing.
+func (b *MemoryBackend) Close() error {
+	return nil
+}
+
+// -------------------------------------------------------------------
+
+// Transport defines the interface of a raw messages transport used
+// by the [TransportBackend] to communicate with the other broker instances
+// (ex. Unix sockets, NATS, Redis Pub/Sub, etc.).
+type Transport interface {
+	// Publish sends the payload to all transport subscribers
+	// (it is OK if it is also delivered back to the sender).
+	Publish(payload []byte) error
+
+	// Subscribe registers the handler for the received payloads.
+	Subscribe(handler func(payload []byte)) error
+
+	// Close releases the transport resources.
+	Close() error
+}
+
+var _ Backend = (*TransportBackend)(nil)
+
+// TransportBackend is a multi-instance broker backend that
+// fans out the broker events via a pluggable [Transport].
+//
+// Each published envelope is signed with the shared secret and the
+// received envelopes with missing or invalid signature are ignored.
+type TransportBackend struct {
+	transport  Transport
+	secret     string
+	instanceId string
+	handler    func(event Event)
+	mux        sync.RWMutex
+}
+
+// transportEnvelope is the serialized TransportBackend message.
+type transportEnvelope struct {
+	// Origin is the instance id of the sender
+	// (used to skip the events published by the current instance).
+	Origin string `json:"origin"`
+
+	Event Event `json:"event"`
+}
+
+// signedTransportEnvelope is the transport payload of a single transportEnvelope.
+type signedTransportEnvelope struct {
+	// Envelope is the serialized transportEnvelope.
+	Envelope json.RawMessage `json:"envelope"`
+
+	// Signature is the HMAC-SHA256 hash of the serialized envelope.
+	Signature string `json:"signature"`
+}
+
+// NewTransportBackend creates a new TransportBackend and subscribes to the provided transport.
+//
+// The secret is used to sign and verify the exchanged envelopes
+// and it must be the same for all broker instances.
+func NewTransportBackend(transport Transport, secret string) (*TransportBackend, error) {
+	if transport == nil {
+		return nil, errors.New("missing transport")
+	}
+
+	if secret == "" {
+		return nil, errors.New("missing transport backend secret")
+	}
+
+	b := &TransportBackend{
+		transport:  transport,
+		secret:     secret,
+		instanceId: security.RandomString(20),
+	}
+
+	if err := transport.Subscribe(b.receive); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// InstanceId returns the unique random identifier of the current backend instance.
+func (b *TransportBackend) InstanceId() string {
+	return b.instanceId
+}
+
+// Publish implements [Backend.Publish].
+func (b *TransportBackend) Publish(event Event) error {
+	envelope, err := json.Marshal(&transportEnvelope{
+		Origin: b.instanceId,
+		Event:  event,
+	})
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(&signedTransportEnvelope{
+		Envelope:  envelope,
+		Signature: security.HS256(string(envelope), b.secret),
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.transport.Publish(payload)
+}
+
+// Handle implements [Backend.Handle].
+func (b *TransportBackend) Handle(handler func(event Event)) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	b.handler = handler
+}
+
+// Close implements [Backend.Close].
+func (b *TransportBackend) Close() error {
+	return b.transport.Close()
+}
+
+func (b *TransportBackend) receive(payload []byte) {
+	signed := &signedTransportEnvelope{}
+	if err := json.Unmarshal(payload, signed); err != nil {
+		return // invalid or unknown payload format
+	}
+
+	if signed.Signature == "" || !security.Equal(signed.Signature, security.HS256(string(signed.Envelope), b.secret)) {
+		return // not signed by a trusted instance
+	}
+
+	envelope := &transportEnvelope{}
+	if err := json.Unmarshal(signed.Envelope, envelope); err != nil {
+		return // invalid or unknown envelope format
+	}
+
+	if envelope.Origin == b.instanceId {
+		return // published by the current instance
+	}
+
+	b.mux.RLock()
+	handler := b.handler
+	b.mux.RUnlock()
+
+	if handler != nil {
+		handler(envelope.Event)
+	}
+}
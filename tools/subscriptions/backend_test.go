@@ -0,0 +1,271 @@
+package subscriptions_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pocketbase/pocketbase/tools/subscriptions"
+)
+
+// testTransportHub is a simple in-memory transport for connecting multiple backends.
+type testTransportHub struct {
+	mux      sync.Mutex
+	handlers []func(payload []byte)
+}
+
+func (h *testTransportHub) transport() subscriptions.Transport {
+	return &testTransport{hub: h}
+}
+
+type testTransport struct {
+	hub *testTransportHub
+}
+
+func (t *testTransport) Publish(payload []byte) error {
+	t.hub.mux.Lock()
+	handlers := t.hub.handlers
+	t.hub.mux.Unlock()
+
+	// note: delivered also to the sender
+	for _, h := range handlers {
+		h(payload)
+	}
+
+	return nil
+}
+
+func (t *testTransport) Subscribe(handler func(payload []byte)) error {
+	t.hub.mux.Lock()
+	defer t.hub.mux.Unlock()
+
+	t.hub.handlers = append(t.hub.handlers, handler)
+
+	return nil
+}
+
+func (t *testTransport) Close() error {
+	return nil
+}
+
+type failingTransport struct {
+	testTransport
+}
+
+func (t *failingTransport) Subscribe(handler func(payload []byte)) error {
+	return errors.New("test")
+}
+
+func TestMemoryBackend(t *testing.T) {
+	b := subscriptions.NewMemoryBackend()
+
+	var calls int
+	b.Handle(func(event subscriptions.Event) {
+		calls++
+	})
+
+	if err := b.Publish(subscriptions.Event{Type: "test"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 0 {
+		t.Fatalf("Expected no handler calls, got %d", calls)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewTransportBackend(t *testing.T) {
+	if _, err := subscriptions.NewTransportBackend(nil, "test"); err == nil {
+		t.Fatal("Expected error for nil transport")
+	}
+
+	if _, err := subscriptions.NewTransportBackend(&testTransport{hub: &testTransportHub{}}, ""); err == nil {
+		t.Fatal("Expected error for empty secret")
+	}
+
+	if _, err := subscriptions.NewTransportBackend(&failingTransport{}, "test"); err == nil {
+		t.Fatal("Expected transport subscribe error")
+	}
+
+	hub := &testTransportHub{}
+
+	b1, err := subscriptions.NewTransportBackend(hub.transport(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b2, err := subscriptions.NewTransportBackend(hub.transport(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b1.InstanceId() == "" || b1.InstanceId() == b2.InstanceId() {
+		t.Fatalf("Expected unique non-empty instance ids, got %q and %q", b1.InstanceId(), b2.InstanceId())
+	}
+}
+
+func TestTransportBackendPublish(t *testing.T) {
+	hub := &testTransportHub{}
+
+	backends := make([]*subscriptions.TransportBackend, 3)
+	received := make([][]subscriptions.Event, 3)
+
+	for i := range backends {
+		b, err := subscriptions.NewTransportBackend(hub.transport(), "test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b.Handle(func(event subscriptions.Event) {
+			received[i] = append(received[i], event)
+		})
+
+		backends[i] = b
+	}
+
+	// replace the handler of the last backend
+	backends[2].Handle(func(event subscriptions.Event) {})
+
+	// invalid payload
+	hub.transport().Publish([]byte("invalid"))
+
+	// unsigned envelope
+	hub.transport().Publish([]byte(`{"envelope":{"origin":"abc","event":{"type":"unsigned"}}}`))
+
+	// envelope signed with a different secret
+	untrusted, err := subscriptions.NewTransportBackend(hub.transport(), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	untrusted.Publish(subscriptions.Event{Type: "untrusted"})
+
+	err = backends[0].Publish(subscriptions.Event{
+		Type: "test",
+		Data: json.RawMessage(`{"a":123}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []int{0, 1, 0}
+	for i, events := range received {
+		if len(events) != expected[i] {
+			t.Fatalf("[%d] Expected %d events, got %d", i, expected[i], len(events))
+		}
+	}
+
+	if received[1][0].Type != "test" || string(received[1][0].Data) != `{"a":123}` {
+		t.Fatalf("Unexpected received event %#v", received[1][0])
+	}
+}
+
+func TestSocketTransport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "sockets")
+
+	t1, err := subscriptions.NewSocketTransport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t1.Close()
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0700 {
+		t.Fatalf("Expected the sockets dir to be created with 0700 permissions, got %o", perm)
+	}
+
+	t2, err := subscriptions.NewSocketTransport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer t2.Close()
+
+	if t1.Path() == t2.Path() {
+		t.Fatalf("Expected different socket paths, got %q", t1.Path())
+	}
+
+	received1 := make(chan string, 10)
+	received2 := make(chan string, 10)
+	t1.Subscribe(func(payload []byte) { received1 <- string(payload) })
+	t2.Subscribe(func(payload []byte) { received2 <- string(payload) })
+
+	assertReceived := func(ch chan string, expected string) {
+		t.Helper()
+
+		select {
+		case v := <-ch:
+			if v != expected {
+				t.Fatalf("Expected payload %q, got %q", expected, v)
+			}
+		case <-time.After(1 * time.Second):
+			t.Fatalf("Expected payload %q to be received", expected)
+		}
+	}
+
+	assertNotReceived := func(ch chan string) {
+		t.Helper()
+
+		select {
+		case v := <-ch:
+			t.Fatalf("Unexpected payload %q", v)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	if err := t1.Publish([]byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	assertReceived(received2, "a")
+	assertNotReceived(received1)
+
+	// reuse the existing connection
+	if err := t1.Publish([]byte("b")); err != nil {
+		t.Fatal(err)
+	}
+	assertReceived(received2, "b")
+
+	if err := t2.Publish([]byte("c")); err != nil {
+		t.Fatal(err)
+	}
+	assertReceived(received1, "c")
+	assertNotReceived(received2)
+
+	// new transport
+	t3, err := subscriptions.NewSocketTransport(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	received3 := make(chan string, 10)
+	t3.Subscribe(func(payload []byte) { received3 <- string(payload) })
+
+	if err := t1.Publish([]byte("d")); err != nil {
+		t.Fatal(err)
+	}
+	assertReceived(received2, "d")
+	assertReceived(received3, "d")
+
+	// closed transport
+	if err := t3.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := t3.Publish([]byte("e")); err == nil {
+		t.Fatal("Expected closed transport publish error")
+	}
+
+	if err := t1.Publish([]byte("f")); err != nil {
+		t.Fatal(err)
+	}
+	assertReceived(received2, "f")
+	assertNotReceived(received3)
+}
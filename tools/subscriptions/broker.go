@@ -2,6 +2,9 @@ package subscriptions
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/pocketbase/pocketbase/tools/list"
 	"github.com/pocketbase/pocketbase/tools/store"
@@ -10,12 +13,24 @@ import (
 // Broker defines a struct for managing subscriptions clients.
 type Broker struct {
 	store *store.Store[string, Client]
+
+	backend        Backend
+	backendHandler func(event Event)
+	backendMux     sync.RWMutex
+
+	history     map[string]*messagesRing
+	historySize int
+	lastId      uint64
+	historyMux  sync.RWMutex
 }
 
 // NewBroker initializes and returns a new Broker instance.
 func NewBroker() *Broker {
 	return &Broker{
-		store: store.New[string, Client](nil),
+		store:       store.New[string, Client](nil),
+		backend:     NewMemoryBackend(),
+		history:     map[string]*messagesRing{},
+		historySize: DefaultHistorySize,
 	}
 }
 
@@ -63,3 +78,156 @@ func (b *Broker) Unregister(clientId string) {
 	client.Discard()
 	b.store.Remove(clientId)
 }
+
+// Presence returns the registered non-discarded clients that are subscribed
+// to the specified topic (with or without subscription options),
+// sorted by their id.
+func (b *Broker) Presence(topic string) []Client {
+	result := []Client{}
+
+	for _, client := range b.store.Values() {
+		if client.IsDiscarded() {
+			continue
+		}
+
+		// note: the "?" suffix ensures that only the exact topic is matched
+		if len(client.Subscriptions(topic+"?")) > 0 {
+			result = append(result, client)
+		}
+	}
+
+	slices.SortFunc(result, func(a, b Client) int {
+		return strings.Compare(a.Id(), b.Id())
+	})
+
+	return result
+}
+
+// Backend returns the broker events distribution backend
+// (default to [MemoryBackend]).
+func (b *Broker) Backend() Backend {
+	b.backendMux.RLock()
+	defer b.backendMux.RUnlock()
+
+	return b.backend
+}
+
+// SetBackend replaces the broker events distribution backend
+// (ex. with [TransportBackend] when running multiple app instances).
+//
+// The previously registered fanout handler (if any) is
+// transferred to the new backend.
+//
+// Note that the old backend is not closed.
+func (b *Broker) SetBackend(backend Backend) {
+	if backend == nil {
+		backend = NewMemoryBackend()
+	}
+
+	b.backendMux.Lock()
+	defer b.backendMux.Unlock()
+
+	b.backend = backend
+
+	if b.backendHandler != nil {
+		b.backend.Handle(b.backendHandler)
+	}
+}
+
+// Fanout publishes the event to the other broker instances via the broker backend.
+//
+// The event is not delivered to the current broker instance.
+func (b *Broker) Fanout(event Event) error {
+	return b.Backend().Publish(event)
+}
+
+// OnFanout registers the handler for the events
+// fanned out by the other broker instances.
+//
+// Calling OnFanout multiple times replaces the previous handler.
+func (b *Broker) OnFanout(handler func(event Event)) {
+	b.backendMux.Lock()
+	defer b.backendMux.Unlock()
+
+	b.backendHandler = handler
+
+	b.backend.Handle(handler)
+}
+
+// HistorySize returns the max number of messages that are kept per topic in the broker history.
+func (b *Broker) HistorySize() int {
+	b.historyMux.RLock()
+	defer b.historyMux.RUnlock()
+
+	return b.historySize
+}
+
+// SetHistorySize changes the max number of messages that are kept
+// per topic in the broker history and clears the existing history.
+//
+// Set it to zero or negative value to disable the history (default).
+//
+// Note that the history messages are kept in the process memory and their
+// ids are valid only for the current process (they are reset on restart and
+// are not shared between multiple app instances).
+func (b *Broker) SetHistorySize(size int) {
+	b.historyMux.Lock()
+	defer b.historyMux.Unlock()
+
+	b.historySize = size
+	b.history = map[string]*messagesRing{}
+}
+
+// Publish assigns a new monotonically increasing id to the message,
+// stores it in the topic history and returns the assigned id.
+//
+// Publish doesn't send the message to the clients and it is intended to be
+// used together with [Broker.History] for replaying missed messages
+// (ex. after a client reconnect).
+//
+// Returns 0 and does nothing if the broker history is disabled.
+func (b *Broker) Publish(topic string, m Message) uint64 {
+	b.historyMux.Lock()
+	defer b.historyMux.Unlock()
+
+	if b.historySize <= 0 {
+		return 0
+	}
+
+	b.lastId++
+	m.Id = b.lastId
+
+	ring, ok := b.history[topic]
+	if !ok {
+		ring = newMessagesRing(b.historySize)
+		b.history[topic] = ring
+	}
+	ring.push(m)
+
+	return m.Id
+}
+
+// LastMessageId returns the id of the last published message (or 0 if none).
+func (b *Broker) LastMessageId() uint64 {
+	b.historyMux.RLock()
+	defer b.historyMux.RUnlock()
+
+	return b.lastId
+}
+
+// History returns the stored topic messages with id greater than afterId
+// (ordered from the oldest to the newest one).
+//
+// Note that the history is bounded and the oldest topic
+// messages may have been already evicted.
+func (b *Broker) History(topic string, afterId uint64) []Message {
+	b.historyMux.RLock()
+	defer b.historyMux.RUnlock()
+
+	ring, ok := b.history[topic]
+	if !ok {
+		return []Message{}
+	}
+
+	return ring.after(afterId)
+}
@@ -1,6 +1,8 @@
 package subscriptions_test
 
 import (
+	"fmt"
+	"slices"
 	"testing"
 
 	"github.com/pocketbase/pocketbase/tools/subscriptions"
@@ -62,6 +64,115 @@ func TestChunkedClients(t *testing.T) {
 	}
 }
 
+func TestPresence(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	if total := len(b.Presence("demo")); total != 0 {
+		t.Fatalf("Expected %d clients, got %d", 0, total)
+	}
+
+	client1 := subscriptions.NewDefaultClient()
+	client1.Subscribe("demo", "other")
+	b.Register(client1)
+
+	client2 := subscriptions.NewDefaultClient()
+	client2.Subscribe(`demo?options={"query":{"a":"1"}}`)
+	b.Register(client2)
+
+	client3 := subscriptions.NewDefaultClient()
+	client3.Subscribe("demo/presence", "demo2")
+	b.Register(client3)
+
+	client4 := subscriptions.NewDefaultClient()
+	client4.Subscribe("demo")
+	client4.Discard()
+	b.Register(client4)
+
+	scenarios := []struct {
+		topic    string
+		expected []string
+	}{
+		{"missing", nil},
+		{"demo", []string{client1.Id(), client2.Id()}},
+		{"other", []string{client1.Id()}},
+		{"demo/presence", []string{client3.Id()}},
+		{"demo2", []string{client3.Id()}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.topic, func(t *testing.T) {
+			clients := b.Presence(s.topic)
+
+			if len(clients) != len(s.expected) {
+				t.Fatalf("Expected %d clients, got %d", len(s.expected), len(clients))
+			}
+
+			for _, id := range s.expected {
+				if !slices.ContainsFunc(clients, func(c subscriptions.Client) bool { return c.Id() == id }) {
+					t.Fatalf("Missing expected client %q", id)
+				}
+			}
+
+			for i := 1; i < len(clients); i++ {
+				if clients[i-1].Id() > clients[i].Id() {
+					t.Fatalf("Expected the clients to be sorted by id, got %q before %q", clients[i-1].Id(), clients[i].Id())
+				}
+			}
+		})
+	}
+}
+
+func TestBrokerBackend(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	if _, ok := b.Backend().(*subscriptions.MemoryBackend); !ok {
+		t.Fatalf("Expected the default backend to be MemoryBackend, got %T", b.Backend())
+	}
+
+	var received []subscriptions.Event
+	b.OnFanout(func(event subscriptions.Event) {
+		received = append(received, event)
+	})
+
+	hub := &testTransportHub{}
+
+	backend, err := subscriptions.NewTransportBackend(hub.transport(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the fanout handler should be transferred to the new backend
+	b.SetBackend(backend)
+
+	if b.Backend() != backend {
+		t.Fatalf("Expected the backend to be replaced, got %T", b.Backend())
+	}
+
+	other, err := subscriptions.NewTransportBackend(hub.transport(), "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := other.Publish(subscriptions.Event{Type: "test1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// shouldn't be delivered to the current broker
+	if err := b.Fanout(subscriptions.Event{Type: "test2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 1 || received[0].Type != "test1" {
+		t.Fatalf("Expected only test1 event, got %v", received)
+	}
+
+	// nil should fallback to the default backend
+	b.SetBackend(nil)
+	if _, ok := b.Backend().(*subscriptions.MemoryBackend); !ok {
+		t.Fatalf("Expected MemoryBackend, got %T", b.Backend())
+	}
+}
+
 func TestTotalClients(t *testing.T) {
 	b := subscriptions.NewBroker()
 
@@ -132,3 +243,74 @@ func TestUnregister(t *testing.T) {
 		t.Fatalf("Expected client with id %s, got error %v", clientB.Id(), err)
 	}
 }
+
+func TestBrokerHistory(t *testing.T) {
+	b := subscriptions.NewBroker()
+
+	if size := b.HistorySize(); size != 0 {
+		t.Fatalf("Expected the history to be disabled by default, got size %d", size)
+	}
+
+	if id := b.Publish("a", subscriptions.Message{}); id != 0 {
+		t.Fatalf("Expected 0 message id for the default disabled history, got %d", id)
+	}
+
+	b.SetHistorySize(3)
+
+	if id := b.LastMessageId(); id != 0 {
+		t.Fatalf("Expected last message id 0, got %d", id)
+	}
+
+	for i, topic := range []string{"a", "b", "a", "a", "a"} {
+		id := b.Publish(topic, subscriptions.Message{Name: topic})
+		if id != uint64(i+1) {
+			t.Fatalf("Expected message id %d, got %d", i+1, id)
+		}
+	}
+
+	if id := b.LastMessageId(); id != 5 {
+		t.Fatalf("Expected last message id 5, got %d", id)
+	}
+
+	scenarios := []struct {
+		topic       string
+		afterId     uint64
+		expectedIds []uint64
+	}{
+		{"missing", 0, []uint64{}},
+		{"a", 0, []uint64{3, 4, 5}}, // 1 was evicted
+		{"a", 3, []uint64{4, 5}},
+		{"a", 5, []uint64{}},
+		{"b", 0, []uint64{2}},
+		{"b", 2, []uint64{}},
+	}
+
+	for _, s := range scenarios {
+		t.Run(fmt.Sprintf("%s_%d", s.topic, s.afterId), func(t *testing.T) {
+			messages := b.History(s.topic, s.afterId)
+
+			ids := make([]uint64, len(messages))
+			for i, m := range messages {
+				ids[i] = m.Id
+				if m.Name != s.topic {
+					t.Fatalf("Expected message %d to be from topic %q, got %q", m.Id, s.topic, m.Name)
+				}
+			}
+
+			if !slices.Equal(ids, s.expectedIds) {
+				t.Fatalf("Expected ids %v, got %v", s.expectedIds, ids)
+			}
+		})
+	}
+
+	// disabled history
+	b.SetHistorySize(0)
+
+	if id := b.Publish("a", subscriptions.Message{}); id != 0 {
+		t.Fatalf("Expected 0 message id for disabled history, got %d", id)
+	}
+
+	if total := len(b.History("a", 0)); total != 0 {
+		t.Fatalf("Expected the history to be cleared, got %d messages", total)
+	}
+}
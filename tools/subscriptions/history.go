@@ -0,0 +1,48 @@
+package subscriptions
+
+// DefaultHistorySize is the default max number of messages
+// that are kept per topic in the broker history.
+//
+// The history is disabled by default because the messages are kept in
+// the process memory (use [Broker.SetHistorySize] to enable it).
+//
+// Note that the message ids are local for the current process, aka.
+// a history replay is not possible after restart or between multiple instances.
+const DefaultHistorySize = 0
+
+// messagesRing is a fixed size circular buffer of messages
+// where the newest message overwrites the oldest one.
+type messagesRing struct {
+	items []Message
+	start int
+}
+
+func newMessagesRing(size int) *messagesRing {
+	return &messagesRing{items: make([]Message, 0, size)}
+}
+
+// push appends a new message to the ring (evicting the oldest one if full).
+func (r *messagesRing) push(m Message) {
+	if len(r.items) < cap(r.items) {
+		r.items = append(r.items, m)
+		return
+	}
+
+	r.items[r.start] = m
+	r.start = (r.start + 1) % len(r.items)
+}
+
+// after returns the ring messages with id greater than afterId (from oldest to newest).
+func (r *messagesRing) after(afterId uint64) []Message {
+	result := []Message{}
+
+	total := len(r.items)
+	for i := 0; i < total; i++ {
+		m := r.items[(r.start+i)%total]
+		if m.Id > afterId {
+			result = append(result, m)
+		}
+	}
+
+	return result
+}
@@ -8,6 +8,9 @@ import (
 type Message struct {
 	Name string `json:"name"`
 	Data []byte `json:"data"`
+
+	// Id is the optional broker assigned message id (see [Broker.Publish]).
+	Id uint64 `json:"id,omitempty"`
 }
 
 // WriteSSE writes the current message in a SSE format into the provided writer.
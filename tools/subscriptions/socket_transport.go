@@ -0,0 +1,248 @@
+package subscriptions
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/tools/security"
+)
+
+// socketTransportMaxPayload is the max allowed size of a single SocketTransport payload.
+const socketTransportMaxPayload = 32 << 20
+
+const socketTransportExt = ".sock"
+
+var _ Transport = (*SocketTransport)(nil)
+
+// SocketTransport is a [Transport] implementation that exchanges the
+// payloads between the processes on the same host via Unix domain sockets.
+//
+// Each SocketTransport listens on its own randomly named socket file
+// in the shared directory and publishes to all other socket files in it.
+//
+// It is intended to be used as a simple stand-in for a message bus when running
+// multiple app processes behind a load balancer on a single machine.
+type SocketTransport struct {
+	dir      string
+	path     string
+	listener net.Listener
+
+	// note: the inbound and outbound state are guarded separately
+	// to avoid blocking the reads while publishing
+	handler    func(payload []byte)
+	inbound    map[net.Conn]struct{}
+	inboundMux sync.Mutex
+
+	outbound map[string]net.Conn
+	closed   bool
+	mux      sync.Mutex
+}
+
+// NewSocketTransport creates a new SocketTransport listening in the specified directory
+// (it will be created with 0700 permissions if missing).
+//
+// The directory should be accessible only by the app processes user.
+//
+// Note that the socket path length is limited by the OS (usually ~100 characters)
+// so keep the directory path short.
+func NewSocketTransport(dir string) (*SocketTransport, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dir, security.RandomString(10)+socketTransportExt)
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &SocketTransport{
+		dir:      dir,
+		path:     path,
+		listener: listener,
+		inbound:  map[net.Conn]struct{}{},
+		outbound: map[string]net.Conn{},
+	}
+
+	go t.acceptLoop()
+
+	return t, nil
+}
+
+// Path returns the socket file path of the current transport.
+func (t *SocketTransport) Path() string {
+	return t.path
+}
+
+// Publish implements [Transport.Publish] by writing the payload
+// to all other socket files in the transport directory.
+//
+// Unreachable (ex. stale) sockets are skipped and reported in the returned error.
+func (t *SocketTransport) Publish(payload []byte) error {
+	if len(payload) > socketTransportMaxPayload {
+		return fmt.Errorf("the payload size must be less than %d bytes", socketTransportMaxPayload)
+	}
+
+	entries, err := os.ReadDir(t.dir)
+	if err != nil {
+		return err
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.closed {
+		return errors.New("the transport is closed")
+	}
+
+	frame := make([]byte, 4+len(payload))
+	binary.BigEndian.PutUint32(frame, uint32(len(payload)))
+	copy(frame[4:], payload)
+
+	var errs []error
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), socketTransportExt) {
+			continue
+		}
+
+		path := filepath.Join(t.dir, entry.Name())
+		if path == t.path {
+			continue
+		}
+
+		if err := t.write(path, frame); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// write sends the frame to the specified socket path
+// reusing the previously established connection (if any).
+func (t *SocketTransport) write(path string, frame []byte) error {
+	conn, ok := t.outbound[path]
+	if ok {
+		if _, err := conn.Write(frame); err == nil {
+			return nil
+		}
+
+		// the peer was probably restarted -> retry with a new connection
+		conn.Close()
+		delete(t.outbound, path)
+	}
+
+	conn, err := net.Dial("unix", path)
+	if err != nil {
+		return err
+	}
+
+	if _, err := conn.Write(frame); err != nil {
+		conn.Close()
+		return err
+	}
+
+	t.outbound[path] = conn
+
+	return nil
+}
+
+// Subscribe implements [Transport.Subscribe].
+func (t *SocketTransport) Subscribe(handler func(payload []byte)) error {
+	t.inboundMux.Lock()
+	defer t.inboundMux.Unlock()
+
+	t.handler = handler
+
+	return nil
+}
+
+// Close implements [Transport.Close] and removes the transport socket file.
+func (t *SocketTransport) Close() error {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	if t.closed {
+		return nil
+	}
+
+	t.closed = true
+
+	for path, conn := range t.outbound {
+		conn.Close()
+		delete(t.outbound, path)
+	}
+
+	err := t.listener.Close()
+
+	t.inboundMux.Lock()
+	for conn := range t.inbound {
+		conn.Close()
+		delete(t.inbound, conn)
+	}
+	t.inboundMux.Unlock()
+
+	return err
+}
+
+func (t *SocketTransport) acceptLoop() {
+	for {
+		conn, err := t.listener.Accept()
+		if err != nil {
+			return // closed listener
+		}
+
+		t.inboundMux.Lock()
+		t.inbound[conn] = struct{}{}
+		t.inboundMux.Unlock()
+
+		go t.readLoop(conn)
+	}
+}
+
+func (t *SocketTransport) readLoop(conn net.Conn) {
+	defer func() {
+		conn.Close()
+
+		t.inboundMux.Lock()
+		delete(t.inbound, conn)
+		t.inboundMux.Unlock()
+	}()
+
+	reader := bufio.NewReader(conn)
+	header := make([]byte, 4)
+
+	for {
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return // closed or broken connection
+		}
+
+		size := binary.BigEndian.Uint32(header)
+		if size > socketTransportMaxPayload {
+			return // invalid frame
+		}
+
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return
+		}
+
+		t.inboundMux.Lock()
+		handler := t.handler
+		t.inboundMux.Unlock()
+
+		if handler != nil {
+			handler(payload)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// maxCBORDepth limits the nesting of the decoded CBOR items.
+const maxCBORDepth = 16
+
+// decodeCBOR decodes a single CBOR data item from the beginning of raw
+// and returns the decoded value together with the remaining bytes.
+//
+// It supports only the subset of the CBOR specification used by the
+// WebAuthn authenticators (RFC 8949 definite length items):
+//   - unsigned and negative integers are returned as int64
+//   - byte strings are returned as []byte
+//   - text strings are returned as string
+//   - arrays are returned as []any
+//   - maps are returned as map[any]any (with int64 or string keys)
+//   - tags are ignored and only their content is returned
+//   - simple values are returned as bool, nil or float64
+func decodeCBOR(raw []byte) (any, []byte, error) {
+	return decodeCBORItem(raw, 0)
+}
+
+func decodeCBORItem(raw []byte, depth int) (any, []byte, error) {
+	if depth > maxCBORDepth {
+		return nil, nil, errors.New("cbor: max nesting depth reached")
+	}
+
+	if len(raw) == 0 {
+		return nil, nil, errors.New("cbor: unexpected end of data")
+	}
+
+	major := raw[0] >> 5
+	info := raw[0] & 0x1f
+
+	// simple values and floats
+	if major == 7 {
+		return decodeCBORSimple(raw, info)
+	}
+
+	arg, rest, err := decodeCBORArgument(raw, info)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch major {
+	case 0: // unsigned integer
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return int64(arg), rest, nil
+	case 1: // negative integer
+		if arg > math.MaxInt64 {
+			return nil, nil, errors.New("cbor: integer overflow")
+		}
+		return -1 - int64(arg), rest, nil
+	case 2, 3: // byte and text string
+		if arg > uint64(len(rest)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		if major == 2 {
+			return append([]byte(nil), rest[:arg]...), rest[arg:], nil
+		}
+		return string(rest[:arg]), rest[arg:], nil
+	case 4: // array
+		// each item is at least 1 byte long
+		if arg > uint64(len(rest)) {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		result := make([]any, 0, arg)
+		for i := uint64(0); i < arg; i++ {
+			var item any
+			item, rest, err = decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			result = append(result, item)
+		}
+		return result, rest, nil
+	case 5: // map
+		// each key-value pair is at least 2 bytes long
+		if arg > uint64(len(rest))/2 {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		result := make(map[any]any, arg)
+		for i := uint64(0); i < arg; i++ {
+			var key, value any
+			key, rest, err = decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, nil, fmt.Errorf("cbor: unsupported map key type %T", key)
+			}
+			value, rest, err = decodeCBORItem(rest, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			result[key] = value
+		}
+		return result, rest, nil
+	case 6: // tag
+		return decodeCBORItem(rest, depth+1)
+	}
+
+	return nil, nil, fmt.Errorf("cbor: unsupported major type %d", major)
+}
+
+func decodeCBORArgument(raw []byte, info byte) (uint64, []byte, error) {
+	switch {
+	case info < 24:
+		return uint64(info), raw[1:], nil
+	case info == 24:
+		if len(raw) < 2 {
+			return 0, nil, errors.New("cbor: unexpected end of data")
+		}
+		return uint64(raw[1]), raw[2:], nil
+	case info == 25:
+		if len(raw) < 3 {
+			return 0, nil, errors.New("cbor: unexpected end of data")
+		}
+		return uint64(binary.BigEndian.Uint16(raw[1:])), raw[3:], nil
+	case info == 26:
+		if len(raw) < 5 {
+			return 0, nil, errors.New("cbor: unexpected end of data")
+		}
+		return uint64(binary.BigEndian.Uint32(raw[1:])), raw[5:], nil
+	case info == 27:
+		if len(raw) < 9 {
+			return 0, nil, errors.New("cbor: unexpected end of data")
+		}
+		return binary.BigEndian.Uint64(raw[1:]), raw[9:], nil
+	}
+
+	return 0, nil, errors.New("cbor: indefinite length items are not supported")
+}
+
+func decodeCBORSimple(raw []byte, info byte) (any, []byte, error) {
+	switch info {
+	case 20:
+		return false, raw[1:], nil
+	case 21:
+		return true, raw[1:], nil
+	case 22, 23: // null, undefined
+		return nil, raw[1:], nil
+	case 25: // half-precision float
+		if len(raw) < 3 {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		return float64(decodeFloat16(binary.BigEndian.Uint16(raw[1:]))), raw[3:], nil
+	case 26:
+		if len(raw) < 5 {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(raw[1:]))), raw[5:], nil
+	case 27:
+		if len(raw) < 9 {
+			return nil, nil, errors.New("cbor: unexpected end of data")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(raw[1:])), raw[9:], nil
+	}
+
+	return nil, nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+}
+
+func decodeFloat16(bits uint16) float32 {
+	sign := uint32(bits>>15) << 31
+	exp := uint32(bits>>10) & 0x1f
+	frac := uint32(bits) & 0x3ff
+
+	switch exp {
+	case 0: // subnormal
+		f := float32(frac) / 1024 * float32(math.Pow(2, -14))
+		if sign != 0 {
+			return -f
+		}
+		return f
+	case 0x1f: // inf or nan
+		return math.Float32frombits(sign | 0x7f800000 | frac<<13)
+	}
+
+	return math.Float32frombits(sign | (exp+112)<<23 | frac<<13)
+}
@@ -0,0 +1,121 @@
+package webauthn
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeCBOR(t *testing.T) {
+	// RFC 8949 Appendix A examples
+	scenarios := []struct {
+		hex         string
+		expected    string // json serialized
+		expectError bool
+	}{
+		{"00", `0`, false},
+		{"17", `23`, false},
+		{"1818", `24`, false},
+		{"1903e8", `1000`, false},
+		{"1a000f4240", `1000000`, false},
+		{"1b000000e8d4a51000", `1000000000000`, false},
+		{"1bffffffffffffffff", ``, true},
+		{"20", `-1`, false},
+		{"3863", `-100`, false},
+		{"f4", `false`, false},
+		{"f5", `true`, false},
+		{"f6", `null`, false},
+		{"f93c00", `1`, false},
+		{"f9c400", `-4`, false},
+		{"fa47c35000", `100000`, false},
+		{"fb3ff199999999999a", `1.1`, false},
+		{"4401020304", `"AQIDBA=="`, false},
+		{"6449455446", `"IETF"`, false},
+		{"83010203", `[1,2,3]`, false},
+		{"8301820203820405", `[1,[2,3],[4,5]]`, false},
+		{"a201020304", `{"1":2,"3":4}`, false},
+		{"a26161016162820203", `{"a":1,"b":[2,3]}`, false},
+		{"c074323031332d30332d32315432303a30343a30305a", `"2013-03-21T20:04:00Z"`, false},
+		// indefinite length
+		{"5f42010243030405ff", ``, true},
+		// truncated
+		{"19", ``, true},
+		{"44010203", ``, true},
+		{"8301", ``, true},
+		{"a1", ``, true},
+		// unsupported map key
+		{"a1410102", ``, true},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.hex, func(t *testing.T) {
+			raw, err := hex.DecodeString(s.hex)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, rest, err := decodeCBOR(raw)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if len(rest) != 0 {
+				t.Fatalf("Expected no remaining bytes, got %x", rest)
+			}
+
+			encoded, err := json.Marshal(normalizeCBOR(result))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(encoded) != s.expected {
+				t.Fatalf("Expected %s, got %s", s.expected, encoded)
+			}
+		})
+	}
+}
+
+func TestDecodeCBORMaxDepth(t *testing.T) {
+	raw := make([]byte, 0, maxCBORDepth+3)
+	for i := 0; i < maxCBORDepth+2; i++ {
+		raw = append(raw, 0x81) // array with 1 item
+	}
+	raw = append(raw, 0x00)
+
+	if _, _, err := decodeCBOR(raw); err == nil {
+		t.Fatal("Expected max depth error")
+	}
+}
+
+// normalizeCBOR converts the decoded map[any]any values to json serializable maps.
+func normalizeCBOR(v any) any {
+	switch val := v.(type) {
+	case map[any]any:
+		result := make(map[string]any, len(val))
+		for k, item := range val {
+			key, _ := json.Marshal(k)
+			result[string(trimQuotes(key))] = normalizeCBOR(item)
+		}
+		return result
+	case []any:
+		for i, item := range val {
+			val[i] = normalizeCBOR(item)
+		}
+		return val
+	}
+
+	return v
+}
+
+func trimQuotes(b []byte) []byte {
+	if len(b) >= 2 && b[0] == '"' && b[len(b)-1] == '"' {
+		return b[1 : len(b)-1]
+	}
+	return b
+}
@@ -0,0 +1,136 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Supported COSE algorithm identifiers.
+//
+// See https://www.iana.org/assignments/cose/cose.xhtml#algorithms.
+const (
+	AlgES256 int64 = -7
+	AlgEdDSA int64 = -8
+	AlgRS256 int64 = -257
+)
+
+// SupportedAlgorithms lists the COSE algorithms that could be used
+// for the credential public keys (in order of preference).
+var SupportedAlgorithms = []int64{AlgES256, AlgEdDSA, AlgRS256}
+
+// COSE key parameters.
+const (
+	coseKeyKty = 1
+	coseKeyAlg = 3
+	coseKeyCrv = -1 // or "n" for RSA
+	coseKeyX   = -2 // or "e" for RSA
+	coseKeyY   = -3
+
+	coseKtyOKP = 1
+	coseKtyEC2 = 2
+	coseKtyRSA = 3
+
+	coseCrvP256    = 1
+	coseCrvEd25519 = 6
+)
+
+// VerifySignature verifies the signature of data with the provided
+// CBOR encoded COSE credential public key.
+func VerifySignature(cosePublicKey []byte, data []byte, signature []byte) error {
+	key, err := parseCOSEKey(cosePublicKey)
+	if err != nil {
+		return err
+	}
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		hash := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(k, hash[:], signature) {
+			return errors.New("invalid ES256 signature")
+		}
+	case ed25519.PublicKey:
+		if !ed25519.Verify(k, data, signature) {
+			return errors.New("invalid EdDSA signature")
+		}
+	case *rsa.PublicKey:
+		hash := sha256.Sum256(data)
+		if err := rsa.VerifyPKCS1v15(k, crypto.SHA256, hash[:], signature); err != nil {
+			return fmt.Errorf("invalid RS256 signature: %w", err)
+		}
+	default:
+		return errors.New("unsupported public key")
+	}
+
+	return nil
+}
+
+// parseCOSEKey parses the CBOR encoded COSE key and returns
+// the corresponding crypto public key.
+func parseCOSEKey(raw []byte) (crypto.PublicKey, error) {
+	decoded, rest, err := decodeCBOR(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("unexpected trailing COSE key data")
+	}
+
+	params, ok := decoded.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid COSE key")
+	}
+
+	kty, _ := params[int64(coseKeyKty)].(int64)
+	alg, _ := params[int64(coseKeyAlg)].(int64)
+
+	switch {
+	case kty == coseKtyEC2 && alg == AlgES256:
+		crv, _ := params[int64(coseKeyCrv)].(int64)
+		x, _ := params[int64(coseKeyX)].([]byte)
+		y, _ := params[int64(coseKeyY)].([]byte)
+		if crv != coseCrvP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid ES256 COSE key")
+		}
+
+		// validate that the point is on the curve
+		uncompressed := append(append([]byte{0x04}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(uncompressed); err != nil {
+			return nil, fmt.Errorf("invalid ES256 COSE key: %w", err)
+		}
+
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	case kty == coseKtyOKP && alg == AlgEdDSA:
+		crv, _ := params[int64(coseKeyCrv)].(int64)
+		x, _ := params[int64(coseKeyX)].([]byte)
+		if crv != coseCrvEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid EdDSA COSE key")
+		}
+
+		return ed25519.PublicKey(x), nil
+	case kty == coseKtyRSA && alg == AlgRS256:
+		n, _ := params[int64(coseKeyCrv)].([]byte)
+		e, _ := params[int64(coseKeyX)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RS256 COSE key")
+		}
+
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported COSE key type %d with algorithm %d", kty, alg)
+}
@@ -0,0 +1,326 @@
+// Package webauthn implements a minimal server side verification of the
+// WebAuthn (aka. passkeys) registration and authentication ceremonies.
+//
+// Only the "none" attestation conveyance is supported, meaning that the
+// authenticator attestation statements are not verified and the
+// credentials are trusted on first use.
+//
+// See https://www.w3.org/TR/webauthn-3/.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Client data types.
+const (
+	TypeCreate = "webauthn.create"
+	TypeGet    = "webauthn.get"
+)
+
+// Authenticator data flags.
+const (
+	FlagUserPresent            byte = 0x01
+	FlagUserVerified           byte = 0x04
+	FlagAttestedCredentialData byte = 0x40
+	FlagExtensionData          byte = 0x80
+)
+
+// EncodeBase64 encodes the provided bytes as unpadded base64url string
+// (the encoding used by the WebAuthn JSON serialization).
+func EncodeBase64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBase64 decodes a base64url (or standard base64) encoded string,
+// with or without padding.
+func DecodeBase64(str string) ([]byte, error) {
+	str = strings.TrimRight(str, "=")
+	str = strings.NewReplacer("+", "-", "/", "_").Replace(str)
+
+	return base64.RawURLEncoding.DecodeString(str)
+}
+
+// ClientData represents the parsed CollectedClientData JSON.
+type ClientData struct {
+	Type        string `json:"type"`
+	Challenge   string `json:"challenge"`
+	Origin      string `json:"origin"`
+	CrossOrigin bool   `json:"crossOrigin"`
+}
+
+// ParseClientData parses the raw clientDataJSON bytes.
+func ParseClientData(raw []byte) (*ClientData, error) {
+	result := &ClientData{}
+
+	if err := json.Unmarshal(raw, result); err != nil {
+		return nil, fmt.Errorf("invalid client data: %w", err)
+	}
+
+	return result, nil
+}
+
+// AuthenticatorData represents the parsed authenticator data structure.
+type AuthenticatorData struct {
+	RPIdHash  []byte
+	Flags     byte
+	SignCount uint32
+
+	// attested credential data (available only on registration)
+	AAGUID       []byte
+	CredentialId []byte
+	PublicKey    []byte // CBOR encoded COSE key
+}
+
+// UserPresent reports whether the UP flag is set.
+func (d *AuthenticatorData) UserPresent() bool {
+	return d.Flags&FlagUserPresent != 0
+}
+
+// UserVerified reports whether the UV flag is set.
+func (d *AuthenticatorData) UserVerified() bool {
+	return d.Flags&FlagUserVerified != 0
+}
+
+// ParseAuthenticatorData parses the raw authenticator data bytes.
+func ParseAuthenticatorData(raw []byte) (*AuthenticatorData, error) {
+	if len(raw) < 37 {
+		return nil, errors.New("authenticator data is too short")
+	}
+
+	result := &AuthenticatorData{
+		RPIdHash:  append([]byte(nil), raw[:32]...),
+		Flags:     raw[32],
+		SignCount: binary.BigEndian.Uint32(raw[33:37]),
+	}
+
+	rest := raw[37:]
+
+	if result.Flags&FlagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return nil, errors.New("invalid attested credential data")
+		}
+
+		result.AAGUID = append([]byte(nil), rest[:16]...)
+
+		idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if idLength == 0 || idLength > 1023 || len(rest) < idLength {
+			return nil, errors.New("invalid credential id length")
+		}
+
+		result.CredentialId = append([]byte(nil), rest[:idLength]...)
+		rest = rest[idLength:]
+
+		// the public key is a single CBOR item with unknown length
+		_, afterKey, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential public key: %w", err)
+		}
+
+		result.PublicKey = append([]byte(nil), rest[:len(rest)-len(afterKey)]...)
+		rest = afterKey
+	}
+
+	if result.Flags&FlagExtensionData != 0 {
+		_, afterExt, err := decodeCBOR(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid extensions data: %w", err)
+		}
+		rest = afterExt
+	}
+
+	if len(rest) > 0 {
+		return nil, errors.New("unexpected trailing authenticator data")
+	}
+
+	return result, nil
+}
+
+// ParseAttestationObject parses the CBOR encoded attestation object and
+// returns its attestation format and the parsed authenticator data.
+//
+// Note that the attestation statement itself is not verified.
+func ParseAttestationObject(raw []byte) (string, *AuthenticatorData, error) {
+	decoded, _, err := decodeCBOR(raw)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+
+	obj, ok := decoded.(map[any]any)
+	if !ok {
+		return "", nil, errors.New("invalid attestation object")
+	}
+
+	format, _ := obj["fmt"].(string)
+
+	rawAuthData, ok := obj["authData"].([]byte)
+	if !ok {
+		return "", nil, errors.New("missing attestation authenticator data")
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthData)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return format, authData, nil
+}
+
+// Expectations defines the relying party expectations used
+// to verify the WebAuthn ceremony responses.
+type Expectations struct {
+	// RPId is the relying party identifier (aka. the app domain).
+	RPId string
+
+	// Challenge is the expected base64url encoded challenge.
+	Challenge string
+
+	// RequireUserVerification requires the UV authenticator data flag to be set.
+	RequireUserVerification bool
+}
+
+// VerifyRegistration verifies the response of a navigator.credentials.create()
+// call and returns the parsed authenticator data with the new credential.
+func VerifyRegistration(clientDataJSON []byte, attestationObject []byte, expect Expectations) (*AuthenticatorData, error) {
+	if err := verifyClientData(clientDataJSON, TypeCreate, expect); err != nil {
+		return nil, err
+	}
+
+	_, authData, err := ParseAttestationObject(attestationObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyAuthenticatorData(authData, expect); err != nil {
+		return nil, err
+	}
+
+	if len(authData.CredentialId) == 0 || len(authData.PublicKey) == 0 {
+		return nil, errors.New("missing attested credential data")
+	}
+
+	// ensure that the credential key is supported
+	if _, err := parseCOSEKey(authData.PublicKey); err != nil {
+		return nil, err
+	}
+
+	return authData, nil
+}
+
+// VerifyAssertion verifies the response of a navigator.credentials.get()
+// call against the stored CBOR encoded COSE credential public key
+// and returns the parsed authenticator data.
+//
+// Note that the caller is responsible for checking the returned SignCount.
+func VerifyAssertion(
+	clientDataJSON []byte,
+	rawAuthenticatorData []byte,
+	signature []byte,
+	cosePublicKey []byte,
+	expect Expectations,
+) (*AuthenticatorData, error) {
+	if err := verifyClientData(clientDataJSON, TypeGet, expect); err != nil {
+		return nil, err
+	}
+
+	authData, err := ParseAuthenticatorData(rawAuthenticatorData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := verifyAuthenticatorData(authData, expect); err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+
+	signed := make([]byte, 0, len(rawAuthenticatorData)+len(clientDataHash))
+	signed = append(signed, rawAuthenticatorData...)
+	signed = append(signed, clientDataHash[:]...)
+
+	if err := VerifySignature(cosePublicKey, signed, signature); err != nil {
+		return nil, err
+	}
+
+	return authData, nil
+}
+
+func verifyClientData(raw []byte, expectedType string, expect Expectations) error {
+	clientData, err := ParseClientData(raw)
+	if err != nil {
+		return err
+	}
+
+	if clientData.Type != expectedType {
+		return fmt.Errorf("invalid client data type %q", clientData.Type)
+	}
+
+	if expect.Challenge == "" || subtle.ConstantTimeCompare(
+		[]byte(strings.TrimRight(clientData.Challenge, "=")),
+		[]byte(strings.TrimRight(expect.Challenge, "=")),
+	) != 1 {
+		return errors.New("client data challenge mismatch")
+	}
+
+	if clientData.CrossOrigin {
+		return errors.New("cross-origin requests are not allowed")
+	}
+
+	if !IsValidOrigin(clientData.Origin, expect.RPId) {
+		return fmt.Errorf("invalid client data origin %q", clientData.Origin)
+	}
+
+	return nil
+}
+
+func verifyAuthenticatorData(authData *AuthenticatorData, expect Expectations) error {
+	rpIdHash := sha256.Sum256([]byte(expect.RPId))
+	if !bytes.Equal(authData.RPIdHash, rpIdHash[:]) {
+		return errors.New("authenticator data rpId hash mismatch")
+	}
+
+	if !authData.UserPresent() {
+		return errors.New("the user presence flag is not set")
+	}
+
+	if expect.RequireUserVerification && !authData.UserVerified() {
+		return errors.New("the user verification flag is not set")
+	}
+
+	return nil
+}
+
+// IsValidOrigin reports whether the provided client origin is allowed
+// for the specified relying party identifier.
+//
+// The origin host must be equal to or a subdomain of the rpId and
+// must use https (plain http is allowed only for localhost).
+func IsValidOrigin(origin string, rpId string) bool {
+	if rpId == "" {
+		return false
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	rpId = strings.ToLower(rpId)
+
+	if host != rpId && !strings.HasSuffix(host, "."+rpId) {
+		return false
+	}
+
+	return u.Scheme == "https" || (u.Scheme == "http" && host == "localhost")
+}
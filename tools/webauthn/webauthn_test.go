@@ -0,0 +1,348 @@
+package webauthn_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tools/webauthn"
+)
+
+const testRPId = "example.com"
+
+func TestBase64(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0xfe, 0x01}
+
+	encoded := webauthn.EncodeBase64(data)
+	if encoded != "-__-AQ" {
+		t.Fatalf("Expected %q, got %q", "-__-AQ", encoded)
+	}
+
+	for _, str := range []string{"-__-AQ", "-__-AQ==", "+//+AQ==", "+//+AQ"} {
+		decoded, err := webauthn.DecodeBase64(str)
+		if err != nil {
+			t.Fatalf("[%s] %v", str, err)
+		}
+		if string(decoded) != string(data) {
+			t.Fatalf("[%s] Expected %x, got %x", str, data, decoded)
+		}
+	}
+}
+
+func TestIsValidOrigin(t *testing.T) {
+	scenarios := []struct {
+		origin   string
+		rpId     string
+		expected bool
+	}{
+		{"https://example.com", "", false},
+		{"", "example.com", false},
+		{"invalid", "example.com", false},
+		{"https://example.com", "example.com", true},
+		{"https://EXAMPLE.com:8443", "example.com", true},
+		{"https://app.example.com", "example.com", true},
+		{"https://example.com.evil.com", "example.com", false},
+		{"https://notexample.com", "example.com", false},
+		{"http://example.com", "example.com", false},
+		{"http://localhost:8090", "localhost", true},
+		{"ftp://example.com", "example.com", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.origin+"_"+s.rpId, func(t *testing.T) {
+			result := webauthn.IsValidOrigin(s.origin, s.rpId)
+			if result != s.expected {
+				t.Fatalf("Expected %v, got %v", s.expected, result)
+			}
+		})
+	}
+}
+
+func TestVerifyRegistration(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	credentialId := []byte("test_credential")
+	coseKey := coseES256Key(key)
+
+	validAuthData := authenticatorData(testRPId, webauthn.FlagUserPresent|webauthn.FlagAttestedCredentialData, 0, credentialId, coseKey)
+
+	scenarios := []struct {
+		name        string
+		clientData  []byte
+		attestation []byte
+		expect      webauthn.Expectations
+		expectError bool
+	}{
+		{
+			"invalid client data type",
+			clientData(webauthn.TypeGet, "abc", "https://example.com"),
+			attestationObject(validAuthData),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"challenge mismatch",
+			clientData(webauthn.TypeCreate, "abc", "https://example.com"),
+			attestationObject(validAuthData),
+			webauthn.Expectations{RPId: testRPId, Challenge: "xyz"},
+			true,
+		},
+		{
+			"empty expected challenge",
+			clientData(webauthn.TypeCreate, "", "https://example.com"),
+			attestationObject(validAuthData),
+			webauthn.Expectations{RPId: testRPId},
+			true,
+		},
+		{
+			"invalid origin",
+			clientData(webauthn.TypeCreate, "abc", "https://evil.com"),
+			attestationObject(validAuthData),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"rpId hash mismatch",
+			clientData(webauthn.TypeCreate, "abc", "https://example.com"),
+			attestationObject(authenticatorData("evil.com", webauthn.FlagUserPresent|webauthn.FlagAttestedCredentialData, 0, credentialId, coseKey)),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"missing user presence",
+			clientData(webauthn.TypeCreate, "abc", "https://example.com"),
+			attestationObject(authenticatorData(testRPId, webauthn.FlagAttestedCredentialData, 0, credentialId, coseKey)),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"missing required user verification",
+			clientData(webauthn.TypeCreate, "abc", "https://example.com"),
+			attestationObject(validAuthData),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc", RequireUserVerification: true},
+			true,
+		},
+		{
+			"missing attested credential data",
+			clientData(webauthn.TypeCreate, "abc", "https://example.com"),
+			attestationObject(authenticatorData(testRPId, webauthn.FlagUserPresent, 0, nil, nil)),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"valid registration",
+			clientData(webauthn.TypeCreate, "abc", "https://app.example.com"),
+			attestationObject(validAuthData),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := webauthn.VerifyRegistration(s.clientData, s.attestation, s.expect)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if string(result.CredentialId) != string(credentialId) {
+				t.Fatalf("Expected credential id %q, got %q", credentialId, result.CredentialId)
+			}
+
+			if string(result.PublicKey) != string(coseKey) {
+				t.Fatalf("Expected public key %x, got %x", coseKey, result.PublicKey)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertion(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validClientData := clientData(webauthn.TypeGet, "abc", "https://example.com")
+	validAuthData := authenticatorData(testRPId, webauthn.FlagUserPresent|webauthn.FlagUserVerified, 5, nil, nil)
+
+	ecSign := func(authData, clientData []byte) []byte {
+		hash := sha256.Sum256(signedData(authData, clientData))
+		sig, err := ecdsa.SignASN1(rand.Reader, ecKey, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	scenarios := []struct {
+		name        string
+		clientData  []byte
+		authData    []byte
+		signature   []byte
+		publicKey   []byte
+		expect      webauthn.Expectations
+		expectError bool
+	}{
+		{
+			"invalid client data type",
+			clientData(webauthn.TypeCreate, "abc", "https://example.com"),
+			validAuthData,
+			ecSign(validAuthData, clientData(webauthn.TypeCreate, "abc", "https://example.com")),
+			coseES256Key(ecKey),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"invalid signature",
+			validClientData,
+			validAuthData,
+			ecSign(validAuthData, clientData(webauthn.TypeGet, "xyz", "https://example.com")),
+			coseES256Key(ecKey),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"different public key",
+			validClientData,
+			validAuthData,
+			ed25519.Sign(edKey, signedData(validAuthData, validClientData)),
+			coseES256Key(ecKey),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			true,
+		},
+		{
+			"valid ES256 signature",
+			validClientData,
+			validAuthData,
+			ecSign(validAuthData, validClientData),
+			coseES256Key(ecKey),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc", RequireUserVerification: true},
+			false,
+		},
+		{
+			"valid EdDSA signature",
+			validClientData,
+			validAuthData,
+			ed25519.Sign(edKey, signedData(validAuthData, validClientData)),
+			coseEdDSAKey(edPub),
+			webauthn.Expectations{RPId: testRPId, Challenge: "abc"},
+			false,
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			result, err := webauthn.VerifyAssertion(s.clientData, s.authData, s.signature, s.publicKey, s.expect)
+
+			hasErr := err != nil
+			if hasErr != s.expectError {
+				t.Fatalf("Expected hasErr %v, got %v (%v)", s.expectError, hasErr, err)
+			}
+
+			if hasErr {
+				return
+			}
+
+			if result.SignCount != 5 {
+				t.Fatalf("Expected sign count 5, got %d", result.SignCount)
+			}
+		})
+	}
+}
+
+// -------------------------------------------------------------------
+
+func clientData(typ, challenge, origin string) []byte {
+	raw, _ := json.Marshal(map[string]any{
+		"type":      typ,
+		"challenge": challenge,
+		"origin":    origin,
+	})
+	return raw
+}
+
+func signedData(authData, clientData []byte) []byte {
+	hash := sha256.Sum256(clientData)
+	return append(append([]byte(nil), authData...), hash[:]...)
+}
+
+func authenticatorData(rpId string, flags byte, signCount uint32, credentialId []byte, coseKey []byte) []byte {
+	rpIdHash := sha256.Sum256([]byte(rpId))
+
+	result := append([]byte(nil), rpIdHash[:]...)
+	result = append(result, flags)
+	result = binary.BigEndian.AppendUint32(result, signCount)
+
+	if flags&webauthn.FlagAttestedCredentialData != 0 {
+		result = append(result, make([]byte, 16)...) // aaguid
+		result = binary.BigEndian.AppendUint16(result, uint16(len(credentialId)))
+		result = append(result, credentialId...)
+		result = append(result, coseKey...)
+	}
+
+	return result
+}
+
+func attestationObject(authData []byte) []byte {
+	result := []byte{0xa3}
+	result = append(result, 0x63)
+	result = append(result, "fmt"...)
+	result = append(result, 0x64)
+	result = append(result, "none"...)
+	result = append(result, 0x67)
+	result = append(result, "attStmt"...)
+	result = append(result, 0xa0)
+	result = append(result, 0x68)
+	result = append(result, "authData"...)
+	result = append(result, 0x59)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(authData)))
+	result = append(result, authData...)
+	return result
+}
+
+func coseES256Key(key *ecdsa.PrivateKey) []byte {
+	x := key.PublicKey.X.FillBytes(make([]byte, 32))
+	y := key.PublicKey.Y.FillBytes(make([]byte, 32))
+
+	result := []byte{
+		0xa5,
+		0x01, 0x02, // kty: EC2
+		0x03, 0x26, // alg: -7
+		0x20, 0x01, // crv: P-256
+		0x21, 0x58, 0x20, // x
+	}
+	result = append(result, x...)
+	result = append(result, 0x22, 0x58, 0x20) // y
+	result = append(result, y...)
+	return result
+}
+
+func coseEdDSAKey(key ed25519.PublicKey) []byte {
+	result := []byte{
+		0xa4,
+		0x01, 0x01, // kty: OKP
+		0x03, 0x27, // alg: -8
+		0x20, 0x06, // crv: Ed25519
+		0x21, 0x58, 0x20, // x
+	}
+	return append(result, key...)
+}